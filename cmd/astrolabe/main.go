@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/go-openapi/swag"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
 	// restClient is the underlying REST/Swagger client
@@ -14,6 +16,8 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
+	"time"
 )
 
 func main() {
@@ -45,6 +49,7 @@ func main() {
 				Name:   "ls",
 				Usage:  "lists entities for a type",
 				Action: ls,
+				Flags:  listFlags("only list protected entities whose IDs, without the type, start with this prefix"),
 			},
 			{
 				Name:      "show",
@@ -57,6 +62,7 @@ func main() {
 				Usage:     "lists snapshots for a Protected Entity",
 				Action:    lssn,
 				ArgsUsage: "<protected entity id>",
+				Flags:     listFlags("only list snapshots whose snapshot IDs start with this prefix"),
 			},
 			{
 				Name:      "snap",
@@ -70,12 +76,91 @@ func main() {
 				Action:    rmsn,
 				ArgsUsage: "<protected entity snapshot id>",
 			},
+			{
+				Name:      "lsdel",
+				Usage:     "lists deleted snapshots of a Protected Entity that can still be undeleted",
+				Action:    lsdel,
+				ArgsUsage: "<protected entity id>",
+			},
+			{
+				Name:      "undelete",
+				Usage:     "restores a deleted Protected Entity snapshot",
+				Action:    undelete,
+				ArgsUsage: "<protected entity snapshot id>",
+			},
 			{
 				Name:      "cp",
 				Usage:     "copies a Protected Entity snapshot",
 				Action:    cp,
 				ArgsUsage: "<src> <dest>",
 			},
+			{
+				Name:  "retention",
+				Usage: "WORM retention and legal hold commands for snapshots in a repository",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "show",
+						Usage:     "shows the retention and legal hold for a snapshot",
+						Action:    retentionShow,
+						ArgsUsage: "<protected entity snapshot id>",
+					},
+					{
+						Name:      "extend",
+						Usage:     "extends the retention of a snapshot, retention cannot be shortened",
+						Action:    retentionExtend,
+						ArgsUsage: "<protected entity snapshot id>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "until",
+								Usage:    "new retain until time, RFC3339, e.g. 2021-01-02T15:04:05Z",
+								Required: true,
+							},
+						},
+					},
+					{
+						Name:      "hold",
+						Usage:     "places a legal hold on a snapshot",
+						Action:    retentionHold,
+						ArgsUsage: "<protected entity snapshot id>",
+					},
+					{
+						Name:      "release",
+						Usage:     "releases the legal hold on a snapshot",
+						Action:    retentionRelease,
+						ArgsUsage: "<protected entity snapshot id>",
+					},
+				},
+			},
+			{
+				Name:  "tasks",
+				Usage: "task commands",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "watch",
+						Usage:     "shows the live status and progress of a task, or of all tasks, needs --host",
+						Action:    tasksWatch,
+						ArgsUsage: "[<task id>]",
+					},
+				},
+			},
+			{
+				Name:  "repo",
+				Usage: "repository commands",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "stats",
+						Usage:     "shows logical and stored bytes per type, protected entity and snapshot as JSON",
+						Action:    repoStats,
+						ArgsUsage: "[<type>]",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "prefix",
+								Usage: "only report protected entities whose IDs start with this prefix",
+							},
+						},
+					},
+				},
+			},
 		},
 	}
 
@@ -85,6 +170,75 @@ func main() {
 	}
 }
 
+var selectorFlag = &cli.StringFlag{
+	Name:    "selector",
+	Aliases: []string{"l"},
+	Usage:   "label selector to filter on, e.g. app=nginx,tier!=frontend",
+}
+
+/*
+Flags for filtering, ordering and paging the ls and lssn output
+*/
+func listFlags(prefixUsage string) []cli.Flag {
+	return []cli.Flag{
+		selectorFlag,
+		&cli.StringFlag{
+			Name:  "prefix",
+			Usage: prefixUsage,
+		},
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "only list entries with this name",
+		},
+		&cli.StringFlag{
+			Name:  "sort-by",
+			Usage: "order by id or name",
+			Value: string(astrolabe.ListOrderByID),
+		},
+		&cli.BoolFlag{
+			Name:  "desc",
+			Usage: "list in descending order",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "list at most this many entries and print the token for the next page, 0 lists all entries",
+		},
+		&cli.StringFlag{
+			Name:  "continue",
+			Usage: "continuation token printed by a previous ls or lssn with the same ordering",
+		},
+	}
+}
+
+func listOptionsFromFlags(c *cli.Context) (astrolabe.ListOptions, error) {
+	selector, err := astrolabe.ParseLabelSelector(c.String("selector"))
+	if err != nil {
+		return astrolabe.ListOptions{}, err
+	}
+	options := astrolabe.ListOptions{
+		MaxResults:        c.Int("limit"),
+		ContinuationToken: c.String("continue"),
+		Prefix:            c.String("prefix"),
+		Name:              c.String("name"),
+		LabelSelector:     selector,
+		OrderBy:           astrolabe.ListOrderBy(c.String("sort-by")),
+		Descending:        c.Bool("desc"),
+	}
+	return options, options.Validate()
+}
+
+/*
+Prints the IDs of page, the continuation token goes to stderr so that the IDs can be piped
+*/
+func printPage(page astrolabe.ProtectedEntityIDPage) {
+	for _, curID := range page.IDs {
+		fmt.Println(curID.String())
+	}
+	if page.ContinuationToken != "" {
+		fmt.Fprintf(os.Stderr, "More entries are available, continue with --continue %s\n", page.ContinuationToken)
+	}
+}
+
 func setupProtectedEntityManager(c *cli.Context) (pem astrolabe.ProtectedEntityManager, err error) {
 	confDirStr := c.String("confDir")
 	if confDirStr != "" {
@@ -132,14 +286,15 @@ func ls(c *cli.Context) error {
 	if petm == nil {
 		log.Fatalf("Could not find type named %s", peType)
 	}
-	peIDs, err := petm.GetProtectedEntities(context.TODO())
+	options, err := listOptionsFromFlags(c)
 	if err != nil {
-		log.Fatalf("Could not retrieve protected entities for type %s err:%b", peType, err)
+		log.Fatalf("Could not parse list options, err: %v", err)
 	}
-
-	for _, curPEID := range peIDs {
-		fmt.Println(curPEID.String())
+	page, err := astrolabe.GetProtectedEntitiesPage(context.TODO(), petm, options)
+	if err != nil {
+		log.Fatalf("Could not retrieve protected entities for type %s err:%v", peType, err)
 	}
+	printPage(page)
 	return nil
 }
 
@@ -149,6 +304,10 @@ func lssn(c *cli.Context) error {
 	if err != nil {
 		log.Fatalf("Could not parse protected entity ID %s, err: %v", peIDStr, err)
 	}
+	options, err := listOptionsFromFlags(c)
+	if err != nil {
+		log.Fatalf("Could not parse list options, err: %v", err)
+	}
 
 	pem, err := setupProtectedEntityManager(c)
 	if err != nil {
@@ -160,15 +319,15 @@ func lssn(c *cli.Context) error {
 		log.Fatalf("Could not retrieve protected entity ID %s, err: %v", peIDStr, err)
 	}
 
-	snaps, err := pe.ListSnapshots(context.TODO())
+	petm := pem.GetProtectedEntityTypeManager(peID.GetPeType())
+	if petm == nil {
+		log.Fatalf("Could not find type named %s", peID.GetPeType())
+	}
+	page, err := astrolabe.ListSnapshotsPage(context.TODO(), petm, pe, options)
 	if err != nil {
 		log.Fatalf("Could not get snapshots for protected entity ID %s, err: %v", peIDStr, err)
 	}
-
-	for _, curSnapshotID := range snaps {
-		curPESnapshotID := peID.IDWithSnapshot(curSnapshotID)
-		fmt.Println(curPESnapshotID.String())
-	}
+	printPage(page)
 	return nil
 }
 
@@ -246,10 +405,61 @@ func rmsn(c *cli.Context) error {
 	}
 	if success {
 		log.Printf("Removed snapshot %s\n", peIDStr)
+		petm := astrolabe.UnwrapProtectedEntityTypeManager(pem.GetProtectedEntityTypeManager(peID.GetPeType()))
+		if _, ok := petm.(astrolabe.SoftDeleteManager); ok {
+			log.Printf("Use undelete to restore it before it is purged\n")
+		}
 	}
 	return nil
 }
 
+func setupSoftDeleteManager(c *cli.Context) (astrolabe.SoftDeleteManager, astrolabe.ProtectedEntityID) {
+	peIDStr := c.Args().First()
+	peID, err := astrolabe.NewProtectedEntityIDFromString(peIDStr)
+	if err != nil {
+		log.Fatalf("Could not parse protected entity ID %s, err: %v", peIDStr, err)
+	}
+	pem, err := setupProtectedEntityManager(c)
+	if err != nil {
+		log.Fatalf("Could not setup protected entity manager, err =%v", err)
+	}
+	petm := pem.GetProtectedEntityTypeManager(peID.GetPeType())
+	if petm == nil {
+		log.Fatalf("Could not find type %s", peID.GetPeType())
+	}
+	sdm, ok := astrolabe.UnwrapProtectedEntityTypeManager(petm).(astrolabe.SoftDeleteManager)
+	if !ok {
+		log.Fatalf("Type %s does not support undelete", peID.GetPeType())
+	}
+	return sdm, peID
+}
+
+func lsdel(c *cli.Context) error {
+	sdm, peID := setupSoftDeleteManager(c)
+	deletedSnapshots, err := sdm.ListDeletedSnapshots(context.TODO(), peID)
+	if err != nil {
+		log.Fatalf("Could not list deleted snapshots for %s, err: %v", peID.String(), err)
+	}
+	for _, deletedSnapshot := range deletedSnapshots {
+		fmt.Printf("%s deleted %s purge after %s\n", deletedSnapshot.ID.String(),
+			deletedSnapshot.DeletedAt.UTC().Format(time.RFC3339), deletedSnapshot.PurgeAfter.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+func undelete(c *cli.Context) error {
+	sdm, peID := setupSoftDeleteManager(c)
+	if !peID.HasSnapshot() {
+		log.Fatalf("Protected entity ID %s does not have a snapshot ID", peID.String())
+	}
+	err := sdm.UndeleteSnapshot(context.TODO(), peID)
+	if err != nil {
+		log.Fatalf("Could not undelete snapshot %s, err: %v", peID.String(), err)
+	}
+	log.Printf("Undeleted snapshot %s\n", peID.String())
+	return nil
+}
+
 func cp(c *cli.Context) error {
 	if c.NArg() != 2 {
 		log.Fatalf("Expected two arguments for cp, got %d", c.NArg())
@@ -307,6 +517,160 @@ func cp(c *cli.Context) error {
 	return nil
 }
 
+func repoStats(c *cli.Context) error {
+	pem, err := setupProtectedEntityManager(c)
+	if err != nil {
+		log.Fatalf("Could not setup protected entity manager, err =%v", err)
+	}
+	usage, err := astrolabe.GetRepositoryUsage(context.TODO(), pem, c.Args().First(), c.String("prefix"))
+	if err != nil {
+		log.Fatalf("Could not retrieve repository usage, err: %v", err)
+	}
+	usageJSON, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		log.Fatalf("Could not marshal repository usage, err: %v", err)
+	}
+	fmt.Println(string(usageJSON))
+	return nil
+}
+
+// Width of the progress bar shown by tasks watch
+const progressBarWidth = 20
+
+func tasksWatch(c *cli.Context) error {
+	pem, err := setupProtectedEntityManager(c)
+	if err != nil {
+		log.Fatalf("Could not setup protected entity manager, err =%v", err)
+	}
+	clientPEM, ok := pem.(*astrolabeClient.ClientProtectedEntityManager)
+	if !ok {
+		log.Fatalf("Watching tasks needs an astrolabe server, use --host")
+	}
+	taskID := c.Args().First()
+	lastLength := 0
+	err = clientPEM.WatchTaskEvents(context.TODO(), taskID, func(event astrolabeClient.TaskEvent) bool {
+		line := formatTaskProgress(event)
+		if taskID == "" {
+			fmt.Println(line)
+			return true
+		}
+		// Redraw the line of the task in place, padding over the end of the previous one
+		padding := ""
+		if lastLength > len(line) {
+			padding = strings.Repeat(" ", lastLength-len(line))
+		}
+		fmt.Print("\r" + line + padding)
+		lastLength = len(line)
+		return true
+	})
+	if taskID != "" {
+		fmt.Println()
+	}
+	if err != nil {
+		log.Fatalf("Could not watch tasks, err: %v", err)
+	}
+	return nil
+}
+
+func formatTaskProgress(event astrolabeClient.TaskEvent) string {
+	info := event.Info
+	progress := swag.Float64Value(info.Progress)
+	filled := int(progress / 100 * progressBarWidth)
+	if filled < 0 {
+		filled = 0
+	} else if filled > progressBarWidth {
+		filled = progressBarWidth
+	}
+	line := fmt.Sprintf("%s %-9s [%s%s] %5.1f%%", info.ID, swag.StringValue(info.Status),
+		strings.Repeat("#", filled), strings.Repeat("-", progressBarWidth-filled), progress)
+	if info.QueuePosition > 0 {
+		line += fmt.Sprintf(" queued at %d", info.QueuePosition)
+	}
+	for _, lock := range info.Locks {
+		if swag.StringValue(lock.State) == server.LockWaiting {
+			line += fmt.Sprintf(" waiting for %s lock on %s", swag.StringValue(lock.Mode),
+				lock.ProtectedEntityID)
+		}
+	}
+	if info.Details != "" {
+		line += " " + info.Details
+	}
+	return line
+}
+
+func setupRetentionManager(c *cli.Context) (astrolabe.RetentionManager, astrolabe.ProtectedEntityID) {
+	peIDStr := c.Args().First()
+	peID, err := astrolabe.NewProtectedEntityIDFromString(peIDStr)
+	if err != nil {
+		log.Fatalf("Could not parse protected entity ID %s, err: %v", peIDStr, err)
+	}
+	if !peID.HasSnapshot() {
+		log.Fatalf("Protected entity ID %s does not have a snapshot ID", peIDStr)
+	}
+	pem, err := setupProtectedEntityManager(c)
+	if err != nil {
+		log.Fatalf("Could not setup protected entity manager, err =%v", err)
+	}
+	petm := pem.GetProtectedEntityTypeManager(peID.GetPeType())
+	if petm == nil {
+		log.Fatalf("Could not find type %s", peID.GetPeType())
+	}
+	rm, ok := astrolabe.UnwrapProtectedEntityTypeManager(petm).(astrolabe.RetentionManager)
+	if !ok {
+		log.Fatalf("Type %s does not support retention", peID.GetPeType())
+	}
+	return rm, peID
+}
+
+func printRetention(peID astrolabe.ProtectedEntityID, retention astrolabe.Retention) {
+	fmt.Printf("%s\n", peID.String())
+	fmt.Printf("Mode: %s\n", retention.Mode.String())
+	if retention.Mode != astrolabe.RetentionModeNone {
+		fmt.Printf("Retain until: %s\n", retention.RetainUntil.UTC().Format(time.RFC3339))
+	}
+	fmt.Printf("Legal hold: %t\n", retention.LegalHold)
+}
+
+func retentionShow(c *cli.Context) error {
+	rm, peID := setupRetentionManager(c)
+	retention, err := rm.GetRetention(context.TODO(), peID)
+	if err != nil {
+		log.Fatalf("Could not retrieve retention for %s, err: %v", peID.String(), err)
+	}
+	printRetention(peID, retention)
+	return nil
+}
+
+func retentionExtend(c *cli.Context) error {
+	retainUntil, err := time.Parse(time.RFC3339, c.String("until"))
+	if err != nil {
+		log.Fatalf("Could not parse retain until time %s, err: %v", c.String("until"), err)
+	}
+	rm, peID := setupRetentionManager(c)
+	err = rm.ExtendRetention(context.TODO(), peID, retainUntil)
+	if err != nil {
+		log.Fatalf("Could not extend retention for %s, err: %v", peID.String(), err)
+	}
+	return retentionShow(c)
+}
+
+func retentionHold(c *cli.Context) error {
+	return setLegalHold(c, true)
+}
+
+func retentionRelease(c *cli.Context) error {
+	return setLegalHold(c, false)
+}
+
+func setLegalHold(c *cli.Context, legalHold bool) error {
+	rm, peID := setupRetentionManager(c)
+	err := rm.SetLegalHold(context.TODO(), peID, legalHold)
+	if err != nil {
+		log.Fatalf("Could not set legal hold for %s, err: %v", peID.String(), err)
+	}
+	return retentionShow(c)
+}
+
 func zipPE(ctx context.Context, pe astrolabe.ProtectedEntity, writer io.WriteCloser) {
 	defer writer.Close()
 	err := astrolabe.ZipProtectedEntity(ctx, pe, writer)
@@ -0,0 +1,320 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+astrolabe_example_plugin is an example of a ProtectedEntityTypeManager plugin.  It keeps its Protected Entities in
+memory.  Copy it into the plugins directory of the astrolabe_server configuration directory to load it, and add
+astrolabe_example_plugin.json to set its parameters:
+
+	{"typeName": "example"}
+*/
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"github.com/vmware-tanzu/astrolabe/pkg/plugin"
+)
+
+func main() {
+	plugin.Serve(newMemoryTypeManager)
+}
+
+type memoryTypeManager struct {
+	mutex    sync.Mutex
+	typeName string
+	entities map[string]*memoryEntity
+	logger   logrus.FieldLogger
+}
+
+/*
+memoryEntity is the current state of a Protected Entity and its snapshots
+*/
+type memoryEntity struct {
+	name      string
+	state     memoryState
+	snapshots map[string]memoryState
+}
+
+type memoryState struct {
+	data     []byte
+	metadata []byte
+}
+
+func newMemoryTypeManager(params map[string]interface{}, s3Config astrolabe.S3Config,
+	logger logrus.FieldLogger) (astrolabe.ProtectedEntityTypeManager, error) {
+	typeName := "example"
+	if paramTypeName, ok := params["typeName"]; ok {
+		typeName, ok = paramTypeName.(string)
+		if !ok {
+			return nil, errors.New("typeName must be a string")
+		}
+	}
+	return &memoryTypeManager{
+		typeName: typeName,
+		entities: map[string]*memoryEntity{},
+		logger:   logger,
+	}, nil
+}
+
+func (this *memoryTypeManager) GetTypeName() string {
+	return this.typeName
+}
+
+func (this *memoryTypeManager) GetProtectedEntity(ctx context.Context,
+	id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	entity, ok := this.entities[id.GetID()]
+	if !ok {
+		return nil, errors.Errorf("protected entity %s not found", id.String())
+	}
+	if id.HasSnapshot() {
+		if _, ok := entity.snapshots[id.GetSnapshotID().String()]; !ok {
+			return nil, errors.Errorf("snapshot %s not found", id.String())
+		}
+	}
+	return &memoryProtectedEntity{
+		petm: this,
+		id:   id,
+	}, nil
+}
+
+func (this *memoryTypeManager) GetProtectedEntities(ctx context.Context) ([]astrolabe.ProtectedEntityID, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	ids := []astrolabe.ProtectedEntityID{}
+	for id := range this.entities {
+		ids = append(ids, astrolabe.NewProtectedEntityID(this.typeName, id))
+	}
+	return ids, nil
+}
+
+func (this *memoryTypeManager) Copy(ctx context.Context, pe astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	if options != astrolabe.AllocateNewObject {
+		return nil, errors.New("only AllocateNewObject is supported")
+	}
+	info, err := pe.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state, err := readState(ctx, pe)
+	if err != nil {
+		return nil, err
+	}
+	id := astrolabe.NewProtectedEntityID(this.typeName, uuid.New().String())
+	this.mutex.Lock()
+	this.entities[id.GetID()] = &memoryEntity{
+		name:      info.GetName(),
+		state:     state,
+		snapshots: map[string]memoryState{},
+	}
+	this.mutex.Unlock()
+	this.logger.Infof("Copied %s to %s", pe.GetID().String(), id.String())
+	return &memoryProtectedEntity{
+		petm: this,
+		id:   id,
+	}, nil
+}
+
+func (this *memoryTypeManager) CopyFromInfo(ctx context.Context, info astrolabe.ProtectedEntityInfo,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	return nil, errors.New("CopyFromInfo is not supported")
+}
+
+/*
+readState reads the data and metadata of pe
+*/
+func readState(ctx context.Context, pe astrolabe.ProtectedEntity) (memoryState, error) {
+	state := memoryState{}
+	metadataReader, err := pe.GetMetadataReader(ctx)
+	if err != nil {
+		return state, err
+	}
+	if metadataReader != nil {
+		defer metadataReader.Close()
+		if state.metadata, err = ioutil.ReadAll(metadataReader); err != nil {
+			return state, err
+		}
+	}
+	dataReader, err := pe.GetDataReader(ctx)
+	if err != nil {
+		return state, err
+	}
+	if dataReader != nil {
+		defer dataReader.Close()
+		if state.data, err = ioutil.ReadAll(dataReader); err != nil {
+			return state, err
+		}
+	}
+	return state, nil
+}
+
+type memoryProtectedEntity struct {
+	petm *memoryTypeManager
+	id   astrolabe.ProtectedEntityID
+}
+
+/*
+getState returns the name and the state of the Protected Entity, or of its snapshot if the ID has one
+*/
+func (this *memoryProtectedEntity) getState() (string, memoryState, error) {
+	this.petm.mutex.Lock()
+	defer this.petm.mutex.Unlock()
+	entity, ok := this.petm.entities[this.id.GetID()]
+	if !ok {
+		return "", memoryState{}, errors.Errorf("protected entity %s not found", this.id.String())
+	}
+	if !this.id.HasSnapshot() {
+		return entity.name, entity.state, nil
+	}
+	state, ok := entity.snapshots[this.id.GetSnapshotID().String()]
+	if !ok {
+		return "", memoryState{}, errors.Errorf("snapshot %s not found", this.id.String())
+	}
+	return entity.name, state, nil
+}
+
+func (this *memoryProtectedEntity) GetID() astrolabe.ProtectedEntityID {
+	return this.id
+}
+
+func (this *memoryProtectedEntity) GetInfo(ctx context.Context) (astrolabe.ProtectedEntityInfo, error) {
+	name, _, err := this.getState()
+	if err != nil {
+		return nil, err
+	}
+	return astrolabe.NewProtectedEntityInfo(this.id, name, []astrolabe.DataTransport{},
+		[]astrolabe.DataTransport{}, []astrolabe.DataTransport{}, []astrolabe.ProtectedEntityID{}), nil
+}
+
+func (this *memoryProtectedEntity) GetCombinedInfo(ctx context.Context) ([]astrolabe.ProtectedEntityInfo, error) {
+	info, err := this.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []astrolabe.ProtectedEntityInfo{info}, nil
+}
+
+func (this *memoryProtectedEntity) Snapshot(ctx context.Context,
+	params map[string]map[string]interface{}) (astrolabe.ProtectedEntitySnapshotID, error) {
+	if this.id.HasSnapshot() {
+		return astrolabe.ProtectedEntitySnapshotID{}, errors.New("cannot snapshot a snapshot")
+	}
+	_, state, err := this.getState()
+	if err != nil {
+		return astrolabe.ProtectedEntitySnapshotID{}, err
+	}
+	snapshotID := astrolabe.NewProtectedEntitySnapshotID(uuid.New().String())
+	this.petm.mutex.Lock()
+	defer this.petm.mutex.Unlock()
+	entity, ok := this.petm.entities[this.id.GetID()]
+	if !ok {
+		return astrolabe.ProtectedEntitySnapshotID{}, errors.Errorf("protected entity %s not found", this.id.String())
+	}
+	entity.snapshots[snapshotID.String()] = state
+	return snapshotID, nil
+}
+
+func (this *memoryProtectedEntity) ListSnapshots(ctx context.Context) ([]astrolabe.ProtectedEntitySnapshotID, error) {
+	this.petm.mutex.Lock()
+	defer this.petm.mutex.Unlock()
+	entity, ok := this.petm.entities[this.id.GetID()]
+	if !ok {
+		return nil, errors.Errorf("protected entity %s not found", this.id.String())
+	}
+	snapshotIDs := []astrolabe.ProtectedEntitySnapshotID{}
+	for snapshotID := range entity.snapshots {
+		snapshotIDs = append(snapshotIDs, astrolabe.NewProtectedEntitySnapshotID(snapshotID))
+	}
+	return snapshotIDs, nil
+}
+
+func (this *memoryProtectedEntity) DeleteSnapshot(ctx context.Context,
+	snapshotToDelete astrolabe.ProtectedEntitySnapshotID, params map[string]map[string]interface{}) (bool, error) {
+	this.petm.mutex.Lock()
+	defer this.petm.mutex.Unlock()
+	entity, ok := this.petm.entities[this.id.GetID()]
+	if !ok {
+		return false, errors.Errorf("protected entity %s not found", this.id.String())
+	}
+	if _, ok := entity.snapshots[snapshotToDelete.String()]; !ok {
+		return false, nil
+	}
+	delete(entity.snapshots, snapshotToDelete.String())
+	return true, nil
+}
+
+func (this *memoryProtectedEntity) GetInfoForSnapshot(ctx context.Context,
+	snapshotID astrolabe.ProtectedEntitySnapshotID) (*astrolabe.ProtectedEntityInfo, error) {
+	snapshotPE := &memoryProtectedEntity{
+		petm: this.petm,
+		id:   this.id.IDWithSnapshot(snapshotID),
+	}
+	info, err := snapshotPE.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (this *memoryProtectedEntity) GetComponents(ctx context.Context) ([]astrolabe.ProtectedEntity, error) {
+	return []astrolabe.ProtectedEntity{}, nil
+}
+
+func (this *memoryProtectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	_, state, err := this.getState()
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(state.data)), nil
+}
+
+func (this *memoryProtectedEntity) GetMetadataReader(ctx context.Context) (io.ReadCloser, error) {
+	_, state, err := this.getState()
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(state.metadata)), nil
+}
+
+func (this *memoryProtectedEntity) Overwrite(ctx context.Context, sourcePE astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, overwriteComponents bool) error {
+	if this.id.HasSnapshot() {
+		return errors.New("cannot overwrite a snapshot")
+	}
+	state, err := readState(ctx, sourcePE)
+	if err != nil {
+		return err
+	}
+	this.petm.mutex.Lock()
+	defer this.petm.mutex.Unlock()
+	entity, ok := this.petm.entities[this.id.GetID()]
+	if !ok {
+		return errors.Errorf("protected entity %s not found", this.id.String())
+	}
+	entity.state = state
+	return nil
+}
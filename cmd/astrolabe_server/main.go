@@ -17,13 +17,29 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"flag"
 	"github.com/go-openapi/loads"
+	"github.com/go-openapi/runtime/middleware"
+	flags "github.com/jessevdk/go-flags"
+	"github.com/sirupsen/logrus"
 	"github.com/vmware-tanzu/astrolabe/gen/restapi"
 	"github.com/vmware-tanzu/astrolabe/gen/restapi/operations"
+	"github.com/vmware-tanzu/astrolabe/pkg/metrics"
+	"github.com/vmware-tanzu/astrolabe/pkg/plugin"
 	"github.com/vmware-tanzu/astrolabe/pkg/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	//"github.com/labstack/gommon/log"
 	"log"
@@ -33,6 +49,23 @@ func main() {
 	confDirStr := flag.String("confDir", "", "Configuration directory")
 	apiPortStr := flag.String("apiPort", "1323", "REST API port")
 	insecure := flag.Bool("insecure", false, "Only use HTTP")
+	s3PortStr := flag.String("s3Port", "", "S3 data path port, defaults to the port in s3config.json")
+	tlsPortStr := flag.String("tlsPort", "1324", "REST API HTTPS port")
+	tlsCert := flag.String("tlsCert", "", "TLS certificate file")
+	tlsKey := flag.String("tlsKey", "", "TLS private key file")
+	grpcPortStr := flag.String("grpcPort", "1325", "gRPC API port, the gRPC API is not served if empty")
+	auditDir := flag.String("auditDir", "", "Directory for the audit log, mutating calls are not audited if not set")
+	auditMaxFileMB := flag.Int64("auditMaxFileMB", server.DefaultAuditMaxFileSize/(1024*1024),
+		"Size in MB at which a new audit file is started")
+	auditMaxFiles := flag.Int("auditMaxFiles", 0, "Number of audit files to keep, 0 keeps all")
+	drainTimeout := flag.Duration("drainTimeout", 5*time.Minute,
+		"How long running tasks are given to finish on SIGTERM or SIGINT before they are cancelled")
+	cancelTimeout := flag.Duration("cancelTimeout", 30*time.Second,
+		"How long cancelled tasks and open requests are given to finish when shutting down")
+	idempotencyWindow := flag.Duration("idempotencyWindow", server.DefaultIdempotencyWindow,
+		"How long the results of mutating calls made with an idempotency key are kept, 0 ignores the keys")
+	lockWait := flag.Duration("lockWait", server.DefaultLockWait,
+		"How long a snapshot, delete or copy waits for conflicting operations on its protected entity, 0 fails at once")
 	flag.Parse()
 	if *confDirStr == "" {
 		log.Println("confDir is not defined")
@@ -44,18 +77,240 @@ func main() {
 		log.Printf("apiPort %s is not an integer\n", *apiPortStr)
 		os.Exit(1)
 	}
-	pem := server.NewProtectedEntityManager(*confDirStr)
+	// Failures from here on set exitCode and return, so that the deferred cleanups run before the process exits
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+	pem := server.NewDirectProtectedEntityManagerFromConfigDir(*confDirStr)
+	// Ends the vCenter and VDDK sessions once everything else has stopped
+	defer func() {
+		if err := pem.Close(); err != nil {
+			log.Println(err)
+		}
+	}()
+	// SIGHUP reloads the protected entity service configuration, s3config.json, auth.json, webhooks.json, admission.json
+	// and servers.json need a restart
+	reloadSignal := make(chan os.Signal, 1)
+	signal.Notify(reloadSignal, syscall.SIGHUP)
+	go func() {
+		for range reloadSignal {
+			result, err := pem.ReloadFromConfigDir(*confDirStr)
+			if err != nil {
+				log.Printf("Could not reload configuration from %s, err: %v\n", *confDirStr, err)
+				continue
+			}
+			for serviceName, err := range result.Failed {
+				log.Printf("Could not reload service %s, err: %v\n", serviceName, err)
+			}
+		}
+	}()
+	s3Config := pem.GetS3Config()
+	// Plugin binaries in the plugins directory serve additional types
+	pluginDir := filepath.Join(*confDirStr, "plugins")
+	if _, err := os.Stat(pluginDir); err == nil {
+		pluginHost := plugin.NewHost(s3Config, logrus.New())
+		defer pluginHost.Close()
+		petms, err := pluginHost.LoadDir(context.Background(), pluginDir)
+		if err != nil {
+			log.Println(err)
+			exitCode = 1
+			return
+		}
+		pem.RegisterExternalProtectedEntityTypeManagers(petms)
+	}
+	s3Port := s3Config.Port
+	if *s3PortStr != "" {
+		s3Port, err = strconv.Atoi(*s3PortStr)
+		if err != nil {
+			log.Printf("s3Port %s is not an integer\n", *s3PortStr)
+			os.Exit(1)
+		}
+	}
+	tlsPort, err := strconv.Atoi(*tlsPortStr)
+	if err != nil {
+		log.Printf("tlsPort %s is not an integer\n", *tlsPortStr)
+		os.Exit(1)
+	}
+	grpcPort := 0
+	if *grpcPortStr != "" {
+		grpcPort, err = strconv.Atoi(*grpcPortStr)
+		if err != nil {
+			log.Printf("grpcPort %s is not an integer\n", *grpcPortStr)
+			os.Exit(1)
+		}
+	}
+	auth, err := server.NewAuthManagerFromConfigDir(*confDirStr, logrus.New())
+	if err != nil {
+		log.Println(err)
+		exitCode = 1
+		return
+	}
+	if auth == nil {
+		log.Printf("No auth.json in %s, requests will not be authenticated\n", *confDirStr)
+	}
+	// Snapshots and copies are limited by the service and datastore limits in admission.json
+	admission, err := server.NewAdmissionControllerFromConfigDir(*confDirStr, logrus.New())
+	if err != nil {
+		log.Println(err)
+		exitCode = 1
+		return
+	}
+	if admission != nil {
+		pem.SetAdmissionController(admission)
+	}
+	// Conflicting operations on a protected entity wait for each other
+	pem.SetLockManager(server.NewLockManager(*lockWait, logrus.New()))
+	// Components on other astrolabe servers are resolved through the servers in servers.json
+	federation, err := server.NewFederationFromConfigDir(*confDirStr, logrus.New())
+	if err != nil {
+		log.Println(err)
+		exitCode = 1
+		return
+	}
+	pem.SetFederation(federation)
 	tm := server.NewTaskManager()
+	tm.SetIdempotencyWindow(*idempotencyWindow)
+	// Type managers replaced by a SIGHUP reload are closed once the tasks running at the reload have finished
+	pem.SetTaskManager(tm)
+	// SIGTERM and SIGINT drain the server before it stops, see api.PreServerShutdown below
+	drainer := server.NewDrainer(tm, logrus.New())
+	healthHandler := server.NewHealthHandler(pem, drainer)
+	// Task, snapshot and copy events are delivered to the webhooks in webhooks.json
+	events := server.NewEventBus(logrus.New())
+	pem.SetEventBus(events)
+	tm.SetEventBus(events)
+	notifier, err := server.NewWebhookNotifierFromConfigDir(*confDirStr, logrus.New())
+	if err != nil {
+		log.Println(err)
+		exitCode = 1
+		return
+	}
+	if notifier != nil {
+		events.Subscribe(notifier.Notify)
+		defer notifier.Close()
+	}
 	apiHandler := server.NewOpenAPIAstrolabeHandler(pem, tm)
+	s3Service := server.NewServiceS3(pem, tm, s3Config.Prefix, logrus.New())
+	s3Service.SetDrainer(drainer)
+	var audit *server.AuditLog
+	if *auditDir != "" {
+		audit, err = server.NewAuditLog(server.AuditConfig{
+			Dir:         *auditDir,
+			MaxFileSize: *auditMaxFileMB * 1024 * 1024,
+			MaxFiles:    *auditMaxFiles,
+		}, logrus.New())
+		if err != nil {
+			log.Println(err)
+			exitCode = 1
+			return
+		}
+		defer audit.Close()
+		audit.SetTaskManager(tm)
+		apiHandler.SetAuditLog(audit)
+		s3Service.SetAuditLog(audit)
+	}
+	// The gRPC and S3 listeners report the errors that stop them here, which stops the API server as well
+	serveErrors := make(chan error, 2)
+	// The gRPC API is served on its own port with the same TLS certificate, authentication and audit log
+	if grpcPort != 0 {
+		grpcHandler := server.NewGRPCAstrolabeHandler(pem, tm)
+		grpcHandler.SetDrainer(drainer)
+		if auth != nil {
+			grpcHandler.SetAuthManager(auth)
+		}
+		if audit != nil {
+			grpcHandler.SetAuditLog(audit)
+		}
+		var serverOptions []grpc.ServerOption
+		if !*insecure && *tlsCert != "" {
+			cert, err := tls.LoadX509KeyPair(*tlsCert, *tlsKey)
+			if err != nil {
+				log.Println(err)
+				exitCode = 1
+				return
+			}
+			tlsConfig := &tls.Config{
+				Certificates: []tls.Certificate{cert},
+			}
+			if auth != nil {
+				auth.ConfigureTLS(tlsConfig)
+			}
+			serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+		grpcServer := grpc.NewServer(serverOptions...)
+		grpcHandler.Register(grpcServer)
+		grpcListener, err := net.Listen("tcp", ":"+strconv.Itoa(grpcPort))
+		if err != nil {
+			log.Println(err)
+			exitCode = 1
+			return
+		}
+		go func() {
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				serveErrors <- err
+			}
+		}()
+		defer stopGRPC(grpcServer, *cancelTimeout)
+	}
+	// Authenticate wraps everything, the OpenAPI and S3 handlers authorize the requests.  Mutating OpenAPI calls are
+	// rejected while draining and replayed if their idempotency key was used before.
+	var builder middleware.Builder = func(handler http.Handler) http.Handler {
+		return drainer.OpenAPIMiddleware(tm.IdempotencyMiddleware(handler))
+	}
+	wrapAuth := func(handler http.Handler) http.Handler { return handler }
+	metricsHandler := metrics.Handler()
+	if auth != nil {
+		s3Service.SetAuthManager(auth)
+		healthHandler.SetAuthManager(auth)
+		builder = func(handler http.Handler) http.Handler {
+			return auth.OpenAPIMiddleware(drainer.OpenAPIMiddleware(tm.IdempotencyMiddleware(handler)))
+		}
+		wrapAuth = auth.Authenticate
+		restapi.TLSConfigurer = auth.ConfigureTLS
+		metricsHandler = auth.RequireAuthentication(metricsHandler)
+	}
+	if audit != nil {
+		// Audit outside of the authorization so that denied calls are recorded
+		authBuilder := builder
+		builder = func(handler http.Handler) http.Handler {
+			if authBuilder != nil {
+				handler = authBuilder(handler)
+			}
+			return audit.OpenAPIMiddleware(handler)
+		}
+	}
+	// The metrics and the unauthenticated health probes are served from the API listener, ahead of the S3 data path
+	// and the API
+	withMetrics := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == metrics.Path {
+				metricsHandler.ServeHTTP(w, r)
+				return
+			}
+			healthHandler.Handler(next).ServeHTTP(w, r)
+		})
+	}
 	// load embedded swagger file
 	swaggerSpec, err := loads.Analyzed(restapi.SwaggerJSON, "")
 	if err != nil {
-		log.Fatalln(err)
+		log.Println(err)
+		exitCode = 1
+		return
 	}
 
 	// create new service API
 	api := operations.NewAstrolabeAPI(swaggerSpec)
+	// The server stops listening once the running tasks have finished or been cancelled
+	api.PreServerShutdown = func() {
+		if err := drainer.Drain(*drainTimeout, *cancelTimeout); err != nil {
+			log.Println(err)
+		}
+	}
 	server := restapi.NewServer(api)
+	server.GracefulTimeout = *cancelTimeout
 	if *insecure {
 		server.EnabledListeners = []string{"http"}
 	}
@@ -65,11 +320,89 @@ func main() {
 	flag.Parse()
 	// set the port this service will be run on
 	server.Port = apiPort
+	server.TLSPort = tlsPort
+	server.TLSCertificate = flags.Filename(*tlsCert)
+	server.TLSCertificateKey = flags.Filename(*tlsKey)
+	if !*insecure && *tlsCert == "" {
+		log.Println("tlsCert is not defined, only using HTTP")
+		server.EnabledListeners = []string{"http"}
+	}
 
 	apiHandler.AttachHandlers(api)
 
+	// The S3 transports of the protected entities point at the S3 data path, either share the API listener or start
+	// a listener on its own port
+	if s3Port == 0 || s3Port == apiPort {
+		if strings.Trim(s3Config.Prefix, "/") == "" {
+			log.Println("s3config.json must set a prefix when the S3 data path shares the API port")
+			exitCode = 1
+			return
+		}
+		server.SetHandler(wrapAuth(withMetrics(s3Service.Handler(api.Serve(builder)))))
+	} else {
+		server.SetHandler(wrapAuth(withMetrics(api.Serve(builder))))
+		s3Server := &http.Server{
+			Addr:      ":" + strconv.Itoa(s3Port),
+			Handler:   wrapAuth(s3Service.Handler(http.NotFoundHandler())),
+			TLSConfig: &tls.Config{},
+		}
+		if auth != nil {
+			auth.ConfigureTLS(s3Server.TLSConfig)
+		}
+		go func() {
+			var err error
+			if *insecure || s3Config.UseHttp || *tlsCert == "" {
+				err = s3Server.ListenAndServe()
+			} else {
+				err = s3Server.ListenAndServeTLS(string(server.TLSCertificate), string(server.TLSCertificateKey))
+			}
+			if err != nil && err != http.ErrServerClosed {
+				serveErrors <- err
+			}
+		}()
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), *cancelTimeout)
+			defer cancel()
+			if err := s3Server.Shutdown(ctx); err != nil {
+				s3Server.Close()
+			}
+		}()
+	}
+
+	serveFailed := make(chan error, 1)
+	go func() {
+		err := <-serveErrors
+		serveFailed <- err
+		server.Shutdown()
+	}()
+
 	// serve API
 	if err := server.Serve(); err != nil {
-		log.Fatalln(err)
+		log.Println(err)
+		exitCode = 1
+		return
+	}
+	select {
+	case err := <-serveFailed:
+		log.Println(err)
+		exitCode = 1
+		return
+	default:
+	}
+}
+
+/*
+stopGRPC lets the running gRPC calls finish for up to timeout and then closes the connections
+*/
+func stopGRPC(grpcServer *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		grpcServer.Stop()
 	}
 }
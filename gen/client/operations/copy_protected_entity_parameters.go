@@ -62,6 +62,16 @@ for the copy protected entity operation typically these are written to a http.Re
 */
 type CopyProtectedEntityParams struct {
 
+	/*IdempotencyKey
+	  A key chosen by the client that identifies this request.  If a
+	request with the same key has already completed successfully within
+	the server's idempotency window, its result is returned instead of
+	running the operation again.  Keys are scoped to the caller and
+	cannot be reused for a different request.
+
+
+	*/
+	IdempotencyKey *string
 	/*Body
 	  Copy Parameters including protected entity to copy
 
@@ -125,6 +135,17 @@ func (o *CopyProtectedEntityParams) SetHTTPClient(client *http.Client) {
 	o.HTTPClient = client
 }
 
+// WithIdempotencyKey adds the idempotencyKey to the copy protected entity params
+func (o *CopyProtectedEntityParams) WithIdempotencyKey(idempotencyKey *string) *CopyProtectedEntityParams {
+	o.SetIdempotencyKey(idempotencyKey)
+	return o
+}
+
+// SetIdempotencyKey adds the idempotencyKey to the copy protected entity params
+func (o *CopyProtectedEntityParams) SetIdempotencyKey(idempotencyKey *string) {
+	o.IdempotencyKey = idempotencyKey
+}
+
 // WithBody adds the body to the copy protected entity params
 func (o *CopyProtectedEntityParams) WithBody(body *models.CopyParameters) *CopyProtectedEntityParams {
 	o.SetBody(body)
@@ -166,6 +187,15 @@ func (o *CopyProtectedEntityParams) WriteToRequest(r runtime.ClientRequest, reg
 	}
 	var res []error
 
+	if o.IdempotencyKey != nil {
+
+		// header param Idempotency-Key
+		if err := r.SetHeaderParam("Idempotency-Key", *o.IdempotencyKey); err != nil {
+			return err
+		}
+
+	}
+
 	if o.Body != nil {
 		if err := r.SetBodyParam(o.Body); err != nil {
 			return err
@@ -29,6 +29,18 @@ func (o *CopyProtectedEntityReader) ReadResponse(response runtime.ClientResponse
 			return nil, err
 		}
 		return result, nil
+	case 400:
+		result := NewCopyProtectedEntityBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 404:
+		result := NewCopyProtectedEntityNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
 
 	default:
 		return nil, runtime.NewAPIError("unknown error", response, response.Code())
@@ -67,3 +79,69 @@ func (o *CopyProtectedEntityAccepted) readResponse(response runtime.ClientRespon
 
 	return nil
 }
+
+// NewCopyProtectedEntityBadRequest creates a CopyProtectedEntityBadRequest with default headers values
+func NewCopyProtectedEntityBadRequest() *CopyProtectedEntityBadRequest {
+	return &CopyProtectedEntityBadRequest{}
+}
+
+/*CopyProtectedEntityBadRequest handles this case with default header values.
+
+Invalid copy parameters or protected entity info
+*/
+type CopyProtectedEntityBadRequest struct {
+	Payload *models.Error
+}
+
+func (o *CopyProtectedEntityBadRequest) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}][%d] copyProtectedEntityBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *CopyProtectedEntityBadRequest) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *CopyProtectedEntityBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewCopyProtectedEntityNotFound creates a CopyProtectedEntityNotFound with default headers values
+func NewCopyProtectedEntityNotFound() *CopyProtectedEntityNotFound {
+	return &CopyProtectedEntityNotFound{}
+}
+
+/*CopyProtectedEntityNotFound handles this case with default header values.
+
+Service not found
+*/
+type CopyProtectedEntityNotFound struct {
+	Payload *models.Error
+}
+
+func (o *CopyProtectedEntityNotFound) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}][%d] copyProtectedEntityNotFound  %+v", 404, o.Payload)
+}
+
+func (o *CopyProtectedEntityNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *CopyProtectedEntityNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
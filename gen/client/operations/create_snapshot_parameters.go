@@ -62,6 +62,16 @@ for the create snapshot operation typically these are written to a http.Request
 */
 type CreateSnapshotParams struct {
 
+	/*IdempotencyKey
+	  A key chosen by the client that identifies this request.  If a
+	request with the same key has already completed successfully within
+	the server's idempotency window, its result is returned instead of
+	running the operation again.  Keys are scoped to the caller and
+	cannot be reused for a different request.
+
+
+	*/
+	IdempotencyKey *string
 	/*Params
 	  Parameters for the snapshot.
 
@@ -116,6 +126,17 @@ func (o *CreateSnapshotParams) SetHTTPClient(client *http.Client) {
 	o.HTTPClient = client
 }
 
+// WithIdempotencyKey adds the idempotencyKey to the create snapshot params
+func (o *CreateSnapshotParams) WithIdempotencyKey(idempotencyKey *string) *CreateSnapshotParams {
+	o.SetIdempotencyKey(idempotencyKey)
+	return o
+}
+
+// SetIdempotencyKey adds the idempotencyKey to the create snapshot params
+func (o *CreateSnapshotParams) SetIdempotencyKey(idempotencyKey *string) {
+	o.IdempotencyKey = idempotencyKey
+}
+
 // WithParams adds the params to the create snapshot params
 func (o *CreateSnapshotParams) WithParams(params models.OperationParamList) *CreateSnapshotParams {
 	o.SetParams(params)
@@ -157,6 +178,15 @@ func (o *CreateSnapshotParams) WriteToRequest(r runtime.ClientRequest, reg strfm
 	}
 	var res []error
 
+	if o.IdempotencyKey != nil {
+
+		// header param Idempotency-Key
+		if err := r.SetHeaderParam("Idempotency-Key", *o.IdempotencyKey); err != nil {
+			return err
+		}
+
+	}
+
 	if o.Params != nil {
 		if err := r.SetBodyParam(o.Params); err != nil {
 			return err
@@ -29,6 +29,30 @@ func (o *CreateSnapshotReader) ReadResponse(response runtime.ClientResponse, con
 			return nil, err
 		}
 		return result, nil
+	case 400:
+		result := NewCreateSnapshotBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 404:
+		result := NewCreateSnapshotNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 409:
+		result := NewCreateSnapshotConflict()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 500:
+		result := NewCreateSnapshotInternalServerError()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
 
 	default:
 		return nil, runtime.NewAPIError("unknown error", response, response.Code())
@@ -65,3 +89,135 @@ func (o *CreateSnapshotOK) readResponse(response runtime.ClientResponse, consume
 
 	return nil
 }
+
+// NewCreateSnapshotBadRequest creates a CreateSnapshotBadRequest with default headers values
+func NewCreateSnapshotBadRequest() *CreateSnapshotBadRequest {
+	return &CreateSnapshotBadRequest{}
+}
+
+/*CreateSnapshotBadRequest handles this case with default header values.
+
+Invalid protected entity ID
+*/
+type CreateSnapshotBadRequest struct {
+	Payload *models.Error
+}
+
+func (o *CreateSnapshotBadRequest) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}/{protectedEntityID}/snapshots][%d] createSnapshotBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *CreateSnapshotBadRequest) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *CreateSnapshotBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewCreateSnapshotNotFound creates a CreateSnapshotNotFound with default headers values
+func NewCreateSnapshotNotFound() *CreateSnapshotNotFound {
+	return &CreateSnapshotNotFound{}
+}
+
+/*CreateSnapshotNotFound handles this case with default header values.
+
+Service or Protected Entity not found
+*/
+type CreateSnapshotNotFound struct {
+	Payload *models.Error
+}
+
+func (o *CreateSnapshotNotFound) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}/{protectedEntityID}/snapshots][%d] createSnapshotNotFound  %+v", 404, o.Payload)
+}
+
+func (o *CreateSnapshotNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *CreateSnapshotNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewCreateSnapshotConflict creates a CreateSnapshotConflict with default headers values
+func NewCreateSnapshotConflict() *CreateSnapshotConflict {
+	return &CreateSnapshotConflict{}
+}
+
+/*CreateSnapshotConflict handles this case with default header values.
+
+Another operation on the protected entity holds its lock
+*/
+type CreateSnapshotConflict struct {
+	Payload *models.Error
+}
+
+func (o *CreateSnapshotConflict) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}/{protectedEntityID}/snapshots][%d] createSnapshotConflict  %+v", 409, o.Payload)
+}
+
+func (o *CreateSnapshotConflict) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *CreateSnapshotConflict) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewCreateSnapshotInternalServerError creates a CreateSnapshotInternalServerError with default headers values
+func NewCreateSnapshotInternalServerError() *CreateSnapshotInternalServerError {
+	return &CreateSnapshotInternalServerError{}
+}
+
+/*CreateSnapshotInternalServerError handles this case with default header values.
+
+Snapshot failed
+*/
+type CreateSnapshotInternalServerError struct {
+	Payload *models.Error
+}
+
+func (o *CreateSnapshotInternalServerError) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}/{protectedEntityID}/snapshots][%d] createSnapshotInternalServerError  %+v", 500, o.Payload)
+}
+
+func (o *CreateSnapshotInternalServerError) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *CreateSnapshotInternalServerError) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	cr "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+)
+
+// NewCreateTaskNexusParams creates a new CreateTaskNexusParams object
+// with the default values initialized.
+func NewCreateTaskNexusParams() *CreateTaskNexusParams {
+
+	return &CreateTaskNexusParams{
+
+		timeout: cr.DefaultTimeout,
+	}
+}
+
+// NewCreateTaskNexusParamsWithTimeout creates a new CreateTaskNexusParams object
+// with the default values initialized, and the ability to set a timeout on a request
+func NewCreateTaskNexusParamsWithTimeout(timeout time.Duration) *CreateTaskNexusParams {
+
+	return &CreateTaskNexusParams{
+
+		timeout: timeout,
+	}
+}
+
+// NewCreateTaskNexusParamsWithContext creates a new CreateTaskNexusParams object
+// with the default values initialized, and the ability to set a context for a request
+func NewCreateTaskNexusParamsWithContext(ctx context.Context) *CreateTaskNexusParams {
+
+	return &CreateTaskNexusParams{
+
+		Context: ctx,
+	}
+}
+
+// NewCreateTaskNexusParamsWithHTTPClient creates a new CreateTaskNexusParams object
+// with the default values initialized, and the ability to set a custom HTTPClient for a request
+func NewCreateTaskNexusParamsWithHTTPClient(client *http.Client) *CreateTaskNexusParams {
+
+	return &CreateTaskNexusParams{
+		HTTPClient: client,
+	}
+}
+
+/*CreateTaskNexusParams contains all the parameters to send to the API endpoint
+for the create task nexus operation typically these are written to a http.Request
+*/
+type CreateTaskNexusParams struct {
+	timeout    time.Duration
+	Context    context.Context
+	HTTPClient *http.Client
+}
+
+// WithTimeout adds the timeout to the create task nexus params
+func (o *CreateTaskNexusParams) WithTimeout(timeout time.Duration) *CreateTaskNexusParams {
+	o.SetTimeout(timeout)
+	return o
+}
+
+// SetTimeout adds the timeout to the create task nexus params
+func (o *CreateTaskNexusParams) SetTimeout(timeout time.Duration) {
+	o.timeout = timeout
+}
+
+// WithContext adds the context to the create task nexus params
+func (o *CreateTaskNexusParams) WithContext(ctx context.Context) *CreateTaskNexusParams {
+	o.SetContext(ctx)
+	return o
+}
+
+// SetContext adds the context to the create task nexus params
+func (o *CreateTaskNexusParams) SetContext(ctx context.Context) {
+	o.Context = ctx
+}
+
+// WithHTTPClient adds the HTTPClient to the create task nexus params
+func (o *CreateTaskNexusParams) WithHTTPClient(client *http.Client) *CreateTaskNexusParams {
+	o.SetHTTPClient(client)
+	return o
+}
+
+// SetHTTPClient adds the HTTPClient to the create task nexus params
+func (o *CreateTaskNexusParams) SetHTTPClient(client *http.Client) {
+	o.HTTPClient = client
+}
+
+// WriteToRequest writes these params to a swagger request
+func (o *CreateTaskNexusParams) WriteToRequest(r runtime.ClientRequest, reg strfmt.Registry) error {
+
+	if err := r.SetTimeout(o.timeout); err != nil {
+		return err
+	}
+	var res []error
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
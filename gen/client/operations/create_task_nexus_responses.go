@@ -0,0 +1,67 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// CreateTaskNexusReader is a Reader for the CreateTaskNexus structure.
+type CreateTaskNexusReader struct {
+	formats strfmt.Registry
+}
+
+// ReadResponse reads a server response into the received o.
+func (o *CreateTaskNexusReader) ReadResponse(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	switch response.Code() {
+	case 200:
+		result := NewCreateTaskNexusOK()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return result, nil
+
+	default:
+		return nil, runtime.NewAPIError("unknown error", response, response.Code())
+	}
+}
+
+// NewCreateTaskNexusOK creates a CreateTaskNexusOK with default headers values
+func NewCreateTaskNexusOK() *CreateTaskNexusOK {
+	return &CreateTaskNexusOK{}
+}
+
+/*CreateTaskNexusOK handles this case with default header values.
+
+New task nexus
+*/
+type CreateTaskNexusOK struct {
+	Payload models.TaskNexusID
+}
+
+func (o *CreateTaskNexusOK) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/tasks/nexus][%d] createTaskNexusOK  %+v", 200, o.Payload)
+}
+
+func (o *CreateTaskNexusOK) GetPayload() models.TaskNexusID {
+	return o.Payload
+}
+
+func (o *CreateTaskNexusOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	// response payload
+	if err := consumer.Consume(response.Body(), &o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
@@ -60,6 +60,16 @@ for the delete protected entity operation typically these are written to a http.
 */
 type DeleteProtectedEntityParams struct {
 
+	/*IdempotencyKey
+	  A key chosen by the client that identifies this request.  If a
+	request with the same key has already completed successfully within
+	the server's idempotency window, its result is returned instead of
+	running the operation again.  Keys are scoped to the caller and
+	cannot be reused for a different request.
+
+
+	*/
+	IdempotencyKey *string
 	/*ProtectedEntityID
 	  The protected entity ID to retrieve info for
 
@@ -109,6 +119,17 @@ func (o *DeleteProtectedEntityParams) SetHTTPClient(client *http.Client) {
 	o.HTTPClient = client
 }
 
+// WithIdempotencyKey adds the idempotencyKey to the delete protected entity params
+func (o *DeleteProtectedEntityParams) WithIdempotencyKey(idempotencyKey *string) *DeleteProtectedEntityParams {
+	o.SetIdempotencyKey(idempotencyKey)
+	return o
+}
+
+// SetIdempotencyKey adds the idempotencyKey to the delete protected entity params
+func (o *DeleteProtectedEntityParams) SetIdempotencyKey(idempotencyKey *string) {
+	o.IdempotencyKey = idempotencyKey
+}
+
 // WithProtectedEntityID adds the protectedEntityID to the delete protected entity params
 func (o *DeleteProtectedEntityParams) WithProtectedEntityID(protectedEntityID string) *DeleteProtectedEntityParams {
 	o.SetProtectedEntityID(protectedEntityID)
@@ -139,6 +160,15 @@ func (o *DeleteProtectedEntityParams) WriteToRequest(r runtime.ClientRequest, re
 	}
 	var res []error
 
+	if o.IdempotencyKey != nil {
+
+		// header param Idempotency-Key
+		if err := r.SetHeaderParam("Idempotency-Key", *o.IdempotencyKey); err != nil {
+			return err
+		}
+
+	}
+
 	// path param protectedEntityID
 	if err := r.SetPathParam("protectedEntityID", o.ProtectedEntityID); err != nil {
 		return err
@@ -29,6 +29,30 @@ func (o *DeleteProtectedEntityReader) ReadResponse(response runtime.ClientRespon
 			return nil, err
 		}
 		return result, nil
+	case 400:
+		result := NewDeleteProtectedEntityBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 404:
+		result := NewDeleteProtectedEntityNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 409:
+		result := NewDeleteProtectedEntityConflict()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 500:
+		result := NewDeleteProtectedEntityInternalServerError()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
 
 	default:
 		return nil, runtime.NewAPIError("unknown error", response, response.Code())
@@ -65,3 +89,135 @@ func (o *DeleteProtectedEntityOK) readResponse(response runtime.ClientResponse,
 
 	return nil
 }
+
+// NewDeleteProtectedEntityBadRequest creates a DeleteProtectedEntityBadRequest with default headers values
+func NewDeleteProtectedEntityBadRequest() *DeleteProtectedEntityBadRequest {
+	return &DeleteProtectedEntityBadRequest{}
+}
+
+/*DeleteProtectedEntityBadRequest handles this case with default header values.
+
+Invalid protected entity ID or the ID does not have a snapshot ID
+*/
+type DeleteProtectedEntityBadRequest struct {
+	Payload *models.Error
+}
+
+func (o *DeleteProtectedEntityBadRequest) Error() string {
+	return fmt.Sprintf("[DELETE /astrolabe/{service}/{protectedEntityID}][%d] deleteProtectedEntityBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *DeleteProtectedEntityBadRequest) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *DeleteProtectedEntityBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewDeleteProtectedEntityNotFound creates a DeleteProtectedEntityNotFound with default headers values
+func NewDeleteProtectedEntityNotFound() *DeleteProtectedEntityNotFound {
+	return &DeleteProtectedEntityNotFound{}
+}
+
+/*DeleteProtectedEntityNotFound handles this case with default header values.
+
+Service or Protected Entity not found
+*/
+type DeleteProtectedEntityNotFound struct {
+	Payload *models.Error
+}
+
+func (o *DeleteProtectedEntityNotFound) Error() string {
+	return fmt.Sprintf("[DELETE /astrolabe/{service}/{protectedEntityID}][%d] deleteProtectedEntityNotFound  %+v", 404, o.Payload)
+}
+
+func (o *DeleteProtectedEntityNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *DeleteProtectedEntityNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewDeleteProtectedEntityConflict creates a DeleteProtectedEntityConflict with default headers values
+func NewDeleteProtectedEntityConflict() *DeleteProtectedEntityConflict {
+	return &DeleteProtectedEntityConflict{}
+}
+
+/*DeleteProtectedEntityConflict handles this case with default header values.
+
+The snapshot is retained and cannot be deleted or another operation on the protected entity holds its lock
+*/
+type DeleteProtectedEntityConflict struct {
+	Payload *models.Error
+}
+
+func (o *DeleteProtectedEntityConflict) Error() string {
+	return fmt.Sprintf("[DELETE /astrolabe/{service}/{protectedEntityID}][%d] deleteProtectedEntityConflict  %+v", 409, o.Payload)
+}
+
+func (o *DeleteProtectedEntityConflict) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *DeleteProtectedEntityConflict) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewDeleteProtectedEntityInternalServerError creates a DeleteProtectedEntityInternalServerError with default headers values
+func NewDeleteProtectedEntityInternalServerError() *DeleteProtectedEntityInternalServerError {
+	return &DeleteProtectedEntityInternalServerError{}
+}
+
+/*DeleteProtectedEntityInternalServerError handles this case with default header values.
+
+Delete failed
+*/
+type DeleteProtectedEntityInternalServerError struct {
+	Payload *models.Error
+}
+
+func (o *DeleteProtectedEntityInternalServerError) Error() string {
+	return fmt.Sprintf("[DELETE /astrolabe/{service}/{protectedEntityID}][%d] deleteProtectedEntityInternalServerError  %+v", 500, o.Payload)
+}
+
+func (o *DeleteProtectedEntityInternalServerError) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *DeleteProtectedEntityInternalServerError) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
@@ -1,69 +0,0 @@
-// Code generated by go-swagger; DO NOT EDIT.
-
-package operations
-
-// This file was generated by the swagger tool.
-// Editing this file might prove futile when you re-run the swagger generate command
-
-import (
-	"fmt"
-	"io"
-
-	"github.com/go-openapi/runtime"
-	"github.com/go-openapi/strfmt"
-
-	"github.com/vmware-tanzu/astrolabe/gen/models"
-)
-
-// GetAstrolabeTasksNexusTaskNexusIDReader is a Reader for the GetAstrolabeTasksNexusTaskNexusID structure.
-type GetAstrolabeTasksNexusTaskNexusIDReader struct {
-	formats strfmt.Registry
-}
-
-// ReadResponse reads a server response into the received o.
-func (o *GetAstrolabeTasksNexusTaskNexusIDReader) ReadResponse(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
-	switch response.Code() {
-	case 200:
-		result := NewGetAstrolabeTasksNexusTaskNexusIDOK()
-		if err := result.readResponse(response, consumer, o.formats); err != nil {
-			return nil, err
-		}
-		return result, nil
-
-	default:
-		return nil, runtime.NewAPIError("unknown error", response, response.Code())
-	}
-}
-
-// NewGetAstrolabeTasksNexusTaskNexusIDOK creates a GetAstrolabeTasksNexusTaskNexusIDOK with default headers values
-func NewGetAstrolabeTasksNexusTaskNexusIDOK() *GetAstrolabeTasksNexusTaskNexusIDOK {
-	return &GetAstrolabeTasksNexusTaskNexusIDOK{}
-}
-
-/*GetAstrolabeTasksNexusTaskNexusIDOK handles this case with default header values.
-
-200 response
-*/
-type GetAstrolabeTasksNexusTaskNexusIDOK struct {
-	Payload *models.TaskNexusResponse
-}
-
-func (o *GetAstrolabeTasksNexusTaskNexusIDOK) Error() string {
-	return fmt.Sprintf("[GET /astrolabe/tasks/nexus/{taskNexusID}][%d] getAstrolabeTasksNexusTaskNexusIdOK  %+v", 200, o.Payload)
-}
-
-func (o *GetAstrolabeTasksNexusTaskNexusIDOK) GetPayload() *models.TaskNexusResponse {
-	return o.Payload
-}
-
-func (o *GetAstrolabeTasksNexusTaskNexusIDOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
-
-	o.Payload = new(models.TaskNexusResponse)
-
-	// response payload
-	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
-		return err
-	}
-
-	return nil
-}
@@ -29,6 +29,24 @@ func (o *GetProtectedEntityInfoReader) ReadResponse(response runtime.ClientRespo
 			return nil, err
 		}
 		return result, nil
+	case 400:
+		result := NewGetProtectedEntityInfoBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 404:
+		result := NewGetProtectedEntityInfoNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 500:
+		result := NewGetProtectedEntityInfoInternalServerError()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
 
 	default:
 		return nil, runtime.NewAPIError("unknown error", response, response.Code())
@@ -67,3 +85,102 @@ func (o *GetProtectedEntityInfoOK) readResponse(response runtime.ClientResponse,
 
 	return nil
 }
+
+// NewGetProtectedEntityInfoBadRequest creates a GetProtectedEntityInfoBadRequest with default headers values
+func NewGetProtectedEntityInfoBadRequest() *GetProtectedEntityInfoBadRequest {
+	return &GetProtectedEntityInfoBadRequest{}
+}
+
+/*GetProtectedEntityInfoBadRequest handles this case with default header values.
+
+Invalid protected entity ID
+*/
+type GetProtectedEntityInfoBadRequest struct {
+	Payload *models.Error
+}
+
+func (o *GetProtectedEntityInfoBadRequest) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}][%d] getProtectedEntityInfoBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *GetProtectedEntityInfoBadRequest) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *GetProtectedEntityInfoBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewGetProtectedEntityInfoNotFound creates a GetProtectedEntityInfoNotFound with default headers values
+func NewGetProtectedEntityInfoNotFound() *GetProtectedEntityInfoNotFound {
+	return &GetProtectedEntityInfoNotFound{}
+}
+
+/*GetProtectedEntityInfoNotFound handles this case with default header values.
+
+Service or Protected Entity not found
+*/
+type GetProtectedEntityInfoNotFound struct {
+	Payload *models.Error
+}
+
+func (o *GetProtectedEntityInfoNotFound) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}][%d] getProtectedEntityInfoNotFound  %+v", 404, o.Payload)
+}
+
+func (o *GetProtectedEntityInfoNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *GetProtectedEntityInfoNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewGetProtectedEntityInfoInternalServerError creates a GetProtectedEntityInfoInternalServerError with default headers values
+func NewGetProtectedEntityInfoInternalServerError() *GetProtectedEntityInfoInternalServerError {
+	return &GetProtectedEntityInfoInternalServerError{}
+}
+
+/*GetProtectedEntityInfoInternalServerError handles this case with default header values.
+
+Failed to retrieve the protected entity info
+*/
+type GetProtectedEntityInfoInternalServerError struct {
+	Payload *models.Error
+}
+
+func (o *GetProtectedEntityInfoInternalServerError) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}][%d] getProtectedEntityInfoInternalServerError  %+v", 500, o.Payload)
+}
+
+func (o *GetProtectedEntityInfoInternalServerError) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *GetProtectedEntityInfoInternalServerError) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	cr "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+)
+
+// NewGetRetentionParams creates a new GetRetentionParams object
+// with the default values initialized.
+func NewGetRetentionParams() *GetRetentionParams {
+	var ()
+	return &GetRetentionParams{
+
+		timeout: cr.DefaultTimeout,
+	}
+}
+
+// NewGetRetentionParamsWithTimeout creates a new GetRetentionParams object
+// with the default values initialized, and the ability to set a timeout on a request
+func NewGetRetentionParamsWithTimeout(timeout time.Duration) *GetRetentionParams {
+	var ()
+	return &GetRetentionParams{
+
+		timeout: timeout,
+	}
+}
+
+// NewGetRetentionParamsWithContext creates a new GetRetentionParams object
+// with the default values initialized, and the ability to set a context for a request
+func NewGetRetentionParamsWithContext(ctx context.Context) *GetRetentionParams {
+	var ()
+	return &GetRetentionParams{
+
+		Context: ctx,
+	}
+}
+
+// NewGetRetentionParamsWithHTTPClient creates a new GetRetentionParams object
+// with the default values initialized, and the ability to set a custom HTTPClient for a request
+func NewGetRetentionParamsWithHTTPClient(client *http.Client) *GetRetentionParams {
+	var ()
+	return &GetRetentionParams{
+		HTTPClient: client,
+	}
+}
+
+/*GetRetentionParams contains all the parameters to send to the API endpoint
+for the get retention operation typically these are written to a http.Request
+*/
+type GetRetentionParams struct {
+
+	/*ProtectedEntityID
+	  The snapshot protected entity ID to retrieve retention for
+
+	*/
+	ProtectedEntityID string
+	/*Service
+	  The service for the protected entity
+
+	*/
+	Service string
+
+	timeout    time.Duration
+	Context    context.Context
+	HTTPClient *http.Client
+}
+
+// WithTimeout adds the timeout to the get retention params
+func (o *GetRetentionParams) WithTimeout(timeout time.Duration) *GetRetentionParams {
+	o.SetTimeout(timeout)
+	return o
+}
+
+// SetTimeout adds the timeout to the get retention params
+func (o *GetRetentionParams) SetTimeout(timeout time.Duration) {
+	o.timeout = timeout
+}
+
+// WithContext adds the context to the get retention params
+func (o *GetRetentionParams) WithContext(ctx context.Context) *GetRetentionParams {
+	o.SetContext(ctx)
+	return o
+}
+
+// SetContext adds the context to the get retention params
+func (o *GetRetentionParams) SetContext(ctx context.Context) {
+	o.Context = ctx
+}
+
+// WithHTTPClient adds the HTTPClient to the get retention params
+func (o *GetRetentionParams) WithHTTPClient(client *http.Client) *GetRetentionParams {
+	o.SetHTTPClient(client)
+	return o
+}
+
+// SetHTTPClient adds the HTTPClient to the get retention params
+func (o *GetRetentionParams) SetHTTPClient(client *http.Client) {
+	o.HTTPClient = client
+}
+
+// WithProtectedEntityID adds the protectedEntityID to the get retention params
+func (o *GetRetentionParams) WithProtectedEntityID(protectedEntityID string) *GetRetentionParams {
+	o.SetProtectedEntityID(protectedEntityID)
+	return o
+}
+
+// SetProtectedEntityID adds the protectedEntityId to the get retention params
+func (o *GetRetentionParams) SetProtectedEntityID(protectedEntityID string) {
+	o.ProtectedEntityID = protectedEntityID
+}
+
+// WithService adds the service to the get retention params
+func (o *GetRetentionParams) WithService(service string) *GetRetentionParams {
+	o.SetService(service)
+	return o
+}
+
+// SetService adds the service to the get retention params
+func (o *GetRetentionParams) SetService(service string) {
+	o.Service = service
+}
+
+// WriteToRequest writes these params to a swagger request
+func (o *GetRetentionParams) WriteToRequest(r runtime.ClientRequest, reg strfmt.Registry) error {
+
+	if err := r.SetTimeout(o.timeout); err != nil {
+		return err
+	}
+	var res []error
+
+	// path param protectedEntityID
+	if err := r.SetPathParam("protectedEntityID", o.ProtectedEntityID); err != nil {
+		return err
+	}
+
+	// path param service
+	if err := r.SetPathParam("service", o.Service); err != nil {
+		return err
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
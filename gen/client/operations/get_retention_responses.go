@@ -0,0 +1,186 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// GetRetentionReader is a Reader for the GetRetention structure.
+type GetRetentionReader struct {
+	formats strfmt.Registry
+}
+
+// ReadResponse reads a server response into the received o.
+func (o *GetRetentionReader) ReadResponse(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	switch response.Code() {
+	case 200:
+		result := NewGetRetentionOK()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case 400:
+		result := NewGetRetentionBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 404:
+		result := NewGetRetentionNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 500:
+		result := NewGetRetentionInternalServerError()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	default:
+		return nil, runtime.NewAPIError("unknown error", response, response.Code())
+	}
+}
+
+// NewGetRetentionOK creates a GetRetentionOK with default headers values
+func NewGetRetentionOK() *GetRetentionOK {
+	return &GetRetentionOK{}
+}
+
+/*GetRetentionOK handles this case with default header values.
+
+200 response
+*/
+type GetRetentionOK struct {
+	Payload *models.Retention
+}
+
+func (o *GetRetentionOK) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}/retention][%d] getRetentionOK  %+v", 200, o.Payload)
+}
+
+func (o *GetRetentionOK) GetPayload() *models.Retention {
+	return o.Payload
+}
+
+func (o *GetRetentionOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Retention)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewGetRetentionBadRequest creates a GetRetentionBadRequest with default headers values
+func NewGetRetentionBadRequest() *GetRetentionBadRequest {
+	return &GetRetentionBadRequest{}
+}
+
+/*GetRetentionBadRequest handles this case with default header values.
+
+Invalid protected entity ID
+*/
+type GetRetentionBadRequest struct {
+	Payload *models.Error
+}
+
+func (o *GetRetentionBadRequest) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}/retention][%d] getRetentionBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *GetRetentionBadRequest) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *GetRetentionBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewGetRetentionNotFound creates a GetRetentionNotFound with default headers values
+func NewGetRetentionNotFound() *GetRetentionNotFound {
+	return &GetRetentionNotFound{}
+}
+
+/*GetRetentionNotFound handles this case with default header values.
+
+Service or snapshot not found, or the service does not support retention
+*/
+type GetRetentionNotFound struct {
+	Payload *models.Error
+}
+
+func (o *GetRetentionNotFound) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}/retention][%d] getRetentionNotFound  %+v", 404, o.Payload)
+}
+
+func (o *GetRetentionNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *GetRetentionNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewGetRetentionInternalServerError creates a GetRetentionInternalServerError with default headers values
+func NewGetRetentionInternalServerError() *GetRetentionInternalServerError {
+	return &GetRetentionInternalServerError{}
+}
+
+/*GetRetentionInternalServerError handles this case with default header values.
+
+Failed to retrieve retention
+*/
+type GetRetentionInternalServerError struct {
+	Payload *models.Error
+}
+
+func (o *GetRetentionInternalServerError) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}/retention][%d] getRetentionInternalServerError  %+v", 500, o.Payload)
+}
+
+func (o *GetRetentionInternalServerError) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *GetRetentionInternalServerError) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
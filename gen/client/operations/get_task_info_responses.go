@@ -29,6 +29,12 @@ func (o *GetTaskInfoReader) ReadResponse(response runtime.ClientResponse, consum
 			return nil, err
 		}
 		return result, nil
+	case 404:
+		result := NewGetTaskInfoNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
 
 	default:
 		return nil, runtime.NewAPIError("unknown error", response, response.Code())
@@ -67,3 +73,36 @@ func (o *GetTaskInfoOK) readResponse(response runtime.ClientResponse, consumer r
 
 	return nil
 }
+
+// NewGetTaskInfoNotFound creates a GetTaskInfoNotFound with default headers values
+func NewGetTaskInfoNotFound() *GetTaskInfoNotFound {
+	return &GetTaskInfoNotFound{}
+}
+
+/*GetTaskInfoNotFound handles this case with default header values.
+
+Task not found
+*/
+type GetTaskInfoNotFound struct {
+	Payload *models.Error
+}
+
+func (o *GetTaskInfoNotFound) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/tasks/{taskID}][%d] getTaskInfoNotFound  %+v", 404, o.Payload)
+}
+
+func (o *GetTaskInfoNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *GetTaskInfoNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
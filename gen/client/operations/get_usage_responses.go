@@ -0,0 +1,147 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// GetUsageReader is a Reader for the GetUsage structure.
+type GetUsageReader struct {
+	formats strfmt.Registry
+}
+
+// ReadResponse reads a server response into the received o.
+func (o *GetUsageReader) ReadResponse(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	switch response.Code() {
+	case 200:
+		result := NewGetUsageOK()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case 404:
+		result := NewGetUsageNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 500:
+		result := NewGetUsageInternalServerError()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	default:
+		return nil, runtime.NewAPIError("unknown error", response, response.Code())
+	}
+}
+
+// NewGetUsageOK creates a GetUsageOK with default headers values
+func NewGetUsageOK() *GetUsageOK {
+	return &GetUsageOK{}
+}
+
+/*GetUsageOK handles this case with default header values.
+
+Usage for the services that store protected entities
+*/
+type GetUsageOK struct {
+	Payload *models.RepositoryUsage
+}
+
+func (o *GetUsageOK) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/usage][%d] getUsageOK  %+v", 200, o.Payload)
+}
+
+func (o *GetUsageOK) GetPayload() *models.RepositoryUsage {
+	return o.Payload
+}
+
+func (o *GetUsageOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.RepositoryUsage)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewGetUsageNotFound creates a GetUsageNotFound with default headers values
+func NewGetUsageNotFound() *GetUsageNotFound {
+	return &GetUsageNotFound{}
+}
+
+/*GetUsageNotFound handles this case with default header values.
+
+Service not found or service does not report usage
+*/
+type GetUsageNotFound struct {
+	Payload *models.Error
+}
+
+func (o *GetUsageNotFound) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/usage][%d] getUsageNotFound  %+v", 404, o.Payload)
+}
+
+func (o *GetUsageNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *GetUsageNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewGetUsageInternalServerError creates a GetUsageInternalServerError with default headers values
+func NewGetUsageInternalServerError() *GetUsageInternalServerError {
+	return &GetUsageInternalServerError{}
+}
+
+/*GetUsageInternalServerError handles this case with default header values.
+
+Usage could not be retrieved
+*/
+type GetUsageInternalServerError struct {
+	Payload *models.Error
+}
+
+func (o *GetUsageInternalServerError) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/usage][%d] getUsageInternalServerError  %+v", 500, o.Payload)
+}
+
+func (o *GetUsageInternalServerError) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *GetUsageInternalServerError) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	cr "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+)
+
+// NewListAuditEventsParams creates a new ListAuditEventsParams object
+// with the default values initialized.
+func NewListAuditEventsParams() *ListAuditEventsParams {
+	var ()
+	return &ListAuditEventsParams{
+
+		timeout: cr.DefaultTimeout,
+	}
+}
+
+// NewListAuditEventsParamsWithTimeout creates a new ListAuditEventsParams object
+// with the default values initialized, and the ability to set a timeout on a request
+func NewListAuditEventsParamsWithTimeout(timeout time.Duration) *ListAuditEventsParams {
+	var ()
+	return &ListAuditEventsParams{
+
+		timeout: timeout,
+	}
+}
+
+// NewListAuditEventsParamsWithContext creates a new ListAuditEventsParams object
+// with the default values initialized, and the ability to set a context for a request
+func NewListAuditEventsParamsWithContext(ctx context.Context) *ListAuditEventsParams {
+	var ()
+	return &ListAuditEventsParams{
+
+		Context: ctx,
+	}
+}
+
+// NewListAuditEventsParamsWithHTTPClient creates a new ListAuditEventsParams object
+// with the default values initialized, and the ability to set a custom HTTPClient for a request
+func NewListAuditEventsParamsWithHTTPClient(client *http.Client) *ListAuditEventsParams {
+	var ()
+	return &ListAuditEventsParams{
+		HTTPClient: client,
+	}
+}
+
+/*ListAuditEventsParams contains all the parameters to send to the API endpoint
+for the list audit events operation typically these are written to a http.Request
+*/
+type ListAuditEventsParams struct {
+
+	/*From
+	  Only return events at or after this time
+
+	*/
+	From *strfmt.DateTime
+	/*ProtectedEntityID
+	  Only return events for this protected entity.  Events for snapshots of the protected entity are included
+	when the ID does not have a snapshot ID
+
+
+	*/
+	ProtectedEntityID *string
+	/*To
+	  Only return events before this time
+
+	*/
+	To *strfmt.DateTime
+
+	timeout    time.Duration
+	Context    context.Context
+	HTTPClient *http.Client
+}
+
+// WithTimeout adds the timeout to the list audit events params
+func (o *ListAuditEventsParams) WithTimeout(timeout time.Duration) *ListAuditEventsParams {
+	o.SetTimeout(timeout)
+	return o
+}
+
+// SetTimeout adds the timeout to the list audit events params
+func (o *ListAuditEventsParams) SetTimeout(timeout time.Duration) {
+	o.timeout = timeout
+}
+
+// WithContext adds the context to the list audit events params
+func (o *ListAuditEventsParams) WithContext(ctx context.Context) *ListAuditEventsParams {
+	o.SetContext(ctx)
+	return o
+}
+
+// SetContext adds the context to the list audit events params
+func (o *ListAuditEventsParams) SetContext(ctx context.Context) {
+	o.Context = ctx
+}
+
+// WithHTTPClient adds the HTTPClient to the list audit events params
+func (o *ListAuditEventsParams) WithHTTPClient(client *http.Client) *ListAuditEventsParams {
+	o.SetHTTPClient(client)
+	return o
+}
+
+// SetHTTPClient adds the HTTPClient to the list audit events params
+func (o *ListAuditEventsParams) SetHTTPClient(client *http.Client) {
+	o.HTTPClient = client
+}
+
+// WithFrom adds the from to the list audit events params
+func (o *ListAuditEventsParams) WithFrom(from *strfmt.DateTime) *ListAuditEventsParams {
+	o.SetFrom(from)
+	return o
+}
+
+// SetFrom adds the from to the list audit events params
+func (o *ListAuditEventsParams) SetFrom(from *strfmt.DateTime) {
+	o.From = from
+}
+
+// WithProtectedEntityID adds the protectedEntityID to the list audit events params
+func (o *ListAuditEventsParams) WithProtectedEntityID(protectedEntityID *string) *ListAuditEventsParams {
+	o.SetProtectedEntityID(protectedEntityID)
+	return o
+}
+
+// SetProtectedEntityID adds the protectedEntityId to the list audit events params
+func (o *ListAuditEventsParams) SetProtectedEntityID(protectedEntityID *string) {
+	o.ProtectedEntityID = protectedEntityID
+}
+
+// WithTo adds the to to the list audit events params
+func (o *ListAuditEventsParams) WithTo(to *strfmt.DateTime) *ListAuditEventsParams {
+	o.SetTo(to)
+	return o
+}
+
+// SetTo adds the to to the list audit events params
+func (o *ListAuditEventsParams) SetTo(to *strfmt.DateTime) {
+	o.To = to
+}
+
+// WriteToRequest writes these params to a swagger request
+func (o *ListAuditEventsParams) WriteToRequest(r runtime.ClientRequest, reg strfmt.Registry) error {
+
+	if err := r.SetTimeout(o.timeout); err != nil {
+		return err
+	}
+	var res []error
+
+	if o.From != nil {
+
+		// query param from
+		var qrFrom strfmt.DateTime
+		if o.From != nil {
+			qrFrom = *o.From
+		}
+		qFrom := qrFrom.String()
+		if qFrom != "" {
+			if err := r.SetQueryParam("from", qFrom); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.ProtectedEntityID != nil {
+
+		// query param protectedEntityID
+		var qrProtectedEntityID string
+		if o.ProtectedEntityID != nil {
+			qrProtectedEntityID = *o.ProtectedEntityID
+		}
+		qProtectedEntityID := qrProtectedEntityID
+		if qProtectedEntityID != "" {
+			if err := r.SetQueryParam("protectedEntityID", qProtectedEntityID); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.To != nil {
+
+		// query param to
+		var qrTo strfmt.DateTime
+		if o.To != nil {
+			qrTo = *o.To
+		}
+		qTo := qrTo.String()
+		if qTo != "" {
+			if err := r.SetQueryParam("to", qTo); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// ListAuditEventsReader is a Reader for the ListAuditEvents structure.
+type ListAuditEventsReader struct {
+	formats strfmt.Registry
+}
+
+// ReadResponse reads a server response into the received o.
+func (o *ListAuditEventsReader) ReadResponse(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	switch response.Code() {
+	case 200:
+		result := NewListAuditEventsOK()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case 400:
+		result := NewListAuditEventsBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 404:
+		result := NewListAuditEventsNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 500:
+		result := NewListAuditEventsInternalServerError()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	default:
+		return nil, runtime.NewAPIError("unknown error", response, response.Code())
+	}
+}
+
+// NewListAuditEventsOK creates a ListAuditEventsOK with default headers values
+func NewListAuditEventsOK() *ListAuditEventsOK {
+	return &ListAuditEventsOK{}
+}
+
+/*ListAuditEventsOK handles this case with default header values.
+
+The matching audit events, oldest first
+*/
+type ListAuditEventsOK struct {
+	Payload *models.AuditEventList
+}
+
+func (o *ListAuditEventsOK) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/audit][%d] listAuditEventsOK  %+v", 200, o.Payload)
+}
+
+func (o *ListAuditEventsOK) GetPayload() *models.AuditEventList {
+	return o.Payload
+}
+
+func (o *ListAuditEventsOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.AuditEventList)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewListAuditEventsBadRequest creates a ListAuditEventsBadRequest with default headers values
+func NewListAuditEventsBadRequest() *ListAuditEventsBadRequest {
+	return &ListAuditEventsBadRequest{}
+}
+
+/*ListAuditEventsBadRequest handles this case with default header values.
+
+Invalid protected entity ID or time range
+*/
+type ListAuditEventsBadRequest struct {
+	Payload *models.Error
+}
+
+func (o *ListAuditEventsBadRequest) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/audit][%d] listAuditEventsBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *ListAuditEventsBadRequest) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *ListAuditEventsBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewListAuditEventsNotFound creates a ListAuditEventsNotFound with default headers values
+func NewListAuditEventsNotFound() *ListAuditEventsNotFound {
+	return &ListAuditEventsNotFound{}
+}
+
+/*ListAuditEventsNotFound handles this case with default header values.
+
+The audit log is not enabled
+*/
+type ListAuditEventsNotFound struct {
+	Payload *models.Error
+}
+
+func (o *ListAuditEventsNotFound) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/audit][%d] listAuditEventsNotFound  %+v", 404, o.Payload)
+}
+
+func (o *ListAuditEventsNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *ListAuditEventsNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewListAuditEventsInternalServerError creates a ListAuditEventsInternalServerError with default headers values
+func NewListAuditEventsInternalServerError() *ListAuditEventsInternalServerError {
+	return &ListAuditEventsInternalServerError{}
+}
+
+/*ListAuditEventsInternalServerError handles this case with default header values.
+
+The audit log could not be read
+*/
+type ListAuditEventsInternalServerError struct {
+	Payload *models.Error
+}
+
+func (o *ListAuditEventsInternalServerError) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/audit][%d] listAuditEventsInternalServerError  %+v", 500, o.Payload)
+}
+
+func (o *ListAuditEventsInternalServerError) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *ListAuditEventsInternalServerError) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	cr "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+)
+
+// NewListDeletedSnapshotsParams creates a new ListDeletedSnapshotsParams object
+// with the default values initialized.
+func NewListDeletedSnapshotsParams() *ListDeletedSnapshotsParams {
+	var ()
+	return &ListDeletedSnapshotsParams{
+
+		timeout: cr.DefaultTimeout,
+	}
+}
+
+// NewListDeletedSnapshotsParamsWithTimeout creates a new ListDeletedSnapshotsParams object
+// with the default values initialized, and the ability to set a timeout on a request
+func NewListDeletedSnapshotsParamsWithTimeout(timeout time.Duration) *ListDeletedSnapshotsParams {
+	var ()
+	return &ListDeletedSnapshotsParams{
+
+		timeout: timeout,
+	}
+}
+
+// NewListDeletedSnapshotsParamsWithContext creates a new ListDeletedSnapshotsParams object
+// with the default values initialized, and the ability to set a context for a request
+func NewListDeletedSnapshotsParamsWithContext(ctx context.Context) *ListDeletedSnapshotsParams {
+	var ()
+	return &ListDeletedSnapshotsParams{
+
+		Context: ctx,
+	}
+}
+
+// NewListDeletedSnapshotsParamsWithHTTPClient creates a new ListDeletedSnapshotsParams object
+// with the default values initialized, and the ability to set a custom HTTPClient for a request
+func NewListDeletedSnapshotsParamsWithHTTPClient(client *http.Client) *ListDeletedSnapshotsParams {
+	var ()
+	return &ListDeletedSnapshotsParams{
+		HTTPClient: client,
+	}
+}
+
+/*ListDeletedSnapshotsParams contains all the parameters to send to the API endpoint
+for the list deleted snapshots operation typically these are written to a http.Request
+*/
+type ListDeletedSnapshotsParams struct {
+
+	/*ProtectedEntityID
+	  The protected entity ID to list deleted snapshots for.  If a
+	snapshot ID is included only that snapshot is returned
+
+
+	*/
+	ProtectedEntityID string
+	/*Service
+	  The service for the protected entity
+
+	*/
+	Service string
+
+	timeout    time.Duration
+	Context    context.Context
+	HTTPClient *http.Client
+}
+
+// WithTimeout adds the timeout to the list deleted snapshots params
+func (o *ListDeletedSnapshotsParams) WithTimeout(timeout time.Duration) *ListDeletedSnapshotsParams {
+	o.SetTimeout(timeout)
+	return o
+}
+
+// SetTimeout adds the timeout to the list deleted snapshots params
+func (o *ListDeletedSnapshotsParams) SetTimeout(timeout time.Duration) {
+	o.timeout = timeout
+}
+
+// WithContext adds the context to the list deleted snapshots params
+func (o *ListDeletedSnapshotsParams) WithContext(ctx context.Context) *ListDeletedSnapshotsParams {
+	o.SetContext(ctx)
+	return o
+}
+
+// SetContext adds the context to the list deleted snapshots params
+func (o *ListDeletedSnapshotsParams) SetContext(ctx context.Context) {
+	o.Context = ctx
+}
+
+// WithHTTPClient adds the HTTPClient to the list deleted snapshots params
+func (o *ListDeletedSnapshotsParams) WithHTTPClient(client *http.Client) *ListDeletedSnapshotsParams {
+	o.SetHTTPClient(client)
+	return o
+}
+
+// SetHTTPClient adds the HTTPClient to the list deleted snapshots params
+func (o *ListDeletedSnapshotsParams) SetHTTPClient(client *http.Client) {
+	o.HTTPClient = client
+}
+
+// WithProtectedEntityID adds the protectedEntityID to the list deleted snapshots params
+func (o *ListDeletedSnapshotsParams) WithProtectedEntityID(protectedEntityID string) *ListDeletedSnapshotsParams {
+	o.SetProtectedEntityID(protectedEntityID)
+	return o
+}
+
+// SetProtectedEntityID adds the protectedEntityId to the list deleted snapshots params
+func (o *ListDeletedSnapshotsParams) SetProtectedEntityID(protectedEntityID string) {
+	o.ProtectedEntityID = protectedEntityID
+}
+
+// WithService adds the service to the list deleted snapshots params
+func (o *ListDeletedSnapshotsParams) WithService(service string) *ListDeletedSnapshotsParams {
+	o.SetService(service)
+	return o
+}
+
+// SetService adds the service to the list deleted snapshots params
+func (o *ListDeletedSnapshotsParams) SetService(service string) {
+	o.Service = service
+}
+
+// WriteToRequest writes these params to a swagger request
+func (o *ListDeletedSnapshotsParams) WriteToRequest(r runtime.ClientRequest, reg strfmt.Registry) error {
+
+	if err := r.SetTimeout(o.timeout); err != nil {
+		return err
+	}
+	var res []error
+
+	// path param protectedEntityID
+	if err := r.SetPathParam("protectedEntityID", o.ProtectedEntityID); err != nil {
+		return err
+	}
+
+	// path param service
+	if err := r.SetPathParam("service", o.Service); err != nil {
+		return err
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// ListDeletedSnapshotsReader is a Reader for the ListDeletedSnapshots structure.
+type ListDeletedSnapshotsReader struct {
+	formats strfmt.Registry
+}
+
+// ReadResponse reads a server response into the received o.
+func (o *ListDeletedSnapshotsReader) ReadResponse(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	switch response.Code() {
+	case 200:
+		result := NewListDeletedSnapshotsOK()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case 400:
+		result := NewListDeletedSnapshotsBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 404:
+		result := NewListDeletedSnapshotsNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 500:
+		result := NewListDeletedSnapshotsInternalServerError()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	default:
+		return nil, runtime.NewAPIError("unknown error", response, response.Code())
+	}
+}
+
+// NewListDeletedSnapshotsOK creates a ListDeletedSnapshotsOK with default headers values
+func NewListDeletedSnapshotsOK() *ListDeletedSnapshotsOK {
+	return &ListDeletedSnapshotsOK{}
+}
+
+/*ListDeletedSnapshotsOK handles this case with default header values.
+
+List succeeded
+*/
+type ListDeletedSnapshotsOK struct {
+	Payload *models.DeletedSnapshotList
+}
+
+func (o *ListDeletedSnapshotsOK) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}/deletedSnapshots][%d] listDeletedSnapshotsOK  %+v", 200, o.Payload)
+}
+
+func (o *ListDeletedSnapshotsOK) GetPayload() *models.DeletedSnapshotList {
+	return o.Payload
+}
+
+func (o *ListDeletedSnapshotsOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.DeletedSnapshotList)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewListDeletedSnapshotsBadRequest creates a ListDeletedSnapshotsBadRequest with default headers values
+func NewListDeletedSnapshotsBadRequest() *ListDeletedSnapshotsBadRequest {
+	return &ListDeletedSnapshotsBadRequest{}
+}
+
+/*ListDeletedSnapshotsBadRequest handles this case with default header values.
+
+Invalid protected entity ID
+*/
+type ListDeletedSnapshotsBadRequest struct {
+	Payload *models.Error
+}
+
+func (o *ListDeletedSnapshotsBadRequest) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}/deletedSnapshots][%d] listDeletedSnapshotsBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *ListDeletedSnapshotsBadRequest) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *ListDeletedSnapshotsBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewListDeletedSnapshotsNotFound creates a ListDeletedSnapshotsNotFound with default headers values
+func NewListDeletedSnapshotsNotFound() *ListDeletedSnapshotsNotFound {
+	return &ListDeletedSnapshotsNotFound{}
+}
+
+/*ListDeletedSnapshotsNotFound handles this case with default header values.
+
+Service not found or the service does not support soft delete
+*/
+type ListDeletedSnapshotsNotFound struct {
+	Payload *models.Error
+}
+
+func (o *ListDeletedSnapshotsNotFound) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}/deletedSnapshots][%d] listDeletedSnapshotsNotFound  %+v", 404, o.Payload)
+}
+
+func (o *ListDeletedSnapshotsNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *ListDeletedSnapshotsNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewListDeletedSnapshotsInternalServerError creates a ListDeletedSnapshotsInternalServerError with default headers values
+func NewListDeletedSnapshotsInternalServerError() *ListDeletedSnapshotsInternalServerError {
+	return &ListDeletedSnapshotsInternalServerError{}
+}
+
+/*ListDeletedSnapshotsInternalServerError handles this case with default header values.
+
+List failed
+*/
+type ListDeletedSnapshotsInternalServerError struct {
+	Payload *models.Error
+}
+
+func (o *ListDeletedSnapshotsInternalServerError) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}/deletedSnapshots][%d] listDeletedSnapshotsInternalServerError  %+v", 500, o.Payload)
+}
+
+func (o *ListDeletedSnapshotsInternalServerError) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *ListDeletedSnapshotsInternalServerError) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
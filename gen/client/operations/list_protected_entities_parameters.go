@@ -61,16 +61,55 @@ for the list protected entities operation typically these are written to a http.
 */
 type ListProtectedEntitiesParams struct {
 
+	/*ContinuationToken
+	  Continue the list from a previous response, pass its
+	continuationToken with the same orderBy and order
+
+
+	*/
+	ContinuationToken *string
 	/*IdsAfter
-	  Results will be returned that come after this ID
+	  Results will be returned that come after this ID.  Deprecated, use
+	continuationToken.  Cannot be combined with continuationToken or
+	ordering
+
 
 	*/
 	IdsAfter *string
+	/*LabelSelector
+	  Only return protected entities whose labels match this label
+	selector, e.g. app=nginx,tier!=frontend
+
+
+	*/
+	LabelSelector *string
 	/*MaxResults
 	  The maximum number of results to return (fewer results may be returned)
 
 	*/
 	MaxResults *int32
+	/*Name
+	  Only return protected entities with this name
+
+	*/
+	Name *string
+	/*Order
+	  Ascending or descending order, asc by default
+
+	*/
+	Order *string
+	/*OrderBy
+	  The order of the results, id by default
+
+	*/
+	OrderBy *string
+	/*Prefix
+	  Only return protected entities whose ID, without the service,
+	starts with this prefix
+
+
+	*/
+	Prefix *string
 	/*Service
 	  The service to list protected entities from
 
@@ -115,6 +154,17 @@ func (o *ListProtectedEntitiesParams) SetHTTPClient(client *http.Client) {
 	o.HTTPClient = client
 }
 
+// WithContinuationToken adds the continuationToken to the list protected entities params
+func (o *ListProtectedEntitiesParams) WithContinuationToken(continuationToken *string) *ListProtectedEntitiesParams {
+	o.SetContinuationToken(continuationToken)
+	return o
+}
+
+// SetContinuationToken adds the continuationToken to the list protected entities params
+func (o *ListProtectedEntitiesParams) SetContinuationToken(continuationToken *string) {
+	o.ContinuationToken = continuationToken
+}
+
 // WithIdsAfter adds the idsAfter to the list protected entities params
 func (o *ListProtectedEntitiesParams) WithIdsAfter(idsAfter *string) *ListProtectedEntitiesParams {
 	o.SetIdsAfter(idsAfter)
@@ -126,6 +176,17 @@ func (o *ListProtectedEntitiesParams) SetIdsAfter(idsAfter *string) {
 	o.IdsAfter = idsAfter
 }
 
+// WithLabelSelector adds the labelSelector to the list protected entities params
+func (o *ListProtectedEntitiesParams) WithLabelSelector(labelSelector *string) *ListProtectedEntitiesParams {
+	o.SetLabelSelector(labelSelector)
+	return o
+}
+
+// SetLabelSelector adds the labelSelector to the list protected entities params
+func (o *ListProtectedEntitiesParams) SetLabelSelector(labelSelector *string) {
+	o.LabelSelector = labelSelector
+}
+
 // WithMaxResults adds the maxResults to the list protected entities params
 func (o *ListProtectedEntitiesParams) WithMaxResults(maxResults *int32) *ListProtectedEntitiesParams {
 	o.SetMaxResults(maxResults)
@@ -137,6 +198,50 @@ func (o *ListProtectedEntitiesParams) SetMaxResults(maxResults *int32) {
 	o.MaxResults = maxResults
 }
 
+// WithName adds the name to the list protected entities params
+func (o *ListProtectedEntitiesParams) WithName(name *string) *ListProtectedEntitiesParams {
+	o.SetName(name)
+	return o
+}
+
+// SetName adds the name to the list protected entities params
+func (o *ListProtectedEntitiesParams) SetName(name *string) {
+	o.Name = name
+}
+
+// WithOrder adds the order to the list protected entities params
+func (o *ListProtectedEntitiesParams) WithOrder(order *string) *ListProtectedEntitiesParams {
+	o.SetOrder(order)
+	return o
+}
+
+// SetOrder adds the order to the list protected entities params
+func (o *ListProtectedEntitiesParams) SetOrder(order *string) {
+	o.Order = order
+}
+
+// WithOrderBy adds the orderBy to the list protected entities params
+func (o *ListProtectedEntitiesParams) WithOrderBy(orderBy *string) *ListProtectedEntitiesParams {
+	o.SetOrderBy(orderBy)
+	return o
+}
+
+// SetOrderBy adds the orderBy to the list protected entities params
+func (o *ListProtectedEntitiesParams) SetOrderBy(orderBy *string) {
+	o.OrderBy = orderBy
+}
+
+// WithPrefix adds the prefix to the list protected entities params
+func (o *ListProtectedEntitiesParams) WithPrefix(prefix *string) *ListProtectedEntitiesParams {
+	o.SetPrefix(prefix)
+	return o
+}
+
+// SetPrefix adds the prefix to the list protected entities params
+func (o *ListProtectedEntitiesParams) SetPrefix(prefix *string) {
+	o.Prefix = prefix
+}
+
 // WithService adds the service to the list protected entities params
 func (o *ListProtectedEntitiesParams) WithService(service string) *ListProtectedEntitiesParams {
 	o.SetService(service)
@@ -156,6 +261,22 @@ func (o *ListProtectedEntitiesParams) WriteToRequest(r runtime.ClientRequest, re
 	}
 	var res []error
 
+	if o.ContinuationToken != nil {
+
+		// query param continuationToken
+		var qrContinuationToken string
+		if o.ContinuationToken != nil {
+			qrContinuationToken = *o.ContinuationToken
+		}
+		qContinuationToken := qrContinuationToken
+		if qContinuationToken != "" {
+			if err := r.SetQueryParam("continuationToken", qContinuationToken); err != nil {
+				return err
+			}
+		}
+
+	}
+
 	if o.IdsAfter != nil {
 
 		// query param idsAfter
@@ -172,6 +293,22 @@ func (o *ListProtectedEntitiesParams) WriteToRequest(r runtime.ClientRequest, re
 
 	}
 
+	if o.LabelSelector != nil {
+
+		// query param labelSelector
+		var qrLabelSelector string
+		if o.LabelSelector != nil {
+			qrLabelSelector = *o.LabelSelector
+		}
+		qLabelSelector := qrLabelSelector
+		if qLabelSelector != "" {
+			if err := r.SetQueryParam("labelSelector", qLabelSelector); err != nil {
+				return err
+			}
+		}
+
+	}
+
 	if o.MaxResults != nil {
 
 		// query param maxResults
@@ -188,6 +325,70 @@ func (o *ListProtectedEntitiesParams) WriteToRequest(r runtime.ClientRequest, re
 
 	}
 
+	if o.Name != nil {
+
+		// query param name
+		var qrName string
+		if o.Name != nil {
+			qrName = *o.Name
+		}
+		qName := qrName
+		if qName != "" {
+			if err := r.SetQueryParam("name", qName); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.Order != nil {
+
+		// query param order
+		var qrOrder string
+		if o.Order != nil {
+			qrOrder = *o.Order
+		}
+		qOrder := qrOrder
+		if qOrder != "" {
+			if err := r.SetQueryParam("order", qOrder); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.OrderBy != nil {
+
+		// query param orderBy
+		var qrOrderBy string
+		if o.OrderBy != nil {
+			qrOrderBy = *o.OrderBy
+		}
+		qOrderBy := qrOrderBy
+		if qOrderBy != "" {
+			if err := r.SetQueryParam("orderBy", qOrderBy); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.Prefix != nil {
+
+		// query param prefix
+		var qrPrefix string
+		if o.Prefix != nil {
+			qrPrefix = *o.Prefix
+		}
+		qPrefix := qrPrefix
+		if qPrefix != "" {
+			if err := r.SetQueryParam("prefix", qPrefix); err != nil {
+				return err
+			}
+		}
+
+	}
+
 	// path param service
 	if err := r.SetPathParam("service", o.Service); err != nil {
 		return err
@@ -29,12 +29,24 @@ func (o *ListProtectedEntitiesReader) ReadResponse(response runtime.ClientRespon
 			return nil, err
 		}
 		return result, nil
+	case 400:
+		result := NewListProtectedEntitiesBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
 	case 404:
 		result := NewListProtectedEntitiesNotFound()
 		if err := result.readResponse(response, consumer, o.formats); err != nil {
 			return nil, err
 		}
 		return nil, result
+	case 500:
+		result := NewListProtectedEntitiesInternalServerError()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
 
 	default:
 		return nil, runtime.NewAPIError("unknown error", response, response.Code())
@@ -74,6 +86,39 @@ func (o *ListProtectedEntitiesOK) readResponse(response runtime.ClientResponse,
 	return nil
 }
 
+// NewListProtectedEntitiesBadRequest creates a ListProtectedEntitiesBadRequest with default headers values
+func NewListProtectedEntitiesBadRequest() *ListProtectedEntitiesBadRequest {
+	return &ListProtectedEntitiesBadRequest{}
+}
+
+/*ListProtectedEntitiesBadRequest handles this case with default header values.
+
+Invalid label selector or list options
+*/
+type ListProtectedEntitiesBadRequest struct {
+	Payload *models.Error
+}
+
+func (o *ListProtectedEntitiesBadRequest) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}][%d] listProtectedEntitiesBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *ListProtectedEntitiesBadRequest) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *ListProtectedEntitiesBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
 // NewListProtectedEntitiesNotFound creates a ListProtectedEntitiesNotFound with default headers values
 func NewListProtectedEntitiesNotFound() *ListProtectedEntitiesNotFound {
 	return &ListProtectedEntitiesNotFound{}
@@ -81,16 +126,61 @@ func NewListProtectedEntitiesNotFound() *ListProtectedEntitiesNotFound {
 
 /*ListProtectedEntitiesNotFound handles this case with default header values.
 
-Service or Protected Entity not found
+Service not found
 */
 type ListProtectedEntitiesNotFound struct {
+	Payload *models.Error
 }
 
 func (o *ListProtectedEntitiesNotFound) Error() string {
-	return fmt.Sprintf("[GET /astrolabe/{service}][%d] listProtectedEntitiesNotFound ", 404)
+	return fmt.Sprintf("[GET /astrolabe/{service}][%d] listProtectedEntitiesNotFound  %+v", 404, o.Payload)
+}
+
+func (o *ListProtectedEntitiesNotFound) GetPayload() *models.Error {
+	return o.Payload
 }
 
 func (o *ListProtectedEntitiesNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
 
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewListProtectedEntitiesInternalServerError creates a ListProtectedEntitiesInternalServerError with default headers values
+func NewListProtectedEntitiesInternalServerError() *ListProtectedEntitiesInternalServerError {
+	return &ListProtectedEntitiesInternalServerError{}
+}
+
+/*ListProtectedEntitiesInternalServerError handles this case with default header values.
+
+List failed
+*/
+type ListProtectedEntitiesInternalServerError struct {
+	Payload *models.Error
+}
+
+func (o *ListProtectedEntitiesInternalServerError) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}][%d] listProtectedEntitiesInternalServerError  %+v", 500, o.Payload)
+}
+
+func (o *ListProtectedEntitiesInternalServerError) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *ListProtectedEntitiesInternalServerError) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
 	return nil
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/go-openapi/runtime"
 	cr "github.com/go-openapi/runtime/client"
 	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 )
 
 // NewListSnapshotsParams creates a new ListSnapshotsParams object
@@ -60,6 +61,46 @@ for the list snapshots operation typically these are written to a http.Request
 */
 type ListSnapshotsParams struct {
 
+	/*ContinuationToken
+	  Continue the list from a previous response, pass its
+	continuationToken with the same orderBy and order
+
+
+	*/
+	ContinuationToken *string
+	/*LabelSelector
+	  Only return snapshots whose labels match this label selector,
+	e.g. app=nginx,tier!=frontend
+
+
+	*/
+	LabelSelector *string
+	/*MaxResults
+	  The maximum number of results to return (fewer results may be returned)
+
+	*/
+	MaxResults *int32
+	/*Name
+	  Only return snapshots with this name
+
+	*/
+	Name *string
+	/*Order
+	  Ascending or descending order, asc by default
+
+	*/
+	Order *string
+	/*OrderBy
+	  The order of the results, id by default
+
+	*/
+	OrderBy *string
+	/*Prefix
+	  Only return snapshots whose snapshot ID starts with this prefix
+
+
+	*/
+	Prefix *string
 	/*ProtectedEntityID
 	  The protected entity ID to retrieve info for
 
@@ -109,6 +150,83 @@ func (o *ListSnapshotsParams) SetHTTPClient(client *http.Client) {
 	o.HTTPClient = client
 }
 
+// WithContinuationToken adds the continuationToken to the list snapshots params
+func (o *ListSnapshotsParams) WithContinuationToken(continuationToken *string) *ListSnapshotsParams {
+	o.SetContinuationToken(continuationToken)
+	return o
+}
+
+// SetContinuationToken adds the continuationToken to the list snapshots params
+func (o *ListSnapshotsParams) SetContinuationToken(continuationToken *string) {
+	o.ContinuationToken = continuationToken
+}
+
+// WithLabelSelector adds the labelSelector to the list snapshots params
+func (o *ListSnapshotsParams) WithLabelSelector(labelSelector *string) *ListSnapshotsParams {
+	o.SetLabelSelector(labelSelector)
+	return o
+}
+
+// SetLabelSelector adds the labelSelector to the list snapshots params
+func (o *ListSnapshotsParams) SetLabelSelector(labelSelector *string) {
+	o.LabelSelector = labelSelector
+}
+
+// WithMaxResults adds the maxResults to the list snapshots params
+func (o *ListSnapshotsParams) WithMaxResults(maxResults *int32) *ListSnapshotsParams {
+	o.SetMaxResults(maxResults)
+	return o
+}
+
+// SetMaxResults adds the maxResults to the list snapshots params
+func (o *ListSnapshotsParams) SetMaxResults(maxResults *int32) {
+	o.MaxResults = maxResults
+}
+
+// WithName adds the name to the list snapshots params
+func (o *ListSnapshotsParams) WithName(name *string) *ListSnapshotsParams {
+	o.SetName(name)
+	return o
+}
+
+// SetName adds the name to the list snapshots params
+func (o *ListSnapshotsParams) SetName(name *string) {
+	o.Name = name
+}
+
+// WithOrder adds the order to the list snapshots params
+func (o *ListSnapshotsParams) WithOrder(order *string) *ListSnapshotsParams {
+	o.SetOrder(order)
+	return o
+}
+
+// SetOrder adds the order to the list snapshots params
+func (o *ListSnapshotsParams) SetOrder(order *string) {
+	o.Order = order
+}
+
+// WithOrderBy adds the orderBy to the list snapshots params
+func (o *ListSnapshotsParams) WithOrderBy(orderBy *string) *ListSnapshotsParams {
+	o.SetOrderBy(orderBy)
+	return o
+}
+
+// SetOrderBy adds the orderBy to the list snapshots params
+func (o *ListSnapshotsParams) SetOrderBy(orderBy *string) {
+	o.OrderBy = orderBy
+}
+
+// WithPrefix adds the prefix to the list snapshots params
+func (o *ListSnapshotsParams) WithPrefix(prefix *string) *ListSnapshotsParams {
+	o.SetPrefix(prefix)
+	return o
+}
+
+// SetPrefix adds the prefix to the list snapshots params
+func (o *ListSnapshotsParams) SetPrefix(prefix *string) {
+	o.Prefix = prefix
+}
+
 // WithProtectedEntityID adds the protectedEntityID to the list snapshots params
 func (o *ListSnapshotsParams) WithProtectedEntityID(protectedEntityID string) *ListSnapshotsParams {
 	o.SetProtectedEntityID(protectedEntityID)
@@ -139,6 +257,118 @@ func (o *ListSnapshotsParams) WriteToRequest(r runtime.ClientRequest, reg strfmt
 	}
 	var res []error
 
+	if o.ContinuationToken != nil {
+
+		// query param continuationToken
+		var qrContinuationToken string
+		if o.ContinuationToken != nil {
+			qrContinuationToken = *o.ContinuationToken
+		}
+		qContinuationToken := qrContinuationToken
+		if qContinuationToken != "" {
+			if err := r.SetQueryParam("continuationToken", qContinuationToken); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.LabelSelector != nil {
+
+		// query param labelSelector
+		var qrLabelSelector string
+		if o.LabelSelector != nil {
+			qrLabelSelector = *o.LabelSelector
+		}
+		qLabelSelector := qrLabelSelector
+		if qLabelSelector != "" {
+			if err := r.SetQueryParam("labelSelector", qLabelSelector); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.MaxResults != nil {
+
+		// query param maxResults
+		var qrMaxResults int32
+		if o.MaxResults != nil {
+			qrMaxResults = *o.MaxResults
+		}
+		qMaxResults := swag.FormatInt32(qrMaxResults)
+		if qMaxResults != "" {
+			if err := r.SetQueryParam("maxResults", qMaxResults); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.Name != nil {
+
+		// query param name
+		var qrName string
+		if o.Name != nil {
+			qrName = *o.Name
+		}
+		qName := qrName
+		if qName != "" {
+			if err := r.SetQueryParam("name", qName); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.Order != nil {
+
+		// query param order
+		var qrOrder string
+		if o.Order != nil {
+			qrOrder = *o.Order
+		}
+		qOrder := qrOrder
+		if qOrder != "" {
+			if err := r.SetQueryParam("order", qOrder); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.OrderBy != nil {
+
+		// query param orderBy
+		var qrOrderBy string
+		if o.OrderBy != nil {
+			qrOrderBy = *o.OrderBy
+		}
+		qOrderBy := qrOrderBy
+		if qOrderBy != "" {
+			if err := r.SetQueryParam("orderBy", qOrderBy); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.Prefix != nil {
+
+		// query param prefix
+		var qrPrefix string
+		if o.Prefix != nil {
+			qrPrefix = *o.Prefix
+		}
+		qPrefix := qrPrefix
+		if qPrefix != "" {
+			if err := r.SetQueryParam("prefix", qPrefix); err != nil {
+				return err
+			}
+		}
+
+	}
+
 	// path param protectedEntityID
 	if err := r.SetPathParam("protectedEntityID", o.ProtectedEntityID); err != nil {
 		return err
@@ -29,12 +29,24 @@ func (o *ListSnapshotsReader) ReadResponse(response runtime.ClientResponse, cons
 			return nil, err
 		}
 		return result, nil
+	case 400:
+		result := NewListSnapshotsBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
 	case 404:
 		result := NewListSnapshotsNotFound()
 		if err := result.readResponse(response, consumer, o.formats); err != nil {
 			return nil, err
 		}
 		return nil, result
+	case 500:
+		result := NewListSnapshotsInternalServerError()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
 
 	default:
 		return nil, runtime.NewAPIError("unknown error", response, response.Code())
@@ -74,6 +86,39 @@ func (o *ListSnapshotsOK) readResponse(response runtime.ClientResponse, consumer
 	return nil
 }
 
+// NewListSnapshotsBadRequest creates a ListSnapshotsBadRequest with default headers values
+func NewListSnapshotsBadRequest() *ListSnapshotsBadRequest {
+	return &ListSnapshotsBadRequest{}
+}
+
+/*ListSnapshotsBadRequest handles this case with default header values.
+
+Invalid label selector or list options
+*/
+type ListSnapshotsBadRequest struct {
+	Payload *models.Error
+}
+
+func (o *ListSnapshotsBadRequest) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}/snapshots][%d] listSnapshotsBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *ListSnapshotsBadRequest) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *ListSnapshotsBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
 // NewListSnapshotsNotFound creates a ListSnapshotsNotFound with default headers values
 func NewListSnapshotsNotFound() *ListSnapshotsNotFound {
 	return &ListSnapshotsNotFound{}
@@ -84,13 +129,58 @@ func NewListSnapshotsNotFound() *ListSnapshotsNotFound {
 Service or Protected Entity not found
 */
 type ListSnapshotsNotFound struct {
+	Payload *models.Error
 }
 
 func (o *ListSnapshotsNotFound) Error() string {
-	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}/snapshots][%d] listSnapshotsNotFound ", 404)
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}/snapshots][%d] listSnapshotsNotFound  %+v", 404, o.Payload)
+}
+
+func (o *ListSnapshotsNotFound) GetPayload() *models.Error {
+	return o.Payload
 }
 
 func (o *ListSnapshotsNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
 
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewListSnapshotsInternalServerError creates a ListSnapshotsInternalServerError with default headers values
+func NewListSnapshotsInternalServerError() *ListSnapshotsInternalServerError {
+	return &ListSnapshotsInternalServerError{}
+}
+
+/*ListSnapshotsInternalServerError handles this case with default header values.
+
+List failed
+*/
+type ListSnapshotsInternalServerError struct {
+	Payload *models.Error
+}
+
+func (o *ListSnapshotsInternalServerError) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/{service}/{protectedEntityID}/snapshots][%d] listSnapshotsInternalServerError  %+v", 500, o.Payload)
+}
+
+func (o *ListSnapshotsInternalServerError) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *ListSnapshotsInternalServerError) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
 	return nil
 }
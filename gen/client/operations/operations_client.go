@@ -7,6 +7,7 @@ package operations
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
@@ -27,20 +28,26 @@ type Client struct {
 
 // ClientService is the interface for Client methods
 type ClientService interface {
-	GetAstrolabeTasksNexusTaskNexusID(params *GetAstrolabeTasksNexusTaskNexusIDParams) (*GetAstrolabeTasksNexusTaskNexusIDOK, error)
-
-	PostAstrolabeTasksNexus(params *PostAstrolabeTasksNexusParams) (*PostAstrolabeTasksNexusOK, error)
-
 	CopyProtectedEntity(params *CopyProtectedEntityParams) (*CopyProtectedEntityAccepted, error)
 
 	CreateSnapshot(params *CreateSnapshotParams) (*CreateSnapshotOK, error)
 
+	CreateTaskNexus(params *CreateTaskNexusParams) (*CreateTaskNexusOK, error)
+
 	DeleteProtectedEntity(params *DeleteProtectedEntityParams) (*DeleteProtectedEntityOK, error)
 
 	GetProtectedEntityInfo(params *GetProtectedEntityInfoParams) (*GetProtectedEntityInfoOK, error)
 
+	GetRetention(params *GetRetentionParams) (*GetRetentionOK, error)
+
 	GetTaskInfo(params *GetTaskInfoParams) (*GetTaskInfoOK, error)
 
+	GetUsage(params *GetUsageParams) (*GetUsageOK, error)
+
+	ListAuditEvents(params *ListAuditEventsParams) (*ListAuditEventsOK, error)
+
+	ListDeletedSnapshots(params *ListDeletedSnapshotsParams) (*ListDeletedSnapshotsOK, error)
+
 	ListProtectedEntities(params *ListProtectedEntitiesParams) (*ListProtectedEntitiesOK, error)
 
 	ListServices(params *ListServicesParams) (*ListServicesOK, error)
@@ -51,219 +58,231 @@ type ClientService interface {
 
 	ListTasks(params *ListTasksParams) (*ListTasksOK, error)
 
+	RehydrateSnapshot(params *RehydrateSnapshotParams) (*RehydrateSnapshotAccepted, error)
+
+	UndeleteSnapshot(params *UndeleteSnapshotParams) (*UndeleteSnapshotOK, error)
+
+	UpdateRetention(params *UpdateRetentionParams) (*UpdateRetentionOK, error)
+
+	WaitOnTaskNexus(params *WaitOnTaskNexusParams) (*WaitOnTaskNexusOK, error)
+
+	WatchTaskEvents(params *WatchTaskEventsParams, writer io.Writer) (*WatchTaskEventsOK, error)
+
 	SetTransport(transport runtime.ClientTransport)
 }
 
 /*
-  GetAstrolabeTasksNexusTaskNexusID get astrolabe tasks nexus task nexus ID API
+  CopyProtectedEntity Copy a protected entity into the repository.  There is no option to
+embed data on this path, for a self-contained or partially
+self-contained object, use the restore from zip file option in the S3
+API REST API
+
 */
-func (a *Client) GetAstrolabeTasksNexusTaskNexusID(params *GetAstrolabeTasksNexusTaskNexusIDParams) (*GetAstrolabeTasksNexusTaskNexusIDOK, error) {
+func (a *Client) CopyProtectedEntity(params *CopyProtectedEntityParams) (*CopyProtectedEntityAccepted, error) {
 	// TODO: Validate the params before sending
 	if params == nil {
-		params = NewGetAstrolabeTasksNexusTaskNexusIDParams()
+		params = NewCopyProtectedEntityParams()
 	}
 
 	result, err := a.transport.Submit(&runtime.ClientOperation{
-		ID:                 "GetAstrolabeTasksNexusTaskNexusID",
-		Method:             "GET",
-		PathPattern:        "/astrolabe/tasks/nexus/{taskNexusID}",
+		ID:                 "copyProtectedEntity",
+		Method:             "POST",
+		PathPattern:        "/astrolabe/{service}",
 		ProducesMediaTypes: []string{"application/json"},
 		ConsumesMediaTypes: []string{"application/json"},
 		Schemes:            []string{"https"},
 		Params:             params,
-		Reader:             &GetAstrolabeTasksNexusTaskNexusIDReader{formats: a.formats},
+		Reader:             &CopyProtectedEntityReader{formats: a.formats},
 		Context:            params.Context,
 		Client:             params.HTTPClient,
 	})
 	if err != nil {
 		return nil, err
 	}
-	success, ok := result.(*GetAstrolabeTasksNexusTaskNexusIDOK)
+	success, ok := result.(*CopyProtectedEntityAccepted)
 	if ok {
 		return success, nil
 	}
 	// unexpected success response
 	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
-	msg := fmt.Sprintf("unexpected success response for GetAstrolabeTasksNexusTaskNexusID: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	msg := fmt.Sprintf("unexpected success response for copyProtectedEntity: API contract not enforced by server. Client expected to get an error, but got: %T", result)
 	panic(msg)
 }
 
 /*
-  PostAstrolabeTasksNexus Creates a new nexus for monitoring task completion
+  CreateSnapshot Creates a new snapshot for this protected entity
+
 */
-func (a *Client) PostAstrolabeTasksNexus(params *PostAstrolabeTasksNexusParams) (*PostAstrolabeTasksNexusOK, error) {
+func (a *Client) CreateSnapshot(params *CreateSnapshotParams) (*CreateSnapshotOK, error) {
 	// TODO: Validate the params before sending
 	if params == nil {
-		params = NewPostAstrolabeTasksNexusParams()
+		params = NewCreateSnapshotParams()
 	}
 
 	result, err := a.transport.Submit(&runtime.ClientOperation{
-		ID:                 "PostAstrolabeTasksNexus",
+		ID:                 "createSnapshot",
 		Method:             "POST",
-		PathPattern:        "/astrolabe/tasks/nexus",
+		PathPattern:        "/astrolabe/{service}/{protectedEntityID}/snapshots",
 		ProducesMediaTypes: []string{"application/json"},
 		ConsumesMediaTypes: []string{"application/json"},
 		Schemes:            []string{"https"},
 		Params:             params,
-		Reader:             &PostAstrolabeTasksNexusReader{formats: a.formats},
+		Reader:             &CreateSnapshotReader{formats: a.formats},
 		Context:            params.Context,
 		Client:             params.HTTPClient,
 	})
 	if err != nil {
 		return nil, err
 	}
-	success, ok := result.(*PostAstrolabeTasksNexusOK)
+	success, ok := result.(*CreateSnapshotOK)
 	if ok {
 		return success, nil
 	}
 	// unexpected success response
 	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
-	msg := fmt.Sprintf("unexpected success response for PostAstrolabeTasksNexus: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	msg := fmt.Sprintf("unexpected success response for createSnapshot: API contract not enforced by server. Client expected to get an error, but got: %T", result)
 	panic(msg)
 }
 
 /*
-  CopyProtectedEntity Copy a protected entity into the repository.  There is no option to
-embed data on this path, for a self-contained or partially
-self-contained object, use the restore from zip file option in the S3
-API REST API
-
+  CreateTaskNexus Creates a new nexus for monitoring task completion
 */
-func (a *Client) CopyProtectedEntity(params *CopyProtectedEntityParams) (*CopyProtectedEntityAccepted, error) {
+func (a *Client) CreateTaskNexus(params *CreateTaskNexusParams) (*CreateTaskNexusOK, error) {
 	// TODO: Validate the params before sending
 	if params == nil {
-		params = NewCopyProtectedEntityParams()
+		params = NewCreateTaskNexusParams()
 	}
 
 	result, err := a.transport.Submit(&runtime.ClientOperation{
-		ID:                 "copyProtectedEntity",
+		ID:                 "createTaskNexus",
 		Method:             "POST",
-		PathPattern:        "/astrolabe/{service}",
+		PathPattern:        "/astrolabe/tasks/nexus",
 		ProducesMediaTypes: []string{"application/json"},
 		ConsumesMediaTypes: []string{"application/json"},
 		Schemes:            []string{"https"},
 		Params:             params,
-		Reader:             &CopyProtectedEntityReader{formats: a.formats},
+		Reader:             &CreateTaskNexusReader{formats: a.formats},
 		Context:            params.Context,
 		Client:             params.HTTPClient,
 	})
 	if err != nil {
 		return nil, err
 	}
-	success, ok := result.(*CopyProtectedEntityAccepted)
+	success, ok := result.(*CreateTaskNexusOK)
 	if ok {
 		return success, nil
 	}
 	// unexpected success response
 	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
-	msg := fmt.Sprintf("unexpected success response for copyProtectedEntity: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	msg := fmt.Sprintf("unexpected success response for createTaskNexus: API contract not enforced by server. Client expected to get an error, but got: %T", result)
 	panic(msg)
 }
 
 /*
-  CreateSnapshot Creates a new snapshot for this protected entity
+  DeleteProtectedEntity Deletes a protected entity or snapshot of a protected entity (if the
+snapshot ID is specified).  Services that support soft delete keep
+deleted snapshots for an undelete window, see undeleteSnapshot
 
 */
-func (a *Client) CreateSnapshot(params *CreateSnapshotParams) (*CreateSnapshotOK, error) {
+func (a *Client) DeleteProtectedEntity(params *DeleteProtectedEntityParams) (*DeleteProtectedEntityOK, error) {
 	// TODO: Validate the params before sending
 	if params == nil {
-		params = NewCreateSnapshotParams()
+		params = NewDeleteProtectedEntityParams()
 	}
 
 	result, err := a.transport.Submit(&runtime.ClientOperation{
-		ID:                 "createSnapshot",
-		Method:             "POST",
-		PathPattern:        "/astrolabe/{service}/{protectedEntityID}/snapshots",
+		ID:                 "deleteProtectedEntity",
+		Method:             "DELETE",
+		PathPattern:        "/astrolabe/{service}/{protectedEntityID}",
 		ProducesMediaTypes: []string{"application/json"},
 		ConsumesMediaTypes: []string{"application/json"},
 		Schemes:            []string{"https"},
 		Params:             params,
-		Reader:             &CreateSnapshotReader{formats: a.formats},
+		Reader:             &DeleteProtectedEntityReader{formats: a.formats},
 		Context:            params.Context,
 		Client:             params.HTTPClient,
 	})
 	if err != nil {
 		return nil, err
 	}
-	success, ok := result.(*CreateSnapshotOK)
+	success, ok := result.(*DeleteProtectedEntityOK)
 	if ok {
 		return success, nil
 	}
 	// unexpected success response
 	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
-	msg := fmt.Sprintf("unexpected success response for createSnapshot: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	msg := fmt.Sprintf("unexpected success response for deleteProtectedEntity: API contract not enforced by server. Client expected to get an error, but got: %T", result)
 	panic(msg)
 }
 
 /*
-  DeleteProtectedEntity Deletes a protected entity or snapshot of a protected entity (if the
-snapshot ID is specified)
+  GetProtectedEntityInfo Get the info for a Protected Entity including name, data access and
+components
 
 */
-func (a *Client) DeleteProtectedEntity(params *DeleteProtectedEntityParams) (*DeleteProtectedEntityOK, error) {
+func (a *Client) GetProtectedEntityInfo(params *GetProtectedEntityInfoParams) (*GetProtectedEntityInfoOK, error) {
 	// TODO: Validate the params before sending
 	if params == nil {
-		params = NewDeleteProtectedEntityParams()
+		params = NewGetProtectedEntityInfoParams()
 	}
 
 	result, err := a.transport.Submit(&runtime.ClientOperation{
-		ID:                 "deleteProtectedEntity",
-		Method:             "DELETE",
+		ID:                 "getProtectedEntityInfo",
+		Method:             "GET",
 		PathPattern:        "/astrolabe/{service}/{protectedEntityID}",
 		ProducesMediaTypes: []string{"application/json"},
 		ConsumesMediaTypes: []string{"application/json"},
 		Schemes:            []string{"https"},
 		Params:             params,
-		Reader:             &DeleteProtectedEntityReader{formats: a.formats},
+		Reader:             &GetProtectedEntityInfoReader{formats: a.formats},
 		Context:            params.Context,
 		Client:             params.HTTPClient,
 	})
 	if err != nil {
 		return nil, err
 	}
-	success, ok := result.(*DeleteProtectedEntityOK)
+	success, ok := result.(*GetProtectedEntityInfoOK)
 	if ok {
 		return success, nil
 	}
 	// unexpected success response
 	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
-	msg := fmt.Sprintf("unexpected success response for deleteProtectedEntity: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	msg := fmt.Sprintf("unexpected success response for getProtectedEntityInfo: API contract not enforced by server. Client expected to get an error, but got: %T", result)
 	panic(msg)
 }
 
 /*
-  GetProtectedEntityInfo Get the info for a Protected Entity including name, data access and
-components
+  GetRetention Gets the WORM retention and legal hold for a snapshot stored in a repository
 
 */
-func (a *Client) GetProtectedEntityInfo(params *GetProtectedEntityInfoParams) (*GetProtectedEntityInfoOK, error) {
+func (a *Client) GetRetention(params *GetRetentionParams) (*GetRetentionOK, error) {
 	// TODO: Validate the params before sending
 	if params == nil {
-		params = NewGetProtectedEntityInfoParams()
+		params = NewGetRetentionParams()
 	}
 
 	result, err := a.transport.Submit(&runtime.ClientOperation{
-		ID:                 "getProtectedEntityInfo",
+		ID:                 "getRetention",
 		Method:             "GET",
-		PathPattern:        "/astrolabe/{service}/{protectedEntityID}",
+		PathPattern:        "/astrolabe/{service}/{protectedEntityID}/retention",
 		ProducesMediaTypes: []string{"application/json"},
 		ConsumesMediaTypes: []string{"application/json"},
 		Schemes:            []string{"https"},
 		Params:             params,
-		Reader:             &GetProtectedEntityInfoReader{formats: a.formats},
+		Reader:             &GetRetentionReader{formats: a.formats},
 		Context:            params.Context,
 		Client:             params.HTTPClient,
 	})
 	if err != nil {
 		return nil, err
 	}
-	success, ok := result.(*GetProtectedEntityInfoOK)
+	success, ok := result.(*GetRetentionOK)
 	if ok {
 		return success, nil
 	}
 	// unexpected success response
 	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
-	msg := fmt.Sprintf("unexpected success response for getProtectedEntityInfo: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	msg := fmt.Sprintf("unexpected success response for getRetention: API contract not enforced by server. Client expected to get an error, but got: %T", result)
 	panic(msg)
 }
 
@@ -301,11 +320,124 @@ func (a *Client) GetTaskInfo(params *GetTaskInfoParams) (*GetTaskInfoOK, error)
 	panic(msg)
 }
 
+/*
+  GetUsage reports repository usage
+
+  Reports logical and stored bytes per protected entity, per snapshot and per service for the services
+that store protected entities (repositories).  Services that do not store data are not included.
+
+*/
+func (a *Client) GetUsage(params *GetUsageParams) (*GetUsageOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewGetUsageParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "getUsage",
+		Method:             "GET",
+		PathPattern:        "/astrolabe/usage",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &GetUsageReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*GetUsageOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for getUsage: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+  ListAuditEvents lists audit events
+
+  Returns the events recorded in the audit log for mutating calls (snapshot, copy, delete, retention,
+undelete and rehydrate) and for the tasks they started.
+
+*/
+func (a *Client) ListAuditEvents(params *ListAuditEventsParams) (*ListAuditEventsOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewListAuditEventsParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "listAuditEvents",
+		Method:             "GET",
+		PathPattern:        "/astrolabe/audit",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &ListAuditEventsReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*ListAuditEventsOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for listAuditEvents: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+  ListDeletedSnapshots Lists the deleted snapshots of this protected entity that can still be
+restored with undeleteSnapshot
+
+*/
+func (a *Client) ListDeletedSnapshots(params *ListDeletedSnapshotsParams) (*ListDeletedSnapshotsOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewListDeletedSnapshotsParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "listDeletedSnapshots",
+		Method:             "GET",
+		PathPattern:        "/astrolabe/{service}/{protectedEntityID}/deletedSnapshots",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &ListDeletedSnapshotsReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*ListDeletedSnapshotsOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for listDeletedSnapshots: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
 /*
   ListProtectedEntities List protected entities for the service.  Results will be returned in
-canonical ID order (string sorted).  Fewer results may be returned than
-expected, the ProtectedEntityList has a field specifying if the list has
-been truncated.
+canonical ID order (string sorted) unless orderBy or order are set.
+Fewer results may be returned than expected, the ProtectedEntityList
+has a field specifying if the list has been truncated and a
+continuationToken for the next page.
 
 */
 func (a *Client) ListProtectedEntities(params *ListProtectedEntitiesParams) (*ListProtectedEntitiesOK, error) {
@@ -377,7 +509,8 @@ func (a *Client) ListServices(params *ListServicesParams) (*ListServicesOK, erro
 }
 
 /*
-  ListSnapshots Gets the list of snapshots for this protected entity
+  ListSnapshots Gets the list of snapshots for this protected entity.  Results will be
+returned in canonical ID order unless orderBy or order are set.
 
 */
 func (a *Client) ListSnapshots(params *ListSnapshotsParams) (*ListSnapshotsOK, error) {
@@ -479,6 +612,185 @@ func (a *Client) ListTasks(params *ListTasksParams) (*ListTasksOK, error) {
 	panic(msg)
 }
 
+/*
+  RehydrateSnapshot Restores the data of a snapshot that has been moved to an archive storage tier so that it can be read.
+Restores can take hours, use getTaskInfo to follow the returned task
+
+*/
+func (a *Client) RehydrateSnapshot(params *RehydrateSnapshotParams) (*RehydrateSnapshotAccepted, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewRehydrateSnapshotParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "rehydrateSnapshot",
+		Method:             "POST",
+		PathPattern:        "/astrolabe/{service}/{protectedEntityID}/rehydrate",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &RehydrateSnapshotReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*RehydrateSnapshotAccepted)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for rehydrateSnapshot: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+  UndeleteSnapshot Restores a deleted snapshot before it is purged
+
+*/
+func (a *Client) UndeleteSnapshot(params *UndeleteSnapshotParams) (*UndeleteSnapshotOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewUndeleteSnapshotParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "undeleteSnapshot",
+		Method:             "POST",
+		PathPattern:        "/astrolabe/{service}/{protectedEntityID}/undelete",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &UndeleteSnapshotReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*UndeleteSnapshotOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for undeleteSnapshot: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+  UpdateRetention Extends the retention of a snapshot and/or places or releases a legal hold.
+Retention can only be extended, never shortened.
+
+*/
+func (a *Client) UpdateRetention(params *UpdateRetentionParams) (*UpdateRetentionOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewUpdateRetentionParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "updateRetention",
+		Method:             "PUT",
+		PathPattern:        "/astrolabe/{service}/{protectedEntityID}/retention",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &UpdateRetentionReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*UpdateRetentionOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for updateRetention: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+  WaitOnTaskNexus Waits for tasks associated with the nexus to finish.  Tasks started after the nexus was created are
+associated with it
+
+*/
+func (a *Client) WaitOnTaskNexus(params *WaitOnTaskNexusParams) (*WaitOnTaskNexusOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewWaitOnTaskNexusParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "waitOnTaskNexus",
+		Method:             "GET",
+		PathPattern:        "/astrolabe/tasks/nexus/{taskNexusID}",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &WaitOnTaskNexusReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*WaitOnTaskNexusOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for waitOnTaskNexus: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+  WatchTaskEvents Streams the status and progress changes of one task or of all tasks as Server-Sent Events.  When the stream
+starts, the current state of the tasks is sent, unless the client resumes with Last-Event-ID.
+
+*/
+func (a *Client) WatchTaskEvents(params *WatchTaskEventsParams, writer io.Writer) (*WatchTaskEventsOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewWatchTaskEventsParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "watchTaskEvents",
+		Method:             "GET",
+		PathPattern:        "/astrolabe/tasks/events",
+		ProducesMediaTypes: []string{"text/event-stream"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &WatchTaskEventsReader{formats: a.formats, writer: writer},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*WatchTaskEventsOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for watchTaskEvents: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
 // SetTransport changes the transport on the client
 func (a *Client) SetTransport(transport runtime.ClientTransport) {
 	a.transport = transport
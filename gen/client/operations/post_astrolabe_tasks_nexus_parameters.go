@@ -1,112 +0,0 @@
-// Code generated by go-swagger; DO NOT EDIT.
-
-package operations
-
-// This file was generated by the swagger tool.
-// Editing this file might prove futile when you re-run the swagger generate command
-
-import (
-	"context"
-	"net/http"
-	"time"
-
-	"github.com/go-openapi/errors"
-	"github.com/go-openapi/runtime"
-	cr "github.com/go-openapi/runtime/client"
-	"github.com/go-openapi/strfmt"
-)
-
-// NewPostAstrolabeTasksNexusParams creates a new PostAstrolabeTasksNexusParams object
-// with the default values initialized.
-func NewPostAstrolabeTasksNexusParams() *PostAstrolabeTasksNexusParams {
-
-	return &PostAstrolabeTasksNexusParams{
-
-		timeout: cr.DefaultTimeout,
-	}
-}
-
-// NewPostAstrolabeTasksNexusParamsWithTimeout creates a new PostAstrolabeTasksNexusParams object
-// with the default values initialized, and the ability to set a timeout on a request
-func NewPostAstrolabeTasksNexusParamsWithTimeout(timeout time.Duration) *PostAstrolabeTasksNexusParams {
-
-	return &PostAstrolabeTasksNexusParams{
-
-		timeout: timeout,
-	}
-}
-
-// NewPostAstrolabeTasksNexusParamsWithContext creates a new PostAstrolabeTasksNexusParams object
-// with the default values initialized, and the ability to set a context for a request
-func NewPostAstrolabeTasksNexusParamsWithContext(ctx context.Context) *PostAstrolabeTasksNexusParams {
-
-	return &PostAstrolabeTasksNexusParams{
-
-		Context: ctx,
-	}
-}
-
-// NewPostAstrolabeTasksNexusParamsWithHTTPClient creates a new PostAstrolabeTasksNexusParams object
-// with the default values initialized, and the ability to set a custom HTTPClient for a request
-func NewPostAstrolabeTasksNexusParamsWithHTTPClient(client *http.Client) *PostAstrolabeTasksNexusParams {
-
-	return &PostAstrolabeTasksNexusParams{
-		HTTPClient: client,
-	}
-}
-
-/*PostAstrolabeTasksNexusParams contains all the parameters to send to the API endpoint
-for the post astrolabe tasks nexus operation typically these are written to a http.Request
-*/
-type PostAstrolabeTasksNexusParams struct {
-	timeout    time.Duration
-	Context    context.Context
-	HTTPClient *http.Client
-}
-
-// WithTimeout adds the timeout to the post astrolabe tasks nexus params
-func (o *PostAstrolabeTasksNexusParams) WithTimeout(timeout time.Duration) *PostAstrolabeTasksNexusParams {
-	o.SetTimeout(timeout)
-	return o
-}
-
-// SetTimeout adds the timeout to the post astrolabe tasks nexus params
-func (o *PostAstrolabeTasksNexusParams) SetTimeout(timeout time.Duration) {
-	o.timeout = timeout
-}
-
-// WithContext adds the context to the post astrolabe tasks nexus params
-func (o *PostAstrolabeTasksNexusParams) WithContext(ctx context.Context) *PostAstrolabeTasksNexusParams {
-	o.SetContext(ctx)
-	return o
-}
-
-// SetContext adds the context to the post astrolabe tasks nexus params
-func (o *PostAstrolabeTasksNexusParams) SetContext(ctx context.Context) {
-	o.Context = ctx
-}
-
-// WithHTTPClient adds the HTTPClient to the post astrolabe tasks nexus params
-func (o *PostAstrolabeTasksNexusParams) WithHTTPClient(client *http.Client) *PostAstrolabeTasksNexusParams {
-	o.SetHTTPClient(client)
-	return o
-}
-
-// SetHTTPClient adds the HTTPClient to the post astrolabe tasks nexus params
-func (o *PostAstrolabeTasksNexusParams) SetHTTPClient(client *http.Client) {
-	o.HTTPClient = client
-}
-
-// WriteToRequest writes these params to a swagger request
-func (o *PostAstrolabeTasksNexusParams) WriteToRequest(r runtime.ClientRequest, reg strfmt.Registry) error {
-
-	if err := r.SetTimeout(o.timeout); err != nil {
-		return err
-	}
-	var res []error
-
-	if len(res) > 0 {
-		return errors.CompositeValidationError(res...)
-	}
-	return nil
-}
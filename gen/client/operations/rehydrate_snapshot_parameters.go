@@ -0,0 +1,186 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	cr "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+)
+
+// NewRehydrateSnapshotParams creates a new RehydrateSnapshotParams object
+// with the default values initialized.
+func NewRehydrateSnapshotParams() *RehydrateSnapshotParams {
+	var ()
+	return &RehydrateSnapshotParams{
+
+		timeout: cr.DefaultTimeout,
+	}
+}
+
+// NewRehydrateSnapshotParamsWithTimeout creates a new RehydrateSnapshotParams object
+// with the default values initialized, and the ability to set a timeout on a request
+func NewRehydrateSnapshotParamsWithTimeout(timeout time.Duration) *RehydrateSnapshotParams {
+	var ()
+	return &RehydrateSnapshotParams{
+
+		timeout: timeout,
+	}
+}
+
+// NewRehydrateSnapshotParamsWithContext creates a new RehydrateSnapshotParams object
+// with the default values initialized, and the ability to set a context for a request
+func NewRehydrateSnapshotParamsWithContext(ctx context.Context) *RehydrateSnapshotParams {
+	var ()
+	return &RehydrateSnapshotParams{
+
+		Context: ctx,
+	}
+}
+
+// NewRehydrateSnapshotParamsWithHTTPClient creates a new RehydrateSnapshotParams object
+// with the default values initialized, and the ability to set a custom HTTPClient for a request
+func NewRehydrateSnapshotParamsWithHTTPClient(client *http.Client) *RehydrateSnapshotParams {
+	var ()
+	return &RehydrateSnapshotParams{
+		HTTPClient: client,
+	}
+}
+
+/*RehydrateSnapshotParams contains all the parameters to send to the API endpoint
+for the rehydrate snapshot operation typically these are written to a http.Request
+*/
+type RehydrateSnapshotParams struct {
+
+	/*IdempotencyKey
+	  A key chosen by the client that identifies this request.  If a
+	request with the same key has already completed successfully within
+	the server's idempotency window, its result is returned instead of
+	running the operation again.  Keys are scoped to the caller and
+	cannot be reused for a different request.
+
+
+	*/
+	IdempotencyKey *string
+	/*ProtectedEntityID
+	  The protected entity snapshot ID to rehydrate
+
+	*/
+	ProtectedEntityID string
+	/*Service
+	  The service for the protected entity
+
+	*/
+	Service string
+
+	timeout    time.Duration
+	Context    context.Context
+	HTTPClient *http.Client
+}
+
+// WithTimeout adds the timeout to the rehydrate snapshot params
+func (o *RehydrateSnapshotParams) WithTimeout(timeout time.Duration) *RehydrateSnapshotParams {
+	o.SetTimeout(timeout)
+	return o
+}
+
+// SetTimeout adds the timeout to the rehydrate snapshot params
+func (o *RehydrateSnapshotParams) SetTimeout(timeout time.Duration) {
+	o.timeout = timeout
+}
+
+// WithContext adds the context to the rehydrate snapshot params
+func (o *RehydrateSnapshotParams) WithContext(ctx context.Context) *RehydrateSnapshotParams {
+	o.SetContext(ctx)
+	return o
+}
+
+// SetContext adds the context to the rehydrate snapshot params
+func (o *RehydrateSnapshotParams) SetContext(ctx context.Context) {
+	o.Context = ctx
+}
+
+// WithHTTPClient adds the HTTPClient to the rehydrate snapshot params
+func (o *RehydrateSnapshotParams) WithHTTPClient(client *http.Client) *RehydrateSnapshotParams {
+	o.SetHTTPClient(client)
+	return o
+}
+
+// SetHTTPClient adds the HTTPClient to the rehydrate snapshot params
+func (o *RehydrateSnapshotParams) SetHTTPClient(client *http.Client) {
+	o.HTTPClient = client
+}
+
+// WithIdempotencyKey adds the idempotencyKey to the rehydrate snapshot params
+func (o *RehydrateSnapshotParams) WithIdempotencyKey(idempotencyKey *string) *RehydrateSnapshotParams {
+	o.SetIdempotencyKey(idempotencyKey)
+	return o
+}
+
+// SetIdempotencyKey adds the idempotencyKey to the rehydrate snapshot params
+func (o *RehydrateSnapshotParams) SetIdempotencyKey(idempotencyKey *string) {
+	o.IdempotencyKey = idempotencyKey
+}
+
+// WithProtectedEntityID adds the protectedEntityID to the rehydrate snapshot params
+func (o *RehydrateSnapshotParams) WithProtectedEntityID(protectedEntityID string) *RehydrateSnapshotParams {
+	o.SetProtectedEntityID(protectedEntityID)
+	return o
+}
+
+// SetProtectedEntityID adds the protectedEntityId to the rehydrate snapshot params
+func (o *RehydrateSnapshotParams) SetProtectedEntityID(protectedEntityID string) {
+	o.ProtectedEntityID = protectedEntityID
+}
+
+// WithService adds the service to the rehydrate snapshot params
+func (o *RehydrateSnapshotParams) WithService(service string) *RehydrateSnapshotParams {
+	o.SetService(service)
+	return o
+}
+
+// SetService adds the service to the rehydrate snapshot params
+func (o *RehydrateSnapshotParams) SetService(service string) {
+	o.Service = service
+}
+
+// WriteToRequest writes these params to a swagger request
+func (o *RehydrateSnapshotParams) WriteToRequest(r runtime.ClientRequest, reg strfmt.Registry) error {
+
+	if err := r.SetTimeout(o.timeout); err != nil {
+		return err
+	}
+	var res []error
+
+	if o.IdempotencyKey != nil {
+
+		// header param Idempotency-Key
+		if err := r.SetHeaderParam("Idempotency-Key", *o.IdempotencyKey); err != nil {
+			return err
+		}
+
+	}
+
+	// path param protectedEntityID
+	if err := r.SetPathParam("protectedEntityID", o.ProtectedEntityID); err != nil {
+		return err
+	}
+
+	// path param service
+	if err := r.SetPathParam("service", o.Service); err != nil {
+		return err
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// RehydrateSnapshotReader is a Reader for the RehydrateSnapshot structure.
+type RehydrateSnapshotReader struct {
+	formats strfmt.Registry
+}
+
+// ReadResponse reads a server response into the received o.
+func (o *RehydrateSnapshotReader) ReadResponse(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	switch response.Code() {
+	case 202:
+		result := NewRehydrateSnapshotAccepted()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case 400:
+		result := NewRehydrateSnapshotBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 404:
+		result := NewRehydrateSnapshotNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 500:
+		result := NewRehydrateSnapshotInternalServerError()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	default:
+		return nil, runtime.NewAPIError("unknown error", response, response.Code())
+	}
+}
+
+// NewRehydrateSnapshotAccepted creates a RehydrateSnapshotAccepted with default headers values
+func NewRehydrateSnapshotAccepted() *RehydrateSnapshotAccepted {
+	return &RehydrateSnapshotAccepted{}
+}
+
+/*RehydrateSnapshotAccepted handles this case with default header values.
+
+Rehydration started, the task reports its progress
+*/
+type RehydrateSnapshotAccepted struct {
+	Payload *models.CreateInProgressResponse
+}
+
+func (o *RehydrateSnapshotAccepted) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}/{protectedEntityID}/rehydrate][%d] rehydrateSnapshotAccepted  %+v", 202, o.Payload)
+}
+
+func (o *RehydrateSnapshotAccepted) GetPayload() *models.CreateInProgressResponse {
+	return o.Payload
+}
+
+func (o *RehydrateSnapshotAccepted) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.CreateInProgressResponse)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewRehydrateSnapshotBadRequest creates a RehydrateSnapshotBadRequest with default headers values
+func NewRehydrateSnapshotBadRequest() *RehydrateSnapshotBadRequest {
+	return &RehydrateSnapshotBadRequest{}
+}
+
+/*RehydrateSnapshotBadRequest handles this case with default header values.
+
+Invalid protected entity ID
+*/
+type RehydrateSnapshotBadRequest struct {
+	Payload *models.Error
+}
+
+func (o *RehydrateSnapshotBadRequest) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}/{protectedEntityID}/rehydrate][%d] rehydrateSnapshotBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *RehydrateSnapshotBadRequest) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *RehydrateSnapshotBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewRehydrateSnapshotNotFound creates a RehydrateSnapshotNotFound with default headers values
+func NewRehydrateSnapshotNotFound() *RehydrateSnapshotNotFound {
+	return &RehydrateSnapshotNotFound{}
+}
+
+/*RehydrateSnapshotNotFound handles this case with default header values.
+
+Service not found, the service does not support tiering or the snapshot does not exist
+*/
+type RehydrateSnapshotNotFound struct {
+	Payload *models.Error
+}
+
+func (o *RehydrateSnapshotNotFound) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}/{protectedEntityID}/rehydrate][%d] rehydrateSnapshotNotFound  %+v", 404, o.Payload)
+}
+
+func (o *RehydrateSnapshotNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *RehydrateSnapshotNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewRehydrateSnapshotInternalServerError creates a RehydrateSnapshotInternalServerError with default headers values
+func NewRehydrateSnapshotInternalServerError() *RehydrateSnapshotInternalServerError {
+	return &RehydrateSnapshotInternalServerError{}
+}
+
+/*RehydrateSnapshotInternalServerError handles this case with default header values.
+
+Rehydration could not be started
+*/
+type RehydrateSnapshotInternalServerError struct {
+	Payload *models.Error
+}
+
+func (o *RehydrateSnapshotInternalServerError) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}/{protectedEntityID}/rehydrate][%d] rehydrateSnapshotInternalServerError  %+v", 500, o.Payload)
+}
+
+func (o *RehydrateSnapshotInternalServerError) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *RehydrateSnapshotInternalServerError) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
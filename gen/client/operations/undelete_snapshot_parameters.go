@@ -0,0 +1,186 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	cr "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+)
+
+// NewUndeleteSnapshotParams creates a new UndeleteSnapshotParams object
+// with the default values initialized.
+func NewUndeleteSnapshotParams() *UndeleteSnapshotParams {
+	var ()
+	return &UndeleteSnapshotParams{
+
+		timeout: cr.DefaultTimeout,
+	}
+}
+
+// NewUndeleteSnapshotParamsWithTimeout creates a new UndeleteSnapshotParams object
+// with the default values initialized, and the ability to set a timeout on a request
+func NewUndeleteSnapshotParamsWithTimeout(timeout time.Duration) *UndeleteSnapshotParams {
+	var ()
+	return &UndeleteSnapshotParams{
+
+		timeout: timeout,
+	}
+}
+
+// NewUndeleteSnapshotParamsWithContext creates a new UndeleteSnapshotParams object
+// with the default values initialized, and the ability to set a context for a request
+func NewUndeleteSnapshotParamsWithContext(ctx context.Context) *UndeleteSnapshotParams {
+	var ()
+	return &UndeleteSnapshotParams{
+
+		Context: ctx,
+	}
+}
+
+// NewUndeleteSnapshotParamsWithHTTPClient creates a new UndeleteSnapshotParams object
+// with the default values initialized, and the ability to set a custom HTTPClient for a request
+func NewUndeleteSnapshotParamsWithHTTPClient(client *http.Client) *UndeleteSnapshotParams {
+	var ()
+	return &UndeleteSnapshotParams{
+		HTTPClient: client,
+	}
+}
+
+/*UndeleteSnapshotParams contains all the parameters to send to the API endpoint
+for the undelete snapshot operation typically these are written to a http.Request
+*/
+type UndeleteSnapshotParams struct {
+
+	/*IdempotencyKey
+	  A key chosen by the client that identifies this request.  If a
+	request with the same key has already completed successfully within
+	the server's idempotency window, its result is returned instead of
+	running the operation again.  Keys are scoped to the caller and
+	cannot be reused for a different request.
+
+
+	*/
+	IdempotencyKey *string
+	/*ProtectedEntityID
+	  The protected entity snapshot ID to restore
+
+	*/
+	ProtectedEntityID string
+	/*Service
+	  The service for the protected entity
+
+	*/
+	Service string
+
+	timeout    time.Duration
+	Context    context.Context
+	HTTPClient *http.Client
+}
+
+// WithTimeout adds the timeout to the undelete snapshot params
+func (o *UndeleteSnapshotParams) WithTimeout(timeout time.Duration) *UndeleteSnapshotParams {
+	o.SetTimeout(timeout)
+	return o
+}
+
+// SetTimeout adds the timeout to the undelete snapshot params
+func (o *UndeleteSnapshotParams) SetTimeout(timeout time.Duration) {
+	o.timeout = timeout
+}
+
+// WithContext adds the context to the undelete snapshot params
+func (o *UndeleteSnapshotParams) WithContext(ctx context.Context) *UndeleteSnapshotParams {
+	o.SetContext(ctx)
+	return o
+}
+
+// SetContext adds the context to the undelete snapshot params
+func (o *UndeleteSnapshotParams) SetContext(ctx context.Context) {
+	o.Context = ctx
+}
+
+// WithHTTPClient adds the HTTPClient to the undelete snapshot params
+func (o *UndeleteSnapshotParams) WithHTTPClient(client *http.Client) *UndeleteSnapshotParams {
+	o.SetHTTPClient(client)
+	return o
+}
+
+// SetHTTPClient adds the HTTPClient to the undelete snapshot params
+func (o *UndeleteSnapshotParams) SetHTTPClient(client *http.Client) {
+	o.HTTPClient = client
+}
+
+// WithIdempotencyKey adds the idempotencyKey to the undelete snapshot params
+func (o *UndeleteSnapshotParams) WithIdempotencyKey(idempotencyKey *string) *UndeleteSnapshotParams {
+	o.SetIdempotencyKey(idempotencyKey)
+	return o
+}
+
+// SetIdempotencyKey adds the idempotencyKey to the undelete snapshot params
+func (o *UndeleteSnapshotParams) SetIdempotencyKey(idempotencyKey *string) {
+	o.IdempotencyKey = idempotencyKey
+}
+
+// WithProtectedEntityID adds the protectedEntityID to the undelete snapshot params
+func (o *UndeleteSnapshotParams) WithProtectedEntityID(protectedEntityID string) *UndeleteSnapshotParams {
+	o.SetProtectedEntityID(protectedEntityID)
+	return o
+}
+
+// SetProtectedEntityID adds the protectedEntityId to the undelete snapshot params
+func (o *UndeleteSnapshotParams) SetProtectedEntityID(protectedEntityID string) {
+	o.ProtectedEntityID = protectedEntityID
+}
+
+// WithService adds the service to the undelete snapshot params
+func (o *UndeleteSnapshotParams) WithService(service string) *UndeleteSnapshotParams {
+	o.SetService(service)
+	return o
+}
+
+// SetService adds the service to the undelete snapshot params
+func (o *UndeleteSnapshotParams) SetService(service string) {
+	o.Service = service
+}
+
+// WriteToRequest writes these params to a swagger request
+func (o *UndeleteSnapshotParams) WriteToRequest(r runtime.ClientRequest, reg strfmt.Registry) error {
+
+	if err := r.SetTimeout(o.timeout); err != nil {
+		return err
+	}
+	var res []error
+
+	if o.IdempotencyKey != nil {
+
+		// header param Idempotency-Key
+		if err := r.SetHeaderParam("Idempotency-Key", *o.IdempotencyKey); err != nil {
+			return err
+		}
+
+	}
+
+	// path param protectedEntityID
+	if err := r.SetPathParam("protectedEntityID", o.ProtectedEntityID); err != nil {
+		return err
+	}
+
+	// path param service
+	if err := r.SetPathParam("service", o.Service); err != nil {
+		return err
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
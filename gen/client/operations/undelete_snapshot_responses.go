@@ -0,0 +1,184 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// UndeleteSnapshotReader is a Reader for the UndeleteSnapshot structure.
+type UndeleteSnapshotReader struct {
+	formats strfmt.Registry
+}
+
+// ReadResponse reads a server response into the received o.
+func (o *UndeleteSnapshotReader) ReadResponse(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	switch response.Code() {
+	case 200:
+		result := NewUndeleteSnapshotOK()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case 400:
+		result := NewUndeleteSnapshotBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 404:
+		result := NewUndeleteSnapshotNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 500:
+		result := NewUndeleteSnapshotInternalServerError()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	default:
+		return nil, runtime.NewAPIError("unknown error", response, response.Code())
+	}
+}
+
+// NewUndeleteSnapshotOK creates a UndeleteSnapshotOK with default headers values
+func NewUndeleteSnapshotOK() *UndeleteSnapshotOK {
+	return &UndeleteSnapshotOK{}
+}
+
+/*UndeleteSnapshotOK handles this case with default header values.
+
+Snapshot restored, returns the snapshot protected entity ID
+*/
+type UndeleteSnapshotOK struct {
+	Payload models.ProtectedEntityID
+}
+
+func (o *UndeleteSnapshotOK) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}/{protectedEntityID}/undelete][%d] undeleteSnapshotOK  %+v", 200, o.Payload)
+}
+
+func (o *UndeleteSnapshotOK) GetPayload() models.ProtectedEntityID {
+	return o.Payload
+}
+
+func (o *UndeleteSnapshotOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	// response payload
+	if err := consumer.Consume(response.Body(), &o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewUndeleteSnapshotBadRequest creates a UndeleteSnapshotBadRequest with default headers values
+func NewUndeleteSnapshotBadRequest() *UndeleteSnapshotBadRequest {
+	return &UndeleteSnapshotBadRequest{}
+}
+
+/*UndeleteSnapshotBadRequest handles this case with default header values.
+
+Invalid protected entity ID
+*/
+type UndeleteSnapshotBadRequest struct {
+	Payload *models.Error
+}
+
+func (o *UndeleteSnapshotBadRequest) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}/{protectedEntityID}/undelete][%d] undeleteSnapshotBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *UndeleteSnapshotBadRequest) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *UndeleteSnapshotBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewUndeleteSnapshotNotFound creates a UndeleteSnapshotNotFound with default headers values
+func NewUndeleteSnapshotNotFound() *UndeleteSnapshotNotFound {
+	return &UndeleteSnapshotNotFound{}
+}
+
+/*UndeleteSnapshotNotFound handles this case with default header values.
+
+Service not found, the service does not support soft delete or the snapshot is not deleted
+*/
+type UndeleteSnapshotNotFound struct {
+	Payload *models.Error
+}
+
+func (o *UndeleteSnapshotNotFound) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}/{protectedEntityID}/undelete][%d] undeleteSnapshotNotFound  %+v", 404, o.Payload)
+}
+
+func (o *UndeleteSnapshotNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *UndeleteSnapshotNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewUndeleteSnapshotInternalServerError creates a UndeleteSnapshotInternalServerError with default headers values
+func NewUndeleteSnapshotInternalServerError() *UndeleteSnapshotInternalServerError {
+	return &UndeleteSnapshotInternalServerError{}
+}
+
+/*UndeleteSnapshotInternalServerError handles this case with default header values.
+
+Undelete failed
+*/
+type UndeleteSnapshotInternalServerError struct {
+	Payload *models.Error
+}
+
+func (o *UndeleteSnapshotInternalServerError) Error() string {
+	return fmt.Sprintf("[POST /astrolabe/{service}/{protectedEntityID}/undelete][%d] undeleteSnapshotInternalServerError  %+v", 500, o.Payload)
+}
+
+func (o *UndeleteSnapshotInternalServerError) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *UndeleteSnapshotInternalServerError) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
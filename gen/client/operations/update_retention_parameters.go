@@ -0,0 +1,210 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	cr "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// NewUpdateRetentionParams creates a new UpdateRetentionParams object
+// with the default values initialized.
+func NewUpdateRetentionParams() *UpdateRetentionParams {
+	var ()
+	return &UpdateRetentionParams{
+
+		timeout: cr.DefaultTimeout,
+	}
+}
+
+// NewUpdateRetentionParamsWithTimeout creates a new UpdateRetentionParams object
+// with the default values initialized, and the ability to set a timeout on a request
+func NewUpdateRetentionParamsWithTimeout(timeout time.Duration) *UpdateRetentionParams {
+	var ()
+	return &UpdateRetentionParams{
+
+		timeout: timeout,
+	}
+}
+
+// NewUpdateRetentionParamsWithContext creates a new UpdateRetentionParams object
+// with the default values initialized, and the ability to set a context for a request
+func NewUpdateRetentionParamsWithContext(ctx context.Context) *UpdateRetentionParams {
+	var ()
+	return &UpdateRetentionParams{
+
+		Context: ctx,
+	}
+}
+
+// NewUpdateRetentionParamsWithHTTPClient creates a new UpdateRetentionParams object
+// with the default values initialized, and the ability to set a custom HTTPClient for a request
+func NewUpdateRetentionParamsWithHTTPClient(client *http.Client) *UpdateRetentionParams {
+	var ()
+	return &UpdateRetentionParams{
+		HTTPClient: client,
+	}
+}
+
+/*UpdateRetentionParams contains all the parameters to send to the API endpoint
+for the update retention operation typically these are written to a http.Request
+*/
+type UpdateRetentionParams struct {
+
+	/*IdempotencyKey
+	  A key chosen by the client that identifies this request.  If a
+	request with the same key has already completed successfully within
+	the server's idempotency window, its result is returned instead of
+	running the operation again.  Keys are scoped to the caller and
+	cannot be reused for a different request.
+
+
+	*/
+	IdempotencyKey *string
+	/*ProtectedEntityID
+	  The snapshot protected entity ID to update retention for
+
+	*/
+	ProtectedEntityID string
+	/*Service
+	  The service for the protected entity
+
+	*/
+	Service string
+	/*Update
+	  The retention changes
+
+	*/
+	Update *models.RetentionUpdate
+
+	timeout    time.Duration
+	Context    context.Context
+	HTTPClient *http.Client
+}
+
+// WithTimeout adds the timeout to the update retention params
+func (o *UpdateRetentionParams) WithTimeout(timeout time.Duration) *UpdateRetentionParams {
+	o.SetTimeout(timeout)
+	return o
+}
+
+// SetTimeout adds the timeout to the update retention params
+func (o *UpdateRetentionParams) SetTimeout(timeout time.Duration) {
+	o.timeout = timeout
+}
+
+// WithContext adds the context to the update retention params
+func (o *UpdateRetentionParams) WithContext(ctx context.Context) *UpdateRetentionParams {
+	o.SetContext(ctx)
+	return o
+}
+
+// SetContext adds the context to the update retention params
+func (o *UpdateRetentionParams) SetContext(ctx context.Context) {
+	o.Context = ctx
+}
+
+// WithHTTPClient adds the HTTPClient to the update retention params
+func (o *UpdateRetentionParams) WithHTTPClient(client *http.Client) *UpdateRetentionParams {
+	o.SetHTTPClient(client)
+	return o
+}
+
+// SetHTTPClient adds the HTTPClient to the update retention params
+func (o *UpdateRetentionParams) SetHTTPClient(client *http.Client) {
+	o.HTTPClient = client
+}
+
+// WithIdempotencyKey adds the idempotencyKey to the update retention params
+func (o *UpdateRetentionParams) WithIdempotencyKey(idempotencyKey *string) *UpdateRetentionParams {
+	o.SetIdempotencyKey(idempotencyKey)
+	return o
+}
+
+// SetIdempotencyKey adds the idempotencyKey to the update retention params
+func (o *UpdateRetentionParams) SetIdempotencyKey(idempotencyKey *string) {
+	o.IdempotencyKey = idempotencyKey
+}
+
+// WithProtectedEntityID adds the protectedEntityID to the update retention params
+func (o *UpdateRetentionParams) WithProtectedEntityID(protectedEntityID string) *UpdateRetentionParams {
+	o.SetProtectedEntityID(protectedEntityID)
+	return o
+}
+
+// SetProtectedEntityID adds the protectedEntityId to the update retention params
+func (o *UpdateRetentionParams) SetProtectedEntityID(protectedEntityID string) {
+	o.ProtectedEntityID = protectedEntityID
+}
+
+// WithService adds the service to the update retention params
+func (o *UpdateRetentionParams) WithService(service string) *UpdateRetentionParams {
+	o.SetService(service)
+	return o
+}
+
+// SetService adds the service to the update retention params
+func (o *UpdateRetentionParams) SetService(service string) {
+	o.Service = service
+}
+
+// WithUpdate adds the update to the update retention params
+func (o *UpdateRetentionParams) WithUpdate(update *models.RetentionUpdate) *UpdateRetentionParams {
+	o.SetUpdate(update)
+	return o
+}
+
+// SetUpdate adds the update to the update retention params
+func (o *UpdateRetentionParams) SetUpdate(update *models.RetentionUpdate) {
+	o.Update = update
+}
+
+// WriteToRequest writes these params to a swagger request
+func (o *UpdateRetentionParams) WriteToRequest(r runtime.ClientRequest, reg strfmt.Registry) error {
+
+	if err := r.SetTimeout(o.timeout); err != nil {
+		return err
+	}
+	var res []error
+
+	if o.IdempotencyKey != nil {
+
+		// header param Idempotency-Key
+		if err := r.SetHeaderParam("Idempotency-Key", *o.IdempotencyKey); err != nil {
+			return err
+		}
+
+	}
+
+	// path param protectedEntityID
+	if err := r.SetPathParam("protectedEntityID", o.ProtectedEntityID); err != nil {
+		return err
+	}
+
+	// path param service
+	if err := r.SetPathParam("service", o.Service); err != nil {
+		return err
+	}
+
+	if o.Update != nil {
+		if err := r.SetBodyParam(o.Update); err != nil {
+			return err
+		}
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
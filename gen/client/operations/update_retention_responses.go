@@ -0,0 +1,186 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// UpdateRetentionReader is a Reader for the UpdateRetention structure.
+type UpdateRetentionReader struct {
+	formats strfmt.Registry
+}
+
+// ReadResponse reads a server response into the received o.
+func (o *UpdateRetentionReader) ReadResponse(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	switch response.Code() {
+	case 200:
+		result := NewUpdateRetentionOK()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case 400:
+		result := NewUpdateRetentionBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 404:
+		result := NewUpdateRetentionNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 500:
+		result := NewUpdateRetentionInternalServerError()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	default:
+		return nil, runtime.NewAPIError("unknown error", response, response.Code())
+	}
+}
+
+// NewUpdateRetentionOK creates a UpdateRetentionOK with default headers values
+func NewUpdateRetentionOK() *UpdateRetentionOK {
+	return &UpdateRetentionOK{}
+}
+
+/*UpdateRetentionOK handles this case with default header values.
+
+Retention updated, returns the new retention
+*/
+type UpdateRetentionOK struct {
+	Payload *models.Retention
+}
+
+func (o *UpdateRetentionOK) Error() string {
+	return fmt.Sprintf("[PUT /astrolabe/{service}/{protectedEntityID}/retention][%d] updateRetentionOK  %+v", 200, o.Payload)
+}
+
+func (o *UpdateRetentionOK) GetPayload() *models.Retention {
+	return o.Payload
+}
+
+func (o *UpdateRetentionOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Retention)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewUpdateRetentionBadRequest creates a UpdateRetentionBadRequest with default headers values
+func NewUpdateRetentionBadRequest() *UpdateRetentionBadRequest {
+	return &UpdateRetentionBadRequest{}
+}
+
+/*UpdateRetentionBadRequest handles this case with default header values.
+
+Invalid protected entity ID or the retention cannot be shortened
+*/
+type UpdateRetentionBadRequest struct {
+	Payload *models.Error
+}
+
+func (o *UpdateRetentionBadRequest) Error() string {
+	return fmt.Sprintf("[PUT /astrolabe/{service}/{protectedEntityID}/retention][%d] updateRetentionBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *UpdateRetentionBadRequest) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *UpdateRetentionBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewUpdateRetentionNotFound creates a UpdateRetentionNotFound with default headers values
+func NewUpdateRetentionNotFound() *UpdateRetentionNotFound {
+	return &UpdateRetentionNotFound{}
+}
+
+/*UpdateRetentionNotFound handles this case with default header values.
+
+Service or snapshot not found, or the service does not support retention
+*/
+type UpdateRetentionNotFound struct {
+	Payload *models.Error
+}
+
+func (o *UpdateRetentionNotFound) Error() string {
+	return fmt.Sprintf("[PUT /astrolabe/{service}/{protectedEntityID}/retention][%d] updateRetentionNotFound  %+v", 404, o.Payload)
+}
+
+func (o *UpdateRetentionNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *UpdateRetentionNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewUpdateRetentionInternalServerError creates a UpdateRetentionInternalServerError with default headers values
+func NewUpdateRetentionInternalServerError() *UpdateRetentionInternalServerError {
+	return &UpdateRetentionInternalServerError{}
+}
+
+/*UpdateRetentionInternalServerError handles this case with default header values.
+
+Failed to update retention
+*/
+type UpdateRetentionInternalServerError struct {
+	Payload *models.Error
+}
+
+func (o *UpdateRetentionInternalServerError) Error() string {
+	return fmt.Sprintf("[PUT /astrolabe/{service}/{protectedEntityID}/retention][%d] updateRetentionInternalServerError  %+v", 500, o.Payload)
+}
+
+func (o *UpdateRetentionInternalServerError) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *UpdateRetentionInternalServerError) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// WaitOnTaskNexusReader is a Reader for the WaitOnTaskNexus structure.
+type WaitOnTaskNexusReader struct {
+	formats strfmt.Registry
+}
+
+// ReadResponse reads a server response into the received o.
+func (o *WaitOnTaskNexusReader) ReadResponse(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	switch response.Code() {
+	case 200:
+		result := NewWaitOnTaskNexusOK()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case 400:
+		result := NewWaitOnTaskNexusBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+	case 404:
+		result := NewWaitOnTaskNexusNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	default:
+		return nil, runtime.NewAPIError("unknown error", response, response.Code())
+	}
+}
+
+// NewWaitOnTaskNexusOK creates a WaitOnTaskNexusOK with default headers values
+func NewWaitOnTaskNexusOK() *WaitOnTaskNexusOK {
+	return &WaitOnTaskNexusOK{}
+}
+
+/*WaitOnTaskNexusOK handles this case with default header values.
+
+200 response
+*/
+type WaitOnTaskNexusOK struct {
+	Payload *models.TaskNexusResponse
+}
+
+func (o *WaitOnTaskNexusOK) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/tasks/nexus/{taskNexusID}][%d] waitOnTaskNexusOK  %+v", 200, o.Payload)
+}
+
+func (o *WaitOnTaskNexusOK) GetPayload() *models.TaskNexusResponse {
+	return o.Payload
+}
+
+func (o *WaitOnTaskNexusOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.TaskNexusResponse)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewWaitOnTaskNexusBadRequest creates a WaitOnTaskNexusBadRequest with default headers values
+func NewWaitOnTaskNexusBadRequest() *WaitOnTaskNexusBadRequest {
+	return &WaitOnTaskNexusBadRequest{}
+}
+
+/*WaitOnTaskNexusBadRequest handles this case with default header values.
+
+Invalid wait time or last finished time
+*/
+type WaitOnTaskNexusBadRequest struct {
+	Payload *models.Error
+}
+
+func (o *WaitOnTaskNexusBadRequest) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/tasks/nexus/{taskNexusID}][%d] waitOnTaskNexusBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *WaitOnTaskNexusBadRequest) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *WaitOnTaskNexusBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewWaitOnTaskNexusNotFound creates a WaitOnTaskNexusNotFound with default headers values
+func NewWaitOnTaskNexusNotFound() *WaitOnTaskNexusNotFound {
+	return &WaitOnTaskNexusNotFound{}
+}
+
+/*WaitOnTaskNexusNotFound handles this case with default header values.
+
+Task nexus not found
+*/
+type WaitOnTaskNexusNotFound struct {
+	Payload *models.Error
+}
+
+func (o *WaitOnTaskNexusNotFound) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/tasks/nexus/{taskNexusID}][%d] waitOnTaskNexusNotFound  %+v", 404, o.Payload)
+}
+
+func (o *WaitOnTaskNexusNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *WaitOnTaskNexusNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
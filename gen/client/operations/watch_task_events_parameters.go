@@ -0,0 +1,173 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	cr "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+)
+
+// NewWatchTaskEventsParams creates a new WatchTaskEventsParams object
+// with the default values initialized.
+func NewWatchTaskEventsParams() *WatchTaskEventsParams {
+	var ()
+	return &WatchTaskEventsParams{
+
+		timeout: cr.DefaultTimeout,
+	}
+}
+
+// NewWatchTaskEventsParamsWithTimeout creates a new WatchTaskEventsParams object
+// with the default values initialized, and the ability to set a timeout on a request
+func NewWatchTaskEventsParamsWithTimeout(timeout time.Duration) *WatchTaskEventsParams {
+	var ()
+	return &WatchTaskEventsParams{
+
+		timeout: timeout,
+	}
+}
+
+// NewWatchTaskEventsParamsWithContext creates a new WatchTaskEventsParams object
+// with the default values initialized, and the ability to set a context for a request
+func NewWatchTaskEventsParamsWithContext(ctx context.Context) *WatchTaskEventsParams {
+	var ()
+	return &WatchTaskEventsParams{
+
+		Context: ctx,
+	}
+}
+
+// NewWatchTaskEventsParamsWithHTTPClient creates a new WatchTaskEventsParams object
+// with the default values initialized, and the ability to set a custom HTTPClient for a request
+func NewWatchTaskEventsParamsWithHTTPClient(client *http.Client) *WatchTaskEventsParams {
+	var ()
+	return &WatchTaskEventsParams{
+		HTTPClient: client,
+	}
+}
+
+/*WatchTaskEventsParams contains all the parameters to send to the API endpoint
+for the watch task events operation typically these are written to a http.Request
+*/
+type WatchTaskEventsParams struct {
+
+	/*LastEventID
+	  The ID of the last event the client received.  The stream resumes with the events after it.  If those
+	events are no longer kept, the stream starts with the current state of the tasks.
+
+
+	*/
+	LastEventID *string
+	/*TaskID
+	  Only stream the events of this task
+
+	*/
+	TaskID *string
+
+	timeout    time.Duration
+	Context    context.Context
+	HTTPClient *http.Client
+}
+
+// WithTimeout adds the timeout to the watch task events params
+func (o *WatchTaskEventsParams) WithTimeout(timeout time.Duration) *WatchTaskEventsParams {
+	o.SetTimeout(timeout)
+	return o
+}
+
+// SetTimeout adds the timeout to the watch task events params
+func (o *WatchTaskEventsParams) SetTimeout(timeout time.Duration) {
+	o.timeout = timeout
+}
+
+// WithContext adds the context to the watch task events params
+func (o *WatchTaskEventsParams) WithContext(ctx context.Context) *WatchTaskEventsParams {
+	o.SetContext(ctx)
+	return o
+}
+
+// SetContext adds the context to the watch task events params
+func (o *WatchTaskEventsParams) SetContext(ctx context.Context) {
+	o.Context = ctx
+}
+
+// WithHTTPClient adds the HTTPClient to the watch task events params
+func (o *WatchTaskEventsParams) WithHTTPClient(client *http.Client) *WatchTaskEventsParams {
+	o.SetHTTPClient(client)
+	return o
+}
+
+// SetHTTPClient adds the HTTPClient to the watch task events params
+func (o *WatchTaskEventsParams) SetHTTPClient(client *http.Client) {
+	o.HTTPClient = client
+}
+
+// WithLastEventID adds the lastEventID to the watch task events params
+func (o *WatchTaskEventsParams) WithLastEventID(lastEventID *string) *WatchTaskEventsParams {
+	o.SetLastEventID(lastEventID)
+	return o
+}
+
+// SetLastEventID adds the lastEventId to the watch task events params
+func (o *WatchTaskEventsParams) SetLastEventID(lastEventID *string) {
+	o.LastEventID = lastEventID
+}
+
+// WithTaskID adds the taskID to the watch task events params
+func (o *WatchTaskEventsParams) WithTaskID(taskID *string) *WatchTaskEventsParams {
+	o.SetTaskID(taskID)
+	return o
+}
+
+// SetTaskID adds the taskId to the watch task events params
+func (o *WatchTaskEventsParams) SetTaskID(taskID *string) {
+	o.TaskID = taskID
+}
+
+// WriteToRequest writes these params to a swagger request
+func (o *WatchTaskEventsParams) WriteToRequest(r runtime.ClientRequest, reg strfmt.Registry) error {
+
+	if err := r.SetTimeout(o.timeout); err != nil {
+		return err
+	}
+	var res []error
+
+	if o.LastEventID != nil {
+
+		// header param Last-Event-ID
+		if err := r.SetHeaderParam("Last-Event-ID", *o.LastEventID); err != nil {
+			return err
+		}
+
+	}
+
+	if o.TaskID != nil {
+
+		// query param taskID
+		var qrTaskID string
+		if o.TaskID != nil {
+			qrTaskID = *o.TaskID
+		}
+		qTaskID := qrTaskID
+		if qTaskID != "" {
+			if err := r.SetQueryParam("taskID", qTaskID); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
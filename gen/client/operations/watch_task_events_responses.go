@@ -0,0 +1,112 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// WatchTaskEventsReader is a Reader for the WatchTaskEvents structure.
+type WatchTaskEventsReader struct {
+	formats strfmt.Registry
+	writer  io.Writer
+}
+
+// ReadResponse reads a server response into the received o.
+func (o *WatchTaskEventsReader) ReadResponse(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	switch response.Code() {
+	case 200:
+		result := NewWatchTaskEventsOK(o.writer)
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case 404:
+		result := NewWatchTaskEventsNotFound()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	default:
+		return nil, runtime.NewAPIError("unknown error", response, response.Code())
+	}
+}
+
+// NewWatchTaskEventsOK creates a WatchTaskEventsOK with default headers values
+func NewWatchTaskEventsOK(writer io.Writer) *WatchTaskEventsOK {
+	return &WatchTaskEventsOK{
+		Payload: writer,
+	}
+}
+
+/*WatchTaskEventsOK handles this case with default header values.
+
+A stream of Server-Sent Events.  Each event has an ID, a type, status when a task starts or its status
+changes and progress when its progress, details, queue position or locks change, and the task info as
+JSON data.
+
+*/
+type WatchTaskEventsOK struct {
+	Payload io.Writer
+}
+
+func (o *WatchTaskEventsOK) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/tasks/events][%d] watchTaskEventsOK  %+v", 200, o.Payload)
+}
+
+func (o *WatchTaskEventsOK) GetPayload() io.Writer {
+	return o.Payload
+}
+
+func (o *WatchTaskEventsOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewWatchTaskEventsNotFound creates a WatchTaskEventsNotFound with default headers values
+func NewWatchTaskEventsNotFound() *WatchTaskEventsNotFound {
+	return &WatchTaskEventsNotFound{}
+}
+
+/*WatchTaskEventsNotFound handles this case with default header values.
+
+Task not found
+*/
+type WatchTaskEventsNotFound struct {
+	Payload *models.Error
+}
+
+func (o *WatchTaskEventsNotFound) Error() string {
+	return fmt.Sprintf("[GET /astrolabe/tasks/events][%d] watchTaskEventsNotFound  %+v", 404, o.Payload)
+}
+
+func (o *WatchTaskEventsNotFound) GetPayload() *models.Error {
+	return o.Payload
+}
+
+func (o *WatchTaskEventsNotFound) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.Error)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
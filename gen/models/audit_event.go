@@ -0,0 +1,230 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"strconv"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// AuditEvent audit event
+//
+// swagger:model AuditEvent
+type AuditEvent struct {
+
+	// snapshot, copy, overwrite, delete, retention, undelete or rehydrate
+	// Required: true
+	Action *string `json:"action"`
+
+	// auth method
+	AuthMethod string `json:"authMethod,omitempty"`
+
+	// duration seconds
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+
+	// error
+	Error string `json:"error,omitempty"`
+
+	// SHA-256 of the previous hash and the event without its hash
+	// Required: true
+	Hash *string `json:"hash"`
+
+	// The authenticated caller, empty if the server does not authenticate
+	Identity string `json:"identity,omitempty"`
+
+	// The API call, an OpenAPI operation ID, s3Copy for the S3 copy bucket or task for the end of a task
+	// Required: true
+	Operation *string `json:"operation"`
+
+	// success, failure, denied or accepted for calls that start a task
+	// Required: true
+	Outcome *string `json:"outcome"`
+
+	// Parameters of the call, secrets are redacted
+	Params interface{} `json:"params,omitempty"`
+
+	// Hash of the previous event, the first event chains from an empty hash
+	PreviousHash string `json:"previousHash,omitempty"`
+
+	// protected entity i ds
+	ProtectedEntityIDs []ProtectedEntityID `json:"protectedEntityIDs"`
+
+	// Position of the event in the audit log, starting at 1
+	// Required: true
+	Sequence *int64 `json:"sequence"`
+
+	// service
+	Service string `json:"service,omitempty"`
+
+	// HTTP status of the response
+	Status int32 `json:"status,omitempty"`
+
+	// task ID
+	TaskID TaskID `json:"taskID,omitempty"`
+
+	// time
+	// Required: true
+	// Format: date-time
+	Time *strfmt.DateTime `json:"time"`
+}
+
+// Validate validates this audit event
+func (m *AuditEvent) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateAction(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateHash(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateOperation(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateOutcome(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateProtectedEntityIDs(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateSequence(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateTaskID(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateTime(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *AuditEvent) validateAction(formats strfmt.Registry) error {
+
+	if err := validate.Required("action", "body", m.Action); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AuditEvent) validateHash(formats strfmt.Registry) error {
+
+	if err := validate.Required("hash", "body", m.Hash); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AuditEvent) validateOperation(formats strfmt.Registry) error {
+
+	if err := validate.Required("operation", "body", m.Operation); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AuditEvent) validateOutcome(formats strfmt.Registry) error {
+
+	if err := validate.Required("outcome", "body", m.Outcome); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AuditEvent) validateProtectedEntityIDs(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ProtectedEntityIDs) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.ProtectedEntityIDs); i++ {
+
+		if err := m.ProtectedEntityIDs[i].Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("protectedEntityIDs" + "." + strconv.Itoa(i))
+			}
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+func (m *AuditEvent) validateSequence(formats strfmt.Registry) error {
+
+	if err := validate.Required("sequence", "body", m.Sequence); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *AuditEvent) validateTaskID(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.TaskID) { // not required
+		return nil
+	}
+
+	if err := m.TaskID.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("taskID")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m *AuditEvent) validateTime(formats strfmt.Registry) error {
+
+	if err := validate.Required("time", "body", m.Time); err != nil {
+		return err
+	}
+
+	if err := validate.FormatOf("time", "body", "date-time", m.Time.String(), formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *AuditEvent) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *AuditEvent) UnmarshalBinary(b []byte) error {
+	var res AuditEvent
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
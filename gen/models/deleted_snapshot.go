@@ -0,0 +1,110 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// DeletedSnapshot deleted snapshot
+//
+// swagger:model DeletedSnapshot
+type DeletedSnapshot struct {
+
+	// deleted at
+	// Required: true
+	// Format: date-time
+	DeletedAt *strfmt.DateTime `json:"deletedAt"`
+
+	// id
+	// Required: true
+	ID ProtectedEntityID `json:"id"`
+
+	// The snapshot may be purged, and can no longer be restored, after this time
+	// Format: date-time
+	PurgeAfter strfmt.DateTime `json:"purgeAfter,omitempty"`
+}
+
+// Validate validates this deleted snapshot
+func (m *DeletedSnapshot) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateDeletedAt(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateID(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validatePurgeAfter(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *DeletedSnapshot) validateDeletedAt(formats strfmt.Registry) error {
+
+	if err := validate.Required("deletedAt", "body", m.DeletedAt); err != nil {
+		return err
+	}
+
+	if err := validate.FormatOf("deletedAt", "body", "date-time", m.DeletedAt.String(), formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *DeletedSnapshot) validateID(formats strfmt.Registry) error {
+
+	if err := m.ID.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("id")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m *DeletedSnapshot) validatePurgeAfter(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.PurgeAfter) { // not required
+		return nil
+	}
+
+	if err := validate.FormatOf("purgeAfter", "body", "date-time", m.PurgeAfter.String(), formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *DeletedSnapshot) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *DeletedSnapshot) UnmarshalBinary(b []byte) error {
+	var res DeletedSnapshot
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
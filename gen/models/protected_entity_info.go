@@ -19,6 +19,9 @@ import (
 // swagger:model ProtectedEntityInfo
 type ProtectedEntityInfo struct {
 
+	// annotations
+	Annotations map[string]string `json:"annotations,omitempty"`
+
 	// combined transports
 	// Required: true
 	CombinedTransports []*DataTransport `json:"combinedTransports"`
@@ -35,6 +38,9 @@ type ProtectedEntityInfo struct {
 	// Required: true
 	ID ProtectedEntityID `json:"id"`
 
+	// labels
+	Labels map[string]string `json:"labels,omitempty"`
+
 	// metadata transports
 	// Required: true
 	MetadataTransports []*DataTransport `json:"metadataTransports"`
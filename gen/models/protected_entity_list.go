@@ -18,6 +18,9 @@ import (
 // swagger:model ProtectedEntityList
 type ProtectedEntityList struct {
 
+	// Set when truncated, pass it to retrieve the next page
+	ContinuationToken string `json:"continuationToken,omitempty"`
+
 	// list
 	List []ProtectedEntityID `json:"list"`
 
@@ -0,0 +1,127 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"strconv"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// ProtectedEntityUsage protected entity usage
+//
+// swagger:model ProtectedEntityUsage
+type ProtectedEntityUsage struct {
+
+	// id
+	// Required: true
+	ID ProtectedEntityID `json:"id"`
+
+	// snapshots
+	Snapshots []*SnapshotUsage `json:"snapshots"`
+
+	// usage
+	// Required: true
+	Usage *UsageStats `json:"usage"`
+}
+
+// Validate validates this protected entity usage
+func (m *ProtectedEntityUsage) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateID(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateSnapshots(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateUsage(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *ProtectedEntityUsage) validateID(formats strfmt.Registry) error {
+
+	if err := m.ID.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("id")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m *ProtectedEntityUsage) validateSnapshots(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Snapshots) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Snapshots); i++ {
+		if swag.IsZero(m.Snapshots[i]) { // not required
+			continue
+		}
+
+		if m.Snapshots[i] != nil {
+			if err := m.Snapshots[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("snapshots" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *ProtectedEntityUsage) validateUsage(formats strfmt.Registry) error {
+
+	if err := validate.Required("usage", "body", m.Usage); err != nil {
+		return err
+	}
+
+	if m.Usage != nil {
+		if err := m.Usage.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("usage")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *ProtectedEntityUsage) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *ProtectedEntityUsage) UnmarshalBinary(b []byte) error {
+	var res ProtectedEntityUsage
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
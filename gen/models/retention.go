@@ -0,0 +1,142 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"encoding/json"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// Retention retention
+//
+// swagger:model Retention
+type Retention struct {
+
+	// The snapshot cannot be deleted while a legal hold is in place
+	// Required: true
+	LegalHold *bool `json:"legalHold"`
+
+	// mode
+	// Required: true
+	// Enum: [none governance compliance]
+	Mode *string `json:"mode"`
+
+	// The snapshot cannot be deleted before this time.  Omitted if mode is none
+	// Format: date-time
+	RetainUntil strfmt.DateTime `json:"retainUntil,omitempty"`
+}
+
+// Validate validates this retention
+func (m *Retention) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateLegalHold(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateMode(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateRetainUntil(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *Retention) validateLegalHold(formats strfmt.Registry) error {
+
+	if err := validate.Required("legalHold", "body", m.LegalHold); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var retentionTypeModePropEnum []interface{}
+
+func init() {
+	var res []string
+	if err := json.Unmarshal([]byte(`["none","governance","compliance"]`), &res); err != nil {
+		panic(err)
+	}
+	for _, v := range res {
+		retentionTypeModePropEnum = append(retentionTypeModePropEnum, v)
+	}
+}
+
+const (
+
+	// RetentionModeNone captures enum value "none"
+	RetentionModeNone string = "none"
+
+	// RetentionModeGovernance captures enum value "governance"
+	RetentionModeGovernance string = "governance"
+
+	// RetentionModeCompliance captures enum value "compliance"
+	RetentionModeCompliance string = "compliance"
+)
+
+// prop value enum
+func (m *Retention) validateModeEnum(path, location string, value string) error {
+	if err := validate.Enum(path, location, value, retentionTypeModePropEnum); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *Retention) validateMode(formats strfmt.Registry) error {
+
+	if err := validate.Required("mode", "body", m.Mode); err != nil {
+		return err
+	}
+
+	// value enum
+	if err := m.validateModeEnum("mode", "body", *m.Mode); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Retention) validateRetainUntil(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.RetainUntil) { // not required
+		return nil
+	}
+
+	if err := validate.FormatOf("retainUntil", "body", "date-time", m.RetainUntil.String(), formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *Retention) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *Retention) UnmarshalBinary(b []byte) error {
+	var res Retention
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
@@ -0,0 +1,71 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// RetentionUpdate retention update
+//
+// swagger:model RetentionUpdate
+type RetentionUpdate struct {
+
+	// Set to place or release a legal hold, unchanged if omitted
+	LegalHold *bool `json:"legalHold,omitempty"`
+
+	// New retain until time, must be later than the current one
+	// Format: date-time
+	RetainUntil strfmt.DateTime `json:"retainUntil,omitempty"`
+}
+
+// Validate validates this retention update
+func (m *RetentionUpdate) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateRetainUntil(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *RetentionUpdate) validateRetainUntil(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.RetainUntil) { // not required
+		return nil
+	}
+
+	if err := validate.FormatOf("retainUntil", "body", "date-time", m.RetainUntil.String(), formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *RetentionUpdate) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *RetentionUpdate) UnmarshalBinary(b []byte) error {
+	var res RetentionUpdate
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
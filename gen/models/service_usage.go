@@ -0,0 +1,124 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"strconv"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// ServiceUsage service usage
+//
+// swagger:model ServiceUsage
+type ServiceUsage struct {
+
+	// protected entities
+	ProtectedEntities []*ProtectedEntityUsage `json:"protectedEntities"`
+
+	// service
+	// Required: true
+	Service *string `json:"service"`
+
+	// usage
+	// Required: true
+	Usage *UsageStats `json:"usage"`
+}
+
+// Validate validates this service usage
+func (m *ServiceUsage) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateProtectedEntities(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateService(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateUsage(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *ServiceUsage) validateProtectedEntities(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ProtectedEntities) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.ProtectedEntities); i++ {
+		if swag.IsZero(m.ProtectedEntities[i]) { // not required
+			continue
+		}
+
+		if m.ProtectedEntities[i] != nil {
+			if err := m.ProtectedEntities[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("protectedEntities" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *ServiceUsage) validateService(formats strfmt.Registry) error {
+
+	if err := validate.Required("service", "body", m.Service); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *ServiceUsage) validateUsage(formats strfmt.Registry) error {
+
+	if err := validate.Required("usage", "body", m.Usage); err != nil {
+		return err
+	}
+
+	if m.Usage != nil {
+		if err := m.Usage.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("usage")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *ServiceUsage) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *ServiceUsage) UnmarshalBinary(b []byte) error {
+	var res ServiceUsage
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
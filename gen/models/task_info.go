@@ -7,6 +7,7 @@ package models
 
 import (
 	"encoding/json"
+	"strconv"
 
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
@@ -36,12 +37,18 @@ type TaskInfo struct {
 	// Required: true
 	ID TaskID `json:"id"`
 
+	// The Protected Entity locks the task holds or is waiting for
+	Locks []*TaskLock `json:"locks,omitempty"`
+
 	// progress
 	// Required: true
 	// Maximum: 100
 	// Minimum: 0
 	Progress *float64 `json:"progress"`
 
+	// Position of the task in the admission queue of the services it is waiting for, 1 is next.  Not set once the task is running.
+	QueuePosition int64 `json:"queuePosition,omitempty"`
+
 	// result
 	Result interface{} `json:"result,omitempty"`
 
@@ -71,6 +78,10 @@ func (m *TaskInfo) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateLocks(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateProgress(formats); err != nil {
 		res = append(res, err)
 	}
@@ -114,6 +125,31 @@ func (m *TaskInfo) validateID(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *TaskInfo) validateLocks(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Locks) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Locks); i++ {
+		if swag.IsZero(m.Locks[i]) { // not required
+			continue
+		}
+
+		if m.Locks[i] != nil {
+			if err := m.Locks[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("locks" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *TaskInfo) validateProgress(formats strfmt.Registry) error {
 
 	if err := validate.Required("progress", "body", m.Progress); err != nil {
@@ -0,0 +1,173 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"encoding/json"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// TaskLock A lock on a Protected Entity.  Locks are taken on the Protected Entity without its snapshot ID, so that snapshots, snapshot deletes and overwrites of a Protected Entity do not run at the same time.
+//
+// swagger:model TaskLock
+type TaskLock struct {
+
+	// mode
+	// Required: true
+	// Enum: [read write]
+	Mode *string `json:"mode"`
+
+	// protected entity ID
+	// Required: true
+	ProtectedEntityID ProtectedEntityID `json:"protectedEntityID"`
+
+	// state
+	// Required: true
+	// Enum: [waiting held]
+	State *string `json:"state"`
+}
+
+// Validate validates this task lock
+func (m *TaskLock) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateMode(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateProtectedEntityID(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateState(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+var taskLockTypeModePropEnum []interface{}
+
+func init() {
+	var res []string
+	if err := json.Unmarshal([]byte(`["read","write"]`), &res); err != nil {
+		panic(err)
+	}
+	for _, v := range res {
+		taskLockTypeModePropEnum = append(taskLockTypeModePropEnum, v)
+	}
+}
+
+const (
+
+	// TaskLockModeRead captures enum value "read"
+	TaskLockModeRead string = "read"
+
+	// TaskLockModeWrite captures enum value "write"
+	TaskLockModeWrite string = "write"
+)
+
+// prop value enum
+func (m *TaskLock) validateModeEnum(path, location string, value string) error {
+	if err := validate.Enum(path, location, value, taskLockTypeModePropEnum); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *TaskLock) validateMode(formats strfmt.Registry) error {
+
+	if err := validate.Required("mode", "body", m.Mode); err != nil {
+		return err
+	}
+
+	// value enum
+	if err := m.validateModeEnum("mode", "body", *m.Mode); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *TaskLock) validateProtectedEntityID(formats strfmt.Registry) error {
+
+	if err := m.ProtectedEntityID.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("protectedEntityID")
+		}
+		return err
+	}
+
+	return nil
+}
+
+var taskLockTypeStatePropEnum []interface{}
+
+func init() {
+	var res []string
+	if err := json.Unmarshal([]byte(`["waiting","held"]`), &res); err != nil {
+		panic(err)
+	}
+	for _, v := range res {
+		taskLockTypeStatePropEnum = append(taskLockTypeStatePropEnum, v)
+	}
+}
+
+const (
+
+	// TaskLockStateWaiting captures enum value "waiting"
+	TaskLockStateWaiting string = "waiting"
+
+	// TaskLockStateHeld captures enum value "held"
+	TaskLockStateHeld string = "held"
+)
+
+// prop value enum
+func (m *TaskLock) validateStateEnum(path, location string, value string) error {
+	if err := validate.Enum(path, location, value, taskLockTypeStatePropEnum); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *TaskLock) validateState(formats strfmt.Registry) error {
+
+	if err := validate.Required("state", "body", m.State); err != nil {
+		return err
+	}
+
+	// value enum
+	if err := m.validateStateEnum("state", "body", *m.State); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *TaskLock) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *TaskLock) UnmarshalBinary(b []byte) error {
+	var res TaskLock
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
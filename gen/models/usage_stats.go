@@ -0,0 +1,101 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
+)
+
+// UsageStats usage stats
+//
+// swagger:model UsageStats
+type UsageStats struct {
+
+	// Bytes of data and metadata as seen by a reader of the protected entity
+	// Required: true
+	LogicalBytes *int64 `json:"logicalBytes"`
+
+	// logicalBytes / storedBytes, the dedup or compression ratio.  Omitted if nothing is stored
+	ReductionRatio float64 `json:"reductionRatio,omitempty"`
+
+	// segment count
+	// Required: true
+	SegmentCount *int64 `json:"segmentCount"`
+
+	// Bytes used in the repository, including protected entity info
+	// Required: true
+	StoredBytes *int64 `json:"storedBytes"`
+}
+
+// Validate validates this usage stats
+func (m *UsageStats) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateLogicalBytes(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateSegmentCount(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateStoredBytes(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *UsageStats) validateLogicalBytes(formats strfmt.Registry) error {
+
+	if err := validate.Required("logicalBytes", "body", m.LogicalBytes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *UsageStats) validateSegmentCount(formats strfmt.Registry) error {
+
+	if err := validate.Required("segmentCount", "body", m.SegmentCount); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *UsageStats) validateStoredBytes(formats strfmt.Registry) error {
+
+	if err := validate.Required("storedBytes", "body", m.StoredBytes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *UsageStats) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *UsageStats) UnmarshalBinary(b []byte) error {
+	var res UsageStats
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
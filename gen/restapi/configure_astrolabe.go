@@ -84,9 +84,16 @@ func configureAPI(api *operations.AstrolabeAPI) http.Handler {
 	return setupGlobalMiddleware(api.Serve(setupMiddlewares))
 }
 
+// TLSConfigurer, if set, is called with the TLS configuration before the HTTPS server starts.  astrolabe_server uses
+// it to request client certificates.
+var TLSConfigurer func(tlsConfig *tls.Config)
+
 // The TLS configuration before HTTPS server starts.
 func configureTLS(tlsConfig *tls.Config) {
 	// Make all necessary changes to the TLS configuration here.
+	if TLSConfigurer != nil {
+		TLSConfigurer(tlsConfig)
+	}
 }
 
 // As soon as server is initialized but not run yet, this function will be called.
@@ -14,6 +14,7 @@
 //
 //  Produces:
 //    - application/json
+//    - text/event-stream
 //
 // swagger:meta
 package restapi
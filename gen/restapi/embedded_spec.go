@@ -47,6 +47,64 @@ func init() {
         }
       }
     },
+    "/astrolabe/audit": {
+      "get": {
+        "description": "Returns the events recorded in the audit log for mutating calls (snapshot, copy, delete, retention,\nundelete and rehydrate) and for the tasks they started.\n",
+        "produces": [
+          "application/json"
+        ],
+        "summary": "Lists audit events",
+        "operationId": "listAuditEvents",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Only return events for this protected entity.  Events for snapshots of the protected entity are included\nwhen the ID does not have a snapshot ID\n",
+            "name": "protectedEntityID",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "Only return events at or after this time",
+            "name": "from",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "Only return events before this time",
+            "name": "to",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The matching audit events, oldest first",
+            "schema": {
+              "$ref": "#/definitions/AuditEventList"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID or time range",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "The audit log is not enabled",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "The audit log could not be read",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
     "/astrolabe/tasks": {
       "get": {
         "description": "Lists running and recent tasks",
@@ -64,6 +122,43 @@ func init() {
         }
       }
     },
+    "/astrolabe/tasks/events": {
+      "get": {
+        "description": "Streams the status and progress changes of one task or of all tasks as Server-Sent Events.  When the stream\nstarts, the current state of the tasks is sent, unless the client resumes with Last-Event-ID.\n",
+        "produces": [
+          "text/event-stream"
+        ],
+        "operationId": "watchTaskEvents",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Only stream the events of this task",
+            "name": "taskID",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "The ID of the last event the client received.  The stream resumes with the events after it.  If those\nevents are no longer kept, the stream starts with the current state of the tasks.\n",
+            "name": "Last-Event-ID",
+            "in": "header"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "A stream of Server-Sent Events.  Each event has an ID, a type, status when a task starts or its status\nchanges and progress when its progress, details, queue position or locks change, and the task info as\nJSON data.\n",
+            "schema": {
+              "type": "file"
+            }
+          },
+          "404": {
+            "description": "Task not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
     "/astrolabe/tasks/nexus": {
       "get": {
         "description": "Provides a list of current task nexus",
@@ -85,6 +180,7 @@ func init() {
         "produces": [
           "application/json"
         ],
+        "operationId": "createTaskNexus",
         "responses": {
           "200": {
             "description": "New task nexus",
@@ -97,9 +193,11 @@ func init() {
     },
     "/astrolabe/tasks/nexus/{taskNexusID}": {
       "get": {
+        "description": "Waits for tasks associated with the nexus to finish.  Tasks started after the nexus was created are\nassociated with it\n",
         "produces": [
           "application/json"
         ],
+        "operationId": "waitOnTaskNexus",
         "parameters": [
           {
             "type": "string",
@@ -129,6 +227,18 @@ func init() {
             "schema": {
               "$ref": "#/definitions/TaskNexusResponse"
             }
+          },
+          "400": {
+            "description": "Invalid wait time or last finished time",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Task nexus not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
         }
       }
@@ -155,13 +265,63 @@ func init() {
             "schema": {
               "$ref": "#/definitions/TaskInfo"
             }
+          },
+          "404": {
+            "description": "Task not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/astrolabe/usage": {
+      "get": {
+        "description": "Reports logical and stored bytes per protected entity, per snapshot and per service for the services\nthat store protected entities (repositories).  Services that do not store data are not included.\n",
+        "produces": [
+          "application/json"
+        ],
+        "summary": "Reports repository usage",
+        "operationId": "getUsage",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Only report usage for this service",
+            "name": "service",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only report usage for protected entities whose IDs start with this prefix",
+            "name": "idPrefix",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Usage for the services that store protected entities",
+            "schema": {
+              "$ref": "#/definitions/RepositoryUsage"
+            }
+          },
+          "404": {
+            "description": "Service not found or service does not report usage",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Usage could not be retrieved",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
         }
       }
     },
     "/astrolabe/{service}": {
       "get": {
-        "description": "List protected entities for the service.  Results will be returned in\ncanonical ID order (string sorted).  Fewer results may be returned than\nexpected, the ProtectedEntityList has a field specifying if the list has\nbeen truncated.\n",
+        "description": "List protected entities for the service.  Results will be returned in\ncanonical ID order (string sorted) unless orderBy or order are set.\nFewer results may be returned than expected, the ProtectedEntityList\nhas a field specifying if the list has been truncated and a\ncontinuationToken for the next page.\n",
         "produces": [
           "application/json"
         ],
@@ -183,9 +343,53 @@ func init() {
           },
           {
             "type": "string",
-            "description": "Results will be returned that come after this ID",
+            "description": "Results will be returned that come after this ID.  Deprecated, use\ncontinuationToken.  Cannot be combined with continuationToken or\nordering\n",
             "name": "idsAfter",
             "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Continue the list from a previous response, pass its\ncontinuationToken with the same orderBy and order\n",
+            "name": "continuationToken",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return protected entities whose ID, without the service,\nstarts with this prefix\n",
+            "name": "prefix",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return protected entities with this name",
+            "name": "name",
+            "in": "query"
+          },
+          {
+            "enum": [
+              "id",
+              "name"
+            ],
+            "type": "string",
+            "description": "The order of the results, id by default",
+            "name": "orderBy",
+            "in": "query"
+          },
+          {
+            "enum": [
+              "asc",
+              "desc"
+            ],
+            "type": "string",
+            "description": "Ascending or descending order, asc by default",
+            "name": "order",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return protected entities whose labels match this label\nselector, e.g. app=nginx,tier!=frontend\n",
+            "name": "labelSelector",
+            "in": "query"
           }
         ],
         "responses": {
@@ -195,8 +399,23 @@ func init() {
               "$ref": "#/definitions/ProtectedEntityList"
             }
           },
+          "400": {
+            "description": "Invalid label selector or list options",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
           "404": {
-            "description": "Service or Protected Entity not found"
+            "description": "Service not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "List failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
         }
       },
@@ -210,6 +429,12 @@ func init() {
         ],
         "operationId": "copyProtectedEntity",
         "parameters": [
+          {
+            "type": "string",
+            "description": "A key chosen by the client that identifies this request.  If a\nrequest with the same key has already completed successfully within\nthe server's idempotency window, its result is returned instead of\nrunning the operation again.  Keys are scoped to the caller and\ncannot be reused for a different request.\n",
+            "name": "Idempotency-Key",
+            "in": "header"
+          },
           {
             "type": "string",
             "description": "The service to copy the protected entity into",
@@ -245,6 +470,18 @@ func init() {
             "schema": {
               "$ref": "#/definitions/CreateInProgressResponse"
             }
+          },
+          "400": {
+            "description": "Invalid copy parameters or protected entity info",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
         }
       }
@@ -278,16 +515,40 @@ func init() {
             "schema": {
               "$ref": "#/definitions/ProtectedEntityInfo"
             }
+          },
+          "400": {
+            "description": "Invalid protected entity ID",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service or Protected Entity not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to retrieve the protected entity info",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
         }
       },
       "delete": {
-        "description": "Deletes a protected entity or snapshot of a protected entity (if the\nsnapshot ID is specified)\n",
+        "description": "Deletes a protected entity or snapshot of a protected entity (if the\nsnapshot ID is specified).  Services that support soft delete keep\ndeleted snapshots for an undelete window, see undeleteSnapshot\n",
         "produces": [
           "application/json"
         ],
         "operationId": "deleteProtectedEntity",
         "parameters": [
+          {
+            "type": "string",
+            "description": "A key chosen by the client that identifies this request.  If a\nrequest with the same key has already completed successfully within\nthe server's idempotency window, its result is returned instead of\nrunning the operation again.  Keys are scoped to the caller and\ncannot be reused for a different request.\n",
+            "name": "Idempotency-Key",
+            "in": "header"
+          },
           {
             "type": "string",
             "description": "The service for the protected entity",
@@ -309,17 +570,41 @@ func init() {
             "schema": {
               "$ref": "#/definitions/ProtectedEntityID"
             }
+          },
+          "400": {
+            "description": "Invalid protected entity ID or the ID does not have a snapshot ID",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service or Protected Entity not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "409": {
+            "description": "The snapshot is retained and cannot be deleted or another operation on the protected entity holds its lock",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Delete failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
         }
       }
     },
-    "/astrolabe/{service}/{protectedEntityID}/snapshots": {
+    "/astrolabe/{service}/{protectedEntityID}/deletedSnapshots": {
       "get": {
-        "description": "Gets the list of snapshots for this protected entity\n",
+        "description": "Lists the deleted snapshots of this protected entity that can still be\nrestored with undeleteSnapshot\n",
         "produces": [
           "application/json"
         ],
-        "operationId": "listSnapshots",
+        "operationId": "listDeletedSnapshots",
         "parameters": [
           {
             "type": "string",
@@ -330,7 +615,7 @@ func init() {
           },
           {
             "type": "string",
-            "description": "The protected entity ID to retrieve info for",
+            "description": "The protected entity ID to list deleted snapshots for.  If a\nsnapshot ID is included only that snapshot is returned\n",
             "name": "protectedEntityID",
             "in": "path",
             "required": true
@@ -340,21 +625,44 @@ func init() {
           "200": {
             "description": "List succeeded",
             "schema": {
-              "$ref": "#/definitions/ProtectedEntityList"
+              "$ref": "#/definitions/DeletedSnapshotList"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           },
           "404": {
-            "description": "Service or Protected Entity not found"
+            "description": "Service not found or the service does not support soft delete",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "List failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
         }
-      },
+      }
+    },
+    "/astrolabe/{service}/{protectedEntityID}/rehydrate": {
       "post": {
-        "description": "Creates a new snapshot for this protected entity\n",
+        "description": "Restores the data of a snapshot that has been moved to an archive storage tier so that it can be read.\nRestores can take hours, use getTaskInfo to follow the returned task\n",
         "produces": [
           "application/json"
         ],
-        "operationId": "createSnapshot",
+        "operationId": "rehydrateSnapshot",
         "parameters": [
+          {
+            "type": "string",
+            "description": "A key chosen by the client that identifies this request.  If a\nrequest with the same key has already completed successfully within\nthe server's idempotency window, its result is returned instead of\nrunning the operation again.  Keys are scoped to the caller and\ncannot be reused for a different request.\n",
+            "name": "Idempotency-Key",
+            "in": "header"
+          },
           {
             "type": "string",
             "description": "The service for the protected entity",
@@ -364,35 +672,476 @@ func init() {
           },
           {
             "type": "string",
-            "description": "The protected entity ID to snapshot",
+            "description": "The protected entity snapshot ID to rehydrate",
             "name": "protectedEntityID",
             "in": "path",
             "required": true
+          }
+        ],
+        "responses": {
+          "202": {
+            "description": "Rehydration started, the task reports its progress",
+            "schema": {
+              "$ref": "#/definitions/CreateInProgressResponse"
+            }
           },
-          {
-            "description": "Parameters for the snapshot.",
-            "name": "params",
-            "in": "body",
+          "400": {
+            "description": "Invalid protected entity ID",
             "schema": {
-              "$ref": "#/definitions/OperationParamList"
+              "$ref": "#/definitions/Error"
             }
+          },
+          "404": {
+            "description": "Service not found, the service does not support tiering or the snapshot does not exist",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Rehydration could not be started",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/astrolabe/{service}/{protectedEntityID}/retention": {
+      "get": {
+        "description": "Gets the WORM retention and legal hold for a snapshot stored in a repository\n",
+        "produces": [
+          "application/json"
+        ],
+        "operationId": "getRetention",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "The service for the protected entity",
+            "name": "service",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "string",
+            "description": "The snapshot protected entity ID to retrieve retention for",
+            "name": "protectedEntityID",
+            "in": "path",
+            "required": true
           }
         ],
         "responses": {
           "200": {
-            "description": "Snapshot created successfully, returns the new snapshot ID",
+            "description": "200 response",
             "schema": {
-              "$ref": "#/definitions/ProtectedEntitySnapshotID"
+              "$ref": "#/definitions/Retention"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service or snapshot not found, or the service does not support retention",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to retrieve retention",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           }
         }
-      }
-    }
-  },
-  "definitions": {
-    "ComponentSpec": {
-      "type": "object",
-      "required": [
+      },
+      "put": {
+        "description": "Extends the retention of a snapshot and/or places or releases a legal hold.\nRetention can only be extended, never shortened.\n",
+        "produces": [
+          "application/json"
+        ],
+        "operationId": "updateRetention",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "A key chosen by the client that identifies this request.  If a\nrequest with the same key has already completed successfully within\nthe server's idempotency window, its result is returned instead of\nrunning the operation again.  Keys are scoped to the caller and\ncannot be reused for a different request.\n",
+            "name": "Idempotency-Key",
+            "in": "header"
+          },
+          {
+            "type": "string",
+            "description": "The service for the protected entity",
+            "name": "service",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "string",
+            "description": "The snapshot protected entity ID to update retention for",
+            "name": "protectedEntityID",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "The retention changes",
+            "name": "update",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/RetentionUpdate"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Retention updated, returns the new retention",
+            "schema": {
+              "$ref": "#/definitions/Retention"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID or the retention cannot be shortened",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service or snapshot not found, or the service does not support retention",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to update retention",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/astrolabe/{service}/{protectedEntityID}/snapshots": {
+      "get": {
+        "description": "Gets the list of snapshots for this protected entity.  Results will be\nreturned in canonical ID order unless orderBy or order are set.\n",
+        "produces": [
+          "application/json"
+        ],
+        "operationId": "listSnapshots",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "The service for the protected entity",
+            "name": "service",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "string",
+            "description": "The protected entity ID to retrieve info for",
+            "name": "protectedEntityID",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "string",
+            "description": "Only return snapshots whose labels match this label selector,\ne.g. app=nginx,tier!=frontend\n",
+            "name": "labelSelector",
+            "in": "query"
+          },
+          {
+            "type": "integer",
+            "format": "int32",
+            "description": "The maximum number of results to return (fewer results may be returned)",
+            "name": "maxResults",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Continue the list from a previous response, pass its\ncontinuationToken with the same orderBy and order\n",
+            "name": "continuationToken",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return snapshots whose snapshot ID starts with this prefix\n",
+            "name": "prefix",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return snapshots with this name",
+            "name": "name",
+            "in": "query"
+          },
+          {
+            "enum": [
+              "id",
+              "name"
+            ],
+            "type": "string",
+            "description": "The order of the results, id by default",
+            "name": "orderBy",
+            "in": "query"
+          },
+          {
+            "enum": [
+              "asc",
+              "desc"
+            ],
+            "type": "string",
+            "description": "Ascending or descending order, asc by default",
+            "name": "order",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "List succeeded",
+            "schema": {
+              "$ref": "#/definitions/ProtectedEntityList"
+            }
+          },
+          "400": {
+            "description": "Invalid label selector or list options",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service or Protected Entity not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "List failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      },
+      "post": {
+        "description": "Creates a new snapshot for this protected entity\n",
+        "produces": [
+          "application/json"
+        ],
+        "operationId": "createSnapshot",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "A key chosen by the client that identifies this request.  If a\nrequest with the same key has already completed successfully within\nthe server's idempotency window, its result is returned instead of\nrunning the operation again.  Keys are scoped to the caller and\ncannot be reused for a different request.\n",
+            "name": "Idempotency-Key",
+            "in": "header"
+          },
+          {
+            "type": "string",
+            "description": "The service for the protected entity",
+            "name": "service",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "string",
+            "description": "The protected entity ID to snapshot",
+            "name": "protectedEntityID",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "Parameters for the snapshot.",
+            "name": "params",
+            "in": "body",
+            "schema": {
+              "$ref": "#/definitions/OperationParamList"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Snapshot created successfully, returns the new snapshot ID",
+            "schema": {
+              "$ref": "#/definitions/ProtectedEntitySnapshotID"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service or Protected Entity not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "409": {
+            "description": "Another operation on the protected entity holds its lock",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Snapshot failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/astrolabe/{service}/{protectedEntityID}/undelete": {
+      "post": {
+        "description": "Restores a deleted snapshot before it is purged\n",
+        "produces": [
+          "application/json"
+        ],
+        "operationId": "undeleteSnapshot",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "A key chosen by the client that identifies this request.  If a\nrequest with the same key has already completed successfully within\nthe server's idempotency window, its result is returned instead of\nrunning the operation again.  Keys are scoped to the caller and\ncannot be reused for a different request.\n",
+            "name": "Idempotency-Key",
+            "in": "header"
+          },
+          {
+            "type": "string",
+            "description": "The service for the protected entity",
+            "name": "service",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "string",
+            "description": "The protected entity snapshot ID to restore",
+            "name": "protectedEntityID",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Snapshot restored, returns the snapshot protected entity ID",
+            "schema": {
+              "$ref": "#/definitions/ProtectedEntityID"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service not found, the service does not support soft delete or the snapshot is not deleted",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Undelete failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    }
+  },
+  "definitions": {
+    "AuditEvent": {
+      "type": "object",
+      "required": [
+        "sequence",
+        "time",
+        "action",
+        "operation",
+        "outcome",
+        "hash"
+      ],
+      "properties": {
+        "action": {
+          "description": "snapshot, copy, overwrite, delete, retention, undelete or rehydrate",
+          "type": "string"
+        },
+        "authMethod": {
+          "type": "string"
+        },
+        "durationSeconds": {
+          "type": "number",
+          "format": "double"
+        },
+        "error": {
+          "type": "string"
+        },
+        "hash": {
+          "description": "SHA-256 of the previous hash and the event without its hash",
+          "type": "string"
+        },
+        "identity": {
+          "description": "The authenticated caller, empty if the server does not authenticate",
+          "type": "string"
+        },
+        "operation": {
+          "description": "The API call, an OpenAPI operation ID, s3Copy for the S3 copy bucket or task for the end of a task",
+          "type": "string"
+        },
+        "outcome": {
+          "description": "success, failure, denied or accepted for calls that start a task",
+          "type": "string"
+        },
+        "params": {
+          "description": "Parameters of the call, secrets are redacted",
+          "type": "object"
+        },
+        "previousHash": {
+          "description": "Hash of the previous event, the first event chains from an empty hash",
+          "type": "string"
+        },
+        "protectedEntityIDs": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ProtectedEntityID"
+          }
+        },
+        "sequence": {
+          "description": "Position of the event in the audit log, starting at 1",
+          "type": "integer",
+          "format": "int64"
+        },
+        "service": {
+          "type": "string"
+        },
+        "status": {
+          "description": "HTTP status of the response",
+          "type": "integer",
+          "format": "int32"
+        },
+        "taskID": {
+          "$ref": "#/definitions/TaskID"
+        },
+        "time": {
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "AuditEventList": {
+      "type": "object",
+      "properties": {
+        "list": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/AuditEvent"
+          }
+        }
+      }
+    },
+    "ComponentSpec": {
+      "type": "object",
+      "required": [
         "id"
       ],
       "properties": {
@@ -437,6 +1186,56 @@ func init() {
         }
       }
     },
+    "DeletedSnapshot": {
+      "type": "object",
+      "required": [
+        "id",
+        "deletedAt"
+      ],
+      "properties": {
+        "deletedAt": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "id": {
+          "$ref": "#/definitions/ProtectedEntityID"
+        },
+        "purgeAfter": {
+          "description": "The snapshot may be purged, and can no longer be restored, after this time",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "DeletedSnapshotList": {
+      "type": "object",
+      "properties": {
+        "list": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/DeletedSnapshot"
+          }
+        }
+      }
+    },
+    "Error": {
+      "description": "Returned with every 4xx and 5xx response",
+      "type": "object",
+      "required": [
+        "code",
+        "message"
+      ],
+      "properties": {
+        "code": {
+          "description": "The HTTP status code of the response",
+          "type": "integer",
+          "format": "int32"
+        },
+        "message": {
+          "type": "string"
+        }
+      }
+    },
     "OperationPEParamItem": {
       "type": "object",
       "properties": {
@@ -485,6 +1284,12 @@ func init() {
         "componentSpecs"
       ],
       "properties": {
+        "annotations": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "string"
+          }
+        },
         "combinedTransports": {
           "type": "array",
           "items": {
@@ -506,33 +1311,119 @@ func init() {
         "id": {
           "$ref": "#/definitions/ProtectedEntityID"
         },
-        "metadataTransports": {
+        "labels": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "string"
+          }
+        },
+        "metadataTransports": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/DataTransport"
+          }
+        },
+        "name": {
+          "type": "string"
+        }
+      }
+    },
+    "ProtectedEntityList": {
+      "type": "object",
+      "properties": {
+        "continuationToken": {
+          "description": "Set when truncated, pass it to retrieve the next page",
+          "type": "string"
+        },
+        "list": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ProtectedEntityID"
+          }
+        },
+        "truncated": {
+          "type": "boolean"
+        }
+      }
+    },
+    "ProtectedEntitySnapshotID": {
+      "type": "string"
+    },
+    "ProtectedEntityUsage": {
+      "type": "object",
+      "required": [
+        "id",
+        "usage"
+      ],
+      "properties": {
+        "id": {
+          "$ref": "#/definitions/ProtectedEntityID"
+        },
+        "snapshots": {
           "type": "array",
           "items": {
-            "$ref": "#/definitions/DataTransport"
+            "$ref": "#/definitions/SnapshotUsage"
           }
         },
-        "name": {
-          "type": "string"
+        "usage": {
+          "$ref": "#/definitions/UsageStats"
         }
       }
     },
-    "ProtectedEntityList": {
+    "RepositoryUsage": {
       "type": "object",
       "properties": {
-        "list": {
+        "services": {
           "type": "array",
           "items": {
-            "$ref": "#/definitions/ProtectedEntityID"
+            "$ref": "#/definitions/ServiceUsage"
           }
         },
-        "truncated": {
+        "usage": {
+          "$ref": "#/definitions/UsageStats"
+        }
+      }
+    },
+    "Retention": {
+      "type": "object",
+      "required": [
+        "mode",
+        "legalHold"
+      ],
+      "properties": {
+        "legalHold": {
+          "description": "The snapshot cannot be deleted while a legal hold is in place",
           "type": "boolean"
+        },
+        "mode": {
+          "type": "string",
+          "enum": [
+            "none",
+            "governance",
+            "compliance"
+          ]
+        },
+        "retainUntil": {
+          "description": "The snapshot cannot be deleted before this time.  Omitted if mode is none",
+          "type": "string",
+          "format": "date-time"
         }
       }
     },
-    "ProtectedEntitySnapshotID": {
-      "type": "string"
+    "RetentionUpdate": {
+      "type": "object",
+      "properties": {
+        "legalHold": {
+          "description": "Set to place or release a legal hold, unchanged if omitted",
+          "type": "boolean",
+          "x-nullable": true
+        },
+        "retainUntil": {
+          "description": "New retain until time, must be later than the current one",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
     },
     "ServiceList": {
       "type": "object",
@@ -545,6 +1436,42 @@ func init() {
         }
       }
     },
+    "ServiceUsage": {
+      "type": "object",
+      "required": [
+        "service",
+        "usage"
+      ],
+      "properties": {
+        "protectedEntities": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ProtectedEntityUsage"
+          }
+        },
+        "service": {
+          "type": "string"
+        },
+        "usage": {
+          "$ref": "#/definitions/UsageStats"
+        }
+      }
+    },
+    "SnapshotUsage": {
+      "type": "object",
+      "required": [
+        "id",
+        "usage"
+      ],
+      "properties": {
+        "id": {
+          "$ref": "#/definitions/ProtectedEntityID"
+        },
+        "usage": {
+          "$ref": "#/definitions/UsageStats"
+        }
+      }
+    },
     "TaskID": {
       "type": "string"
     },
@@ -581,10 +1508,22 @@ func init() {
         "id": {
           "$ref": "#/definitions/TaskID"
         },
+        "locks": {
+          "description": "The Protected Entity locks the task holds or is waiting for",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/TaskLock"
+          },
+          "x-omitempty": true
+        },
         "progress": {
           "type": "number",
           "maximum": 100
         },
+        "queuePosition": {
+          "description": "Position of the task in the admission queue of the services it is waiting for, 1 is next.  Not set once the task is running.",
+          "type": "integer"
+        },
         "result": {
           "type": "object"
         },
@@ -606,6 +1545,34 @@ func init() {
         }
       }
     },
+    "TaskLock": {
+      "description": "A lock on a Protected Entity.  Locks are taken on the Protected Entity without its snapshot ID, so that snapshots, snapshot deletes and overwrites of a Protected Entity do not run at the same time.",
+      "type": "object",
+      "required": [
+        "protectedEntityID",
+        "mode",
+        "state"
+      ],
+      "properties": {
+        "mode": {
+          "type": "string",
+          "enum": [
+            "read",
+            "write"
+          ]
+        },
+        "protectedEntityID": {
+          "$ref": "#/definitions/ProtectedEntityID"
+        },
+        "state": {
+          "type": "string",
+          "enum": [
+            "waiting",
+            "held"
+          ]
+        }
+      }
+    },
     "TaskNexusID": {
       "type": "string"
     },
@@ -617,138 +1584,553 @@ func init() {
           "items": {
             "$ref": "#/definitions/TaskID"
           }
-        },
-        "id": {
-          "$ref": "#/definitions/TaskNexusID"
+        },
+        "id": {
+          "$ref": "#/definitions/TaskNexusID"
+        }
+      }
+    },
+    "TaskNexusList": {
+      "type": "array",
+      "items": {
+        "$ref": "#/definitions/TaskNexusInfo"
+      }
+    },
+    "TaskNexusResponse": {
+      "type": "object",
+      "properties": {
+        "finished": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/TaskInfo"
+          }
+        },
+        "id": {
+          "$ref": "#/definitions/TaskNexusID"
+        }
+      }
+    },
+    "UsageStats": {
+      "type": "object",
+      "required": [
+        "logicalBytes",
+        "storedBytes",
+        "segmentCount"
+      ],
+      "properties": {
+        "logicalBytes": {
+          "description": "Bytes of data and metadata as seen by a reader of the protected entity",
+          "type": "integer",
+          "format": "int64"
+        },
+        "reductionRatio": {
+          "description": "logicalBytes / storedBytes, the dedup or compression ratio.  Omitted if nothing is stored",
+          "type": "number",
+          "format": "double"
+        },
+        "segmentCount": {
+          "type": "integer",
+          "format": "int64"
+        },
+        "storedBytes": {
+          "description": "Bytes used in the repository, including protected entity info",
+          "type": "integer",
+          "format": "int64"
+        }
+      }
+    }
+  },
+  "x-components": {}
+}`))
+	FlatSwaggerJSON = json.RawMessage([]byte(`{
+  "schemes": [
+    "https"
+  ],
+  "swagger": "2.0",
+  "info": {
+    "description": "Astrolabe data protection framework API",
+    "title": "Astrolabe API",
+    "version": "1.0.0"
+  },
+  "basePath": "/v1",
+  "paths": {
+    "/astrolabe": {
+      "get": {
+        "description": "This returns the list of services that this Astrolabe server supports\n",
+        "produces": [
+          "application/json"
+        ],
+        "summary": "List available services",
+        "operationId": "listServices",
+        "responses": {
+          "200": {
+            "description": "200 response",
+            "schema": {
+              "$ref": "#/definitions/ServiceList"
+            }
+          }
+        }
+      }
+    },
+    "/astrolabe/audit": {
+      "get": {
+        "description": "Returns the events recorded in the audit log for mutating calls (snapshot, copy, delete, retention,\nundelete and rehydrate) and for the tasks they started.\n",
+        "produces": [
+          "application/json"
+        ],
+        "summary": "Lists audit events",
+        "operationId": "listAuditEvents",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Only return events for this protected entity.  Events for snapshots of the protected entity are included\nwhen the ID does not have a snapshot ID\n",
+            "name": "protectedEntityID",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "Only return events at or after this time",
+            "name": "from",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "format": "date-time",
+            "description": "Only return events before this time",
+            "name": "to",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The matching audit events, oldest first",
+            "schema": {
+              "$ref": "#/definitions/AuditEventList"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID or time range",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "The audit log is not enabled",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "The audit log could not be read",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/astrolabe/tasks": {
+      "get": {
+        "description": "Lists running and recent tasks",
+        "produces": [
+          "application/json"
+        ],
+        "operationId": "listTasks",
+        "responses": {
+          "200": {
+            "description": "List of recent task IDs",
+            "schema": {
+              "$ref": "#/definitions/TaskIDList"
+            }
+          }
+        }
+      }
+    },
+    "/astrolabe/tasks/events": {
+      "get": {
+        "description": "Streams the status and progress changes of one task or of all tasks as Server-Sent Events.  When the stream\nstarts, the current state of the tasks is sent, unless the client resumes with Last-Event-ID.\n",
+        "produces": [
+          "text/event-stream"
+        ],
+        "operationId": "watchTaskEvents",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Only stream the events of this task",
+            "name": "taskID",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "The ID of the last event the client received.  The stream resumes with the events after it.  If those\nevents are no longer kept, the stream starts with the current state of the tasks.\n",
+            "name": "Last-Event-ID",
+            "in": "header"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "A stream of Server-Sent Events.  Each event has an ID, a type, status when a task starts or its status\nchanges and progress when its progress, details, queue position or locks change, and the task info as\nJSON data.\n",
+            "schema": {
+              "type": "file"
+            }
+          },
+          "404": {
+            "description": "Task not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/astrolabe/tasks/nexus": {
+      "get": {
+        "description": "Provides a list of current task nexus",
+        "produces": [
+          "application/json"
+        ],
+        "operationId": "listTaskNexus",
+        "responses": {
+          "200": {
+            "description": "Task nexus list",
+            "schema": {
+              "$ref": "#/definitions/TaskNexusList"
+            }
+          }
+        }
+      },
+      "post": {
+        "description": "Creates a new nexus for monitoring task completion",
+        "produces": [
+          "application/json"
+        ],
+        "operationId": "createTaskNexus",
+        "responses": {
+          "200": {
+            "description": "New task nexus",
+            "schema": {
+              "$ref": "#/definitions/TaskNexusID"
+            }
+          }
         }
       }
     },
-    "TaskNexusList": {
-      "type": "array",
-      "items": {
-        "$ref": "#/definitions/TaskNexusInfo"
-      }
-    },
-    "TaskNexusResponse": {
-      "type": "object",
-      "properties": {
-        "finished": {
-          "type": "array",
-          "items": {
-            "$ref": "#/definitions/TaskInfo"
+    "/astrolabe/tasks/nexus/{taskNexusID}": {
+      "get": {
+        "description": "Waits for tasks associated with the nexus to finish.  Tasks started after the nexus was created are\nassociated with it\n",
+        "produces": [
+          "application/json"
+        ],
+        "operationId": "waitOnTaskNexus",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "The nexus to wait on",
+            "name": "taskNexusID",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "integer",
+            "description": "Time to wait (milliseconds) before returning if no tasks   complete",
+            "name": "waitTime",
+            "in": "query",
+            "required": true
+          },
+          {
+            "type": "integer",
+            "description": "Last finished time seen by this client.  Tasks that have completed after this time tick will be returned, or if no tasks\nhave finished, the call will hang until waitTime has passed or a task finishes.  Starting time tick should\nbe the finished time of the last task that the caller saw completed on this nexus.  Use 0 to get all finished\ntasks (tasks that have finished and timed out of the server will not be shown)\n",
+            "name": "lastFinishedNS",
+            "in": "query",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "200 response",
+            "schema": {
+              "$ref": "#/definitions/TaskNexusResponse"
+            }
+          },
+          "400": {
+            "description": "Invalid wait time or last finished time",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Task nexus not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
-        },
-        "id": {
-          "$ref": "#/definitions/TaskNexusID"
         }
       }
-    }
-  },
-  "x-components": {}
-}`))
-	FlatSwaggerJSON = json.RawMessage([]byte(`{
-  "schemes": [
-    "https"
-  ],
-  "swagger": "2.0",
-  "info": {
-    "description": "Astrolabe data protection framework API",
-    "title": "Astrolabe API",
-    "version": "1.0.0"
-  },
-  "basePath": "/v1",
-  "paths": {
-    "/astrolabe": {
+    },
+    "/astrolabe/tasks/{taskID}": {
       "get": {
-        "description": "This returns the list of services that this Astrolabe server supports\n",
         "produces": [
           "application/json"
         ],
-        "summary": "List available services",
-        "operationId": "listServices",
+        "summary": "Gets info about a running or recently completed task",
+        "operationId": "getTaskInfo",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "The ID of the task to retrieve info for",
+            "name": "taskID",
+            "in": "path",
+            "required": true
+          }
+        ],
         "responses": {
           "200": {
-            "description": "200 response",
+            "description": "Info for running or recently completed task",
             "schema": {
-              "$ref": "#/definitions/ServiceList"
+              "$ref": "#/definitions/TaskInfo"
+            }
+          },
+          "404": {
+            "description": "Task not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           }
         }
       }
     },
-    "/astrolabe/tasks": {
+    "/astrolabe/usage": {
       "get": {
-        "description": "Lists running and recent tasks",
+        "description": "Reports logical and stored bytes per protected entity, per snapshot and per service for the services\nthat store protected entities (repositories).  Services that do not store data are not included.\n",
         "produces": [
           "application/json"
         ],
-        "operationId": "listTasks",
+        "summary": "Reports repository usage",
+        "operationId": "getUsage",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "Only report usage for this service",
+            "name": "service",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only report usage for protected entities whose IDs start with this prefix",
+            "name": "idPrefix",
+            "in": "query"
+          }
+        ],
         "responses": {
           "200": {
-            "description": "List of recent task IDs",
+            "description": "Usage for the services that store protected entities",
             "schema": {
-              "$ref": "#/definitions/TaskIDList"
+              "$ref": "#/definitions/RepositoryUsage"
+            }
+          },
+          "404": {
+            "description": "Service not found or service does not report usage",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Usage could not be retrieved",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           }
         }
       }
     },
-    "/astrolabe/tasks/nexus": {
+    "/astrolabe/{service}": {
       "get": {
-        "description": "Provides a list of current task nexus",
+        "description": "List protected entities for the service.  Results will be returned in\ncanonical ID order (string sorted) unless orderBy or order are set.\nFewer results may be returned than expected, the ProtectedEntityList\nhas a field specifying if the list has been truncated and a\ncontinuationToken for the next page.\n",
         "produces": [
           "application/json"
         ],
-        "operationId": "listTaskNexus",
+        "operationId": "listProtectedEntities",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "The service to list protected entities from",
+            "name": "service",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "integer",
+            "format": "int32",
+            "description": "The maximum number of results to return (fewer results may be returned)",
+            "name": "maxResults",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Results will be returned that come after this ID.  Deprecated, use\ncontinuationToken.  Cannot be combined with continuationToken or\nordering\n",
+            "name": "idsAfter",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Continue the list from a previous response, pass its\ncontinuationToken with the same orderBy and order\n",
+            "name": "continuationToken",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return protected entities whose ID, without the service,\nstarts with this prefix\n",
+            "name": "prefix",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return protected entities with this name",
+            "name": "name",
+            "in": "query"
+          },
+          {
+            "enum": [
+              "id",
+              "name"
+            ],
+            "type": "string",
+            "description": "The order of the results, id by default",
+            "name": "orderBy",
+            "in": "query"
+          },
+          {
+            "enum": [
+              "asc",
+              "desc"
+            ],
+            "type": "string",
+            "description": "Ascending or descending order, asc by default",
+            "name": "order",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return protected entities whose labels match this label\nselector, e.g. app=nginx,tier!=frontend\n",
+            "name": "labelSelector",
+            "in": "query"
+          }
+        ],
         "responses": {
           "200": {
-            "description": "Task nexus list",
+            "description": "200 response",
             "schema": {
-              "$ref": "#/definitions/TaskNexusList"
+              "$ref": "#/definitions/ProtectedEntityList"
+            }
+          },
+          "400": {
+            "description": "Invalid label selector or list options",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "List failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           }
         }
       },
       "post": {
-        "description": "Creates a new nexus for monitoring task completion",
+        "description": "Copy a protected entity into the repository.  There is no option to\nembed data on this path, for a self-contained or partially\nself-contained object, use the restore from zip file option in the S3\nAPI REST API\n",
+        "consumes": [
+          "application/json"
+        ],
         "produces": [
           "application/json"
         ],
+        "operationId": "copyProtectedEntity",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "A key chosen by the client that identifies this request.  If a\nrequest with the same key has already completed successfully within\nthe server's idempotency window, its result is returned instead of\nrunning the operation again.  Keys are scoped to the caller and\ncannot be reused for a different request.\n",
+            "name": "Idempotency-Key",
+            "in": "header"
+          },
+          {
+            "type": "string",
+            "description": "The service to copy the protected entity into",
+            "name": "service",
+            "in": "path",
+            "required": true
+          },
+          {
+            "enum": [
+              "create",
+              "create_new",
+              "update"
+            ],
+            "type": "string",
+            "description": "How to handle the copy.  create - a new protected entity with the\nProtected Entity ID will be created.  If the Protected Entity ID\nalready exists, the copy will fail.  create_new - A Protected Entity\nwith a new ID will be created with data and metadata from the source\nprotected entity.  Update - If a protected entity with the same ID\nexists it will be overwritten.  If there is no PE with that ID, one\nwill be created with the same ID. For complex Persistent Entities,\nthe mode will be applied to all of the component entities that are\npart of this operation as well.\n",
+            "name": "mode",
+            "in": "query",
+            "required": true
+          },
+          {
+            "description": "Copy Parameters including protected entity to copy",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/CopyParameters"
+            }
+          }
+        ],
         "responses": {
-          "200": {
-            "description": "New task nexus",
+          "202": {
+            "description": "Create in progress",
             "schema": {
-              "$ref": "#/definitions/TaskNexusID"
+              "$ref": "#/definitions/CreateInProgressResponse"
+            }
+          },
+          "400": {
+            "description": "Invalid copy parameters or protected entity info",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           }
         }
       }
     },
-    "/astrolabe/tasks/nexus/{taskNexusID}": {
+    "/astrolabe/{service}/{protectedEntityID}": {
       "get": {
+        "description": "Get the info for a Protected Entity including name, data access and\ncomponents\n",
         "produces": [
           "application/json"
         ],
+        "operationId": "getProtectedEntityInfo",
         "parameters": [
           {
             "type": "string",
-            "description": "The nexus to wait on",
-            "name": "taskNexusID",
+            "description": "The service for the protected entity",
+            "name": "service",
             "in": "path",
             "required": true
           },
           {
-            "type": "integer",
-            "description": "Time to wait (milliseconds) before returning if no tasks   complete",
-            "name": "waitTime",
-            "in": "query",
-            "required": true
-          },
-          {
-            "type": "integer",
-            "description": "Last finished time seen by this client.  Tasks that have completed after this time tick will be returned, or if no tasks\nhave finished, the call will hang until waitTime has passed or a task finishes.  Starting time tick should\nbe the finished time of the last task that the caller saw completed on this nexus.  Use 0 to get all finished\ntasks (tasks that have finished and timed out of the server will not be shown)\n",
-            "name": "lastFinishedNS",
-            "in": "query",
+            "type": "string",
+            "description": "The protected entity ID to retrieve info for",
+            "name": "protectedEntityID",
+            "in": "path",
             "required": true
           }
         ],
@@ -756,135 +2138,206 @@ func init() {
           "200": {
             "description": "200 response",
             "schema": {
-              "$ref": "#/definitions/TaskNexusResponse"
+              "$ref": "#/definitions/ProtectedEntityInfo"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service or Protected Entity not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to retrieve the protected entity info",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           }
         }
-      }
-    },
-    "/astrolabe/tasks/{taskID}": {
-      "get": {
+      },
+      "delete": {
+        "description": "Deletes a protected entity or snapshot of a protected entity (if the\nsnapshot ID is specified).  Services that support soft delete keep\ndeleted snapshots for an undelete window, see undeleteSnapshot\n",
         "produces": [
           "application/json"
         ],
-        "summary": "Gets info about a running or recently completed task",
-        "operationId": "getTaskInfo",
+        "operationId": "deleteProtectedEntity",
         "parameters": [
           {
             "type": "string",
-            "description": "The ID of the task to retrieve info for",
-            "name": "taskID",
+            "description": "A key chosen by the client that identifies this request.  If a\nrequest with the same key has already completed successfully within\nthe server's idempotency window, its result is returned instead of\nrunning the operation again.  Keys are scoped to the caller and\ncannot be reused for a different request.\n",
+            "name": "Idempotency-Key",
+            "in": "header"
+          },
+          {
+            "type": "string",
+            "description": "The service for the protected entity",
+            "name": "service",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "string",
+            "description": "The protected entity ID to retrieve info for",
+            "name": "protectedEntityID",
             "in": "path",
             "required": true
           }
         ],
         "responses": {
           "200": {
-            "description": "Info for running or recently completed task",
+            "description": "200 response",
             "schema": {
-              "$ref": "#/definitions/TaskInfo"
+              "$ref": "#/definitions/ProtectedEntityID"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID or the ID does not have a snapshot ID",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service or Protected Entity not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "409": {
+            "description": "The snapshot is retained and cannot be deleted or another operation on the protected entity holds its lock",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Delete failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           }
         }
       }
     },
-    "/astrolabe/{service}": {
+    "/astrolabe/{service}/{protectedEntityID}/deletedSnapshots": {
       "get": {
-        "description": "List protected entities for the service.  Results will be returned in\ncanonical ID order (string sorted).  Fewer results may be returned than\nexpected, the ProtectedEntityList has a field specifying if the list has\nbeen truncated.\n",
+        "description": "Lists the deleted snapshots of this protected entity that can still be\nrestored with undeleteSnapshot\n",
         "produces": [
           "application/json"
         ],
-        "operationId": "listProtectedEntities",
+        "operationId": "listDeletedSnapshots",
         "parameters": [
           {
             "type": "string",
-            "description": "The service to list protected entities from",
+            "description": "The service for the protected entity",
             "name": "service",
             "in": "path",
             "required": true
           },
-          {
-            "type": "integer",
-            "format": "int32",
-            "description": "The maximum number of results to return (fewer results may be returned)",
-            "name": "maxResults",
-            "in": "query"
-          },
           {
             "type": "string",
-            "description": "Results will be returned that come after this ID",
-            "name": "idsAfter",
-            "in": "query"
+            "description": "The protected entity ID to list deleted snapshots for.  If a\nsnapshot ID is included only that snapshot is returned\n",
+            "name": "protectedEntityID",
+            "in": "path",
+            "required": true
           }
         ],
         "responses": {
           "200": {
-            "description": "200 response",
+            "description": "List succeeded",
             "schema": {
-              "$ref": "#/definitions/ProtectedEntityList"
+              "$ref": "#/definitions/DeletedSnapshotList"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           },
           "404": {
-            "description": "Service or Protected Entity not found"
+            "description": "Service not found or the service does not support soft delete",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "List failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
         }
-      },
+      }
+    },
+    "/astrolabe/{service}/{protectedEntityID}/rehydrate": {
       "post": {
-        "description": "Copy a protected entity into the repository.  There is no option to\nembed data on this path, for a self-contained or partially\nself-contained object, use the restore from zip file option in the S3\nAPI REST API\n",
-        "consumes": [
-          "application/json"
-        ],
+        "description": "Restores the data of a snapshot that has been moved to an archive storage tier so that it can be read.\nRestores can take hours, use getTaskInfo to follow the returned task\n",
         "produces": [
           "application/json"
         ],
-        "operationId": "copyProtectedEntity",
+        "operationId": "rehydrateSnapshot",
         "parameters": [
           {
             "type": "string",
-            "description": "The service to copy the protected entity into",
+            "description": "A key chosen by the client that identifies this request.  If a\nrequest with the same key has already completed successfully within\nthe server's idempotency window, its result is returned instead of\nrunning the operation again.  Keys are scoped to the caller and\ncannot be reused for a different request.\n",
+            "name": "Idempotency-Key",
+            "in": "header"
+          },
+          {
+            "type": "string",
+            "description": "The service for the protected entity",
             "name": "service",
             "in": "path",
             "required": true
           },
           {
-            "enum": [
-              "create",
-              "create_new",
-              "update"
-            ],
             "type": "string",
-            "description": "How to handle the copy.  create - a new protected entity with the\nProtected Entity ID will be created.  If the Protected Entity ID\nalready exists, the copy will fail.  create_new - A Protected Entity\nwith a new ID will be created with data and metadata from the source\nprotected entity.  Update - If a protected entity with the same ID\nexists it will be overwritten.  If there is no PE with that ID, one\nwill be created with the same ID. For complex Persistent Entities,\nthe mode will be applied to all of the component entities that are\npart of this operation as well.\n",
-            "name": "mode",
-            "in": "query",
+            "description": "The protected entity snapshot ID to rehydrate",
+            "name": "protectedEntityID",
+            "in": "path",
             "required": true
-          },
-          {
-            "description": "Copy Parameters including protected entity to copy",
-            "name": "body",
-            "in": "body",
-            "required": true,
-            "schema": {
-              "$ref": "#/definitions/CopyParameters"
-            }
           }
         ],
         "responses": {
           "202": {
-            "description": "Create in progress",
+            "description": "Rehydration started, the task reports its progress",
             "schema": {
               "$ref": "#/definitions/CreateInProgressResponse"
             }
+          },
+          "400": {
+            "description": "Invalid protected entity ID",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service not found, the service does not support tiering or the snapshot does not exist",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Rehydration could not be started",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
         }
       }
     },
-    "/astrolabe/{service}/{protectedEntityID}": {
+    "/astrolabe/{service}/{protectedEntityID}/retention": {
       "get": {
-        "description": "Get the info for a Protected Entity including name, data access and\ncomponents\n",
+        "description": "Gets the WORM retention and legal hold for a snapshot stored in a repository\n",
         "produces": [
           "application/json"
         ],
-        "operationId": "getProtectedEntityInfo",
+        "operationId": "getRetention",
         "parameters": [
           {
             "type": "string",
@@ -895,7 +2348,7 @@ func init() {
           },
           {
             "type": "string",
-            "description": "The protected entity ID to retrieve info for",
+            "description": "The snapshot protected entity ID to retrieve retention for",
             "name": "protectedEntityID",
             "in": "path",
             "required": true
@@ -905,18 +2358,42 @@ func init() {
           "200": {
             "description": "200 response",
             "schema": {
-              "$ref": "#/definitions/ProtectedEntityInfo"
+              "$ref": "#/definitions/Retention"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service or snapshot not found, or the service does not support retention",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to retrieve retention",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           }
         }
       },
-      "delete": {
-        "description": "Deletes a protected entity or snapshot of a protected entity (if the\nsnapshot ID is specified)\n",
+      "put": {
+        "description": "Extends the retention of a snapshot and/or places or releases a legal hold.\nRetention can only be extended, never shortened.\n",
         "produces": [
           "application/json"
         ],
-        "operationId": "deleteProtectedEntity",
+        "operationId": "updateRetention",
         "parameters": [
+          {
+            "type": "string",
+            "description": "A key chosen by the client that identifies this request.  If a\nrequest with the same key has already completed successfully within\nthe server's idempotency window, its result is returned instead of\nrunning the operation again.  Keys are scoped to the caller and\ncannot be reused for a different request.\n",
+            "name": "Idempotency-Key",
+            "in": "header"
+          },
           {
             "type": "string",
             "description": "The service for the protected entity",
@@ -926,17 +2403,44 @@ func init() {
           },
           {
             "type": "string",
-            "description": "The protected entity ID to retrieve info for",
+            "description": "The snapshot protected entity ID to update retention for",
             "name": "protectedEntityID",
             "in": "path",
             "required": true
+          },
+          {
+            "description": "The retention changes",
+            "name": "update",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/RetentionUpdate"
+            }
           }
         ],
         "responses": {
           "200": {
-            "description": "200 response",
+            "description": "Retention updated, returns the new retention",
             "schema": {
-              "$ref": "#/definitions/ProtectedEntityID"
+              "$ref": "#/definitions/Retention"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID or the retention cannot be shortened",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service or snapshot not found, or the service does not support retention",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Failed to update retention",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           }
         }
@@ -944,7 +2448,7 @@ func init() {
     },
     "/astrolabe/{service}/{protectedEntityID}/snapshots": {
       "get": {
-        "description": "Gets the list of snapshots for this protected entity\n",
+        "description": "Gets the list of snapshots for this protected entity.  Results will be\nreturned in canonical ID order unless orderBy or order are set.\n",
         "produces": [
           "application/json"
         ],
@@ -959,31 +2463,174 @@ func init() {
           },
           {
             "type": "string",
-            "description": "The protected entity ID to retrieve info for",
+            "description": "The protected entity ID to retrieve info for",
+            "name": "protectedEntityID",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "string",
+            "description": "Only return snapshots whose labels match this label selector,\ne.g. app=nginx,tier!=frontend\n",
+            "name": "labelSelector",
+            "in": "query"
+          },
+          {
+            "type": "integer",
+            "format": "int32",
+            "description": "The maximum number of results to return (fewer results may be returned)",
+            "name": "maxResults",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Continue the list from a previous response, pass its\ncontinuationToken with the same orderBy and order\n",
+            "name": "continuationToken",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return snapshots whose snapshot ID starts with this prefix\n",
+            "name": "prefix",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only return snapshots with this name",
+            "name": "name",
+            "in": "query"
+          },
+          {
+            "enum": [
+              "id",
+              "name"
+            ],
+            "type": "string",
+            "description": "The order of the results, id by default",
+            "name": "orderBy",
+            "in": "query"
+          },
+          {
+            "enum": [
+              "asc",
+              "desc"
+            ],
+            "type": "string",
+            "description": "Ascending or descending order, asc by default",
+            "name": "order",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "List succeeded",
+            "schema": {
+              "$ref": "#/definitions/ProtectedEntityList"
+            }
+          },
+          "400": {
+            "description": "Invalid label selector or list options",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service or Protected Entity not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "List failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      },
+      "post": {
+        "description": "Creates a new snapshot for this protected entity\n",
+        "produces": [
+          "application/json"
+        ],
+        "operationId": "createSnapshot",
+        "parameters": [
+          {
+            "type": "string",
+            "description": "A key chosen by the client that identifies this request.  If a\nrequest with the same key has already completed successfully within\nthe server's idempotency window, its result is returned instead of\nrunning the operation again.  Keys are scoped to the caller and\ncannot be reused for a different request.\n",
+            "name": "Idempotency-Key",
+            "in": "header"
+          },
+          {
+            "type": "string",
+            "description": "The service for the protected entity",
+            "name": "service",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "string",
+            "description": "The protected entity ID to snapshot",
             "name": "protectedEntityID",
             "in": "path",
             "required": true
+          },
+          {
+            "description": "Parameters for the snapshot.",
+            "name": "params",
+            "in": "body",
+            "schema": {
+              "$ref": "#/definitions/OperationParamList"
+            }
           }
         ],
         "responses": {
           "200": {
-            "description": "List succeeded",
+            "description": "Snapshot created successfully, returns the new snapshot ID",
             "schema": {
-              "$ref": "#/definitions/ProtectedEntityList"
+              "$ref": "#/definitions/ProtectedEntitySnapshotID"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           },
           "404": {
-            "description": "Service or Protected Entity not found"
+            "description": "Service or Protected Entity not found",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "409": {
+            "description": "Another operation on the protected entity holds its lock",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Snapshot failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
           }
         }
-      },
+      }
+    },
+    "/astrolabe/{service}/{protectedEntityID}/undelete": {
       "post": {
-        "description": "Creates a new snapshot for this protected entity\n",
+        "description": "Restores a deleted snapshot before it is purged\n",
         "produces": [
           "application/json"
         ],
-        "operationId": "createSnapshot",
+        "operationId": "undeleteSnapshot",
         "parameters": [
+          {
+            "type": "string",
+            "description": "A key chosen by the client that identifies this request.  If a\nrequest with the same key has already completed successfully within\nthe server's idempotency window, its result is returned instead of\nrunning the operation again.  Keys are scoped to the caller and\ncannot be reused for a different request.\n",
+            "name": "Idempotency-Key",
+            "in": "header"
+          },
           {
             "type": "string",
             "description": "The service for the protected entity",
@@ -993,25 +2640,35 @@ func init() {
           },
           {
             "type": "string",
-            "description": "The protected entity ID to snapshot",
+            "description": "The protected entity snapshot ID to restore",
             "name": "protectedEntityID",
             "in": "path",
             "required": true
-          },
-          {
-            "description": "Parameters for the snapshot.",
-            "name": "params",
-            "in": "body",
-            "schema": {
-              "$ref": "#/definitions/OperationParamList"
-            }
           }
         ],
         "responses": {
           "200": {
-            "description": "Snapshot created successfully, returns the new snapshot ID",
+            "description": "Snapshot restored, returns the snapshot protected entity ID",
             "schema": {
-              "$ref": "#/definitions/ProtectedEntitySnapshotID"
+              "$ref": "#/definitions/ProtectedEntityID"
+            }
+          },
+          "400": {
+            "description": "Invalid protected entity ID",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "Service not found, the service does not support soft delete or the snapshot is not deleted",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Undelete failed",
+            "schema": {
+              "$ref": "#/definitions/Error"
             }
           }
         }
@@ -1019,6 +2676,94 @@ func init() {
     }
   },
   "definitions": {
+    "AuditEvent": {
+      "type": "object",
+      "required": [
+        "sequence",
+        "time",
+        "action",
+        "operation",
+        "outcome",
+        "hash"
+      ],
+      "properties": {
+        "action": {
+          "description": "snapshot, copy, overwrite, delete, retention, undelete or rehydrate",
+          "type": "string"
+        },
+        "authMethod": {
+          "type": "string"
+        },
+        "durationSeconds": {
+          "type": "number",
+          "format": "double"
+        },
+        "error": {
+          "type": "string"
+        },
+        "hash": {
+          "description": "SHA-256 of the previous hash and the event without its hash",
+          "type": "string"
+        },
+        "identity": {
+          "description": "The authenticated caller, empty if the server does not authenticate",
+          "type": "string"
+        },
+        "operation": {
+          "description": "The API call, an OpenAPI operation ID, s3Copy for the S3 copy bucket or task for the end of a task",
+          "type": "string"
+        },
+        "outcome": {
+          "description": "success, failure, denied or accepted for calls that start a task",
+          "type": "string"
+        },
+        "params": {
+          "description": "Parameters of the call, secrets are redacted",
+          "type": "object"
+        },
+        "previousHash": {
+          "description": "Hash of the previous event, the first event chains from an empty hash",
+          "type": "string"
+        },
+        "protectedEntityIDs": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ProtectedEntityID"
+          }
+        },
+        "sequence": {
+          "description": "Position of the event in the audit log, starting at 1",
+          "type": "integer",
+          "format": "int64"
+        },
+        "service": {
+          "type": "string"
+        },
+        "status": {
+          "description": "HTTP status of the response",
+          "type": "integer",
+          "format": "int32"
+        },
+        "taskID": {
+          "$ref": "#/definitions/TaskID"
+        },
+        "time": {
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "AuditEventList": {
+      "type": "object",
+      "properties": {
+        "list": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/AuditEvent"
+          }
+        }
+      }
+    },
     "ComponentSpec": {
       "type": "object",
       "required": [
@@ -1066,6 +2811,56 @@ func init() {
         }
       }
     },
+    "DeletedSnapshot": {
+      "type": "object",
+      "required": [
+        "id",
+        "deletedAt"
+      ],
+      "properties": {
+        "deletedAt": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "id": {
+          "$ref": "#/definitions/ProtectedEntityID"
+        },
+        "purgeAfter": {
+          "description": "The snapshot may be purged, and can no longer be restored, after this time",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "DeletedSnapshotList": {
+      "type": "object",
+      "properties": {
+        "list": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/DeletedSnapshot"
+          }
+        }
+      }
+    },
+    "Error": {
+      "description": "Returned with every 4xx and 5xx response",
+      "type": "object",
+      "required": [
+        "code",
+        "message"
+      ],
+      "properties": {
+        "code": {
+          "description": "The HTTP status code of the response",
+          "type": "integer",
+          "format": "int32"
+        },
+        "message": {
+          "type": "string"
+        }
+      }
+    },
     "OperationPEParamItem": {
       "type": "object",
       "properties": {
@@ -1114,6 +2909,12 @@ func init() {
         "componentSpecs"
       ],
       "properties": {
+        "annotations": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "string"
+          }
+        },
         "combinedTransports": {
           "type": "array",
           "items": {
@@ -1135,6 +2936,12 @@ func init() {
         "id": {
           "$ref": "#/definitions/ProtectedEntityID"
         },
+        "labels": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "string"
+          }
+        },
         "metadataTransports": {
           "type": "array",
           "items": {
@@ -1149,6 +2956,10 @@ func init() {
     "ProtectedEntityList": {
       "type": "object",
       "properties": {
+        "continuationToken": {
+          "description": "Set when truncated, pass it to retrieve the next page",
+          "type": "string"
+        },
         "list": {
           "type": "array",
           "items": {
@@ -1163,6 +2974,82 @@ func init() {
     "ProtectedEntitySnapshotID": {
       "type": "string"
     },
+    "ProtectedEntityUsage": {
+      "type": "object",
+      "required": [
+        "id",
+        "usage"
+      ],
+      "properties": {
+        "id": {
+          "$ref": "#/definitions/ProtectedEntityID"
+        },
+        "snapshots": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/SnapshotUsage"
+          }
+        },
+        "usage": {
+          "$ref": "#/definitions/UsageStats"
+        }
+      }
+    },
+    "RepositoryUsage": {
+      "type": "object",
+      "properties": {
+        "services": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ServiceUsage"
+          }
+        },
+        "usage": {
+          "$ref": "#/definitions/UsageStats"
+        }
+      }
+    },
+    "Retention": {
+      "type": "object",
+      "required": [
+        "mode",
+        "legalHold"
+      ],
+      "properties": {
+        "legalHold": {
+          "description": "The snapshot cannot be deleted while a legal hold is in place",
+          "type": "boolean"
+        },
+        "mode": {
+          "type": "string",
+          "enum": [
+            "none",
+            "governance",
+            "compliance"
+          ]
+        },
+        "retainUntil": {
+          "description": "The snapshot cannot be deleted before this time.  Omitted if mode is none",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "RetentionUpdate": {
+      "type": "object",
+      "properties": {
+        "legalHold": {
+          "description": "Set to place or release a legal hold, unchanged if omitted",
+          "type": "boolean",
+          "x-nullable": true
+        },
+        "retainUntil": {
+          "description": "New retain until time, must be later than the current one",
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
     "ServiceList": {
       "type": "object",
       "properties": {
@@ -1174,6 +3061,42 @@ func init() {
         }
       }
     },
+    "ServiceUsage": {
+      "type": "object",
+      "required": [
+        "service",
+        "usage"
+      ],
+      "properties": {
+        "protectedEntities": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ProtectedEntityUsage"
+          }
+        },
+        "service": {
+          "type": "string"
+        },
+        "usage": {
+          "$ref": "#/definitions/UsageStats"
+        }
+      }
+    },
+    "SnapshotUsage": {
+      "type": "object",
+      "required": [
+        "id",
+        "usage"
+      ],
+      "properties": {
+        "id": {
+          "$ref": "#/definitions/ProtectedEntityID"
+        },
+        "usage": {
+          "$ref": "#/definitions/UsageStats"
+        }
+      }
+    },
     "TaskID": {
       "type": "string"
     },
@@ -1210,11 +3133,23 @@ func init() {
         "id": {
           "$ref": "#/definitions/TaskID"
         },
+        "locks": {
+          "description": "The Protected Entity locks the task holds or is waiting for",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/TaskLock"
+          },
+          "x-omitempty": true
+        },
         "progress": {
           "type": "number",
           "maximum": 100,
           "minimum": 0
         },
+        "queuePosition": {
+          "description": "Position of the task in the admission queue of the services it is waiting for, 1 is next.  Not set once the task is running.",
+          "type": "integer"
+        },
         "result": {
           "type": "object"
         },
@@ -1236,6 +3171,34 @@ func init() {
         }
       }
     },
+    "TaskLock": {
+      "description": "A lock on a Protected Entity.  Locks are taken on the Protected Entity without its snapshot ID, so that snapshots, snapshot deletes and overwrites of a Protected Entity do not run at the same time.",
+      "type": "object",
+      "required": [
+        "protectedEntityID",
+        "mode",
+        "state"
+      ],
+      "properties": {
+        "mode": {
+          "type": "string",
+          "enum": [
+            "read",
+            "write"
+          ]
+        },
+        "protectedEntityID": {
+          "$ref": "#/definitions/ProtectedEntityID"
+        },
+        "state": {
+          "type": "string",
+          "enum": [
+            "waiting",
+            "held"
+          ]
+        }
+      }
+    },
     "TaskNexusID": {
       "type": "string"
     },
@@ -1272,6 +3235,35 @@ func init() {
           "$ref": "#/definitions/TaskNexusID"
         }
       }
+    },
+    "UsageStats": {
+      "type": "object",
+      "required": [
+        "logicalBytes",
+        "storedBytes",
+        "segmentCount"
+      ],
+      "properties": {
+        "logicalBytes": {
+          "description": "Bytes of data and metadata as seen by a reader of the protected entity",
+          "type": "integer",
+          "format": "int64"
+        },
+        "reductionRatio": {
+          "description": "logicalBytes / storedBytes, the dedup or compression ratio.  Omitted if nothing is stored",
+          "type": "number",
+          "format": "double"
+        },
+        "segmentCount": {
+          "type": "integer",
+          "format": "int64"
+        },
+        "storedBytes": {
+          "description": "Bytes used in the repository, including protected entity info",
+          "type": "integer",
+          "format": "int64"
+        }
+      }
     }
   },
   "x-components": {}
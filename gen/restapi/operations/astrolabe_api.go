@@ -7,6 +7,7 @@ package operations
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -40,28 +41,40 @@ func NewAstrolabeAPI(spec *loads.Document) *AstrolabeAPI {
 		JSONConsumer: runtime.JSONConsumer(),
 
 		JSONProducer: runtime.JSONProducer(),
-
-		GetAstrolabeTasksNexusTaskNexusIDHandler: GetAstrolabeTasksNexusTaskNexusIDHandlerFunc(func(params GetAstrolabeTasksNexusTaskNexusIDParams) middleware.Responder {
-			return middleware.NotImplemented("operation GetAstrolabeTasksNexusTaskNexusID has not yet been implemented")
-		}),
-		PostAstrolabeTasksNexusHandler: PostAstrolabeTasksNexusHandlerFunc(func(params PostAstrolabeTasksNexusParams) middleware.Responder {
-			return middleware.NotImplemented("operation PostAstrolabeTasksNexus has not yet been implemented")
+		TextEventStreamProducer: runtime.ProducerFunc(func(w io.Writer, data interface{}) error {
+			return errors.NotImplemented("textEventStream producer has not yet been implemented")
 		}),
+
 		CopyProtectedEntityHandler: CopyProtectedEntityHandlerFunc(func(params CopyProtectedEntityParams) middleware.Responder {
 			return middleware.NotImplemented("operation CopyProtectedEntity has not yet been implemented")
 		}),
 		CreateSnapshotHandler: CreateSnapshotHandlerFunc(func(params CreateSnapshotParams) middleware.Responder {
 			return middleware.NotImplemented("operation CreateSnapshot has not yet been implemented")
 		}),
+		CreateTaskNexusHandler: CreateTaskNexusHandlerFunc(func(params CreateTaskNexusParams) middleware.Responder {
+			return middleware.NotImplemented("operation CreateTaskNexus has not yet been implemented")
+		}),
 		DeleteProtectedEntityHandler: DeleteProtectedEntityHandlerFunc(func(params DeleteProtectedEntityParams) middleware.Responder {
 			return middleware.NotImplemented("operation DeleteProtectedEntity has not yet been implemented")
 		}),
 		GetProtectedEntityInfoHandler: GetProtectedEntityInfoHandlerFunc(func(params GetProtectedEntityInfoParams) middleware.Responder {
 			return middleware.NotImplemented("operation GetProtectedEntityInfo has not yet been implemented")
 		}),
+		GetRetentionHandler: GetRetentionHandlerFunc(func(params GetRetentionParams) middleware.Responder {
+			return middleware.NotImplemented("operation GetRetention has not yet been implemented")
+		}),
 		GetTaskInfoHandler: GetTaskInfoHandlerFunc(func(params GetTaskInfoParams) middleware.Responder {
 			return middleware.NotImplemented("operation GetTaskInfo has not yet been implemented")
 		}),
+		GetUsageHandler: GetUsageHandlerFunc(func(params GetUsageParams) middleware.Responder {
+			return middleware.NotImplemented("operation GetUsage has not yet been implemented")
+		}),
+		ListAuditEventsHandler: ListAuditEventsHandlerFunc(func(params ListAuditEventsParams) middleware.Responder {
+			return middleware.NotImplemented("operation ListAuditEvents has not yet been implemented")
+		}),
+		ListDeletedSnapshotsHandler: ListDeletedSnapshotsHandlerFunc(func(params ListDeletedSnapshotsParams) middleware.Responder {
+			return middleware.NotImplemented("operation ListDeletedSnapshots has not yet been implemented")
+		}),
 		ListProtectedEntitiesHandler: ListProtectedEntitiesHandlerFunc(func(params ListProtectedEntitiesParams) middleware.Responder {
 			return middleware.NotImplemented("operation ListProtectedEntities has not yet been implemented")
 		}),
@@ -77,6 +90,21 @@ func NewAstrolabeAPI(spec *loads.Document) *AstrolabeAPI {
 		ListTasksHandler: ListTasksHandlerFunc(func(params ListTasksParams) middleware.Responder {
 			return middleware.NotImplemented("operation ListTasks has not yet been implemented")
 		}),
+		RehydrateSnapshotHandler: RehydrateSnapshotHandlerFunc(func(params RehydrateSnapshotParams) middleware.Responder {
+			return middleware.NotImplemented("operation RehydrateSnapshot has not yet been implemented")
+		}),
+		UndeleteSnapshotHandler: UndeleteSnapshotHandlerFunc(func(params UndeleteSnapshotParams) middleware.Responder {
+			return middleware.NotImplemented("operation UndeleteSnapshot has not yet been implemented")
+		}),
+		UpdateRetentionHandler: UpdateRetentionHandlerFunc(func(params UpdateRetentionParams) middleware.Responder {
+			return middleware.NotImplemented("operation UpdateRetention has not yet been implemented")
+		}),
+		WaitOnTaskNexusHandler: WaitOnTaskNexusHandlerFunc(func(params WaitOnTaskNexusParams) middleware.Responder {
+			return middleware.NotImplemented("operation WaitOnTaskNexus has not yet been implemented")
+		}),
+		WatchTaskEventsHandler: WatchTaskEventsHandlerFunc(func(params WatchTaskEventsParams) middleware.Responder {
+			return middleware.NotImplemented("operation WatchTaskEvents has not yet been implemented")
+		}),
 	}
 }
 
@@ -109,21 +137,30 @@ type AstrolabeAPI struct {
 	// JSONProducer registers a producer for the following mime types:
 	//   - application/json
 	JSONProducer runtime.Producer
+	// TextEventStreamProducer registers a producer for the following mime types:
+	//   - text/event-stream
+	TextEventStreamProducer runtime.Producer
 
-	// GetAstrolabeTasksNexusTaskNexusIDHandler sets the operation handler for the get astrolabe tasks nexus task nexus ID operation
-	GetAstrolabeTasksNexusTaskNexusIDHandler GetAstrolabeTasksNexusTaskNexusIDHandler
-	// PostAstrolabeTasksNexusHandler sets the operation handler for the post astrolabe tasks nexus operation
-	PostAstrolabeTasksNexusHandler PostAstrolabeTasksNexusHandler
 	// CopyProtectedEntityHandler sets the operation handler for the copy protected entity operation
 	CopyProtectedEntityHandler CopyProtectedEntityHandler
 	// CreateSnapshotHandler sets the operation handler for the create snapshot operation
 	CreateSnapshotHandler CreateSnapshotHandler
+	// CreateTaskNexusHandler sets the operation handler for the create task nexus operation
+	CreateTaskNexusHandler CreateTaskNexusHandler
 	// DeleteProtectedEntityHandler sets the operation handler for the delete protected entity operation
 	DeleteProtectedEntityHandler DeleteProtectedEntityHandler
 	// GetProtectedEntityInfoHandler sets the operation handler for the get protected entity info operation
 	GetProtectedEntityInfoHandler GetProtectedEntityInfoHandler
+	// GetRetentionHandler sets the operation handler for the get retention operation
+	GetRetentionHandler GetRetentionHandler
 	// GetTaskInfoHandler sets the operation handler for the get task info operation
 	GetTaskInfoHandler GetTaskInfoHandler
+	// GetUsageHandler sets the operation handler for the get usage operation
+	GetUsageHandler GetUsageHandler
+	// ListAuditEventsHandler sets the operation handler for the list audit events operation
+	ListAuditEventsHandler ListAuditEventsHandler
+	// ListDeletedSnapshotsHandler sets the operation handler for the list deleted snapshots operation
+	ListDeletedSnapshotsHandler ListDeletedSnapshotsHandler
 	// ListProtectedEntitiesHandler sets the operation handler for the list protected entities operation
 	ListProtectedEntitiesHandler ListProtectedEntitiesHandler
 	// ListServicesHandler sets the operation handler for the list services operation
@@ -134,6 +171,16 @@ type AstrolabeAPI struct {
 	ListTaskNexusHandler ListTaskNexusHandler
 	// ListTasksHandler sets the operation handler for the list tasks operation
 	ListTasksHandler ListTasksHandler
+	// RehydrateSnapshotHandler sets the operation handler for the rehydrate snapshot operation
+	RehydrateSnapshotHandler RehydrateSnapshotHandler
+	// UndeleteSnapshotHandler sets the operation handler for the undelete snapshot operation
+	UndeleteSnapshotHandler UndeleteSnapshotHandler
+	// UpdateRetentionHandler sets the operation handler for the update retention operation
+	UpdateRetentionHandler UpdateRetentionHandler
+	// WaitOnTaskNexusHandler sets the operation handler for the wait on task nexus operation
+	WaitOnTaskNexusHandler WaitOnTaskNexusHandler
+	// WatchTaskEventsHandler sets the operation handler for the watch task events operation
+	WatchTaskEventsHandler WatchTaskEventsHandler
 	// ServeError is called when an error is received, there is a default handler
 	// but you can set your own with this
 	ServeError func(http.ResponseWriter, *http.Request, error)
@@ -199,28 +246,40 @@ func (o *AstrolabeAPI) Validate() error {
 	if o.JSONProducer == nil {
 		unregistered = append(unregistered, "JSONProducer")
 	}
-
-	if o.GetAstrolabeTasksNexusTaskNexusIDHandler == nil {
-		unregistered = append(unregistered, "GetAstrolabeTasksNexusTaskNexusIDHandler")
-	}
-	if o.PostAstrolabeTasksNexusHandler == nil {
-		unregistered = append(unregistered, "PostAstrolabeTasksNexusHandler")
+	if o.TextEventStreamProducer == nil {
+		unregistered = append(unregistered, "TextEventStreamProducer")
 	}
+
 	if o.CopyProtectedEntityHandler == nil {
 		unregistered = append(unregistered, "CopyProtectedEntityHandler")
 	}
 	if o.CreateSnapshotHandler == nil {
 		unregistered = append(unregistered, "CreateSnapshotHandler")
 	}
+	if o.CreateTaskNexusHandler == nil {
+		unregistered = append(unregistered, "CreateTaskNexusHandler")
+	}
 	if o.DeleteProtectedEntityHandler == nil {
 		unregistered = append(unregistered, "DeleteProtectedEntityHandler")
 	}
 	if o.GetProtectedEntityInfoHandler == nil {
 		unregistered = append(unregistered, "GetProtectedEntityInfoHandler")
 	}
+	if o.GetRetentionHandler == nil {
+		unregistered = append(unregistered, "GetRetentionHandler")
+	}
 	if o.GetTaskInfoHandler == nil {
 		unregistered = append(unregistered, "GetTaskInfoHandler")
 	}
+	if o.GetUsageHandler == nil {
+		unregistered = append(unregistered, "GetUsageHandler")
+	}
+	if o.ListAuditEventsHandler == nil {
+		unregistered = append(unregistered, "ListAuditEventsHandler")
+	}
+	if o.ListDeletedSnapshotsHandler == nil {
+		unregistered = append(unregistered, "ListDeletedSnapshotsHandler")
+	}
 	if o.ListProtectedEntitiesHandler == nil {
 		unregistered = append(unregistered, "ListProtectedEntitiesHandler")
 	}
@@ -236,6 +295,21 @@ func (o *AstrolabeAPI) Validate() error {
 	if o.ListTasksHandler == nil {
 		unregistered = append(unregistered, "ListTasksHandler")
 	}
+	if o.RehydrateSnapshotHandler == nil {
+		unregistered = append(unregistered, "RehydrateSnapshotHandler")
+	}
+	if o.UndeleteSnapshotHandler == nil {
+		unregistered = append(unregistered, "UndeleteSnapshotHandler")
+	}
+	if o.UpdateRetentionHandler == nil {
+		unregistered = append(unregistered, "UpdateRetentionHandler")
+	}
+	if o.WaitOnTaskNexusHandler == nil {
+		unregistered = append(unregistered, "WaitOnTaskNexusHandler")
+	}
+	if o.WatchTaskEventsHandler == nil {
+		unregistered = append(unregistered, "WatchTaskEventsHandler")
+	}
 
 	if len(unregistered) > 0 {
 		return fmt.Errorf("missing registration: %s", strings.Join(unregistered, ", "))
@@ -284,6 +358,8 @@ func (o *AstrolabeAPI) ProducersFor(mediaTypes []string) map[string]runtime.Prod
 		switch mt {
 		case "application/json":
 			result["application/json"] = o.JSONProducer
+		case "text/event-stream":
+			result["text/event-stream"] = o.TextEventStreamProducer
 		}
 
 		if p, ok := o.customProducers[mt]; ok {
@@ -324,22 +400,18 @@ func (o *AstrolabeAPI) initHandlerCache() {
 		o.handlers = make(map[string]map[string]http.Handler)
 	}
 
-	if o.handlers["GET"] == nil {
-		o.handlers["GET"] = make(map[string]http.Handler)
-	}
-	o.handlers["GET"]["/astrolabe/tasks/nexus/{taskNexusID}"] = NewGetAstrolabeTasksNexusTaskNexusID(o.context, o.GetAstrolabeTasksNexusTaskNexusIDHandler)
 	if o.handlers["POST"] == nil {
 		o.handlers["POST"] = make(map[string]http.Handler)
 	}
-	o.handlers["POST"]["/astrolabe/tasks/nexus"] = NewPostAstrolabeTasksNexus(o.context, o.PostAstrolabeTasksNexusHandler)
+	o.handlers["POST"]["/astrolabe/{service}"] = NewCopyProtectedEntity(o.context, o.CopyProtectedEntityHandler)
 	if o.handlers["POST"] == nil {
 		o.handlers["POST"] = make(map[string]http.Handler)
 	}
-	o.handlers["POST"]["/astrolabe/{service}"] = NewCopyProtectedEntity(o.context, o.CopyProtectedEntityHandler)
+	o.handlers["POST"]["/astrolabe/{service}/{protectedEntityID}/snapshots"] = NewCreateSnapshot(o.context, o.CreateSnapshotHandler)
 	if o.handlers["POST"] == nil {
 		o.handlers["POST"] = make(map[string]http.Handler)
 	}
-	o.handlers["POST"]["/astrolabe/{service}/{protectedEntityID}/snapshots"] = NewCreateSnapshot(o.context, o.CreateSnapshotHandler)
+	o.handlers["POST"]["/astrolabe/tasks/nexus"] = NewCreateTaskNexus(o.context, o.CreateTaskNexusHandler)
 	if o.handlers["DELETE"] == nil {
 		o.handlers["DELETE"] = make(map[string]http.Handler)
 	}
@@ -351,10 +423,26 @@ func (o *AstrolabeAPI) initHandlerCache() {
 	if o.handlers["GET"] == nil {
 		o.handlers["GET"] = make(map[string]http.Handler)
 	}
+	o.handlers["GET"]["/astrolabe/{service}/{protectedEntityID}/retention"] = NewGetRetention(o.context, o.GetRetentionHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
 	o.handlers["GET"]["/astrolabe/tasks/{taskID}"] = NewGetTaskInfo(o.context, o.GetTaskInfoHandler)
 	if o.handlers["GET"] == nil {
 		o.handlers["GET"] = make(map[string]http.Handler)
 	}
+	o.handlers["GET"]["/astrolabe/usage"] = NewGetUsage(o.context, o.GetUsageHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/astrolabe/audit"] = NewListAuditEvents(o.context, o.ListAuditEventsHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/astrolabe/{service}/{protectedEntityID}/deletedSnapshots"] = NewListDeletedSnapshots(o.context, o.ListDeletedSnapshotsHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
 	o.handlers["GET"]["/astrolabe/{service}"] = NewListProtectedEntities(o.context, o.ListProtectedEntitiesHandler)
 	if o.handlers["GET"] == nil {
 		o.handlers["GET"] = make(map[string]http.Handler)
@@ -372,6 +460,26 @@ func (o *AstrolabeAPI) initHandlerCache() {
 		o.handlers["GET"] = make(map[string]http.Handler)
 	}
 	o.handlers["GET"]["/astrolabe/tasks"] = NewListTasks(o.context, o.ListTasksHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/astrolabe/{service}/{protectedEntityID}/rehydrate"] = NewRehydrateSnapshot(o.context, o.RehydrateSnapshotHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/astrolabe/{service}/{protectedEntityID}/undelete"] = NewUndeleteSnapshot(o.context, o.UndeleteSnapshotHandler)
+	if o.handlers["PUT"] == nil {
+		o.handlers["PUT"] = make(map[string]http.Handler)
+	}
+	o.handlers["PUT"]["/astrolabe/{service}/{protectedEntityID}/retention"] = NewUpdateRetention(o.context, o.UpdateRetentionHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/astrolabe/tasks/nexus/{taskNexusID}"] = NewWaitOnTaskNexus(o.context, o.WaitOnTaskNexusHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/astrolabe/tasks/events"] = NewWatchTaskEvents(o.context, o.WatchTaskEventsHandler)
 }
 
 // Serve creates a http handler to serve the API over HTTP
@@ -34,6 +34,15 @@ type CopyProtectedEntityParams struct {
 	// HTTP Request Object
 	HTTPRequest *http.Request `json:"-"`
 
+	/*A key chosen by the client that identifies this request.  If a
+	request with the same key has already completed successfully within
+	the server's idempotency window, its result is returned instead of
+	running the operation again.  Keys are scoped to the caller and
+	cannot be reused for a different request.
+
+	  In: header
+	*/
+	IdempotencyKey *string
 	/*Copy Parameters including protected entity to copy
 	  Required: true
 	  In: body
@@ -71,6 +80,10 @@ func (o *CopyProtectedEntityParams) BindRequest(r *http.Request, route *middlewa
 
 	qs := runtime.Values(r.URL.Query())
 
+	if err := o.bindIdempotencyKey(r.Header[http.CanonicalHeaderKey("Idempotency-Key")], true, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
 	if runtime.HasBody(r) {
 		defer r.Body.Close()
 		var body models.CopyParameters
@@ -109,6 +122,24 @@ func (o *CopyProtectedEntityParams) BindRequest(r *http.Request, route *middlewa
 	return nil
 }
 
+// bindIdempotencyKey binds and validates parameter IdempotencyKey from header.
+func (o *CopyProtectedEntityParams) bindIdempotencyKey(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.IdempotencyKey = &raw
+
+	return nil
+}
+
 // bindMode binds and validates parameter Mode from query.
 func (o *CopyProtectedEntityParams) bindMode(rawData []string, hasKey bool, formats strfmt.Registry) error {
 	if !hasKey {
@@ -56,3 +56,91 @@ func (o *CopyProtectedEntityAccepted) WriteResponse(rw http.ResponseWriter, prod
 		}
 	}
 }
+
+// CopyProtectedEntityBadRequestCode is the HTTP code returned for type CopyProtectedEntityBadRequest
+const CopyProtectedEntityBadRequestCode int = 400
+
+/*CopyProtectedEntityBadRequest Invalid copy parameters or protected entity info
+
+swagger:response copyProtectedEntityBadRequest
+*/
+type CopyProtectedEntityBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewCopyProtectedEntityBadRequest creates CopyProtectedEntityBadRequest with default headers values
+func NewCopyProtectedEntityBadRequest() *CopyProtectedEntityBadRequest {
+
+	return &CopyProtectedEntityBadRequest{}
+}
+
+// WithPayload adds the payload to the copy protected entity bad request response
+func (o *CopyProtectedEntityBadRequest) WithPayload(payload *models.Error) *CopyProtectedEntityBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the copy protected entity bad request response
+func (o *CopyProtectedEntityBadRequest) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *CopyProtectedEntityBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// CopyProtectedEntityNotFoundCode is the HTTP code returned for type CopyProtectedEntityNotFound
+const CopyProtectedEntityNotFoundCode int = 404
+
+/*CopyProtectedEntityNotFound Service not found
+
+swagger:response copyProtectedEntityNotFound
+*/
+type CopyProtectedEntityNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewCopyProtectedEntityNotFound creates CopyProtectedEntityNotFound with default headers values
+func NewCopyProtectedEntityNotFound() *CopyProtectedEntityNotFound {
+
+	return &CopyProtectedEntityNotFound{}
+}
+
+// WithPayload adds the payload to the copy protected entity not found response
+func (o *CopyProtectedEntityNotFound) WithPayload(payload *models.Error) *CopyProtectedEntityNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the copy protected entity not found response
+func (o *CopyProtectedEntityNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *CopyProtectedEntityNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
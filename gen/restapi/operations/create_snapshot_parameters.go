@@ -32,6 +32,15 @@ type CreateSnapshotParams struct {
 	// HTTP Request Object
 	HTTPRequest *http.Request `json:"-"`
 
+	/*A key chosen by the client that identifies this request.  If a
+	request with the same key has already completed successfully within
+	the server's idempotency window, its result is returned instead of
+	running the operation again.  Keys are scoped to the caller and
+	cannot be reused for a different request.
+
+	  In: header
+	*/
+	IdempotencyKey *string
 	/*Parameters for the snapshot.
 	  In: body
 	*/
@@ -57,6 +66,10 @@ func (o *CreateSnapshotParams) BindRequest(r *http.Request, route *middleware.Ma
 
 	o.HTTPRequest = r
 
+	if err := o.bindIdempotencyKey(r.Header[http.CanonicalHeaderKey("Idempotency-Key")], true, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
 	if runtime.HasBody(r) {
 		defer r.Body.Close()
 		var body models.OperationParamList
@@ -89,6 +102,24 @@ func (o *CreateSnapshotParams) BindRequest(r *http.Request, route *middleware.Ma
 	return nil
 }
 
+// bindIdempotencyKey binds and validates parameter IdempotencyKey from header.
+func (o *CreateSnapshotParams) bindIdempotencyKey(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.IdempotencyKey = &raw
+
+	return nil
+}
+
 // bindProtectedEntityID binds and validates parameter ProtectedEntityID from path.
 func (o *CreateSnapshotParams) bindProtectedEntityID(rawData []string, hasKey bool, formats strfmt.Registry) error {
 	var raw string
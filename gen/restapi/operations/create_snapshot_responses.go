@@ -54,3 +54,179 @@ func (o *CreateSnapshotOK) WriteResponse(rw http.ResponseWriter, producer runtim
 		panic(err) // let the recovery middleware deal with this
 	}
 }
+
+// CreateSnapshotBadRequestCode is the HTTP code returned for type CreateSnapshotBadRequest
+const CreateSnapshotBadRequestCode int = 400
+
+/*CreateSnapshotBadRequest Invalid protected entity ID
+
+swagger:response createSnapshotBadRequest
+*/
+type CreateSnapshotBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewCreateSnapshotBadRequest creates CreateSnapshotBadRequest with default headers values
+func NewCreateSnapshotBadRequest() *CreateSnapshotBadRequest {
+
+	return &CreateSnapshotBadRequest{}
+}
+
+// WithPayload adds the payload to the create snapshot bad request response
+func (o *CreateSnapshotBadRequest) WithPayload(payload *models.Error) *CreateSnapshotBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the create snapshot bad request response
+func (o *CreateSnapshotBadRequest) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *CreateSnapshotBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// CreateSnapshotNotFoundCode is the HTTP code returned for type CreateSnapshotNotFound
+const CreateSnapshotNotFoundCode int = 404
+
+/*CreateSnapshotNotFound Service or Protected Entity not found
+
+swagger:response createSnapshotNotFound
+*/
+type CreateSnapshotNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewCreateSnapshotNotFound creates CreateSnapshotNotFound with default headers values
+func NewCreateSnapshotNotFound() *CreateSnapshotNotFound {
+
+	return &CreateSnapshotNotFound{}
+}
+
+// WithPayload adds the payload to the create snapshot not found response
+func (o *CreateSnapshotNotFound) WithPayload(payload *models.Error) *CreateSnapshotNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the create snapshot not found response
+func (o *CreateSnapshotNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *CreateSnapshotNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// CreateSnapshotConflictCode is the HTTP code returned for type CreateSnapshotConflict
+const CreateSnapshotConflictCode int = 409
+
+/*CreateSnapshotConflict Another operation on the protected entity holds its lock
+
+swagger:response createSnapshotConflict
+*/
+type CreateSnapshotConflict struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewCreateSnapshotConflict creates CreateSnapshotConflict with default headers values
+func NewCreateSnapshotConflict() *CreateSnapshotConflict {
+
+	return &CreateSnapshotConflict{}
+}
+
+// WithPayload adds the payload to the create snapshot conflict response
+func (o *CreateSnapshotConflict) WithPayload(payload *models.Error) *CreateSnapshotConflict {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the create snapshot conflict response
+func (o *CreateSnapshotConflict) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *CreateSnapshotConflict) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(409)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// CreateSnapshotInternalServerErrorCode is the HTTP code returned for type CreateSnapshotInternalServerError
+const CreateSnapshotInternalServerErrorCode int = 500
+
+/*CreateSnapshotInternalServerError Snapshot failed
+
+swagger:response createSnapshotInternalServerError
+*/
+type CreateSnapshotInternalServerError struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewCreateSnapshotInternalServerError creates CreateSnapshotInternalServerError with default headers values
+func NewCreateSnapshotInternalServerError() *CreateSnapshotInternalServerError {
+
+	return &CreateSnapshotInternalServerError{}
+}
+
+// WithPayload adds the payload to the create snapshot internal server error response
+func (o *CreateSnapshotInternalServerError) WithPayload(payload *models.Error) *CreateSnapshotInternalServerError {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the create snapshot internal server error response
+func (o *CreateSnapshotInternalServerError) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *CreateSnapshotInternalServerError) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(500)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
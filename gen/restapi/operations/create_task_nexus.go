@@ -0,0 +1,58 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+)
+
+// CreateTaskNexusHandlerFunc turns a function with the right signature into a create task nexus handler
+type CreateTaskNexusHandlerFunc func(CreateTaskNexusParams) middleware.Responder
+
+// Handle executing the request and returning a response
+func (fn CreateTaskNexusHandlerFunc) Handle(params CreateTaskNexusParams) middleware.Responder {
+	return fn(params)
+}
+
+// CreateTaskNexusHandler interface for that can handle valid create task nexus params
+type CreateTaskNexusHandler interface {
+	Handle(CreateTaskNexusParams) middleware.Responder
+}
+
+// NewCreateTaskNexus creates a new http.Handler for the create task nexus operation
+func NewCreateTaskNexus(ctx *middleware.Context, handler CreateTaskNexusHandler) *CreateTaskNexus {
+	return &CreateTaskNexus{Context: ctx, Handler: handler}
+}
+
+/*CreateTaskNexus swagger:route POST /astrolabe/tasks/nexus createTaskNexus
+
+Creates a new nexus for monitoring task completion
+
+*/
+type CreateTaskNexus struct {
+	Context *middleware.Context
+	Handler CreateTaskNexusHandler
+}
+
+func (o *CreateTaskNexus) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	route, rCtx, _ := o.Context.RouteInfo(r)
+	if rCtx != nil {
+		r = rCtx
+	}
+	var Params = NewCreateTaskNexusParams()
+
+	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+
+	res := o.Handler.Handle(Params) // actually handle the request
+
+	o.Context.Respond(rw, r, route.Produces, route, res)
+
+}
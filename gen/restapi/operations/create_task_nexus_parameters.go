@@ -12,18 +12,18 @@ import (
 	"github.com/go-openapi/runtime/middleware"
 )
 
-// NewPostAstrolabeTasksNexusParams creates a new PostAstrolabeTasksNexusParams object
+// NewCreateTaskNexusParams creates a new CreateTaskNexusParams object
 // no default values defined in spec.
-func NewPostAstrolabeTasksNexusParams() PostAstrolabeTasksNexusParams {
+func NewCreateTaskNexusParams() CreateTaskNexusParams {
 
-	return PostAstrolabeTasksNexusParams{}
+	return CreateTaskNexusParams{}
 }
 
-// PostAstrolabeTasksNexusParams contains all the bound params for the post astrolabe tasks nexus operation
+// CreateTaskNexusParams contains all the bound params for the create task nexus operation
 // typically these are obtained from a http.Request
 //
-// swagger:parameters PostAstrolabeTasksNexus
-type PostAstrolabeTasksNexusParams struct {
+// swagger:parameters createTaskNexus
+type CreateTaskNexusParams struct {
 
 	// HTTP Request Object
 	HTTPRequest *http.Request `json:"-"`
@@ -32,8 +32,8 @@ type PostAstrolabeTasksNexusParams struct {
 // BindRequest both binds and validates a request, it assumes that complex things implement a Validatable(strfmt.Registry) error interface
 // for simple values it will use straight method calls.
 //
-// To ensure default values, the struct must have been initialized with NewPostAstrolabeTasksNexusParams() beforehand.
-func (o *PostAstrolabeTasksNexusParams) BindRequest(r *http.Request, route *middleware.MatchedRoute) error {
+// To ensure default values, the struct must have been initialized with NewCreateTaskNexusParams() beforehand.
+func (o *CreateTaskNexusParams) BindRequest(r *http.Request, route *middleware.MatchedRoute) error {
 	var res []error
 
 	o.HTTPRequest = r
@@ -0,0 +1,56 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// CreateTaskNexusOKCode is the HTTP code returned for type CreateTaskNexusOK
+const CreateTaskNexusOKCode int = 200
+
+/*CreateTaskNexusOK New task nexus
+
+swagger:response createTaskNexusOK
+*/
+type CreateTaskNexusOK struct {
+
+	/*
+	  In: Body
+	*/
+	Payload models.TaskNexusID `json:"body,omitempty"`
+}
+
+// NewCreateTaskNexusOK creates CreateTaskNexusOK with default headers values
+func NewCreateTaskNexusOK() *CreateTaskNexusOK {
+
+	return &CreateTaskNexusOK{}
+}
+
+// WithPayload adds the payload to the create task nexus o k response
+func (o *CreateTaskNexusOK) WithPayload(payload models.TaskNexusID) *CreateTaskNexusOK {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the create task nexus o k response
+func (o *CreateTaskNexusOK) SetPayload(payload models.TaskNexusID) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *CreateTaskNexusOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(200)
+	payload := o.Payload
+	if err := producer.Produce(rw, payload); err != nil {
+		panic(err) // let the recovery middleware deal with this
+	}
+}
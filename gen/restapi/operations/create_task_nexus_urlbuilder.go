@@ -11,15 +11,15 @@ import (
 	golangswaggerpaths "path"
 )
 
-// PostAstrolabeTasksNexusURL generates an URL for the post astrolabe tasks nexus operation
-type PostAstrolabeTasksNexusURL struct {
+// CreateTaskNexusURL generates an URL for the create task nexus operation
+type CreateTaskNexusURL struct {
 	_basePath string
 }
 
 // WithBasePath sets the base path for this url builder, only required when it's different from the
 // base path specified in the swagger spec.
 // When the value of the base path is an empty string
-func (o *PostAstrolabeTasksNexusURL) WithBasePath(bp string) *PostAstrolabeTasksNexusURL {
+func (o *CreateTaskNexusURL) WithBasePath(bp string) *CreateTaskNexusURL {
 	o.SetBasePath(bp)
 	return o
 }
@@ -27,12 +27,12 @@ func (o *PostAstrolabeTasksNexusURL) WithBasePath(bp string) *PostAstrolabeTasks
 // SetBasePath sets the base path for this url builder, only required when it's different from the
 // base path specified in the swagger spec.
 // When the value of the base path is an empty string
-func (o *PostAstrolabeTasksNexusURL) SetBasePath(bp string) {
+func (o *CreateTaskNexusURL) SetBasePath(bp string) {
 	o._basePath = bp
 }
 
 // Build a url path and query string
-func (o *PostAstrolabeTasksNexusURL) Build() (*url.URL, error) {
+func (o *CreateTaskNexusURL) Build() (*url.URL, error) {
 	var _result url.URL
 
 	var _path = "/astrolabe/tasks/nexus"
@@ -47,7 +47,7 @@ func (o *PostAstrolabeTasksNexusURL) Build() (*url.URL, error) {
 }
 
 // Must is a helper function to panic when the url builder returns an error
-func (o *PostAstrolabeTasksNexusURL) Must(u *url.URL, err error) *url.URL {
+func (o *CreateTaskNexusURL) Must(u *url.URL, err error) *url.URL {
 	if err != nil {
 		panic(err)
 	}
@@ -58,17 +58,17 @@ func (o *PostAstrolabeTasksNexusURL) Must(u *url.URL, err error) *url.URL {
 }
 
 // String returns the string representation of the path with query string
-func (o *PostAstrolabeTasksNexusURL) String() string {
+func (o *CreateTaskNexusURL) String() string {
 	return o.Must(o.Build()).String()
 }
 
 // BuildFull builds a full url with scheme, host, path and query string
-func (o *PostAstrolabeTasksNexusURL) BuildFull(scheme, host string) (*url.URL, error) {
+func (o *CreateTaskNexusURL) BuildFull(scheme, host string) (*url.URL, error) {
 	if scheme == "" {
-		return nil, errors.New("scheme is required for a full url on PostAstrolabeTasksNexusURL")
+		return nil, errors.New("scheme is required for a full url on CreateTaskNexusURL")
 	}
 	if host == "" {
-		return nil, errors.New("host is required for a full url on PostAstrolabeTasksNexusURL")
+		return nil, errors.New("host is required for a full url on CreateTaskNexusURL")
 	}
 
 	base, err := o.Build()
@@ -82,6 +82,6 @@ func (o *PostAstrolabeTasksNexusURL) BuildFull(scheme, host string) (*url.URL, e
 }
 
 // StringFull returns the string representation of a complete url
-func (o *PostAstrolabeTasksNexusURL) StringFull(scheme, host string) string {
+func (o *CreateTaskNexusURL) StringFull(scheme, host string) string {
 	return o.Must(o.BuildFull(scheme, host)).String()
 }
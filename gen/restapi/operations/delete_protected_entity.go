@@ -32,7 +32,8 @@ func NewDeleteProtectedEntity(ctx *middleware.Context, handler DeleteProtectedEn
 /*DeleteProtectedEntity swagger:route DELETE /astrolabe/{service}/{protectedEntityID} deleteProtectedEntity
 
 Deletes a protected entity or snapshot of a protected entity (if the
-snapshot ID is specified)
+snapshot ID is specified).  Services that support soft delete keep
+deleted snapshots for an undelete window, see undeleteSnapshot
 
 
 */
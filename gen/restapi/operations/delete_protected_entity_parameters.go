@@ -29,6 +29,15 @@ type DeleteProtectedEntityParams struct {
 	// HTTP Request Object
 	HTTPRequest *http.Request `json:"-"`
 
+	/*A key chosen by the client that identifies this request.  If a
+	request with the same key has already completed successfully within
+	the server's idempotency window, its result is returned instead of
+	running the operation again.  Keys are scoped to the caller and
+	cannot be reused for a different request.
+
+	  In: header
+	*/
+	IdempotencyKey *string
 	/*The protected entity ID to retrieve info for
 	  Required: true
 	  In: path
@@ -50,6 +59,10 @@ func (o *DeleteProtectedEntityParams) BindRequest(r *http.Request, route *middle
 
 	o.HTTPRequest = r
 
+	if err := o.bindIdempotencyKey(r.Header[http.CanonicalHeaderKey("Idempotency-Key")], true, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
 	rProtectedEntityID, rhkProtectedEntityID, _ := route.Params.GetOK("protectedEntityID")
 	if err := o.bindProtectedEntityID(rProtectedEntityID, rhkProtectedEntityID, route.Formats); err != nil {
 		res = append(res, err)
@@ -66,6 +79,24 @@ func (o *DeleteProtectedEntityParams) BindRequest(r *http.Request, route *middle
 	return nil
 }
 
+// bindIdempotencyKey binds and validates parameter IdempotencyKey from header.
+func (o *DeleteProtectedEntityParams) bindIdempotencyKey(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.IdempotencyKey = &raw
+
+	return nil
+}
+
 // bindProtectedEntityID binds and validates parameter ProtectedEntityID from path.
 func (o *DeleteProtectedEntityParams) bindProtectedEntityID(rawData []string, hasKey bool, formats strfmt.Registry) error {
 	var raw string
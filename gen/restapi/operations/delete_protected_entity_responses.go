@@ -54,3 +54,179 @@ func (o *DeleteProtectedEntityOK) WriteResponse(rw http.ResponseWriter, producer
 		panic(err) // let the recovery middleware deal with this
 	}
 }
+
+// DeleteProtectedEntityBadRequestCode is the HTTP code returned for type DeleteProtectedEntityBadRequest
+const DeleteProtectedEntityBadRequestCode int = 400
+
+/*DeleteProtectedEntityBadRequest Invalid protected entity ID or the ID does not have a snapshot ID
+
+swagger:response deleteProtectedEntityBadRequest
+*/
+type DeleteProtectedEntityBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewDeleteProtectedEntityBadRequest creates DeleteProtectedEntityBadRequest with default headers values
+func NewDeleteProtectedEntityBadRequest() *DeleteProtectedEntityBadRequest {
+
+	return &DeleteProtectedEntityBadRequest{}
+}
+
+// WithPayload adds the payload to the delete protected entity bad request response
+func (o *DeleteProtectedEntityBadRequest) WithPayload(payload *models.Error) *DeleteProtectedEntityBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the delete protected entity bad request response
+func (o *DeleteProtectedEntityBadRequest) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *DeleteProtectedEntityBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// DeleteProtectedEntityNotFoundCode is the HTTP code returned for type DeleteProtectedEntityNotFound
+const DeleteProtectedEntityNotFoundCode int = 404
+
+/*DeleteProtectedEntityNotFound Service or Protected Entity not found
+
+swagger:response deleteProtectedEntityNotFound
+*/
+type DeleteProtectedEntityNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewDeleteProtectedEntityNotFound creates DeleteProtectedEntityNotFound with default headers values
+func NewDeleteProtectedEntityNotFound() *DeleteProtectedEntityNotFound {
+
+	return &DeleteProtectedEntityNotFound{}
+}
+
+// WithPayload adds the payload to the delete protected entity not found response
+func (o *DeleteProtectedEntityNotFound) WithPayload(payload *models.Error) *DeleteProtectedEntityNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the delete protected entity not found response
+func (o *DeleteProtectedEntityNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *DeleteProtectedEntityNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// DeleteProtectedEntityConflictCode is the HTTP code returned for type DeleteProtectedEntityConflict
+const DeleteProtectedEntityConflictCode int = 409
+
+/*DeleteProtectedEntityConflict The snapshot is retained and cannot be deleted or another operation on the protected entity holds its lock
+
+swagger:response deleteProtectedEntityConflict
+*/
+type DeleteProtectedEntityConflict struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewDeleteProtectedEntityConflict creates DeleteProtectedEntityConflict with default headers values
+func NewDeleteProtectedEntityConflict() *DeleteProtectedEntityConflict {
+
+	return &DeleteProtectedEntityConflict{}
+}
+
+// WithPayload adds the payload to the delete protected entity conflict response
+func (o *DeleteProtectedEntityConflict) WithPayload(payload *models.Error) *DeleteProtectedEntityConflict {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the delete protected entity conflict response
+func (o *DeleteProtectedEntityConflict) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *DeleteProtectedEntityConflict) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(409)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// DeleteProtectedEntityInternalServerErrorCode is the HTTP code returned for type DeleteProtectedEntityInternalServerError
+const DeleteProtectedEntityInternalServerErrorCode int = 500
+
+/*DeleteProtectedEntityInternalServerError Delete failed
+
+swagger:response deleteProtectedEntityInternalServerError
+*/
+type DeleteProtectedEntityInternalServerError struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewDeleteProtectedEntityInternalServerError creates DeleteProtectedEntityInternalServerError with default headers values
+func NewDeleteProtectedEntityInternalServerError() *DeleteProtectedEntityInternalServerError {
+
+	return &DeleteProtectedEntityInternalServerError{}
+}
+
+// WithPayload adds the payload to the delete protected entity internal server error response
+func (o *DeleteProtectedEntityInternalServerError) WithPayload(payload *models.Error) *DeleteProtectedEntityInternalServerError {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the delete protected entity internal server error response
+func (o *DeleteProtectedEntityInternalServerError) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *DeleteProtectedEntityInternalServerError) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(500)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
@@ -1,58 +0,0 @@
-// Code generated by go-swagger; DO NOT EDIT.
-
-package operations
-
-// This file was generated by the swagger tool.
-// Editing this file might prove futile when you re-run the generate command
-
-import (
-	"net/http"
-
-	"github.com/go-openapi/runtime/middleware"
-)
-
-// GetAstrolabeTasksNexusTaskNexusIDHandlerFunc turns a function with the right signature into a get astrolabe tasks nexus task nexus ID handler
-type GetAstrolabeTasksNexusTaskNexusIDHandlerFunc func(GetAstrolabeTasksNexusTaskNexusIDParams) middleware.Responder
-
-// Handle executing the request and returning a response
-func (fn GetAstrolabeTasksNexusTaskNexusIDHandlerFunc) Handle(params GetAstrolabeTasksNexusTaskNexusIDParams) middleware.Responder {
-	return fn(params)
-}
-
-// GetAstrolabeTasksNexusTaskNexusIDHandler interface for that can handle valid get astrolabe tasks nexus task nexus ID params
-type GetAstrolabeTasksNexusTaskNexusIDHandler interface {
-	Handle(GetAstrolabeTasksNexusTaskNexusIDParams) middleware.Responder
-}
-
-// NewGetAstrolabeTasksNexusTaskNexusID creates a new http.Handler for the get astrolabe tasks nexus task nexus ID operation
-func NewGetAstrolabeTasksNexusTaskNexusID(ctx *middleware.Context, handler GetAstrolabeTasksNexusTaskNexusIDHandler) *GetAstrolabeTasksNexusTaskNexusID {
-	return &GetAstrolabeTasksNexusTaskNexusID{Context: ctx, Handler: handler}
-}
-
-/*GetAstrolabeTasksNexusTaskNexusID swagger:route GET /astrolabe/tasks/nexus/{taskNexusID} getAstrolabeTasksNexusTaskNexusId
-
-GetAstrolabeTasksNexusTaskNexusID get astrolabe tasks nexus task nexus ID API
-
-*/
-type GetAstrolabeTasksNexusTaskNexusID struct {
-	Context *middleware.Context
-	Handler GetAstrolabeTasksNexusTaskNexusIDHandler
-}
-
-func (o *GetAstrolabeTasksNexusTaskNexusID) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
-	route, rCtx, _ := o.Context.RouteInfo(r)
-	if rCtx != nil {
-		r = rCtx
-	}
-	var Params = NewGetAstrolabeTasksNexusTaskNexusIDParams()
-
-	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
-		o.Context.Respond(rw, r, route.Produces, route, err)
-		return
-	}
-
-	res := o.Handler.Handle(Params) // actually handle the request
-
-	o.Context.Respond(rw, r, route.Produces, route, res)
-
-}
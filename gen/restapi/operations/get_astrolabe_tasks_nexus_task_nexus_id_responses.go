@@ -1,58 +0,0 @@
-// Code generated by go-swagger; DO NOT EDIT.
-
-package operations
-
-// This file was generated by the swagger tool.
-// Editing this file might prove futile when you re-run the swagger generate command
-
-import (
-	"net/http"
-
-	"github.com/go-openapi/runtime"
-
-	"github.com/vmware-tanzu/astrolabe/gen/models"
-)
-
-// GetAstrolabeTasksNexusTaskNexusIDOKCode is the HTTP code returned for type GetAstrolabeTasksNexusTaskNexusIDOK
-const GetAstrolabeTasksNexusTaskNexusIDOKCode int = 200
-
-/*GetAstrolabeTasksNexusTaskNexusIDOK 200 response
-
-swagger:response getAstrolabeTasksNexusTaskNexusIdOK
-*/
-type GetAstrolabeTasksNexusTaskNexusIDOK struct {
-
-	/*
-	  In: Body
-	*/
-	Payload *models.TaskNexusResponse `json:"body,omitempty"`
-}
-
-// NewGetAstrolabeTasksNexusTaskNexusIDOK creates GetAstrolabeTasksNexusTaskNexusIDOK with default headers values
-func NewGetAstrolabeTasksNexusTaskNexusIDOK() *GetAstrolabeTasksNexusTaskNexusIDOK {
-
-	return &GetAstrolabeTasksNexusTaskNexusIDOK{}
-}
-
-// WithPayload adds the payload to the get astrolabe tasks nexus task nexus Id o k response
-func (o *GetAstrolabeTasksNexusTaskNexusIDOK) WithPayload(payload *models.TaskNexusResponse) *GetAstrolabeTasksNexusTaskNexusIDOK {
-	o.Payload = payload
-	return o
-}
-
-// SetPayload sets the payload to the get astrolabe tasks nexus task nexus Id o k response
-func (o *GetAstrolabeTasksNexusTaskNexusIDOK) SetPayload(payload *models.TaskNexusResponse) {
-	o.Payload = payload
-}
-
-// WriteResponse to the client
-func (o *GetAstrolabeTasksNexusTaskNexusIDOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
-
-	rw.WriteHeader(200)
-	if o.Payload != nil {
-		payload := o.Payload
-		if err := producer.Produce(rw, payload); err != nil {
-			panic(err) // let the recovery middleware deal with this
-		}
-	}
-}
@@ -56,3 +56,135 @@ func (o *GetProtectedEntityInfoOK) WriteResponse(rw http.ResponseWriter, produce
 		}
 	}
 }
+
+// GetProtectedEntityInfoBadRequestCode is the HTTP code returned for type GetProtectedEntityInfoBadRequest
+const GetProtectedEntityInfoBadRequestCode int = 400
+
+/*GetProtectedEntityInfoBadRequest Invalid protected entity ID
+
+swagger:response getProtectedEntityInfoBadRequest
+*/
+type GetProtectedEntityInfoBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewGetProtectedEntityInfoBadRequest creates GetProtectedEntityInfoBadRequest with default headers values
+func NewGetProtectedEntityInfoBadRequest() *GetProtectedEntityInfoBadRequest {
+
+	return &GetProtectedEntityInfoBadRequest{}
+}
+
+// WithPayload adds the payload to the get protected entity info bad request response
+func (o *GetProtectedEntityInfoBadRequest) WithPayload(payload *models.Error) *GetProtectedEntityInfoBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get protected entity info bad request response
+func (o *GetProtectedEntityInfoBadRequest) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetProtectedEntityInfoBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// GetProtectedEntityInfoNotFoundCode is the HTTP code returned for type GetProtectedEntityInfoNotFound
+const GetProtectedEntityInfoNotFoundCode int = 404
+
+/*GetProtectedEntityInfoNotFound Service or Protected Entity not found
+
+swagger:response getProtectedEntityInfoNotFound
+*/
+type GetProtectedEntityInfoNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewGetProtectedEntityInfoNotFound creates GetProtectedEntityInfoNotFound with default headers values
+func NewGetProtectedEntityInfoNotFound() *GetProtectedEntityInfoNotFound {
+
+	return &GetProtectedEntityInfoNotFound{}
+}
+
+// WithPayload adds the payload to the get protected entity info not found response
+func (o *GetProtectedEntityInfoNotFound) WithPayload(payload *models.Error) *GetProtectedEntityInfoNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get protected entity info not found response
+func (o *GetProtectedEntityInfoNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetProtectedEntityInfoNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// GetProtectedEntityInfoInternalServerErrorCode is the HTTP code returned for type GetProtectedEntityInfoInternalServerError
+const GetProtectedEntityInfoInternalServerErrorCode int = 500
+
+/*GetProtectedEntityInfoInternalServerError Failed to retrieve the protected entity info
+
+swagger:response getProtectedEntityInfoInternalServerError
+*/
+type GetProtectedEntityInfoInternalServerError struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewGetProtectedEntityInfoInternalServerError creates GetProtectedEntityInfoInternalServerError with default headers values
+func NewGetProtectedEntityInfoInternalServerError() *GetProtectedEntityInfoInternalServerError {
+
+	return &GetProtectedEntityInfoInternalServerError{}
+}
+
+// WithPayload adds the payload to the get protected entity info internal server error response
+func (o *GetProtectedEntityInfoInternalServerError) WithPayload(payload *models.Error) *GetProtectedEntityInfoInternalServerError {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get protected entity info internal server error response
+func (o *GetProtectedEntityInfoInternalServerError) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetProtectedEntityInfoInternalServerError) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(500)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// GetRetentionOKCode is the HTTP code returned for type GetRetentionOK
+const GetRetentionOKCode int = 200
+
+/*GetRetentionOK 200 response
+
+swagger:response getRetentionOK
+*/
+type GetRetentionOK struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Retention `json:"body,omitempty"`
+}
+
+// NewGetRetentionOK creates GetRetentionOK with default headers values
+func NewGetRetentionOK() *GetRetentionOK {
+
+	return &GetRetentionOK{}
+}
+
+// WithPayload adds the payload to the get retention o k response
+func (o *GetRetentionOK) WithPayload(payload *models.Retention) *GetRetentionOK {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get retention o k response
+func (o *GetRetentionOK) SetPayload(payload *models.Retention) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetRetentionOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(200)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// GetRetentionBadRequestCode is the HTTP code returned for type GetRetentionBadRequest
+const GetRetentionBadRequestCode int = 400
+
+/*GetRetentionBadRequest Invalid protected entity ID
+
+swagger:response getRetentionBadRequest
+*/
+type GetRetentionBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewGetRetentionBadRequest creates GetRetentionBadRequest with default headers values
+func NewGetRetentionBadRequest() *GetRetentionBadRequest {
+
+	return &GetRetentionBadRequest{}
+}
+
+// WithPayload adds the payload to the get retention bad request response
+func (o *GetRetentionBadRequest) WithPayload(payload *models.Error) *GetRetentionBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get retention bad request response
+func (o *GetRetentionBadRequest) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetRetentionBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// GetRetentionNotFoundCode is the HTTP code returned for type GetRetentionNotFound
+const GetRetentionNotFoundCode int = 404
+
+/*GetRetentionNotFound Service or snapshot not found, or the service does not support retention
+
+swagger:response getRetentionNotFound
+*/
+type GetRetentionNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewGetRetentionNotFound creates GetRetentionNotFound with default headers values
+func NewGetRetentionNotFound() *GetRetentionNotFound {
+
+	return &GetRetentionNotFound{}
+}
+
+// WithPayload adds the payload to the get retention not found response
+func (o *GetRetentionNotFound) WithPayload(payload *models.Error) *GetRetentionNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get retention not found response
+func (o *GetRetentionNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetRetentionNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// GetRetentionInternalServerErrorCode is the HTTP code returned for type GetRetentionInternalServerError
+const GetRetentionInternalServerErrorCode int = 500
+
+/*GetRetentionInternalServerError Failed to retrieve retention
+
+swagger:response getRetentionInternalServerError
+*/
+type GetRetentionInternalServerError struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewGetRetentionInternalServerError creates GetRetentionInternalServerError with default headers values
+func NewGetRetentionInternalServerError() *GetRetentionInternalServerError {
+
+	return &GetRetentionInternalServerError{}
+}
+
+// WithPayload adds the payload to the get retention internal server error response
+func (o *GetRetentionInternalServerError) WithPayload(payload *models.Error) *GetRetentionInternalServerError {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get retention internal server error response
+func (o *GetRetentionInternalServerError) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetRetentionInternalServerError) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(500)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
@@ -56,3 +56,47 @@ func (o *GetTaskInfoOK) WriteResponse(rw http.ResponseWriter, producer runtime.P
 		}
 	}
 }
+
+// GetTaskInfoNotFoundCode is the HTTP code returned for type GetTaskInfoNotFound
+const GetTaskInfoNotFoundCode int = 404
+
+/*GetTaskInfoNotFound Task not found
+
+swagger:response getTaskInfoNotFound
+*/
+type GetTaskInfoNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewGetTaskInfoNotFound creates GetTaskInfoNotFound with default headers values
+func NewGetTaskInfoNotFound() *GetTaskInfoNotFound {
+
+	return &GetTaskInfoNotFound{}
+}
+
+// WithPayload adds the payload to the get task info not found response
+func (o *GetTaskInfoNotFound) WithPayload(payload *models.Error) *GetTaskInfoNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get task info not found response
+func (o *GetTaskInfoNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetTaskInfoNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
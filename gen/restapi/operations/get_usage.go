@@ -0,0 +1,62 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+)
+
+// GetUsageHandlerFunc turns a function with the right signature into a get usage handler
+type GetUsageHandlerFunc func(GetUsageParams) middleware.Responder
+
+// Handle executing the request and returning a response
+func (fn GetUsageHandlerFunc) Handle(params GetUsageParams) middleware.Responder {
+	return fn(params)
+}
+
+// GetUsageHandler interface for that can handle valid get usage params
+type GetUsageHandler interface {
+	Handle(GetUsageParams) middleware.Responder
+}
+
+// NewGetUsage creates a new http.Handler for the get usage operation
+func NewGetUsage(ctx *middleware.Context, handler GetUsageHandler) *GetUsage {
+	return &GetUsage{Context: ctx, Handler: handler}
+}
+
+/*GetUsage swagger:route GET /astrolabe/usage getUsage
+
+Reports repository usage
+
+Reports logical and stored bytes per protected entity, per snapshot and per service for the services
+that store protected entities (repositories).  Services that do not store data are not included.
+
+
+*/
+type GetUsage struct {
+	Context *middleware.Context
+	Handler GetUsageHandler
+}
+
+func (o *GetUsage) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	route, rCtx, _ := o.Context.RouteInfo(r)
+	if rCtx != nil {
+		r = rCtx
+	}
+	var Params = NewGetUsageParams()
+
+	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+
+	res := o.Handler.Handle(Params) // actually handle the request
+
+	o.Context.Respond(rw, r, route.Produces, route, res)
+
+}
@@ -0,0 +1,104 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+)
+
+// NewGetUsageParams creates a new GetUsageParams object
+// no default values defined in spec.
+func NewGetUsageParams() GetUsageParams {
+
+	return GetUsageParams{}
+}
+
+// GetUsageParams contains all the bound params for the get usage operation
+// typically these are obtained from a http.Request
+//
+// swagger:parameters getUsage
+type GetUsageParams struct {
+
+	// HTTP Request Object
+	HTTPRequest *http.Request `json:"-"`
+
+	/*Only report usage for protected entities whose IDs start with this prefix
+	  In: query
+	*/
+	IDPrefix *string
+	/*Only report usage for this service
+	  In: query
+	*/
+	Service *string
+}
+
+// BindRequest both binds and validates a request, it assumes that complex things implement a Validatable(strfmt.Registry) error interface
+// for simple values it will use straight method calls.
+//
+// To ensure default values, the struct must have been initialized with NewGetUsageParams() beforehand.
+func (o *GetUsageParams) BindRequest(r *http.Request, route *middleware.MatchedRoute) error {
+	var res []error
+
+	o.HTTPRequest = r
+
+	qs := runtime.Values(r.URL.Query())
+
+	qIDPrefix, qhkIDPrefix, _ := qs.GetOK("idPrefix")
+	if err := o.bindIDPrefix(qIDPrefix, qhkIDPrefix, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qService, qhkService, _ := qs.GetOK("service")
+	if err := o.bindService(qService, qhkService, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+// bindIDPrefix binds and validates parameter IDPrefix from query.
+func (o *GetUsageParams) bindIDPrefix(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.IDPrefix = &raw
+
+	return nil
+}
+
+// bindService binds and validates parameter Service from query.
+func (o *GetUsageParams) bindService(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.Service = &raw
+
+	return nil
+}
@@ -0,0 +1,146 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// GetUsageOKCode is the HTTP code returned for type GetUsageOK
+const GetUsageOKCode int = 200
+
+/*GetUsageOK Usage for the services that store protected entities
+
+swagger:response getUsageOK
+*/
+type GetUsageOK struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.RepositoryUsage `json:"body,omitempty"`
+}
+
+// NewGetUsageOK creates GetUsageOK with default headers values
+func NewGetUsageOK() *GetUsageOK {
+
+	return &GetUsageOK{}
+}
+
+// WithPayload adds the payload to the get usage o k response
+func (o *GetUsageOK) WithPayload(payload *models.RepositoryUsage) *GetUsageOK {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get usage o k response
+func (o *GetUsageOK) SetPayload(payload *models.RepositoryUsage) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetUsageOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(200)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// GetUsageNotFoundCode is the HTTP code returned for type GetUsageNotFound
+const GetUsageNotFoundCode int = 404
+
+/*GetUsageNotFound Service not found or service does not report usage
+
+swagger:response getUsageNotFound
+*/
+type GetUsageNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewGetUsageNotFound creates GetUsageNotFound with default headers values
+func NewGetUsageNotFound() *GetUsageNotFound {
+
+	return &GetUsageNotFound{}
+}
+
+// WithPayload adds the payload to the get usage not found response
+func (o *GetUsageNotFound) WithPayload(payload *models.Error) *GetUsageNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get usage not found response
+func (o *GetUsageNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetUsageNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// GetUsageInternalServerErrorCode is the HTTP code returned for type GetUsageInternalServerError
+const GetUsageInternalServerErrorCode int = 500
+
+/*GetUsageInternalServerError Usage could not be retrieved
+
+swagger:response getUsageInternalServerError
+*/
+type GetUsageInternalServerError struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewGetUsageInternalServerError creates GetUsageInternalServerError with default headers values
+func NewGetUsageInternalServerError() *GetUsageInternalServerError {
+
+	return &GetUsageInternalServerError{}
+}
+
+// WithPayload adds the payload to the get usage internal server error response
+func (o *GetUsageInternalServerError) WithPayload(payload *models.Error) *GetUsageInternalServerError {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get usage internal server error response
+func (o *GetUsageInternalServerError) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetUsageInternalServerError) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(500)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+)
+
+// ListAuditEventsHandlerFunc turns a function with the right signature into a list audit events handler
+type ListAuditEventsHandlerFunc func(ListAuditEventsParams) middleware.Responder
+
+// Handle executing the request and returning a response
+func (fn ListAuditEventsHandlerFunc) Handle(params ListAuditEventsParams) middleware.Responder {
+	return fn(params)
+}
+
+// ListAuditEventsHandler interface for that can handle valid list audit events params
+type ListAuditEventsHandler interface {
+	Handle(ListAuditEventsParams) middleware.Responder
+}
+
+// NewListAuditEvents creates a new http.Handler for the list audit events operation
+func NewListAuditEvents(ctx *middleware.Context, handler ListAuditEventsHandler) *ListAuditEvents {
+	return &ListAuditEvents{Context: ctx, Handler: handler}
+}
+
+/*ListAuditEvents swagger:route GET /astrolabe/audit listAuditEvents
+
+Lists audit events
+
+Returns the events recorded in the audit log for mutating calls (snapshot, copy, delete, retention,
+undelete and rehydrate) and for the tasks they started.
+
+
+*/
+type ListAuditEvents struct {
+	Context *middleware.Context
+	Handler ListAuditEventsHandler
+}
+
+func (o *ListAuditEvents) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	route, rCtx, _ := o.Context.RouteInfo(r)
+	if rCtx != nil {
+		r = rCtx
+	}
+	var Params = NewListAuditEventsParams()
+
+	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+
+	res := o.Handler.Handle(Params) // actually handle the request
+
+	o.Context.Respond(rw, r, route.Produces, route, res)
+
+}
@@ -0,0 +1,170 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+)
+
+// NewListAuditEventsParams creates a new ListAuditEventsParams object
+// no default values defined in spec.
+func NewListAuditEventsParams() ListAuditEventsParams {
+
+	return ListAuditEventsParams{}
+}
+
+// ListAuditEventsParams contains all the bound params for the list audit events operation
+// typically these are obtained from a http.Request
+//
+// swagger:parameters listAuditEvents
+type ListAuditEventsParams struct {
+
+	// HTTP Request Object
+	HTTPRequest *http.Request `json:"-"`
+
+	/*Only return events at or after this time
+	  In: query
+	*/
+	From *strfmt.DateTime
+	/*Only return events for this protected entity.  Events for snapshots of the protected entity are included
+	when the ID does not have a snapshot ID
+
+	  In: query
+	*/
+	ProtectedEntityID *string
+	/*Only return events before this time
+	  In: query
+	*/
+	To *strfmt.DateTime
+}
+
+// BindRequest both binds and validates a request, it assumes that complex things implement a Validatable(strfmt.Registry) error interface
+// for simple values it will use straight method calls.
+//
+// To ensure default values, the struct must have been initialized with NewListAuditEventsParams() beforehand.
+func (o *ListAuditEventsParams) BindRequest(r *http.Request, route *middleware.MatchedRoute) error {
+	var res []error
+
+	o.HTTPRequest = r
+
+	qs := runtime.Values(r.URL.Query())
+
+	qFrom, qhkFrom, _ := qs.GetOK("from")
+	if err := o.bindFrom(qFrom, qhkFrom, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qProtectedEntityID, qhkProtectedEntityID, _ := qs.GetOK("protectedEntityID")
+	if err := o.bindProtectedEntityID(qProtectedEntityID, qhkProtectedEntityID, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qTo, qhkTo, _ := qs.GetOK("to")
+	if err := o.bindTo(qTo, qhkTo, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+// bindFrom binds and validates parameter From from query.
+func (o *ListAuditEventsParams) bindFrom(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	// Format: date-time
+	value, err := formats.Parse("date-time", raw)
+	if err != nil {
+		return errors.InvalidType("from", "query", "strfmt.DateTime", raw)
+	}
+	o.From = (value.(*strfmt.DateTime))
+
+	if err := o.validateFrom(formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateFrom carries on validations for parameter From
+func (o *ListAuditEventsParams) validateFrom(formats strfmt.Registry) error {
+
+	if err := validate.FormatOf("from", "query", "date-time", o.From.String(), formats); err != nil {
+		return err
+	}
+	return nil
+}
+
+// bindProtectedEntityID binds and validates parameter ProtectedEntityID from query.
+func (o *ListAuditEventsParams) bindProtectedEntityID(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.ProtectedEntityID = &raw
+
+	return nil
+}
+
+// bindTo binds and validates parameter To from query.
+func (o *ListAuditEventsParams) bindTo(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	// Format: date-time
+	value, err := formats.Parse("date-time", raw)
+	if err != nil {
+		return errors.InvalidType("to", "query", "strfmt.DateTime", raw)
+	}
+	o.To = (value.(*strfmt.DateTime))
+
+	if err := o.validateTo(formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateTo carries on validations for parameter To
+func (o *ListAuditEventsParams) validateTo(formats strfmt.Registry) error {
+
+	if err := validate.FormatOf("to", "query", "date-time", o.To.String(), formats); err != nil {
+		return err
+	}
+	return nil
+}
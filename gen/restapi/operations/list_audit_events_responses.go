@@ -0,0 +1,190 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// ListAuditEventsOKCode is the HTTP code returned for type ListAuditEventsOK
+const ListAuditEventsOKCode int = 200
+
+/*ListAuditEventsOK The matching audit events, oldest first
+
+swagger:response listAuditEventsOK
+*/
+type ListAuditEventsOK struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.AuditEventList `json:"body,omitempty"`
+}
+
+// NewListAuditEventsOK creates ListAuditEventsOK with default headers values
+func NewListAuditEventsOK() *ListAuditEventsOK {
+
+	return &ListAuditEventsOK{}
+}
+
+// WithPayload adds the payload to the list audit events o k response
+func (o *ListAuditEventsOK) WithPayload(payload *models.AuditEventList) *ListAuditEventsOK {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list audit events o k response
+func (o *ListAuditEventsOK) SetPayload(payload *models.AuditEventList) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListAuditEventsOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(200)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// ListAuditEventsBadRequestCode is the HTTP code returned for type ListAuditEventsBadRequest
+const ListAuditEventsBadRequestCode int = 400
+
+/*ListAuditEventsBadRequest Invalid protected entity ID or time range
+
+swagger:response listAuditEventsBadRequest
+*/
+type ListAuditEventsBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewListAuditEventsBadRequest creates ListAuditEventsBadRequest with default headers values
+func NewListAuditEventsBadRequest() *ListAuditEventsBadRequest {
+
+	return &ListAuditEventsBadRequest{}
+}
+
+// WithPayload adds the payload to the list audit events bad request response
+func (o *ListAuditEventsBadRequest) WithPayload(payload *models.Error) *ListAuditEventsBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list audit events bad request response
+func (o *ListAuditEventsBadRequest) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListAuditEventsBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// ListAuditEventsNotFoundCode is the HTTP code returned for type ListAuditEventsNotFound
+const ListAuditEventsNotFoundCode int = 404
+
+/*ListAuditEventsNotFound The audit log is not enabled
+
+swagger:response listAuditEventsNotFound
+*/
+type ListAuditEventsNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewListAuditEventsNotFound creates ListAuditEventsNotFound with default headers values
+func NewListAuditEventsNotFound() *ListAuditEventsNotFound {
+
+	return &ListAuditEventsNotFound{}
+}
+
+// WithPayload adds the payload to the list audit events not found response
+func (o *ListAuditEventsNotFound) WithPayload(payload *models.Error) *ListAuditEventsNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list audit events not found response
+func (o *ListAuditEventsNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListAuditEventsNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// ListAuditEventsInternalServerErrorCode is the HTTP code returned for type ListAuditEventsInternalServerError
+const ListAuditEventsInternalServerErrorCode int = 500
+
+/*ListAuditEventsInternalServerError The audit log could not be read
+
+swagger:response listAuditEventsInternalServerError
+*/
+type ListAuditEventsInternalServerError struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewListAuditEventsInternalServerError creates ListAuditEventsInternalServerError with default headers values
+func NewListAuditEventsInternalServerError() *ListAuditEventsInternalServerError {
+
+	return &ListAuditEventsInternalServerError{}
+}
+
+// WithPayload adds the payload to the list audit events internal server error response
+func (o *ListAuditEventsInternalServerError) WithPayload(payload *models.Error) *ListAuditEventsInternalServerError {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list audit events internal server error response
+func (o *ListAuditEventsInternalServerError) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListAuditEventsInternalServerError) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(500)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
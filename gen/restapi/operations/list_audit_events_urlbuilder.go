@@ -0,0 +1,123 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the generate command
+
+import (
+	"errors"
+	"net/url"
+	golangswaggerpaths "path"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// ListAuditEventsURL generates an URL for the list audit events operation
+type ListAuditEventsURL struct {
+	From              *strfmt.DateTime
+	ProtectedEntityID *string
+	To                *strfmt.DateTime
+
+	_basePath string
+	// avoid unkeyed usage
+	_ struct{}
+}
+
+// WithBasePath sets the base path for this url builder, only required when it's different from the
+// base path specified in the swagger spec.
+// When the value of the base path is an empty string
+func (o *ListAuditEventsURL) WithBasePath(bp string) *ListAuditEventsURL {
+	o.SetBasePath(bp)
+	return o
+}
+
+// SetBasePath sets the base path for this url builder, only required when it's different from the
+// base path specified in the swagger spec.
+// When the value of the base path is an empty string
+func (o *ListAuditEventsURL) SetBasePath(bp string) {
+	o._basePath = bp
+}
+
+// Build a url path and query string
+func (o *ListAuditEventsURL) Build() (*url.URL, error) {
+	var _result url.URL
+
+	var _path = "/astrolabe/audit"
+
+	_basePath := o._basePath
+	if _basePath == "" {
+		_basePath = "/v1"
+	}
+	_result.Path = golangswaggerpaths.Join(_basePath, _path)
+
+	qs := make(url.Values)
+
+	var fromQ string
+	if o.From != nil {
+		fromQ = o.From.String()
+	}
+	if fromQ != "" {
+		qs.Set("from", fromQ)
+	}
+
+	var protectedEntityIDQ string
+	if o.ProtectedEntityID != nil {
+		protectedEntityIDQ = *o.ProtectedEntityID
+	}
+	if protectedEntityIDQ != "" {
+		qs.Set("protectedEntityID", protectedEntityIDQ)
+	}
+
+	var toQ string
+	if o.To != nil {
+		toQ = o.To.String()
+	}
+	if toQ != "" {
+		qs.Set("to", toQ)
+	}
+
+	_result.RawQuery = qs.Encode()
+
+	return &_result, nil
+}
+
+// Must is a helper function to panic when the url builder returns an error
+func (o *ListAuditEventsURL) Must(u *url.URL, err error) *url.URL {
+	if err != nil {
+		panic(err)
+	}
+	if u == nil {
+		panic("url can't be nil")
+	}
+	return u
+}
+
+// String returns the string representation of the path with query string
+func (o *ListAuditEventsURL) String() string {
+	return o.Must(o.Build()).String()
+}
+
+// BuildFull builds a full url with scheme, host, path and query string
+func (o *ListAuditEventsURL) BuildFull(scheme, host string) (*url.URL, error) {
+	if scheme == "" {
+		return nil, errors.New("scheme is required for a full url on ListAuditEventsURL")
+	}
+	if host == "" {
+		return nil, errors.New("host is required for a full url on ListAuditEventsURL")
+	}
+
+	base, err := o.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	base.Scheme = scheme
+	base.Host = host
+	return base, nil
+}
+
+// StringFull returns the string representation of a complete url
+func (o *ListAuditEventsURL) StringFull(scheme, host string) string {
+	return o.Must(o.BuildFull(scheme, host)).String()
+}
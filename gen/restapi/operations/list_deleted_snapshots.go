@@ -0,0 +1,60 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+)
+
+// ListDeletedSnapshotsHandlerFunc turns a function with the right signature into a list deleted snapshots handler
+type ListDeletedSnapshotsHandlerFunc func(ListDeletedSnapshotsParams) middleware.Responder
+
+// Handle executing the request and returning a response
+func (fn ListDeletedSnapshotsHandlerFunc) Handle(params ListDeletedSnapshotsParams) middleware.Responder {
+	return fn(params)
+}
+
+// ListDeletedSnapshotsHandler interface for that can handle valid list deleted snapshots params
+type ListDeletedSnapshotsHandler interface {
+	Handle(ListDeletedSnapshotsParams) middleware.Responder
+}
+
+// NewListDeletedSnapshots creates a new http.Handler for the list deleted snapshots operation
+func NewListDeletedSnapshots(ctx *middleware.Context, handler ListDeletedSnapshotsHandler) *ListDeletedSnapshots {
+	return &ListDeletedSnapshots{Context: ctx, Handler: handler}
+}
+
+/*ListDeletedSnapshots swagger:route GET /astrolabe/{service}/{protectedEntityID}/deletedSnapshots listDeletedSnapshots
+
+Lists the deleted snapshots of this protected entity that can still be
+restored with undeleteSnapshot
+
+
+*/
+type ListDeletedSnapshots struct {
+	Context *middleware.Context
+	Handler ListDeletedSnapshotsHandler
+}
+
+func (o *ListDeletedSnapshots) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	route, rCtx, _ := o.Context.RouteInfo(r)
+	if rCtx != nil {
+		r = rCtx
+	}
+	var Params = NewListDeletedSnapshotsParams()
+
+	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+
+	res := o.Handler.Handle(Params) // actually handle the request
+
+	o.Context.Respond(rw, r, route.Produces, route, res)
+
+}
@@ -0,0 +1,190 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// ListDeletedSnapshotsOKCode is the HTTP code returned for type ListDeletedSnapshotsOK
+const ListDeletedSnapshotsOKCode int = 200
+
+/*ListDeletedSnapshotsOK List succeeded
+
+swagger:response listDeletedSnapshotsOK
+*/
+type ListDeletedSnapshotsOK struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.DeletedSnapshotList `json:"body,omitempty"`
+}
+
+// NewListDeletedSnapshotsOK creates ListDeletedSnapshotsOK with default headers values
+func NewListDeletedSnapshotsOK() *ListDeletedSnapshotsOK {
+
+	return &ListDeletedSnapshotsOK{}
+}
+
+// WithPayload adds the payload to the list deleted snapshots o k response
+func (o *ListDeletedSnapshotsOK) WithPayload(payload *models.DeletedSnapshotList) *ListDeletedSnapshotsOK {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list deleted snapshots o k response
+func (o *ListDeletedSnapshotsOK) SetPayload(payload *models.DeletedSnapshotList) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListDeletedSnapshotsOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(200)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// ListDeletedSnapshotsBadRequestCode is the HTTP code returned for type ListDeletedSnapshotsBadRequest
+const ListDeletedSnapshotsBadRequestCode int = 400
+
+/*ListDeletedSnapshotsBadRequest Invalid protected entity ID
+
+swagger:response listDeletedSnapshotsBadRequest
+*/
+type ListDeletedSnapshotsBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewListDeletedSnapshotsBadRequest creates ListDeletedSnapshotsBadRequest with default headers values
+func NewListDeletedSnapshotsBadRequest() *ListDeletedSnapshotsBadRequest {
+
+	return &ListDeletedSnapshotsBadRequest{}
+}
+
+// WithPayload adds the payload to the list deleted snapshots bad request response
+func (o *ListDeletedSnapshotsBadRequest) WithPayload(payload *models.Error) *ListDeletedSnapshotsBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list deleted snapshots bad request response
+func (o *ListDeletedSnapshotsBadRequest) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListDeletedSnapshotsBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// ListDeletedSnapshotsNotFoundCode is the HTTP code returned for type ListDeletedSnapshotsNotFound
+const ListDeletedSnapshotsNotFoundCode int = 404
+
+/*ListDeletedSnapshotsNotFound Service not found or the service does not support soft delete
+
+swagger:response listDeletedSnapshotsNotFound
+*/
+type ListDeletedSnapshotsNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewListDeletedSnapshotsNotFound creates ListDeletedSnapshotsNotFound with default headers values
+func NewListDeletedSnapshotsNotFound() *ListDeletedSnapshotsNotFound {
+
+	return &ListDeletedSnapshotsNotFound{}
+}
+
+// WithPayload adds the payload to the list deleted snapshots not found response
+func (o *ListDeletedSnapshotsNotFound) WithPayload(payload *models.Error) *ListDeletedSnapshotsNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list deleted snapshots not found response
+func (o *ListDeletedSnapshotsNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListDeletedSnapshotsNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// ListDeletedSnapshotsInternalServerErrorCode is the HTTP code returned for type ListDeletedSnapshotsInternalServerError
+const ListDeletedSnapshotsInternalServerErrorCode int = 500
+
+/*ListDeletedSnapshotsInternalServerError List failed
+
+swagger:response listDeletedSnapshotsInternalServerError
+*/
+type ListDeletedSnapshotsInternalServerError struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewListDeletedSnapshotsInternalServerError creates ListDeletedSnapshotsInternalServerError with default headers values
+func NewListDeletedSnapshotsInternalServerError() *ListDeletedSnapshotsInternalServerError {
+
+	return &ListDeletedSnapshotsInternalServerError{}
+}
+
+// WithPayload adds the payload to the list deleted snapshots internal server error response
+func (o *ListDeletedSnapshotsInternalServerError) WithPayload(payload *models.Error) *ListDeletedSnapshotsInternalServerError {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list deleted snapshots internal server error response
+func (o *ListDeletedSnapshotsInternalServerError) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListDeletedSnapshotsInternalServerError) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(500)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
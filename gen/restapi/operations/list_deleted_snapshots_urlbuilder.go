@@ -0,0 +1,107 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the generate command
+
+import (
+	"errors"
+	"net/url"
+	golangswaggerpaths "path"
+	"strings"
+)
+
+// ListDeletedSnapshotsURL generates an URL for the list deleted snapshots operation
+type ListDeletedSnapshotsURL struct {
+	ProtectedEntityID string
+	Service           string
+
+	_basePath string
+	// avoid unkeyed usage
+	_ struct{}
+}
+
+// WithBasePath sets the base path for this url builder, only required when it's different from the
+// base path specified in the swagger spec.
+// When the value of the base path is an empty string
+func (o *ListDeletedSnapshotsURL) WithBasePath(bp string) *ListDeletedSnapshotsURL {
+	o.SetBasePath(bp)
+	return o
+}
+
+// SetBasePath sets the base path for this url builder, only required when it's different from the
+// base path specified in the swagger spec.
+// When the value of the base path is an empty string
+func (o *ListDeletedSnapshotsURL) SetBasePath(bp string) {
+	o._basePath = bp
+}
+
+// Build a url path and query string
+func (o *ListDeletedSnapshotsURL) Build() (*url.URL, error) {
+	var _result url.URL
+
+	var _path = "/astrolabe/{service}/{protectedEntityID}/deletedSnapshots"
+
+	protectedEntityID := o.ProtectedEntityID
+	if protectedEntityID != "" {
+		_path = strings.Replace(_path, "{protectedEntityID}", protectedEntityID, -1)
+	} else {
+		return nil, errors.New("protectedEntityId is required on ListDeletedSnapshotsURL")
+	}
+
+	service := o.Service
+	if service != "" {
+		_path = strings.Replace(_path, "{service}", service, -1)
+	} else {
+		return nil, errors.New("service is required on ListDeletedSnapshotsURL")
+	}
+
+	_basePath := o._basePath
+	if _basePath == "" {
+		_basePath = "/v1"
+	}
+	_result.Path = golangswaggerpaths.Join(_basePath, _path)
+
+	return &_result, nil
+}
+
+// Must is a helper function to panic when the url builder returns an error
+func (o *ListDeletedSnapshotsURL) Must(u *url.URL, err error) *url.URL {
+	if err != nil {
+		panic(err)
+	}
+	if u == nil {
+		panic("url can't be nil")
+	}
+	return u
+}
+
+// String returns the string representation of the path with query string
+func (o *ListDeletedSnapshotsURL) String() string {
+	return o.Must(o.Build()).String()
+}
+
+// BuildFull builds a full url with scheme, host, path and query string
+func (o *ListDeletedSnapshotsURL) BuildFull(scheme, host string) (*url.URL, error) {
+	if scheme == "" {
+		return nil, errors.New("scheme is required for a full url on ListDeletedSnapshotsURL")
+	}
+	if host == "" {
+		return nil, errors.New("host is required for a full url on ListDeletedSnapshotsURL")
+	}
+
+	base, err := o.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	base.Scheme = scheme
+	base.Host = host
+	return base, nil
+}
+
+// StringFull returns the string representation of a complete url
+func (o *ListDeletedSnapshotsURL) StringFull(scheme, host string) string {
+	return o.Must(o.BuildFull(scheme, host)).String()
+}
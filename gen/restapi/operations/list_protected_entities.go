@@ -32,9 +32,10 @@ func NewListProtectedEntities(ctx *middleware.Context, handler ListProtectedEnti
 /*ListProtectedEntities swagger:route GET /astrolabe/{service} listProtectedEntities
 
 List protected entities for the service.  Results will be returned in
-canonical ID order (string sorted).  Fewer results may be returned than
-expected, the ProtectedEntityList has a field specifying if the list has
-been truncated.
+canonical ID order (string sorted) unless orderBy or order are set.
+Fewer results may be returned than expected, the ProtectedEntityList
+has a field specifying if the list has been truncated and a
+continuationToken for the next page.
 
 
 */
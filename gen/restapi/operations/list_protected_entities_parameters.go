@@ -13,6 +13,7 @@ import (
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
 )
 
 // NewListProtectedEntitiesParams creates a new ListProtectedEntitiesParams object
@@ -31,14 +32,47 @@ type ListProtectedEntitiesParams struct {
 	// HTTP Request Object
 	HTTPRequest *http.Request `json:"-"`
 
-	/*Results will be returned that come after this ID
+	/*Continue the list from a previous response, pass its
+	continuationToken with the same orderBy and order
+
+	  In: query
+	*/
+	ContinuationToken *string
+	/*Results will be returned that come after this ID.  Deprecated, use
+	continuationToken.  Cannot be combined with continuationToken or
+	ordering
+
 	  In: query
 	*/
 	IdsAfter *string
+	/*Only return protected entities whose labels match this label
+	selector, e.g. app=nginx,tier!=frontend
+
+	  In: query
+	*/
+	LabelSelector *string
 	/*The maximum number of results to return (fewer results may be returned)
 	  In: query
 	*/
 	MaxResults *int32
+	/*Only return protected entities with this name
+	  In: query
+	*/
+	Name *string
+	/*Ascending or descending order, asc by default
+	  In: query
+	*/
+	Order *string
+	/*The order of the results, id by default
+	  In: query
+	*/
+	OrderBy *string
+	/*Only return protected entities whose ID, without the service,
+	starts with this prefix
+
+	  In: query
+	*/
+	Prefix *string
 	/*The service to list protected entities from
 	  Required: true
 	  In: path
@@ -57,16 +91,46 @@ func (o *ListProtectedEntitiesParams) BindRequest(r *http.Request, route *middle
 
 	qs := runtime.Values(r.URL.Query())
 
+	qContinuationToken, qhkContinuationToken, _ := qs.GetOK("continuationToken")
+	if err := o.bindContinuationToken(qContinuationToken, qhkContinuationToken, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
 	qIdsAfter, qhkIdsAfter, _ := qs.GetOK("idsAfter")
 	if err := o.bindIdsAfter(qIdsAfter, qhkIdsAfter, route.Formats); err != nil {
 		res = append(res, err)
 	}
 
+	qLabelSelector, qhkLabelSelector, _ := qs.GetOK("labelSelector")
+	if err := o.bindLabelSelector(qLabelSelector, qhkLabelSelector, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
 	qMaxResults, qhkMaxResults, _ := qs.GetOK("maxResults")
 	if err := o.bindMaxResults(qMaxResults, qhkMaxResults, route.Formats); err != nil {
 		res = append(res, err)
 	}
 
+	qName, qhkName, _ := qs.GetOK("name")
+	if err := o.bindName(qName, qhkName, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qOrder, qhkOrder, _ := qs.GetOK("order")
+	if err := o.bindOrder(qOrder, qhkOrder, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qOrderBy, qhkOrderBy, _ := qs.GetOK("orderBy")
+	if err := o.bindOrderBy(qOrderBy, qhkOrderBy, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qPrefix, qhkPrefix, _ := qs.GetOK("prefix")
+	if err := o.bindPrefix(qPrefix, qhkPrefix, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
 	rService, rhkService, _ := route.Params.GetOK("service")
 	if err := o.bindService(rService, rhkService, route.Formats); err != nil {
 		res = append(res, err)
@@ -78,6 +142,24 @@ func (o *ListProtectedEntitiesParams) BindRequest(r *http.Request, route *middle
 	return nil
 }
 
+// bindContinuationToken binds and validates parameter ContinuationToken from query.
+func (o *ListProtectedEntitiesParams) bindContinuationToken(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.ContinuationToken = &raw
+
+	return nil
+}
+
 // bindIdsAfter binds and validates parameter IdsAfter from query.
 func (o *ListProtectedEntitiesParams) bindIdsAfter(rawData []string, hasKey bool, formats strfmt.Registry) error {
 	var raw string
@@ -96,6 +178,24 @@ func (o *ListProtectedEntitiesParams) bindIdsAfter(rawData []string, hasKey bool
 	return nil
 }
 
+// bindLabelSelector binds and validates parameter LabelSelector from query.
+func (o *ListProtectedEntitiesParams) bindLabelSelector(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.LabelSelector = &raw
+
+	return nil
+}
+
 // bindMaxResults binds and validates parameter MaxResults from query.
 func (o *ListProtectedEntitiesParams) bindMaxResults(rawData []string, hasKey bool, formats strfmt.Registry) error {
 	var raw string
@@ -118,6 +218,106 @@ func (o *ListProtectedEntitiesParams) bindMaxResults(rawData []string, hasKey bo
 	return nil
 }
 
+// bindName binds and validates parameter Name from query.
+func (o *ListProtectedEntitiesParams) bindName(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.Name = &raw
+
+	return nil
+}
+
+// bindOrder binds and validates parameter Order from query.
+func (o *ListProtectedEntitiesParams) bindOrder(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.Order = &raw
+
+	if err := o.validateOrder(formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateOrder carries on validations for parameter Order
+func (o *ListProtectedEntitiesParams) validateOrder(formats strfmt.Registry) error {
+
+	if err := validate.Enum("order", "query", *o.Order, []interface{}{"asc", "desc"}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// bindOrderBy binds and validates parameter OrderBy from query.
+func (o *ListProtectedEntitiesParams) bindOrderBy(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.OrderBy = &raw
+
+	if err := o.validateOrderBy(formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateOrderBy carries on validations for parameter OrderBy
+func (o *ListProtectedEntitiesParams) validateOrderBy(formats strfmt.Registry) error {
+
+	if err := validate.Enum("orderBy", "query", *o.OrderBy, []interface{}{"id", "name"}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// bindPrefix binds and validates parameter Prefix from query.
+func (o *ListProtectedEntitiesParams) bindPrefix(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.Prefix = &raw
+
+	return nil
+}
+
 // bindService binds and validates parameter Service from path.
 func (o *ListProtectedEntitiesParams) bindService(rawData []string, hasKey bool, formats strfmt.Registry) error {
 	var raw string
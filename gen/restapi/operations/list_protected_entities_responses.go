@@ -57,14 +57,63 @@ func (o *ListProtectedEntitiesOK) WriteResponse(rw http.ResponseWriter, producer
 	}
 }
 
+// ListProtectedEntitiesBadRequestCode is the HTTP code returned for type ListProtectedEntitiesBadRequest
+const ListProtectedEntitiesBadRequestCode int = 400
+
+/*ListProtectedEntitiesBadRequest Invalid label selector or list options
+
+swagger:response listProtectedEntitiesBadRequest
+*/
+type ListProtectedEntitiesBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewListProtectedEntitiesBadRequest creates ListProtectedEntitiesBadRequest with default headers values
+func NewListProtectedEntitiesBadRequest() *ListProtectedEntitiesBadRequest {
+
+	return &ListProtectedEntitiesBadRequest{}
+}
+
+// WithPayload adds the payload to the list protected entities bad request response
+func (o *ListProtectedEntitiesBadRequest) WithPayload(payload *models.Error) *ListProtectedEntitiesBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list protected entities bad request response
+func (o *ListProtectedEntitiesBadRequest) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListProtectedEntitiesBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
 // ListProtectedEntitiesNotFoundCode is the HTTP code returned for type ListProtectedEntitiesNotFound
 const ListProtectedEntitiesNotFoundCode int = 404
 
-/*ListProtectedEntitiesNotFound Service or Protected Entity not found
+/*ListProtectedEntitiesNotFound Service not found
 
 swagger:response listProtectedEntitiesNotFound
 */
 type ListProtectedEntitiesNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
 }
 
 // NewListProtectedEntitiesNotFound creates ListProtectedEntitiesNotFound with default headers values
@@ -73,10 +122,69 @@ func NewListProtectedEntitiesNotFound() *ListProtectedEntitiesNotFound {
 	return &ListProtectedEntitiesNotFound{}
 }
 
+// WithPayload adds the payload to the list protected entities not found response
+func (o *ListProtectedEntitiesNotFound) WithPayload(payload *models.Error) *ListProtectedEntitiesNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list protected entities not found response
+func (o *ListProtectedEntitiesNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
 // WriteResponse to the client
 func (o *ListProtectedEntitiesNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
 
-	rw.Header().Del(runtime.HeaderContentType) //Remove Content-Type on empty responses
-
 	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// ListProtectedEntitiesInternalServerErrorCode is the HTTP code returned for type ListProtectedEntitiesInternalServerError
+const ListProtectedEntitiesInternalServerErrorCode int = 500
+
+/*ListProtectedEntitiesInternalServerError List failed
+
+swagger:response listProtectedEntitiesInternalServerError
+*/
+type ListProtectedEntitiesInternalServerError struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewListProtectedEntitiesInternalServerError creates ListProtectedEntitiesInternalServerError with default headers values
+func NewListProtectedEntitiesInternalServerError() *ListProtectedEntitiesInternalServerError {
+
+	return &ListProtectedEntitiesInternalServerError{}
+}
+
+// WithPayload adds the payload to the list protected entities internal server error response
+func (o *ListProtectedEntitiesInternalServerError) WithPayload(payload *models.Error) *ListProtectedEntitiesInternalServerError {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list protected entities internal server error response
+func (o *ListProtectedEntitiesInternalServerError) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListProtectedEntitiesInternalServerError) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(500)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
 }
@@ -18,8 +18,14 @@ import (
 type ListProtectedEntitiesURL struct {
 	Service string
 
-	IdsAfter   *string
-	MaxResults *int32
+	ContinuationToken *string
+	IdsAfter          *string
+	LabelSelector     *string
+	MaxResults        *int32
+	Name              *string
+	Order             *string
+	OrderBy           *string
+	Prefix            *string
 
 	_basePath string
 	// avoid unkeyed usage
@@ -62,6 +68,14 @@ func (o *ListProtectedEntitiesURL) Build() (*url.URL, error) {
 
 	qs := make(url.Values)
 
+	var continuationTokenQ string
+	if o.ContinuationToken != nil {
+		continuationTokenQ = *o.ContinuationToken
+	}
+	if continuationTokenQ != "" {
+		qs.Set("continuationToken", continuationTokenQ)
+	}
+
 	var idsAfterQ string
 	if o.IdsAfter != nil {
 		idsAfterQ = *o.IdsAfter
@@ -70,6 +84,14 @@ func (o *ListProtectedEntitiesURL) Build() (*url.URL, error) {
 		qs.Set("idsAfter", idsAfterQ)
 	}
 
+	var labelSelectorQ string
+	if o.LabelSelector != nil {
+		labelSelectorQ = *o.LabelSelector
+	}
+	if labelSelectorQ != "" {
+		qs.Set("labelSelector", labelSelectorQ)
+	}
+
 	var maxResultsQ string
 	if o.MaxResults != nil {
 		maxResultsQ = swag.FormatInt32(*o.MaxResults)
@@ -78,6 +100,38 @@ func (o *ListProtectedEntitiesURL) Build() (*url.URL, error) {
 		qs.Set("maxResults", maxResultsQ)
 	}
 
+	var nameQ string
+	if o.Name != nil {
+		nameQ = *o.Name
+	}
+	if nameQ != "" {
+		qs.Set("name", nameQ)
+	}
+
+	var orderQ string
+	if o.Order != nil {
+		orderQ = *o.Order
+	}
+	if orderQ != "" {
+		qs.Set("order", orderQ)
+	}
+
+	var orderByQ string
+	if o.OrderBy != nil {
+		orderByQ = *o.OrderBy
+	}
+	if orderByQ != "" {
+		qs.Set("orderBy", orderByQ)
+	}
+
+	var prefixQ string
+	if o.Prefix != nil {
+		prefixQ = *o.Prefix
+	}
+	if prefixQ != "" {
+		qs.Set("prefix", prefixQ)
+	}
+
 	_result.RawQuery = qs.Encode()
 
 	return &_result, nil
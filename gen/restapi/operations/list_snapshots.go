@@ -31,7 +31,8 @@ func NewListSnapshots(ctx *middleware.Context, handler ListSnapshotsHandler) *Li
 
 /*ListSnapshots swagger:route GET /astrolabe/{service}/{protectedEntityID}/snapshots listSnapshots
 
-Gets the list of snapshots for this protected entity
+Gets the list of snapshots for this protected entity.  Results will be
+returned in canonical ID order unless orderBy or order are set.
 
 
 */
@@ -9,8 +9,11 @@ import (
 	"net/http"
 
 	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
 )
 
 // NewListSnapshotsParams creates a new ListSnapshotsParams object
@@ -29,6 +32,39 @@ type ListSnapshotsParams struct {
 	// HTTP Request Object
 	HTTPRequest *http.Request `json:"-"`
 
+	/*Continue the list from a previous response, pass its
+	continuationToken with the same orderBy and order
+
+	  In: query
+	*/
+	ContinuationToken *string
+	/*Only return snapshots whose labels match this label selector,
+	e.g. app=nginx,tier!=frontend
+
+	  In: query
+	*/
+	LabelSelector *string
+	/*The maximum number of results to return (fewer results may be returned)
+	  In: query
+	*/
+	MaxResults *int32
+	/*Only return snapshots with this name
+	  In: query
+	*/
+	Name *string
+	/*Ascending or descending order, asc by default
+	  In: query
+	*/
+	Order *string
+	/*The order of the results, id by default
+	  In: query
+	*/
+	OrderBy *string
+	/*Only return snapshots whose snapshot ID starts with this prefix
+
+	  In: query
+	*/
+	Prefix *string
 	/*The protected entity ID to retrieve info for
 	  Required: true
 	  In: path
@@ -50,6 +86,43 @@ func (o *ListSnapshotsParams) BindRequest(r *http.Request, route *middleware.Mat
 
 	o.HTTPRequest = r
 
+	qs := runtime.Values(r.URL.Query())
+
+	qContinuationToken, qhkContinuationToken, _ := qs.GetOK("continuationToken")
+	if err := o.bindContinuationToken(qContinuationToken, qhkContinuationToken, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qLabelSelector, qhkLabelSelector, _ := qs.GetOK("labelSelector")
+	if err := o.bindLabelSelector(qLabelSelector, qhkLabelSelector, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qMaxResults, qhkMaxResults, _ := qs.GetOK("maxResults")
+	if err := o.bindMaxResults(qMaxResults, qhkMaxResults, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qName, qhkName, _ := qs.GetOK("name")
+	if err := o.bindName(qName, qhkName, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qOrder, qhkOrder, _ := qs.GetOK("order")
+	if err := o.bindOrder(qOrder, qhkOrder, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qOrderBy, qhkOrderBy, _ := qs.GetOK("orderBy")
+	if err := o.bindOrderBy(qOrderBy, qhkOrderBy, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qPrefix, qhkPrefix, _ := qs.GetOK("prefix")
+	if err := o.bindPrefix(qPrefix, qhkPrefix, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
 	rProtectedEntityID, rhkProtectedEntityID, _ := route.Params.GetOK("protectedEntityID")
 	if err := o.bindProtectedEntityID(rProtectedEntityID, rhkProtectedEntityID, route.Formats); err != nil {
 		res = append(res, err)
@@ -66,6 +139,164 @@ func (o *ListSnapshotsParams) BindRequest(r *http.Request, route *middleware.Mat
 	return nil
 }
 
+// bindContinuationToken binds and validates parameter ContinuationToken from query.
+func (o *ListSnapshotsParams) bindContinuationToken(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.ContinuationToken = &raw
+
+	return nil
+}
+
+// bindLabelSelector binds and validates parameter LabelSelector from query.
+func (o *ListSnapshotsParams) bindLabelSelector(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.LabelSelector = &raw
+
+	return nil
+}
+
+// bindMaxResults binds and validates parameter MaxResults from query.
+func (o *ListSnapshotsParams) bindMaxResults(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	value, err := swag.ConvertInt32(raw)
+	if err != nil {
+		return errors.InvalidType("maxResults", "query", "int32", raw)
+	}
+	o.MaxResults = &value
+
+	return nil
+}
+
+// bindName binds and validates parameter Name from query.
+func (o *ListSnapshotsParams) bindName(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.Name = &raw
+
+	return nil
+}
+
+// bindOrder binds and validates parameter Order from query.
+func (o *ListSnapshotsParams) bindOrder(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.Order = &raw
+
+	if err := o.validateOrder(formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateOrder carries on validations for parameter Order
+func (o *ListSnapshotsParams) validateOrder(formats strfmt.Registry) error {
+
+	if err := validate.Enum("order", "query", *o.Order, []interface{}{"asc", "desc"}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// bindOrderBy binds and validates parameter OrderBy from query.
+func (o *ListSnapshotsParams) bindOrderBy(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.OrderBy = &raw
+
+	if err := o.validateOrderBy(formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateOrderBy carries on validations for parameter OrderBy
+func (o *ListSnapshotsParams) validateOrderBy(formats strfmt.Registry) error {
+
+	if err := validate.Enum("orderBy", "query", *o.OrderBy, []interface{}{"id", "name"}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// bindPrefix binds and validates parameter Prefix from query.
+func (o *ListSnapshotsParams) bindPrefix(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.Prefix = &raw
+
+	return nil
+}
+
 // bindProtectedEntityID binds and validates parameter ProtectedEntityID from path.
 func (o *ListSnapshotsParams) bindProtectedEntityID(rawData []string, hasKey bool, formats strfmt.Registry) error {
 	var raw string
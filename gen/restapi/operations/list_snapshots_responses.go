@@ -57,6 +57,50 @@ func (o *ListSnapshotsOK) WriteResponse(rw http.ResponseWriter, producer runtime
 	}
 }
 
+// ListSnapshotsBadRequestCode is the HTTP code returned for type ListSnapshotsBadRequest
+const ListSnapshotsBadRequestCode int = 400
+
+/*ListSnapshotsBadRequest Invalid label selector or list options
+
+swagger:response listSnapshotsBadRequest
+*/
+type ListSnapshotsBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewListSnapshotsBadRequest creates ListSnapshotsBadRequest with default headers values
+func NewListSnapshotsBadRequest() *ListSnapshotsBadRequest {
+
+	return &ListSnapshotsBadRequest{}
+}
+
+// WithPayload adds the payload to the list snapshots bad request response
+func (o *ListSnapshotsBadRequest) WithPayload(payload *models.Error) *ListSnapshotsBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list snapshots bad request response
+func (o *ListSnapshotsBadRequest) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListSnapshotsBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
 // ListSnapshotsNotFoundCode is the HTTP code returned for type ListSnapshotsNotFound
 const ListSnapshotsNotFoundCode int = 404
 
@@ -65,6 +109,11 @@ const ListSnapshotsNotFoundCode int = 404
 swagger:response listSnapshotsNotFound
 */
 type ListSnapshotsNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
 }
 
 // NewListSnapshotsNotFound creates ListSnapshotsNotFound with default headers values
@@ -73,10 +122,69 @@ func NewListSnapshotsNotFound() *ListSnapshotsNotFound {
 	return &ListSnapshotsNotFound{}
 }
 
+// WithPayload adds the payload to the list snapshots not found response
+func (o *ListSnapshotsNotFound) WithPayload(payload *models.Error) *ListSnapshotsNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list snapshots not found response
+func (o *ListSnapshotsNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
 // WriteResponse to the client
 func (o *ListSnapshotsNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
 
-	rw.Header().Del(runtime.HeaderContentType) //Remove Content-Type on empty responses
-
 	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// ListSnapshotsInternalServerErrorCode is the HTTP code returned for type ListSnapshotsInternalServerError
+const ListSnapshotsInternalServerErrorCode int = 500
+
+/*ListSnapshotsInternalServerError List failed
+
+swagger:response listSnapshotsInternalServerError
+*/
+type ListSnapshotsInternalServerError struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewListSnapshotsInternalServerError creates ListSnapshotsInternalServerError with default headers values
+func NewListSnapshotsInternalServerError() *ListSnapshotsInternalServerError {
+
+	return &ListSnapshotsInternalServerError{}
+}
+
+// WithPayload adds the payload to the list snapshots internal server error response
+func (o *ListSnapshotsInternalServerError) WithPayload(payload *models.Error) *ListSnapshotsInternalServerError {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list snapshots internal server error response
+func (o *ListSnapshotsInternalServerError) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListSnapshotsInternalServerError) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(500)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
 }
@@ -10,6 +10,8 @@ import (
 	"net/url"
 	golangswaggerpaths "path"
 	"strings"
+
+	"github.com/go-openapi/swag"
 )
 
 // ListSnapshotsURL generates an URL for the list snapshots operation
@@ -17,6 +19,14 @@ type ListSnapshotsURL struct {
 	ProtectedEntityID string
 	Service           string
 
+	ContinuationToken *string
+	LabelSelector     *string
+	MaxResults        *int32
+	Name              *string
+	Order             *string
+	OrderBy           *string
+	Prefix            *string
+
 	_basePath string
 	// avoid unkeyed usage
 	_ struct{}
@@ -63,6 +73,66 @@ func (o *ListSnapshotsURL) Build() (*url.URL, error) {
 	}
 	_result.Path = golangswaggerpaths.Join(_basePath, _path)
 
+	qs := make(url.Values)
+
+	var continuationTokenQ string
+	if o.ContinuationToken != nil {
+		continuationTokenQ = *o.ContinuationToken
+	}
+	if continuationTokenQ != "" {
+		qs.Set("continuationToken", continuationTokenQ)
+	}
+
+	var labelSelectorQ string
+	if o.LabelSelector != nil {
+		labelSelectorQ = *o.LabelSelector
+	}
+	if labelSelectorQ != "" {
+		qs.Set("labelSelector", labelSelectorQ)
+	}
+
+	var maxResultsQ string
+	if o.MaxResults != nil {
+		maxResultsQ = swag.FormatInt32(*o.MaxResults)
+	}
+	if maxResultsQ != "" {
+		qs.Set("maxResults", maxResultsQ)
+	}
+
+	var nameQ string
+	if o.Name != nil {
+		nameQ = *o.Name
+	}
+	if nameQ != "" {
+		qs.Set("name", nameQ)
+	}
+
+	var orderQ string
+	if o.Order != nil {
+		orderQ = *o.Order
+	}
+	if orderQ != "" {
+		qs.Set("order", orderQ)
+	}
+
+	var orderByQ string
+	if o.OrderBy != nil {
+		orderByQ = *o.OrderBy
+	}
+	if orderByQ != "" {
+		qs.Set("orderBy", orderByQ)
+	}
+
+	var prefixQ string
+	if o.Prefix != nil {
+		prefixQ = *o.Prefix
+	}
+	if prefixQ != "" {
+		qs.Set("prefix", prefixQ)
+	}
+
+	_result.RawQuery = qs.Encode()
+
 	return &_result, nil
 }
 
@@ -1,58 +0,0 @@
-// Code generated by go-swagger; DO NOT EDIT.
-
-package operations
-
-// This file was generated by the swagger tool.
-// Editing this file might prove futile when you re-run the generate command
-
-import (
-	"net/http"
-
-	"github.com/go-openapi/runtime/middleware"
-)
-
-// PostAstrolabeTasksNexusHandlerFunc turns a function with the right signature into a post astrolabe tasks nexus handler
-type PostAstrolabeTasksNexusHandlerFunc func(PostAstrolabeTasksNexusParams) middleware.Responder
-
-// Handle executing the request and returning a response
-func (fn PostAstrolabeTasksNexusHandlerFunc) Handle(params PostAstrolabeTasksNexusParams) middleware.Responder {
-	return fn(params)
-}
-
-// PostAstrolabeTasksNexusHandler interface for that can handle valid post astrolabe tasks nexus params
-type PostAstrolabeTasksNexusHandler interface {
-	Handle(PostAstrolabeTasksNexusParams) middleware.Responder
-}
-
-// NewPostAstrolabeTasksNexus creates a new http.Handler for the post astrolabe tasks nexus operation
-func NewPostAstrolabeTasksNexus(ctx *middleware.Context, handler PostAstrolabeTasksNexusHandler) *PostAstrolabeTasksNexus {
-	return &PostAstrolabeTasksNexus{Context: ctx, Handler: handler}
-}
-
-/*PostAstrolabeTasksNexus swagger:route POST /astrolabe/tasks/nexus postAstrolabeTasksNexus
-
-Creates a new nexus for monitoring task completion
-
-*/
-type PostAstrolabeTasksNexus struct {
-	Context *middleware.Context
-	Handler PostAstrolabeTasksNexusHandler
-}
-
-func (o *PostAstrolabeTasksNexus) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
-	route, rCtx, _ := o.Context.RouteInfo(r)
-	if rCtx != nil {
-		r = rCtx
-	}
-	var Params = NewPostAstrolabeTasksNexusParams()
-
-	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
-		o.Context.Respond(rw, r, route.Produces, route, err)
-		return
-	}
-
-	res := o.Handler.Handle(Params) // actually handle the request
-
-	o.Context.Respond(rw, r, route.Produces, route, res)
-
-}
@@ -1,56 +0,0 @@
-// Code generated by go-swagger; DO NOT EDIT.
-
-package operations
-
-// This file was generated by the swagger tool.
-// Editing this file might prove futile when you re-run the swagger generate command
-
-import (
-	"net/http"
-
-	"github.com/go-openapi/runtime"
-
-	"github.com/vmware-tanzu/astrolabe/gen/models"
-)
-
-// PostAstrolabeTasksNexusOKCode is the HTTP code returned for type PostAstrolabeTasksNexusOK
-const PostAstrolabeTasksNexusOKCode int = 200
-
-/*PostAstrolabeTasksNexusOK New task nexus
-
-swagger:response postAstrolabeTasksNexusOK
-*/
-type PostAstrolabeTasksNexusOK struct {
-
-	/*
-	  In: Body
-	*/
-	Payload models.TaskNexusID `json:"body,omitempty"`
-}
-
-// NewPostAstrolabeTasksNexusOK creates PostAstrolabeTasksNexusOK with default headers values
-func NewPostAstrolabeTasksNexusOK() *PostAstrolabeTasksNexusOK {
-
-	return &PostAstrolabeTasksNexusOK{}
-}
-
-// WithPayload adds the payload to the post astrolabe tasks nexus o k response
-func (o *PostAstrolabeTasksNexusOK) WithPayload(payload models.TaskNexusID) *PostAstrolabeTasksNexusOK {
-	o.Payload = payload
-	return o
-}
-
-// SetPayload sets the payload to the post astrolabe tasks nexus o k response
-func (o *PostAstrolabeTasksNexusOK) SetPayload(payload models.TaskNexusID) {
-	o.Payload = payload
-}
-
-// WriteResponse to the client
-func (o *PostAstrolabeTasksNexusOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
-
-	rw.WriteHeader(200)
-	payload := o.Payload
-	if err := producer.Produce(rw, payload); err != nil {
-		panic(err) // let the recovery middleware deal with this
-	}
-}
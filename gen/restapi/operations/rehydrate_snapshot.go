@@ -0,0 +1,60 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+)
+
+// RehydrateSnapshotHandlerFunc turns a function with the right signature into a rehydrate snapshot handler
+type RehydrateSnapshotHandlerFunc func(RehydrateSnapshotParams) middleware.Responder
+
+// Handle executing the request and returning a response
+func (fn RehydrateSnapshotHandlerFunc) Handle(params RehydrateSnapshotParams) middleware.Responder {
+	return fn(params)
+}
+
+// RehydrateSnapshotHandler interface for that can handle valid rehydrate snapshot params
+type RehydrateSnapshotHandler interface {
+	Handle(RehydrateSnapshotParams) middleware.Responder
+}
+
+// NewRehydrateSnapshot creates a new http.Handler for the rehydrate snapshot operation
+func NewRehydrateSnapshot(ctx *middleware.Context, handler RehydrateSnapshotHandler) *RehydrateSnapshot {
+	return &RehydrateSnapshot{Context: ctx, Handler: handler}
+}
+
+/*RehydrateSnapshot swagger:route POST /astrolabe/{service}/{protectedEntityID}/rehydrate rehydrateSnapshot
+
+Restores the data of a snapshot that has been moved to an archive storage tier so that it can be read.
+Restores can take hours, use getTaskInfo to follow the returned task
+
+
+*/
+type RehydrateSnapshot struct {
+	Context *middleware.Context
+	Handler RehydrateSnapshotHandler
+}
+
+func (o *RehydrateSnapshot) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	route, rCtx, _ := o.Context.RouteInfo(r)
+	if rCtx != nil {
+		r = rCtx
+	}
+	var Params = NewRehydrateSnapshotParams()
+
+	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+
+	res := o.Handler.Handle(Params) // actually handle the request
+
+	o.Context.Respond(rw, r, route.Produces, route, res)
+
+}
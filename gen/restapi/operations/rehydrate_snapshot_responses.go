@@ -0,0 +1,190 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// RehydrateSnapshotAcceptedCode is the HTTP code returned for type RehydrateSnapshotAccepted
+const RehydrateSnapshotAcceptedCode int = 202
+
+/*RehydrateSnapshotAccepted Rehydration started, the task reports its progress
+
+swagger:response rehydrateSnapshotAccepted
+*/
+type RehydrateSnapshotAccepted struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.CreateInProgressResponse `json:"body,omitempty"`
+}
+
+// NewRehydrateSnapshotAccepted creates RehydrateSnapshotAccepted with default headers values
+func NewRehydrateSnapshotAccepted() *RehydrateSnapshotAccepted {
+
+	return &RehydrateSnapshotAccepted{}
+}
+
+// WithPayload adds the payload to the rehydrate snapshot accepted response
+func (o *RehydrateSnapshotAccepted) WithPayload(payload *models.CreateInProgressResponse) *RehydrateSnapshotAccepted {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the rehydrate snapshot accepted response
+func (o *RehydrateSnapshotAccepted) SetPayload(payload *models.CreateInProgressResponse) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *RehydrateSnapshotAccepted) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(202)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// RehydrateSnapshotBadRequestCode is the HTTP code returned for type RehydrateSnapshotBadRequest
+const RehydrateSnapshotBadRequestCode int = 400
+
+/*RehydrateSnapshotBadRequest Invalid protected entity ID
+
+swagger:response rehydrateSnapshotBadRequest
+*/
+type RehydrateSnapshotBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewRehydrateSnapshotBadRequest creates RehydrateSnapshotBadRequest with default headers values
+func NewRehydrateSnapshotBadRequest() *RehydrateSnapshotBadRequest {
+
+	return &RehydrateSnapshotBadRequest{}
+}
+
+// WithPayload adds the payload to the rehydrate snapshot bad request response
+func (o *RehydrateSnapshotBadRequest) WithPayload(payload *models.Error) *RehydrateSnapshotBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the rehydrate snapshot bad request response
+func (o *RehydrateSnapshotBadRequest) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *RehydrateSnapshotBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// RehydrateSnapshotNotFoundCode is the HTTP code returned for type RehydrateSnapshotNotFound
+const RehydrateSnapshotNotFoundCode int = 404
+
+/*RehydrateSnapshotNotFound Service not found, the service does not support tiering or the snapshot does not exist
+
+swagger:response rehydrateSnapshotNotFound
+*/
+type RehydrateSnapshotNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewRehydrateSnapshotNotFound creates RehydrateSnapshotNotFound with default headers values
+func NewRehydrateSnapshotNotFound() *RehydrateSnapshotNotFound {
+
+	return &RehydrateSnapshotNotFound{}
+}
+
+// WithPayload adds the payload to the rehydrate snapshot not found response
+func (o *RehydrateSnapshotNotFound) WithPayload(payload *models.Error) *RehydrateSnapshotNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the rehydrate snapshot not found response
+func (o *RehydrateSnapshotNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *RehydrateSnapshotNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// RehydrateSnapshotInternalServerErrorCode is the HTTP code returned for type RehydrateSnapshotInternalServerError
+const RehydrateSnapshotInternalServerErrorCode int = 500
+
+/*RehydrateSnapshotInternalServerError Rehydration could not be started
+
+swagger:response rehydrateSnapshotInternalServerError
+*/
+type RehydrateSnapshotInternalServerError struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewRehydrateSnapshotInternalServerError creates RehydrateSnapshotInternalServerError with default headers values
+func NewRehydrateSnapshotInternalServerError() *RehydrateSnapshotInternalServerError {
+
+	return &RehydrateSnapshotInternalServerError{}
+}
+
+// WithPayload adds the payload to the rehydrate snapshot internal server error response
+func (o *RehydrateSnapshotInternalServerError) WithPayload(payload *models.Error) *RehydrateSnapshotInternalServerError {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the rehydrate snapshot internal server error response
+func (o *RehydrateSnapshotInternalServerError) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *RehydrateSnapshotInternalServerError) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(500)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+)
+
+// UndeleteSnapshotHandlerFunc turns a function with the right signature into a undelete snapshot handler
+type UndeleteSnapshotHandlerFunc func(UndeleteSnapshotParams) middleware.Responder
+
+// Handle executing the request and returning a response
+func (fn UndeleteSnapshotHandlerFunc) Handle(params UndeleteSnapshotParams) middleware.Responder {
+	return fn(params)
+}
+
+// UndeleteSnapshotHandler interface for that can handle valid undelete snapshot params
+type UndeleteSnapshotHandler interface {
+	Handle(UndeleteSnapshotParams) middleware.Responder
+}
+
+// NewUndeleteSnapshot creates a new http.Handler for the undelete snapshot operation
+func NewUndeleteSnapshot(ctx *middleware.Context, handler UndeleteSnapshotHandler) *UndeleteSnapshot {
+	return &UndeleteSnapshot{Context: ctx, Handler: handler}
+}
+
+/*UndeleteSnapshot swagger:route POST /astrolabe/{service}/{protectedEntityID}/undelete undeleteSnapshot
+
+Restores a deleted snapshot before it is purged
+
+
+*/
+type UndeleteSnapshot struct {
+	Context *middleware.Context
+	Handler UndeleteSnapshotHandler
+}
+
+func (o *UndeleteSnapshot) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	route, rCtx, _ := o.Context.RouteInfo(r)
+	if rCtx != nil {
+		r = rCtx
+	}
+	var Params = NewUndeleteSnapshotParams()
+
+	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+
+	res := o.Handler.Handle(Params) // actually handle the request
+
+	o.Context.Respond(rw, r, route.Produces, route, res)
+
+}
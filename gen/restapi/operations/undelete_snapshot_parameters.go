@@ -0,0 +1,128 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+)
+
+// NewUndeleteSnapshotParams creates a new UndeleteSnapshotParams object
+// no default values defined in spec.
+func NewUndeleteSnapshotParams() UndeleteSnapshotParams {
+
+	return UndeleteSnapshotParams{}
+}
+
+// UndeleteSnapshotParams contains all the bound params for the undelete snapshot operation
+// typically these are obtained from a http.Request
+//
+// swagger:parameters undeleteSnapshot
+type UndeleteSnapshotParams struct {
+
+	// HTTP Request Object
+	HTTPRequest *http.Request `json:"-"`
+
+	/*A key chosen by the client that identifies this request.  If a
+	request with the same key has already completed successfully within
+	the server's idempotency window, its result is returned instead of
+	running the operation again.  Keys are scoped to the caller and
+	cannot be reused for a different request.
+
+	  In: header
+	*/
+	IdempotencyKey *string
+	/*The protected entity snapshot ID to restore
+	  Required: true
+	  In: path
+	*/
+	ProtectedEntityID string
+	/*The service for the protected entity
+	  Required: true
+	  In: path
+	*/
+	Service string
+}
+
+// BindRequest both binds and validates a request, it assumes that complex things implement a Validatable(strfmt.Registry) error interface
+// for simple values it will use straight method calls.
+//
+// To ensure default values, the struct must have been initialized with NewUndeleteSnapshotParams() beforehand.
+func (o *UndeleteSnapshotParams) BindRequest(r *http.Request, route *middleware.MatchedRoute) error {
+	var res []error
+
+	o.HTTPRequest = r
+
+	if err := o.bindIdempotencyKey(r.Header[http.CanonicalHeaderKey("Idempotency-Key")], true, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	rProtectedEntityID, rhkProtectedEntityID, _ := route.Params.GetOK("protectedEntityID")
+	if err := o.bindProtectedEntityID(rProtectedEntityID, rhkProtectedEntityID, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	rService, rhkService, _ := route.Params.GetOK("service")
+	if err := o.bindService(rService, rhkService, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+// bindIdempotencyKey binds and validates parameter IdempotencyKey from header.
+func (o *UndeleteSnapshotParams) bindIdempotencyKey(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.IdempotencyKey = &raw
+
+	return nil
+}
+
+// bindProtectedEntityID binds and validates parameter ProtectedEntityID from path.
+func (o *UndeleteSnapshotParams) bindProtectedEntityID(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: true
+	// Parameter is provided by construction from the route
+
+	o.ProtectedEntityID = raw
+
+	return nil
+}
+
+// bindService binds and validates parameter Service from path.
+func (o *UndeleteSnapshotParams) bindService(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: true
+	// Parameter is provided by construction from the route
+
+	o.Service = raw
+
+	return nil
+}
@@ -0,0 +1,188 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// UndeleteSnapshotOKCode is the HTTP code returned for type UndeleteSnapshotOK
+const UndeleteSnapshotOKCode int = 200
+
+/*UndeleteSnapshotOK Snapshot restored, returns the snapshot protected entity ID
+
+swagger:response undeleteSnapshotOK
+*/
+type UndeleteSnapshotOK struct {
+
+	/*
+	  In: Body
+	*/
+	Payload models.ProtectedEntityID `json:"body,omitempty"`
+}
+
+// NewUndeleteSnapshotOK creates UndeleteSnapshotOK with default headers values
+func NewUndeleteSnapshotOK() *UndeleteSnapshotOK {
+
+	return &UndeleteSnapshotOK{}
+}
+
+// WithPayload adds the payload to the undelete snapshot o k response
+func (o *UndeleteSnapshotOK) WithPayload(payload models.ProtectedEntityID) *UndeleteSnapshotOK {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the undelete snapshot o k response
+func (o *UndeleteSnapshotOK) SetPayload(payload models.ProtectedEntityID) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *UndeleteSnapshotOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(200)
+	payload := o.Payload
+	if err := producer.Produce(rw, payload); err != nil {
+		panic(err) // let the recovery middleware deal with this
+	}
+}
+
+// UndeleteSnapshotBadRequestCode is the HTTP code returned for type UndeleteSnapshotBadRequest
+const UndeleteSnapshotBadRequestCode int = 400
+
+/*UndeleteSnapshotBadRequest Invalid protected entity ID
+
+swagger:response undeleteSnapshotBadRequest
+*/
+type UndeleteSnapshotBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewUndeleteSnapshotBadRequest creates UndeleteSnapshotBadRequest with default headers values
+func NewUndeleteSnapshotBadRequest() *UndeleteSnapshotBadRequest {
+
+	return &UndeleteSnapshotBadRequest{}
+}
+
+// WithPayload adds the payload to the undelete snapshot bad request response
+func (o *UndeleteSnapshotBadRequest) WithPayload(payload *models.Error) *UndeleteSnapshotBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the undelete snapshot bad request response
+func (o *UndeleteSnapshotBadRequest) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *UndeleteSnapshotBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// UndeleteSnapshotNotFoundCode is the HTTP code returned for type UndeleteSnapshotNotFound
+const UndeleteSnapshotNotFoundCode int = 404
+
+/*UndeleteSnapshotNotFound Service not found, the service does not support soft delete or the snapshot is not deleted
+
+swagger:response undeleteSnapshotNotFound
+*/
+type UndeleteSnapshotNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewUndeleteSnapshotNotFound creates UndeleteSnapshotNotFound with default headers values
+func NewUndeleteSnapshotNotFound() *UndeleteSnapshotNotFound {
+
+	return &UndeleteSnapshotNotFound{}
+}
+
+// WithPayload adds the payload to the undelete snapshot not found response
+func (o *UndeleteSnapshotNotFound) WithPayload(payload *models.Error) *UndeleteSnapshotNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the undelete snapshot not found response
+func (o *UndeleteSnapshotNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *UndeleteSnapshotNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// UndeleteSnapshotInternalServerErrorCode is the HTTP code returned for type UndeleteSnapshotInternalServerError
+const UndeleteSnapshotInternalServerErrorCode int = 500
+
+/*UndeleteSnapshotInternalServerError Undelete failed
+
+swagger:response undeleteSnapshotInternalServerError
+*/
+type UndeleteSnapshotInternalServerError struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewUndeleteSnapshotInternalServerError creates UndeleteSnapshotInternalServerError with default headers values
+func NewUndeleteSnapshotInternalServerError() *UndeleteSnapshotInternalServerError {
+
+	return &UndeleteSnapshotInternalServerError{}
+}
+
+// WithPayload adds the payload to the undelete snapshot internal server error response
+func (o *UndeleteSnapshotInternalServerError) WithPayload(payload *models.Error) *UndeleteSnapshotInternalServerError {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the undelete snapshot internal server error response
+func (o *UndeleteSnapshotInternalServerError) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *UndeleteSnapshotInternalServerError) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(500)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
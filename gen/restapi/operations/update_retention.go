@@ -0,0 +1,60 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+)
+
+// UpdateRetentionHandlerFunc turns a function with the right signature into a update retention handler
+type UpdateRetentionHandlerFunc func(UpdateRetentionParams) middleware.Responder
+
+// Handle executing the request and returning a response
+func (fn UpdateRetentionHandlerFunc) Handle(params UpdateRetentionParams) middleware.Responder {
+	return fn(params)
+}
+
+// UpdateRetentionHandler interface for that can handle valid update retention params
+type UpdateRetentionHandler interface {
+	Handle(UpdateRetentionParams) middleware.Responder
+}
+
+// NewUpdateRetention creates a new http.Handler for the update retention operation
+func NewUpdateRetention(ctx *middleware.Context, handler UpdateRetentionHandler) *UpdateRetention {
+	return &UpdateRetention{Context: ctx, Handler: handler}
+}
+
+/*UpdateRetention swagger:route PUT /astrolabe/{service}/{protectedEntityID}/retention updateRetention
+
+Extends the retention of a snapshot and/or places or releases a legal hold.
+Retention can only be extended, never shortened.
+
+
+*/
+type UpdateRetention struct {
+	Context *middleware.Context
+	Handler UpdateRetentionHandler
+}
+
+func (o *UpdateRetention) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	route, rCtx, _ := o.Context.RouteInfo(r)
+	if rCtx != nil {
+		r = rCtx
+	}
+	var Params = NewUpdateRetentionParams()
+
+	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+
+	res := o.Handler.Handle(Params) // actually handle the request
+
+	o.Context.Respond(rw, r, route.Produces, route, res)
+
+}
@@ -0,0 +1,190 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// UpdateRetentionOKCode is the HTTP code returned for type UpdateRetentionOK
+const UpdateRetentionOKCode int = 200
+
+/*UpdateRetentionOK Retention updated, returns the new retention
+
+swagger:response updateRetentionOK
+*/
+type UpdateRetentionOK struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Retention `json:"body,omitempty"`
+}
+
+// NewUpdateRetentionOK creates UpdateRetentionOK with default headers values
+func NewUpdateRetentionOK() *UpdateRetentionOK {
+
+	return &UpdateRetentionOK{}
+}
+
+// WithPayload adds the payload to the update retention o k response
+func (o *UpdateRetentionOK) WithPayload(payload *models.Retention) *UpdateRetentionOK {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the update retention o k response
+func (o *UpdateRetentionOK) SetPayload(payload *models.Retention) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *UpdateRetentionOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(200)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// UpdateRetentionBadRequestCode is the HTTP code returned for type UpdateRetentionBadRequest
+const UpdateRetentionBadRequestCode int = 400
+
+/*UpdateRetentionBadRequest Invalid protected entity ID or the retention cannot be shortened
+
+swagger:response updateRetentionBadRequest
+*/
+type UpdateRetentionBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewUpdateRetentionBadRequest creates UpdateRetentionBadRequest with default headers values
+func NewUpdateRetentionBadRequest() *UpdateRetentionBadRequest {
+
+	return &UpdateRetentionBadRequest{}
+}
+
+// WithPayload adds the payload to the update retention bad request response
+func (o *UpdateRetentionBadRequest) WithPayload(payload *models.Error) *UpdateRetentionBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the update retention bad request response
+func (o *UpdateRetentionBadRequest) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *UpdateRetentionBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// UpdateRetentionNotFoundCode is the HTTP code returned for type UpdateRetentionNotFound
+const UpdateRetentionNotFoundCode int = 404
+
+/*UpdateRetentionNotFound Service or snapshot not found, or the service does not support retention
+
+swagger:response updateRetentionNotFound
+*/
+type UpdateRetentionNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewUpdateRetentionNotFound creates UpdateRetentionNotFound with default headers values
+func NewUpdateRetentionNotFound() *UpdateRetentionNotFound {
+
+	return &UpdateRetentionNotFound{}
+}
+
+// WithPayload adds the payload to the update retention not found response
+func (o *UpdateRetentionNotFound) WithPayload(payload *models.Error) *UpdateRetentionNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the update retention not found response
+func (o *UpdateRetentionNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *UpdateRetentionNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// UpdateRetentionInternalServerErrorCode is the HTTP code returned for type UpdateRetentionInternalServerError
+const UpdateRetentionInternalServerErrorCode int = 500
+
+/*UpdateRetentionInternalServerError Failed to update retention
+
+swagger:response updateRetentionInternalServerError
+*/
+type UpdateRetentionInternalServerError struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewUpdateRetentionInternalServerError creates UpdateRetentionInternalServerError with default headers values
+func NewUpdateRetentionInternalServerError() *UpdateRetentionInternalServerError {
+
+	return &UpdateRetentionInternalServerError{}
+}
+
+// WithPayload adds the payload to the update retention internal server error response
+func (o *UpdateRetentionInternalServerError) WithPayload(payload *models.Error) *UpdateRetentionInternalServerError {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the update retention internal server error response
+func (o *UpdateRetentionInternalServerError) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *UpdateRetentionInternalServerError) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(500)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
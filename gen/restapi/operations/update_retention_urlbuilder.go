@@ -0,0 +1,107 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the generate command
+
+import (
+	"errors"
+	"net/url"
+	golangswaggerpaths "path"
+	"strings"
+)
+
+// UpdateRetentionURL generates an URL for the update retention operation
+type UpdateRetentionURL struct {
+	ProtectedEntityID string
+	Service           string
+
+	_basePath string
+	// avoid unkeyed usage
+	_ struct{}
+}
+
+// WithBasePath sets the base path for this url builder, only required when it's different from the
+// base path specified in the swagger spec.
+// When the value of the base path is an empty string
+func (o *UpdateRetentionURL) WithBasePath(bp string) *UpdateRetentionURL {
+	o.SetBasePath(bp)
+	return o
+}
+
+// SetBasePath sets the base path for this url builder, only required when it's different from the
+// base path specified in the swagger spec.
+// When the value of the base path is an empty string
+func (o *UpdateRetentionURL) SetBasePath(bp string) {
+	o._basePath = bp
+}
+
+// Build a url path and query string
+func (o *UpdateRetentionURL) Build() (*url.URL, error) {
+	var _result url.URL
+
+	var _path = "/astrolabe/{service}/{protectedEntityID}/retention"
+
+	protectedEntityID := o.ProtectedEntityID
+	if protectedEntityID != "" {
+		_path = strings.Replace(_path, "{protectedEntityID}", protectedEntityID, -1)
+	} else {
+		return nil, errors.New("protectedEntityId is required on UpdateRetentionURL")
+	}
+
+	service := o.Service
+	if service != "" {
+		_path = strings.Replace(_path, "{service}", service, -1)
+	} else {
+		return nil, errors.New("service is required on UpdateRetentionURL")
+	}
+
+	_basePath := o._basePath
+	if _basePath == "" {
+		_basePath = "/v1"
+	}
+	_result.Path = golangswaggerpaths.Join(_basePath, _path)
+
+	return &_result, nil
+}
+
+// Must is a helper function to panic when the url builder returns an error
+func (o *UpdateRetentionURL) Must(u *url.URL, err error) *url.URL {
+	if err != nil {
+		panic(err)
+	}
+	if u == nil {
+		panic("url can't be nil")
+	}
+	return u
+}
+
+// String returns the string representation of the path with query string
+func (o *UpdateRetentionURL) String() string {
+	return o.Must(o.Build()).String()
+}
+
+// BuildFull builds a full url with scheme, host, path and query string
+func (o *UpdateRetentionURL) BuildFull(scheme, host string) (*url.URL, error) {
+	if scheme == "" {
+		return nil, errors.New("scheme is required for a full url on UpdateRetentionURL")
+	}
+	if host == "" {
+		return nil, errors.New("host is required for a full url on UpdateRetentionURL")
+	}
+
+	base, err := o.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	base.Scheme = scheme
+	base.Host = host
+	return base, nil
+}
+
+// StringFull returns the string representation of a complete url
+func (o *UpdateRetentionURL) StringFull(scheme, host string) string {
+	return o.Must(o.BuildFull(scheme, host)).String()
+}
@@ -0,0 +1,60 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+)
+
+// WaitOnTaskNexusHandlerFunc turns a function with the right signature into a wait on task nexus handler
+type WaitOnTaskNexusHandlerFunc func(WaitOnTaskNexusParams) middleware.Responder
+
+// Handle executing the request and returning a response
+func (fn WaitOnTaskNexusHandlerFunc) Handle(params WaitOnTaskNexusParams) middleware.Responder {
+	return fn(params)
+}
+
+// WaitOnTaskNexusHandler interface for that can handle valid wait on task nexus params
+type WaitOnTaskNexusHandler interface {
+	Handle(WaitOnTaskNexusParams) middleware.Responder
+}
+
+// NewWaitOnTaskNexus creates a new http.Handler for the wait on task nexus operation
+func NewWaitOnTaskNexus(ctx *middleware.Context, handler WaitOnTaskNexusHandler) *WaitOnTaskNexus {
+	return &WaitOnTaskNexus{Context: ctx, Handler: handler}
+}
+
+/*WaitOnTaskNexus swagger:route GET /astrolabe/tasks/nexus/{taskNexusID} waitOnTaskNexus
+
+Waits for tasks associated with the nexus to finish.  Tasks started after the nexus was created are
+associated with it
+
+
+*/
+type WaitOnTaskNexus struct {
+	Context *middleware.Context
+	Handler WaitOnTaskNexusHandler
+}
+
+func (o *WaitOnTaskNexus) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	route, rCtx, _ := o.Context.RouteInfo(r)
+	if rCtx != nil {
+		r = rCtx
+	}
+	var Params = NewWaitOnTaskNexusParams()
+
+	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+
+	res := o.Handler.Handle(Params) // actually handle the request
+
+	o.Context.Respond(rw, r, route.Produces, route, res)
+
+}
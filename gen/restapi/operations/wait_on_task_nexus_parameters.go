@@ -16,18 +16,18 @@ import (
 	"github.com/go-openapi/validate"
 )
 
-// NewGetAstrolabeTasksNexusTaskNexusIDParams creates a new GetAstrolabeTasksNexusTaskNexusIDParams object
+// NewWaitOnTaskNexusParams creates a new WaitOnTaskNexusParams object
 // no default values defined in spec.
-func NewGetAstrolabeTasksNexusTaskNexusIDParams() GetAstrolabeTasksNexusTaskNexusIDParams {
+func NewWaitOnTaskNexusParams() WaitOnTaskNexusParams {
 
-	return GetAstrolabeTasksNexusTaskNexusIDParams{}
+	return WaitOnTaskNexusParams{}
 }
 
-// GetAstrolabeTasksNexusTaskNexusIDParams contains all the bound params for the get astrolabe tasks nexus task nexus ID operation
+// WaitOnTaskNexusParams contains all the bound params for the wait on task nexus operation
 // typically these are obtained from a http.Request
 //
-// swagger:parameters GetAstrolabeTasksNexusTaskNexusID
-type GetAstrolabeTasksNexusTaskNexusIDParams struct {
+// swagger:parameters waitOnTaskNexus
+type WaitOnTaskNexusParams struct {
 
 	// HTTP Request Object
 	HTTPRequest *http.Request `json:"-"`
@@ -56,8 +56,8 @@ type GetAstrolabeTasksNexusTaskNexusIDParams struct {
 // BindRequest both binds and validates a request, it assumes that complex things implement a Validatable(strfmt.Registry) error interface
 // for simple values it will use straight method calls.
 //
-// To ensure default values, the struct must have been initialized with NewGetAstrolabeTasksNexusTaskNexusIDParams() beforehand.
-func (o *GetAstrolabeTasksNexusTaskNexusIDParams) BindRequest(r *http.Request, route *middleware.MatchedRoute) error {
+// To ensure default values, the struct must have been initialized with NewWaitOnTaskNexusParams() beforehand.
+func (o *WaitOnTaskNexusParams) BindRequest(r *http.Request, route *middleware.MatchedRoute) error {
 	var res []error
 
 	o.HTTPRequest = r
@@ -86,7 +86,7 @@ func (o *GetAstrolabeTasksNexusTaskNexusIDParams) BindRequest(r *http.Request, r
 }
 
 // bindLastFinishedNS binds and validates parameter LastFinishedNS from query.
-func (o *GetAstrolabeTasksNexusTaskNexusIDParams) bindLastFinishedNS(rawData []string, hasKey bool, formats strfmt.Registry) error {
+func (o *WaitOnTaskNexusParams) bindLastFinishedNS(rawData []string, hasKey bool, formats strfmt.Registry) error {
 	if !hasKey {
 		return errors.Required("lastFinishedNS", "query")
 	}
@@ -111,7 +111,7 @@ func (o *GetAstrolabeTasksNexusTaskNexusIDParams) bindLastFinishedNS(rawData []s
 }
 
 // bindTaskNexusID binds and validates parameter TaskNexusID from path.
-func (o *GetAstrolabeTasksNexusTaskNexusIDParams) bindTaskNexusID(rawData []string, hasKey bool, formats strfmt.Registry) error {
+func (o *WaitOnTaskNexusParams) bindTaskNexusID(rawData []string, hasKey bool, formats strfmt.Registry) error {
 	var raw string
 	if len(rawData) > 0 {
 		raw = rawData[len(rawData)-1]
@@ -126,7 +126,7 @@ func (o *GetAstrolabeTasksNexusTaskNexusIDParams) bindTaskNexusID(rawData []stri
 }
 
 // bindWaitTime binds and validates parameter WaitTime from query.
-func (o *GetAstrolabeTasksNexusTaskNexusIDParams) bindWaitTime(rawData []string, hasKey bool, formats strfmt.Registry) error {
+func (o *WaitOnTaskNexusParams) bindWaitTime(rawData []string, hasKey bool, formats strfmt.Registry) error {
 	if !hasKey {
 		return errors.Required("waitTime", "query")
 	}
@@ -0,0 +1,146 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// WaitOnTaskNexusOKCode is the HTTP code returned for type WaitOnTaskNexusOK
+const WaitOnTaskNexusOKCode int = 200
+
+/*WaitOnTaskNexusOK 200 response
+
+swagger:response waitOnTaskNexusOK
+*/
+type WaitOnTaskNexusOK struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.TaskNexusResponse `json:"body,omitempty"`
+}
+
+// NewWaitOnTaskNexusOK creates WaitOnTaskNexusOK with default headers values
+func NewWaitOnTaskNexusOK() *WaitOnTaskNexusOK {
+
+	return &WaitOnTaskNexusOK{}
+}
+
+// WithPayload adds the payload to the wait on task nexus o k response
+func (o *WaitOnTaskNexusOK) WithPayload(payload *models.TaskNexusResponse) *WaitOnTaskNexusOK {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the wait on task nexus o k response
+func (o *WaitOnTaskNexusOK) SetPayload(payload *models.TaskNexusResponse) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *WaitOnTaskNexusOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(200)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// WaitOnTaskNexusBadRequestCode is the HTTP code returned for type WaitOnTaskNexusBadRequest
+const WaitOnTaskNexusBadRequestCode int = 400
+
+/*WaitOnTaskNexusBadRequest Invalid wait time or last finished time
+
+swagger:response waitOnTaskNexusBadRequest
+*/
+type WaitOnTaskNexusBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewWaitOnTaskNexusBadRequest creates WaitOnTaskNexusBadRequest with default headers values
+func NewWaitOnTaskNexusBadRequest() *WaitOnTaskNexusBadRequest {
+
+	return &WaitOnTaskNexusBadRequest{}
+}
+
+// WithPayload adds the payload to the wait on task nexus bad request response
+func (o *WaitOnTaskNexusBadRequest) WithPayload(payload *models.Error) *WaitOnTaskNexusBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the wait on task nexus bad request response
+func (o *WaitOnTaskNexusBadRequest) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *WaitOnTaskNexusBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// WaitOnTaskNexusNotFoundCode is the HTTP code returned for type WaitOnTaskNexusNotFound
+const WaitOnTaskNexusNotFoundCode int = 404
+
+/*WaitOnTaskNexusNotFound Task nexus not found
+
+swagger:response waitOnTaskNexusNotFound
+*/
+type WaitOnTaskNexusNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewWaitOnTaskNexusNotFound creates WaitOnTaskNexusNotFound with default headers values
+func NewWaitOnTaskNexusNotFound() *WaitOnTaskNexusNotFound {
+
+	return &WaitOnTaskNexusNotFound{}
+}
+
+// WithPayload adds the payload to the wait on task nexus not found response
+func (o *WaitOnTaskNexusNotFound) WithPayload(payload *models.Error) *WaitOnTaskNexusNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the wait on task nexus not found response
+func (o *WaitOnTaskNexusNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *WaitOnTaskNexusNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
@@ -14,8 +14,8 @@ import (
 	"github.com/go-openapi/swag"
 )
 
-// GetAstrolabeTasksNexusTaskNexusIDURL generates an URL for the get astrolabe tasks nexus task nexus ID operation
-type GetAstrolabeTasksNexusTaskNexusIDURL struct {
+// WaitOnTaskNexusURL generates an URL for the wait on task nexus operation
+type WaitOnTaskNexusURL struct {
 	TaskNexusID string
 
 	LastFinishedNS int64
@@ -29,7 +29,7 @@ type GetAstrolabeTasksNexusTaskNexusIDURL struct {
 // WithBasePath sets the base path for this url builder, only required when it's different from the
 // base path specified in the swagger spec.
 // When the value of the base path is an empty string
-func (o *GetAstrolabeTasksNexusTaskNexusIDURL) WithBasePath(bp string) *GetAstrolabeTasksNexusTaskNexusIDURL {
+func (o *WaitOnTaskNexusURL) WithBasePath(bp string) *WaitOnTaskNexusURL {
 	o.SetBasePath(bp)
 	return o
 }
@@ -37,12 +37,12 @@ func (o *GetAstrolabeTasksNexusTaskNexusIDURL) WithBasePath(bp string) *GetAstro
 // SetBasePath sets the base path for this url builder, only required when it's different from the
 // base path specified in the swagger spec.
 // When the value of the base path is an empty string
-func (o *GetAstrolabeTasksNexusTaskNexusIDURL) SetBasePath(bp string) {
+func (o *WaitOnTaskNexusURL) SetBasePath(bp string) {
 	o._basePath = bp
 }
 
 // Build a url path and query string
-func (o *GetAstrolabeTasksNexusTaskNexusIDURL) Build() (*url.URL, error) {
+func (o *WaitOnTaskNexusURL) Build() (*url.URL, error) {
 	var _result url.URL
 
 	var _path = "/astrolabe/tasks/nexus/{taskNexusID}"
@@ -51,7 +51,7 @@ func (o *GetAstrolabeTasksNexusTaskNexusIDURL) Build() (*url.URL, error) {
 	if taskNexusID != "" {
 		_path = strings.Replace(_path, "{taskNexusID}", taskNexusID, -1)
 	} else {
-		return nil, errors.New("taskNexusId is required on GetAstrolabeTasksNexusTaskNexusIDURL")
+		return nil, errors.New("taskNexusId is required on WaitOnTaskNexusURL")
 	}
 
 	_basePath := o._basePath
@@ -78,7 +78,7 @@ func (o *GetAstrolabeTasksNexusTaskNexusIDURL) Build() (*url.URL, error) {
 }
 
 // Must is a helper function to panic when the url builder returns an error
-func (o *GetAstrolabeTasksNexusTaskNexusIDURL) Must(u *url.URL, err error) *url.URL {
+func (o *WaitOnTaskNexusURL) Must(u *url.URL, err error) *url.URL {
 	if err != nil {
 		panic(err)
 	}
@@ -89,17 +89,17 @@ func (o *GetAstrolabeTasksNexusTaskNexusIDURL) Must(u *url.URL, err error) *url.
 }
 
 // String returns the string representation of the path with query string
-func (o *GetAstrolabeTasksNexusTaskNexusIDURL) String() string {
+func (o *WaitOnTaskNexusURL) String() string {
 	return o.Must(o.Build()).String()
 }
 
 // BuildFull builds a full url with scheme, host, path and query string
-func (o *GetAstrolabeTasksNexusTaskNexusIDURL) BuildFull(scheme, host string) (*url.URL, error) {
+func (o *WaitOnTaskNexusURL) BuildFull(scheme, host string) (*url.URL, error) {
 	if scheme == "" {
-		return nil, errors.New("scheme is required for a full url on GetAstrolabeTasksNexusTaskNexusIDURL")
+		return nil, errors.New("scheme is required for a full url on WaitOnTaskNexusURL")
 	}
 	if host == "" {
-		return nil, errors.New("host is required for a full url on GetAstrolabeTasksNexusTaskNexusIDURL")
+		return nil, errors.New("host is required for a full url on WaitOnTaskNexusURL")
 	}
 
 	base, err := o.Build()
@@ -113,6 +113,6 @@ func (o *GetAstrolabeTasksNexusTaskNexusIDURL) BuildFull(scheme, host string) (*
 }
 
 // StringFull returns the string representation of a complete url
-func (o *GetAstrolabeTasksNexusTaskNexusIDURL) StringFull(scheme, host string) string {
+func (o *WaitOnTaskNexusURL) StringFull(scheme, host string) string {
 	return o.Must(o.BuildFull(scheme, host)).String()
 }
@@ -0,0 +1,60 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+)
+
+// WatchTaskEventsHandlerFunc turns a function with the right signature into a watch task events handler
+type WatchTaskEventsHandlerFunc func(WatchTaskEventsParams) middleware.Responder
+
+// Handle executing the request and returning a response
+func (fn WatchTaskEventsHandlerFunc) Handle(params WatchTaskEventsParams) middleware.Responder {
+	return fn(params)
+}
+
+// WatchTaskEventsHandler interface for that can handle valid watch task events params
+type WatchTaskEventsHandler interface {
+	Handle(WatchTaskEventsParams) middleware.Responder
+}
+
+// NewWatchTaskEvents creates a new http.Handler for the watch task events operation
+func NewWatchTaskEvents(ctx *middleware.Context, handler WatchTaskEventsHandler) *WatchTaskEvents {
+	return &WatchTaskEvents{Context: ctx, Handler: handler}
+}
+
+/*WatchTaskEvents swagger:route GET /astrolabe/tasks/events watchTaskEvents
+
+Streams the status and progress changes of one task or of all tasks as Server-Sent Events.  When the stream
+starts, the current state of the tasks is sent, unless the client resumes with Last-Event-ID.
+
+
+*/
+type WatchTaskEvents struct {
+	Context *middleware.Context
+	Handler WatchTaskEventsHandler
+}
+
+func (o *WatchTaskEvents) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	route, rCtx, _ := o.Context.RouteInfo(r)
+	if rCtx != nil {
+		r = rCtx
+	}
+	var Params = NewWatchTaskEventsParams()
+
+	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+
+	res := o.Handler.Handle(Params) // actually handle the request
+
+	o.Context.Respond(rw, r, route.Produces, route, res)
+
+}
@@ -0,0 +1,105 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+)
+
+// NewWatchTaskEventsParams creates a new WatchTaskEventsParams object
+// no default values defined in spec.
+func NewWatchTaskEventsParams() WatchTaskEventsParams {
+
+	return WatchTaskEventsParams{}
+}
+
+// WatchTaskEventsParams contains all the bound params for the watch task events operation
+// typically these are obtained from a http.Request
+//
+// swagger:parameters watchTaskEvents
+type WatchTaskEventsParams struct {
+
+	// HTTP Request Object
+	HTTPRequest *http.Request `json:"-"`
+
+	/*The ID of the last event the client received.  The stream resumes with the events after it.  If those
+	events are no longer kept, the stream starts with the current state of the tasks.
+
+	  In: header
+	*/
+	LastEventID *string
+	/*Only stream the events of this task
+	  In: query
+	*/
+	TaskID *string
+}
+
+// BindRequest both binds and validates a request, it assumes that complex things implement a Validatable(strfmt.Registry) error interface
+// for simple values it will use straight method calls.
+//
+// To ensure default values, the struct must have been initialized with NewWatchTaskEventsParams() beforehand.
+func (o *WatchTaskEventsParams) BindRequest(r *http.Request, route *middleware.MatchedRoute) error {
+	var res []error
+
+	o.HTTPRequest = r
+
+	qs := runtime.Values(r.URL.Query())
+
+	if err := o.bindLastEventID(r.Header[http.CanonicalHeaderKey("Last-Event-ID")], true, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qTaskID, qhkTaskID, _ := qs.GetOK("taskID")
+	if err := o.bindTaskID(qTaskID, qhkTaskID, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+// bindLastEventID binds and validates parameter LastEventID from header.
+func (o *WatchTaskEventsParams) bindLastEventID(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.LastEventID = &raw
+
+	return nil
+}
+
+// bindTaskID binds and validates parameter TaskID from query.
+func (o *WatchTaskEventsParams) bindTaskID(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.TaskID = &raw
+
+	return nil
+}
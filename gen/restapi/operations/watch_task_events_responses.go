@@ -0,0 +1,104 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package operations
+
+// This file was generated by the swagger tool.
+// Editing this file might prove futile when you re-run the swagger generate command
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+// WatchTaskEventsOKCode is the HTTP code returned for type WatchTaskEventsOK
+const WatchTaskEventsOKCode int = 200
+
+/*WatchTaskEventsOK A stream of Server-Sent Events.  Each event has an ID, a type, status when a task starts or its status
+changes and progress when its progress, details, queue position or locks change, and the task info as
+JSON data.
+
+
+swagger:response watchTaskEventsOK
+*/
+type WatchTaskEventsOK struct {
+
+	/*
+	  In: Body
+	*/
+	Payload io.ReadCloser `json:"body,omitempty"`
+}
+
+// NewWatchTaskEventsOK creates WatchTaskEventsOK with default headers values
+func NewWatchTaskEventsOK() *WatchTaskEventsOK {
+
+	return &WatchTaskEventsOK{}
+}
+
+// WithPayload adds the payload to the watch task events o k response
+func (o *WatchTaskEventsOK) WithPayload(payload io.ReadCloser) *WatchTaskEventsOK {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the watch task events o k response
+func (o *WatchTaskEventsOK) SetPayload(payload io.ReadCloser) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *WatchTaskEventsOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(200)
+	payload := o.Payload
+	if err := producer.Produce(rw, payload); err != nil {
+		panic(err) // let the recovery middleware deal with this
+	}
+}
+
+// WatchTaskEventsNotFoundCode is the HTTP code returned for type WatchTaskEventsNotFound
+const WatchTaskEventsNotFoundCode int = 404
+
+/*WatchTaskEventsNotFound Task not found
+
+swagger:response watchTaskEventsNotFound
+*/
+type WatchTaskEventsNotFound struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewWatchTaskEventsNotFound creates WatchTaskEventsNotFound with default headers values
+func NewWatchTaskEventsNotFound() *WatchTaskEventsNotFound {
+
+	return &WatchTaskEventsNotFound{}
+}
+
+// WithPayload adds the payload to the watch task events not found response
+func (o *WatchTaskEventsNotFound) WithPayload(payload *models.Error) *WatchTaskEventsNotFound {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the watch task events not found response
+func (o *WatchTaskEventsNotFound) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *WatchTaskEventsNotFound) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(404)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
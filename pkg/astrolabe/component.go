@@ -0,0 +1,70 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+/*
+ComponentSpec identifies a component of a Protected Entity.  Server is the name of the astrolabe server that serves
+the component, it is empty if the component is served by the same server as the Protected Entity.  Server names are
+the names the servers are configured with in their servers.json and must be the same on every server.
+*/
+type ComponentSpec struct {
+	ID     ProtectedEntityID
+	Server string
+}
+
+/*
+NewComponentSpecs returns specs for components served by the same server as their Protected Entity
+*/
+func NewComponentSpecs(ids []ProtectedEntityID) []ComponentSpec {
+	specs := make([]ComponentSpec, len(ids))
+	for curID, id := range ids {
+		specs[curID] = ComponentSpec{ID: id}
+	}
+	return specs
+}
+
+/*
+ComponentResolver is implemented by ProtectedEntityManagers that can reach other astrolabe servers.  GetComponent
+returns the Protected Entity for spec from the server named by spec.Server, or from the manager itself if Server is
+empty or names the manager's own server.  GetServerTypeManager returns the type manager for peType on server in the
+same way, it is used to copy components back to their server.
+*/
+type ComponentResolver interface {
+	GetComponent(ctx context.Context, spec ComponentSpec) (ProtectedEntity, error)
+	GetServerTypeManager(server string, peType string) (ProtectedEntityTypeManager, error)
+}
+
+/*
+ResolveComponent returns the Protected Entity for spec.  Components on other servers can only be resolved if pem
+implements ComponentResolver.
+*/
+func ResolveComponent(ctx context.Context, pem ProtectedEntityManager, spec ComponentSpec) (ProtectedEntity, error) {
+	if resolver, ok := pem.(ComponentResolver); ok {
+		return resolver.GetComponent(ctx, spec)
+	}
+	if spec.Server != "" {
+		return nil, errors.Errorf("cannot resolve %s on server %s, other servers are not reachable", spec.ID.String(),
+			spec.Server)
+	}
+	return pem.GetProtectedEntity(ctx, spec.ID)
+}
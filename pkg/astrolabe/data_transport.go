@@ -110,6 +110,7 @@ const (
 	MDExt       = ".md"
 	CombinedExt = ".zip"
 	PEInfoExt   = ".peinfo"
+	ZipDataExt  = ".data"
 )
 
 func NewS3DataTransportForPEID(peid ProtectedEntityID, s3Config S3Config) (DataTransport, error) {
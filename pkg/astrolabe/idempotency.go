@@ -0,0 +1,48 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import "context"
+
+/*
+Mutating calls to an astrolabe server can carry an idempotency key chosen by the caller.  A server that has already
+completed a call with the same key returns the result of that call instead of running it again, so that a call that
+timed out can be retried without, for example, creating a second snapshot.  REST calls carry the key in the
+Idempotency-Key header and gRPC calls in the idempotency-key metadata.
+*/
+const (
+	IdempotencyKeyHeader   = "Idempotency-Key"
+	IdempotencyKeyMetadata = "idempotency-key"
+)
+
+type idempotencyKeyContextKey struct{}
+
+/*
+WithIdempotencyKey returns a context that makes the astrolabe clients send key with the mutating calls made with it.
+Use a new key for every operation and the same key for the retries of an operation.
+*/
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+/*
+IdempotencyKeyFromContext returns the idempotency key set with WithIdempotencyKey, "" if there is none
+*/
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key
+}
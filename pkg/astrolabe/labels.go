@@ -0,0 +1,240 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// Param group keys used to pass labels and annotations to Snapshot and Copy
+	LabelsParamKey      = "labels"
+	AnnotationsParamKey = "annotations"
+)
+
+/*
+ProtectedEntityTypeManagers that can evaluate label selectors natively (e.g. against an index) can implement this
+interface.  Callers should use GetProtectedEntitiesWithLabelSelector, which falls back to filtering on GetInfo
+for type managers that do not implement it.
+*/
+type LabelSelectorProtectedEntityTypeManager interface {
+	GetProtectedEntitiesWithLabelSelector(ctx context.Context, selector k8slabels.Selector) ([]ProtectedEntityID, error)
+}
+
+/*
+ProtectedEntities that can evaluate label selectors against their snapshots natively can implement this interface.
+Callers should use ListSnapshotsWithLabelSelector.
+*/
+type LabelSelectorProtectedEntity interface {
+	ListSnapshotsWithLabelSelector(ctx context.Context, selector k8slabels.Selector) ([]ProtectedEntitySnapshotID, error)
+}
+
+/*
+Parses a Kubernetes style label selector, e.g. "app=nginx,tier in (frontend,backend),!canary".  An empty string
+selects everything.
+*/
+func ParseLabelSelector(selector string) (k8slabels.Selector, error) {
+	if strings.TrimSpace(selector) == "" {
+		return k8slabels.Everything(), nil
+	}
+	parsed, err := k8slabels.Parse(selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid label selector %q", selector)
+	}
+	return parsed, nil
+}
+
+func MatchesLabelSelector(info ProtectedEntityInfo, selector k8slabels.Selector) bool {
+	if selector == nil || selector.Empty() {
+		return true
+	}
+	return selector.Matches(k8slabels.Set(info.GetLabels()))
+}
+
+/*
+Returns the labels passed in the LabelsParamKey param group.  Label keys and values are validated using the
+Kubernetes label syntax so that anything stored can later be selected on.  Returns nil if no labels were passed.
+*/
+func GetLabelsFromParams(params map[string]map[string]interface{}) (map[string]string, error) {
+	labels, err := getStringMapFromParams(params, LabelsParamKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateLabels(labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+/*
+LabelsNotSupportedError is returned by Snapshot when labels or annotations are passed to a Protected Entity that
+cannot store them with its snapshots, so that they are not silently dropped.
+*/
+type LabelsNotSupportedError struct {
+	ID ProtectedEntityID
+}
+
+func (this LabelsNotSupportedError) Error() string {
+	return fmt.Sprintf("%s cannot store labels or annotations with its snapshots", this.ID.String())
+}
+
+func IsLabelsNotSupportedError(err error) bool {
+	_, ok := errors.Cause(err).(LabelsNotSupportedError)
+	return ok
+}
+
+/*
+Returns a LabelsNotSupportedError if params passes labels or annotations.  Snapshot implementations that cannot store
+them call this before snapshotting.
+*/
+func CheckNoLabelParams(id ProtectedEntityID, params map[string]map[string]interface{}) error {
+	if len(params[LabelsParamKey]) > 0 || len(params[AnnotationsParamKey]) > 0 {
+		return LabelsNotSupportedError{ID: id}
+	}
+	return nil
+}
+
+/*
+Returns the annotations passed in the AnnotationsParamKey param group.  Annotation values are not interpreted.
+Returns nil if no annotations were passed.
+*/
+func GetAnnotationsFromParams(params map[string]map[string]interface{}) (map[string]string, error) {
+	return getStringMapFromParams(params, AnnotationsParamKey)
+}
+
+func getStringMapFromParams(params map[string]map[string]interface{}, key string) (map[string]string, error) {
+	group, ok := params[key]
+	if !ok || len(group) == 0 {
+		return nil, nil
+	}
+	retMap := make(map[string]string, len(group))
+	for curKey, curValue := range group {
+		switch value := curValue.(type) {
+		case string:
+			retMap[curKey] = value
+		case fmt.Stringer:
+			retMap[curKey] = value.String()
+		case bool, int, int32, int64, float32, float64:
+			retMap[curKey] = fmt.Sprint(value)
+		default:
+			return nil, errors.Errorf("value for %s %q is not a string", key, curKey)
+		}
+	}
+	return retMap, nil
+}
+
+func ValidateLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return errors.Errorf("invalid label key %q: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return errors.Errorf("invalid label value %q for key %q: %s", value, key, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+/*
+Returns a new map with the entries of base overridden by the entries in overrides.  Returns nil if both are empty so
+that infos without labels do not acquire an empty map.
+*/
+func MergeStringMaps(base map[string]string, overrides map[string]string) map[string]string {
+	if len(base) == 0 && len(overrides) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(overrides))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+	return merged
+}
+
+/*
+Returns the IDs of the protected entities managed by petm whose labels match selector.
+*/
+func GetProtectedEntitiesWithLabelSelector(ctx context.Context, petm ProtectedEntityTypeManager,
+	selector k8slabels.Selector) ([]ProtectedEntityID, error) {
+	if selector == nil || selector.Empty() {
+		return petm.GetProtectedEntities(ctx)
+	}
+	if lspetm, ok := petm.(LabelSelectorProtectedEntityTypeManager); ok {
+		return lspetm.GetProtectedEntitiesWithLabelSelector(ctx, selector)
+	}
+	peIDs, err := petm.GetProtectedEntities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	retIDs := make([]ProtectedEntityID, 0, len(peIDs))
+	for _, curPEID := range peIDs {
+		curPE, err := petm.GetProtectedEntity(ctx, curPEID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not retrieve protected entity %s", curPEID.String())
+		}
+		curInfo, err := curPE.GetInfo(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not retrieve info for %s", curPEID.String())
+		}
+		if MatchesLabelSelector(curInfo, selector) {
+			retIDs = append(retIDs, curPEID)
+		}
+	}
+	return retIDs, nil
+}
+
+/*
+Returns the snapshots of pe whose labels match selector.  petm is used to retrieve the info for each snapshot when pe
+cannot evaluate the selector itself.
+*/
+func ListSnapshotsWithLabelSelector(ctx context.Context, petm ProtectedEntityTypeManager, pe ProtectedEntity,
+	selector k8slabels.Selector) ([]ProtectedEntitySnapshotID, error) {
+	if selector == nil || selector.Empty() {
+		return pe.ListSnapshots(ctx)
+	}
+	if lspe, ok := pe.(LabelSelectorProtectedEntity); ok {
+		return lspe.ListSnapshotsWithLabelSelector(ctx, selector)
+	}
+	snapshotIDs, err := pe.ListSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	retIDs := make([]ProtectedEntitySnapshotID, 0, len(snapshotIDs))
+	for _, curSnapshotID := range snapshotIDs {
+		snapshotPEID := pe.GetID().IDWithSnapshot(curSnapshotID)
+		snapshotPE, err := petm.GetProtectedEntity(ctx, snapshotPEID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not retrieve snapshot %s", snapshotPEID.String())
+		}
+		snapshotInfo, err := snapshotPE.GetInfo(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not retrieve info for snapshot %s", snapshotPEID.String())
+		}
+		if MatchesLabelSelector(snapshotInfo, selector) {
+			retIDs = append(retIDs, curSnapshotID)
+		}
+	}
+	return retIDs, nil
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import (
+	"encoding/json"
+	"gotest.tools/assert"
+	"reflect"
+	"testing"
+)
+
+func TestLabelsJSON(t *testing.T) {
+	peID, err := NewProtectedEntityIDFromString("ivd:aa-bbb-cc:snap-1")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	peInfo := NewProtectedEntityInfoWithLabels(peID, "labelsTestJSON", []DataTransport{}, []DataTransport{},
+		[]DataTransport{}, []ProtectedEntityID{},
+		map[string]string{"app": "nginx", "tier": "frontend"},
+		map[string]string{"description": "nightly backup"})
+
+	jsonBuffer, err := json.Marshal(peInfo)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	unmarshalled := ProtectedEntityInfoImpl{}
+	err = json.Unmarshal(jsonBuffer, &unmarshalled)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, reflect.DeepEqual(peInfo.GetLabels(), unmarshalled.GetLabels()), "labels do not match")
+	assert.Assert(t, reflect.DeepEqual(peInfo.GetAnnotations(), unmarshalled.GetAnnotations()), "annotations do not match")
+}
+
+func TestLabelSelector(t *testing.T) {
+	peID := NewProtectedEntityID("ivd", "aa-bbb-cc")
+	peInfo := NewProtectedEntityInfoWithLabels(peID, "labelSelectorTest", nil, nil, nil, nil,
+		map[string]string{"app": "nginx", "tier": "frontend"}, nil)
+	noLabelsInfo := NewProtectedEntityInfo(peID, "noLabelsTest", nil, nil, nil, nil)
+
+	tests := []struct {
+		selector string
+		matches  bool
+		noLabels bool
+	}{
+		{"", true, true},
+		{"app=nginx", true, false},
+		{"app=nginx,tier=frontend", true, false},
+		{"app=nginx,tier!=frontend", false, false},
+		{"tier in (frontend,backend)", true, false},
+		{"!canary", true, true},
+		{"app", true, false},
+	}
+	for _, test := range tests {
+		selector, err := ParseLabelSelector(test.selector)
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		assert.Equal(t, test.matches, MatchesLabelSelector(peInfo, selector), test.selector)
+		assert.Equal(t, test.noLabels, MatchesLabelSelector(noLabelsInfo, selector), test.selector)
+	}
+
+	_, err := ParseLabelSelector("tier in (frontend")
+	assert.Assert(t, err != nil, "expected invalid selector to fail")
+}
+
+func TestLabelsFromParams(t *testing.T) {
+	params := map[string]map[string]interface{}{
+		LabelsParamKey: {
+			"app":     "nginx",
+			"release": 3,
+		},
+		AnnotationsParamKey: {
+			"note": "has spaces, which are fine in annotations",
+		},
+	}
+	labels, err := GetLabelsFromParams(params)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, reflect.DeepEqual(map[string]string{"app": "nginx", "release": "3"}, labels))
+	annotations, err := GetAnnotationsFromParams(params)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "has spaces, which are fine in annotations", annotations["note"])
+
+	params[LabelsParamKey]["bad"] = "has spaces"
+	_, err = GetLabelsFromParams(params)
+	assert.Assert(t, err != nil, "expected invalid label value to fail")
+
+	noLabels, err := GetLabelsFromParams(map[string]map[string]interface{}{})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, noLabels == nil)
+
+	merged := MergeStringMaps(map[string]string{"app": "nginx", "tier": "frontend"}, map[string]string{"tier": "backend"})
+	assert.Assert(t, reflect.DeepEqual(map[string]string{"app": "nginx", "tier": "backend"}, merged))
+	assert.Assert(t, MergeStringMaps(nil, map[string]string{}) == nil)
+
+	peid := NewProtectedEntityID("fs", "a")
+	err = CheckNoLabelParams(peid, params)
+	assert.Assert(t, IsLabelsNotSupportedError(err), "expected labels to be rejected")
+	err = CheckNoLabelParams(peid, map[string]map[string]interface{}{AnnotationsParamKey: {"note": "x"}})
+	assert.Assert(t, IsLabelsNotSupportedError(err), "expected annotations to be rejected")
+	assert.NilError(t, CheckNoLabelParams(peid, map[string]map[string]interface{}{"other": {"a": "b"}}))
+}
@@ -0,0 +1,356 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+)
+
+type ListOrderBy string
+
+const (
+	// IDs are ordered by their canonical string form
+	ListOrderByID ListOrderBy = "id"
+	// IDs are ordered by the name of the protected entity, then by ID
+	ListOrderByName ListOrderBy = "name"
+)
+
+/*
+ListOptions select a page of protected entity or snapshot IDs.  The zero value returns all of the IDs in ID order.
+*/
+type ListOptions struct {
+	// The maximum number of IDs to return, 0 returns all of them
+	MaxResults int
+	// The ContinuationToken of the previous page, empty for the first page
+	ContinuationToken string
+	// Only protected entities whose ID, without the type, starts with Prefix.  For snapshots, only snapshots whose
+	// snapshot ID starts with Prefix
+	Prefix string
+	// Only protected entities or snapshots whose name is Name
+	Name          string
+	LabelSelector k8slabels.Selector
+	// ListOrderByID if not set
+	OrderBy    ListOrderBy
+	Descending bool
+}
+
+func (this ListOptions) Validate() error {
+	if this.MaxResults < 0 {
+		return InvalidListOptionsError{Reason: "maxResults cannot be negative"}
+	}
+	switch this.OrderBy {
+	case "", ListOrderByID, ListOrderByName:
+	default:
+		return InvalidListOptionsError{Reason: fmt.Sprintf("cannot order by %q", this.OrderBy)}
+	}
+	return nil
+}
+
+func (this ListOptions) orderBy() ListOrderBy {
+	if this.OrderBy == "" {
+		return ListOrderByID
+	}
+	return this.OrderBy
+}
+
+type ProtectedEntityIDPage struct {
+	IDs []ProtectedEntityID
+	// Set if there are more IDs, pass it in ListOptions to retrieve the next page
+	ContinuationToken string
+}
+
+/*
+ProtectedEntityTypeManagers that can filter and page through their protected entities without enumerating all of
+them implement ProtectedEntityLister.  Callers should use GetProtectedEntitiesPage, which falls back to
+GetProtectedEntities and GetInfo for type managers that do not implement it.  Implementations that build the page
+from a full list can use PageListEntries.
+*/
+type ProtectedEntityLister interface {
+	GetProtectedEntitiesPage(ctx context.Context, options ListOptions) (ProtectedEntityIDPage, error)
+}
+
+/*
+ProtectedEntityTypeManagers that can page through the snapshots of a protected entity implement SnapshotLister.
+The page holds the IDs of the snapshots, including the protected entity ID.  Callers should use ListSnapshotsPage.
+*/
+type SnapshotLister interface {
+	ListSnapshotsPage(ctx context.Context, id ProtectedEntityID, options ListOptions) (ProtectedEntityIDPage, error)
+}
+
+/*
+InvalidListOptionsError is returned for options that fail validation and for continuation tokens that cannot be
+decoded or were issued for a different ordering.
+*/
+type InvalidListOptionsError struct {
+	Reason string
+}
+
+func (this InvalidListOptionsError) Error() string {
+	return "invalid list options: " + this.Reason
+}
+
+func IsInvalidListOptionsError(err error) bool {
+	_, ok := errors.Cause(err).(InvalidListOptionsError)
+	return ok
+}
+
+/*
+ListEntry is a protected entity or snapshot ID with the name it is ordered and filtered by.  Name only needs to be
+set when ordering or filtering by name.
+*/
+type ListEntry struct {
+	ID   ProtectedEntityID
+	Name string
+}
+
+/*
+listCursor is encoded in the continuation token.  It holds the sort key of the last ID returned and the ordering the
+key belongs to.
+*/
+type listCursor struct {
+	OrderBy    ListOrderBy `json:"orderBy"`
+	Descending bool        `json:"descending,omitempty"`
+	Name       string      `json:"name,omitempty"`
+	ID         string      `json:"id"`
+}
+
+func encodeListCursor(cursor listCursor) string {
+	cursorBytes, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(cursorBytes)
+}
+
+func decodeListCursor(token string, options ListOptions) (listCursor, error) {
+	cursor := listCursor{}
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(cursorBytes, &cursor)
+	}
+	if err != nil || cursor.ID == "" {
+		return listCursor{}, InvalidListOptionsError{Reason: "malformed continuation token"}
+	}
+	if cursor.OrderBy != options.orderBy() || cursor.Descending != options.Descending {
+		return listCursor{}, InvalidListOptionsError{Reason: "the continuation token is for a different ordering"}
+	}
+	return cursor, nil
+}
+
+/*
+ContinuationTokenAfter returns a continuation token that continues an ascending ID ordered list after id.  It
+supports callers that page with the ID of the last protected entity, such as the idsAfter parameter of the REST API.
+*/
+func ContinuationTokenAfter(id string) string {
+	return encodeListCursor(listCursor{
+		OrderBy: ListOrderByID,
+		ID:      id,
+	})
+}
+
+/*
+Compares entry to the cursor position in ascending order
+*/
+func compareToCursor(entry ListEntry, cursor listCursor) int {
+	if cursor.OrderBy == ListOrderByName {
+		if entry.Name != cursor.Name {
+			return strings.Compare(entry.Name, cursor.Name)
+		}
+	}
+	return strings.Compare(entry.ID.String(), cursor.ID)
+}
+
+/*
+Returns true if id passes the prefix filter of options
+*/
+func matchesPrefix(id ProtectedEntityID, prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	if id.HasSnapshot() {
+		return strings.HasPrefix(id.GetSnapshotID().String(), prefix)
+	}
+	return strings.HasPrefix(id.GetID(), prefix)
+}
+
+/*
+PageListEntries applies the prefix and name filters, the ordering and the continuation token in options to entries
+and returns up to MaxResults IDs.  entries must already match the label selector.
+*/
+func PageListEntries(entries []ListEntry, options ListOptions) (ProtectedEntityIDPage, error) {
+	if err := options.Validate(); err != nil {
+		return ProtectedEntityIDPage{}, err
+	}
+	var cursor *listCursor
+	if options.ContinuationToken != "" {
+		decoded, err := decodeListCursor(options.ContinuationToken, options)
+		if err != nil {
+			return ProtectedEntityIDPage{}, err
+		}
+		cursor = &decoded
+	}
+	orderBy := options.orderBy()
+	selected := make([]ListEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !matchesPrefix(entry.ID, options.Prefix) || (options.Name != "" && entry.Name != options.Name) {
+			continue
+		}
+		if cursor != nil {
+			comparison := compareToCursor(entry, *cursor)
+			if (!options.Descending && comparison <= 0) || (options.Descending && comparison >= 0) {
+				continue
+			}
+		}
+		selected = append(selected, entry)
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		if options.Descending {
+			i, j = j, i
+		}
+		if orderBy == ListOrderByName && selected[i].Name != selected[j].Name {
+			return selected[i].Name < selected[j].Name
+		}
+		return selected[i].ID.String() < selected[j].ID.String()
+	})
+	page := ProtectedEntityIDPage{
+		IDs: []ProtectedEntityID{},
+	}
+	if options.MaxResults > 0 && len(selected) > options.MaxResults {
+		selected = selected[:options.MaxResults]
+		last := selected[len(selected)-1]
+		nextCursor := listCursor{
+			OrderBy:    orderBy,
+			Descending: options.Descending,
+			ID:         last.ID.String(),
+		}
+		if orderBy == ListOrderByName {
+			nextCursor.Name = last.Name
+		}
+		page.ContinuationToken = encodeListCursor(nextCursor)
+	}
+	for _, entry := range selected {
+		page.IDs = append(page.IDs, entry.ID)
+	}
+	return page, nil
+}
+
+/*
+GetListEntries returns the entries for the ids that pass the prefix filter and label selector of options.  The info
+of each ID is retrieved from petm if the label selector or the name filter or ordering needs it.
+*/
+func GetListEntries(ctx context.Context, petm ProtectedEntityTypeManager, ids []ProtectedEntityID,
+	options ListOptions) ([]ListEntry, error) {
+	needInfo := options.Name != "" || options.orderBy() == ListOrderByName ||
+		(options.LabelSelector != nil && !options.LabelSelector.Empty())
+	entries := make([]ListEntry, 0, len(ids))
+	for _, curID := range ids {
+		if !matchesPrefix(curID, options.Prefix) {
+			continue
+		}
+		entry := ListEntry{
+			ID: curID,
+		}
+		if needInfo {
+			curPE, err := petm.GetProtectedEntity(ctx, curID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not retrieve protected entity %s", curID.String())
+			}
+			curInfo, err := curPE.GetInfo(ctx)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not retrieve info for %s", curID.String())
+			}
+			if !MatchesLabelSelector(curInfo, options.LabelSelector) {
+				continue
+			}
+			entry.Name = curInfo.GetName()
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+/*
+GetProtectedEntitiesPage returns a page of the IDs of the protected entities managed by petm.  Type managers that
+implement ProtectedEntityLister build the page themselves, for others all of the IDs are retrieved and filtered.
+*/
+func GetProtectedEntitiesPage(ctx context.Context, petm ProtectedEntityTypeManager,
+	options ListOptions) (ProtectedEntityIDPage, error) {
+	if err := options.Validate(); err != nil {
+		return ProtectedEntityIDPage{}, err
+	}
+	if lister, ok := UnwrapProtectedEntityTypeManager(petm).(ProtectedEntityLister); ok {
+		return lister.GetProtectedEntitiesPage(ctx, options)
+	}
+	var peids []ProtectedEntityID
+	var err error
+	if lspetm, ok := UnwrapProtectedEntityTypeManager(petm).(LabelSelectorProtectedEntityTypeManager); ok &&
+		options.LabelSelector != nil && !options.LabelSelector.Empty() {
+		peids, err = lspetm.GetProtectedEntitiesWithLabelSelector(ctx, options.LabelSelector)
+		// The type manager has applied the selector
+		options.LabelSelector = nil
+	} else {
+		peids, err = petm.GetProtectedEntities(ctx)
+	}
+	if err != nil {
+		return ProtectedEntityIDPage{}, err
+	}
+	entries, err := GetListEntries(ctx, petm, peids, options)
+	if err != nil {
+		return ProtectedEntityIDPage{}, err
+	}
+	return PageListEntries(entries, options)
+}
+
+/*
+ListSnapshotsPage returns a page of the IDs of the snapshots of pe, including the protected entity ID.  petm is used
+to retrieve the info for each snapshot when the options need it.
+*/
+func ListSnapshotsPage(ctx context.Context, petm ProtectedEntityTypeManager, pe ProtectedEntity,
+	options ListOptions) (ProtectedEntityIDPage, error) {
+	if err := options.Validate(); err != nil {
+		return ProtectedEntityIDPage{}, err
+	}
+	if lister, ok := UnwrapProtectedEntityTypeManager(petm).(SnapshotLister); ok {
+		return lister.ListSnapshotsPage(ctx, pe.GetID(), options)
+	}
+	var snapshotIDs []ProtectedEntitySnapshotID
+	var err error
+	if lspe, ok := pe.(LabelSelectorProtectedEntity); ok && options.LabelSelector != nil &&
+		!options.LabelSelector.Empty() {
+		snapshotIDs, err = lspe.ListSnapshotsWithLabelSelector(ctx, options.LabelSelector)
+		options.LabelSelector = nil
+	} else {
+		snapshotIDs, err = pe.ListSnapshots(ctx)
+	}
+	if err != nil {
+		return ProtectedEntityIDPage{}, err
+	}
+	snapshotPEIDs := make([]ProtectedEntityID, len(snapshotIDs))
+	for snapshotNum, snapshotID := range snapshotIDs {
+		snapshotPEIDs[snapshotNum] = pe.GetID().IDWithSnapshot(snapshotID)
+	}
+	entries, err := GetListEntries(ctx, petm, snapshotPEIDs, options)
+	if err != nil {
+		return ProtectedEntityIDPage{}, err
+	}
+	return PageListEntries(entries, options)
+}
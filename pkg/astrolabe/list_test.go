@@ -0,0 +1,100 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import (
+	"gotest.tools/assert"
+	"testing"
+)
+
+func listEntryIDs(page ProtectedEntityIDPage) []string {
+	ids := []string{}
+	for _, id := range page.IDs {
+		ids = append(ids, id.GetID())
+	}
+	return ids
+}
+
+/*
+Pages through entries with options and returns the IDs of all of the pages
+*/
+func pageAll(t *testing.T, entries []ListEntry, options ListOptions) [][]string {
+	pages := [][]string{}
+	for {
+		page, err := PageListEntries(entries, options)
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		pages = append(pages, listEntryIDs(page))
+		if page.ContinuationToken == "" {
+			return pages
+		}
+		options.ContinuationToken = page.ContinuationToken
+	}
+}
+
+func TestPageListEntries(t *testing.T) {
+	entries := []ListEntry{
+		{ID: NewProtectedEntityID("ivd", "c"), Name: "disk-1"},
+		{ID: NewProtectedEntityID("ivd", "a"), Name: "disk-2"},
+		{ID: NewProtectedEntityID("ivd", "b2"), Name: "disk-1"},
+		{ID: NewProtectedEntityID("ivd", "b1"), Name: "other"},
+	}
+	assert.DeepEqual(t, [][]string{{"a", "b1", "b2", "c"}}, pageAll(t, entries, ListOptions{}))
+	assert.DeepEqual(t, [][]string{{"a", "b1"}, {"b2", "c"}}, pageAll(t, entries, ListOptions{MaxResults: 2}))
+	assert.DeepEqual(t, [][]string{{"c", "b2", "b1"}, {"a"}}, pageAll(t, entries, ListOptions{
+		MaxResults: 3,
+		Descending: true,
+	}))
+	assert.DeepEqual(t, [][]string{{"b2"}, {"c"}, {"a"}, {"b1"}}, pageAll(t, entries, ListOptions{
+		MaxResults: 1,
+		OrderBy:    ListOrderByName,
+	}))
+	assert.DeepEqual(t, [][]string{{"b1", "b2"}}, pageAll(t, entries, ListOptions{Prefix: "b"}))
+	assert.DeepEqual(t, [][]string{{"b2"}, {"c"}}, pageAll(t, entries, ListOptions{
+		MaxResults: 1,
+		Name:       "disk-1",
+	}))
+
+	// The pages continue after the last ID returned even if it has been removed
+	page, err := PageListEntries(entries, ListOptions{MaxResults: 2})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	page, err = PageListEntries(entries[:2], ListOptions{MaxResults: 2, ContinuationToken: page.ContinuationToken})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{"c"}, listEntryIDs(page))
+	page, err = PageListEntries(entries, ListOptions{ContinuationToken: ContinuationTokenAfter("ivd:b1")})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{"b2", "c"}, listEntryIDs(page))
+
+	_, err = PageListEntries(entries, ListOptions{ContinuationToken: "not a token"})
+	assert.Assert(t, IsInvalidListOptionsError(err), "expected an invalid list options error")
+	_, err = PageListEntries(entries, ListOptions{
+		OrderBy:           ListOrderByName,
+		ContinuationToken: ContinuationTokenAfter("ivd:b1"),
+	})
+	assert.ErrorContains(t, err, "different ordering")
+	_, err = PageListEntries(entries, ListOptions{OrderBy: "size"})
+	assert.Assert(t, IsInvalidListOptionsError(err), "expected an invalid list options error")
+	_, err = PageListEntries(entries, ListOptions{MaxResults: -1})
+	assert.Assert(t, IsInvalidListOptionsError(err), "expected an invalid list options error")
+}
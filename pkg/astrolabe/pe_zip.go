@@ -20,9 +20,14 @@ import (
 	"archive/zip"
 	"context"
 	"encoding/json"
+	"github.com/pkg/errors"
 	"io"
 )
 
+/*
+ZipProtectedEntity writes the combined stream for entity in the zip file format described in the SPEC.  The zip holds the
+peinfo, the metadata and data if the entity has them and a zip for each of its components under components/.
+*/
 func ZipProtectedEntity(ctx context.Context, entity ProtectedEntity, writer io.Writer) error {
 	zipWriter := zip.NewWriter(writer)
 	peInfo, err := entity.GetInfo(ctx)
@@ -33,7 +38,7 @@ func ZipProtectedEntity(ctx context.Context, entity ProtectedEntity, writer io.W
 	if err != nil {
 		return err
 	}
-	peInfoWriter, err := zipWriter.Create(entity.GetID().String() + ".peinfo")
+	peInfoWriter, err := zipWriter.Create(entity.GetID().String() + PEInfoExt)
 	if err != nil {
 		return err
 	}
@@ -41,5 +46,59 @@ func ZipProtectedEntity(ctx context.Context, entity ProtectedEntity, writer io.W
 	if err != nil {
 		return err
 	}
+	mdReader, err := entity.GetMetadataReader(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "could not get metadata reader for %s", entity.GetID().String())
+	}
+	err = zipStream(zipWriter, entity.GetID().String()+MDExt, mdReader)
+	if err != nil {
+		return err
+	}
+	dataReader, err := entity.GetDataReader(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "could not get data reader for %s", entity.GetID().String())
+	}
+	err = zipStream(zipWriter, entity.GetID().String()+ZipDataExt, dataReader)
+	if err != nil {
+		return err
+	}
+	components, err := entity.GetComponents(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "could not get components for %s", entity.GetID().String())
+	}
+	for _, component := range components {
+		// Component zips are stored uncompressed so that they can be read in place
+		componentWriter, err := zipWriter.CreateHeader(&zip.FileHeader{
+			Name:   "components/" + component.GetID().String() + CombinedExt,
+			Method: zip.Store,
+		})
+		if err != nil {
+			return err
+		}
+		err = ZipProtectedEntity(ctx, component, componentWriter)
+		if err != nil {
+			return err
+		}
+	}
+	return zipWriter.Close()
+}
+
+/*
+zipStream copies reader into a new entry in zipWriter.  Entities without the stream return a nil reader and no entry
+is written.
+*/
+func zipStream(zipWriter *zip.Writer, name string, reader io.ReadCloser) error {
+	if reader == nil {
+		return nil
+	}
+	defer reader.Close()
+	entryWriter, err := zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entryWriter, reader)
+	if err != nil {
+		return errors.Wrapf(err, "could not write %s", name)
+	}
 	return nil
 }
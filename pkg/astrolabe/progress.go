@@ -0,0 +1,81 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import (
+	"context"
+	"io"
+)
+
+type progressReporterContextKey struct{}
+
+/*
+WithProgressReporter returns a context that makes the copies run with it report their progress, in percent, to report
+*/
+func WithProgressReporter(ctx context.Context, report func(progress float64)) context.Context {
+	return context.WithValue(ctx, progressReporterContextKey{}, report)
+}
+
+/*
+ReportProgress reports progress, in percent, to the reporter set with WithProgressReporter.  It does nothing if there
+is none.
+*/
+func ReportProgress(ctx context.Context, progress float64) {
+	if report, ok := ctx.Value(progressReporterContextKey{}).(func(progress float64)); ok {
+		report(progress)
+	}
+}
+
+/*
+NewProgressReader returns a reader that reports the share of the stream that has been read from reader with
+ReportProgress.  The length of the stream comes from StreamSize, reader is returned as is if it is not known or ctx has
+no progress reporter.
+*/
+func NewProgressReader(ctx context.Context, reader io.Reader) io.Reader {
+	if _, ok := ctx.Value(progressReporterContextKey{}).(func(progress float64)); !ok {
+		return reader
+	}
+	size, err := StreamSize(reader)
+	if err != nil || size < 1 {
+		return reader
+	}
+	return &progressReader{
+		ctx:    ctx,
+		reader: reader,
+		size:   size,
+	}
+}
+
+type progressReader struct {
+	ctx    context.Context
+	reader io.Reader
+	size   int64
+	read   int64
+}
+
+func (this *progressReader) Read(p []byte) (int, error) {
+	n, err := this.reader.Read(p)
+	if n > 0 {
+		this.read += int64(n)
+		progress := float64(this.read) * 100 / float64(this.size)
+		if progress > 100 {
+			progress = 100
+		}
+		ReportProgress(this.ctx, progress)
+	}
+	return n, err
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import (
+	"bytes"
+	"context"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"testing"
+)
+
+func TestProgressReader(t *testing.T) {
+	data := make([]byte, 1000)
+	// Without a reporter the reader is not wrapped
+	reader := bytes.NewReader(data)
+	assert.Equal(t, reader, NewProgressReader(context.Background(), reader))
+
+	reported := []float64{}
+	ctx := WithProgressReporter(context.Background(), func(progress float64) {
+		reported = append(reported, progress)
+	})
+	progressReader := NewProgressReader(ctx, NewSizedReadCloser(ioutil.NopCloser(bytes.NewReader(data)),
+		int64(len(data))))
+	buf := make([]byte, 250)
+	_, err := progressReader.Read(buf)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []float64{25}, reported)
+	_, err = ioutil.ReadAll(progressReader)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, float64(100), reported[len(reported)-1])
+
+	// Streams of unknown length do not report progress
+	unsized := ioutil.NopCloser(bytes.NewReader(data))
+	assert.Equal(t, unsized, NewProgressReader(ctx, unsized))
+}
@@ -19,19 +19,44 @@ package astrolabe
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"log"
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/vmware-tanzu/astrolabe/gen/models"
 )
 
 const (
 	peIDSep = "/"
+	// Separates the type from the instance name in the service name of a named instance, e.g. ivd@vc-east
+	InstanceSep = "@"
 )
 
+/*
+NewServiceName returns the name of the instance of peType named instance.  An empty instance is the unnamed instance,
+whose service name is peType.
+*/
+func NewServiceName(peType string, instance string) string {
+	if instance == "" {
+		return peType
+	}
+	return peType + InstanceSep + instance
+}
+
+/*
+SplitServiceName returns the type and the instance name of serviceName.  The instance name is empty for a service that
+is not a named instance.
+*/
+func SplitServiceName(serviceName string) (peType string, instance string) {
+	sep := strings.Index(serviceName, InstanceSep)
+	if sep < 0 {
+		return serviceName, ""
+	}
+	return serviceName[:sep], serviceName[sep+len(InstanceSep):]
+}
+
 type ProtectedEntityID struct {
 	peType     string
 	id         string
@@ -111,6 +136,15 @@ func (this ProtectedEntityID) GetPeType() string {
 	return this.peType
 }
 
+/*
+GetBasePeType returns the type of the Protected Entity without the instance name, for example ivd for
+ivd@vc-east:<id>
+*/
+func (this ProtectedEntityID) GetBasePeType() string {
+	peType, _ := SplitServiceName(this.peType)
+	return peType
+}
+
 func (this ProtectedEntityID) GetSnapshotID() ProtectedEntitySnapshotID {
 	return this.snapshotID
 
@@ -211,3 +245,57 @@ type ProtectedEntity interface {
 	Overwrite(ctx context.Context, sourcePE ProtectedEntity, params map[string]map[string]interface{},
 		overwriteComponents bool) error
 }
+
+/*
+SizedReader is implemented by data and metadata readers that know the length of their stream without reading it
+through, for example readers assembled from stored segments.  Size returns the total length of the stream.
+*/
+type SizedReader interface {
+	io.Reader
+	Size() int64
+}
+
+type sizedReadCloser struct {
+	io.ReadCloser
+	size int64
+}
+
+func (this sizedReadCloser) Size() int64 {
+	return this.size
+}
+
+/*
+NewSizedReadCloser returns reader with the length of its stream attached, see SizedReader
+*/
+func NewSizedReadCloser(reader io.ReadCloser, size int64) io.ReadCloser {
+	return sizedReadCloser{
+		ReadCloser: reader,
+		size:       size,
+	}
+}
+
+/*
+StreamSize returns the length of the stream read by reader without reading it, or -1 if it is not known.  Seekable
+readers are sized by seeking to the end and back.
+*/
+func StreamSize(reader io.Reader) (int64, error) {
+	if sized, ok := reader.(SizedReader); ok {
+		return sized.Size(), nil
+	}
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return -1, nil
+	}
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1, errors.Wrap(err, "could not get the stream offset")
+	}
+	size, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return -1, errors.Wrap(err, "could not seek to the end of the stream")
+	}
+	if _, err = seeker.Seek(current, io.SeekStart); err != nil {
+		return -1, errors.Wrap(err, "could not seek back in the stream")
+	}
+	return size, nil
+}
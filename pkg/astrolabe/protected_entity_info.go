@@ -31,6 +31,9 @@ type ProtectedEntityInfo interface {
 	GetMetadataTransports() []DataTransport
 	GetCombinedTransports() []DataTransport
 	GetComponentIDs() []ProtectedEntityID
+	GetComponentSpecs() []ComponentSpec
+	GetLabels() map[string]string
+	GetAnnotations() map[string]string
 	GetModelProtectedEntityInfo() models.ProtectedEntityInfo
 }
 
@@ -41,6 +44,10 @@ type ProtectedEntityInfoImpl struct {
 	metadataTransports []DataTransport
 	combinedTransports []DataTransport
 	componentIDs       []ProtectedEntityID
+	labels             map[string]string
+	annotations        map[string]string
+	// The servers of the components, in the order of componentIDs.  nil if all of the components are local
+	componentServers []string
 }
 
 func NewProtectedEntityInfo(id ProtectedEntityID, name string, dataTransports []DataTransport, metadataTransports []DataTransport,
@@ -55,6 +62,66 @@ func NewProtectedEntityInfo(id ProtectedEntityID, name string, dataTransports []
 	}
 }
 
+/*
+Creates a ProtectedEntityInfo that carries labels and annotations.  Labels are used for selection
+(see ParseLabelSelector), annotations are free-form and are not interpreted by Astrolabe.
+*/
+func NewProtectedEntityInfoWithLabels(id ProtectedEntityID, name string, dataTransports []DataTransport, metadataTransports []DataTransport,
+	combinedTransports []DataTransport, componentIDs []ProtectedEntityID, labels map[string]string,
+	annotations map[string]string) ProtectedEntityInfo {
+	return ProtectedEntityInfoImpl{
+		id:                 id,
+		name:               name,
+		dataTransports:     dataTransports,
+		metadataTransports: metadataTransports,
+		combinedTransports: combinedTransports,
+		componentIDs:       componentIDs,
+		labels:             labels,
+		annotations:        annotations,
+	}
+}
+
+/*
+Creates a ProtectedEntityInfo whose components may be served by other astrolabe servers (see ComponentSpec)
+*/
+func NewProtectedEntityInfoWithComponentSpecs(id ProtectedEntityID, name string, dataTransports []DataTransport,
+	metadataTransports []DataTransport, combinedTransports []DataTransport, componentSpecs []ComponentSpec,
+	labels map[string]string, annotations map[string]string) ProtectedEntityInfo {
+	componentIDs, componentServers := splitComponentSpecs(componentSpecs)
+	return ProtectedEntityInfoImpl{
+		id:                 id,
+		name:               name,
+		dataTransports:     dataTransports,
+		metadataTransports: metadataTransports,
+		combinedTransports: combinedTransports,
+		componentIDs:       componentIDs,
+		componentServers:   componentServers,
+		labels:             labels,
+		annotations:        annotations,
+	}
+}
+
+/*
+splitComponentSpecs returns the IDs of the components and their servers.  The servers are nil if all of the
+components are local.
+*/
+func splitComponentSpecs(componentSpecs []ComponentSpec) ([]ProtectedEntityID, []string) {
+	componentIDs := make([]ProtectedEntityID, len(componentSpecs))
+	var componentServers []string
+	for curComponentNum, curComponentSpec := range componentSpecs {
+		componentIDs[curComponentNum] = curComponentSpec.ID
+		if curComponentSpec.Server != "" && componentServers == nil {
+			componentServers = make([]string, len(componentSpecs))
+		}
+	}
+	if componentServers != nil {
+		for curComponentNum, curComponentSpec := range componentSpecs {
+			componentServers[curComponentNum] = curComponentSpec.Server
+		}
+	}
+	return componentIDs, componentServers
+}
+
 func NewProtectedEntityInfoFromModel(mpei *models.ProtectedEntityInfo) (ProtectedEntityInfo, error) {
 	pei := ProtectedEntityInfoImpl{}
 	err := pei.FillFromModel(mpei)
@@ -101,10 +168,10 @@ func (this ProtectedEntityInfoImpl) MarshalJSON() ([]byte, error) {
 
 func (this ProtectedEntityInfoImpl) GetModelProtectedEntityInfo() models.ProtectedEntityInfo {
 	componentSpecs := make([]*models.ComponentSpec, len(this.componentIDs))
-	for curComponentNum, curComponentID := range this.componentIDs {
+	for curComponentNum, curComponentSpec := range this.GetComponentSpecs() {
 		componentSpecs[curComponentNum] = &models.ComponentSpec{
-			ID:     models.ProtectedEntityID(curComponentID.String()),
-			Server: "", // TODO - convert to component specs throughout
+			ID:     models.ProtectedEntityID(curComponentSpec.ID.String()),
+			Server: curComponentSpec.Server,
 		}
 	}
 	jsonStruct := models.ProtectedEntityInfo{
@@ -114,6 +181,8 @@ func (this ProtectedEntityInfoImpl) GetModelProtectedEntityInfo() models.Protect
 		MetadataTransports: convertToModelTransports(this.metadataTransports),
 		CombinedTransports: convertToModelTransports(this.combinedTransports),
 		ComponentSpecs:     componentSpecs,
+		Labels:             this.labels,
+		Annotations:        this.annotations,
 	}
 	return jsonStruct
 }
@@ -167,15 +236,20 @@ func (this *ProtectedEntityInfoImpl) FillFromModel(jsonStruct *models.ProtectedE
 	this.dataTransports = convertToTransports(jsonStruct.DataTransports)
 	this.metadataTransports = convertToTransports(jsonStruct.MetadataTransports)
 	this.combinedTransports = convertToTransports(jsonStruct.CombinedTransports)
-	componentIDs := make([]ProtectedEntityID, len(jsonStruct.ComponentSpecs))
-	for curComponentNum, curComponentSpec := range componentIDs {
-		componentID, err := NewProtectedEntityIDFromString(curComponentSpec.id)
+	componentSpecs := make([]ComponentSpec, len(jsonStruct.ComponentSpecs))
+	for curComponentNum, curComponentSpec := range jsonStruct.ComponentSpecs {
+		componentID, err := NewProtectedEntityIDFromString(string(curComponentSpec.ID))
 		if err != nil {
 			return err
 		}
-		componentIDs[curComponentNum] = componentID
+		componentSpecs[curComponentNum] = ComponentSpec{
+			ID:     componentID,
+			Server: curComponentSpec.Server,
+		}
 	}
-	this.componentIDs = componentIDs
+	this.componentIDs, this.componentServers = splitComponentSpecs(componentSpecs)
+	this.labels = jsonStruct.Labels
+	this.annotations = jsonStruct.Annotations
 	return nil
 }
 
@@ -194,3 +268,21 @@ func (this ProtectedEntityInfoImpl) GetCombinedTransports() []DataTransport {
 func (this ProtectedEntityInfoImpl) GetComponentIDs() []ProtectedEntityID {
 	return this.componentIDs
 }
+
+func (this ProtectedEntityInfoImpl) GetComponentSpecs() []ComponentSpec {
+	componentSpecs := NewComponentSpecs(this.componentIDs)
+	if this.componentServers != nil {
+		for curComponentNum := range componentSpecs {
+			componentSpecs[curComponentNum].Server = this.componentServers[curComponentNum]
+		}
+	}
+	return componentSpecs
+}
+
+func (this ProtectedEntityInfoImpl) GetLabels() map[string]string {
+	return this.labels
+}
+
+func (this ProtectedEntityInfoImpl) GetAnnotations() map[string]string {
+	return this.annotations
+}
@@ -68,3 +68,37 @@ func TestProtectedEntityInfoJSON(t *testing.T) {
 	assert.Assert(t, reflect.DeepEqual(peii, unmarshalled), "peii  != unmarshalled")
 	//assert.Equal(t, peii, unmarshalled, "peii  != unmarshalled")
 }
+
+func TestProtectedEntityInfoComponentSpecs(t *testing.T) {
+	pvcID, err := NewProtectedEntityIDFromString("pvc:nginx/data")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	ivdID, err := NewProtectedEntityIDFromString("ivd:aa-bbb-cc")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	fsID, err := NewProtectedEntityIDFromString("fs:logs")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	componentSpecs := []ComponentSpec{
+		{ID: ivdID, Server: "vc-east"},
+		{ID: fsID},
+	}
+	peii := NewProtectedEntityInfoWithComponentSpecs(pvcID, "data", []DataTransport{}, []DataTransport{},
+		[]DataTransport{}, componentSpecs, nil, nil)
+	assert.Assert(t, reflect.DeepEqual([]ProtectedEntityID{ivdID, fsID}, peii.GetComponentIDs()),
+		"component IDs differ")
+
+	jsonBuffer, err := json.Marshal(peii)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	unmarshalled := ProtectedEntityInfoImpl{}
+	err = json.Unmarshal(jsonBuffer, &unmarshalled)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, reflect.DeepEqual(componentSpecs, unmarshalled.GetComponentSpecs()), "component specs differ")
+}
@@ -71,3 +71,24 @@ func TestProtectedEntityIDJSON(t *testing.T) {
 
 	assert.Equal(t, test1ID, unmarshalledID, "Unmarshalled ID does not match test1 ID")
 }
+
+func TestNamedInstanceProtectedEntityID(t *testing.T) {
+	const testStr = "ivd@vc-east:e1c3cb20-db88-4c1c-9f02-5f5347e435d5:67469e1c-50a8-4f63-9a6a-ad8a2265197c"
+	testID, err := NewProtectedEntityIDFromString(testStr)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, testStr, testID.String())
+	assert.Equal(t, "ivd@vc-east", testID.GetPeType())
+	assert.Equal(t, "ivd", testID.GetBasePeType())
+	assert.Equal(t, "e1c3cb20-db88-4c1c-9f02-5f5347e435d5", testID.GetID())
+
+	peType, instance := SplitServiceName("ivd@vc-east")
+	assert.Equal(t, "ivd", peType)
+	assert.Equal(t, "vc-east", instance)
+	peType, instance = SplitServiceName("ivd")
+	assert.Equal(t, "ivd", peType)
+	assert.Equal(t, "", instance)
+	assert.Equal(t, "ivd@vc-east", NewServiceName("ivd", "vc-east"))
+	assert.Equal(t, "ivd", NewServiceName("ivd", ""))
+}
@@ -36,3 +36,56 @@ type ProtectedEntityTypeManager interface {
 	Copy(ctx context.Context, pe ProtectedEntity, params map[string]map[string]interface{}, options CopyCreateOptions) (ProtectedEntity, error)
 	CopyFromInfo(ctx context.Context, info ProtectedEntityInfo, params map[string]map[string]interface{}, options CopyCreateOptions) (ProtectedEntity, error)
 }
+
+/*
+NamedInstanceProtectedEntityTypeManager is implemented by type managers that can serve a named instance of their type,
+so that a server can serve more than one, for example two vCenters as ivd@vc-east and ivd@vc-west.  SetInstanceName
+is called before the type manager is used.  GetTypeName then returns the service name (see NewServiceName) and the IDs
+of its Protected Entities use it as their type.
+*/
+type NamedInstanceProtectedEntityTypeManager interface {
+	ProtectedEntityTypeManager
+	SetInstanceName(instance string)
+}
+
+/*
+DatastoreLocator is implemented by type managers whose Protected Entities are stored on datastores that can be
+overloaded by concurrent operations, so that operations can be limited per datastore.  GetDatastore returns the name
+of the datastore that holds the Protected Entity.
+*/
+type DatastoreLocator interface {
+	GetDatastore(ctx context.Context, id ProtectedEntityID) (string, error)
+}
+
+/*
+HealthChecker is implemented by type managers that depend on a remote service or a resource that can become
+unavailable after they are created.  CheckHealth returns an error describing the problem if the type manager cannot
+serve requests.  It is called periodically and should return quickly, checks are given a deadline through ctx.
+*/
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+/*
+WrappedProtectedEntityTypeManager is implemented by type managers that decorate another type manager, for example to
+record metrics.  The optional interfaces (RetentionManager, SoftDeleteManager, Rehydrator, UsageReporter,
+DatastoreLocator, HealthChecker) are implemented by the wrapped type manager, use UnwrapProtectedEntityTypeManager
+before checking for them.
+*/
+type WrappedProtectedEntityTypeManager interface {
+	ProtectedEntityTypeManager
+	Unwrap() ProtectedEntityTypeManager
+}
+
+/*
+UnwrapProtectedEntityTypeManager returns the innermost type manager of petm
+*/
+func UnwrapProtectedEntityTypeManager(petm ProtectedEntityTypeManager) ProtectedEntityTypeManager {
+	for {
+		wrapped, ok := petm.(WrappedProtectedEntityTypeManager)
+		if !ok {
+			return petm
+		}
+		petm = wrapped.Unwrap()
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+type RetentionMode string
+
+const (
+	RetentionModeNone RetentionMode = ""
+	// Governance retention can only be removed by users with special permissions.  Astrolabe never bypasses it.
+	RetentionModeGovernance RetentionMode = "governance"
+	// Compliance retention cannot be shortened or removed by anyone until it expires
+	RetentionModeCompliance RetentionMode = "compliance"
+)
+
+func NewRetentionMode(modeStr string) (RetentionMode, error) {
+	switch RetentionMode(modeStr) {
+	case RetentionModeNone, RetentionModeGovernance, RetentionModeCompliance:
+		return RetentionMode(modeStr), nil
+	case "none":
+		return RetentionModeNone, nil
+	}
+	return RetentionModeNone, errors.Errorf("unknown retention mode %q, expected governance or compliance", modeStr)
+}
+
+func (this RetentionMode) String() string {
+	if this == RetentionModeNone {
+		return "none"
+	}
+	return string(this)
+}
+
+/*
+Retention describes the write-once-read-many (WORM) protection of a stored snapshot.  A snapshot is retained, and
+cannot be deleted, while it is under a legal hold or until RetainUntil has passed.
+*/
+type Retention struct {
+	Mode        RetentionMode
+	RetainUntil time.Time
+	LegalHold   bool
+}
+
+func (this Retention) IsRetained(now time.Time) bool {
+	if this.LegalHold {
+		return true
+	}
+	return this.Mode != RetentionModeNone && now.Before(this.RetainUntil)
+}
+
+func (this Retention) GetModelRetention() *models.Retention {
+	retention := &models.Retention{
+		Mode:      swag.String(this.Mode.String()),
+		LegalHold: swag.Bool(this.LegalHold),
+	}
+	if this.Mode != RetentionModeNone {
+		retention.RetainUntil = strfmt.DateTime(this.RetainUntil)
+	}
+	return retention
+}
+
+func NewRetentionFromModel(mretention *models.Retention) (Retention, error) {
+	mode, err := NewRetentionMode(swag.StringValue(mretention.Mode))
+	if err != nil {
+		return Retention{}, err
+	}
+	retention := Retention{
+		Mode:      mode,
+		LegalHold: swag.BoolValue(mretention.LegalHold),
+	}
+	if mode != RetentionModeNone {
+		retention.RetainUntil = time.Time(mretention.RetainUntil)
+	}
+	return retention, nil
+}
+
+/*
+RetentionError is returned when an operation, such as DeleteSnapshot, is refused because the snapshot is retained.
+*/
+type RetentionError struct {
+	ID        ProtectedEntityID
+	Retention Retention
+}
+
+func (this RetentionError) Error() string {
+	if this.Retention.LegalHold {
+		return fmt.Sprintf("snapshot %s is under a legal hold and cannot be deleted", this.ID.String())
+	}
+	return fmt.Sprintf("snapshot %s is under %s retention until %s and cannot be deleted", this.ID.String(),
+		this.Retention.Mode, this.Retention.RetainUntil.UTC().Format(time.RFC3339))
+}
+
+func IsRetentionError(err error) bool {
+	_, ok := errors.Cause(err).(RetentionError)
+	return ok
+}
+
+/*
+ProtectedEntityTypeManagers that can store snapshots with WORM retention implement RetentionManager.  Retention can
+only be extended, never shortened.
+*/
+type RetentionManager interface {
+	GetRetention(ctx context.Context, id ProtectedEntityID) (Retention, error)
+	ExtendRetention(ctx context.Context, id ProtectedEntityID, retainUntil time.Time) error
+	SetLegalHold(ctx context.Context, id ProtectedEntityID, legalHold bool) error
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+/*
+DeletedSnapshot is a snapshot that has been deleted but can still be restored with UndeleteSnapshot until PurgeAfter.
+*/
+type DeletedSnapshot struct {
+	ID         ProtectedEntityID
+	DeletedAt  time.Time
+	PurgeAfter time.Time
+}
+
+func (this DeletedSnapshot) GetModelDeletedSnapshot() *models.DeletedSnapshot {
+	deletedAt := strfmt.DateTime(this.DeletedAt)
+	return &models.DeletedSnapshot{
+		ID:         this.ID.GetModelProtectedEntityID(),
+		DeletedAt:  &deletedAt,
+		PurgeAfter: strfmt.DateTime(this.PurgeAfter),
+	}
+}
+
+func NewDeletedSnapshotFromModel(mdeleted *models.DeletedSnapshot) (DeletedSnapshot, error) {
+	id, err := NewProtectedEntityIDFromModel(mdeleted.ID)
+	if err != nil {
+		return DeletedSnapshot{}, errors.Wrapf(err, "could not parse deleted snapshot ID %s", mdeleted.ID)
+	}
+	var deletedAt time.Time
+	if mdeleted.DeletedAt != nil {
+		deletedAt = time.Time(*mdeleted.DeletedAt)
+	}
+	return DeletedSnapshot{
+		ID:         id,
+		DeletedAt:  deletedAt,
+		PurgeAfter: time.Time(mdeleted.PurgeAfter),
+	}, nil
+}
+
+/*
+ProtectedEntityTypeManagers that keep deleted snapshots for an undelete window implement SoftDeleteManager.  Deleted
+snapshots are not returned by GetProtectedEntities, ListSnapshots or GetProtectedEntity until they are undeleted.
+*/
+type SoftDeleteManager interface {
+	/*
+		Lists the deleted snapshots of the protected entity id.  If id has a snapshot ID only that snapshot is returned.
+	*/
+	ListDeletedSnapshots(ctx context.Context, id ProtectedEntityID) ([]DeletedSnapshot, error)
+	/*
+		Restores a deleted snapshot.  It is an error if the snapshot is not deleted or has already been purged.
+	*/
+	UndeleteSnapshot(ctx context.Context, id ProtectedEntityID) error
+}
@@ -30,6 +30,16 @@ func (this TaskID) GetModelTaskID() models.TaskID {
 	return models.TaskID(this.id)
 }
 
+func NewTaskIDFromString(idStr string) TaskID {
+	return TaskID{
+		id: idStr,
+	}
+}
+
+func (this TaskID) String() string {
+	return this.id
+}
+
 func GenerateTaskID() TaskID {
 	newUUID, err := uuid.NewUUID()
 	if err != nil {
@@ -96,6 +106,10 @@ func (this GenericTask) GetTaskID() TaskID {
 	return this.ID
 }
 
+func (this GenericTask) GetID() TaskID {
+	return this.ID
+}
+
 func (this GenericTask) GetDetails() string {
 	return this.Details
 }
@@ -122,17 +136,23 @@ func (this GenericTask) GetResult() interface{} {
 
 func (this GenericTask) GetModelTaskInfo() models.TaskInfo {
 	startedTimeStr := this.StartedTime.Format(time.RFC3339)
+	startedTimeNS := this.StartedTime.UnixNano()
 	var taskStatus = this.TaskStatus.String()
-	return models.TaskInfo{
-		Completed:    &this.Completed,
-		Details:      "",
-		FinishedTime: this.FinishedTime.Format(time.RFC3339),
-		ID:           this.ID.GetModelTaskID(),
-		Progress:     &this.Progress,
-		StartedTime:  &startedTimeStr,
-		Status:       &taskStatus,
-		Result:       this.Result,
+	taskInfo := models.TaskInfo{
+		Completed:     &this.Completed,
+		Details:       this.Details,
+		ID:            this.ID.GetModelTaskID(),
+		Progress:      &this.Progress,
+		StartedTime:   &startedTimeStr,
+		StartedTimeNS: &startedTimeNS,
+		Status:        &taskStatus,
+		Result:        this.Result,
+	}
+	if !this.FinishedTime.IsZero() {
+		taskInfo.FinishedTime = this.FinishedTime.Format(time.RFC3339)
+		taskInfo.FinishedTimeNS = this.FinishedTime.UnixNano()
 	}
+	return taskInfo
 }
 
 func (this GenericTask) Cancel() error {
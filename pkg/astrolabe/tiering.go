@@ -0,0 +1,52 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+/*
+RehydrationRequiredError is returned when the data of a snapshot has been moved to an archive tier and must be
+rehydrated before it can be read.
+*/
+type RehydrationRequiredError struct {
+	ID   ProtectedEntityID
+	Tier string
+}
+
+func (this RehydrationRequiredError) Error() string {
+	return fmt.Sprintf("data for snapshot %s is in the %s tier and must be rehydrated before it can be read",
+		this.ID.String(), this.Tier)
+}
+
+func IsRehydrationRequiredError(err error) bool {
+	_, ok := errors.Cause(err).(RehydrationRequiredError)
+	return ok
+}
+
+/*
+ProtectedEntityTypeManagers that move snapshot data to archive tiers implement Rehydrator.  Rehydrate starts restoring
+the data of the snapshot id and returns a Task that reports the progress of the restore.  The task succeeds
+immediately if the data is readable already.
+*/
+type Rehydrator interface {
+	Rehydrate(ctx context.Context, id ProtectedEntityID) (Task, error)
+}
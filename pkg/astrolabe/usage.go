@@ -0,0 +1,159 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import (
+	"context"
+
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+)
+
+/*
+ProtectedEntityTypeManagers that store protected entities (repositories) implement UsageReporter to report how much
+space they are using.  If idPrefix is not empty, only protected entities whose IDs start with idPrefix are reported.
+*/
+type UsageReporter interface {
+	GetUsage(ctx context.Context, idPrefix string) (ServiceUsage, error)
+}
+
+type UsageStats struct {
+	// Bytes of data and metadata as seen by a reader of the protected entity
+	LogicalBytes int64
+	// Bytes used in the repository, including protected entity info
+	StoredBytes  int64
+	SegmentCount int64
+}
+
+func (this *UsageStats) Add(other UsageStats) {
+	this.LogicalBytes += other.LogicalBytes
+	this.StoredBytes += other.StoredBytes
+	this.SegmentCount += other.SegmentCount
+}
+
+/*
+Returns logical bytes / stored bytes, the dedup or compression ratio.  Returns 0 if nothing is stored.
+*/
+func (this UsageStats) ReductionRatio() float64 {
+	if this.StoredBytes == 0 {
+		return 0
+	}
+	return float64(this.LogicalBytes) / float64(this.StoredBytes)
+}
+
+func (this UsageStats) GetModelUsageStats() *models.UsageStats {
+	return &models.UsageStats{
+		LogicalBytes:   swag.Int64(this.LogicalBytes),
+		StoredBytes:    swag.Int64(this.StoredBytes),
+		SegmentCount:   swag.Int64(this.SegmentCount),
+		ReductionRatio: this.ReductionRatio(),
+	}
+}
+
+type SnapshotUsage struct {
+	ID    ProtectedEntityID
+	Usage UsageStats
+}
+
+/*
+Usage for a protected entity, totalled over all of its snapshots.  ID does not have a snapshot component.
+*/
+type ProtectedEntityUsage struct {
+	ID        ProtectedEntityID
+	Usage     UsageStats
+	Snapshots []SnapshotUsage
+}
+
+type ServiceUsage struct {
+	Service           string
+	Usage             UsageStats
+	ProtectedEntities []ProtectedEntityUsage
+}
+
+func (this ServiceUsage) GetModelServiceUsage() *models.ServiceUsage {
+	mpeUsages := make([]*models.ProtectedEntityUsage, len(this.ProtectedEntities))
+	for peNum, peUsage := range this.ProtectedEntities {
+		msnapshotUsages := make([]*models.SnapshotUsage, len(peUsage.Snapshots))
+		for snapshotNum, snapshotUsage := range peUsage.Snapshots {
+			msnapshotUsages[snapshotNum] = &models.SnapshotUsage{
+				ID:    snapshotUsage.ID.GetModelProtectedEntityID(),
+				Usage: snapshotUsage.Usage.GetModelUsageStats(),
+			}
+		}
+		mpeUsages[peNum] = &models.ProtectedEntityUsage{
+			ID:        peUsage.ID.GetModelProtectedEntityID(),
+			Usage:     peUsage.Usage.GetModelUsageStats(),
+			Snapshots: msnapshotUsages,
+		}
+	}
+	return &models.ServiceUsage{
+		Service:           swag.String(this.Service),
+		Usage:             this.Usage.GetModelUsageStats(),
+		ProtectedEntities: mpeUsages,
+	}
+}
+
+/*
+ProtectedEntityManagers that can produce the repository usage report themselves (e.g. by asking a remote server)
+implement RepositoryUsageReporter.  Callers should use GetRepositoryUsage.
+*/
+type RepositoryUsageReporter interface {
+	GetRepositoryUsage(ctx context.Context, service string, idPrefix string) (*models.RepositoryUsage, error)
+}
+
+/*
+Collects usage from the type managers in pem that implement UsageReporter.  Type managers that do not store data
+are skipped.  If service is not empty only that service is reported and it is an error if it does not report usage.
+*/
+func GetRepositoryUsage(ctx context.Context, pem ProtectedEntityManager, service string,
+	idPrefix string) (*models.RepositoryUsage, error) {
+	if reporter, ok := pem.(RepositoryUsageReporter); ok {
+		return reporter.GetRepositoryUsage(ctx, service, idPrefix)
+	}
+	var petms []ProtectedEntityTypeManager
+	if service != "" {
+		petm := pem.GetProtectedEntityTypeManager(service)
+		if petm == nil {
+			return nil, errors.Errorf("service %s not found", service)
+		}
+		if _, ok := UnwrapProtectedEntityTypeManager(petm).(UsageReporter); !ok {
+			return nil, errors.Errorf("service %s does not report usage", service)
+		}
+		petms = []ProtectedEntityTypeManager{petm}
+	} else {
+		petms = pem.ListEntityTypeManagers()
+	}
+	var total UsageStats
+	serviceUsages := []*models.ServiceUsage{}
+	for _, petm := range petms {
+		reporter, ok := UnwrapProtectedEntityTypeManager(petm).(UsageReporter)
+		if !ok {
+			continue
+		}
+		serviceUsage, err := reporter.GetUsage(ctx, idPrefix)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get usage for service %s", petm.GetTypeName())
+		}
+		total.Add(serviceUsage.Usage)
+		serviceUsages = append(serviceUsages, serviceUsage.GetModelServiceUsage())
+	}
+	return &models.RepositoryUsage{
+		Usage:    total.GetModelUsageStats(),
+		Services: serviceUsages,
+	}, nil
+}
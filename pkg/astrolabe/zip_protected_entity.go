@@ -0,0 +1,253 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+/*
+ZipFileProtectedEntity is a read-only ProtectedEntity backed by a combined zip (see ZipProtectedEntity).  It is used
+as the source when reconstituting protected entities from a zip.  Data and metadata come from the zip entries if
+present, otherwise from the S3 transports in the peinfo.  Close must be called to remove the temporary files
+used for compressed component zips.
+*/
+type ZipFileProtectedEntity struct {
+	info       ProtectedEntityInfo
+	files      map[string]*zip.File
+	components []*ZipFileProtectedEntity
+	tempFiles  []*os.File
+}
+
+/*
+NewZipFileProtectedEntity parses the combined zip in reader.  Component zips are parsed as well.
+*/
+func NewZipFileProtectedEntity(reader io.ReaderAt, size int64) (*ZipFileProtectedEntity, error) {
+	zipReader, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read zip")
+	}
+	returnPE := &ZipFileProtectedEntity{
+		files: map[string]*zip.File{},
+	}
+	var peinfoFile *zip.File
+	componentFiles := []*zip.File{}
+	for _, curFile := range zipReader.File {
+		switch {
+		case strings.HasPrefix(curFile.Name, "components/"):
+			if strings.HasSuffix(curFile.Name, CombinedExt) {
+				componentFiles = append(componentFiles, curFile)
+			}
+		case strings.HasSuffix(curFile.Name, PEInfoExt):
+			if peinfoFile != nil {
+				return nil, errors.Errorf("zip has multiple peinfos, %s and %s", peinfoFile.Name, curFile.Name)
+			}
+			peinfoFile = curFile
+		default:
+			returnPE.files[curFile.Name] = curFile
+		}
+	}
+	if peinfoFile == nil {
+		return nil, errors.New("zip does not have a peinfo")
+	}
+	returnPE.info, err = readZipPEInfo(peinfoFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, componentFile := range componentFiles {
+		component, err := returnPE.openComponent(componentFile, reader)
+		if err != nil {
+			returnPE.Close()
+			return nil, errors.Wrapf(err, "could not read component %s", componentFile.Name)
+		}
+		returnPE.components = append(returnPE.components, component)
+	}
+	return returnPE, nil
+}
+
+func readZipPEInfo(peinfoFile *zip.File) (ProtectedEntityInfo, error) {
+	peinfoReader, err := peinfoFile.Open()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", peinfoFile.Name)
+	}
+	defer peinfoReader.Close()
+	peinfoBytes, err := ioutil.ReadAll(peinfoReader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", peinfoFile.Name)
+	}
+	peinfo := ProtectedEntityInfoImpl{}
+	err = json.Unmarshal(peinfoBytes, &peinfo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not unmarshal %s", peinfoFile.Name)
+	}
+	return peinfo, nil
+}
+
+/*
+openComponent parses a component zip.  Stored (uncompressed) entries are read in place, compressed entries are
+extracted to a temporary file first since zip needs random access.
+*/
+func (this *ZipFileProtectedEntity) openComponent(componentFile *zip.File, reader io.ReaderAt) (*ZipFileProtectedEntity, error) {
+	size := int64(componentFile.UncompressedSize64)
+	if componentFile.Method == zip.Store {
+		offset, err := componentFile.DataOffset()
+		if err != nil {
+			return nil, err
+		}
+		return NewZipFileProtectedEntity(io.NewSectionReader(reader, offset, size), size)
+	}
+	tempFile, err := ioutil.TempFile("", "astrolabe-component")
+	if err != nil {
+		return nil, err
+	}
+	this.tempFiles = append(this.tempFiles, tempFile)
+	componentReader, err := componentFile.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer componentReader.Close()
+	_, err = io.Copy(tempFile, componentReader)
+	if err != nil {
+		return nil, err
+	}
+	return NewZipFileProtectedEntity(tempFile, size)
+}
+
+/*
+Close removes the temporary files for this entity and its components
+*/
+func (this *ZipFileProtectedEntity) Close() error {
+	for _, component := range this.components {
+		component.Close()
+	}
+	for _, tempFile := range this.tempFiles {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+	}
+	this.tempFiles = nil
+	return nil
+}
+
+/*
+GetAllProtectedEntities returns the components, depth first, followed by this entity
+*/
+func (this *ZipFileProtectedEntity) GetAllProtectedEntities() []*ZipFileProtectedEntity {
+	returnPEs := []*ZipFileProtectedEntity{}
+	for _, component := range this.components {
+		returnPEs = append(returnPEs, component.GetAllProtectedEntities()...)
+	}
+	return append(returnPEs, this)
+}
+
+func (this *ZipFileProtectedEntity) GetInfo(ctx context.Context) (ProtectedEntityInfo, error) {
+	return this.info, nil
+}
+
+func (this *ZipFileProtectedEntity) GetCombinedInfo(ctx context.Context) ([]ProtectedEntityInfo, error) {
+	combinedInfo := []ProtectedEntityInfo{}
+	for _, curPE := range this.GetAllProtectedEntities() {
+		combinedInfo = append(combinedInfo, curPE.info)
+	}
+	return combinedInfo, nil
+}
+
+func (this *ZipFileProtectedEntity) Snapshot(ctx context.Context, params map[string]map[string]interface{}) (ProtectedEntitySnapshotID, error) {
+	return ProtectedEntitySnapshotID{}, errors.New("zip protected entities cannot be snapshotted")
+}
+
+func (this *ZipFileProtectedEntity) ListSnapshots(ctx context.Context) ([]ProtectedEntitySnapshotID, error) {
+	return []ProtectedEntitySnapshotID{}, nil
+}
+
+func (this *ZipFileProtectedEntity) DeleteSnapshot(ctx context.Context, snapshotToDelete ProtectedEntitySnapshotID,
+	params map[string]map[string]interface{}) (bool, error) {
+	return false, errors.New("zip protected entities do not have snapshots")
+}
+
+func (this *ZipFileProtectedEntity) GetInfoForSnapshot(ctx context.Context, snapshotID ProtectedEntitySnapshotID) (*ProtectedEntityInfo, error) {
+	return nil, errors.New("zip protected entities do not have snapshots")
+}
+
+func (this *ZipFileProtectedEntity) GetComponents(ctx context.Context) ([]ProtectedEntity, error) {
+	components := make([]ProtectedEntity, len(this.components))
+	for componentNum, component := range this.components {
+		components[componentNum] = component
+	}
+	return components, nil
+}
+
+func (this *ZipFileProtectedEntity) GetID() ProtectedEntityID {
+	return this.info.GetID()
+}
+
+func (this *ZipFileProtectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	return this.getReader(ctx, ZipDataExt, this.info.GetDataTransports())
+}
+
+func (this *ZipFileProtectedEntity) GetMetadataReader(ctx context.Context) (io.ReadCloser, error) {
+	return this.getReader(ctx, MDExt, this.info.GetMetadataTransports())
+}
+
+func (this *ZipFileProtectedEntity) getReader(ctx context.Context, ext string, transports []DataTransport) (io.ReadCloser, error) {
+	if file, ok := this.files[this.GetID().String()+ext]; ok {
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		return NewSizedReadCloser(reader, int64(file.UncompressedSize64)), nil
+	}
+	for _, transport := range transports {
+		if transport.GetTransportType() != S3TransportType {
+			continue
+		}
+		urlStr, ok := transport.GetParam(S3URLParam)
+		if !ok {
+			continue
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not create request for %s", urlStr)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not retrieve %s", urlStr)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, errors.Errorf("could not retrieve %s, status = %s", urlStr, resp.Status)
+		}
+		if resp.ContentLength >= 0 {
+			return NewSizedReadCloser(resp.Body, resp.ContentLength), nil
+		}
+		return resp.Body, nil
+	}
+	return nil, nil
+}
+
+func (this *ZipFileProtectedEntity) Overwrite(ctx context.Context, sourcePE ProtectedEntity, params map[string]map[string]interface{},
+	overwriteComponents bool) error {
+	return errors.New("zip protected entities cannot be overwritten")
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package astrolabe
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"testing"
+)
+
+func writeZipEntry(t *testing.T, zipWriter *zip.Writer, name string, method uint16, contents []byte) {
+	entryWriter, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:   name,
+		Method: method,
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, err = entryWriter.Write(contents)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+}
+
+func writePEInfo(t *testing.T, zipWriter *zip.Writer, peInfo ProtectedEntityInfo) {
+	peInfoBytes, err := json.Marshal(peInfo)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	writeZipEntry(t, zipWriter, peInfo.GetID().String()+PEInfoExt, zip.Deflate, peInfoBytes)
+}
+
+func readZipPEData(t *testing.T, pe ProtectedEntity) []byte {
+	reader, err := pe.GetDataReader(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, reader != nil, "no data for %s", pe.GetID().String())
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return data
+}
+
+func TestZipFileProtectedEntity(t *testing.T) {
+	parentID := NewProtectedEntityID("test", "parent")
+	componentID := NewProtectedEntityID("test", "component")
+	componentData := []byte("component data")
+
+	// The component zip is deflated, which requires extracting it to read it
+	componentBuf := bytes.Buffer{}
+	componentWriter := zip.NewWriter(&componentBuf)
+	writePEInfo(t, componentWriter, NewProtectedEntityInfo(componentID, "component", []DataTransport{},
+		[]DataTransport{}, []DataTransport{}, []ProtectedEntityID{}))
+	writeZipEntry(t, componentWriter, componentID.String()+ZipDataExt, zip.Deflate, componentData)
+	err := componentWriter.Close()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	parentBuf := bytes.Buffer{}
+	parentWriter := zip.NewWriter(&parentBuf)
+	writePEInfo(t, parentWriter, NewProtectedEntityInfo(parentID, "parent", []DataTransport{},
+		[]DataTransport{}, []DataTransport{}, []ProtectedEntityID{componentID}))
+	writeZipEntry(t, parentWriter, parentID.String()+MDExt, zip.Deflate, []byte("parent metadata"))
+	writeZipEntry(t, parentWriter, "components/"+componentID.String()+CombinedExt, zip.Deflate, componentBuf.Bytes())
+	err = parentWriter.Close()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	zipPE, err := NewZipFileProtectedEntity(bytes.NewReader(parentBuf.Bytes()), int64(parentBuf.Len()))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer zipPE.Close()
+	assert.Equal(t, parentID.String(), zipPE.GetID().String())
+	dataReader, err := zipPE.GetDataReader(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, dataReader == nil, "parent should not have data")
+	allPEs := zipPE.GetAllProtectedEntities()
+	assert.Equal(t, 2, len(allPEs))
+	assert.Equal(t, componentID.String(), allPEs[0].GetID().String())
+	assert.DeepEqual(t, componentData, readZipPEData(t, allPEs[0]))
+
+	// ZipProtectedEntity stores the component zips, which are then read in place
+	rezipBuf := bytes.Buffer{}
+	err = ZipProtectedEntity(context.Background(), zipPE, &rezipBuf)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	rezipPE, err := NewZipFileProtectedEntity(bytes.NewReader(rezipBuf.Bytes()), int64(rezipBuf.Len()))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer rezipPE.Close()
+	assert.Equal(t, 0, len(rezipPE.tempFiles))
+	components, err := rezipPE.GetComponents(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1, len(components))
+	assert.DeepEqual(t, componentData, readZipPEData(t, components[0]))
+	mdReader, err := rezipPE.GetMetadataReader(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	metadata, err := ioutil.ReadAll(mdReader)
+	mdReader.Close()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "parent metadata", string(metadata))
+
+	_, err = NewZipFileProtectedEntity(bytes.NewReader([]byte("not a zip")), 9)
+	assert.Assert(t, err != nil, "expected an error for an invalid zip")
+}
@@ -7,11 +7,14 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/go-openapi/swag"
 	"github.com/pkg/errors"
 	"github.com/vmware-tanzu/astrolabe/gen/client/operations"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
 	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
 	"io"
 	"io/ioutil"
+	"k8s.io/apimachinery/pkg/labels"
 	"net/http"
 	"time"
 )
@@ -50,6 +53,9 @@ func (this ClientProtectedEntity) Snapshot(ctx context.Context, snapshotParams m
 	createSnapshotParams := operations.CreateSnapshotParams{
 		Service:           this.petm.typeName,
 		ProtectedEntityID: this.id.String(),
+		Params:            convertParamsToModel(snapshotParams),
+		IdempotencyKey:    idempotencyKey(ctx),
+		Context:           ctx,
 	}
 	createSnapshotParams.SetTimeout(time.Minute * 10)
 	snapshotOK, err := this.petm.entityManager.restClient.Operations.CreateSnapshot(&createSnapshotParams)
@@ -60,9 +66,23 @@ func (this ClientProtectedEntity) Snapshot(ctx context.Context, snapshotParams m
 }
 
 func (this ClientProtectedEntity) ListSnapshots(ctx context.Context) ([]astrolabe.ProtectedEntitySnapshotID, error) {
+	return this.listSnapshots(ctx, nil)
+}
+
+func (this ClientProtectedEntity) ListSnapshotsWithLabelSelector(ctx context.Context,
+	selector labels.Selector) ([]astrolabe.ProtectedEntitySnapshotID, error) {
+	var selectorStr *string
+	if selector != nil && !selector.Empty() {
+		selectorStr = swag.String(selector.String())
+	}
+	return this.listSnapshots(ctx, selectorStr)
+}
+
+func (this ClientProtectedEntity) listSnapshots(ctx context.Context, labelSelector *string) ([]astrolabe.ProtectedEntitySnapshotID, error) {
 	params := operations.ListSnapshotsParams{
 		ProtectedEntityID: this.id.String(),
 		Service:           this.petm.typeName,
+		LabelSelector:     labelSelector,
 	}
 	params.SetTimeout(time.Minute)
 	listSnapshotsOK, err := this.petm.entityManager.restClient.Operations.ListSnapshots(&params)
@@ -81,7 +101,16 @@ func (this ClientProtectedEntity) ListSnapshots(ctx context.Context) ([]astrolab
 }
 
 func (this ClientProtectedEntity) DeleteSnapshot(ctx context.Context, snapshotToDelete astrolabe.ProtectedEntitySnapshotID, params map[string]map[string]interface{}) (bool, error) {
-	panic("implement me")
+	deleteParams := operations.NewDeleteProtectedEntityParamsWithContext(ctx)
+	deleteParams.Service = this.id.GetPeType()
+	deleteParams.ProtectedEntityID = this.id.IDWithSnapshot(snapshotToDelete).String()
+	deleteParams.IdempotencyKey = idempotencyKey(ctx)
+	deleteParams.SetTimeout(time.Minute)
+	_, err := this.petm.entityManager.restClient.Operations.DeleteProtectedEntity(deleteParams)
+	if err != nil {
+		return false, errors.Wrap(err, "Failed in DeleteProtectedEntity")
+	}
+	return true, nil
 }
 
 func (this ClientProtectedEntity) GetInfoForSnapshot(ctx context.Context, snapshotID astrolabe.ProtectedEntitySnapshotID) (*astrolabe.ProtectedEntityInfo, error) {
@@ -93,12 +122,12 @@ func (this ClientProtectedEntity) GetComponents(ctx context.Context) ([]astrolab
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed in GetProtectedEntityInfo")
 	}
-	componentIDs := peInfo.GetComponentIDs()
-	returnEntities := make([]astrolabe.ProtectedEntity, len(componentIDs))
-	for curComponentIDNum, curComponentID := range componentIDs {
-		returnEntities[curComponentIDNum], err = this.petm.entityManager.GetProtectedEntity(ctx, curComponentID)
+	componentSpecs := peInfo.GetComponentSpecs()
+	returnEntities := make([]astrolabe.ProtectedEntity, len(componentSpecs))
+	for curComponentNum, curComponentSpec := range componentSpecs {
+		returnEntities[curComponentNum], err = this.petm.entityManager.getComponent(ctx, curComponentSpec)
 		if err != nil {
-			return nil, errors.Wrapf(err, "Failed in GetProtectedEntity for %s", curComponentID.String())
+			return nil, errors.Wrapf(err, "Failed in GetProtectedEntity for %s", curComponentSpec.ID.String())
 		}
 	}
 	return returnEntities, nil
@@ -131,6 +160,27 @@ func (this ClientProtectedEntity) Overwrite(ctx context.Context, sourcePE astrol
 	return nil
 }
 
+func convertParamsToModel(params map[string]map[string]interface{}) models.OperationParamList {
+	if len(params) == 0 {
+		return nil
+	}
+	modelParams := make(models.OperationParamList, 0, len(params))
+	for key, curPEParams := range params {
+		modelPEParams := make(models.OperationPEParamList, 0, len(curPEParams))
+		for curParamKey, curParamValue := range curPEParams {
+			modelPEParams = append(modelPEParams, &models.OperationPEParamItem{
+				Key:   curParamKey,
+				Value: curParamValue,
+			})
+		}
+		modelParams = append(modelParams, &models.OperationParamItem{
+			Key:   key,
+			Value: modelPEParams,
+		})
+	}
+	return modelParams
+}
+
 func getBestReaderForTransports(ctx context.Context, transports []astrolabe.DataTransport) (io.ReadCloser, error) {
 	for _, checkTransport := range transports {
 		switch checkTransport.GetTransportType() {
@@ -3,16 +3,21 @@ package client
 import (
 	"context"
 	"fmt"
+	"github.com/go-openapi/swag"
 	"github.com/pkg/errors"
 	"github.com/vmware-tanzu/astrolabe/gen/client"
+	"github.com/vmware-tanzu/astrolabe/gen/client/operations"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
 	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
 	"sync"
+	"time"
 )
 
 type ClientProtectedEntityManager struct {
-	restClient       *client.Astrolabe
-	typeManagers     map[string]ClientProtectedEntityTypeManager
-	typeManagerMutex sync.Mutex
+	restClient        *client.Astrolabe
+	typeManagers      map[string]ClientProtectedEntityTypeManager
+	typeManagerMutex  sync.Mutex
+	componentResolver astrolabe.ComponentResolver
 }
 
 func NewClientProtectedEntityManager(restClient *client.Astrolabe) (*ClientProtectedEntityManager, error) {
@@ -20,6 +25,7 @@ func NewClientProtectedEntityManager(restClient *client.Astrolabe) (*ClientProte
 		restClient:       restClient,
 		typeManagerMutex: sync.Mutex{},
 	}
+	registerTaskEventConsumer(restClient)
 	err := returnClient.syncTypeManagers()
 	if err != nil {
 		return nil, err
@@ -35,6 +41,27 @@ func (this *ClientProtectedEntityManager) GetProtectedEntity(ctx context.Context
 	return petm.GetProtectedEntity(ctx, id)
 }
 
+/*
+SetComponentResolver sets the resolver used for components that the server reports on other astrolabe servers.
+Without a resolver, GetComponents fails for those components.
+*/
+func (this *ClientProtectedEntityManager) SetComponentResolver(resolver astrolabe.ComponentResolver) {
+	this.componentResolver = resolver
+}
+
+/*
+getComponent returns a component from this server, or through the component resolver if it is on another server
+*/
+func (this *ClientProtectedEntityManager) getComponent(ctx context.Context, spec astrolabe.ComponentSpec) (astrolabe.ProtectedEntity, error) {
+	if spec.Server == "" {
+		return this.GetProtectedEntity(ctx, spec.ID)
+	}
+	if this.componentResolver == nil {
+		return nil, errors.Errorf("%s is on server %s, no component resolver is set", spec.ID.String(), spec.Server)
+	}
+	return this.componentResolver.GetComponent(ctx, spec)
+}
+
 func (this *ClientProtectedEntityManager) GetProtectedEntityTypeManager(peType string) astrolabe.ProtectedEntityTypeManager {
 	petm, ok := this.typeManagers[peType]
 	if !ok {
@@ -69,3 +96,70 @@ func (this *ClientProtectedEntityManager) syncTypeManagers() error {
 	this.typeManagers = newPETMs
 	return nil
 }
+
+func (this *ClientProtectedEntityManager) GetRepositoryUsage(ctx context.Context, service string, idPrefix string) (*models.RepositoryUsage, error) {
+	params := operations.NewGetUsageParamsWithContext(ctx)
+	if service != "" {
+		params.Service = &service
+	}
+	if idPrefix != "" {
+		params.IDPrefix = &idPrefix
+	}
+	params.SetTimeout(time.Minute * 10)
+	getUsageOK, err := this.restClient.Operations.GetUsage(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in GetUsage")
+	}
+	return getUsageOK.GetPayload(), nil
+}
+
+// How often waitForProtectedEntityTask checks the task
+const taskPollInterval = time.Second
+
+/*
+waitForProtectedEntityTask polls a task whose result is a Protected Entity ID, e.g. a copy, until it finishes and
+returns the ID
+*/
+func (this *ClientProtectedEntityManager) waitForProtectedEntityTask(ctx context.Context,
+	taskID astrolabe.TaskID) (astrolabe.ProtectedEntityID, error) {
+	for {
+		params := operations.NewGetTaskInfoParamsWithContext(ctx)
+		params.TaskID = taskID.String()
+		params.SetTimeout(time.Minute)
+		getTaskInfoOK, err := this.restClient.Operations.GetTaskInfo(params)
+		if err != nil {
+			return astrolabe.ProtectedEntityID{}, errors.Wrap(err, "Failed in GetTaskInfo")
+		}
+		taskInfo := getTaskInfoOK.GetPayload()
+		if swag.BoolValue(taskInfo.Completed) {
+			status := swag.StringValue(taskInfo.Status)
+			if status != astrolabe.Success.String() {
+				return astrolabe.ProtectedEntityID{}, errors.Errorf("task %s %s: %s", taskID.String(), status,
+					taskInfo.Details)
+			}
+			peidStr, ok := taskInfo.Result.(string)
+			if !ok {
+				return astrolabe.ProtectedEntityID{}, errors.Errorf("task %s result %v is not a protected entity ID",
+					taskID.String(), taskInfo.Result)
+			}
+			return astrolabe.NewProtectedEntityIDFromString(peidStr)
+		}
+		select {
+		case <-ctx.Done():
+			return astrolabe.ProtectedEntityID{}, ctx.Err()
+		case <-time.After(taskPollInterval):
+		}
+	}
+}
+
+/*
+idempotencyKey returns the idempotency key set on ctx with astrolabe.WithIdempotencyKey for the parameters of a
+mutating call, nil if there is none
+*/
+func idempotencyKey(ctx context.Context) *string {
+	key := astrolabe.IdempotencyKeyFromContext(ctx)
+	if key == "" {
+		return nil
+	}
+	return swag.String(key)
+}
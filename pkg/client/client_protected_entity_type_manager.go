@@ -2,9 +2,13 @@ package client
 
 import (
 	"context"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 	"github.com/pkg/errors"
 	"github.com/vmware-tanzu/astrolabe/gen/client/operations"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
 	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"k8s.io/apimachinery/pkg/labels"
 	"time"
 )
 
@@ -41,8 +45,22 @@ func (this ClientProtectedEntityTypeManager) GetProtectedEntity(ctx context.Cont
 }
 
 func (this ClientProtectedEntityTypeManager) GetProtectedEntities(ctx context.Context) ([]astrolabe.ProtectedEntityID, error) {
+	return this.listProtectedEntities(ctx, nil)
+}
+
+func (this ClientProtectedEntityTypeManager) GetProtectedEntitiesWithLabelSelector(ctx context.Context,
+	selector labels.Selector) ([]astrolabe.ProtectedEntityID, error) {
+	var selectorStr *string
+	if selector != nil && !selector.Empty() {
+		selectorStr = swag.String(selector.String())
+	}
+	return this.listProtectedEntities(ctx, selectorStr)
+}
+
+func (this ClientProtectedEntityTypeManager) listProtectedEntities(ctx context.Context, labelSelector *string) ([]astrolabe.ProtectedEntityID, error) {
 	params := operations.ListProtectedEntitiesParams{
-		Service: this.typeName,
+		Service:       this.typeName,
+		LabelSelector: labelSelector,
 	}
 	params.SetTimeout(time.Minute)
 	listPEsOK, err := this.entityManager.restClient.Operations.ListProtectedEntities(&params)
@@ -59,16 +77,199 @@ func (this ClientProtectedEntityTypeManager) GetProtectedEntities(ctx context.Co
 	return returnPEIDs, nil
 }
 
+func (this ClientProtectedEntityTypeManager) GetProtectedEntitiesPage(ctx context.Context,
+	options astrolabe.ListOptions) (astrolabe.ProtectedEntityIDPage, error) {
+	params := operations.NewListProtectedEntitiesParamsWithContext(ctx)
+	params.Service = this.typeName
+	params.LabelSelector = optionalString(labelSelectorString(options.LabelSelector))
+	params.ContinuationToken = optionalString(options.ContinuationToken)
+	params.Prefix = optionalString(options.Prefix)
+	params.Name = optionalString(options.Name)
+	params.OrderBy = optionalString(string(options.OrderBy))
+	params.Order = listOrder(options)
+	if options.MaxResults > 0 {
+		params.MaxResults = swag.Int32(int32(options.MaxResults))
+	}
+	params.SetTimeout(time.Minute)
+	listPEsOK, err := this.entityManager.restClient.Operations.ListProtectedEntities(params)
+	if err != nil {
+		return astrolabe.ProtectedEntityIDPage{}, errors.Wrap(err, "Failed in ListProtectedEntities")
+	}
+	return pageFromModel(listPEsOK.GetPayload())
+}
+
+func (this ClientProtectedEntityTypeManager) ListSnapshotsPage(ctx context.Context, id astrolabe.ProtectedEntityID,
+	options astrolabe.ListOptions) (astrolabe.ProtectedEntityIDPage, error) {
+	params := operations.NewListSnapshotsParamsWithContext(ctx)
+	params.Service = this.typeName
+	params.ProtectedEntityID = id.String()
+	params.LabelSelector = optionalString(labelSelectorString(options.LabelSelector))
+	params.ContinuationToken = optionalString(options.ContinuationToken)
+	params.Prefix = optionalString(options.Prefix)
+	params.Name = optionalString(options.Name)
+	params.OrderBy = optionalString(string(options.OrderBy))
+	params.Order = listOrder(options)
+	if options.MaxResults > 0 {
+		params.MaxResults = swag.Int32(int32(options.MaxResults))
+	}
+	params.SetTimeout(time.Minute)
+	listSnapshotsOK, err := this.entityManager.restClient.Operations.ListSnapshots(params)
+	if err != nil {
+		return astrolabe.ProtectedEntityIDPage{}, errors.Wrap(err, "Failed in ListSnapshots")
+	}
+	return pageFromModel(listSnapshotsOK.GetPayload())
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return swag.String(value)
+}
+
+func listOrder(options astrolabe.ListOptions) *string {
+	if options.Descending {
+		return swag.String("desc")
+	}
+	return nil
+}
+
+func pageFromModel(peList *models.ProtectedEntityList) (astrolabe.ProtectedEntityIDPage, error) {
+	page := astrolabe.ProtectedEntityIDPage{
+		IDs:               make([]astrolabe.ProtectedEntityID, len(peList.List)),
+		ContinuationToken: peList.ContinuationToken,
+	}
+	for curPEIDNum, curPEID := range peList.List {
+		peid, err := astrolabe.NewProtectedEntityIDFromModel(curPEID)
+		if err != nil {
+			return astrolabe.ProtectedEntityIDPage{}, errors.Wrapf(err, "Failed in to convert Protected Entity ID %v", curPEID)
+		}
+		page.IDs[curPEIDNum] = peid
+	}
+	return page, nil
+}
+
+/*
+Copy copies pe from its info, its data transports must be reachable by the server
+*/
 func (this ClientProtectedEntityTypeManager) Copy(ctx context.Context, pe astrolabe.ProtectedEntity, params map[string]map[string]interface{},
 	options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
-	panic("implement me")
+	peInfo, err := pe.GetInfo(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in GetInfo")
+	}
+	return this.CopyFromInfo(ctx, peInfo, params, options)
+}
+
+var copyModes = map[astrolabe.CopyCreateOptions]string{
+	astrolabe.AllocateObjectWithID: "create",
+	astrolabe.AllocateNewObject:    "create_new",
+	astrolabe.UpdateExistingObject: "update",
 }
 
+/*
+CopyFromInfo starts the copy on the server and waits for the copy task to finish
+*/
 func (this ClientProtectedEntityTypeManager) CopyFromInfo(ctx context.Context, info astrolabe.ProtectedEntityInfo, params map[string]map[string]interface{},
 	options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
-	panic("implement me")
+	mode, ok := copyModes[options]
+	if !ok {
+		return nil, errors.Errorf("unknown copy option %d", options)
+	}
+	modelInfo := info.GetModelProtectedEntityInfo()
+	copyParams := operations.NewCopyProtectedEntityParamsWithContext(ctx)
+	copyParams.Service = this.typeName
+	copyParams.Mode = mode
+	copyParams.IdempotencyKey = idempotencyKey(ctx)
+	copyParams.Body = &models.CopyParameters{
+		CopyParams:          convertParamsToModel(params),
+		ProtectedEntityInfo: &modelInfo,
+	}
+	copyParams.SetTimeout(time.Minute)
+	copyAccepted, err := this.entityManager.restClient.Operations.CopyProtectedEntity(copyParams)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in CopyProtectedEntity")
+	}
+	newPEID, err := this.entityManager.waitForProtectedEntityTask(ctx,
+		astrolabe.NewTaskIDFromString(string(copyAccepted.GetPayload().TaskID)))
+	if err != nil {
+		return nil, err
+	}
+	return this.GetProtectedEntity(ctx, newPEID)
 }
 
 func (this ClientProtectedEntityTypeManager) Delete(ctx context.Context, id astrolabe.ProtectedEntityID) error {
 	panic("implement me")
 }
+
+func (this ClientProtectedEntityTypeManager) GetRetention(ctx context.Context, id astrolabe.ProtectedEntityID) (astrolabe.Retention, error) {
+	params := operations.NewGetRetentionParamsWithContext(ctx)
+	params.Service = this.typeName
+	params.ProtectedEntityID = id.String()
+	params.SetTimeout(time.Minute)
+	getRetentionOK, err := this.entityManager.restClient.Operations.GetRetention(params)
+	if err != nil {
+		return astrolabe.Retention{}, errors.Wrap(err, "Failed in GetRetention")
+	}
+	return astrolabe.NewRetentionFromModel(getRetentionOK.GetPayload())
+}
+
+func (this ClientProtectedEntityTypeManager) ExtendRetention(ctx context.Context, id astrolabe.ProtectedEntityID, retainUntil time.Time) error {
+	return this.updateRetention(ctx, id, &models.RetentionUpdate{
+		RetainUntil: strfmt.DateTime(retainUntil),
+	})
+}
+
+func (this ClientProtectedEntityTypeManager) SetLegalHold(ctx context.Context, id astrolabe.ProtectedEntityID, legalHold bool) error {
+	return this.updateRetention(ctx, id, &models.RetentionUpdate{
+		LegalHold: swag.Bool(legalHold),
+	})
+}
+
+func (this ClientProtectedEntityTypeManager) updateRetention(ctx context.Context, id astrolabe.ProtectedEntityID,
+	update *models.RetentionUpdate) error {
+	params := operations.NewUpdateRetentionParamsWithContext(ctx)
+	params.Service = this.typeName
+	params.ProtectedEntityID = id.String()
+	params.Update = update
+	params.IdempotencyKey = idempotencyKey(ctx)
+	params.SetTimeout(time.Minute)
+	_, err := this.entityManager.restClient.Operations.UpdateRetention(params)
+	if err != nil {
+		return errors.Wrap(err, "Failed in UpdateRetention")
+	}
+	return nil
+}
+
+func (this ClientProtectedEntityTypeManager) ListDeletedSnapshots(ctx context.Context,
+	id astrolabe.ProtectedEntityID) ([]astrolabe.DeletedSnapshot, error) {
+	params := operations.NewListDeletedSnapshotsParamsWithContext(ctx)
+	params.Service = this.typeName
+	params.ProtectedEntityID = id.String()
+	params.SetTimeout(time.Minute)
+	listDeletedOK, err := this.entityManager.restClient.Operations.ListDeletedSnapshots(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in ListDeletedSnapshots")
+	}
+	deletedSnapshots := make([]astrolabe.DeletedSnapshot, len(listDeletedOK.GetPayload().List))
+	for deletedNum, mdeleted := range listDeletedOK.GetPayload().List {
+		deletedSnapshots[deletedNum], err = astrolabe.NewDeletedSnapshotFromModel(mdeleted)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return deletedSnapshots, nil
+}
+
+func (this ClientProtectedEntityTypeManager) UndeleteSnapshot(ctx context.Context, id astrolabe.ProtectedEntityID) error {
+	params := operations.NewUndeleteSnapshotParamsWithContext(ctx)
+	params.Service = this.typeName
+	params.ProtectedEntityID = id.String()
+	params.IdempotencyKey = idempotencyKey(ctx)
+	params.SetTimeout(time.Minute)
+	_, err := this.entityManager.restClient.Operations.UndeleteSnapshot(params)
+	if err != nil {
+		return errors.Wrap(err, "Failed in UndeleteSnapshot")
+	}
+	return nil
+}
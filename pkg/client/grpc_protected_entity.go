@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"github.com/vmware-tanzu/astrolabe/pkg/grpcapi"
+	"io"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type GRPCProtectedEntity struct {
+	id   astrolabe.ProtectedEntityID
+	petm GRPCProtectedEntityTypeManager
+}
+
+func NewGRPCProtectedEntity(id astrolabe.ProtectedEntityID, petm GRPCProtectedEntityTypeManager) GRPCProtectedEntity {
+	return GRPCProtectedEntity{
+		id:   id,
+		petm: petm,
+	}
+}
+
+func (this GRPCProtectedEntity) getInfo(ctx context.Context, id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntityInfo, error) {
+	peInfo, err := this.petm.entityManager.grpcClient.GetProtectedEntityInfo(ctx, &grpcapi.ProtectedEntityRequest{
+		Service: this.petm.typeName,
+		Id:      id.String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in GetProtectedEntityInfo")
+	}
+	return grpcapi.InfoFromProto(peInfo)
+}
+
+func (this GRPCProtectedEntity) GetInfo(ctx context.Context) (astrolabe.ProtectedEntityInfo, error) {
+	return this.getInfo(ctx, this.id)
+}
+
+func (this GRPCProtectedEntity) GetCombinedInfo(ctx context.Context) ([]astrolabe.ProtectedEntityInfo, error) {
+	return nil, errors.New("GetCombinedInfo is not supported by the gRPC API")
+}
+
+func (this GRPCProtectedEntity) Snapshot(ctx context.Context, snapshotParams map[string]map[string]interface{}) (astrolabe.ProtectedEntitySnapshotID, error) {
+	paramsJSON, err := grpcapi.ParamsToJSON(snapshotParams)
+	if err != nil {
+		return astrolabe.ProtectedEntitySnapshotID{}, errors.Wrap(err, "could not marshal params")
+	}
+	snapshotID, err := this.petm.entityManager.grpcClient.CreateSnapshot(withIdempotencyKey(ctx), &grpcapi.CreateSnapshotRequest{
+		Service:    this.petm.typeName,
+		Id:         this.id.String(),
+		ParamsJson: paramsJSON,
+	})
+	if err != nil {
+		return astrolabe.ProtectedEntitySnapshotID{}, errors.Wrap(err, "Failed in CreateSnapshot")
+	}
+	return astrolabe.NewProtectedEntitySnapshotID(snapshotID.Id), nil
+}
+
+func (this GRPCProtectedEntity) ListSnapshots(ctx context.Context) ([]astrolabe.ProtectedEntitySnapshotID, error) {
+	return this.listSnapshots(ctx, "")
+}
+
+func (this GRPCProtectedEntity) ListSnapshotsWithLabelSelector(ctx context.Context,
+	selector labels.Selector) ([]astrolabe.ProtectedEntitySnapshotID, error) {
+	selectorStr := ""
+	if selector != nil && !selector.Empty() {
+		selectorStr = selector.String()
+	}
+	return this.listSnapshots(ctx, selectorStr)
+}
+
+func (this GRPCProtectedEntity) listSnapshots(ctx context.Context, labelSelector string) ([]astrolabe.ProtectedEntitySnapshotID, error) {
+	snapshotList, err := this.petm.entityManager.grpcClient.ListSnapshots(ctx, &grpcapi.ListSnapshotsRequest{
+		Service:       this.petm.typeName,
+		Id:            this.id.String(),
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in ListSnapshots")
+	}
+	returnList := make([]astrolabe.ProtectedEntitySnapshotID, len(snapshotList.Ids))
+	for curSnapshotNum, curSnapshotPEID := range snapshotList.Ids {
+		curPEID, err := astrolabe.NewProtectedEntityIDFromString(curSnapshotPEID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse %v", curSnapshotPEID)
+		}
+		returnList[curSnapshotNum] = curPEID.GetSnapshotID()
+	}
+	return returnList, nil
+}
+
+func (this GRPCProtectedEntity) DeleteSnapshot(ctx context.Context, snapshotToDelete astrolabe.ProtectedEntitySnapshotID, params map[string]map[string]interface{}) (bool, error) {
+	_, err := this.petm.entityManager.grpcClient.DeleteProtectedEntity(withIdempotencyKey(ctx), &grpcapi.ProtectedEntityRequest{
+		Service: this.petm.typeName,
+		Id:      this.id.IDWithSnapshot(snapshotToDelete).String(),
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "Failed in DeleteProtectedEntity")
+	}
+	return true, nil
+}
+
+func (this GRPCProtectedEntity) GetInfoForSnapshot(ctx context.Context, snapshotID astrolabe.ProtectedEntitySnapshotID) (*astrolabe.ProtectedEntityInfo, error) {
+	peInfo, err := this.getInfo(ctx, this.id.IDWithSnapshot(snapshotID))
+	if err != nil {
+		return nil, err
+	}
+	return &peInfo, nil
+}
+
+func (this GRPCProtectedEntity) GetComponents(ctx context.Context) ([]astrolabe.ProtectedEntity, error) {
+	peInfo, err := this.GetInfo(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in GetProtectedEntityInfo")
+	}
+	componentSpecs := peInfo.GetComponentSpecs()
+	returnEntities := make([]astrolabe.ProtectedEntity, len(componentSpecs))
+	for curComponentNum, curComponentSpec := range componentSpecs {
+		returnEntities[curComponentNum], err = this.petm.entityManager.getComponent(ctx, curComponentSpec)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed in GetProtectedEntity for %s", curComponentSpec.ID.String())
+		}
+	}
+	return returnEntities, nil
+}
+
+func (this GRPCProtectedEntity) GetID() astrolabe.ProtectedEntityID {
+	return this.id
+}
+
+func (this GRPCProtectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	peInfo, err := this.GetInfo(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in GetProtectedEntityInfo")
+	}
+	return getBestReaderForTransports(ctx, peInfo.GetDataTransports())
+}
+
+func (this GRPCProtectedEntity) GetMetadataReader(ctx context.Context) (io.ReadCloser, error) {
+	peInfo, err := this.GetInfo(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in GetProtectedEntityInfo")
+	}
+	return getBestReaderForTransports(ctx, peInfo.GetMetadataTransports())
+}
+
+func (this GRPCProtectedEntity) Overwrite(ctx context.Context, sourcePE astrolabe.ProtectedEntity, params map[string]map[string]interface{},
+	overwriteComponents bool) error {
+	return errors.New("Overwrite is not supported by the gRPC API, copy with UpdateExistingObject instead")
+}
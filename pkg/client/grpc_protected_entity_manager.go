@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"github.com/vmware-tanzu/astrolabe/pkg/grpcapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"io"
+	"sync"
+	"time"
+)
+
+/*
+GRPCProtectedEntityManager is a ProtectedEntityManager that talks to astrolabe_server through the gRPC control-plane
+API.  Pass grpc.WithPerRPCCredentials when dialing conn if the server requires authentication.
+*/
+type GRPCProtectedEntityManager struct {
+	grpcClient        grpcapi.AstrolabeClient
+	typeManagers      map[string]GRPCProtectedEntityTypeManager
+	typeManagerMutex  sync.Mutex
+	componentResolver astrolabe.ComponentResolver
+}
+
+func NewGRPCProtectedEntityManager(conn *grpc.ClientConn) (*GRPCProtectedEntityManager, error) {
+	returnClient := GRPCProtectedEntityManager{
+		grpcClient:       grpcapi.NewAstrolabeClient(conn),
+		typeManagerMutex: sync.Mutex{},
+	}
+	err := returnClient.syncTypeManagers()
+	if err != nil {
+		return nil, err
+	}
+	return &returnClient, nil
+}
+
+func (this *GRPCProtectedEntityManager) GetProtectedEntity(ctx context.Context, id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
+	petm := this.GetProtectedEntityTypeManager(id.GetPeType())
+	if petm == nil {
+		return nil, errors.New(fmt.Sprintf("could not find manager for type %s", id.GetPeType()))
+	}
+	return petm.GetProtectedEntity(ctx, id)
+}
+
+/*
+SetComponentResolver sets the resolver used for components that the server reports on other astrolabe servers.
+Without a resolver, GetComponents fails for those components.
+*/
+func (this *GRPCProtectedEntityManager) SetComponentResolver(resolver astrolabe.ComponentResolver) {
+	this.componentResolver = resolver
+}
+
+/*
+getComponent returns a component from this server, or through the component resolver if it is on another server
+*/
+func (this *GRPCProtectedEntityManager) getComponent(ctx context.Context, spec astrolabe.ComponentSpec) (astrolabe.ProtectedEntity, error) {
+	if spec.Server == "" {
+		return this.GetProtectedEntity(ctx, spec.ID)
+	}
+	if this.componentResolver == nil {
+		return nil, errors.Errorf("%s is on server %s, no component resolver is set", spec.ID.String(), spec.Server)
+	}
+	return this.componentResolver.GetComponent(ctx, spec)
+}
+
+func (this *GRPCProtectedEntityManager) GetProtectedEntityTypeManager(peType string) astrolabe.ProtectedEntityTypeManager {
+	this.typeManagerMutex.Lock()
+	defer this.typeManagerMutex.Unlock()
+	petm, ok := this.typeManagers[peType]
+	if !ok {
+		return nil
+	}
+	return petm
+}
+
+func (this *GRPCProtectedEntityManager) ListEntityTypeManagers() []astrolabe.ProtectedEntityTypeManager {
+	this.typeManagerMutex.Lock()
+	defer this.typeManagerMutex.Unlock()
+	returnPETMs := []astrolabe.ProtectedEntityTypeManager{}
+	for _, curPETM := range this.typeManagers {
+		returnPETMs = append(returnPETMs, curPETM)
+	}
+	return returnPETMs
+}
+
+func (this *GRPCProtectedEntityManager) syncTypeManagers() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	serviceList, err := this.grpcClient.ListServices(ctx, &grpcapi.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "ListServices failed")
+	}
+
+	newPETMs := make(map[string]GRPCProtectedEntityTypeManager, len(serviceList.Services))
+	for _, curService := range serviceList.Services {
+		newPETMs[curService] = NewGRPCProtectedEntityTypeManager(curService, this)
+	}
+
+	this.typeManagerMutex.Lock()
+	defer this.typeManagerMutex.Unlock()
+	this.typeManagers = newPETMs
+	return nil
+}
+
+func (this *GRPCProtectedEntityManager) ListTasks(ctx context.Context) ([]astrolabe.TaskID, error) {
+	taskIDList, err := this.grpcClient.ListTasks(ctx, &grpcapi.Empty{})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in ListTasks")
+	}
+	taskIDs := make([]astrolabe.TaskID, len(taskIDList.Ids))
+	for taskNum, taskID := range taskIDList.Ids {
+		taskIDs[taskNum] = astrolabe.NewTaskIDFromString(taskID)
+	}
+	return taskIDs, nil
+}
+
+func (this *GRPCProtectedEntityManager) GetTaskInfo(ctx context.Context, taskID astrolabe.TaskID) (*grpcapi.TaskInfo, error) {
+	taskInfo, err := this.grpcClient.GetTaskInfo(ctx, &grpcapi.TaskID{Id: taskID.String()})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in GetTaskInfo")
+	}
+	return taskInfo, nil
+}
+
+/*
+WatchTask calls progress with the task info whenever the progress or the status of the task changes and returns the
+info of the finished task
+*/
+func (this *GRPCProtectedEntityManager) WatchTask(ctx context.Context, taskID astrolabe.TaskID,
+	progress func(taskInfo *grpcapi.TaskInfo)) (*grpcapi.TaskInfo, error) {
+	stream, err := this.grpcClient.WatchTask(ctx, &grpcapi.TaskID{Id: taskID.String()})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in WatchTask")
+	}
+	var lastInfo *grpcapi.TaskInfo
+	for {
+		taskInfo, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed in WatchTask")
+		}
+		if progress != nil {
+			progress(taskInfo)
+		}
+		lastInfo = taskInfo
+	}
+	if lastInfo == nil || !lastInfo.Completed {
+		return nil, errors.Errorf("WatchTask for task %s ended before the task finished", taskID.String())
+	}
+	return lastInfo, nil
+}
+
+/*
+waitForProtectedEntityTask waits for a task whose result is a Protected Entity ID, e.g. a copy, and returns the ID
+*/
+func (this *GRPCProtectedEntityManager) waitForProtectedEntityTask(ctx context.Context,
+	taskID astrolabe.TaskID) (astrolabe.ProtectedEntityID, error) {
+	taskInfo, err := this.WatchTask(ctx, taskID, nil)
+	if err != nil {
+		return astrolabe.ProtectedEntityID{}, err
+	}
+	if taskInfo.Status != astrolabe.Success.String() {
+		return astrolabe.ProtectedEntityID{}, errors.Errorf("task %s %s: %s", taskID.String(), taskInfo.Status,
+			taskInfo.Details)
+	}
+	var peidStr string
+	if err := json.Unmarshal(taskInfo.ResultJson, &peidStr); err != nil {
+		return astrolabe.ProtectedEntityID{}, errors.Wrapf(err, "could not parse the result of task %s", taskID.String())
+	}
+	return astrolabe.NewProtectedEntityIDFromString(peidStr)
+}
+
+/*
+withIdempotencyKey returns the context for a mutating call, it sends the idempotency key set on ctx with
+astrolabe.WithIdempotencyKey, if there is one
+*/
+func withIdempotencyKey(ctx context.Context) context.Context {
+	key := astrolabe.IdempotencyKeyFromContext(ctx)
+	if key == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, astrolabe.IdempotencyKeyMetadata, key)
+}
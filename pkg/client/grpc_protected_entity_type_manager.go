@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"github.com/vmware-tanzu/astrolabe/pkg/grpcapi"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type GRPCProtectedEntityTypeManager struct {
+	typeName      string
+	entityManager *GRPCProtectedEntityManager
+}
+
+func NewGRPCProtectedEntityTypeManager(typeName string, entityManager *GRPCProtectedEntityManager) GRPCProtectedEntityTypeManager {
+	return GRPCProtectedEntityTypeManager{
+		typeName:      typeName,
+		entityManager: entityManager,
+	}
+}
+
+func (this GRPCProtectedEntityTypeManager) GetTypeName() string {
+	return this.typeName
+}
+
+func (this GRPCProtectedEntityTypeManager) GetProtectedEntity(ctx context.Context, id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
+	peInfo, err := this.entityManager.grpcClient.GetProtectedEntityInfo(ctx, &grpcapi.ProtectedEntityRequest{
+		Service: this.typeName,
+		Id:      id.String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in GetProtectedEntityInfo")
+	}
+	peID, err := astrolabe.NewProtectedEntityIDFromString(peInfo.Id)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in parsing ID")
+	}
+	return NewGRPCProtectedEntity(peID, this), nil
+}
+
+func (this GRPCProtectedEntityTypeManager) GetProtectedEntities(ctx context.Context) ([]astrolabe.ProtectedEntityID, error) {
+	return this.listProtectedEntities(ctx, "")
+}
+
+func (this GRPCProtectedEntityTypeManager) GetProtectedEntitiesWithLabelSelector(ctx context.Context,
+	selector labels.Selector) ([]astrolabe.ProtectedEntityID, error) {
+	selectorStr := ""
+	if selector != nil && !selector.Empty() {
+		selectorStr = selector.String()
+	}
+	return this.listProtectedEntities(ctx, selectorStr)
+}
+
+func (this GRPCProtectedEntityTypeManager) listProtectedEntities(ctx context.Context, labelSelector string) ([]astrolabe.ProtectedEntityID, error) {
+	peList, err := this.entityManager.grpcClient.ListProtectedEntities(ctx, &grpcapi.ListProtectedEntitiesRequest{
+		Service:       this.typeName,
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in ListProtectedEntities")
+	}
+	returnPEIDs := make([]astrolabe.ProtectedEntityID, len(peList.Ids))
+	for curPEIDNum, curPEID := range peList.Ids {
+		returnPEIDs[curPEIDNum], err = astrolabe.NewProtectedEntityIDFromString(curPEID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed in to convert Protected Entity ID %v", curPEID)
+		}
+	}
+	return returnPEIDs, nil
+}
+
+func (this GRPCProtectedEntityTypeManager) GetProtectedEntitiesPage(ctx context.Context,
+	options astrolabe.ListOptions) (astrolabe.ProtectedEntityIDPage, error) {
+	peList, err := this.entityManager.grpcClient.ListProtectedEntities(ctx, &grpcapi.ListProtectedEntitiesRequest{
+		Service:       this.typeName,
+		LabelSelector: labelSelectorString(options.LabelSelector),
+		MaxResults:    int32(options.MaxResults),
+		Options:       grpcapi.ListOptionsToProto(options),
+	})
+	if err != nil {
+		return astrolabe.ProtectedEntityIDPage{}, errors.Wrap(err, "Failed in ListProtectedEntities")
+	}
+	return grpcapi.PageFromProto(peList)
+}
+
+func (this GRPCProtectedEntityTypeManager) ListSnapshotsPage(ctx context.Context, id astrolabe.ProtectedEntityID,
+	options astrolabe.ListOptions) (astrolabe.ProtectedEntityIDPage, error) {
+	snapshotList, err := this.entityManager.grpcClient.ListSnapshots(ctx, &grpcapi.ListSnapshotsRequest{
+		Service:       this.typeName,
+		Id:            id.String(),
+		LabelSelector: labelSelectorString(options.LabelSelector),
+		MaxResults:    int32(options.MaxResults),
+		Options:       grpcapi.ListOptionsToProto(options),
+	})
+	if err != nil {
+		return astrolabe.ProtectedEntityIDPage{}, errors.Wrap(err, "Failed in ListSnapshots")
+	}
+	return grpcapi.PageFromProto(snapshotList)
+}
+
+func labelSelectorString(selector labels.Selector) string {
+	if selector == nil || selector.Empty() {
+		return ""
+	}
+	return selector.String()
+}
+
+/*
+Copy copies pe from its info, its data transports must be reachable by the server
+*/
+func (this GRPCProtectedEntityTypeManager) Copy(ctx context.Context, pe astrolabe.ProtectedEntity, params map[string]map[string]interface{},
+	options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	peInfo, err := pe.GetInfo(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in GetInfo")
+	}
+	return this.CopyFromInfo(ctx, peInfo, params, options)
+}
+
+var grpcCopyModes = map[astrolabe.CopyCreateOptions]string{
+	astrolabe.AllocateObjectWithID: "create",
+	astrolabe.AllocateNewObject:    "create_new",
+	astrolabe.UpdateExistingObject: "update",
+}
+
+/*
+CopyFromInfo starts the copy on the server and waits for the copy task to finish
+*/
+func (this GRPCProtectedEntityTypeManager) CopyFromInfo(ctx context.Context, info astrolabe.ProtectedEntityInfo, params map[string]map[string]interface{},
+	options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	mode, ok := grpcCopyModes[options]
+	if !ok {
+		return nil, errors.Errorf("unknown copy option %d", options)
+	}
+	paramsJSON, err := grpcapi.ParamsToJSON(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal params")
+	}
+	taskID, err := this.entityManager.grpcClient.CopyProtectedEntity(withIdempotencyKey(ctx), &grpcapi.CopyProtectedEntityRequest{
+		Service:    this.typeName,
+		Mode:       mode,
+		Info:       grpcapi.InfoToProto(info),
+		ParamsJson: paramsJSON,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed in CopyProtectedEntity")
+	}
+	newPEID, err := this.entityManager.waitForProtectedEntityTask(ctx, astrolabe.NewTaskIDFromString(taskID.Id))
+	if err != nil {
+		return nil, err
+	}
+	return this.GetProtectedEntity(ctx, newPEID)
+}
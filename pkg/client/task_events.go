@@ -0,0 +1,146 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/go-openapi/runtime"
+	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/gen/client"
+	"github.com/vmware-tanzu/astrolabe/gen/client/operations"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+	"io"
+	"strings"
+	"time"
+)
+
+/*
+TaskEvent is a change of a task received from the task event stream.  Type is "status" if the task was started or its
+status changed and "progress" if its progress, details, queue position or locks changed.  Info is the task info after
+the change.
+*/
+type TaskEvent struct {
+	ID   string
+	Type string
+	Info models.TaskInfo
+}
+
+// How many times in a row WatchTaskEvents tries to open the task event stream before giving up
+const taskEventConnectAttempts = 3
+
+/*
+registerTaskEventConsumer lets the REST transport stream the text/event-stream responses to the writer passed to
+WatchTaskEvents
+*/
+func registerTaskEventConsumer(restClient *client.Astrolabe) {
+	if transport, ok := restClient.Transport.(*httptransport.Runtime); ok {
+		transport.Consumers["text/event-stream"] = runtime.ByteStreamConsumer()
+	}
+}
+
+/*
+WatchTaskEvents calls handler with the events of taskID, or of all tasks if it is empty, until ctx is done, handler
+returns false or, if taskID is set, the task has finished.  The first events are the current state of the tasks.  If
+the server closes the stream, for example when its write timeout expires, it is reopened from the last event received.
+*/
+func (this *ClientProtectedEntityManager) WatchTaskEvents(ctx context.Context, taskID string,
+	handler func(event TaskEvent) bool) error {
+	lastEventID := ""
+	failedAttempts := 0
+	for {
+		streamCtx, cancel := context.WithCancel(ctx)
+		params := operations.NewWatchTaskEventsParamsWithContext(streamCtx)
+		if taskID != "" {
+			params.TaskID = swag.String(taskID)
+		}
+		if lastEventID != "" {
+			params.LastEventID = swag.String(lastEventID)
+		}
+		reader, writer := io.Pipe()
+		go func() {
+			_, err := this.restClient.Operations.WatchTaskEvents(params, writer)
+			if err == nil {
+				err = io.EOF
+			}
+			writer.CloseWithError(err)
+		}()
+		opened, done, err := readTaskEvents(reader, taskID, &lastEventID, handler)
+		cancel()
+		reader.Close()
+		if done {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, ok := err.(*operations.WatchTaskEventsNotFound); ok {
+			return errors.Wrap(err, "Failed in WatchTaskEvents")
+		}
+		if opened {
+			failedAttempts = 0
+		} else {
+			failedAttempts++
+			if failedAttempts >= taskEventConnectAttempts {
+				return errors.Wrap(err, "Failed in WatchTaskEvents")
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(taskPollInterval):
+		}
+	}
+}
+
+/*
+readTaskEvents parses the Server-Sent Events read from reader and passes them to handler, lastEventID is set to the ID
+of each event passed.  opened is true if anything was read and done is true if the handler or, if taskID is set, the
+task has finished, or if an event could not be parsed.
+*/
+func readTaskEvents(reader io.Reader, taskID string, lastEventID *string,
+	handler func(event TaskEvent) bool) (opened bool, done bool, err error) {
+	lines := bufio.NewReader(reader)
+	event := TaskEvent{}
+	data := ""
+	for {
+		line, err := lines.ReadString('\n')
+		if err != nil {
+			return opened, false, err
+		}
+		opened = true
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if data != "" {
+				if err := json.Unmarshal([]byte(data), &event.Info); err != nil {
+					return true, true, errors.Wrapf(err, "could not parse task event %s", event.ID)
+				}
+				*lastEventID = event.ID
+				if !handler(event) || (taskID != "" && swag.BoolValue(event.Info.Completed)) {
+					return true, true, nil
+				}
+			}
+			event = TaskEvent{}
+			data = ""
+			continue
+		}
+		// Lines starting with a colon are comments and have an empty field name
+		field, value := line, ""
+		if colon := strings.Index(line, ":"); colon >= 0 {
+			field = line[:colon]
+			value = strings.TrimPrefix(line[colon+1:], " ")
+		}
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Type = value
+		case "data":
+			if data != "" {
+				data += "\n"
+			}
+			data += value
+		}
+	}
+}
@@ -88,6 +88,9 @@ func (this FSProtectedEntity) GetCombinedInfo(ctx context.Context) ([]astrolabe.
  * Snapshot APIs
  */
 func (this FSProtectedEntity) Snapshot(ctx context.Context, params map[string]map[string]interface{}) (astrolabe.ProtectedEntitySnapshotID, error) {
+	if err := astrolabe.CheckNoLabelParams(this.id, params); err != nil {
+		return astrolabe.ProtectedEntitySnapshotID{}, err
+	}
 	return astrolabe.ProtectedEntitySnapshotID{}, nil
 }
 
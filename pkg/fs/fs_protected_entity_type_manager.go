@@ -24,10 +24,12 @@ import (
 	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
 	"io"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 )
 
 type FSProtectedEntityTypeManager struct {
+	typeName string
 	root     string
 	s3Config astrolabe.S3Config
 	logger   logrus.FieldLogger
@@ -40,6 +42,7 @@ func NewFSProtectedEntityTypeManagerFromConfig(params map[string]interface{}, s3
 	root := params["root"].(string)
 
 	returnTypeManager := FSProtectedEntityTypeManager{
+		typeName: kTYPE_NAME,
 		root:     root,
 		s3Config: s3Config,
 		logger:   logger,
@@ -48,7 +51,25 @@ func NewFSProtectedEntityTypeManagerFromConfig(params map[string]interface{}, s3
 }
 
 func (this *FSProtectedEntityTypeManager) GetTypeName() string {
-	return kTYPE_NAME
+	return this.typeName
+}
+
+/*
+CheckHealth checks that the root directory exists
+*/
+func (this *FSProtectedEntityTypeManager) CheckHealth(ctx context.Context) error {
+	rootInfo, err := os.Stat(this.root)
+	if err != nil {
+		return errors.Wrapf(err, "could not stat root %s", this.root)
+	}
+	if !rootInfo.IsDir() {
+		return errors.Errorf("root %s is not a directory", this.root)
+	}
+	return nil
+}
+
+func (this *FSProtectedEntityTypeManager) SetInstanceName(instance string) {
+	this.typeName = astrolabe.NewServiceName(kTYPE_NAME, instance)
 }
 
 func (this *FSProtectedEntityTypeManager) GetProtectedEntity(ctx context.Context, id astrolabe.ProtectedEntityID) (
@@ -64,7 +85,7 @@ func (this *FSProtectedEntityTypeManager) GetProtectedEntities(ctx context.Conte
 
 	var retVal = make([]astrolabe.ProtectedEntityID, len(files))
 	for index, curFile := range files {
-		peid := astrolabe.NewProtectedEntityID("fs", curFile.Name())
+		peid := astrolabe.NewProtectedEntityID(this.typeName, curFile.Name())
 		retVal[index] = peid
 	}
 	return retVal, nil
@@ -94,7 +115,7 @@ func (this *FSProtectedEntityTypeManager) Copy(ctx context.Context, pe astrolabe
 	if err != nil {
 		return nil, err
 	}
-	return this.copyInt(ctx, sourcePEInfo, options, dataReader, metadataReader)
+	return this.copyInt(ctx, sourcePEInfo, options, astrolabe.NewProgressReader(ctx, dataReader), metadataReader)
 }
 
 func (this *FSProtectedEntityTypeManager) CopyFromInfo(ctx context.Context, pe astrolabe.ProtectedEntityInfo,
@@ -105,7 +126,7 @@ func (this *FSProtectedEntityTypeManager) CopyFromInfo(ctx context.Context, pe a
 func (this *FSProtectedEntityTypeManager) copyInt(ctx context.Context, sourcePEInfo astrolabe.ProtectedEntityInfo,
 	options astrolabe.CopyCreateOptions, dataReader io.Reader, metadataReader io.Reader) (astrolabe.ProtectedEntity, error) {
 	id := sourcePEInfo.GetID()
-	if id.GetPeType() != kTYPE_NAME {
+	if id.GetBasePeType() != kTYPE_NAME {
 		return nil, errors.New(id.GetPeType() + " is not of type fs")
 	}
 	if options == astrolabe.AllocateObjectWithID {
@@ -120,7 +141,7 @@ func (this *FSProtectedEntityTypeManager) copyInt(ctx context.Context, sourcePEI
 	if err != nil {
 		panic("uuid.NewRandom return err ")
 	}
-	newPEID := astrolabe.NewProtectedEntityID(kTYPE_NAME, fsUUID.String())
+	newPEID := astrolabe.NewProtectedEntityID(this.typeName, fsUUID.String())
 	newPE, err := newFSProtectedEntity(this, newPEID, sourcePEInfo.GetName(), filepath.Join(this.root, newPEID.GetID()))
 	if err != nil {
 		return nil, err
@@ -0,0 +1,217 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcapi
+
+import (
+	"encoding/json"
+
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+func InfoToProto(info astrolabe.ProtectedEntityInfo) *ProtectedEntityInfo {
+	modelInfo := info.GetModelProtectedEntityInfo()
+	protoInfo := &ProtectedEntityInfo{
+		Id:                 string(modelInfo.ID),
+		DataTransports:     transportsToProto(modelInfo.DataTransports),
+		MetadataTransports: transportsToProto(modelInfo.MetadataTransports),
+		CombinedTransports: transportsToProto(modelInfo.CombinedTransports),
+		Labels:             modelInfo.Labels,
+		Annotations:        modelInfo.Annotations,
+	}
+	if modelInfo.Name != nil {
+		protoInfo.Name = *modelInfo.Name
+	}
+	remoteComponents := false
+	for _, curComponentSpec := range modelInfo.ComponentSpecs {
+		protoInfo.ComponentIds = append(protoInfo.ComponentIds, string(curComponentSpec.ID))
+		remoteComponents = remoteComponents || curComponentSpec.Server != ""
+	}
+	if remoteComponents {
+		for _, curComponentSpec := range modelInfo.ComponentSpecs {
+			protoInfo.ComponentServers = append(protoInfo.ComponentServers, curComponentSpec.Server)
+		}
+	}
+	return protoInfo
+}
+
+func InfoFromProto(protoInfo *ProtectedEntityInfo) (astrolabe.ProtectedEntityInfo, error) {
+	if protoInfo == nil {
+		return nil, errors.New("missing protected entity info")
+	}
+	name := protoInfo.Name
+	modelInfo := models.ProtectedEntityInfo{
+		ID:                 models.ProtectedEntityID(protoInfo.Id),
+		Name:               &name,
+		DataTransports:     transportsFromProto(protoInfo.DataTransports),
+		MetadataTransports: transportsFromProto(protoInfo.MetadataTransports),
+		CombinedTransports: transportsFromProto(protoInfo.CombinedTransports),
+		ComponentSpecs:     []*models.ComponentSpec{},
+		Labels:             protoInfo.Labels,
+		Annotations:        protoInfo.Annotations,
+	}
+	if len(protoInfo.ComponentServers) > 0 && len(protoInfo.ComponentServers) != len(protoInfo.ComponentIds) {
+		return nil, errors.Errorf("%d component servers for %d components", len(protoInfo.ComponentServers),
+			len(protoInfo.ComponentIds))
+	}
+	for curComponentNum, curComponentID := range protoInfo.ComponentIds {
+		componentSpec := &models.ComponentSpec{
+			ID: models.ProtectedEntityID(curComponentID),
+		}
+		if len(protoInfo.ComponentServers) > 0 {
+			componentSpec.Server = protoInfo.ComponentServers[curComponentNum]
+		}
+		modelInfo.ComponentSpecs = append(modelInfo.ComponentSpecs, componentSpec)
+	}
+	return astrolabe.NewProtectedEntityInfoFromModel(&modelInfo)
+}
+
+func transportsToProto(transports []*models.DataTransport) []*DataTransport {
+	protoTransports := make([]*DataTransport, len(transports))
+	for transportNum, curTransport := range transports {
+		protoTransports[transportNum] = &DataTransport{
+			TransportType: curTransport.TransportType,
+			Params:        curTransport.Params,
+		}
+	}
+	return protoTransports
+}
+
+func transportsFromProto(protoTransports []*DataTransport) []*models.DataTransport {
+	transports := make([]*models.DataTransport, len(protoTransports))
+	for transportNum, curTransport := range protoTransports {
+		transports[transportNum] = &models.DataTransport{
+			TransportType: curTransport.TransportType,
+			Params:        curTransport.Params,
+		}
+	}
+	return transports
+}
+
+func ParamsToJSON(params map[string]map[string]interface{}) ([]byte, error) {
+	if params == nil {
+		return nil, nil
+	}
+	return json.Marshal(params)
+}
+
+func ParamsFromJSON(paramsJSON []byte) (map[string]map[string]interface{}, error) {
+	params := map[string]map[string]interface{}{}
+	if len(paramsJSON) == 0 {
+		return params, nil
+	}
+	if err := json.Unmarshal(paramsJSON, &params); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal params")
+	}
+	return params, nil
+}
+
+/*
+TaskInfoToProto returns the info of task.  The result of the task is carried as JSON.
+*/
+func TaskInfoToProto(task astrolabe.Task) (*TaskInfo, error) {
+	modelInfo := task.GetModelTaskInfo()
+	protoInfo := &TaskInfo{
+		Id:             string(modelInfo.ID),
+		Details:        modelInfo.Details,
+		FinishedTimeNs: modelInfo.FinishedTimeNS,
+		QueuePosition:  modelInfo.QueuePosition,
+	}
+	if modelInfo.Status != nil {
+		protoInfo.Status = *modelInfo.Status
+	}
+	if modelInfo.Completed != nil {
+		protoInfo.Completed = *modelInfo.Completed
+	}
+	if modelInfo.Progress != nil {
+		protoInfo.Progress = *modelInfo.Progress
+	}
+	if modelInfo.StartedTimeNS != nil {
+		protoInfo.StartedTimeNs = *modelInfo.StartedTimeNS
+	}
+	if modelInfo.Result != nil {
+		resultJSON, err := json.Marshal(modelInfo.Result)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not marshal the result of task %s", protoInfo.Id)
+		}
+		protoInfo.ResultJson = resultJSON
+	}
+	for _, lock := range modelInfo.Locks {
+		protoInfo.Locks = append(protoInfo.Locks, &TaskLock{
+			ProtectedEntityId: string(lock.ProtectedEntityID),
+			Mode:              swag.StringValue(lock.Mode),
+			State:             swag.StringValue(lock.State),
+		})
+	}
+	return protoInfo, nil
+}
+
+/*
+ListOptionsFromProto returns the options of a list request.  The label selector is parsed by the caller.
+*/
+func ListOptionsFromProto(maxResults int32, protoOptions *ListOptions) astrolabe.ListOptions {
+	options := astrolabe.ListOptions{
+		MaxResults: int(maxResults),
+	}
+	if protoOptions != nil {
+		options.ContinuationToken = protoOptions.ContinuationToken
+		options.Prefix = protoOptions.Prefix
+		options.Name = protoOptions.Name
+		options.OrderBy = astrolabe.ListOrderBy(protoOptions.OrderBy)
+		options.Descending = protoOptions.Descending
+	}
+	return options
+}
+
+func ListOptionsToProto(options astrolabe.ListOptions) *ListOptions {
+	return &ListOptions{
+		ContinuationToken: options.ContinuationToken,
+		Prefix:            options.Prefix,
+		Name:              options.Name,
+		OrderBy:           string(options.OrderBy),
+		Descending:        options.Descending,
+	}
+}
+
+func PageToProto(page astrolabe.ProtectedEntityIDPage) *ProtectedEntityList {
+	protoList := &ProtectedEntityList{
+		Ids:               make([]string, len(page.IDs)),
+		Truncated:         page.ContinuationToken != "",
+		ContinuationToken: page.ContinuationToken,
+	}
+	for idNum, id := range page.IDs {
+		protoList.Ids[idNum] = id.String()
+	}
+	return protoList
+}
+
+func PageFromProto(protoList *ProtectedEntityList) (astrolabe.ProtectedEntityIDPage, error) {
+	page := astrolabe.ProtectedEntityIDPage{
+		IDs:               make([]astrolabe.ProtectedEntityID, len(protoList.Ids)),
+		ContinuationToken: protoList.ContinuationToken,
+	}
+	for idNum, idStr := range protoList.Ids {
+		id, err := astrolabe.NewProtectedEntityIDFromString(idStr)
+		if err != nil {
+			return astrolabe.ProtectedEntityIDPage{}, errors.Wrapf(err, "could not parse protected entity ID %s", idStr)
+		}
+		page.IDs[idNum] = id
+	}
+	return page, nil
+}
@@ -0,0 +1,281 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+/*
+The gRPC service of astrolabe.proto.  The server implements AstrolabeServer, clients call it through AstrolabeClient.
+*/
+
+const serviceName = "astrolabe.v1.Astrolabe"
+
+type AstrolabeServer interface {
+	ListServices(ctx context.Context, request *Empty) (*ServiceList, error)
+	ListProtectedEntities(ctx context.Context, request *ListProtectedEntitiesRequest) (*ProtectedEntityList, error)
+	GetProtectedEntityInfo(ctx context.Context, request *ProtectedEntityRequest) (*ProtectedEntityInfo, error)
+	CreateSnapshot(ctx context.Context, request *CreateSnapshotRequest) (*SnapshotID, error)
+	ListSnapshots(ctx context.Context, request *ListSnapshotsRequest) (*ProtectedEntityList, error)
+	CopyProtectedEntity(ctx context.Context, request *CopyProtectedEntityRequest) (*TaskID, error)
+	DeleteProtectedEntity(ctx context.Context, request *ProtectedEntityRequest) (*ProtectedEntityID, error)
+	ListTasks(ctx context.Context, request *Empty) (*TaskIDList, error)
+	GetTaskInfo(ctx context.Context, request *TaskID) (*TaskInfo, error)
+	WatchTask(request *TaskID, stream TaskInfoSender) error
+}
+
+/*
+TaskInfoSender sends the task infos of WatchTask on the server side
+*/
+type TaskInfoSender interface {
+	Send(*TaskInfo) error
+	Context() context.Context
+}
+
+type taskInfoServerStream struct {
+	grpc.ServerStream
+}
+
+func (this *taskInfoServerStream) Send(taskInfo *TaskInfo) error {
+	return this.ServerStream.SendMsg(taskInfo)
+}
+
+/*
+unaryHandler returns the grpc.MethodDesc handler for a unary method of AstrolabeServer
+*/
+func unaryHandler(methodName string, newRequest func() interface{},
+	call func(srv AstrolabeServer, ctx context.Context, request interface{}) (interface{}, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: methodName,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error,
+			interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			request := newRequest()
+			if err := dec(request); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(srv.(AstrolabeServer), ctx, request)
+			}
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/" + serviceName + "/" + methodName,
+			}
+			return interceptor(ctx, request, info, func(ctx context.Context, request interface{}) (interface{}, error) {
+				return call(srv.(AstrolabeServer), ctx, request)
+			})
+		},
+	}
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*AstrolabeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryHandler("ListServices", func() interface{} { return &Empty{} },
+			func(srv AstrolabeServer, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.ListServices(ctx, request.(*Empty))
+			}),
+		unaryHandler("ListProtectedEntities", func() interface{} { return &ListProtectedEntitiesRequest{} },
+			func(srv AstrolabeServer, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.ListProtectedEntities(ctx, request.(*ListProtectedEntitiesRequest))
+			}),
+		unaryHandler("GetProtectedEntityInfo", func() interface{} { return &ProtectedEntityRequest{} },
+			func(srv AstrolabeServer, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.GetProtectedEntityInfo(ctx, request.(*ProtectedEntityRequest))
+			}),
+		unaryHandler("CreateSnapshot", func() interface{} { return &CreateSnapshotRequest{} },
+			func(srv AstrolabeServer, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.CreateSnapshot(ctx, request.(*CreateSnapshotRequest))
+			}),
+		unaryHandler("ListSnapshots", func() interface{} { return &ListSnapshotsRequest{} },
+			func(srv AstrolabeServer, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.ListSnapshots(ctx, request.(*ListSnapshotsRequest))
+			}),
+		unaryHandler("CopyProtectedEntity", func() interface{} { return &CopyProtectedEntityRequest{} },
+			func(srv AstrolabeServer, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.CopyProtectedEntity(ctx, request.(*CopyProtectedEntityRequest))
+			}),
+		unaryHandler("DeleteProtectedEntity", func() interface{} { return &ProtectedEntityRequest{} },
+			func(srv AstrolabeServer, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.DeleteProtectedEntity(ctx, request.(*ProtectedEntityRequest))
+			}),
+		unaryHandler("ListTasks", func() interface{} { return &Empty{} },
+			func(srv AstrolabeServer, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.ListTasks(ctx, request.(*Empty))
+			}),
+		unaryHandler("GetTaskInfo", func() interface{} { return &TaskID{} },
+			func(srv AstrolabeServer, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.GetTaskInfo(ctx, request.(*TaskID))
+			}),
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "WatchTask",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				request := &TaskID{}
+				if err := stream.RecvMsg(request); err != nil {
+					return err
+				}
+				return srv.(AstrolabeServer).WatchTask(request, &taskInfoServerStream{stream})
+			},
+			ServerStreams: true,
+		},
+	},
+	Metadata: "astrolabe.proto",
+}
+
+func RegisterAstrolabeServer(server *grpc.Server, srv AstrolabeServer) {
+	server.RegisterService(&serviceDesc, srv)
+}
+
+/*
+TaskInfoReceiver receives the task infos of WatchTask on the client side.  Recv returns io.EOF after the info of the
+finished task.
+*/
+type TaskInfoReceiver interface {
+	Recv() (*TaskInfo, error)
+}
+
+type taskInfoClientStream struct {
+	grpc.ClientStream
+}
+
+func (this *taskInfoClientStream) Recv() (*TaskInfo, error) {
+	taskInfo := &TaskInfo{}
+	if err := this.ClientStream.RecvMsg(taskInfo); err != nil {
+		return nil, err
+	}
+	return taskInfo, nil
+}
+
+type AstrolabeClient struct {
+	conn *grpc.ClientConn
+}
+
+func NewAstrolabeClient(conn *grpc.ClientConn) AstrolabeClient {
+	return AstrolabeClient{
+		conn: conn,
+	}
+}
+
+func (this AstrolabeClient) ListServices(ctx context.Context, request *Empty,
+	opts ...grpc.CallOption) (*ServiceList, error) {
+	response := &ServiceList{}
+	err := this.conn.Invoke(ctx, "/"+serviceName+"/ListServices", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this AstrolabeClient) ListProtectedEntities(ctx context.Context, request *ListProtectedEntitiesRequest,
+	opts ...grpc.CallOption) (*ProtectedEntityList, error) {
+	response := &ProtectedEntityList{}
+	err := this.conn.Invoke(ctx, "/"+serviceName+"/ListProtectedEntities", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this AstrolabeClient) GetProtectedEntityInfo(ctx context.Context, request *ProtectedEntityRequest,
+	opts ...grpc.CallOption) (*ProtectedEntityInfo, error) {
+	response := &ProtectedEntityInfo{}
+	err := this.conn.Invoke(ctx, "/"+serviceName+"/GetProtectedEntityInfo", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this AstrolabeClient) CreateSnapshot(ctx context.Context, request *CreateSnapshotRequest,
+	opts ...grpc.CallOption) (*SnapshotID, error) {
+	response := &SnapshotID{}
+	err := this.conn.Invoke(ctx, "/"+serviceName+"/CreateSnapshot", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this AstrolabeClient) ListSnapshots(ctx context.Context, request *ListSnapshotsRequest,
+	opts ...grpc.CallOption) (*ProtectedEntityList, error) {
+	response := &ProtectedEntityList{}
+	err := this.conn.Invoke(ctx, "/"+serviceName+"/ListSnapshots", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this AstrolabeClient) CopyProtectedEntity(ctx context.Context, request *CopyProtectedEntityRequest,
+	opts ...grpc.CallOption) (*TaskID, error) {
+	response := &TaskID{}
+	err := this.conn.Invoke(ctx, "/"+serviceName+"/CopyProtectedEntity", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this AstrolabeClient) DeleteProtectedEntity(ctx context.Context, request *ProtectedEntityRequest,
+	opts ...grpc.CallOption) (*ProtectedEntityID, error) {
+	response := &ProtectedEntityID{}
+	err := this.conn.Invoke(ctx, "/"+serviceName+"/DeleteProtectedEntity", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this AstrolabeClient) ListTasks(ctx context.Context, request *Empty,
+	opts ...grpc.CallOption) (*TaskIDList, error) {
+	response := &TaskIDList{}
+	err := this.conn.Invoke(ctx, "/"+serviceName+"/ListTasks", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this AstrolabeClient) GetTaskInfo(ctx context.Context, request *TaskID,
+	opts ...grpc.CallOption) (*TaskInfo, error) {
+	response := &TaskInfo{}
+	err := this.conn.Invoke(ctx, "/"+serviceName+"/GetTaskInfo", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this AstrolabeClient) WatchTask(ctx context.Context, request *TaskID,
+	opts ...grpc.CallOption) (TaskInfoReceiver, error) {
+	stream, err := this.conn.NewStream(ctx, &serviceDesc.Streams[0], "/"+serviceName+"/WatchTask", opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(request); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &taskInfoClientStream{stream}, nil
+}
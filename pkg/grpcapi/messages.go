@@ -0,0 +1,200 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcapi
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+/*
+The messages of astrolabe.proto.  They are marshaled by github.com/golang/protobuf from their struct tags.
+*/
+
+type Empty struct {
+}
+
+func (this *Empty) Reset()         { *this = Empty{} }
+func (this *Empty) String() string { return proto.CompactTextString(this) }
+func (*Empty) ProtoMessage()       {}
+
+type ServiceList struct {
+	Services []string `protobuf:"bytes,1,rep,name=services,proto3"`
+}
+
+func (this *ServiceList) Reset()         { *this = ServiceList{} }
+func (this *ServiceList) String() string { return proto.CompactTextString(this) }
+func (*ServiceList) ProtoMessage()       {}
+
+type ListProtectedEntitiesRequest struct {
+	Service       string       `protobuf:"bytes,1,opt,name=service,proto3"`
+	LabelSelector string       `protobuf:"bytes,2,opt,name=label_selector,json=labelSelector,proto3"`
+	IdsAfter      string       `protobuf:"bytes,3,opt,name=ids_after,json=idsAfter,proto3"`
+	MaxResults    int32        `protobuf:"varint,4,opt,name=max_results,json=maxResults,proto3"`
+	Options       *ListOptions `protobuf:"bytes,5,opt,name=options,proto3"`
+}
+
+func (this *ListProtectedEntitiesRequest) Reset()         { *this = ListProtectedEntitiesRequest{} }
+func (this *ListProtectedEntitiesRequest) String() string { return proto.CompactTextString(this) }
+func (*ListProtectedEntitiesRequest) ProtoMessage()       {}
+
+type ListOptions struct {
+	ContinuationToken string `protobuf:"bytes,1,opt,name=continuation_token,json=continuationToken,proto3"`
+	Prefix            string `protobuf:"bytes,2,opt,name=prefix,proto3"`
+	Name              string `protobuf:"bytes,3,opt,name=name,proto3"`
+	OrderBy           string `protobuf:"bytes,4,opt,name=order_by,json=orderBy,proto3"`
+	Descending        bool   `protobuf:"varint,5,opt,name=descending,proto3"`
+}
+
+func (this *ListOptions) Reset()         { *this = ListOptions{} }
+func (this *ListOptions) String() string { return proto.CompactTextString(this) }
+func (*ListOptions) ProtoMessage()       {}
+
+type ProtectedEntityList struct {
+	Ids               []string `protobuf:"bytes,1,rep,name=ids,proto3"`
+	Truncated         bool     `protobuf:"varint,2,opt,name=truncated,proto3"`
+	ContinuationToken string   `protobuf:"bytes,3,opt,name=continuation_token,json=continuationToken,proto3"`
+}
+
+func (this *ProtectedEntityList) Reset()         { *this = ProtectedEntityList{} }
+func (this *ProtectedEntityList) String() string { return proto.CompactTextString(this) }
+func (*ProtectedEntityList) ProtoMessage()       {}
+
+type ProtectedEntityRequest struct {
+	Service string `protobuf:"bytes,1,opt,name=service,proto3"`
+	Id      string `protobuf:"bytes,2,opt,name=id,proto3"`
+}
+
+func (this *ProtectedEntityRequest) Reset()         { *this = ProtectedEntityRequest{} }
+func (this *ProtectedEntityRequest) String() string { return proto.CompactTextString(this) }
+func (*ProtectedEntityRequest) ProtoMessage()       {}
+
+type ProtectedEntityID struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3"`
+}
+
+func (this *ProtectedEntityID) Reset()         { *this = ProtectedEntityID{} }
+func (this *ProtectedEntityID) String() string { return proto.CompactTextString(this) }
+func (*ProtectedEntityID) ProtoMessage()       {}
+
+type SnapshotID struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3"`
+}
+
+func (this *SnapshotID) Reset()         { *this = SnapshotID{} }
+func (this *SnapshotID) String() string { return proto.CompactTextString(this) }
+func (*SnapshotID) ProtoMessage()       {}
+
+type DataTransport struct {
+	TransportType string            `protobuf:"bytes,1,opt,name=transport_type,json=transportType,proto3"`
+	Params        map[string]string `protobuf:"bytes,2,rep,name=params,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (this *DataTransport) Reset()         { *this = DataTransport{} }
+func (this *DataTransport) String() string { return proto.CompactTextString(this) }
+func (*DataTransport) ProtoMessage()       {}
+
+type ProtectedEntityInfo struct {
+	Id                 string            `protobuf:"bytes,1,opt,name=id,proto3"`
+	Name               string            `protobuf:"bytes,2,opt,name=name,proto3"`
+	DataTransports     []*DataTransport  `protobuf:"bytes,3,rep,name=data_transports,json=dataTransports,proto3"`
+	MetadataTransports []*DataTransport  `protobuf:"bytes,4,rep,name=metadata_transports,json=metadataTransports,proto3"`
+	CombinedTransports []*DataTransport  `protobuf:"bytes,5,rep,name=combined_transports,json=combinedTransports,proto3"`
+	ComponentIds       []string          `protobuf:"bytes,6,rep,name=component_ids,json=componentIds,proto3"`
+	Labels             map[string]string `protobuf:"bytes,7,rep,name=labels,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Annotations        map[string]string `protobuf:"bytes,8,rep,name=annotations,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ComponentServers   []string          `protobuf:"bytes,9,rep,name=component_servers,json=componentServers,proto3"`
+}
+
+func (this *ProtectedEntityInfo) Reset()         { *this = ProtectedEntityInfo{} }
+func (this *ProtectedEntityInfo) String() string { return proto.CompactTextString(this) }
+func (*ProtectedEntityInfo) ProtoMessage()       {}
+
+type CreateSnapshotRequest struct {
+	Service    string `protobuf:"bytes,1,opt,name=service,proto3"`
+	Id         string `protobuf:"bytes,2,opt,name=id,proto3"`
+	ParamsJson []byte `protobuf:"bytes,3,opt,name=params_json,json=paramsJson,proto3"`
+}
+
+func (this *CreateSnapshotRequest) Reset()         { *this = CreateSnapshotRequest{} }
+func (this *CreateSnapshotRequest) String() string { return proto.CompactTextString(this) }
+func (*CreateSnapshotRequest) ProtoMessage()       {}
+
+type ListSnapshotsRequest struct {
+	Service       string       `protobuf:"bytes,1,opt,name=service,proto3"`
+	Id            string       `protobuf:"bytes,2,opt,name=id,proto3"`
+	LabelSelector string       `protobuf:"bytes,3,opt,name=label_selector,json=labelSelector,proto3"`
+	MaxResults    int32        `protobuf:"varint,4,opt,name=max_results,json=maxResults,proto3"`
+	Options       *ListOptions `protobuf:"bytes,5,opt,name=options,proto3"`
+}
+
+func (this *ListSnapshotsRequest) Reset()         { *this = ListSnapshotsRequest{} }
+func (this *ListSnapshotsRequest) String() string { return proto.CompactTextString(this) }
+func (*ListSnapshotsRequest) ProtoMessage()       {}
+
+type CopyProtectedEntityRequest struct {
+	Service    string               `protobuf:"bytes,1,opt,name=service,proto3"`
+	Mode       string               `protobuf:"bytes,2,opt,name=mode,proto3"`
+	Info       *ProtectedEntityInfo `protobuf:"bytes,3,opt,name=info,proto3"`
+	ParamsJson []byte               `protobuf:"bytes,4,opt,name=params_json,json=paramsJson,proto3"`
+}
+
+func (this *CopyProtectedEntityRequest) Reset()         { *this = CopyProtectedEntityRequest{} }
+func (this *CopyProtectedEntityRequest) String() string { return proto.CompactTextString(this) }
+func (*CopyProtectedEntityRequest) ProtoMessage()       {}
+
+type TaskID struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3"`
+}
+
+func (this *TaskID) Reset()         { *this = TaskID{} }
+func (this *TaskID) String() string { return proto.CompactTextString(this) }
+func (*TaskID) ProtoMessage()       {}
+
+type TaskIDList struct {
+	Ids []string `protobuf:"bytes,1,rep,name=ids,proto3"`
+}
+
+func (this *TaskIDList) Reset()         { *this = TaskIDList{} }
+func (this *TaskIDList) String() string { return proto.CompactTextString(this) }
+func (*TaskIDList) ProtoMessage()       {}
+
+type TaskInfo struct {
+	Id             string      `protobuf:"bytes,1,opt,name=id,proto3"`
+	Details        string      `protobuf:"bytes,2,opt,name=details,proto3"`
+	Status         string      `protobuf:"bytes,3,opt,name=status,proto3"`
+	Completed      bool        `protobuf:"varint,4,opt,name=completed,proto3"`
+	Progress       float64     `protobuf:"fixed64,5,opt,name=progress,proto3"`
+	StartedTimeNs  int64       `protobuf:"varint,6,opt,name=started_time_ns,json=startedTimeNs,proto3"`
+	FinishedTimeNs int64       `protobuf:"varint,7,opt,name=finished_time_ns,json=finishedTimeNs,proto3"`
+	ResultJson     []byte      `protobuf:"bytes,8,opt,name=result_json,json=resultJson,proto3"`
+	QueuePosition  int64       `protobuf:"varint,9,opt,name=queue_position,json=queuePosition,proto3"`
+	Locks          []*TaskLock `protobuf:"bytes,10,rep,name=locks,proto3"`
+}
+
+func (this *TaskInfo) Reset()         { *this = TaskInfo{} }
+func (this *TaskInfo) String() string { return proto.CompactTextString(this) }
+func (*TaskInfo) ProtoMessage()       {}
+
+type TaskLock struct {
+	ProtectedEntityId string `protobuf:"bytes,1,opt,name=protected_entity_id,json=protectedEntityId,proto3"`
+	Mode              string `protobuf:"bytes,2,opt,name=mode,proto3"`
+	State             string `protobuf:"bytes,3,opt,name=state,proto3"`
+}
+
+func (this *TaskLock) Reset()         { *this = TaskLock{} }
+func (this *TaskLock) String() string { return proto.CompactTextString(this) }
+func (*TaskLock) ProtoMessage()       {}
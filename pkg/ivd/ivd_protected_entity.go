@@ -287,6 +287,9 @@ const waitTime = 3600 * time.Second
  */
 func (this IVDProtectedEntity) Snapshot(ctx context.Context, params map[string]map[string]interface{}) (astrolabe.ProtectedEntitySnapshotID, error) {
 	this.logger.Infof("CreateSnapshot called on IVD Protected Entity, %v", this.id.String())
+	if err := astrolabe.CheckNoLabelParams(this.id, params); err != nil {
+		return astrolabe.ProtectedEntitySnapshotID{}, err
+	}
 	var retVal astrolabe.ProtectedEntitySnapshotID
 	retryInterval := time.Second
 	err := wait.PollImmediate(retryInterval, time.Hour, func() (bool, error) {
@@ -414,7 +417,7 @@ func (this IVDProtectedEntity) GetID() astrolabe.ProtectedEntityID {
 func (this IVDProtectedEntity) Overwrite(ctx context.Context, sourcePE astrolabe.ProtectedEntity, params map[string]map[string]interface{},
 	overwriteComponents bool) error {
 	// overwriteComponents is ignored because we have no components
-	if sourcePE.GetID().GetPeType() != "ivd" {
+	if sourcePE.GetID().GetBasePeType() != ivdPEType {
 		return errors.New("Overwrite source must be an ivd")
 	}
 	// TODO - verify that our size is >= sourcePE size
@@ -446,7 +449,7 @@ func NewIDFromString(idStr string) vim.ID {
 }
 
 func NewVimIDFromPEID(peid astrolabe.ProtectedEntityID) vim.ID {
-	if peid.GetPeType() == "ivd" {
+	if peid.GetBasePeType() == ivdPEType {
 		return vim.ID{
 			Id: peid.GetID(),
 		}
@@ -33,16 +33,19 @@ import (
 	"github.com/vmware/gvddk/gDiskLib"
 	"io"
 	"net/url"
+	"sync"
 	"time"
 )
 
 type IVDProtectedEntityTypeManager struct {
+	typeName  string
 	client    *govmomi.Client
 	vsom      *vslm.GlobalObjectManager
 	cnsClient *cns.Client
 	s3Config  astrolabe.S3Config
 	vcParams  map[string]interface{} // Save the VC configuration params
 	logger    logrus.FieldLogger
+	closeOnce sync.Once
 }
 
 func NewIVDProtectedEntityTypeManagerFromConfig(params map[string]interface{}, s3Config astrolabe.S3Config,
@@ -139,16 +142,43 @@ const vsphereMajor = 6
 const vSphereMinor = 7
 const disklibLib64 = "/usr/lib/vmware-vix-disklib/lib64"
 
+// VDDK is initialized once for all the IVD type managers and shut down when the last one is closed
+var vddkMutex sync.Mutex
+var vddkUsers int
+
+func initVDDK() error {
+	vddkMutex.Lock()
+	defer vddkMutex.Unlock()
+	if vddkUsers == 0 {
+		err := gDiskLib.Init(vsphereMajor, vSphereMinor, disklibLib64)
+		if err != nil {
+			return err
+		}
+	}
+	vddkUsers++
+	return nil
+}
+
+func exitVDDK() {
+	vddkMutex.Lock()
+	defer vddkMutex.Unlock()
+	vddkUsers--
+	if vddkUsers == 0 {
+		gDiskLib.Exit()
+	}
+}
+
 func newIVDProtectedEntityTypeManagerWithClient(client *govmomi.Client, s3Config astrolabe.S3Config, vslmClient *vslm.Client,
 	cnsClient *cns.Client, logger logrus.FieldLogger) (*IVDProtectedEntityTypeManager, error) {
 
 	vsom := vslm.NewGlobalObjectManager(vslmClient)
 
-	err := gDiskLib.Init(vsphereMajor, vSphereMinor, disklibLib64)
+	err := initVDDK()
 	if err != nil {
 		return nil, errors.Wrap(err, "Could not initialize VDDK")
 	}
 	retVal := IVDProtectedEntityTypeManager{
+		typeName:  ivdPEType,
 		client:    client,
 		vsom:      vsom,
 		cnsClient: cnsClient,
@@ -158,8 +188,56 @@ func newIVDProtectedEntityTypeManagerWithClient(client *govmomi.Client, s3Config
 	return &retVal, nil
 }
 
+const ivdPEType = "ivd"
+
+// How long Close waits for vCenter to end the session
+const logoutTimeout = 30 * time.Second
+
+/*
+Close ends the vCenter session and, if this is the last IVD type manager, shuts down VDDK.  The type manager cannot
+be used afterwards.
+*/
+func (this *IVDProtectedEntityTypeManager) Close() error {
+	var err error
+	this.closeOnce.Do(func() {
+		defer exitVDDK()
+		ctx, cancel := context.WithTimeout(context.Background(), logoutTimeout)
+		defer cancel()
+		if logoutErr := this.client.Logout(ctx); logoutErr != nil {
+			err = errors.Wrapf(logoutErr, "could not log out of %s", this.client.URL().Host)
+		}
+	})
+	return err
+}
+
+/*
+CheckHealth checks that the vCenter session is still logged in.  The session is kept alive, a session that has been
+lost is not logged in again.
+*/
+func (this *IVDProtectedEntityTypeManager) CheckHealth(ctx context.Context) error {
+	userSession, err := this.client.SessionManager.UserSession(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "could not check the session with %s", this.client.URL().Host)
+	}
+	if userSession == nil {
+		return errors.Errorf("not logged in to %s", this.client.URL().Host)
+	}
+	return nil
+}
+
 func (this *IVDProtectedEntityTypeManager) GetTypeName() string {
-	return "ivd"
+	return this.typeName
+}
+
+func (this *IVDProtectedEntityTypeManager) SetInstanceName(instance string) {
+	this.typeName = astrolabe.NewServiceName(ivdPEType, instance)
+}
+
+/*
+newProtectedEntityID returns the ID of the IVD with the type of this instance
+*/
+func (this *IVDProtectedEntityTypeManager) newProtectedEntityID(id types.ID) astrolabe.ProtectedEntityID {
+	return astrolabe.NewProtectedEntityID(this.typeName, id.Id)
 }
 
 func (this *IVDProtectedEntityTypeManager) GetProtectedEntity(ctx context.Context, id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
@@ -171,19 +249,58 @@ func (this *IVDProtectedEntityTypeManager) GetProtectedEntity(ctx context.Contex
 }
 
 func (this *IVDProtectedEntityTypeManager) GetProtectedEntities(ctx context.Context) ([]astrolabe.ProtectedEntityID, error) {
+	return this.queryProtectedEntityIDs(ctx, nil)
+}
+
+/*
+GetProtectedEntitiesPage passes the name and prefix filters to vSphere so that only the matching disks are returned
+*/
+func (this *IVDProtectedEntityTypeManager) GetProtectedEntitiesPage(ctx context.Context,
+	options astrolabe.ListOptions) (astrolabe.ProtectedEntityIDPage, error) {
+	if err := options.Validate(); err != nil {
+		return astrolabe.ProtectedEntityIDPage{}, err
+	}
+	var specs []vslmtypes.VslmVsoVStorageObjectQuerySpec
+	if options.Name != "" {
+		specs = append(specs, vslmtypes.VslmVsoVStorageObjectQuerySpec{
+			QueryField:    string(vslmtypes.VslmVsoVStorageObjectQuerySpecQueryFieldEnumName),
+			QueryOperator: string(vslmtypes.VslmVsoVStorageObjectQuerySpecQueryOperatorEnumEquals),
+			QueryValue:    []string{options.Name},
+		})
+	}
+	if options.Prefix != "" {
+		specs = append(specs, vslmtypes.VslmVsoVStorageObjectQuerySpec{
+			QueryField:    string(vslmtypes.VslmVsoVStorageObjectQuerySpecQueryFieldEnumId),
+			QueryOperator: string(vslmtypes.VslmVsoVStorageObjectQuerySpecQueryOperatorEnumStartsWith),
+			QueryValue:    []string{options.Prefix},
+		})
+	}
+	peids, err := this.queryProtectedEntityIDs(ctx, specs)
+	if err != nil {
+		return astrolabe.ProtectedEntityIDPage{}, err
+	}
+	entries, err := astrolabe.GetListEntries(ctx, this, peids, options)
+	if err != nil {
+		return astrolabe.ProtectedEntityIDPage{}, err
+	}
+	return astrolabe.PageListEntries(entries, options)
+}
+
+func (this *IVDProtectedEntityTypeManager) queryProtectedEntityIDs(ctx context.Context,
+	specs []vslmtypes.VslmVsoVStorageObjectQuerySpec) ([]astrolabe.ProtectedEntityID, error) {
 	// Kludge because of PR
 	spec := vslmtypes.VslmVsoVStorageObjectQuerySpec{
 		QueryField:    "createTime",
 		QueryOperator: "greaterThan",
 		QueryValue:    []string{"0"},
 	}
-	res, err := this.vsom.ListObjectsForSpec(ctx, []vslmtypes.VslmVsoVStorageObjectQuerySpec{spec}, 1000)
+	res, err := this.vsom.ListObjectsForSpec(ctx, append([]vslmtypes.VslmVsoVStorageObjectQuerySpec{spec}, specs...), 1000)
 	if err != nil {
 		return nil, err
 	}
 	retIDs := make([]astrolabe.ProtectedEntityID, len(res.Id))
 	for idNum, curVSOID := range res.Id {
-		retIDs[idNum] = newProtectedEntityID(curVSOID)
+		retIDs[idNum] = this.newProtectedEntityID(curVSOID)
 	}
 	return retIDs, nil
 }
@@ -212,7 +329,8 @@ func (this *IVDProtectedEntityTypeManager) Copy(ctx context.Context, sourcePE as
 	if err != nil {
 		return nil, errors.Wrap(err, "GetMetadataReader failed")
 	}
-	returnPE, err := this.copyInt(ctx, sourcePEInfo, options, dataReader, metadataReader)
+	returnPE, err := this.copyInt(ctx, sourcePEInfo, options, astrolabe.NewProgressReader(ctx, dataReader),
+		metadataReader)
 	if err != nil {
 		return nil, errors.Wrap(err, "copyInt failed")
 	}
@@ -235,7 +353,7 @@ func (this backingSpec) GetVslmCreateSpecBackingSpec() *types.VslmCreateSpecBack
 func (this *IVDProtectedEntityTypeManager) copyInt(ctx context.Context, sourcePEInfo astrolabe.ProtectedEntityInfo,
 	options astrolabe.CopyCreateOptions, dataReader io.Reader, metadataReader io.Reader) (astrolabe.ProtectedEntity, error) {
 	this.logger.Debug("ivd PETM copyInt called")
-	if sourcePEInfo.GetID().GetPeType() != "ivd" {
+	if sourcePEInfo.GetID().GetBasePeType() != ivdPEType {
 		return nil, errors.New("Copy source must be an ivd")
 	}
 	ourVC := false
@@ -309,7 +427,7 @@ func (this *IVDProtectedEntityTypeManager) copyInt(ctx context.Context, sourcePE
 			return nil, err
 		}
 		newVSO := retVal.(types.VStorageObject)
-		retPE, err = newIVDProtectedEntity(this, newProtectedEntityID(newVSO.Config.Id))
+		retPE, err = newIVDProtectedEntity(this, this.newProtectedEntityID(newVSO.Config.Id))
 
 		// if there is any local snasphot, we need to call updateMetadata explicitly
 		// since CreateDiskFromSnapshot doesn't accept metadata as a param. The API need to be changed accordingly.
@@ -335,7 +453,7 @@ func (this *IVDProtectedEntityTypeManager) copyInt(ctx context.Context, sourcePE
 		if err != nil {
 			return nil, errors.Wrap(err, "CreateDisk failed")
 		}
-		retPE, err = newIVDProtectedEntity(this, newProtectedEntityID(volumeVimID))
+		retPE, err = newIVDProtectedEntity(this, this.newProtectedEntityID(volumeVimID))
 		if err != nil {
 			return nil, errors.Wrap(err, "CreateDisk failed")
 		}
@@ -353,6 +471,17 @@ func (this *IVDProtectedEntityTypeManager) copyInt(ctx context.Context, sourcePE
 	return retPE, nil
 }
 
+/*
+GetDatastore returns the managed object ID of the datastore that backs the IVD, snapshots are on the same datastore
+*/
+func (this *IVDProtectedEntityTypeManager) GetDatastore(ctx context.Context, id astrolabe.ProtectedEntityID) (string, error) {
+	vso, err := this.vsom.Retrieve(ctx, NewVimIDFromPEID(id))
+	if err != nil {
+		return "", errors.Wrapf(err, "could not retrieve %s", id.String())
+	}
+	return vso.Config.Backing.GetBaseConfigInfoBackingInfo().Datastore.Value, nil
+}
+
 func (this *IVDProtectedEntityTypeManager) getDataTransports(id astrolabe.ProtectedEntityID) ([]astrolabe.DataTransport,
 	[]astrolabe.DataTransport,
 	[]astrolabe.DataTransport, error) {
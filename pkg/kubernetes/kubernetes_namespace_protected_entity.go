@@ -60,6 +60,9 @@ func (this *KubernetesNamespaceProtectedEntity) GetCombinedInfo(ctx context.Cont
 }
 
 func (this *KubernetesNamespaceProtectedEntity) Snapshot(ctx context.Context, params map[string]map[string]interface{}) (astrolabe.ProtectedEntitySnapshotID, error) {
+	if err := astrolabe.CheckNoLabelParams(this.GetID(), params); err != nil {
+		return astrolabe.ProtectedEntitySnapshotID{}, err
+	}
 	return astrolabe.ProtectedEntitySnapshotID{}, nil
 
 }
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package metrics exports Prometheus metrics for Astrolabe.  Type managers are instrumented by wrapping them with
+NewMetricsProtectedEntityTypeManager, tasks and the S3 repository report through the functions below.  The metrics
+are registered with the default Prometheus registry and served by Handler.
+*/
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "astrolabe"
+
+	// Path is where astrolabe_server serves the metrics
+	Path = "/metrics"
+)
+
+// Operations recorded for each type manager
+const (
+	SnapshotOperation       = "snapshot"
+	DeleteSnapshotOperation = "delete_snapshot"
+	CopyOperation           = "copy"
+	GetDataReaderOperation  = "get_data_reader"
+)
+
+var (
+	operationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "type_manager",
+		Name:      "operations_total",
+		Help:      "Number of operations started, by type manager and operation",
+	}, []string{"type", "operation"})
+	operationErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "type_manager",
+		Name:      "operation_errors_total",
+		Help:      "Number of operations that failed, by type manager and operation",
+	}, []string{"type", "operation"})
+	operationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "type_manager",
+		Name:      "operation_duration_seconds",
+		Help:      "Latency of operations, by type manager and operation",
+		// 10ms to about 45 minutes
+		Buckets: prometheus.ExponentialBuckets(0.01, 4, 10),
+	}, []string{"type", "operation"})
+	copyBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "type_manager",
+		Name:      "copy_bytes",
+		Help:      "Bytes of data and metadata read from the source of each copy, by destination type manager",
+		// 64KiB to 16TiB
+		Buckets: prometheus.ExponentialBuckets(64*1024, 4, 15),
+	}, []string{"type"})
+
+	tasksInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "tasks",
+		Name:      "in_flight",
+		Help:      "Number of tasks that are running",
+	})
+	tasksCompletedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tasks",
+		Name:      "completed_total",
+		Help:      "Number of tasks that finished, by status",
+	}, []string{"status"})
+
+	segmentUploadsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "s3repository",
+		Name:      "segment_uploads_total",
+		Help:      "Number of segment uploads to the S3 repository, by result",
+	}, []string{"result"})
+	segmentBytesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "s3repository",
+		Name:      "segment_bytes_total",
+		Help:      "Bytes uploaded in segments to the S3 repository",
+	})
+	s3RetriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "s3repository",
+		Name:      "request_retries_total",
+		Help:      "Number of S3 requests retried by the S3 repository, by S3 operation",
+	}, []string{"operation"})
+)
+
+func init() {
+	prometheus.MustRegister(operationsTotal, operationErrorsTotal, operationDuration, copyBytes, tasksInFlight,
+		tasksCompletedTotal, segmentUploadsTotal, segmentBytesTotal, s3RetriesTotal)
+}
+
+/*
+Handler serves the metrics in the Prometheus text format
+*/
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+/*
+TaskStarted is called when a task starts running and TaskFinished when it finishes with status, e.g. success
+*/
+func TaskStarted() {
+	tasksInFlight.Inc()
+}
+
+func TaskFinished(status string) {
+	tasksInFlight.Dec()
+	tasksCompletedTotal.WithLabelValues(status).Inc()
+}
+
+/*
+SegmentUploaded records the upload of a segment to the S3 repository.  bytes is the number of bytes uploaded and err
+is the result of the upload.
+*/
+func SegmentUploaded(bytes int64, err error) {
+	if err != nil {
+		segmentUploadsTotal.WithLabelValues("failure").Inc()
+		return
+	}
+	segmentUploadsTotal.WithLabelValues("success").Inc()
+	segmentBytesTotal.Add(float64(bytes))
+}
+
+/*
+InstrumentS3Handlers counts the retries of requests sent with handlers.  It should be called on the handlers of a
+session before clients are created from it.
+*/
+func InstrumentS3Handlers(handlers *request.Handlers) {
+	handlers.Send.PushFrontNamed(request.NamedHandler{
+		Name: "astrolabe.metrics.CountRetries",
+		Fn: func(r *request.Request) {
+			// The send handlers run for each attempt, RetryCount is the number of attempts before this one
+			if r.RetryCount > 0 {
+				s3RetriesTotal.WithLabelValues(r.Operation.Name).Inc()
+			}
+		},
+	})
+}
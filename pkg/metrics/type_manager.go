@@ -0,0 +1,189 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+ProtectedEntityTypeManager decorates a type manager with metrics.  Copy and CopyFromInfo are recorded here, the
+protected entities it returns are decorated to record Snapshot, DeleteSnapshot and GetDataReader.  The optional
+interfaces of the wrapped type manager are found with astrolabe.UnwrapProtectedEntityTypeManager.
+*/
+type ProtectedEntityTypeManager struct {
+	astrolabe.ProtectedEntityTypeManager
+}
+
+/*
+NewMetricsProtectedEntityTypeManager wraps petm with metrics.  petm is returned as is if it already records metrics.
+*/
+func NewMetricsProtectedEntityTypeManager(petm astrolabe.ProtectedEntityTypeManager) astrolabe.ProtectedEntityTypeManager {
+	if _, ok := petm.(*ProtectedEntityTypeManager); ok {
+		return petm
+	}
+	return &ProtectedEntityTypeManager{
+		ProtectedEntityTypeManager: petm,
+	}
+}
+
+func (this *ProtectedEntityTypeManager) Unwrap() astrolabe.ProtectedEntityTypeManager {
+	return this.ProtectedEntityTypeManager
+}
+
+func (this *ProtectedEntityTypeManager) GetProtectedEntity(ctx context.Context,
+	id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
+	pe, err := this.ProtectedEntityTypeManager.GetProtectedEntity(ctx, id)
+	return this.wrap(pe), err
+}
+
+func (this *ProtectedEntityTypeManager) Copy(ctx context.Context, pe astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	source := &copySourceProtectedEntity{
+		ProtectedEntity: pe,
+	}
+	done := startOperation(this.GetTypeName(), CopyOperation)
+	newPE, err := this.ProtectedEntityTypeManager.Copy(ctx, source, params, options)
+	done(err)
+	if err == nil {
+		copyBytes.WithLabelValues(this.GetTypeName()).Observe(float64(atomic.LoadInt64(&source.bytesRead)))
+	}
+	return this.wrap(newPE), err
+}
+
+func (this *ProtectedEntityTypeManager) CopyFromInfo(ctx context.Context, info astrolabe.ProtectedEntityInfo,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	done := startOperation(this.GetTypeName(), CopyOperation)
+	newPE, err := this.ProtectedEntityTypeManager.CopyFromInfo(ctx, info, params, options)
+	done(err)
+	return this.wrap(newPE), err
+}
+
+func (this *ProtectedEntityTypeManager) wrap(pe astrolabe.ProtectedEntity) astrolabe.ProtectedEntity {
+	if pe == nil {
+		return nil
+	}
+	return &protectedEntity{
+		ProtectedEntity: pe,
+		typeName:        this.GetTypeName(),
+	}
+}
+
+/*
+startOperation counts an operation and returns a function to call with the result when it finishes
+*/
+func startOperation(typeName string, operation string) func(err error) {
+	start := time.Now()
+	operationsTotal.WithLabelValues(typeName, operation).Inc()
+	return func(err error) {
+		operationDuration.WithLabelValues(typeName, operation).Observe(time.Since(start).Seconds())
+		if err != nil {
+			operationErrorsTotal.WithLabelValues(typeName, operation).Inc()
+		}
+	}
+}
+
+/*
+protectedEntity records the operations of a protected entity returned by a ProtectedEntityTypeManager
+*/
+type protectedEntity struct {
+	astrolabe.ProtectedEntity
+	typeName string
+}
+
+func (this *protectedEntity) Snapshot(ctx context.Context,
+	params map[string]map[string]interface{}) (astrolabe.ProtectedEntitySnapshotID, error) {
+	done := startOperation(this.typeName, SnapshotOperation)
+	snapshotID, err := this.ProtectedEntity.Snapshot(ctx, params)
+	done(err)
+	return snapshotID, err
+}
+
+func (this *protectedEntity) DeleteSnapshot(ctx context.Context, snapshotToDelete astrolabe.ProtectedEntitySnapshotID,
+	params map[string]map[string]interface{}) (bool, error) {
+	done := startOperation(this.typeName, DeleteSnapshotOperation)
+	deleted, err := this.ProtectedEntity.DeleteSnapshot(ctx, snapshotToDelete, params)
+	done(err)
+	return deleted, err
+}
+
+/*
+GetDataReader records the time taken to open the reader, not the time taken to read it
+*/
+func (this *protectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	done := startOperation(this.typeName, GetDataReaderOperation)
+	reader, err := this.ProtectedEntity.GetDataReader(ctx)
+	done(err)
+	return reader, err
+}
+
+func (this *protectedEntity) GetComponents(ctx context.Context) ([]astrolabe.ProtectedEntity, error) {
+	components, err := this.ProtectedEntity.GetComponents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wrapped := make([]astrolabe.ProtectedEntity, len(components))
+	for curComponent, component := range components {
+		wrapped[curComponent] = &protectedEntity{
+			ProtectedEntity: component,
+			typeName:        component.GetID().GetPeType(),
+		}
+	}
+	return wrapped, nil
+}
+
+/*
+copySourceProtectedEntity counts the bytes the type manager reads from the source of a copy
+*/
+type copySourceProtectedEntity struct {
+	astrolabe.ProtectedEntity
+	bytesRead int64
+}
+
+func (this *copySourceProtectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	return this.countReader(this.ProtectedEntity.GetDataReader(ctx))
+}
+
+func (this *copySourceProtectedEntity) GetMetadataReader(ctx context.Context) (io.ReadCloser, error) {
+	return this.countReader(this.ProtectedEntity.GetMetadataReader(ctx))
+}
+
+func (this *copySourceProtectedEntity) countReader(reader io.ReadCloser, err error) (io.ReadCloser, error) {
+	if reader == nil || err != nil {
+		return reader, err
+	}
+	return &countingReader{
+		ReadCloser: reader,
+		count:      &this.bytesRead,
+	}, nil
+}
+
+type countingReader struct {
+	io.ReadCloser
+	count *int64
+}
+
+func (this *countingReader) Read(p []byte) (int, error) {
+	bytesRead, err := this.ReadCloser.Read(p)
+	atomic.AddInt64(this.count, int64(bytesRead))
+	return bytesRead, err
+}
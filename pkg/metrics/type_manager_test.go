@@ -0,0 +1,187 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"gotest.tools/assert"
+)
+
+/*
+stubTypeManager and stubProtectedEntity implement only what the tests call, the embedded interfaces are nil
+*/
+type stubTypeManager struct {
+	astrolabe.ProtectedEntityTypeManager
+	typeName string
+	copyErr  error
+}
+
+func (this *stubTypeManager) GetTypeName() string {
+	return this.typeName
+}
+
+func (this *stubTypeManager) GetProtectedEntity(ctx context.Context,
+	id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
+	return &stubProtectedEntity{id: id}, nil
+}
+
+func (this *stubTypeManager) Copy(ctx context.Context, pe astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	if this.copyErr != nil {
+		return nil, this.copyErr
+	}
+	dataReader, err := pe.GetDataReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(dataReader)
+	if err != nil {
+		return nil, err
+	}
+	return &stubProtectedEntity{
+		id:   astrolabe.NewProtectedEntityID(this.typeName, "copy"),
+		data: data,
+	}, nil
+}
+
+type stubProtectedEntity struct {
+	astrolabe.ProtectedEntity
+	id          astrolabe.ProtectedEntityID
+	data        []byte
+	snapshotErr error
+}
+
+func (this *stubProtectedEntity) GetID() astrolabe.ProtectedEntityID {
+	return this.id
+}
+
+func (this *stubProtectedEntity) Snapshot(ctx context.Context,
+	params map[string]map[string]interface{}) (astrolabe.ProtectedEntitySnapshotID, error) {
+	return astrolabe.NewProtectedEntitySnapshotID("snap"), this.snapshotErr
+}
+
+func (this *stubProtectedEntity) DeleteSnapshot(ctx context.Context,
+	snapshotToDelete astrolabe.ProtectedEntitySnapshotID, params map[string]map[string]interface{}) (bool, error) {
+	return false, errors.New("snapshot not found")
+}
+
+func (this *stubProtectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(this.data)), nil
+}
+
+func (this *stubProtectedEntity) GetMetadataReader(ctx context.Context) (io.ReadCloser, error) {
+	return nil, nil
+}
+
+func copyBytesSum(t *testing.T, typeName string) (uint64, float64) {
+	metric := &dto.Metric{}
+	err := copyBytes.WithLabelValues(typeName).(prometheus.Metric).Write(metric)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return metric.GetHistogram().GetSampleCount(), metric.GetHistogram().GetSampleSum()
+}
+
+func TestMetricsProtectedEntityTypeManager(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubTypeManager{typeName: "metrics-test"}
+	petm := NewMetricsProtectedEntityTypeManager(stub)
+	assert.Equal(t, petm, NewMetricsProtectedEntityTypeManager(petm))
+	assert.Equal(t, astrolabe.ProtectedEntityTypeManager(stub), astrolabe.UnwrapProtectedEntityTypeManager(petm))
+
+	pe, err := petm.GetProtectedEntity(ctx, astrolabe.NewProtectedEntityID("metrics-test", "a"))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, err = pe.Snapshot(ctx, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, err = pe.DeleteSnapshot(ctx, astrolabe.NewProtectedEntitySnapshotID("snap"), nil)
+	assert.ErrorContains(t, err, "snapshot not found")
+	_, err = pe.GetDataReader(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1.0, testutil.ToFloat64(operationsTotal.WithLabelValues("metrics-test", SnapshotOperation)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(operationErrorsTotal.WithLabelValues("metrics-test", SnapshotOperation)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(operationsTotal.WithLabelValues("metrics-test", DeleteSnapshotOperation)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(operationErrorsTotal.WithLabelValues("metrics-test",
+		DeleteSnapshotOperation)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(operationsTotal.WithLabelValues("metrics-test", GetDataReaderOperation)))
+
+	// The bytes the type manager reads from the source are observed when the copy succeeds
+	source := &stubProtectedEntity{
+		id:   astrolabe.NewProtectedEntityID("other", "b"),
+		data: make([]byte, 1000),
+	}
+	newPE, err := petm.Copy(ctx, source, nil, astrolabe.AllocateNewObject)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, err = newPE.Snapshot(ctx, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 2.0, testutil.ToFloat64(operationsTotal.WithLabelValues("metrics-test", SnapshotOperation)))
+	count, sum := copyBytesSum(t, "metrics-test")
+	assert.Equal(t, uint64(1), count)
+	assert.Equal(t, 1000.0, sum)
+
+	stub.copyErr = errors.New("copy failed")
+	_, err = petm.Copy(ctx, source, nil, astrolabe.AllocateNewObject)
+	assert.ErrorContains(t, err, "copy failed")
+	assert.Equal(t, 2.0, testutil.ToFloat64(operationsTotal.WithLabelValues("metrics-test", CopyOperation)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(operationErrorsTotal.WithLabelValues("metrics-test", CopyOperation)))
+	count, _ = copyBytesSum(t, "metrics-test")
+	assert.Equal(t, uint64(1), count)
+}
+
+func TestMetricsHandler(t *testing.T) {
+	inFlight := testutil.ToFloat64(tasksInFlight)
+	TaskStarted()
+	assert.Equal(t, inFlight+1, testutil.ToFloat64(tasksInFlight))
+	TaskFinished(astrolabe.Success.String())
+	assert.Equal(t, inFlight, testutil.ToFloat64(tasksInFlight))
+
+	SegmentUploaded(100, nil)
+	SegmentUploaded(0, errors.New("upload failed"))
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", Path, nil))
+	body := recorder.Body.String()
+	for _, expected := range []string{
+		`astrolabe_tasks_completed_total{status="success"} 1`,
+		`astrolabe_s3repository_segment_uploads_total{result="success"} 1`,
+		`astrolabe_s3repository_segment_uploads_total{result="failure"} 1`,
+		`astrolabe_s3repository_segment_bytes_total 100`,
+	} {
+		assert.Assert(t, strings.Contains(body, expected), expected)
+	}
+}
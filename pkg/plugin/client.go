@@ -0,0 +1,296 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+ProtectedEntityTypeManager is the host side of a plugin, it implements astrolabe.ProtectedEntityTypeManager by calling
+the plugin
+*/
+type ProtectedEntityTypeManager struct {
+	typeName string
+	client   ProtectedEntityTypeManagerClient
+	peClient ProtectedEntityClient
+	logger   logrus.FieldLogger
+}
+
+func (this *ProtectedEntityTypeManager) GetTypeName() string {
+	return this.typeName
+}
+
+func (this *ProtectedEntityTypeManager) GetProtectedEntity(ctx context.Context,
+	id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
+	response, err := this.client.GetProtectedEntity(ctx, &ProtectedEntityID{Id: id.String()})
+	if err != nil {
+		return nil, errorFromStatus(err)
+	}
+	return this.newProtectedEntity(response.Id)
+}
+
+func (this *ProtectedEntityTypeManager) newProtectedEntity(idStr string) (astrolabe.ProtectedEntity, error) {
+	id, err := astrolabe.NewProtectedEntityIDFromString(idStr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "plugin %s returned an invalid protected entity ID", this.typeName)
+	}
+	return &protectedEntity{
+		petm: this,
+		id:   id,
+	}, nil
+}
+
+func (this *ProtectedEntityTypeManager) GetProtectedEntities(ctx context.Context) ([]astrolabe.ProtectedEntityID, error) {
+	response, err := this.client.GetProtectedEntities(ctx, &Empty{})
+	if err != nil {
+		return nil, errorFromStatus(err)
+	}
+	return idsFromProto(response)
+}
+
+func (this *ProtectedEntityTypeManager) Copy(ctx context.Context, pe astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	paramsJSON, err := paramsToJSON(params)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := this.client.Copy(ctx)
+	if err != nil {
+		return nil, errorFromStatus(err)
+	}
+	header := &CopyHeader{
+		ParamsJson: paramsJSON,
+		Options:    int32(options),
+	}
+	response := &ProtectedEntityID{}
+	if err := sendSource(ctx, stream, header, pe, response); err != nil {
+		return nil, err
+	}
+	return this.newProtectedEntity(response.Id)
+}
+
+/*
+sendSource sends the info, metadata and data of source to a Copy or Overwrite stream
+*/
+func sendSource(ctx context.Context, stream CopyChunkSender, header *CopyHeader, source astrolabe.ProtectedEntity,
+	response interface{}) error {
+	sourceInfo, err := source.GetInfo(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "could not get info for %s", source.GetID().String())
+	}
+	header.SourceInfo = infoToProto(sourceInfo)
+	metadataReader, err := source.GetMetadataReader(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "could not get metadata reader for %s", source.GetID().String())
+	}
+	if metadataReader != nil {
+		defer metadataReader.Close()
+	}
+	dataReader, err := source.GetDataReader(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "could not get data reader for %s", source.GetID().String())
+	}
+	if dataReader != nil {
+		defer dataReader.Close()
+	}
+	// A nil io.ReadCloser in an io.Reader is not nil
+	var metadata, data io.Reader
+	if metadataReader != nil {
+		metadata = metadataReader
+	}
+	if dataReader != nil {
+		data = dataReader
+	}
+	return errorFromStatus(sendCopyStream(stream, header, metadata, data, response))
+}
+
+func (this *ProtectedEntityTypeManager) CopyFromInfo(ctx context.Context, info astrolabe.ProtectedEntityInfo,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	paramsJSON, err := paramsToJSON(params)
+	if err != nil {
+		return nil, err
+	}
+	response, err := this.client.CopyFromInfo(ctx, &CopyFromInfoRequest{
+		Info:       infoToProto(info),
+		ParamsJson: paramsJSON,
+		Options:    int32(options),
+	})
+	if err != nil {
+		return nil, errorFromStatus(err)
+	}
+	return this.newProtectedEntity(response.Id)
+}
+
+/*
+protectedEntity is a Protected Entity served by a plugin
+*/
+type protectedEntity struct {
+	petm *ProtectedEntityTypeManager
+	id   astrolabe.ProtectedEntityID
+}
+
+func (this *protectedEntity) GetID() astrolabe.ProtectedEntityID {
+	return this.id
+}
+
+func (this *protectedEntity) protoID() *ProtectedEntityID {
+	return &ProtectedEntityID{
+		Id: this.id.String(),
+	}
+}
+
+func (this *protectedEntity) GetInfo(ctx context.Context) (astrolabe.ProtectedEntityInfo, error) {
+	response, err := this.petm.peClient.GetInfo(ctx, this.protoID())
+	if err != nil {
+		return nil, errorFromStatus(err)
+	}
+	return infoFromProto(response)
+}
+
+func (this *protectedEntity) GetCombinedInfo(ctx context.Context) ([]astrolabe.ProtectedEntityInfo, error) {
+	response, err := this.petm.peClient.GetCombinedInfo(ctx, this.protoID())
+	if err != nil {
+		return nil, errorFromStatus(err)
+	}
+	return infosFromProto(response)
+}
+
+func (this *protectedEntity) Snapshot(ctx context.Context,
+	params map[string]map[string]interface{}) (astrolabe.ProtectedEntitySnapshotID, error) {
+	paramsJSON, err := paramsToJSON(params)
+	if err != nil {
+		return astrolabe.ProtectedEntitySnapshotID{}, err
+	}
+	response, err := this.petm.peClient.Snapshot(ctx, &SnapshotRequest{
+		Id:         this.id.String(),
+		ParamsJson: paramsJSON,
+	})
+	if err != nil {
+		return astrolabe.ProtectedEntitySnapshotID{}, errorFromStatus(err)
+	}
+	return astrolabe.NewProtectedEntitySnapshotID(response.Id), nil
+}
+
+func (this *protectedEntity) ListSnapshots(ctx context.Context) ([]astrolabe.ProtectedEntitySnapshotID, error) {
+	response, err := this.petm.peClient.ListSnapshots(ctx, this.protoID())
+	if err != nil {
+		return nil, errorFromStatus(err)
+	}
+	snapshotIDs := []astrolabe.ProtectedEntitySnapshotID{}
+	for _, curSnapshotID := range response.Ids {
+		snapshotIDs = append(snapshotIDs, astrolabe.NewProtectedEntitySnapshotID(curSnapshotID))
+	}
+	return snapshotIDs, nil
+}
+
+func (this *protectedEntity) DeleteSnapshot(ctx context.Context, snapshotToDelete astrolabe.ProtectedEntitySnapshotID,
+	params map[string]map[string]interface{}) (bool, error) {
+	paramsJSON, err := paramsToJSON(params)
+	if err != nil {
+		return false, err
+	}
+	response, err := this.petm.peClient.DeleteSnapshot(ctx, &SnapshotRequest{
+		Id:         this.id.String(),
+		SnapshotId: snapshotToDelete.String(),
+		ParamsJson: paramsJSON,
+	})
+	if err != nil {
+		return false, errorFromStatus(err)
+	}
+	return response.Deleted, nil
+}
+
+func (this *protectedEntity) GetInfoForSnapshot(ctx context.Context,
+	snapshotID astrolabe.ProtectedEntitySnapshotID) (*astrolabe.ProtectedEntityInfo, error) {
+	response, err := this.petm.peClient.GetInfoForSnapshot(ctx, &SnapshotRequest{
+		Id:         this.id.String(),
+		SnapshotId: snapshotID.String(),
+	})
+	if err != nil {
+		return nil, errorFromStatus(err)
+	}
+	info, err := infoFromProto(response)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (this *protectedEntity) GetComponents(ctx context.Context) ([]astrolabe.ProtectedEntity, error) {
+	response, err := this.petm.peClient.GetComponents(ctx, this.protoID())
+	if err != nil {
+		return nil, errorFromStatus(err)
+	}
+	components := []astrolabe.ProtectedEntity{}
+	for _, curComponentID := range response.Ids {
+		component, err := this.petm.newProtectedEntity(curComponentID)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, component)
+	}
+	return components, nil
+}
+
+func (this *protectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	// The stream outlives the call, it is cancelled when the reader is closed
+	streamCtx, cancel := context.WithCancel(context.Background())
+	stream, err := this.petm.peClient.GetDataReader(streamCtx, this.protoID())
+	if err != nil {
+		cancel()
+		return nil, errorFromStatus(err)
+	}
+	return newChunkReader(stream, cancel)
+}
+
+func (this *protectedEntity) GetMetadataReader(ctx context.Context) (io.ReadCloser, error) {
+	streamCtx, cancel := context.WithCancel(context.Background())
+	stream, err := this.petm.peClient.GetMetadataReader(streamCtx, this.protoID())
+	if err != nil {
+		cancel()
+		return nil, errorFromStatus(err)
+	}
+	return newChunkReader(stream, cancel)
+}
+
+func (this *protectedEntity) Overwrite(ctx context.Context, sourcePE astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, overwriteComponents bool) error {
+	paramsJSON, err := paramsToJSON(params)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := this.petm.peClient.Overwrite(ctx)
+	if err != nil {
+		return errorFromStatus(err)
+	}
+	header := &CopyHeader{
+		Id:                  this.id.String(),
+		ParamsJson:          paramsJSON,
+		OverwriteComponents: overwriteComponents,
+	}
+	return sendSource(ctx, stream, header, sourcePE, &Empty{})
+}
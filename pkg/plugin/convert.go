@@ -0,0 +1,401 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The size of the data in a CopyChunk or ReadChunk, well under the default 4MB gRPC message limit
+const chunkSize = 1024 * 1024
+
+func infoToProto(info astrolabe.ProtectedEntityInfo) *ProtectedEntityInfo {
+	modelInfo := info.GetModelProtectedEntityInfo()
+	protoInfo := &ProtectedEntityInfo{
+		Id:                 string(modelInfo.ID),
+		DataTransports:     transportsToProto(modelInfo.DataTransports),
+		MetadataTransports: transportsToProto(modelInfo.MetadataTransports),
+		CombinedTransports: transportsToProto(modelInfo.CombinedTransports),
+		Labels:             modelInfo.Labels,
+		Annotations:        modelInfo.Annotations,
+	}
+	if modelInfo.Name != nil {
+		protoInfo.Name = *modelInfo.Name
+	}
+	for _, curComponentSpec := range modelInfo.ComponentSpecs {
+		protoInfo.ComponentIds = append(protoInfo.ComponentIds, string(curComponentSpec.ID))
+	}
+	return protoInfo
+}
+
+func infoFromProto(protoInfo *ProtectedEntityInfo) (astrolabe.ProtectedEntityInfo, error) {
+	if protoInfo == nil {
+		return nil, errors.New("missing protected entity info")
+	}
+	name := protoInfo.Name
+	modelInfo := models.ProtectedEntityInfo{
+		ID:                 models.ProtectedEntityID(protoInfo.Id),
+		Name:               &name,
+		DataTransports:     transportsFromProto(protoInfo.DataTransports),
+		MetadataTransports: transportsFromProto(protoInfo.MetadataTransports),
+		CombinedTransports: transportsFromProto(protoInfo.CombinedTransports),
+		ComponentSpecs:     []*models.ComponentSpec{},
+		Labels:             protoInfo.Labels,
+		Annotations:        protoInfo.Annotations,
+	}
+	for _, curComponentID := range protoInfo.ComponentIds {
+		modelInfo.ComponentSpecs = append(modelInfo.ComponentSpecs, &models.ComponentSpec{
+			ID: models.ProtectedEntityID(curComponentID),
+		})
+	}
+	return astrolabe.NewProtectedEntityInfoFromModel(&modelInfo)
+}
+
+func transportsToProto(transports []*models.DataTransport) []*DataTransport {
+	protoTransports := make([]*DataTransport, len(transports))
+	for transportNum, curTransport := range transports {
+		protoTransports[transportNum] = &DataTransport{
+			TransportType: curTransport.TransportType,
+			Params:        curTransport.Params,
+		}
+	}
+	return protoTransports
+}
+
+func transportsFromProto(protoTransports []*DataTransport) []*models.DataTransport {
+	transports := make([]*models.DataTransport, len(protoTransports))
+	for transportNum, curTransport := range protoTransports {
+		transports[transportNum] = &models.DataTransport{
+			TransportType: curTransport.TransportType,
+			Params:        curTransport.Params,
+		}
+	}
+	return transports
+}
+
+func infosToProto(infos []astrolabe.ProtectedEntityInfo) *ProtectedEntityInfoList {
+	protoInfos := &ProtectedEntityInfoList{}
+	for _, curInfo := range infos {
+		protoInfos.Infos = append(protoInfos.Infos, infoToProto(curInfo))
+	}
+	return protoInfos
+}
+
+func infosFromProto(protoInfos *ProtectedEntityInfoList) ([]astrolabe.ProtectedEntityInfo, error) {
+	infos := []astrolabe.ProtectedEntityInfo{}
+	for _, curInfo := range protoInfos.Infos {
+		info, err := infoFromProto(curInfo)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func idsToProto(ids []astrolabe.ProtectedEntityID) *ProtectedEntityIDList {
+	protoIDs := &ProtectedEntityIDList{}
+	for _, curID := range ids {
+		protoIDs.Ids = append(protoIDs.Ids, curID.String())
+	}
+	return protoIDs
+}
+
+func idsFromProto(protoIDs *ProtectedEntityIDList) ([]astrolabe.ProtectedEntityID, error) {
+	ids := []astrolabe.ProtectedEntityID{}
+	for _, curID := range protoIDs.Ids {
+		id, err := astrolabe.NewProtectedEntityIDFromString(curID)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func paramsToJSON(params map[string]map[string]interface{}) ([]byte, error) {
+	if params == nil {
+		return nil, nil
+	}
+	return json.Marshal(params)
+}
+
+func paramsFromJSON(paramsJSON []byte) (map[string]map[string]interface{}, error) {
+	if len(paramsJSON) == 0 {
+		return nil, nil
+	}
+	params := map[string]map[string]interface{}{}
+	if err := json.Unmarshal(paramsJSON, &params); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal params")
+	}
+	return params, nil
+}
+
+/*
+errorFromStatus returns the message of an error returned by a gRPC call as an error
+*/
+func errorFromStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errors.New(status.Convert(err).Message())
+}
+
+/*
+invalidArgument returns err as an InvalidArgument gRPC error
+*/
+func invalidArgument(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+/*
+sendCopyStream sends header, then the metadata and then the data of a Copy or Overwrite.  Either reader may be nil.
+The response is received into response.
+*/
+func sendCopyStream(stream CopyChunkSender, header *CopyHeader, metadataReader io.Reader, dataReader io.Reader,
+	response interface{}) error {
+	header.NoMetadata = metadataReader == nil
+	header.NoData = dataReader == nil
+	err := stream.Send(&CopyChunk{Header: header})
+	if err == nil && metadataReader != nil {
+		err = sendChunks(metadataReader, func(buf []byte) error {
+			return stream.Send(&CopyChunk{Metadata: buf})
+		})
+	}
+	if err == nil && dataReader != nil {
+		err = sendChunks(dataReader, func(buf []byte) error {
+			return stream.Send(&CopyChunk{Data: buf})
+		})
+	}
+	// Send returns io.EOF if the plugin has stopped receiving, its error is returned by CloseAndRecv
+	if err != nil && err != io.EOF {
+		return err
+	}
+	return stream.CloseAndRecv(response)
+}
+
+func sendChunks(reader io.Reader, send func(buf []byte) error) error {
+	buf := make([]byte, chunkSize)
+	for {
+		bytesRead, err := reader.Read(buf)
+		if bytesRead > 0 {
+			if sendErr := send(buf[:bytesRead]); sendErr != nil {
+				return sendErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+/*
+sendReader sends the contents of reader as ReadChunks.  A nil reader is sent as a single chunk with NoReader set.
+*/
+func sendReader(stream ReadChunkSender, reader io.ReadCloser) error {
+	if reader == nil {
+		return stream.Send(&ReadChunk{NoReader: true})
+	}
+	defer reader.Close()
+	return sendChunks(reader, func(buf []byte) error {
+		return stream.Send(&ReadChunk{Data: buf})
+	})
+}
+
+/*
+chunkReader reads the ReadChunks of a data or metadata reader on the host side.  Close cancels the stream.
+*/
+type chunkReader struct {
+	stream ReadChunkReceiver
+	cancel context.CancelFunc
+	buf    []byte
+}
+
+/*
+newChunkReader returns a reader for stream, or nil if the plugin's reader was nil
+*/
+func newChunkReader(stream ReadChunkReceiver, cancel context.CancelFunc) (io.ReadCloser, error) {
+	chunk, err := stream.Recv()
+	if err == io.EOF {
+		return &chunkReader{stream: stream, cancel: cancel}, nil
+	}
+	if err != nil {
+		cancel()
+		return nil, errorFromStatus(err)
+	}
+	if chunk.NoReader {
+		cancel()
+		return nil, nil
+	}
+	return &chunkReader{
+		stream: stream,
+		cancel: cancel,
+		buf:    chunk.Data,
+	}, nil
+}
+
+func (this *chunkReader) Read(p []byte) (int, error) {
+	for len(this.buf) == 0 {
+		if this.stream == nil {
+			return 0, io.EOF
+		}
+		chunk, err := this.stream.Recv()
+		if err == io.EOF {
+			this.stream = nil
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, errorFromStatus(err)
+		}
+		this.buf = chunk.Data
+	}
+	bytesRead := copy(p, this.buf)
+	this.buf = this.buf[bytesRead:]
+	return bytesRead, nil
+}
+
+func (this *chunkReader) Close() error {
+	this.cancel()
+	return nil
+}
+
+/*
+receivedProtectedEntity is the source of a Copy or Overwrite on the plugin side.  The metadata is held in memory, the
+data is streamed as it is read.  Only GetID, GetInfo and the readers are available.
+*/
+type receivedProtectedEntity struct {
+	astrolabe.ProtectedEntity
+	info       astrolabe.ProtectedEntityInfo
+	metadata   []byte
+	noMetadata bool
+	data       *io.PipeReader
+	done       chan struct{}
+}
+
+func (this *receivedProtectedEntity) GetID() astrolabe.ProtectedEntityID {
+	return this.info.GetID()
+}
+
+func (this *receivedProtectedEntity) GetInfo(ctx context.Context) (astrolabe.ProtectedEntityInfo, error) {
+	return this.info, nil
+}
+
+func (this *receivedProtectedEntity) GetMetadataReader(ctx context.Context) (io.ReadCloser, error) {
+	if this.noMetadata {
+		return nil, nil
+	}
+	return ioutil.NopCloser(bytes.NewReader(this.metadata)), nil
+}
+
+func (this *receivedProtectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	if this.data == nil {
+		return nil, nil
+	}
+	return this.data, nil
+}
+
+/*
+finish stops receiving data and waits for the receiver to stop using the stream
+*/
+func (this *receivedProtectedEntity) finish() {
+	if this.data != nil {
+		this.data.Close()
+		<-this.done
+	}
+}
+
+/*
+receiveCopyStream receives the header and the metadata of a Copy or Overwrite.  The data is received as the returned
+Protected Entity's data reader is read, finish must be called before the handler returns.
+*/
+func receiveCopyStream(stream CopyChunkReceiver) (*CopyHeader, *receivedProtectedEntity, error) {
+	chunk, err := stream.Recv()
+	if err != nil {
+		return nil, nil, err
+	}
+	header := chunk.Header
+	if header == nil {
+		return nil, nil, invalidArgument(errors.New("the first chunk must carry the header"))
+	}
+	info, err := infoFromProto(header.SourceInfo)
+	if err != nil {
+		return nil, nil, invalidArgument(err)
+	}
+	source := &receivedProtectedEntity{
+		info:       info,
+		noMetadata: header.NoMetadata,
+	}
+	var firstData []byte
+	for {
+		chunk, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(chunk.Data) > 0 {
+			firstData = chunk.Data
+			break
+		}
+		source.metadata = append(source.metadata, chunk.Metadata...)
+	}
+	if header.NoData {
+		return header, source, nil
+	}
+	pipeReader, pipeWriter := io.Pipe()
+	source.data = pipeReader
+	source.done = make(chan struct{})
+	go func() {
+		defer close(source.done)
+		if err == io.EOF {
+			pipeWriter.Close()
+			return
+		}
+		if _, err := pipeWriter.Write(firstData); err != nil {
+			return
+		}
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				pipeWriter.Close()
+				return
+			}
+			if err != nil {
+				pipeWriter.CloseWithError(err)
+				return
+			}
+			if _, err := pipeWriter.Write(chunk.Data); err != nil {
+				return
+			}
+		}
+	}()
+	return header, source, nil
+}
@@ -0,0 +1,477 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+/*
+The gRPC services of astrolabe_plugin.proto.  The plugin side implements ProtectedEntityTypeManagerServer and
+ProtectedEntityServer, the host calls them through ProtectedEntityTypeManagerClient and ProtectedEntityClient.
+*/
+
+const (
+	typeManagerServiceName     = "astrolabe.plugin.v1.ProtectedEntityTypeManager"
+	protectedEntityServiceName = "astrolabe.plugin.v1.ProtectedEntity"
+)
+
+/*
+CopyChunkReceiver receives the chunks of a Copy or Overwrite on the plugin side
+*/
+type CopyChunkReceiver interface {
+	Recv() (*CopyChunk, error)
+	Context() context.Context
+}
+
+type copyChunkServerStream struct {
+	grpc.ServerStream
+}
+
+func (this *copyChunkServerStream) Recv() (*CopyChunk, error) {
+	chunk := &CopyChunk{}
+	if err := this.ServerStream.RecvMsg(chunk); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+/*
+ReadChunkSender sends the chunks of a data or metadata reader on the plugin side
+*/
+type ReadChunkSender interface {
+	Send(*ReadChunk) error
+	Context() context.Context
+}
+
+type readChunkServerStream struct {
+	grpc.ServerStream
+}
+
+func (this *readChunkServerStream) Send(chunk *ReadChunk) error {
+	return this.ServerStream.SendMsg(chunk)
+}
+
+type ProtectedEntityTypeManagerServer interface {
+	Init(ctx context.Context, request *InitRequest) (*InitResponse, error)
+	GetTypeName(ctx context.Context, request *Empty) (*TypeName, error)
+	GetProtectedEntity(ctx context.Context, request *ProtectedEntityID) (*ProtectedEntityID, error)
+	GetProtectedEntities(ctx context.Context, request *Empty) (*ProtectedEntityIDList, error)
+	CopyFromInfo(ctx context.Context, request *CopyFromInfoRequest) (*ProtectedEntityID, error)
+	// Copy returns the ID of the new Protected Entity
+	Copy(stream CopyChunkReceiver) (*ProtectedEntityID, error)
+}
+
+type ProtectedEntityServer interface {
+	GetInfo(ctx context.Context, request *ProtectedEntityID) (*ProtectedEntityInfo, error)
+	GetCombinedInfo(ctx context.Context, request *ProtectedEntityID) (*ProtectedEntityInfoList, error)
+	Snapshot(ctx context.Context, request *SnapshotRequest) (*SnapshotID, error)
+	ListSnapshots(ctx context.Context, request *ProtectedEntityID) (*SnapshotIDList, error)
+	DeleteSnapshot(ctx context.Context, request *SnapshotRequest) (*DeleteSnapshotResponse, error)
+	GetInfoForSnapshot(ctx context.Context, request *SnapshotRequest) (*ProtectedEntityInfo, error)
+	GetComponents(ctx context.Context, request *ProtectedEntityID) (*ProtectedEntityIDList, error)
+	GetDataReader(request *ProtectedEntityID, stream ReadChunkSender) error
+	GetMetadataReader(request *ProtectedEntityID, stream ReadChunkSender) error
+	Overwrite(stream CopyChunkReceiver) (*Empty, error)
+}
+
+/*
+unaryHandler returns the grpc.MethodDesc handler for a unary method of a server
+*/
+func unaryHandler(serviceName string, methodName string, newRequest func() interface{},
+	call func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: methodName,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error,
+			interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			request := newRequest()
+			if err := dec(request); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(srv, ctx, request)
+			}
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/" + serviceName + "/" + methodName,
+			}
+			return interceptor(ctx, request, info, func(ctx context.Context, request interface{}) (interface{}, error) {
+				return call(srv, ctx, request)
+			})
+		},
+	}
+}
+
+/*
+copyStreamHandler returns the grpc.StreamDesc for a client streaming method that receives CopyChunks
+*/
+func copyStreamHandler(methodName string, call func(srv interface{}, stream CopyChunkReceiver) (interface{}, error)) grpc.StreamDesc {
+	return grpc.StreamDesc{
+		StreamName: methodName,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			response, err := call(srv, &copyChunkServerStream{stream})
+			if err != nil {
+				return err
+			}
+			return stream.SendMsg(response)
+		},
+		ClientStreams: true,
+	}
+}
+
+/*
+readStreamHandler returns the grpc.StreamDesc for a server streaming method that sends ReadChunks
+*/
+func readStreamHandler(methodName string, call func(srv interface{}, request *ProtectedEntityID,
+	stream ReadChunkSender) error) grpc.StreamDesc {
+	return grpc.StreamDesc{
+		StreamName: methodName,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			request := &ProtectedEntityID{}
+			if err := stream.RecvMsg(request); err != nil {
+				return err
+			}
+			return call(srv, request, &readChunkServerStream{stream})
+		},
+		ServerStreams: true,
+	}
+}
+
+var typeManagerServiceDesc = grpc.ServiceDesc{
+	ServiceName: typeManagerServiceName,
+	HandlerType: (*ProtectedEntityTypeManagerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryHandler(typeManagerServiceName, "Init", func() interface{} { return &InitRequest{} },
+			func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.(ProtectedEntityTypeManagerServer).Init(ctx, request.(*InitRequest))
+			}),
+		unaryHandler(typeManagerServiceName, "GetTypeName", func() interface{} { return &Empty{} },
+			func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.(ProtectedEntityTypeManagerServer).GetTypeName(ctx, request.(*Empty))
+			}),
+		unaryHandler(typeManagerServiceName, "GetProtectedEntity", func() interface{} { return &ProtectedEntityID{} },
+			func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.(ProtectedEntityTypeManagerServer).GetProtectedEntity(ctx, request.(*ProtectedEntityID))
+			}),
+		unaryHandler(typeManagerServiceName, "GetProtectedEntities", func() interface{} { return &Empty{} },
+			func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.(ProtectedEntityTypeManagerServer).GetProtectedEntities(ctx, request.(*Empty))
+			}),
+		unaryHandler(typeManagerServiceName, "CopyFromInfo", func() interface{} { return &CopyFromInfoRequest{} },
+			func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.(ProtectedEntityTypeManagerServer).CopyFromInfo(ctx, request.(*CopyFromInfoRequest))
+			}),
+	},
+	Streams: []grpc.StreamDesc{
+		copyStreamHandler("Copy", func(srv interface{}, stream CopyChunkReceiver) (interface{}, error) {
+			return srv.(ProtectedEntityTypeManagerServer).Copy(stream)
+		}),
+	},
+	Metadata: "astrolabe_plugin.proto",
+}
+
+var protectedEntityServiceDesc = grpc.ServiceDesc{
+	ServiceName: protectedEntityServiceName,
+	HandlerType: (*ProtectedEntityServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryHandler(protectedEntityServiceName, "GetInfo", func() interface{} { return &ProtectedEntityID{} },
+			func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.(ProtectedEntityServer).GetInfo(ctx, request.(*ProtectedEntityID))
+			}),
+		unaryHandler(protectedEntityServiceName, "GetCombinedInfo", func() interface{} { return &ProtectedEntityID{} },
+			func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.(ProtectedEntityServer).GetCombinedInfo(ctx, request.(*ProtectedEntityID))
+			}),
+		unaryHandler(protectedEntityServiceName, "Snapshot", func() interface{} { return &SnapshotRequest{} },
+			func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.(ProtectedEntityServer).Snapshot(ctx, request.(*SnapshotRequest))
+			}),
+		unaryHandler(protectedEntityServiceName, "ListSnapshots", func() interface{} { return &ProtectedEntityID{} },
+			func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.(ProtectedEntityServer).ListSnapshots(ctx, request.(*ProtectedEntityID))
+			}),
+		unaryHandler(protectedEntityServiceName, "DeleteSnapshot", func() interface{} { return &SnapshotRequest{} },
+			func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.(ProtectedEntityServer).DeleteSnapshot(ctx, request.(*SnapshotRequest))
+			}),
+		unaryHandler(protectedEntityServiceName, "GetInfoForSnapshot", func() interface{} { return &SnapshotRequest{} },
+			func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.(ProtectedEntityServer).GetInfoForSnapshot(ctx, request.(*SnapshotRequest))
+			}),
+		unaryHandler(protectedEntityServiceName, "GetComponents", func() interface{} { return &ProtectedEntityID{} },
+			func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+				return srv.(ProtectedEntityServer).GetComponents(ctx, request.(*ProtectedEntityID))
+			}),
+	},
+	Streams: []grpc.StreamDesc{
+		readStreamHandler("GetDataReader", func(srv interface{}, request *ProtectedEntityID,
+			stream ReadChunkSender) error {
+			return srv.(ProtectedEntityServer).GetDataReader(request, stream)
+		}),
+		readStreamHandler("GetMetadataReader", func(srv interface{}, request *ProtectedEntityID,
+			stream ReadChunkSender) error {
+			return srv.(ProtectedEntityServer).GetMetadataReader(request, stream)
+		}),
+		copyStreamHandler("Overwrite", func(srv interface{}, stream CopyChunkReceiver) (interface{}, error) {
+			return srv.(ProtectedEntityServer).Overwrite(stream)
+		}),
+	},
+	Metadata: "astrolabe_plugin.proto",
+}
+
+func RegisterProtectedEntityTypeManagerServer(server *grpc.Server, srv ProtectedEntityTypeManagerServer) {
+	server.RegisterService(&typeManagerServiceDesc, srv)
+}
+
+func RegisterProtectedEntityServer(server *grpc.Server, srv ProtectedEntityServer) {
+	server.RegisterService(&protectedEntityServiceDesc, srv)
+}
+
+/*
+CopyChunkSender sends the chunks of a Copy or Overwrite on the host side.  CloseAndRecv closes the stream and waits for
+the response.
+*/
+type CopyChunkSender interface {
+	Send(*CopyChunk) error
+	CloseAndRecv(response interface{}) error
+}
+
+type copyChunkClientStream struct {
+	grpc.ClientStream
+}
+
+func (this *copyChunkClientStream) Send(chunk *CopyChunk) error {
+	return this.ClientStream.SendMsg(chunk)
+}
+
+func (this *copyChunkClientStream) CloseAndRecv(response interface{}) error {
+	if err := this.ClientStream.CloseSend(); err != nil {
+		return err
+	}
+	return this.ClientStream.RecvMsg(response)
+}
+
+/*
+ReadChunkReceiver receives the chunks of a data or metadata reader on the host side
+*/
+type ReadChunkReceiver interface {
+	Recv() (*ReadChunk, error)
+}
+
+type readChunkClientStream struct {
+	grpc.ClientStream
+}
+
+func (this *readChunkClientStream) Recv() (*ReadChunk, error) {
+	chunk := &ReadChunk{}
+	if err := this.ClientStream.RecvMsg(chunk); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+type ProtectedEntityTypeManagerClient struct {
+	conn *grpc.ClientConn
+}
+
+func NewProtectedEntityTypeManagerClient(conn *grpc.ClientConn) ProtectedEntityTypeManagerClient {
+	return ProtectedEntityTypeManagerClient{
+		conn: conn,
+	}
+}
+
+func (this ProtectedEntityTypeManagerClient) Init(ctx context.Context, request *InitRequest,
+	opts ...grpc.CallOption) (*InitResponse, error) {
+	response := &InitResponse{}
+	err := this.conn.Invoke(ctx, "/"+typeManagerServiceName+"/Init", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this ProtectedEntityTypeManagerClient) GetTypeName(ctx context.Context, request *Empty,
+	opts ...grpc.CallOption) (*TypeName, error) {
+	response := &TypeName{}
+	err := this.conn.Invoke(ctx, "/"+typeManagerServiceName+"/GetTypeName", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this ProtectedEntityTypeManagerClient) GetProtectedEntity(ctx context.Context, request *ProtectedEntityID,
+	opts ...grpc.CallOption) (*ProtectedEntityID, error) {
+	response := &ProtectedEntityID{}
+	err := this.conn.Invoke(ctx, "/"+typeManagerServiceName+"/GetProtectedEntity", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this ProtectedEntityTypeManagerClient) GetProtectedEntities(ctx context.Context, request *Empty,
+	opts ...grpc.CallOption) (*ProtectedEntityIDList, error) {
+	response := &ProtectedEntityIDList{}
+	err := this.conn.Invoke(ctx, "/"+typeManagerServiceName+"/GetProtectedEntities", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this ProtectedEntityTypeManagerClient) CopyFromInfo(ctx context.Context, request *CopyFromInfoRequest,
+	opts ...grpc.CallOption) (*ProtectedEntityID, error) {
+	response := &ProtectedEntityID{}
+	err := this.conn.Invoke(ctx, "/"+typeManagerServiceName+"/CopyFromInfo", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this ProtectedEntityTypeManagerClient) Copy(ctx context.Context, opts ...grpc.CallOption) (CopyChunkSender, error) {
+	stream, err := this.conn.NewStream(ctx, &typeManagerServiceDesc.Streams[0], "/"+typeManagerServiceName+"/Copy",
+		opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &copyChunkClientStream{stream}, nil
+}
+
+type ProtectedEntityClient struct {
+	conn *grpc.ClientConn
+}
+
+func NewProtectedEntityClient(conn *grpc.ClientConn) ProtectedEntityClient {
+	return ProtectedEntityClient{
+		conn: conn,
+	}
+}
+
+func (this ProtectedEntityClient) GetInfo(ctx context.Context, request *ProtectedEntityID,
+	opts ...grpc.CallOption) (*ProtectedEntityInfo, error) {
+	response := &ProtectedEntityInfo{}
+	err := this.conn.Invoke(ctx, "/"+protectedEntityServiceName+"/GetInfo", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this ProtectedEntityClient) GetCombinedInfo(ctx context.Context, request *ProtectedEntityID,
+	opts ...grpc.CallOption) (*ProtectedEntityInfoList, error) {
+	response := &ProtectedEntityInfoList{}
+	err := this.conn.Invoke(ctx, "/"+protectedEntityServiceName+"/GetCombinedInfo", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this ProtectedEntityClient) Snapshot(ctx context.Context, request *SnapshotRequest,
+	opts ...grpc.CallOption) (*SnapshotID, error) {
+	response := &SnapshotID{}
+	err := this.conn.Invoke(ctx, "/"+protectedEntityServiceName+"/Snapshot", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this ProtectedEntityClient) ListSnapshots(ctx context.Context, request *ProtectedEntityID,
+	opts ...grpc.CallOption) (*SnapshotIDList, error) {
+	response := &SnapshotIDList{}
+	err := this.conn.Invoke(ctx, "/"+protectedEntityServiceName+"/ListSnapshots", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this ProtectedEntityClient) DeleteSnapshot(ctx context.Context, request *SnapshotRequest,
+	opts ...grpc.CallOption) (*DeleteSnapshotResponse, error) {
+	response := &DeleteSnapshotResponse{}
+	err := this.conn.Invoke(ctx, "/"+protectedEntityServiceName+"/DeleteSnapshot", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this ProtectedEntityClient) GetInfoForSnapshot(ctx context.Context, request *SnapshotRequest,
+	opts ...grpc.CallOption) (*ProtectedEntityInfo, error) {
+	response := &ProtectedEntityInfo{}
+	err := this.conn.Invoke(ctx, "/"+protectedEntityServiceName+"/GetInfoForSnapshot", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this ProtectedEntityClient) GetComponents(ctx context.Context, request *ProtectedEntityID,
+	opts ...grpc.CallOption) (*ProtectedEntityIDList, error) {
+	response := &ProtectedEntityIDList{}
+	err := this.conn.Invoke(ctx, "/"+protectedEntityServiceName+"/GetComponents", request, response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (this ProtectedEntityClient) GetDataReader(ctx context.Context, request *ProtectedEntityID,
+	opts ...grpc.CallOption) (ReadChunkReceiver, error) {
+	stream, err := this.conn.NewStream(ctx, &protectedEntityServiceDesc.Streams[0],
+		"/"+protectedEntityServiceName+"/GetDataReader", opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(request); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &readChunkClientStream{stream}, nil
+}
+
+func (this ProtectedEntityClient) GetMetadataReader(ctx context.Context, request *ProtectedEntityID,
+	opts ...grpc.CallOption) (ReadChunkReceiver, error) {
+	stream, err := this.conn.NewStream(ctx, &protectedEntityServiceDesc.Streams[1],
+		"/"+protectedEntityServiceName+"/GetMetadataReader", opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(request); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &readChunkClientStream{stream}, nil
+}
+
+func (this ProtectedEntityClient) Overwrite(ctx context.Context, opts ...grpc.CallOption) (CopyChunkSender, error) {
+	stream, err := this.conn.NewStream(ctx, &protectedEntityServiceDesc.Streams[2],
+		"/"+protectedEntityServiceName+"/Overwrite", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &copyChunkClientStream{stream}, nil
+}
@@ -0,0 +1,293 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"google.golang.org/grpc"
+)
+
+const (
+	// DefaultHandshakeTimeout is how long a plugin has to write its handshake and answer Init
+	DefaultHandshakeTimeout = 30 * time.Second
+	// How long a plugin has to exit after its stdin is closed before it is killed
+	exitTimeout = 5 * time.Second
+	// A plugin's parameters are in <plugin>.json next to the plugin binary
+	paramsFileSuffix = ".json"
+)
+
+/*
+Host starts plugin binaries and serves their type managers.  Each plugin binary serves one type manager.
+*/
+type Host struct {
+	mutex            sync.Mutex
+	plugins          []*pluginProcess
+	s3Config         astrolabe.S3Config
+	HandshakeTimeout time.Duration
+	logger           logrus.FieldLogger
+}
+
+type pluginProcess struct {
+	path   string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	conn   *grpc.ClientConn
+	exited chan struct{}
+}
+
+func NewHost(s3Config astrolabe.S3Config, logger logrus.FieldLogger) *Host {
+	return &Host{
+		s3Config:         s3Config,
+		HandshakeTimeout: DefaultHandshakeTimeout,
+		logger:           logger,
+	}
+}
+
+/*
+LoadDir starts the plugin binaries in pluginDir and returns their type managers.  Every executable file is a plugin
+binary, except files whose name starts with '.' or ends in .json.  Plugins that fail to start are logged and skipped.
+*/
+func (this *Host) LoadDir(ctx context.Context, pluginDir string) ([]astrolabe.ProtectedEntityTypeManager, error) {
+	files, err := ioutil.ReadDir(pluginDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not list plugin directory %s", pluginDir)
+	}
+	petms := []astrolabe.ProtectedEntityTypeManager{}
+	for _, curFile := range files {
+		if strings.HasPrefix(curFile.Name(), ".") || strings.HasSuffix(curFile.Name(), paramsFileSuffix) ||
+			!curFile.Mode().IsRegular() || curFile.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		petm, err := this.Start(ctx, filepath.Join(pluginDir, curFile.Name()))
+		if err != nil {
+			this.logger.WithError(err).Errorf("Could not start plugin %s", curFile.Name())
+			continue
+		}
+		this.logger.Infof("Started plugin %s for type %s", curFile.Name(), petm.GetTypeName())
+		petms = append(petms, petm)
+	}
+	return petms, nil
+}
+
+/*
+Start starts the plugin binary at path, does the handshake and calls Init with the parameters in <path>.json
+*/
+func (this *Host) Start(ctx context.Context, path string) (*ProtectedEntityTypeManager, error) {
+	params, err := readPluginParams(path + paramsFileSuffix)
+	if err != nil {
+		return nil, err
+	}
+	s3ConfigJSON, err := json.Marshal(this.s3Config)
+	if err != nil {
+		return nil, err
+	}
+	logger := this.logger.WithField("plugin", filepath.Base(path))
+
+	cmd := exec.Command(path)
+	cmd.Env = append(os.Environ(), MagicCookieKey+"="+MagicCookieValue)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	// Wait closes the pipes from StdoutPipe and StderrPipe while they may still be read, use our own
+	stdout, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, stderrWriter, err := os.Pipe()
+	if err != nil {
+		stdout.Close()
+		stdoutWriter.Close()
+		return nil, err
+	}
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
+	err = cmd.Start()
+	stdoutWriter.Close()
+	stderrWriter.Close()
+	if err != nil {
+		stdout.Close()
+		stderr.Close()
+		return nil, errors.Wrapf(err, "could not start %s", path)
+	}
+	process := &pluginProcess{
+		path:   path,
+		cmd:    cmd,
+		stdin:  stdin,
+		exited: make(chan struct{}),
+	}
+	go func() {
+		copyLines(stderr, logger)
+		stderr.Close()
+	}()
+	go func() {
+		err := cmd.Wait()
+		if err != nil {
+			logger.WithError(err).Info("Plugin exited")
+		}
+		close(process.exited)
+	}()
+
+	petm, err := this.handshake(ctx, process, stdout, params, s3ConfigJSON, logger)
+	if err != nil {
+		process.stop()
+		return nil, err
+	}
+	this.mutex.Lock()
+	this.plugins = append(this.plugins, process)
+	this.mutex.Unlock()
+	return petm, nil
+}
+
+func (this *Host) handshake(ctx context.Context, process *pluginProcess, stdout io.ReadCloser, params []byte,
+	s3ConfigJSON []byte, logger logrus.FieldLogger) (*ProtectedEntityTypeManager, error) {
+	ctx, cancel := context.WithTimeout(ctx, this.HandshakeTimeout)
+	defer cancel()
+	handshakeLine := make(chan string, 1)
+	stdoutReader := bufio.NewReader(stdout)
+	go func() {
+		line, _ := stdoutReader.ReadString('\n')
+		handshakeLine <- line
+		// Anything else the plugin writes to stdout is logged
+		copyLines(stdoutReader, logger)
+		stdout.Close()
+	}()
+	var line string
+	select {
+	case line = <-handshakeLine:
+	case <-process.exited:
+		return nil, errors.Errorf("plugin %s exited before the handshake", process.path)
+	case <-ctx.Done():
+		return nil, errors.Errorf("plugin %s did not write its handshake", process.path)
+	}
+	network, address, err := parseHandshake(line)
+	if err != nil {
+		return nil, errors.Wrapf(err, "plugin %s", process.path)
+	}
+	conn, err := grpc.DialContext(ctx, address, grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		}))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not connect to plugin %s at %s", process.path, address)
+	}
+	process.conn = conn
+	petm := &ProtectedEntityTypeManager{
+		client:   NewProtectedEntityTypeManagerClient(conn),
+		peClient: NewProtectedEntityClient(conn),
+		logger:   logger,
+	}
+	response, err := petm.client.Init(ctx, &InitRequest{
+		ParamsJson:   params,
+		S3ConfigJson: s3ConfigJSON,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(errorFromStatus(err), "plugin %s failed to initialize", process.path)
+	}
+	if response.TypeName == "" {
+		return nil, errors.Errorf("plugin %s did not return a type name", process.path)
+	}
+	petm.typeName = response.TypeName
+	return petm, nil
+}
+
+/*
+parseHandshake parses handshakePrefix|<protocol version>|<network>|<address>
+*/
+func parseHandshake(line string) (network string, address string, err error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 4 || parts[0] != handshakePrefix {
+		return "", "", errors.Errorf("invalid handshake %q", line)
+	}
+	version, err := strconv.Atoi(parts[1])
+	if err != nil || version != ProtocolVersion {
+		return "", "", errors.Errorf("plugin protocol version %s is not supported, expected %d", parts[1],
+			ProtocolVersion)
+	}
+	if parts[2] != "unix" && parts[2] != "tcp" {
+		return "", "", errors.Errorf("invalid handshake network %s", parts[2])
+	}
+	return parts[2], parts[3], nil
+}
+
+func readPluginParams(paramsPath string) ([]byte, error) {
+	paramsJSON, err := ioutil.ReadFile(paramsPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read plugin parameters %s", paramsPath)
+	}
+	params := map[string]interface{}{}
+	if err := json.Unmarshal(paramsJSON, &params); err != nil {
+		return nil, errors.Wrapf(err, "could not unmarshal plugin parameters %s", paramsPath)
+	}
+	return paramsJSON, nil
+}
+
+func copyLines(reader io.Reader, logger logrus.FieldLogger) {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		logger.Info(scanner.Text())
+	}
+}
+
+/*
+stop closes the connection and stdin of the plugin, which tells it to exit.  It is killed if it does not.
+*/
+func (this *pluginProcess) stop() {
+	if this.conn != nil {
+		this.conn.Close()
+	}
+	this.stdin.Close()
+	select {
+	case <-this.exited:
+	case <-time.After(exitTimeout):
+		this.cmd.Process.Kill()
+		<-this.exited
+	}
+}
+
+/*
+Close stops all of the plugins.  The type managers of the plugins cannot be used afterwards.
+*/
+func (this *Host) Close() {
+	this.mutex.Lock()
+	plugins := this.plugins
+	this.plugins = nil
+	this.mutex.Unlock()
+	for _, curPlugin := range plugins {
+		curPlugin.stop()
+	}
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+/*
+The messages of astrolabe_plugin.proto.  They are marshaled by github.com/golang/protobuf from their struct tags.
+*/
+
+type Empty struct {
+}
+
+func (this *Empty) Reset()         { *this = Empty{} }
+func (this *Empty) String() string { return proto.CompactTextString(this) }
+func (*Empty) ProtoMessage()       {}
+
+type InitRequest struct {
+	ParamsJson   []byte `protobuf:"bytes,1,opt,name=params_json,json=paramsJson,proto3"`
+	S3ConfigJson []byte `protobuf:"bytes,2,opt,name=s3_config_json,json=s3ConfigJson,proto3"`
+}
+
+func (this *InitRequest) Reset()         { *this = InitRequest{} }
+func (this *InitRequest) String() string { return proto.CompactTextString(this) }
+func (*InitRequest) ProtoMessage()       {}
+
+type InitResponse struct {
+	TypeName string `protobuf:"bytes,1,opt,name=type_name,json=typeName,proto3"`
+}
+
+func (this *InitResponse) Reset()         { *this = InitResponse{} }
+func (this *InitResponse) String() string { return proto.CompactTextString(this) }
+func (*InitResponse) ProtoMessage()       {}
+
+type TypeName struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3"`
+}
+
+func (this *TypeName) Reset()         { *this = TypeName{} }
+func (this *TypeName) String() string { return proto.CompactTextString(this) }
+func (*TypeName) ProtoMessage()       {}
+
+type ProtectedEntityID struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3"`
+}
+
+func (this *ProtectedEntityID) Reset()         { *this = ProtectedEntityID{} }
+func (this *ProtectedEntityID) String() string { return proto.CompactTextString(this) }
+func (*ProtectedEntityID) ProtoMessage()       {}
+
+type ProtectedEntityIDList struct {
+	Ids []string `protobuf:"bytes,1,rep,name=ids,proto3"`
+}
+
+func (this *ProtectedEntityIDList) Reset()         { *this = ProtectedEntityIDList{} }
+func (this *ProtectedEntityIDList) String() string { return proto.CompactTextString(this) }
+func (*ProtectedEntityIDList) ProtoMessage()       {}
+
+type SnapshotID struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3"`
+}
+
+func (this *SnapshotID) Reset()         { *this = SnapshotID{} }
+func (this *SnapshotID) String() string { return proto.CompactTextString(this) }
+func (*SnapshotID) ProtoMessage()       {}
+
+type SnapshotIDList struct {
+	Ids []string `protobuf:"bytes,1,rep,name=ids,proto3"`
+}
+
+func (this *SnapshotIDList) Reset()         { *this = SnapshotIDList{} }
+func (this *SnapshotIDList) String() string { return proto.CompactTextString(this) }
+func (*SnapshotIDList) ProtoMessage()       {}
+
+type DataTransport struct {
+	TransportType string            `protobuf:"bytes,1,opt,name=transport_type,json=transportType,proto3"`
+	Params        map[string]string `protobuf:"bytes,2,rep,name=params,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (this *DataTransport) Reset()         { *this = DataTransport{} }
+func (this *DataTransport) String() string { return proto.CompactTextString(this) }
+func (*DataTransport) ProtoMessage()       {}
+
+type ProtectedEntityInfo struct {
+	Id                 string            `protobuf:"bytes,1,opt,name=id,proto3"`
+	Name               string            `protobuf:"bytes,2,opt,name=name,proto3"`
+	DataTransports     []*DataTransport  `protobuf:"bytes,3,rep,name=data_transports,json=dataTransports,proto3"`
+	MetadataTransports []*DataTransport  `protobuf:"bytes,4,rep,name=metadata_transports,json=metadataTransports,proto3"`
+	CombinedTransports []*DataTransport  `protobuf:"bytes,5,rep,name=combined_transports,json=combinedTransports,proto3"`
+	ComponentIds       []string          `protobuf:"bytes,6,rep,name=component_ids,json=componentIds,proto3"`
+	Labels             map[string]string `protobuf:"bytes,7,rep,name=labels,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Annotations        map[string]string `protobuf:"bytes,8,rep,name=annotations,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (this *ProtectedEntityInfo) Reset()         { *this = ProtectedEntityInfo{} }
+func (this *ProtectedEntityInfo) String() string { return proto.CompactTextString(this) }
+func (*ProtectedEntityInfo) ProtoMessage()       {}
+
+type ProtectedEntityInfoList struct {
+	Infos []*ProtectedEntityInfo `protobuf:"bytes,1,rep,name=infos,proto3"`
+}
+
+func (this *ProtectedEntityInfoList) Reset()         { *this = ProtectedEntityInfoList{} }
+func (this *ProtectedEntityInfoList) String() string { return proto.CompactTextString(this) }
+func (*ProtectedEntityInfoList) ProtoMessage()       {}
+
+type SnapshotRequest struct {
+	Id         string `protobuf:"bytes,1,opt,name=id,proto3"`
+	SnapshotId string `protobuf:"bytes,2,opt,name=snapshot_id,json=snapshotId,proto3"`
+	ParamsJson []byte `protobuf:"bytes,3,opt,name=params_json,json=paramsJson,proto3"`
+}
+
+func (this *SnapshotRequest) Reset()         { *this = SnapshotRequest{} }
+func (this *SnapshotRequest) String() string { return proto.CompactTextString(this) }
+func (*SnapshotRequest) ProtoMessage()       {}
+
+type DeleteSnapshotResponse struct {
+	Deleted bool `protobuf:"varint,1,opt,name=deleted,proto3"`
+}
+
+func (this *DeleteSnapshotResponse) Reset()         { *this = DeleteSnapshotResponse{} }
+func (this *DeleteSnapshotResponse) String() string { return proto.CompactTextString(this) }
+func (*DeleteSnapshotResponse) ProtoMessage()       {}
+
+type CopyHeader struct {
+	Id                  string               `protobuf:"bytes,1,opt,name=id,proto3"`
+	SourceInfo          *ProtectedEntityInfo `protobuf:"bytes,2,opt,name=source_info,json=sourceInfo,proto3"`
+	ParamsJson          []byte               `protobuf:"bytes,3,opt,name=params_json,json=paramsJson,proto3"`
+	Options             int32                `protobuf:"varint,4,opt,name=options,proto3"`
+	OverwriteComponents bool                 `protobuf:"varint,5,opt,name=overwrite_components,json=overwriteComponents,proto3"`
+	NoMetadata          bool                 `protobuf:"varint,6,opt,name=no_metadata,json=noMetadata,proto3"`
+	NoData              bool                 `protobuf:"varint,7,opt,name=no_data,json=noData,proto3"`
+}
+
+func (this *CopyHeader) Reset()         { *this = CopyHeader{} }
+func (this *CopyHeader) String() string { return proto.CompactTextString(this) }
+func (*CopyHeader) ProtoMessage()       {}
+
+type CopyChunk struct {
+	Header   *CopyHeader `protobuf:"bytes,1,opt,name=header,proto3"`
+	Metadata []byte      `protobuf:"bytes,2,opt,name=metadata,proto3"`
+	Data     []byte      `protobuf:"bytes,3,opt,name=data,proto3"`
+}
+
+func (this *CopyChunk) Reset()         { *this = CopyChunk{} }
+func (this *CopyChunk) String() string { return proto.CompactTextString(this) }
+func (*CopyChunk) ProtoMessage()       {}
+
+type CopyFromInfoRequest struct {
+	Info       *ProtectedEntityInfo `protobuf:"bytes,1,opt,name=info,proto3"`
+	ParamsJson []byte               `protobuf:"bytes,2,opt,name=params_json,json=paramsJson,proto3"`
+	Options    int32                `protobuf:"varint,3,opt,name=options,proto3"`
+}
+
+func (this *CopyFromInfoRequest) Reset()         { *this = CopyFromInfoRequest{} }
+func (this *CopyFromInfoRequest) String() string { return proto.CompactTextString(this) }
+func (*CopyFromInfoRequest) ProtoMessage()       {}
+
+type ReadChunk struct {
+	Data     []byte `protobuf:"bytes,1,opt,name=data,proto3"`
+	NoReader bool   `protobuf:"varint,2,opt,name=no_reader,json=noReader,proto3"`
+}
+
+func (this *ReadChunk) Reset()         { *this = ReadChunk{} }
+func (this *ReadChunk) String() string { return proto.CompactTextString(this) }
+func (*ReadChunk) ProtoMessage()       {}
@@ -0,0 +1,202 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"gotest.tools/assert"
+)
+
+/*
+localProtectedEntity is the source of the copies, only what Copy and Overwrite use is implemented
+*/
+type localProtectedEntity struct {
+	astrolabe.ProtectedEntity
+	id       astrolabe.ProtectedEntityID
+	data     []byte
+	metadata []byte
+}
+
+func (this *localProtectedEntity) GetID() astrolabe.ProtectedEntityID {
+	return this.id
+}
+
+func (this *localProtectedEntity) GetInfo(ctx context.Context) (astrolabe.ProtectedEntityInfo, error) {
+	return astrolabe.NewProtectedEntityInfoWithLabels(this.id, "local", []astrolabe.DataTransport{},
+		[]astrolabe.DataTransport{}, []astrolabe.DataTransport{}, []astrolabe.ProtectedEntityID{},
+		map[string]string{"app": "test"}, nil), nil
+}
+
+func (this *localProtectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(this.data)), nil
+}
+
+func (this *localProtectedEntity) GetMetadataReader(ctx context.Context) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(this.metadata)), nil
+}
+
+func readAll(t *testing.T, getReader func(ctx context.Context) (io.ReadCloser, error)) []byte {
+	reader, err := getReader(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return data
+}
+
+/*
+buildExamplePlugin builds astrolabe_example_plugin into pluginDir
+*/
+func buildExamplePlugin(t *testing.T, pluginDir string) {
+	goCmd := filepath.Join(runtime.GOROOT(), "bin", "go")
+	build := exec.Command(goCmd, "build", "-o", filepath.Join(pluginDir, "example"),
+		"github.com/vmware-tanzu/astrolabe/cmd/astrolabe_example_plugin")
+	output, err := build.CombinedOutput()
+	if err != nil {
+		t.Fatal("Could not build the example plugin: " + err.Error() + "\n" + string(output))
+	}
+}
+
+func TestExamplePlugin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the example plugin")
+	}
+	ctx := context.Background()
+	pluginDir, err := ioutil.TempDir("", "astrolabe-plugins")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer os.RemoveAll(pluginDir)
+	buildExamplePlugin(t, pluginDir)
+	err = ioutil.WriteFile(filepath.Join(pluginDir, "example.json"), []byte(`{"typeName": "plugin-test"}`), 0600)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	// Not executable, so not a plugin
+	err = ioutil.WriteFile(filepath.Join(pluginDir, "README"), []byte("plugins"), 0600)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	host := NewHost(astrolabe.S3Config{}, logrus.New())
+	defer host.Close()
+	petms, err := host.LoadDir(ctx, pluginDir)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1, len(petms))
+	petm := petms[0]
+	assert.Equal(t, "plugin-test", petm.GetTypeName())
+
+	// Copy streams the metadata and data of the source to the plugin
+	data := bytes.Repeat([]byte("0123456789"), chunkSize/4)
+	source := &localProtectedEntity{
+		id:       astrolabe.NewProtectedEntityID("local", "source"),
+		data:     data,
+		metadata: []byte("metadata"),
+	}
+	pe, err := petm.Copy(ctx, source, map[string]map[string]interface{}{"local": {"key": "value"}},
+		astrolabe.AllocateNewObject)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "plugin-test", pe.GetID().GetPeType())
+	ids, err := petm.GetProtectedEntities(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{pe.GetID().String()}, []string{ids[0].String()})
+	info, err := pe.GetInfo(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "local", info.GetName())
+	assert.Equal(t, pe.GetID(), info.GetID())
+	assert.Assert(t, bytes.Equal(data, readAll(t, pe.GetDataReader)))
+	assert.Equal(t, "metadata", string(readAll(t, pe.GetMetadataReader)))
+
+	snapshotID, err := pe.Snapshot(ctx, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	snapshotIDs, err := pe.ListSnapshots(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{snapshotID.String()}, []string{snapshotIDs[0].String()})
+	snapshotInfo, err := pe.GetInfoForSnapshot(ctx, snapshotID)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, pe.GetID().IDWithSnapshot(snapshotID), (*snapshotInfo).GetID())
+
+	// Overwriting changes the current state, the snapshot keeps the old one
+	err = pe.Overwrite(ctx, &localProtectedEntity{
+		id:       astrolabe.NewProtectedEntityID("local", "other"),
+		data:     []byte("new data"),
+		metadata: []byte("new metadata"),
+	}, nil, false)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "new data", string(readAll(t, pe.GetDataReader)))
+	snapshotPE, err := petm.GetProtectedEntity(ctx, pe.GetID().IDWithSnapshot(snapshotID))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, bytes.Equal(data, readAll(t, snapshotPE.GetDataReader)))
+
+	deleted, err := pe.DeleteSnapshot(ctx, snapshotID, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, deleted)
+
+	// Errors from the plugin are returned with their message
+	_, err = petm.GetProtectedEntity(ctx, astrolabe.NewProtectedEntityID("plugin-test", "missing"))
+	assert.ErrorContains(t, err, "protected entity plugin-test:missing not found")
+	_, err = petm.CopyFromInfo(ctx, info, nil, astrolabe.AllocateNewObject)
+	assert.ErrorContains(t, err, "CopyFromInfo is not supported")
+}
+
+func TestParseHandshake(t *testing.T) {
+	network, address, err := parseHandshake("astrolabe-plugin|1|unix|/tmp/plugin.sock\n")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "unix", network)
+	assert.Equal(t, "/tmp/plugin.sock", address)
+	_, _, err = parseHandshake("astrolabe-plugin|2|unix|/tmp/plugin.sock")
+	assert.ErrorContains(t, err, "plugin protocol version 2 is not supported")
+	_, _, err = parseHandshake("hello")
+	assert.ErrorContains(t, err, "invalid handshake")
+}
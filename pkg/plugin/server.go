@@ -0,0 +1,399 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// The host sets MagicCookieKey to MagicCookieValue in the environment of the plugins it starts
+	MagicCookieKey   = "ASTROLABE_PLUGIN_MAGIC_COOKIE"
+	MagicCookieValue = "6f1c2b0e-astrolabe-plugin"
+	// ProtocolVersion is the version of astrolabe_plugin.proto, it changes when the protocol changes incompatibly
+	ProtocolVersion = 1
+	// The first line a plugin writes to stdout is handshakePrefix|<protocol version>|<network>|<address>
+	handshakePrefix = "astrolabe-plugin"
+)
+
+/*
+TypeManagerFactory creates the type manager served by a plugin.  params are read from <plugin>.json next to the plugin
+binary, they are empty if there is no such file.  s3Config is the configuration of the S3 data path of the server.
+*/
+type TypeManagerFactory func(params map[string]interface{}, s3Config astrolabe.S3Config,
+	logger logrus.FieldLogger) (astrolabe.ProtectedEntityTypeManager, error)
+
+/*
+Serve is called from the main of a plugin binary.  It does the handshake with the host and serves the type manager
+created by factory until the host closes stdin.  Logs go to stderr, which the host copies to its log.  Serve exits the
+process if the binary was not started by a plugin host.
+*/
+func Serve(factory TypeManagerFactory) {
+	logger := logrus.New()
+	logger.Out = os.Stderr
+	if os.Getenv(MagicCookieKey) != MagicCookieValue {
+		fmt.Fprintln(os.Stderr, "This binary is an astrolabe plugin, it is started by astrolabe_server from the plugins "+
+			"directory of its configuration directory")
+		os.Exit(1)
+	}
+	err := serve(factory, os.Stdin, os.Stdout, logger)
+	if err != nil {
+		logger.WithError(err).Error("Plugin failed")
+		os.Exit(1)
+	}
+}
+
+func serve(factory TypeManagerFactory, stdin io.Reader, stdout io.Writer, logger logrus.FieldLogger) error {
+	socketDir, err := ioutil.TempDir("", "astrolabe-plugin")
+	if err != nil {
+		return errors.Wrap(err, "could not create the socket directory")
+	}
+	defer os.RemoveAll(socketDir)
+	socketPath := filepath.Join(socketDir, "plugin.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return errors.Wrapf(err, "could not listen on %s", socketPath)
+	}
+	grpcServer := grpc.NewServer()
+	pluginServer := &pluginServer{
+		factory: factory,
+		logger:  logger,
+	}
+	RegisterProtectedEntityTypeManagerServer(grpcServer, pluginServer)
+	RegisterProtectedEntityServer(grpcServer, pluginServer)
+
+	// The host closes stdin when it is done with the plugin, or when it exits
+	go func() {
+		io.Copy(ioutil.Discard, stdin)
+		grpcServer.Stop()
+	}()
+	_, err = fmt.Fprintf(stdout, "%s|%d|unix|%s\n", handshakePrefix, ProtocolVersion, socketPath)
+	if err != nil {
+		return errors.Wrap(err, "could not write the handshake")
+	}
+	return grpcServer.Serve(listener)
+}
+
+/*
+pluginServer implements the gRPC services on top of the type manager created by Init
+*/
+type pluginServer struct {
+	mutex   sync.RWMutex
+	factory TypeManagerFactory
+	petm    astrolabe.ProtectedEntityTypeManager
+	logger  logrus.FieldLogger
+}
+
+func (this *pluginServer) typeManager() (astrolabe.ProtectedEntityTypeManager, error) {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	if this.petm == nil {
+		return nil, status.Error(codes.FailedPrecondition, "Init has not been called")
+	}
+	return this.petm, nil
+}
+
+func (this *pluginServer) getProtectedEntity(ctx context.Context, idStr string) (astrolabe.ProtectedEntity, error) {
+	petm, err := this.typeManager()
+	if err != nil {
+		return nil, err
+	}
+	id, err := astrolabe.NewProtectedEntityIDFromString(idStr)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	pe, err := petm.GetProtectedEntity(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pe == nil {
+		return nil, status.Errorf(codes.NotFound, "protected entity %s not found", idStr)
+	}
+	return pe, nil
+}
+
+func (this *pluginServer) Init(ctx context.Context, request *InitRequest) (*InitResponse, error) {
+	params := map[string]interface{}{}
+	if len(request.ParamsJson) > 0 {
+		if err := json.Unmarshal(request.ParamsJson, &params); err != nil {
+			return nil, invalidArgument(errors.Wrap(err, "could not unmarshal params"))
+		}
+	}
+	s3Config := astrolabe.S3Config{}
+	if len(request.S3ConfigJson) > 0 {
+		if err := json.Unmarshal(request.S3ConfigJson, &s3Config); err != nil {
+			return nil, invalidArgument(errors.Wrap(err, "could not unmarshal S3 config"))
+		}
+	}
+	petm, err := this.factory(params, s3Config, this.logger)
+	if err != nil {
+		return nil, err
+	}
+	this.mutex.Lock()
+	this.petm = petm
+	this.mutex.Unlock()
+	return &InitResponse{
+		TypeName: petm.GetTypeName(),
+	}, nil
+}
+
+func (this *pluginServer) GetTypeName(ctx context.Context, request *Empty) (*TypeName, error) {
+	petm, err := this.typeManager()
+	if err != nil {
+		return nil, err
+	}
+	return &TypeName{
+		Name: petm.GetTypeName(),
+	}, nil
+}
+
+func (this *pluginServer) GetProtectedEntity(ctx context.Context, request *ProtectedEntityID) (*ProtectedEntityID, error) {
+	pe, err := this.getProtectedEntity(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &ProtectedEntityID{
+		Id: pe.GetID().String(),
+	}, nil
+}
+
+func (this *pluginServer) GetProtectedEntities(ctx context.Context, request *Empty) (*ProtectedEntityIDList, error) {
+	petm, err := this.typeManager()
+	if err != nil {
+		return nil, err
+	}
+	ids, err := petm.GetProtectedEntities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return idsToProto(ids), nil
+}
+
+func (this *pluginServer) Copy(stream CopyChunkReceiver) (*ProtectedEntityID, error) {
+	petm, err := this.typeManager()
+	if err != nil {
+		return nil, err
+	}
+	header, source, err := receiveCopyStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	defer source.finish()
+	params, err := paramsFromJSON(header.ParamsJson)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	newPE, err := petm.Copy(stream.Context(), source, params, astrolabe.CopyCreateOptions(header.Options))
+	if err != nil {
+		return nil, err
+	}
+	return &ProtectedEntityID{
+		Id: newPE.GetID().String(),
+	}, nil
+}
+
+func (this *pluginServer) CopyFromInfo(ctx context.Context, request *CopyFromInfoRequest) (*ProtectedEntityID, error) {
+	petm, err := this.typeManager()
+	if err != nil {
+		return nil, err
+	}
+	info, err := infoFromProto(request.Info)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	params, err := paramsFromJSON(request.ParamsJson)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	newPE, err := petm.CopyFromInfo(ctx, info, params, astrolabe.CopyCreateOptions(request.Options))
+	if err != nil {
+		return nil, err
+	}
+	if newPE == nil {
+		return nil, status.Error(codes.Unimplemented, "CopyFromInfo is not supported")
+	}
+	return &ProtectedEntityID{
+		Id: newPE.GetID().String(),
+	}, nil
+}
+
+func (this *pluginServer) GetInfo(ctx context.Context, request *ProtectedEntityID) (*ProtectedEntityInfo, error) {
+	pe, err := this.getProtectedEntity(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	info, err := pe.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return infoToProto(info), nil
+}
+
+func (this *pluginServer) GetCombinedInfo(ctx context.Context, request *ProtectedEntityID) (*ProtectedEntityInfoList, error) {
+	pe, err := this.getProtectedEntity(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := pe.GetCombinedInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return infosToProto(infos), nil
+}
+
+func (this *pluginServer) Snapshot(ctx context.Context, request *SnapshotRequest) (*SnapshotID, error) {
+	pe, err := this.getProtectedEntity(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	params, err := paramsFromJSON(request.ParamsJson)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	snapshotID, err := pe.Snapshot(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &SnapshotID{
+		Id: snapshotID.String(),
+	}, nil
+}
+
+func (this *pluginServer) ListSnapshots(ctx context.Context, request *ProtectedEntityID) (*SnapshotIDList, error) {
+	pe, err := this.getProtectedEntity(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	snapshotIDs, err := pe.ListSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	protoSnapshotIDs := &SnapshotIDList{}
+	for _, curSnapshotID := range snapshotIDs {
+		protoSnapshotIDs.Ids = append(protoSnapshotIDs.Ids, curSnapshotID.String())
+	}
+	return protoSnapshotIDs, nil
+}
+
+func (this *pluginServer) DeleteSnapshot(ctx context.Context, request *SnapshotRequest) (*DeleteSnapshotResponse, error) {
+	pe, err := this.getProtectedEntity(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	params, err := paramsFromJSON(request.ParamsJson)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	deleted, err := pe.DeleteSnapshot(ctx, astrolabe.NewProtectedEntitySnapshotID(request.SnapshotId), params)
+	if err != nil {
+		return nil, err
+	}
+	return &DeleteSnapshotResponse{
+		Deleted: deleted,
+	}, nil
+}
+
+func (this *pluginServer) GetInfoForSnapshot(ctx context.Context, request *SnapshotRequest) (*ProtectedEntityInfo, error) {
+	pe, err := this.getProtectedEntity(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	info, err := pe.GetInfoForSnapshot(ctx, astrolabe.NewProtectedEntitySnapshotID(request.SnapshotId))
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, status.Errorf(codes.NotFound, "snapshot %s not found", request.SnapshotId)
+	}
+	return infoToProto(*info), nil
+}
+
+func (this *pluginServer) GetComponents(ctx context.Context, request *ProtectedEntityID) (*ProtectedEntityIDList, error) {
+	pe, err := this.getProtectedEntity(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	components, err := pe.GetComponents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	componentIDs := make([]astrolabe.ProtectedEntityID, len(components))
+	for componentNum, curComponent := range components {
+		componentIDs[componentNum] = curComponent.GetID()
+	}
+	return idsToProto(componentIDs), nil
+}
+
+func (this *pluginServer) GetDataReader(request *ProtectedEntityID, stream ReadChunkSender) error {
+	pe, err := this.getProtectedEntity(stream.Context(), request.Id)
+	if err != nil {
+		return err
+	}
+	reader, err := pe.GetDataReader(stream.Context())
+	if err != nil {
+		return err
+	}
+	return sendReader(stream, reader)
+}
+
+func (this *pluginServer) GetMetadataReader(request *ProtectedEntityID, stream ReadChunkSender) error {
+	pe, err := this.getProtectedEntity(stream.Context(), request.Id)
+	if err != nil {
+		return err
+	}
+	reader, err := pe.GetMetadataReader(stream.Context())
+	if err != nil {
+		return err
+	}
+	return sendReader(stream, reader)
+}
+
+func (this *pluginServer) Overwrite(stream CopyChunkReceiver) (*Empty, error) {
+	header, source, err := receiveCopyStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	defer source.finish()
+	pe, err := this.getProtectedEntity(stream.Context(), header.Id)
+	if err != nil {
+		return nil, err
+	}
+	params, err := paramsFromJSON(header.ParamsJson)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	err = pe.Overwrite(stream.Context(), source, params, header.OverwriteComponents)
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
@@ -70,8 +70,8 @@ func (this PVCProtectedEntity) GetInfo(ctx context.Context) (astrolabe.Protected
 	if err != nil {
 		return nil, errors.Wrap(err, "Could not retrieve component")
 	}
-	retPEInfo := astrolabe.NewProtectedEntityInfo(this.id, pvc.Name, this.data, this.metadata, this.combined,
-		[]astrolabe.ProtectedEntityID{components[0].GetID()})
+	retPEInfo := astrolabe.NewProtectedEntityInfoWithComponentSpecs(this.id, pvc.Name, this.data, this.metadata,
+		this.combined, []astrolabe.ComponentSpec{this.ppetm.componentSpec(components[0].GetID())}, nil, nil)
 	return retPEInfo, nil
 }
 
@@ -83,6 +83,9 @@ func (this PVCProtectedEntity) Snapshot(ctx context.Context, params map[string]m
 	if this.id.HasSnapshot() {
 		return astrolabe.ProtectedEntitySnapshotID{}, errors.New("Cannot create snapshot of snapshot")
 	}
+	if err := astrolabe.CheckNoLabelParams(this.id, params); err != nil {
+		return astrolabe.ProtectedEntitySnapshotID{}, err
+	}
 	pvc, err := this.GetPVC()
 	if err != nil {
 		return astrolabe.ProtectedEntitySnapshotID{}, errors.Wrap(err, "Could not retrieve pvc")
@@ -312,7 +315,7 @@ func (this PVCProtectedEntity) getProtectedEntityForPV(ctx context.Context, pv *
 				} else {
 					pvPEID = astrolabe.NewProtectedEntityID(pvPEType, pvIDstr)
 				}
-				pvPE, err := this.ppetm.pem.GetProtectedEntity(ctx, pvPEID)
+				pvPE, err := astrolabe.ResolveComponent(ctx, this.ppetm.pem, this.ppetm.componentSpec(pvPEID))
 				if err != nil {
 					return nil, errors.Wrapf(err, "Could not get Protected Entity for PV %s", pvPEID.String())
 				}
@@ -12,6 +12,8 @@ import (
 	v1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -20,9 +22,11 @@ import (
 type PVCProtectedEntityTypeManager struct {
 	clientSet *kubernetes.Clientset
 	isGuest   bool
-	pem       astrolabe.ProtectedEntityManager
-	s3Config  astrolabe.S3Config
-	logger    logrus.FieldLogger
+	// The astrolabe server that serves the PV components, empty if they are served by the same server
+	componentServer string
+	pem             astrolabe.ProtectedEntityManager
+	s3Config        astrolabe.S3Config
+	logger          logrus.FieldLogger
 }
 
 func NewProtectedEntityIDFromPVCName(namespace string, pvcName string) astrolabe.ProtectedEntityID {
@@ -34,6 +38,8 @@ Creates a PVC ProtectectEntityTypeManager
 K8S configuration is provided through parameters
 restConfig - *rest.Config - if set, this will be used
 if restConfig is not set, masterURL and kubeConfigPath will be used.  Either can be set
+componentServer - string - the astrolabe server that serves the PVs, e.g. the server for the vCenter.  If not set,
+the PVs are served by the same server
 */
 func NewPVCProtectedEntityTypeManagerFromConfig(params map[string]interface{}, s3Config astrolabe.S3Config,
 	logger logrus.FieldLogger) (*PVCProtectedEntityTypeManager, error) {
@@ -53,11 +59,13 @@ func NewPVCProtectedEntityTypeManagerFromConfig(params map[string]interface{}, s
 		return nil, err
 	}
 	_, isGuest := params["svcNamespace"]
+	componentServer, _ := params["componentServer"].(string)
 	return &PVCProtectedEntityTypeManager{
-		clientSet: clientSet,
-		isGuest:   isGuest,
-		s3Config:  s3Config,
-		logger:    logger,
+		clientSet:       clientSet,
+		isGuest:         isGuest,
+		componentServer: componentServer,
+		s3Config:        s3Config,
+		logger:          logger,
 	}, nil
 }
 
@@ -65,10 +73,31 @@ func (this *PVCProtectedEntityTypeManager) SetProtectedEntityManager(pem astrola
 	this.pem = pem
 }
 
+/*
+componentSpec returns the spec for the PV component with id, on the component server if one is configured
+*/
+func (this *PVCProtectedEntityTypeManager) componentSpec(id astrolabe.ProtectedEntityID) astrolabe.ComponentSpec {
+	return astrolabe.ComponentSpec{
+		ID:     id,
+		Server: this.componentServer,
+	}
+}
+
 func (this *PVCProtectedEntityTypeManager) GetTypeName() string {
 	return astrolabe.PvcPEType
 }
 
+/*
+CheckHealth checks that the Kubernetes API server is reachable and healthy
+*/
+func (this *PVCProtectedEntityTypeManager) CheckHealth(ctx context.Context) error {
+	_, err := this.clientSet.Discovery().RESTClient().Get().AbsPath("/healthz").DoRaw(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Kubernetes API server is not healthy")
+	}
+	return nil
+}
+
 func (this *PVCProtectedEntityTypeManager) GetProtectedEntity(ctx context.Context, peid astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
 	namespace, name, err := astrolabe.GetNamespaceAndNameFromPEID(peid)
 	if err != nil {
@@ -107,6 +136,34 @@ func (this *PVCProtectedEntityTypeManager) GetProtectedEntities(ctx context.Cont
 	return retPEIDs, nil
 }
 
+/*
+GetProtectedEntitiesPage lists the PVCs of all namespaces with one call instead of one call per namespace.  The name
+filter is passed to Kubernetes as a field selector.  PVC protected entities have no labels, so a label selector
+matches either all of them or none of them.
+*/
+func (this *PVCProtectedEntityTypeManager) GetProtectedEntitiesPage(ctx context.Context,
+	options astrolabe.ListOptions) (astrolabe.ProtectedEntityIDPage, error) {
+	if options.LabelSelector != nil && !options.LabelSelector.Matches(k8slabels.Set{}) {
+		return astrolabe.PageListEntries(nil, options)
+	}
+	listOptions := metav1.ListOptions{}
+	if options.Name != "" {
+		listOptions.FieldSelector = fields.OneTermEqualSelector("metadata.name", options.Name).String()
+	}
+	pvcList, err := this.clientSet.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, listOptions)
+	if err != nil {
+		return astrolabe.ProtectedEntityIDPage{}, errors.Wrap(err, "Could not list PVCs")
+	}
+	entries := make([]astrolabe.ListEntry, len(pvcList.Items))
+	for pvcNum, curPVC := range pvcList.Items {
+		entries[pvcNum] = astrolabe.ListEntry{
+			ID:   NewProtectedEntityIDFromPVCName(curPVC.Namespace, curPVC.Name),
+			Name: curPVC.Name,
+		}
+	}
+	return astrolabe.PageListEntries(entries, options)
+}
+
 func (this *PVCProtectedEntityTypeManager) Copy(ctx context.Context, pe astrolabe.ProtectedEntity,
 	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
 	panic("implement me")
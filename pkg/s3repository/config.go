@@ -0,0 +1,163 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+/*
+ * The repository is configured with a JSON object, for example
+ *    {
+ *        "undeleteWindow": "24h",
+ *        "purgeInterval": "1h",
+ *        "tieringPolicies": [{"age": "720h", "storageClass": "GLACIER"}],
+ *        "tierInterval": "24h"
+ *    }
+ * Durations use the Go duration syntax.  Without an undeleteWindow deleted snapshots are removed immediately,
+ * otherwise they are kept for the window and purged by a purger that runs every purgeInterval.  If there are tiering
+ * policies a tierer applies them every tierInterval, see SetTieringPolicies.
+ */
+const (
+	UndeleteWindowParam  = "undeleteWindow"
+	PurgeIntervalParam   = "purgeInterval"
+	TieringPoliciesParam = "tieringPolicies"
+	TierIntervalParam    = "tierInterval"
+)
+
+// How often the purger runs if the configuration does not set purgeInterval
+const DefaultPurgeInterval = time.Hour
+
+// How often the tierer runs if the configuration does not set tierInterval
+const DefaultTierInterval = 24 * time.Hour
+
+type repositoryConfig struct {
+	UndeleteWindow  string                `json:"undeleteWindow,omitempty"`
+	PurgeInterval   string                `json:"purgeInterval,omitempty"`
+	TieringPolicies []tieringPolicyConfig `json:"tieringPolicies,omitempty"`
+	TierInterval    string                `json:"tierInterval,omitempty"`
+}
+
+type tieringPolicyConfig struct {
+	Age          string `json:"age"`
+	StorageClass string `json:"storageClass"`
+}
+
+/*
+ * NewS3RepositoryProtectedEntityTypeManagerFromConfig creates the type manager and starts the background work that
+ * params configures.  Close stops it.
+ */
+func NewS3RepositoryProtectedEntityTypeManagerFromConfig(typeName string, session session.Session, bucket string,
+	prefix string, params map[string]interface{}, logger logrus.FieldLogger) (*ProtectedEntityTypeManager, error) {
+	config, err := parseRepositoryConfig(params)
+	if err != nil {
+		return nil, err
+	}
+	petm, err := NewS3RepositoryProtectedEntityTypeManager(typeName, session, bucket, prefix, logger)
+	if err != nil {
+		return nil, err
+	}
+	undeleteWindow, err := parseConfigDuration(UndeleteWindowParam, config.UndeleteWindow, 0)
+	if err != nil {
+		return nil, err
+	}
+	purgeInterval, err := parseConfigDuration(PurgeIntervalParam, config.PurgeInterval, DefaultPurgeInterval)
+	if err != nil {
+		return nil, err
+	}
+	if purgeInterval <= 0 {
+		return nil, errors.Errorf("%s %v must be positive", PurgeIntervalParam, purgeInterval)
+	}
+	err = petm.SetUndeleteWindow(undeleteWindow)
+	if err != nil {
+		return nil, err
+	}
+	tieringPolicies := make([]TieringPolicy, len(config.TieringPolicies))
+	for curPolicy, policyConfig := range config.TieringPolicies {
+		if policyConfig.Age == "" {
+			return nil, errors.Errorf("tiering policy for %s has no age", policyConfig.StorageClass)
+		}
+		age, err := parseConfigDuration("tiering policy age", policyConfig.Age, 0)
+		if err != nil {
+			return nil, err
+		}
+		tieringPolicies[curPolicy] = TieringPolicy{
+			Age:          age,
+			StorageClass: policyConfig.StorageClass,
+		}
+	}
+	tierInterval, err := parseConfigDuration(TierIntervalParam, config.TierInterval, DefaultTierInterval)
+	if err != nil {
+		return nil, err
+	}
+	if tierInterval <= 0 {
+		return nil, errors.Errorf("%s %v must be positive", TierIntervalParam, tierInterval)
+	}
+	err = petm.SetTieringPolicies(tieringPolicies)
+	if err != nil {
+		return nil, err
+	}
+	var background context.Context
+	background, petm.stopBackground = context.WithCancel(context.Background())
+	if undeleteWindow > 0 {
+		petm.StartPurger(background, purgeInterval)
+	}
+	if len(tieringPolicies) > 0 {
+		petm.StartTierer(background, tierInterval)
+	}
+	return petm, nil
+}
+
+func parseRepositoryConfig(params map[string]interface{}) (repositoryConfig, error) {
+	var config repositoryConfig
+	configJSON, err := json.Marshal(params)
+	if err != nil {
+		return config, errors.Wrap(err, "Failed to marshal the repository configuration")
+	}
+	err = json.Unmarshal(configJSON, &config)
+	if err != nil {
+		return config, errors.Wrap(err, "Invalid repository configuration")
+	}
+	return config, nil
+}
+
+func parseConfigDuration(name string, value string, defaultValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Invalid %s %q", name, value)
+	}
+	return duration, nil
+}
+
+/*
+ * Close stops the background work started by NewS3RepositoryProtectedEntityTypeManagerFromConfig
+ */
+func (this *ProtectedEntityTypeManager) Close() error {
+	if this.stopBackground != nil {
+		this.stopBackground()
+	}
+	return nil
+}
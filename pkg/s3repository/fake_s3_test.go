@@ -0,0 +1,876 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+ * fakeS3Server is a minimal, in-memory S3 stand-in that implements the subset of the S3 API used by the repository
+ * (path style object get/put/head/delete, ListObjects V1 and V2, multipart uploads and object lock).  All buckets
+ * behave as if Object Lock is enabled.  Unlike S3, deleting a locked object is refused rather than creating a delete
+ * marker so that tests notice any attempt to delete a retained object.
+ */
+type fakeS3Lock struct {
+	mode        string
+	retainUntil time.Time
+	legalHold   bool
+}
+
+func (this fakeS3Lock) isLocked(now time.Time) bool {
+	return this.legalHold || (this.mode != "" && now.Before(this.retainUntil))
+}
+
+/*
+ * Objects in archive storage classes cannot be read until they are restored.  Restores complete the first time their
+ * status is checked with a HEAD after the HEAD that reports them as ongoing.
+ */
+type fakeS3Restore struct {
+	requested, ongoing bool
+	expiry             time.Time
+}
+
+type fakeS3Object struct {
+	data         []byte
+	etag         string
+	lastModified time.Time
+	lock         fakeS3Lock
+	storageClass string
+	restore      fakeS3Restore
+}
+
+func (this *fakeS3Object) getStorageClass() string {
+	if this.storageClass == "" {
+		return "STANDARD"
+	}
+	return this.storageClass
+}
+
+func (this *fakeS3Object) isReadable() bool {
+	archived := this.storageClass == "GLACIER" || this.storageClass == "DEEP_ARCHIVE"
+	return !archived || (this.restore.requested && !this.restore.ongoing)
+}
+
+type fakeS3Upload struct {
+	bucket, key  string
+	parts        map[int][]byte
+	lock         fakeS3Lock
+	storageClass string
+}
+
+type fakeS3Server struct {
+	mutex        sync.Mutex
+	buckets      map[string]map[string]*fakeS3Object
+	uploads      map[string]*fakeS3Upload
+	nextUploadID int
+	server       *httptest.Server
+}
+
+func newFakeS3Server() *fakeS3Server {
+	fake := &fakeS3Server{
+		buckets: map[string]map[string]*fakeS3Object{},
+		uploads: map[string]*fakeS3Upload{},
+	}
+	fake.server = httptest.NewServer(fake)
+	return fake
+}
+
+func (this *fakeS3Server) Close() {
+	this.server.Close()
+}
+
+func (this *fakeS3Server) session(t *testing.T) session.Session {
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(this.server.URL),
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+		DisableSSL:       aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("fake-access-key", "fake-secret", ""),
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return *sess
+}
+
+func (this *fakeS3Server) newPETM(t *testing.T, typeName string, bucket string) *ProtectedEntityTypeManager {
+	petm, err := NewS3RepositoryProtectedEntityTypeManager(typeName, this.session(t), bucket, "repo", logrus.New())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return petm
+}
+
+func (this *fakeS3Server) putObject(bucket string, key string, data []byte) *fakeS3Object {
+	objects, ok := this.buckets[bucket]
+	if !ok {
+		objects = map[string]*fakeS3Object{}
+		this.buckets[bucket] = objects
+	}
+	sum := md5.Sum(data)
+	object := &fakeS3Object{
+		data:         data,
+		etag:         "\"" + hex.EncodeToString(sum[:]) + "\"",
+		lastModified: time.Now().UTC(),
+	}
+	objects[key] = object
+	return object
+}
+
+func (this *fakeS3Server) getObject(bucket string, key string) *fakeS3Object {
+	objects, ok := this.buckets[bucket]
+	if !ok {
+		return nil
+	}
+	return objects[key]
+}
+
+type fakeS3Error struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string
+	Message string
+}
+
+func (this *fakeS3Server) objectCount(bucket string) int {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return len(this.buckets[bucket])
+}
+
+/*
+ * storageClasses returns the storage class of each object in the bucket whose key starts with prefix
+ */
+func (this *fakeS3Server) storageClasses(bucket string, prefix string) map[string]string {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	storageClasses := map[string]string{}
+	for key, object := range this.buckets[bucket] {
+		if strings.HasPrefix(key, prefix) {
+			storageClasses[key] = object.getStorageClass()
+		}
+	}
+	return storageClasses
+}
+
+func writeFakeS3Error(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(fakeS3Error{Code: code, Message: message})
+}
+
+func writeFakeS3XML(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(value)
+}
+
+func (this *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	bucket, key := path, ""
+	if sep := strings.IndexByte(path, '/'); sep >= 0 {
+		bucket, key = path[:sep], path[sep+1:]
+	}
+	query := r.URL.Query()
+	_, hasUploads := query["uploads"]
+	_, hasRetention := query["retention"]
+	_, hasLegalHold := query["legal-hold"]
+	_, hasRestore := query["restore"]
+	uploadID := query.Get("uploadId")
+	copySource := r.Header.Get("x-amz-copy-source")
+
+	switch {
+	case key == "" && r.Method == http.MethodGet && hasUploads:
+		this.listMultipartUploads(w, bucket)
+	case key == "" && r.Method == http.MethodGet:
+		this.listObjects(w, bucket, query)
+	case key == "" && r.Method == http.MethodPut:
+		if _, ok := this.buckets[bucket]; !ok {
+			this.buckets[bucket] = map[string]*fakeS3Object{}
+		}
+		w.WriteHeader(http.StatusOK)
+	case hasRetention || hasLegalHold:
+		this.objectLock(w, r, bucket, key, hasRetention)
+	case r.Method == http.MethodPost && hasUploads:
+		lock, err := parseFakeS3LockHeaders(r.Header)
+		if err != nil {
+			writeFakeS3Error(w, http.StatusBadRequest, "InvalidArgument", err.Error())
+			return
+		}
+		this.createMultipartUpload(w, bucket, key, lock, r.Header.Get("x-amz-storage-class"))
+	case r.Method == http.MethodPost && hasRestore:
+		this.restoreObject(w, r, bucket, key)
+	case r.Method == http.MethodPost && uploadID != "":
+		this.completeMultipartUpload(w, r, bucket, key, uploadID)
+	case r.Method == http.MethodPut && uploadID != "" && copySource != "":
+		this.uploadPartCopy(w, r, uploadID, query.Get("partNumber"), copySource)
+	case r.Method == http.MethodPut && uploadID != "":
+		this.uploadPart(w, r, uploadID, query.Get("partNumber"))
+	case r.Method == http.MethodPut && copySource != "":
+		this.copyObject(w, r, bucket, key, copySource)
+	case r.Method == http.MethodGet && uploadID != "":
+		this.listParts(w, bucket, key, uploadID)
+	case r.Method == http.MethodDelete && uploadID != "":
+		delete(this.uploads, uploadID)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		lock, err := parseFakeS3LockHeaders(r.Header)
+		if err != nil {
+			writeFakeS3Error(w, http.StatusBadRequest, "InvalidArgument", err.Error())
+			return
+		}
+		if (lock.mode != "" || lock.legalHold) && r.Header.Get("Content-MD5") == "" {
+			writeFakeS3Error(w, http.StatusBadRequest, "InvalidRequest",
+				"Content-MD5 HTTP header is required for Put Object requests with Object Lock parameters")
+			return
+		}
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeFakeS3Error(w, http.StatusInternalServerError, "InternalError", err.Error())
+			return
+		}
+		object := this.putObject(bucket, key, data)
+		object.lock = lock
+		object.storageClass = r.Header.Get("x-amz-storage-class")
+		w.Header().Set("ETag", object.etag)
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		this.getOrHeadObject(w, r, bucket, key)
+	case r.Method == http.MethodDelete:
+		if object := this.getObject(bucket, key); object != nil {
+			if object.lock.isLocked(time.Now()) {
+				writeFakeS3Error(w, http.StatusForbidden, "AccessDenied", "Access Denied because object protected by object lock.")
+				return
+			}
+			delete(this.buckets[bucket], key)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeS3Error(w, http.StatusNotImplemented, "NotImplemented", r.Method+" "+r.URL.String())
+	}
+}
+
+func (this *fakeS3Server) getOrHeadObject(w http.ResponseWriter, r *http.Request, bucket string, key string) {
+	object := this.getObject(bucket, key)
+	if object == nil {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			writeFakeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		}
+		return
+	}
+	if r.Method == http.MethodGet && !object.isReadable() {
+		writeFakeS3Error(w, http.StatusForbidden, "InvalidObjectState", "The operation is not valid for the object's storage class")
+		return
+	}
+	data := object.data
+	status := http.StatusOK
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+		var start, end int64
+		end = int64(len(data)) - 1
+		rangeSpec := strings.TrimPrefix(rangeHeader, "bytes=")
+		parts := strings.SplitN(rangeSpec, "-", 2)
+		start, _ = strconv.ParseInt(parts[0], 10, 64)
+		if len(parts) == 2 && parts[1] != "" {
+			end, _ = strconv.ParseInt(parts[1], 10, 64)
+		}
+		if end >= int64(len(data)) {
+			end = int64(len(data)) - 1
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		data = data[start : end+1]
+		status = http.StatusPartialContent
+	}
+	if object.lock.mode != "" {
+		w.Header().Set("x-amz-object-lock-mode", object.lock.mode)
+		w.Header().Set("x-amz-object-lock-retain-until-date", object.lock.retainUntil.UTC().Format(time.RFC3339))
+	}
+	if object.lock.legalHold {
+		w.Header().Set("x-amz-object-lock-legal-hold", "ON")
+	}
+	if object.storageClass != "" && object.storageClass != "STANDARD" {
+		w.Header().Set("x-amz-storage-class", object.storageClass)
+	}
+	if object.restore.ongoing {
+		w.Header().Set("x-amz-restore", `ongoing-request="true"`)
+		if r.Method == http.MethodHead {
+			object.restore.ongoing = false
+		}
+	} else if object.restore.requested {
+		w.Header().Set("x-amz-restore", fmt.Sprintf(`ongoing-request="false", expiry-date="%s"`,
+			object.restore.expiry.UTC().Format(http.TimeFormat)))
+	}
+	w.Header().Set("ETag", object.etag)
+	w.Header().Set("Last-Modified", object.lastModified.Format(http.TimeFormat))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+type fakeS3Contents struct {
+	Key          string
+	Size         int64
+	ETag         string
+	LastModified string
+	StorageClass string
+}
+
+type fakeS3ListBucketResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	Name                  string
+	Prefix                string
+	Marker                string `xml:",omitempty"`
+	NextMarker            string `xml:",omitempty"`
+	ContinuationToken     string `xml:",omitempty"`
+	NextContinuationToken string `xml:",omitempty"`
+	KeyCount              int
+	MaxKeys               int
+	IsTruncated           bool
+	Contents              []fakeS3Contents
+}
+
+func (this *fakeS3Server) listObjects(w http.ResponseWriter, bucket string, query map[string][]string) {
+	get := func(name string) string {
+		if values, ok := query[name]; ok && len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	prefix := get("prefix")
+	maxKeys := 1000
+	if maxKeysStr := get("max-keys"); maxKeysStr != "" {
+		maxKeys, _ = strconv.Atoi(maxKeysStr)
+	}
+	v2 := get("list-type") == "2"
+	startAfter := get("marker")
+	if v2 {
+		startAfter = get("continuation-token")
+	}
+
+	keys := []string{}
+	for key := range this.buckets[bucket] {
+		if strings.HasPrefix(key, prefix) && key > startAfter {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	result := fakeS3ListBucketResult{
+		Name:     bucket,
+		Prefix:   prefix,
+		MaxKeys:  maxKeys,
+		Contents: []fakeS3Contents{},
+	}
+	if len(keys) > maxKeys {
+		keys = keys[:maxKeys]
+		result.IsTruncated = true
+		if v2 {
+			result.ContinuationToken = startAfter
+			result.NextContinuationToken = keys[len(keys)-1]
+		} else {
+			result.Marker = startAfter
+			result.NextMarker = keys[len(keys)-1]
+		}
+	}
+	for _, key := range keys {
+		object := this.buckets[bucket][key]
+		result.Contents = append(result.Contents, fakeS3Contents{
+			Key:          key,
+			Size:         int64(len(object.data)),
+			ETag:         object.etag,
+			LastModified: object.lastModified.Format(time.RFC3339),
+			StorageClass: object.getStorageClass(),
+		})
+	}
+	result.KeyCount = len(result.Contents)
+	writeFakeS3XML(w, result)
+}
+
+type fakeS3InitiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Bucket   string
+	Key      string
+	UploadId string
+}
+
+func (this *fakeS3Server) createMultipartUpload(w http.ResponseWriter, bucket string, key string, lock fakeS3Lock,
+	storageClass string) {
+	this.nextUploadID++
+	uploadID := fmt.Sprintf("upload-%d", this.nextUploadID)
+	this.uploads[uploadID] = &fakeS3Upload{
+		bucket:       bucket,
+		key:          key,
+		parts:        map[int][]byte{},
+		lock:         lock,
+		storageClass: storageClass,
+	}
+	writeFakeS3XML(w, fakeS3InitiateMultipartUploadResult{
+		Bucket:   bucket,
+		Key:      key,
+		UploadId: uploadID,
+	})
+}
+
+func (this *fakeS3Server) uploadPart(w http.ResponseWriter, r *http.Request, uploadID string, partNumberStr string) {
+	upload, ok := this.uploads[uploadID]
+	if !ok {
+		writeFakeS3Error(w, http.StatusNotFound, "NoSuchUpload", uploadID)
+		return
+	}
+	partNumber, err := strconv.Atoi(partNumberStr)
+	if err != nil {
+		writeFakeS3Error(w, http.StatusBadRequest, "InvalidArgument", partNumberStr)
+		return
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeFakeS3Error(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	upload.parts[partNumber] = data
+	sum := md5.Sum(data)
+	w.Header().Set("ETag", "\""+hex.EncodeToString(sum[:])+"\"")
+	w.WriteHeader(http.StatusOK)
+}
+
+type fakeS3CompleteMultipartUploadResult struct {
+	XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+	Bucket  string
+	Key     string
+	ETag    string
+}
+
+func (this *fakeS3Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request, bucket string, key string,
+	uploadID string) {
+	upload, ok := this.uploads[uploadID]
+	if !ok {
+		writeFakeS3Error(w, http.StatusNotFound, "NoSuchUpload", uploadID)
+		return
+	}
+	partNumbers := []int{}
+	for partNumber := range upload.parts {
+		partNumbers = append(partNumbers, partNumber)
+	}
+	sort.Ints(partNumbers)
+	data := []byte{}
+	for _, partNumber := range partNumbers {
+		data = append(data, upload.parts[partNumber]...)
+	}
+	delete(this.uploads, uploadID)
+	object := this.putObject(bucket, key, data)
+	object.lock = upload.lock
+	object.storageClass = upload.storageClass
+	writeFakeS3XML(w, fakeS3CompleteMultipartUploadResult{
+		Bucket: bucket,
+		Key:    key,
+		ETag:   object.etag,
+	})
+}
+
+type fakeS3ListMultipartUploadsResult struct {
+	XMLName     xml.Name `xml:"ListMultipartUploadsResult"`
+	Bucket      string
+	IsTruncated bool
+	Upload      []fakeS3UploadXML
+}
+
+type fakeS3UploadXML struct {
+	Key      string
+	UploadId string
+}
+
+func (this *fakeS3Server) listMultipartUploads(w http.ResponseWriter, bucket string) {
+	result := fakeS3ListMultipartUploadsResult{
+		Bucket: bucket,
+	}
+	for uploadID, upload := range this.uploads {
+		if upload.bucket == bucket {
+			result.Upload = append(result.Upload, fakeS3UploadXML{Key: upload.key, UploadId: uploadID})
+		}
+	}
+	writeFakeS3XML(w, result)
+}
+
+type fakeS3Part struct {
+	PartNumber int
+	ETag       string
+	Size       int64
+}
+
+type fakeS3ListPartsResult struct {
+	XMLName     xml.Name `xml:"ListPartsResult"`
+	Bucket      string
+	Key         string
+	UploadId    string
+	IsTruncated bool
+	Part        []fakeS3Part
+}
+
+func (this *fakeS3Server) listParts(w http.ResponseWriter, bucket string, key string, uploadID string) {
+	upload, ok := this.uploads[uploadID]
+	if !ok {
+		writeFakeS3Error(w, http.StatusNotFound, "NoSuchUpload", uploadID)
+		return
+	}
+	result := fakeS3ListPartsResult{
+		Bucket:   bucket,
+		Key:      key,
+		UploadId: uploadID,
+	}
+	for partNumber, data := range upload.parts {
+		sum := md5.Sum(data)
+		result.Part = append(result.Part, fakeS3Part{
+			PartNumber: partNumber,
+			ETag:       "\"" + hex.EncodeToString(sum[:]) + "\"",
+			Size:       int64(len(data)),
+		})
+	}
+	sort.Slice(result.Part, func(i, j int) bool {
+		return result.Part[i].PartNumber < result.Part[j].PartNumber
+	})
+	writeFakeS3XML(w, result)
+}
+
+type fakeS3CopyResult struct {
+	ETag         string
+	LastModified string
+}
+
+type fakeS3CopyObjectResult struct {
+	XMLName xml.Name `xml:"CopyObjectResult"`
+	fakeS3CopyResult
+}
+
+type fakeS3CopyPartResult struct {
+	XMLName xml.Name `xml:"CopyPartResult"`
+	fakeS3CopyResult
+}
+
+/*
+ * copySourceObject returns the object named by an x-amz-copy-source header, or writes an error and returns nil.
+ */
+func (this *fakeS3Server) copySourceObject(w http.ResponseWriter, copySource string) *fakeS3Object {
+	source, err := url.PathUnescape(copySource)
+	if err != nil {
+		writeFakeS3Error(w, http.StatusBadRequest, "InvalidArgument", err.Error())
+		return nil
+	}
+	source = strings.TrimPrefix(source, "/")
+	sep := strings.IndexByte(source, '/')
+	if sep < 0 {
+		writeFakeS3Error(w, http.StatusBadRequest, "InvalidArgument", "invalid copy source "+copySource)
+		return nil
+	}
+	object := this.getObject(source[:sep], source[sep+1:])
+	if object == nil {
+		writeFakeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return nil
+	}
+	if !object.isReadable() {
+		writeFakeS3Error(w, http.StatusForbidden, "InvalidObjectState", "The operation is not valid for the object's storage class")
+		return nil
+	}
+	return object
+}
+
+func (this *fakeS3Server) copyObject(w http.ResponseWriter, r *http.Request, bucket string, key string, copySource string) {
+	sourceObject := this.copySourceObject(w, copySource)
+	if sourceObject == nil {
+		return
+	}
+	lock, err := parseFakeS3LockHeaders(r.Header)
+	if err != nil {
+		writeFakeS3Error(w, http.StatusBadRequest, "InvalidArgument", err.Error())
+		return
+	}
+	object := this.putObject(bucket, key, sourceObject.data)
+	object.lock = lock
+	object.storageClass = r.Header.Get("x-amz-storage-class")
+	writeFakeS3XML(w, fakeS3CopyObjectResult{
+		fakeS3CopyResult: fakeS3CopyResult{
+			ETag:         object.etag,
+			LastModified: object.lastModified.Format(time.RFC3339),
+		},
+	})
+}
+
+func (this *fakeS3Server) uploadPartCopy(w http.ResponseWriter, r *http.Request, uploadID string, partNumberStr string,
+	copySource string) {
+	upload, ok := this.uploads[uploadID]
+	if !ok {
+		writeFakeS3Error(w, http.StatusNotFound, "NoSuchUpload", uploadID)
+		return
+	}
+	partNumber, err := strconv.Atoi(partNumberStr)
+	if err != nil {
+		writeFakeS3Error(w, http.StatusBadRequest, "InvalidArgument", partNumberStr)
+		return
+	}
+	sourceObject := this.copySourceObject(w, copySource)
+	if sourceObject == nil {
+		return
+	}
+	data := sourceObject.data
+	if rangeHeader := r.Header.Get("x-amz-copy-source-range"); rangeHeader != "" {
+		var start, end int64
+		_, err := fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end)
+		if err != nil || start > end || end >= int64(len(data)) {
+			writeFakeS3Error(w, http.StatusBadRequest, "InvalidArgument", "invalid copy source range "+rangeHeader)
+			return
+		}
+		data = data[start : end+1]
+	}
+	upload.parts[partNumber] = data
+	sum := md5.Sum(data)
+	writeFakeS3XML(w, fakeS3CopyPartResult{
+		fakeS3CopyResult: fakeS3CopyResult{
+			ETag:         "\"" + hex.EncodeToString(sum[:]) + "\"",
+			LastModified: time.Now().UTC().Format(time.RFC3339),
+		},
+	})
+}
+
+type fakeS3RestoreRequest struct {
+	XMLName xml.Name `xml:"RestoreRequest"`
+	Days    int
+}
+
+func (this *fakeS3Server) restoreObject(w http.ResponseWriter, r *http.Request, bucket string, key string) {
+	object := this.getObject(bucket, key)
+	if object == nil {
+		writeFakeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+	if object.storageClass != "GLACIER" && object.storageClass != "DEEP_ARCHIVE" {
+		writeFakeS3Error(w, http.StatusForbidden, "InvalidObjectState", "Restore is not allowed for the object's current storage class")
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeFakeS3Error(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	restoreRequest := fakeS3RestoreRequest{}
+	if err := xml.Unmarshal(body, &restoreRequest); err != nil {
+		writeFakeS3Error(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+	if object.restore.ongoing {
+		writeFakeS3Error(w, http.StatusConflict, "RestoreAlreadyInProgress", "Object restore is already in progress")
+		return
+	}
+	status := http.StatusAccepted
+	if object.restore.requested {
+		// Already restored, the expiry is updated
+		status = http.StatusOK
+	} else {
+		object.restore.ongoing = true
+	}
+	object.restore.requested = true
+	object.restore.expiry = time.Now().Add(time.Duration(restoreRequest.Days) * 24 * time.Hour)
+	w.WriteHeader(status)
+}
+
+func parseFakeS3LockHeaders(header http.Header) (fakeS3Lock, error) {
+	lock := fakeS3Lock{
+		mode:      header.Get("x-amz-object-lock-mode"),
+		legalHold: header.Get("x-amz-object-lock-legal-hold") == "ON",
+	}
+	if retainUntilStr := header.Get("x-amz-object-lock-retain-until-date"); retainUntilStr != "" {
+		retainUntil, err := time.Parse(time.RFC3339, retainUntilStr)
+		if err != nil {
+			return fakeS3Lock{}, err
+		}
+		lock.retainUntil = retainUntil
+	}
+	if (lock.mode == "") != lock.retainUntil.IsZero() {
+		return fakeS3Lock{}, errors.New("object lock mode and retain until date must be set together")
+	}
+	return lock, nil
+}
+
+type fakeS3Retention struct {
+	XMLName         xml.Name `xml:"Retention"`
+	Mode            string   `xml:",omitempty"`
+	RetainUntilDate string   `xml:",omitempty"`
+}
+
+type fakeS3LegalHold struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	Status  string
+}
+
+func (this *fakeS3Server) objectLock(w http.ResponseWriter, r *http.Request, bucket string, key string, retention bool) {
+	object := this.getObject(bucket, key)
+	if object == nil {
+		writeFakeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+	if r.Method == http.MethodGet {
+		if retention {
+			result := fakeS3Retention{Mode: object.lock.mode}
+			if object.lock.mode != "" {
+				result.RetainUntilDate = object.lock.retainUntil.UTC().Format(time.RFC3339)
+			}
+			writeFakeS3XML(w, result)
+		} else {
+			status := "OFF"
+			if object.lock.legalHold {
+				status = "ON"
+			}
+			writeFakeS3XML(w, fakeS3LegalHold{Status: status})
+		}
+		return
+	}
+	if r.Method != http.MethodPut {
+		writeFakeS3Error(w, http.StatusNotImplemented, "NotImplemented", r.Method+" "+r.URL.String())
+		return
+	}
+	if r.Header.Get("Content-MD5") == "" {
+		writeFakeS3Error(w, http.StatusBadRequest, "InvalidRequest", "Content-MD5 HTTP header is required")
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeFakeS3Error(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	if !retention {
+		legalHold := fakeS3LegalHold{}
+		if err := xml.Unmarshal(body, &legalHold); err != nil {
+			writeFakeS3Error(w, http.StatusBadRequest, "MalformedXML", err.Error())
+			return
+		}
+		object.lock.legalHold = legalHold.Status == "ON"
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	newRetention := fakeS3Retention{}
+	if err := xml.Unmarshal(body, &newRetention); err != nil {
+		writeFakeS3Error(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+	retainUntil, err := time.Parse(time.RFC3339, newRetention.RetainUntilDate)
+	if err != nil {
+		writeFakeS3Error(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+	// Without a governance bypass, retention on a locked object can only be extended
+	if object.lock.isLocked(time.Now()) && object.lock.mode != "" {
+		if retainUntil.Before(object.lock.retainUntil) || (object.lock.mode == "COMPLIANCE" && newRetention.Mode != "COMPLIANCE") {
+			writeFakeS3Error(w, http.StatusForbidden, "AccessDenied", "Access Denied because object protected by object lock.")
+			return
+		}
+	}
+	object.lock.mode = newRetention.Mode
+	object.lock.retainUntil = retainUntil
+	w.WriteHeader(http.StatusOK)
+}
+
+/*
+ * memoryProtectedEntity is an in-memory protected entity used as a copy source in tests
+ */
+type memoryProtectedEntity struct {
+	info     astrolabe.ProtectedEntityInfo
+	data, md []byte
+}
+
+func newMemoryProtectedEntity(id astrolabe.ProtectedEntityID, data []byte, md []byte) memoryProtectedEntity {
+	return memoryProtectedEntity{
+		info: astrolabe.NewProtectedEntityInfo(id, id.GetID(),
+			[]astrolabe.DataTransport{astrolabe.NewDataTransportForS3URL("http://localhost/data")},
+			[]astrolabe.DataTransport{astrolabe.NewDataTransportForS3URL("http://localhost/md")},
+			[]astrolabe.DataTransport{}, []astrolabe.ProtectedEntityID{}),
+		data: data,
+		md:   md,
+	}
+}
+
+func (this memoryProtectedEntity) GetInfo(ctx context.Context) (astrolabe.ProtectedEntityInfo, error) {
+	return this.info, nil
+}
+
+func (this memoryProtectedEntity) GetCombinedInfo(ctx context.Context) ([]astrolabe.ProtectedEntityInfo, error) {
+	return nil, nil
+}
+
+func (this memoryProtectedEntity) Snapshot(ctx context.Context, params map[string]map[string]interface{}) (astrolabe.ProtectedEntitySnapshotID, error) {
+	return astrolabe.ProtectedEntitySnapshotID{}, errors.New("Snapshot not supported")
+}
+
+func (this memoryProtectedEntity) ListSnapshots(ctx context.Context) ([]astrolabe.ProtectedEntitySnapshotID, error) {
+	return []astrolabe.ProtectedEntitySnapshotID{}, nil
+}
+
+func (this memoryProtectedEntity) DeleteSnapshot(ctx context.Context, snapshotToDelete astrolabe.ProtectedEntitySnapshotID,
+	params map[string]map[string]interface{}) (bool, error) {
+	return false, errors.New("DeleteSnapshot not supported")
+}
+
+func (this memoryProtectedEntity) GetInfoForSnapshot(ctx context.Context, snapshotID astrolabe.ProtectedEntitySnapshotID) (*astrolabe.ProtectedEntityInfo, error) {
+	return nil, nil
+}
+
+func (this memoryProtectedEntity) GetComponents(ctx context.Context) ([]astrolabe.ProtectedEntity, error) {
+	return []astrolabe.ProtectedEntity{}, nil
+}
+
+func (this memoryProtectedEntity) GetID() astrolabe.ProtectedEntityID {
+	return this.info.GetID()
+}
+
+func (this memoryProtectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(this.data)), nil
+}
+
+func (this memoryProtectedEntity) GetMetadataReader(ctx context.Context) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(this.md)), nil
+}
+
+func (this memoryProtectedEntity) Overwrite(ctx context.Context, sourcePE astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, overwriteComponents bool) error {
+	return errors.New("Overwrite not supported")
+}
@@ -27,15 +27,19 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/pkg/errors"
 	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"github.com/vmware-tanzu/astrolabe/pkg/metrics"
 	"io"
 	"io/ioutil"
 	"sort"
 	"strings"
+	"time"
 )
 
 type ProtectedEntity struct {
 	rpetm  *ProtectedEntityTypeManager
 	peinfo astrolabe.ProtectedEntityInfo
+	// Object lock retention for objects written by copy
+	retention astrolabe.Retention
 }
 
 /*
@@ -98,28 +102,53 @@ func (this ProtectedEntity) ListSnapshots(ctx context.Context) ([]astrolabe.Prot
 func (this ProtectedEntity) DeleteSnapshot(ctx context.Context,
 	snapshotToDelete astrolabe.ProtectedEntitySnapshotID,
 	params map[string]map[string]interface{}) (bool, error) {
+	// Refuse before touching anything so that a retained snapshot is never partially deleted
+	err := this.rpetm.checkRetention(ctx, this.peinfo.GetID())
+	if err != nil {
+		return false, err
+	}
+
+	if this.rpetm.undeleteWindow > 0 {
+		err = this.rpetm.writeTombstone(ctx, this.peinfo.GetID())
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	err = this.purge(ctx)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+ * purge removes all of the objects for the snapshot.  The peinfo is removed first so that a partially purged snapshot
+ * is no longer visible.
+ */
+func (this ProtectedEntity) purge(ctx context.Context) error {
 	var err error
 	bucket := this.rpetm.bucket
 
 	peinfoName := this.rpetm.peinfoName(this.peinfo.GetID())
 	_, err = this.deleteSnapshotComponents(ctx, bucket, peinfoName)
 	if err != nil {
-		return false, errors.Wrapf(err, "Failed to delete peinfo from bucket %q", bucket)
+		return errors.Wrapf(err, "Failed to delete peinfo from bucket %q", bucket)
 	}
 
 	mdName := this.rpetm.metadataName(this.peinfo.GetID())
 	_, err = this.deleteSnapshotComponents(ctx, bucket, mdName)
 	if err != nil {
-		return false, errors.Wrapf(err, "Failed to delete metadata from bucket %q", bucket)
+		return errors.Wrapf(err, "Failed to delete metadata from bucket %q", bucket)
 	}
 
 	dataName := this.rpetm.dataName(this.peinfo.GetID())
 	_, err = this.deleteSnapshotComponents(ctx, bucket, dataName)
 	if err != nil {
-		return false, errors.Wrapf(err, "Failed to delete data from bucket %q", bucket)
+		return errors.Wrapf(err, "Failed to delete data from bucket %q", bucket)
 	}
 
-	return true, nil
+	return nil
 }
 
 func (this ProtectedEntity) getS3Segments(ctx context.Context, bucket string, componentName string) ([]s3Segment, error) {
@@ -201,6 +230,15 @@ func (this ProtectedEntity) GetID() astrolabe.ProtectedEntityID {
 func (this ProtectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
 	if len(this.peinfo.GetDataTransports()) > 0 {
 		dataName := this.rpetm.dataName(this.GetID())
+		s3Segments, err := this.getS3Segments(ctx, this.rpetm.bucket, dataName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not get reader for bucket %s, key %s", this.rpetm.bucket, dataName)
+		}
+		// Archived data has to be rehydrated first, otherwise the read would fail part way through
+		err = this.rpetm.checkReadable(ctx, this, s3Segments)
+		if err != nil {
+			return nil, err
+		}
 		return this.getReader(ctx, dataName)
 	}
 	return nil, nil
@@ -244,6 +282,7 @@ func (this *ProtectedEntity) uploadStream(ctx context.Context, name string, maxS
 		}
 		if uploadSegment {
 			bytesThisSegment, err = this.uploadSegment(ctx, name, partNum, startOffset, maxSegmentSize, reader)
+			metrics.SegmentUploaded(bytesThisSegment, err)
 			if err != nil {
 				return err
 			}
@@ -402,10 +441,14 @@ func (this *ProtectedEntity) uploadSegment(ctx context.Context, baseName string,
 					// We don't have enough data to do a multipart upload
 					uploader := s3manager.NewUploader(&this.rpetm.session)
 
+					lockMode, lockRetainUntil, lockLegalHold := objectLockHeaders(this.retention)
 					result, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
-						Body:   bufferReader,
-						Bucket: awsBucketName,
-						Key:    awsKey,
+						Body:                      bufferReader,
+						Bucket:                    awsBucketName,
+						Key:                       awsKey,
+						ObjectLockMode:            lockMode,
+						ObjectLockRetainUntilDate: lockRetainUntil,
+						ObjectLockLegalHoldStatus: lockLegalHold,
 					})
 					if err == nil {
 						log.Infof("Successfully uploaded to", result.Location)
@@ -418,9 +461,13 @@ func (this *ProtectedEntity) uploadSegment(ctx context.Context, baseName string,
 				} else {
 					// Wait until here to start the multi-part upload in case we're too small
 					if uploadID == "" {
+						lockMode, lockRetainUntil, lockLegalHold := objectLockHeaders(this.retention)
 						uploadInput := &s3.CreateMultipartUploadInput{
-							Bucket: awsBucketName,
-							Key:    awsKey,
+							Bucket:                    awsBucketName,
+							Key:                       awsKey,
+							ObjectLockMode:            lockMode,
+							ObjectLockRetainUntilDate: lockRetainUntil,
+							ObjectLockLegalHoldStatus: lockLegalHold,
 						}
 
 						resp, err := this.rpetm.s3.CreateMultipartUploadWithContext(ctx, uploadInput)
@@ -560,8 +607,8 @@ func (this *ProtectedEntity) copy(ctx context.Context, maxSegmentSize int64, dat
 	metadataReader io.Reader) error {
 	defer this.cleanupOnAbortedUpload(&ctx)
 	peInfo := this.peinfo
-	peinfoName := this.rpetm.peinfoName(peInfo.GetID())
 
+	// Check the peinfo size before uploading anything
 	peInfoBuf, err := json.Marshal(peInfo)
 	if err != nil {
 		return err
@@ -586,21 +633,43 @@ func (this *ProtectedEntity) copy(ctx context.Context, maxSegmentSize int64, dat
 			return err
 		}
 	}
+	return this.putPEInfo(ctx)
+}
+
+/*
+ * putPEInfo writes the peinfo object.  The peinfo is written last when copying, it is also rewritten when the data is
+ * moved to another tier.
+ */
+func (this *ProtectedEntity) putPEInfo(ctx context.Context) error {
+	peInfo := this.peinfo
+	peinfoName := this.rpetm.peinfoName(peInfo.GetID())
+
+	peInfoBuf, err := json.Marshal(peInfo)
+	if err != nil {
+		return err
+	}
+	if len(peInfoBuf) > maxPEInfoSize {
+		return errors.New("JSON for pe info > 16K")
+	}
 	jsonBytes := bytes.NewReader(peInfoBuf)
 
+	lockMode, lockRetainUntil, lockLegalHold := objectLockHeaders(unexpiredRetention(this.retention, time.Now()))
 	jsonParams := &s3.PutObjectInput{
-		Bucket:        aws.String(this.rpetm.bucket),
-		Key:           aws.String(peinfoName),
-		Body:          jsonBytes,
-		ContentLength: aws.Int64(int64(len(peInfoBuf))),
-		ContentType:   aws.String(peInfoFileType),
+		Bucket:                    aws.String(this.rpetm.bucket),
+		Key:                       aws.String(peinfoName),
+		Body:                      jsonBytes,
+		ContentLength:             aws.Int64(int64(len(peInfoBuf))),
+		ContentType:               aws.String(peInfoFileType),
+		ObjectLockMode:            lockMode,
+		ObjectLockRetainUntilDate: lockRetainUntil,
+		ObjectLockLegalHoldStatus: lockLegalHold,
 	}
 	_, err = this.rpetm.s3.PutObjectWithContext(ctx, jsonParams)
 	if err != nil {
-		return errors.Wrapf(err, "copy S3 PutObject for PE info failed for PE %s bucket %s key %s",
+		return errors.Wrapf(err, "S3 PutObject for PE info failed for PE %s bucket %s key %s",
 			peInfo.GetID(), this.rpetm.bucket, peinfoName)
 	}
-	return err
+	return nil
 }
 
 func (this *ProtectedEntity) cleanupOnAbortedUpload(ctx *context.Context) {
@@ -610,15 +679,12 @@ func (this *ProtectedEntity) cleanupOnAbortedUpload(ctx *context.Context) {
 		log.Infof("The context was canceled during copy of pe %v, proceeding with cleanup", peInfo.GetName())
 		log.Debugf("Attempting to delete any uploaded snapshots for %v", this.peinfo.GetID())
 		// New context or else downstream "withContext" calls will error out.
-		status, err := this.DeleteSnapshot(context.Background(), this.peinfo.GetID().GetSnapshotID(), make(map[string]map[string]interface{}))
+		// The partial upload is purged rather than soft deleted, there is nothing to undelete
+		err := this.purge(context.Background())
 		if err != nil {
 			log.Errorf("Received error %v when deleting local snapshots of %v during cleanup", err.Error(), this.peinfo.GetID())
 			return
 		}
-		if !status {
-			log.Errorf("Failed in deleting local snapshots of %v during cleanup", this.peinfo.GetID())
-			return
-		}
 		log.Infof("Successfully deleted any uploaded snapshots for %v present", this.peinfo.GetID())
 	} else {
 		log.Debugf("The PE: %v was uploaded successfully, no abort detected.", peInfo.GetName())
@@ -632,7 +698,12 @@ func (this *ProtectedEntity) getReader(ctx context.Context, key string) (io.Read
 	}
 	segmentReader, err := newS3SegmentReader(this.rpetm.s3, s3Segments, this.rpetm.bucket)
 	s3BufferedReader := bufio.NewReaderSize(&segmentReader, 1024*1024)
-	return ioutil.NopCloser(s3BufferedReader), nil
+	// The length is known from the segments, so the stream can be sized without reading it
+	var size int64
+	for _, segment := range s3Segments {
+		size += segment.length
+	}
+	return astrolabe.NewSizedReadCloser(ioutil.NopCloser(s3BufferedReader), size), nil
 }
 
 func (this ProtectedEntity) Overwrite(ctx context.Context, sourcePE astrolabe.ProtectedEntity, params map[string]map[string]interface{},
@@ -24,8 +24,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"github.com/vmware-tanzu/astrolabe/pkg/metrics"
 	"io"
 	"strings"
+	"time"
 )
 
 /*
@@ -38,14 +40,25 @@ type ProtectedEntityTypeManager struct {
 	s3                                               s3.S3
 	bucket                                           string
 	objectPrefix, peinfoPrefix, mdPrefix, dataPrefix string
+	trashPrefix                                      string
 	logger                                           logrus.FieldLogger
 	maxSegmentSize                                   int64
 	maxBufferSize                                    int64
 	maxParts                                         int64
+	objectLockPolicy                                 ObjectLockPolicy
+	undeleteWindow                                   time.Duration
+	tieringPolicies                                  []TieringPolicy
+	rehydrationOptions                               RehydrationOptions
+	maxCopyObjectSize                                int64
+	// Stops the purger and the tierer, see Close
+	stopBackground context.CancelFunc
 }
 
 func NewS3RepositoryProtectedEntityTypeManager(typeName string, session session.Session, bucket string,
 	prefix string, logger logrus.FieldLogger) (*ProtectedEntityTypeManager, error) {
+	if logger == nil {
+		logger = logrus.New()
+	}
 	if !strings.HasSuffix(prefix, "/") {
 		prefix = prefix + "/"
 	}
@@ -53,19 +66,26 @@ func NewS3RepositoryProtectedEntityTypeManager(typeName string, session session.
 	peinfoPrefix := objectPrefix + "peinfo/"
 	mdPrefix := objectPrefix + "md/"
 	dataPrefix := objectPrefix + "data/"
+	trashPrefix := objectPrefix + "deleted/"
+	// Copy the handlers so that instrumenting them does not change the caller's session
+	session.Handlers = session.Handlers.Copy()
+	metrics.InstrumentS3Handlers(&session.Handlers)
 	returnPETM := ProtectedEntityTypeManager{
-		typeName:       typeName,
-		session:        session,
-		s3:             *(s3.New(&session)),
-		bucket:         bucket,
-		objectPrefix:   objectPrefix,
-		peinfoPrefix:   peinfoPrefix,
-		mdPrefix:       mdPrefix,
-		dataPrefix:     dataPrefix,
-		logger:         logger,
-		maxSegmentSize: SegmentSizeLimit,
-		maxBufferSize:  MaxBufferSize,
-		maxParts:       MaxParts,
+		typeName:           typeName,
+		session:            session,
+		s3:                 *(s3.New(&session)),
+		bucket:             bucket,
+		objectPrefix:       objectPrefix,
+		peinfoPrefix:       peinfoPrefix,
+		mdPrefix:           mdPrefix,
+		dataPrefix:         dataPrefix,
+		trashPrefix:        trashPrefix,
+		logger:             logger,
+		maxSegmentSize:     SegmentSizeLimit,
+		maxBufferSize:      MaxBufferSize,
+		maxParts:           MaxParts,
+		rehydrationOptions: DefaultRehydrationOptions,
+		maxCopyObjectSize:  MaxCopyObjectSize,
 	}
 	logger.Infof("Created S3 repo type=%s bucket=%s prefix=%s", typeName, bucket, prefix)
 	return &returnPETM, nil
@@ -162,9 +182,36 @@ func (this *ProtectedEntityTypeManager) GetTypeName() string {
 	return this.typeName
 }
 
+/*
+CheckHealth checks that the bucket can be accessed with the repository's credentials
+*/
+func (this *ProtectedEntityTypeManager) CheckHealth(ctx context.Context) error {
+	_, err := this.s3.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(this.bucket),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "could not access bucket %s", this.bucket)
+	}
+	return nil
+}
+
 const maxPEInfoSize int = 16 * 1024
 
 func (this *ProtectedEntityTypeManager) GetProtectedEntity(ctx context.Context, id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
+	deleted, err := this.isDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if deleted {
+		return nil, errors.Errorf("snapshot %s has been deleted", id.String())
+	}
+	return this.getProtectedEntity(ctx, id)
+}
+
+/*
+ * getProtectedEntity retrieves the protected entity whether or not it has been deleted
+ */
+func (this *ProtectedEntityTypeManager) getProtectedEntity(ctx context.Context, id astrolabe.ProtectedEntityID) (ProtectedEntity, error) {
 	peKey := this.peinfoName(id)
 	oi := s3.GetObjectInput{
 		Bucket: &this.bucket,
@@ -173,11 +220,11 @@ func (this *ProtectedEntityTypeManager) GetProtectedEntity(ctx context.Context,
 
 	oo, err := this.s3.GetObject(&oi)
 	if err != nil {
-		return nil, errors.Wrapf(err, "GetObject failed for bucket %s, key %s", this.bucket, peKey)
+		return ProtectedEntity{}, errors.Wrapf(err, "GetObject failed for bucket %s, key %s", this.bucket, peKey)
 	}
 	returnPE, err := NewProtectedEntityFromJSONReader(this, oo.Body)
 	if err != nil {
-		return nil, errors.Wrapf(err, "NewProtectedEntityFromJSONReader failed for %s", id.String())
+		return ProtectedEntity{}, errors.Wrapf(err, "NewProtectedEntityFromJSONReader failed for %s", id.String())
 	}
 	return returnPE, nil
 }
@@ -191,6 +238,10 @@ func (this *ProtectedEntityTypeManager) GetProtectedEntitiesByIDPrefix(ctx conte
 	if idPrefix != "" {
 		prefix = this.peinfoPrefix + idPrefix
 	}
+	deletedPEIDs, err := this.deletedIDs(ctx, idPrefix)
+	if err != nil {
+		return nil, err
+	}
 	retPEIDs := make([]astrolabe.ProtectedEntityID, 0)
 	for hasMore {
 		maxKeys := maxS3ObjectsToFetch
@@ -211,6 +262,9 @@ func (this *ProtectedEntityTypeManager) GetProtectedEntitiesByIDPrefix(ctx conte
 			s3Key := *item.Key
 			retPEID, err := this.objectPEID(s3Key)
 			if err == nil {
+				if deletedPEIDs[retPEID.String()] {
+					continue
+				}
 				retPEIDs = append(retPEIDs, retPEID)
 			} else {
 
@@ -254,7 +308,9 @@ func (this *ProtectedEntityTypeManager) Copy(ctx context.Context, sourcePE astro
 	if err != nil {
 		return nil, err
 	}
-	return this.copyInt(ctx, sourcePEInfo, options, dataReader, metadataReader)
+	// The copy reports how much of the data has been uploaded as its progress
+	return this.copyInt(ctx, sourcePEInfo, params, options, astrolabe.NewProgressReader(ctx, dataReader),
+		metadataReader)
 }
 
 func (this *ProtectedEntityTypeManager) CopyFromInfo(ctx context.Context, sourcePEInfo astrolabe.ProtectedEntityInfo, params map[string]map[string]interface{},
@@ -264,7 +320,8 @@ func (this *ProtectedEntityTypeManager) CopyFromInfo(ctx context.Context, source
 }
 
 func (this *ProtectedEntityTypeManager) copyInt(ctx context.Context, sourcePEInfo astrolabe.ProtectedEntityInfo,
-	options astrolabe.CopyCreateOptions, dataReader io.Reader, metadataReader io.Reader) (astrolabe.ProtectedEntity, error) {
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions, dataReader io.Reader,
+	metadataReader io.Reader) (astrolabe.ProtectedEntity, error) {
 	id := sourcePEInfo.GetID()
 	if id.GetPeType() != this.typeName {
 		return nil, errors.New(id.GetPeType() + " is not of type " + this.typeName)
@@ -277,7 +334,24 @@ func (this *ProtectedEntityTypeManager) copyInt(ctx context.Context, sourcePEInf
 		return nil, errors.New("UpdateExistingObject not supported")
 	}
 
-	_, err := this.GetProtectedEntity(ctx, id)
+	// Labels and annotations passed in the params are merged over the ones carried by the source
+	labels, err := astrolabe.GetLabelsFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := astrolabe.GetAnnotationsFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	retention, err := this.retentionForCopy(params, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deleted snapshots still occupy the ID until they are purged
+	_, err = this.getProtectedEntity(ctx, id)
 	if err == nil {
 		return nil, errors.New("id " + id.String() + " already exists")
 	}
@@ -304,15 +378,33 @@ func (this *ProtectedEntityTypeManager) copyInt(ctx context.Context, sourcePEInf
 
 	combinedTransports := []astrolabe.DataTransport{}
 
-	rPEInfo := astrolabe.NewProtectedEntityInfo(sourcePEInfo.GetID(), sourcePEInfo.GetName(),
-		dataTransports, metadataTransports, combinedTransports, sourcePEInfo.GetComponentIDs())
+	// Component specs keep their servers so that the components can be found on their own servers
+	rPEInfo := astrolabe.NewProtectedEntityInfoWithComponentSpecs(sourcePEInfo.GetID(), sourcePEInfo.GetName(),
+		dataTransports, metadataTransports, combinedTransports, sourcePEInfo.GetComponentSpecs(),
+		astrolabe.MergeStringMaps(sourcePEInfo.GetLabels(), labels),
+		astrolabe.MergeStringMaps(astrolabe.MergeStringMaps(sourcePEInfo.GetAnnotations(), annotations),
+			tierAnnotations(s3.StorageClassStandard, now)))
 
 	rpe := ProtectedEntity{
-		rpetm:  this,
-		peinfo: rPEInfo,
+		rpetm:     this,
+		peinfo:    rPEInfo,
+		retention: retention,
 	}
 
-	_, err = rpe.DeleteSnapshot(ctx, id.GetSnapshotID(), make(map[string]map[string]interface{}))
+	err = this.checkRetention(ctx, id)
+	if err != nil {
+		if astrolabe.IsRetentionError(err) {
+			return nil, errors.Wrapf(err, "segments from a previous upload of %s are retained", id.String())
+		}
+		this.checkIfCanceledError(&err)
+		return nil, err
+	}
+	// Remove leftovers from a previous upload or an interrupted purge, including a stale tombstone which would
+	// otherwise hide (and later purge) the new copy
+	err = rpe.purge(ctx)
+	if err == nil {
+		err = this.removeTombstone(ctx, id)
+	}
 	if err != nil {
 		this.checkIfCanceledError(&err)
 		return nil, err
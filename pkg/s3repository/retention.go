@@ -0,0 +1,321 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+ * Snapshots can be written with S3 Object Lock retention so that they cannot be deleted before a retention date.  The
+ * bucket must have Object Lock enabled.  Every object of the snapshot (peinfo, md and data segments) is written with
+ * the same retention and legal hold.  Governance retention is never bypassed by the repository.
+ *
+ * Retention for a copy comes from the type manager's ObjectLockPolicy and can be overridden with the ObjectLockParamKey
+ * param group.
+ */
+const (
+	ObjectLockParamKey              = "objectLock"
+	ObjectLockModeParamKey          = "mode"          // governance or compliance
+	ObjectLockRetainUntilParamKey   = "retainUntil"   // RFC3339
+	ObjectLockRetentionDaysParamKey = "retentionDays" // Used if retainUntil is not set
+	ObjectLockLegalHoldParamKey     = "legalHold"     // true or false
+)
+
+type ObjectLockPolicy struct {
+	Mode            astrolabe.RetentionMode
+	RetentionPeriod time.Duration
+	LegalHold       bool
+}
+
+/*
+ * SetObjectLockPolicy sets the default retention for snapshots copied into the repository from now on.  A policy with
+ * RetentionModeNone and no legal hold disables object lock.
+ */
+func (this *ProtectedEntityTypeManager) SetObjectLockPolicy(policy ObjectLockPolicy) error {
+	if policy.Mode != astrolabe.RetentionModeNone && policy.RetentionPeriod <= 0 {
+		return errors.Errorf("retention period must be positive for %s retention", policy.Mode)
+	}
+	if _, err := astrolabe.NewRetentionMode(string(policy.Mode)); err != nil {
+		return err
+	}
+	this.objectLockPolicy = policy
+	return nil
+}
+
+func (this *ProtectedEntityTypeManager) retentionForCopy(params map[string]map[string]interface{},
+	now time.Time) (astrolabe.Retention, error) {
+	retention := astrolabe.Retention{
+		Mode:      this.objectLockPolicy.Mode,
+		LegalHold: this.objectLockPolicy.LegalHold,
+	}
+	if retention.Mode != astrolabe.RetentionModeNone {
+		retention.RetainUntil = now.Add(this.objectLockPolicy.RetentionPeriod)
+	}
+	lockParams, ok := params[ObjectLockParamKey]
+	if !ok {
+		return retention, nil
+	}
+	if modeIF, ok := lockParams[ObjectLockModeParamKey]; ok {
+		mode, err := astrolabe.NewRetentionMode(fmt.Sprint(modeIF))
+		if err != nil {
+			return astrolabe.Retention{}, err
+		}
+		retention.Mode = mode
+	}
+	if retainUntilIF, ok := lockParams[ObjectLockRetainUntilParamKey]; ok {
+		retainUntil, err := time.Parse(time.RFC3339, fmt.Sprint(retainUntilIF))
+		if err != nil {
+			return astrolabe.Retention{}, errors.Wrapf(err, "invalid %s", ObjectLockRetainUntilParamKey)
+		}
+		retention.RetainUntil = retainUntil
+	} else if retentionDaysIF, ok := lockParams[ObjectLockRetentionDaysParamKey]; ok {
+		retentionDays, err := strconv.ParseFloat(fmt.Sprint(retentionDaysIF), 64)
+		if err != nil || retentionDays <= 0 {
+			return astrolabe.Retention{}, errors.Errorf("invalid %s %v", ObjectLockRetentionDaysParamKey, retentionDaysIF)
+		}
+		retention.RetainUntil = now.Add(time.Duration(retentionDays * float64(24*time.Hour)))
+	}
+	if legalHoldIF, ok := lockParams[ObjectLockLegalHoldParamKey]; ok {
+		legalHold, err := strconv.ParseBool(fmt.Sprint(legalHoldIF))
+		if err != nil {
+			return astrolabe.Retention{}, errors.Wrapf(err, "invalid %s", ObjectLockLegalHoldParamKey)
+		}
+		retention.LegalHold = legalHold
+	}
+	if retention.Mode == astrolabe.RetentionModeNone {
+		retention.RetainUntil = time.Time{}
+	} else if !retention.RetainUntil.After(now) {
+		return astrolabe.Retention{}, errors.Errorf("%s retention requires a retain until date in the future", retention.Mode)
+	}
+	return retention, nil
+}
+
+/*
+ * objectLockHeaders returns the values for the x-amz-object-lock-* headers for a new object, nil values are not sent.
+ */
+func objectLockHeaders(retention astrolabe.Retention) (mode *string, retainUntil *time.Time, legalHold *string) {
+	if retention.Mode != astrolabe.RetentionModeNone {
+		mode = s3ObjectLockMode(retention.Mode)
+		retainUntil = aws.Time(retention.RetainUntil.UTC())
+	}
+	if retention.LegalHold {
+		legalHold = aws.String(s3.ObjectLockLegalHoldStatusOn)
+	}
+	return
+}
+
+func s3ObjectLockMode(mode astrolabe.RetentionMode) *string {
+	switch mode {
+	case astrolabe.RetentionModeGovernance:
+		return aws.String(s3.ObjectLockModeGovernance)
+	case astrolabe.RetentionModeCompliance:
+		return aws.String(s3.ObjectLockModeCompliance)
+	}
+	return nil
+}
+
+func retentionFromS3(mode *string, retainUntil *time.Time, legalHold *string) astrolabe.Retention {
+	retention := astrolabe.Retention{
+		LegalHold: aws.StringValue(legalHold) == s3.ObjectLockLegalHoldStatusOn,
+	}
+	switch aws.StringValue(mode) {
+	case s3.ObjectLockModeGovernance:
+		retention.Mode = astrolabe.RetentionModeGovernance
+	case s3.ObjectLockModeCompliance:
+		retention.Mode = astrolabe.RetentionModeCompliance
+	}
+	if retention.Mode != astrolabe.RetentionModeNone && retainUntil != nil {
+		retention.RetainUntil = *retainUntil
+	}
+	return retention
+}
+
+/*
+ * snapshotObjectKeys returns the keys of all of the objects stored for a snapshot.  The peinfo key is always last so
+ * that it is updated after the segments it describes.
+ */
+func (this *ProtectedEntityTypeManager) snapshotObjectKeys(ctx context.Context, id astrolabe.ProtectedEntityID) ([]string, error) {
+	keys := []string{}
+	for _, streamName := range []string{this.dataName(id), this.metadataName(id)} {
+		err := this.s3.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+			Bucket: aws.String(this.bucket),
+			Prefix: aws.String(streamName + "/"),
+		}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, object := range output.Contents {
+				keys = append(keys, aws.StringValue(object.Key))
+			}
+			return true
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to list segments for %s in bucket %s", streamName, this.bucket)
+		}
+	}
+	peinfoName := this.peinfoName(id)
+	_, err := this.s3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(this.bucket),
+		Key:    aws.String(peinfoName),
+	})
+	if err == nil {
+		keys = append(keys, peinfoName)
+	} else if !isNotFound(err) {
+		return nil, errors.Wrapf(err, "Failed to retrieve %s from bucket %s", peinfoName, this.bucket)
+	}
+	return keys, nil
+}
+
+func (this *ProtectedEntityTypeManager) getObjectRetention(ctx context.Context, key string) (astrolabe.Retention, error) {
+	headOutput, err := this.s3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(this.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return astrolabe.Retention{}, errors.Wrapf(err, "Failed to retrieve retention for %s from bucket %s", key, this.bucket)
+	}
+	return retentionFromS3(headOutput.ObjectLockMode, headOutput.ObjectLockRetainUntilDate,
+		headOutput.ObjectLockLegalHoldStatus), nil
+}
+
+/*
+ * checkRetention returns a RetentionError if any of the objects for the snapshot are retained.  This is checked
+ * before any object is deleted so that a retained snapshot is never partially deleted.
+ */
+func (this *ProtectedEntityTypeManager) checkRetention(ctx context.Context, id astrolabe.ProtectedEntityID) error {
+	keys, err := this.snapshotObjectKeys(ctx, id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, key := range keys {
+		retention, err := this.getObjectRetention(ctx, key)
+		if err != nil {
+			return err
+		}
+		if retention.IsRetained(now) {
+			return astrolabe.RetentionError{
+				ID:        id,
+				Retention: retention,
+			}
+		}
+	}
+	return nil
+}
+
+/*
+ * GetRetention returns the retention of the snapshot, as recorded on its peinfo object.
+ */
+func (this *ProtectedEntityTypeManager) GetRetention(ctx context.Context, id astrolabe.ProtectedEntityID) (astrolabe.Retention, error) {
+	if !id.HasSnapshot() {
+		return astrolabe.Retention{}, errors.Errorf("%s does not have a snapshot ID", id.String())
+	}
+	return this.getObjectRetention(ctx, this.peinfoName(id))
+}
+
+/*
+ * ExtendRetention moves the retain until date of every object of the snapshot to retainUntil.  Retention cannot be
+ * shortened.  Snapshots that were written without retention get the mode from the type manager's policy.
+ */
+func (this *ProtectedEntityTypeManager) ExtendRetention(ctx context.Context, id astrolabe.ProtectedEntityID, retainUntil time.Time) error {
+	current, err := this.GetRetention(ctx, id)
+	if err != nil {
+		return err
+	}
+	mode := current.Mode
+	if mode == astrolabe.RetentionModeNone {
+		mode = this.objectLockPolicy.Mode
+		if mode == astrolabe.RetentionModeNone {
+			return errors.Errorf("snapshot %s does not have retention and no object lock policy is set", id.String())
+		}
+	} else if retainUntil.Before(current.RetainUntil) {
+		return errors.Errorf("cannot shorten retention of snapshot %s from %s to %s", id.String(),
+			current.RetainUntil.UTC().Format(time.RFC3339), retainUntil.UTC().Format(time.RFC3339))
+	}
+	if !retainUntil.After(time.Now()) {
+		return errors.Errorf("retain until date %s is in the past", retainUntil.UTC().Format(time.RFC3339))
+	}
+	keys, err := this.snapshotObjectKeys(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		_, err = this.s3.PutObjectRetentionWithContext(ctx, &s3.PutObjectRetentionInput{
+			Bucket: aws.String(this.bucket),
+			Key:    aws.String(key),
+			Retention: &s3.ObjectLockRetention{
+				Mode:            s3ObjectLockMode(mode),
+				RetainUntilDate: aws.Time(retainUntil.UTC()),
+			},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Failed to extend retention for %s in bucket %s", key, this.bucket)
+		}
+	}
+	this.logger.Infof("Extended %s retention of %s until %s", mode, id.String(), retainUntil.UTC().Format(time.RFC3339))
+	return nil
+}
+
+/*
+ * SetLegalHold places or releases a legal hold on every object of the snapshot.  A snapshot under a legal hold cannot
+ * be deleted regardless of its retention date.
+ */
+func (this *ProtectedEntityTypeManager) SetLegalHold(ctx context.Context, id astrolabe.ProtectedEntityID, legalHold bool) error {
+	if !id.HasSnapshot() {
+		return errors.Errorf("%s does not have a snapshot ID", id.String())
+	}
+	status := s3.ObjectLockLegalHoldStatusOff
+	if legalHold {
+		status = s3.ObjectLockLegalHoldStatusOn
+	}
+	keys, err := this.snapshotObjectKeys(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return errors.Errorf("snapshot %s not found in bucket %s", id.String(), this.bucket)
+	}
+	for _, key := range keys {
+		_, err = this.s3.PutObjectLegalHoldWithContext(ctx, &s3.PutObjectLegalHoldInput{
+			Bucket: aws.String(this.bucket),
+			Key:    aws.String(key),
+			LegalHold: &s3.ObjectLockLegalHold{
+				Status: aws.String(status),
+			},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Failed to set legal hold for %s in bucket %s", key, this.bucket)
+		}
+	}
+	this.logger.Infof("Set legal hold %s on %s", status, id.String())
+	return nil
+}
+
+func isNotFound(err error) bool {
+	if requestFailure, ok := errors.Cause(err).(awserr.RequestFailure); ok {
+		return requestFailure.StatusCode() == http.StatusNotFound
+	}
+	return false
+}
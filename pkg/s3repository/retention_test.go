@@ -0,0 +1,163 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3repository
+
+import (
+	"bytes"
+	"context"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+func copyRetentionTestPE(t *testing.T, petm *ProtectedEntityTypeManager, snapshot string,
+	params map[string]map[string]interface{}) astrolabe.ProtectedEntity {
+	peID := astrolabe.NewProtectedEntityIDWithSnapshotID("ivd", "disk1", astrolabe.NewProtectedEntitySnapshotID(snapshot))
+	sourcePE := newMemoryProtectedEntity(peID, bytes.Repeat([]byte("astrolabe"), 1000), []byte("metadata"))
+	repoPE, err := petm.Copy(context.Background(), sourcePE, params, astrolabe.AllocateNewObject)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return repoPE
+}
+
+func TestRetentionPolicy(t *testing.T) {
+	fake := newFakeS3Server()
+	defer fake.Close()
+	petm := fake.newPETM(t, "ivd", "worm-bucket")
+	ctx := context.Background()
+
+	err := petm.SetObjectLockPolicy(ObjectLockPolicy{
+		Mode:            astrolabe.RetentionModeGovernance,
+		RetentionPeriod: time.Hour,
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	repoPE := copyRetentionTestPE(t, petm, "snap1", nil)
+	objectCount := fake.objectCount("worm-bucket")
+
+	retention, err := petm.GetRetention(ctx, repoPE.GetID())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, astrolabe.RetentionModeGovernance, retention.Mode)
+	assert.Assert(t, retention.IsRetained(time.Now()), "snapshot should be retained")
+
+	_, err = repoPE.DeleteSnapshot(ctx, repoPE.GetID().GetSnapshotID(), nil)
+	assert.Assert(t, astrolabe.IsRetentionError(err), "expected retention error, got %v", err)
+	assert.Equal(t, objectCount, fake.objectCount("worm-bucket"))
+
+	extended := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	err = petm.ExtendRetention(ctx, repoPE.GetID(), extended)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	retention, err = petm.GetRetention(ctx, repoPE.GetID())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, retention.RetainUntil.Equal(extended), "retain until %v, expected %v", retention.RetainUntil, extended)
+
+	err = petm.ExtendRetention(ctx, repoPE.GetID(), time.Now().Add(time.Hour))
+	assert.Assert(t, err != nil, "shortening retention should fail")
+}
+
+func TestLegalHold(t *testing.T) {
+	fake := newFakeS3Server()
+	defer fake.Close()
+	petm := fake.newPETM(t, "ivd", "worm-bucket")
+	ctx := context.Background()
+	// Remove the objects on delete rather than keeping them for undelete
+	err := petm.SetUndeleteWindow(0)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	repoPE := copyRetentionTestPE(t, petm, "snap1", nil)
+	retention, err := petm.GetRetention(ctx, repoPE.GetID())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, !retention.IsRetained(time.Now()), "snapshot should not be retained without a policy")
+
+	err = petm.SetLegalHold(ctx, repoPE.GetID(), true)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, err = repoPE.DeleteSnapshot(ctx, repoPE.GetID().GetSnapshotID(), nil)
+	assert.Assert(t, astrolabe.IsRetentionError(err), "expected retention error, got %v", err)
+
+	err = petm.SetLegalHold(ctx, repoPE.GetID(), false)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	deleted, err := repoPE.DeleteSnapshot(ctx, repoPE.GetID().GetSnapshotID(), nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, deleted)
+	assert.Equal(t, 0, fake.objectCount("worm-bucket"))
+}
+
+func TestRetentionParams(t *testing.T) {
+	fake := newFakeS3Server()
+	defer fake.Close()
+	petm := fake.newPETM(t, "ivd", "worm-bucket")
+	ctx := context.Background()
+
+	err := petm.SetObjectLockPolicy(ObjectLockPolicy{
+		Mode:            astrolabe.RetentionModeGovernance,
+		RetentionPeriod: time.Hour,
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	retainUntil := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	repoPE := copyRetentionTestPE(t, petm, "snap1", map[string]map[string]interface{}{
+		ObjectLockParamKey: {
+			ObjectLockModeParamKey:        "compliance",
+			ObjectLockRetainUntilParamKey: retainUntil.Format(time.RFC3339),
+		},
+	})
+	retention, err := petm.GetRetention(ctx, repoPE.GetID())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, astrolabe.RetentionModeCompliance, retention.Mode)
+	assert.Assert(t, retention.RetainUntil.Equal(retainUntil), "retain until %v, expected %v", retention.RetainUntil, retainUntil)
+
+	// Opting out of the policy for a single copy
+	unretainedPE := copyRetentionTestPE(t, petm, "snap2", map[string]map[string]interface{}{
+		ObjectLockParamKey: {
+			ObjectLockModeParamKey: "none",
+		},
+	})
+	_, err = unretainedPE.DeleteSnapshot(ctx, unretainedPE.GetID().GetSnapshotID(), nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	_, err = petm.retentionForCopy(map[string]map[string]interface{}{
+		ObjectLockParamKey: {
+			ObjectLockRetainUntilParamKey: time.Now().Add(-time.Hour).Format(time.RFC3339),
+		},
+	}, time.Now())
+	assert.Assert(t, err != nil, "retain until in the past should fail")
+}
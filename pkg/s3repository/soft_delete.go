@@ -0,0 +1,256 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3repository
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+ * Deleting a snapshot does not remove its objects immediately.  Instead an empty tombstone object is written to
+ *    <bucket>/<user specified prefix>/<type>/deleted/<peid>
+ * and the snapshot is hidden from listings and GetProtectedEntity.  The snapshot can be restored by removing the
+ * tombstone until the undelete window, measured from the tombstone's last modified time, has passed.  After that
+ * PurgeDeletedSnapshots removes the snapshot's objects and then the tombstone.
+ *
+ * An undelete window of zero, the default, disables soft delete and DeleteSnapshot removes the objects immediately.
+ * Snapshots are only purged if something calls PurgeDeletedSnapshots, so a window should only be set together with
+ * StartPurger, as NewS3RepositoryProtectedEntityTypeManagerFromConfig does.
+ */
+
+/*
+ * SetUndeleteWindow sets how long deleted snapshots can be restored for.  Changing the window applies to snapshots
+ * that have already been deleted as well.
+ */
+func (this *ProtectedEntityTypeManager) SetUndeleteWindow(window time.Duration) error {
+	if window < 0 {
+		return errors.Errorf("undelete window %v cannot be negative", window)
+	}
+	this.undeleteWindow = window
+	return nil
+}
+
+func (this *ProtectedEntityTypeManager) tombstoneName(id astrolabe.ProtectedEntityID) string {
+	if !id.HasSnapshot() {
+		panic("Cannot store objects that do not have snapshots")
+	}
+	return this.trashPrefix + id.String()
+}
+
+func (this *ProtectedEntityTypeManager) writeTombstone(ctx context.Context, id astrolabe.ProtectedEntityID) error {
+	tombstoneName := this.tombstoneName(id)
+	_, err := this.s3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(this.bucket),
+		Key:    aws.String(tombstoneName),
+		Body:   bytes.NewReader([]byte{}),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to write tombstone %s to bucket %s", tombstoneName, this.bucket)
+	}
+	this.logger.Infof("Deleted snapshot %s, it can be undeleted until %s", id.String(),
+		time.Now().Add(this.undeleteWindow).UTC().Format(time.RFC3339))
+	return nil
+}
+
+func (this *ProtectedEntityTypeManager) removeTombstone(ctx context.Context, id astrolabe.ProtectedEntityID) error {
+	tombstoneName := this.tombstoneName(id)
+	_, err := this.s3.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(this.bucket),
+		Key:    aws.String(tombstoneName),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to delete tombstone %s from bucket %s", tombstoneName, this.bucket)
+	}
+	return nil
+}
+
+func (this *ProtectedEntityTypeManager) isDeleted(ctx context.Context, id astrolabe.ProtectedEntityID) (bool, error) {
+	if !id.HasSnapshot() {
+		return false, nil
+	}
+	tombstoneName := this.tombstoneName(id)
+	_, err := this.s3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(this.bucket),
+		Key:    aws.String(tombstoneName),
+	})
+	if err == nil {
+		return true, nil
+	}
+	if isNotFound(err) {
+		return false, nil
+	}
+	return false, errors.Wrapf(err, "Failed to retrieve %s from bucket %s", tombstoneName, this.bucket)
+}
+
+/*
+ * listDeleted returns the deleted snapshots whose IDs start with idPrefix
+ */
+func (this *ProtectedEntityTypeManager) listDeleted(ctx context.Context, idPrefix string) ([]astrolabe.DeletedSnapshot, error) {
+	deletedSnapshots := []astrolabe.DeletedSnapshot{}
+	err := this.s3.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(this.bucket),
+		Prefix: aws.String(this.trashPrefix + idPrefix),
+	}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range output.Contents {
+			idStr := strings.TrimPrefix(aws.StringValue(object.Key), this.trashPrefix)
+			id, err := astrolabe.NewProtectedEntityIDFromString(idStr)
+			if err != nil {
+				this.logger.Debugf("Skipping tombstone with unparsable ID %s", idStr)
+				continue
+			}
+			deletedAt := aws.TimeValue(object.LastModified)
+			deletedSnapshots = append(deletedSnapshots, astrolabe.DeletedSnapshot{
+				ID:         id,
+				DeletedAt:  deletedAt,
+				PurgeAfter: deletedAt.Add(this.undeleteWindow),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to list deleted snapshots in bucket %s", this.bucket)
+	}
+	return deletedSnapshots, nil
+}
+
+func (this *ProtectedEntityTypeManager) deletedIDs(ctx context.Context, idPrefix string) (map[string]bool, error) {
+	deletedSnapshots, err := this.listDeleted(ctx, idPrefix)
+	if err != nil {
+		return nil, err
+	}
+	deletedIDs := map[string]bool{}
+	for _, deletedSnapshot := range deletedSnapshots {
+		deletedIDs[deletedSnapshot.ID.String()] = true
+	}
+	return deletedIDs, nil
+}
+
+/*
+ * ListDeletedSnapshots returns the deleted snapshots of id that have not been purged yet.  Snapshots whose undelete
+ * window has passed are returned until the purge runs and can still be undeleted.
+ */
+func (this *ProtectedEntityTypeManager) ListDeletedSnapshots(ctx context.Context,
+	id astrolabe.ProtectedEntityID) ([]astrolabe.DeletedSnapshot, error) {
+	if id.HasSnapshot() {
+		deletedSnapshots, err := this.listDeleted(ctx, id.String())
+		if err != nil {
+			return nil, err
+		}
+		// The prefix also matches snapshot IDs that start with this one
+		for _, deletedSnapshot := range deletedSnapshots {
+			if deletedSnapshot.ID == id {
+				return []astrolabe.DeletedSnapshot{deletedSnapshot}, nil
+			}
+		}
+		return []astrolabe.DeletedSnapshot{}, nil
+	}
+	return this.listDeleted(ctx, id.String()+":")
+}
+
+func (this *ProtectedEntityTypeManager) UndeleteSnapshot(ctx context.Context, id astrolabe.ProtectedEntityID) error {
+	if !id.HasSnapshot() {
+		return errors.Errorf("%s does not have a snapshot ID", id.String())
+	}
+	deleted, err := this.isDeleted(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.Errorf("snapshot %s is not deleted", id.String())
+	}
+	err = this.removeTombstone(ctx, id)
+	if err != nil {
+		return err
+	}
+	this.logger.Infof("Undeleted snapshot %s", id.String())
+	return nil
+}
+
+/*
+ * PurgeDeletedSnapshots removes the objects of the deleted snapshots whose undelete window has passed and returns
+ * the number of snapshots purged.  Snapshots that are still retained are skipped and purged by a later call.  The
+ * tombstone is removed last so that a failed purge is retried.
+ */
+func (this *ProtectedEntityTypeManager) PurgeDeletedSnapshots(ctx context.Context) (int, error) {
+	deletedSnapshots, err := this.listDeleted(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	purged := 0
+	for _, deletedSnapshot := range deletedSnapshots {
+		if now.Before(deletedSnapshot.PurgeAfter) {
+			continue
+		}
+		id := deletedSnapshot.ID
+		err = this.checkRetention(ctx, id)
+		if astrolabe.IsRetentionError(err) {
+			this.logger.Infof("Not purging deleted snapshot %s, %v", id.String(), err)
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		// Only the ID is needed to purge so the peinfo does not need to be readable
+		pe := ProtectedEntity{
+			rpetm:  this,
+			peinfo: astrolabe.NewProtectedEntityInfo(id, "", nil, nil, nil, nil),
+		}
+		err = pe.purge(ctx)
+		if err != nil {
+			return purged, errors.Wrapf(err, "Failed to purge deleted snapshot %s", id.String())
+		}
+		err = this.removeTombstone(ctx, id)
+		if err != nil {
+			return purged, err
+		}
+		this.logger.Infof("Purged deleted snapshot %s", id.String())
+		purged++
+	}
+	return purged, nil
+}
+
+/*
+ * StartPurger runs PurgeDeletedSnapshots every interval until ctx is done.
+ */
+func (this *ProtectedEntityTypeManager) StartPurger(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				purged, err := this.PurgeDeletedSnapshots(ctx)
+				if err != nil {
+					this.logger.Errorf("Failed to purge deleted snapshots, err: %v", err)
+				} else if purged > 0 {
+					this.logger.Infof("Purged %d deleted snapshots", purged)
+				}
+			}
+		}
+	}()
+}
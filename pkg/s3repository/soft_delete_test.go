@@ -0,0 +1,189 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3repository
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+func TestSoftDelete(t *testing.T) {
+	fake := newFakeS3Server()
+	defer fake.Close()
+	petm := fake.newPETM(t, "ivd", "trash-bucket")
+	ctx := context.Background()
+	err := petm.SetUndeleteWindow(24 * time.Hour)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	snap1PE := copyRetentionTestPE(t, petm, "snap1", nil)
+	snap2PE := copyRetentionTestPE(t, petm, "snap2", nil)
+	objectCount := fake.objectCount("trash-bucket")
+
+	deleted, err := snap1PE.DeleteSnapshot(ctx, snap1PE.GetID().GetSnapshotID(), nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, deleted)
+	// Only the tombstone has been added
+	assert.Equal(t, objectCount+1, fake.objectCount("trash-bucket"))
+
+	_, err = petm.GetProtectedEntity(ctx, snap1PE.GetID())
+	assert.Assert(t, err != nil, "deleted snapshot should not be retrievable")
+	snapshotIDs, err := snap2PE.ListSnapshots(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1, len(snapshotIDs))
+	assert.Equal(t, snap2PE.GetID().GetSnapshotID(), snapshotIDs[0])
+
+	deletedSnapshots, err := petm.ListDeletedSnapshots(ctx, astrolabe.NewProtectedEntityID("ivd", "disk1"))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1, len(deletedSnapshots))
+	assert.Equal(t, snap1PE.GetID(), deletedSnapshots[0].ID)
+	assert.Equal(t, 24*time.Hour, deletedSnapshots[0].PurgeAfter.Sub(deletedSnapshots[0].DeletedAt))
+
+	// Nothing is purged inside the undelete window
+	purged, err := petm.PurgeDeletedSnapshots(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 0, purged)
+
+	err = petm.UndeleteSnapshot(ctx, snap1PE.GetID())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, err = petm.GetProtectedEntity(ctx, snap1PE.GetID())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, objectCount, fake.objectCount("trash-bucket"))
+
+	err = petm.UndeleteSnapshot(ctx, snap1PE.GetID())
+	assert.Assert(t, err != nil, "undeleting a snapshot that is not deleted should fail")
+}
+
+func TestPurgeDeletedSnapshots(t *testing.T) {
+	fake := newFakeS3Server()
+	defer fake.Close()
+	petm := fake.newPETM(t, "ivd", "trash-bucket")
+	ctx := context.Background()
+	err := petm.SetUndeleteWindow(24 * time.Hour)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	snap1PE := copyRetentionTestPE(t, petm, "snap1", nil)
+	snap2PE := copyRetentionTestPE(t, petm, "snap2", nil)
+	for _, pe := range []astrolabe.ProtectedEntity{snap1PE, snap2PE} {
+		_, err := pe.DeleteSnapshot(ctx, pe.GetID().GetSnapshotID(), nil)
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+	}
+	// A legal hold placed after the delete keeps the snapshot from being purged
+	err = petm.SetLegalHold(ctx, snap2PE.GetID(), true)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	err = petm.SetUndeleteWindow(time.Nanosecond)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	purged, err := petm.PurgeDeletedSnapshots(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1, purged)
+	err = petm.UndeleteSnapshot(ctx, snap1PE.GetID())
+	assert.Assert(t, err != nil, "purged snapshot should not be undeletable")
+
+	deletedSnapshots, err := petm.ListDeletedSnapshots(ctx, astrolabe.NewProtectedEntityID("ivd", "disk1"))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1, len(deletedSnapshots))
+	assert.Equal(t, snap2PE.GetID(), deletedSnapshots[0].ID)
+
+	err = petm.SetLegalHold(ctx, snap2PE.GetID(), false)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	purged, err = petm.PurgeDeletedSnapshots(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1, purged)
+	assert.Equal(t, 0, fake.objectCount("trash-bucket"))
+}
+
+func TestSoftDeleteFromConfig(t *testing.T) {
+	fake := newFakeS3Server()
+	defer fake.Close()
+	ctx := context.Background()
+	// Created before the background work starts, creating a session races with requests in flight
+	sess := fake.session(t)
+
+	// Without an undelete window snapshots are deleted immediately
+	petm, err := NewS3RepositoryProtectedEntityTypeManagerFromConfig("ivd", sess, "hard-bucket", "repo",
+		map[string]interface{}{}, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer petm.Close()
+	repoPE := copyRetentionTestPE(t, petm, "snap1", nil)
+	_, err = repoPE.DeleteSnapshot(ctx, repoPE.GetID().GetSnapshotID(), nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 0, fake.objectCount("hard-bucket"))
+
+	// With one the purger removes the snapshot once the window has passed
+	petm, err = NewS3RepositoryProtectedEntityTypeManagerFromConfig("ivd", sess, "soft-bucket", "repo",
+		map[string]interface{}{
+			UndeleteWindowParam: "50ms",
+			PurgeIntervalParam:  "10ms",
+		}, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer petm.Close()
+	repoPE = copyRetentionTestPE(t, petm, "snap1", nil)
+	_, err = repoPE.DeleteSnapshot(ctx, repoPE.GetID().GetSnapshotID(), nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, fake.objectCount("soft-bucket") > 0, "deleted snapshot should be kept for the undelete window")
+	deadline := time.Now().Add(10 * time.Second)
+	for fake.objectCount("soft-bucket") > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 0, fake.objectCount("soft-bucket"))
+
+	_, err = NewS3RepositoryProtectedEntityTypeManagerFromConfig("ivd", sess, "soft-bucket", "repo",
+		map[string]interface{}{UndeleteWindowParam: "a day"}, nil)
+	assert.Assert(t, err != nil, "invalid undelete window should be rejected")
+}
@@ -0,0 +1,612 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3repository
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+ * Tiering policies move the data segments of a snapshot to colder S3 storage classes as the snapshot ages.  The peinfo
+ * and metadata objects are never tiered so that listing and inspecting snapshots stays cheap.  The current storage
+ * class of the data segments is recorded in the peinfo annotations under TierAnnotation and the time the snapshot
+ * was copied into the repository under CopiedAtAnnotation.
+ *
+ * Data in the GLACIER and DEEP_ARCHIVE classes cannot be read until it has been restored with Rehydrate.  The restored
+ * copy is kept for RehydrationOptions.Days, after which it has to be rehydrated again.
+ */
+const (
+	TierAnnotation     = "s3repository.astrolabe.vmware-tanzu.com/storage-class"
+	CopiedAtAnnotation = "s3repository.astrolabe.vmware-tanzu.com/copied-at"
+)
+
+// CopyObject is limited to 5GB, larger segments are copied with a multipart upload
+const MaxCopyObjectSize = 5 * 1024 * 1024 * 1024
+
+// Storage classes ordered from hot to cold, tiering only moves segments to a colder class
+var storageClassRanks = map[string]int{
+	s3.StorageClassStandard:           0,
+	s3.StorageClassReducedRedundancy:  0,
+	s3.StorageClassIntelligentTiering: 1,
+	s3.StorageClassStandardIa:         2,
+	s3.StorageClassOnezoneIa:          2,
+	s3.StorageClassGlacier:            3,
+	s3.StorageClassDeepArchive:        4,
+}
+
+func requiresRehydration(storageClass string) bool {
+	return storageClass == s3.StorageClassGlacier || storageClass == s3.StorageClassDeepArchive
+}
+
+type TieringPolicy struct {
+	// Snapshots older than Age have their data moved to StorageClass
+	Age          time.Duration
+	StorageClass string
+}
+
+/*
+ * SetTieringPolicies replaces the tiering policies of the type manager.  When several policies apply to a snapshot
+ * the one with the greatest Age wins.
+ */
+func (this *ProtectedEntityTypeManager) SetTieringPolicies(policies []TieringPolicy) error {
+	sortedPolicies := make([]TieringPolicy, len(policies))
+	copy(sortedPolicies, policies)
+	for _, policy := range sortedPolicies {
+		if _, ok := storageClassRanks[policy.StorageClass]; !ok {
+			return errors.Errorf("unknown storage class %q", policy.StorageClass)
+		}
+		if policy.Age < 0 {
+			return errors.Errorf("tiering age %v cannot be negative", policy.Age)
+		}
+	}
+	sort.Slice(sortedPolicies, func(i, j int) bool {
+		return sortedPolicies[i].Age < sortedPolicies[j].Age
+	})
+	this.tieringPolicies = sortedPolicies
+	return nil
+}
+
+type RehydrationOptions struct {
+	// Number of days the restored copy of the data is kept
+	Days int64
+	// Glacier retrieval tier, one of the s3.Tier* values
+	RetrievalTier string
+	// How often the rehydration task checks whether the restore has finished
+	PollInterval time.Duration
+}
+
+var DefaultRehydrationOptions = RehydrationOptions{
+	Days:          7,
+	RetrievalTier: s3.TierStandard,
+	PollInterval:  time.Minute,
+}
+
+func (this *ProtectedEntityTypeManager) SetRehydrationOptions(options RehydrationOptions) error {
+	if options.Days < 1 {
+		return errors.Errorf("rehydration days %d must be at least 1", options.Days)
+	}
+	switch options.RetrievalTier {
+	case s3.TierStandard, s3.TierBulk, s3.TierExpedited:
+	default:
+		return errors.Errorf("unknown retrieval tier %q", options.RetrievalTier)
+	}
+	if options.PollInterval <= 0 {
+		return errors.Errorf("poll interval %v must be positive", options.PollInterval)
+	}
+	this.rehydrationOptions = options
+	return nil
+}
+
+func tierAnnotations(storageClass string, copiedAt time.Time) map[string]string {
+	return map[string]string{
+		TierAnnotation:     storageClass,
+		CopiedAtAnnotation: copiedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func storageTier(peinfo astrolabe.ProtectedEntityInfo) string {
+	if tier, ok := peinfo.GetAnnotations()[TierAnnotation]; ok {
+		return tier
+	}
+	// Snapshots copied before tiering was available have never been moved
+	return s3.StorageClassStandard
+}
+
+/*
+ * GetStorageTier returns the storage class of the data segments of the snapshot id
+ */
+func (this *ProtectedEntityTypeManager) GetStorageTier(ctx context.Context, id astrolabe.ProtectedEntityID) (string, error) {
+	pe, err := this.getProtectedEntity(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return storageTier(pe.peinfo), nil
+}
+
+/*
+ * ApplyTieringPolicies moves the data of every snapshot that is older than a policy's Age to the policy's storage
+ * class and returns the number of snapshots that were moved.
+ */
+func (this *ProtectedEntityTypeManager) ApplyTieringPolicies(ctx context.Context) (int, error) {
+	if len(this.tieringPolicies) == 0 {
+		return 0, nil
+	}
+	peinfoModified := map[string]time.Time{}
+	err := this.s3.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(this.bucket),
+		Prefix: aws.String(this.peinfoPrefix),
+	}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range output.Contents {
+			idStr := strings.TrimPrefix(aws.StringValue(object.Key), this.peinfoPrefix)
+			peinfoModified[idStr] = aws.TimeValue(object.LastModified)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "Failed to list snapshots in bucket %s", this.bucket)
+	}
+	deletedIDs, err := this.deletedIDs(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	tiered := 0
+	for idStr, lastModified := range peinfoModified {
+		if deletedIDs[idStr] {
+			continue
+		}
+		id, err := astrolabe.NewProtectedEntityIDFromString(idStr)
+		if err != nil {
+			this.logger.Debugf("Skipping peinfo with unparsable ID %s", idStr)
+			continue
+		}
+		pe, err := this.getProtectedEntity(ctx, id)
+		if err != nil {
+			return tiered, err
+		}
+		if len(pe.peinfo.GetDataTransports()) == 0 {
+			continue
+		}
+		copiedAt := lastModified
+		if copiedAtStr, ok := pe.peinfo.GetAnnotations()[CopiedAtAnnotation]; ok {
+			if parsed, err := time.Parse(time.RFC3339, copiedAtStr); err == nil {
+				copiedAt = parsed
+			}
+		}
+		targetClass := ""
+		for _, policy := range this.tieringPolicies {
+			if now.Sub(copiedAt) >= policy.Age {
+				targetClass = policy.StorageClass
+			}
+		}
+		currentClass := storageTier(pe.peinfo)
+		if targetClass == "" || storageClassRanks[targetClass] <= storageClassRanks[currentClass] {
+			continue
+		}
+		err = this.tierSnapshot(ctx, pe, targetClass)
+		if err != nil {
+			return tiered, errors.Wrapf(err, "Failed to move snapshot %s to %s", idStr, targetClass)
+		}
+		this.logger.Infof("Moved data of snapshot %s from %s to %s", idStr, currentClass, targetClass)
+		tiered++
+	}
+	return tiered, nil
+}
+
+/*
+ * StartTierer runs ApplyTieringPolicies every interval until ctx is done.
+ */
+func (this *ProtectedEntityTypeManager) StartTierer(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				tiered, err := this.ApplyTieringPolicies(ctx)
+				if err != nil {
+					this.logger.Errorf("Failed to apply tiering policies, err: %v", err)
+				} else if tiered > 0 {
+					this.logger.Infof("Moved %d snapshots to colder storage", tiered)
+				}
+			}
+		}
+	}()
+}
+
+/*
+ * tierSnapshot moves the data segments of pe to storageClass and then records the new tier in the peinfo.  If the
+ * move is interrupted the peinfo still has the old tier and the move is retried by the next ApplyTieringPolicies.
+ * The share of the data that has been moved is reported with astrolabe.ReportProgress.
+ */
+func (this *ProtectedEntityTypeManager) tierSnapshot(ctx context.Context, pe ProtectedEntity, storageClass string) error {
+	id := pe.GetID()
+	segments, err := pe.getS3Segments(ctx, this.bucket, this.dataName(id)+"/")
+	if err != nil {
+		return errors.Wrapf(err, "Failed to list data segments of %s", id.String())
+	}
+	var size, moved int64
+	for _, segment := range segments {
+		size += segment.length
+	}
+	for _, segment := range segments {
+		err = this.changeStorageClass(ctx, segment.key, segment.length, storageClass)
+		if err != nil {
+			return err
+		}
+		moved += segment.length
+		if size > 0 {
+			astrolabe.ReportProgress(ctx, float64(moved)*100/float64(size))
+		}
+	}
+
+	peinfo := pe.peinfo
+	annotations := astrolabe.MergeStringMaps(peinfo.GetAnnotations(), map[string]string{
+		TierAnnotation: storageClass,
+	})
+	pe.peinfo = astrolabe.NewProtectedEntityInfoWithComponentSpecs(peinfo.GetID(), peinfo.GetName(),
+		peinfo.GetDataTransports(), peinfo.GetMetadataTransports(), peinfo.GetCombinedTransports(),
+		peinfo.GetComponentSpecs(), peinfo.GetLabels(), annotations)
+	pe.retention, err = this.getObjectRetention(ctx, this.peinfoName(id))
+	if err != nil {
+		return err
+	}
+	return pe.putPEInfo(ctx)
+}
+
+/*
+ * unexpiredRetention returns retention without its retain until date if that has passed, S3 rejects lock headers with
+ * a retain until date in the past.
+ */
+func unexpiredRetention(retention astrolabe.Retention, now time.Time) astrolabe.Retention {
+	if retention.Mode != astrolabe.RetentionModeNone && !retention.RetainUntil.After(now) {
+		retention.Mode = astrolabe.RetentionModeNone
+		retention.RetainUntil = time.Time{}
+	}
+	return retention
+}
+
+/*
+ * changeStorageClass copies the object onto itself with the new storage class.  The object lock of the object is
+ * carried over to the copy.
+ */
+func (this *ProtectedEntityTypeManager) changeStorageClass(ctx context.Context, key string, size int64,
+	storageClass string) error {
+	headOutput, err := this.s3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(this.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to retrieve %s from bucket %s", key, this.bucket)
+	}
+	retention := unexpiredRetention(retentionFromS3(headOutput.ObjectLockMode, headOutput.ObjectLockRetainUntilDate,
+		headOutput.ObjectLockLegalHoldStatus), time.Now())
+	lockMode, lockRetainUntil, lockLegalHold := objectLockHeaders(retention)
+	copySource := aws.String(url.PathEscape(this.bucket + "/" + key))
+
+	if size <= this.maxCopyObjectSize {
+		_, err = this.s3.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+			Bucket:                    aws.String(this.bucket),
+			Key:                       aws.String(key),
+			CopySource:                copySource,
+			MetadataDirective:         aws.String(s3.MetadataDirectiveCopy),
+			StorageClass:              aws.String(storageClass),
+			ObjectLockMode:            lockMode,
+			ObjectLockRetainUntilDate: lockRetainUntil,
+			ObjectLockLegalHoldStatus: lockLegalHold,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Failed to change storage class of %s in bucket %s to %s", key, this.bucket,
+				storageClass)
+		}
+		return nil
+	}
+
+	createOutput, err := this.s3.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:                    aws.String(this.bucket),
+		Key:                       aws.String(key),
+		ContentType:               headOutput.ContentType,
+		Metadata:                  headOutput.Metadata,
+		StorageClass:              aws.String(storageClass),
+		ObjectLockMode:            lockMode,
+		ObjectLockRetainUntilDate: lockRetainUntil,
+		ObjectLockLegalHoldStatus: lockLegalHold,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to start copy of %s in bucket %s", key, this.bucket)
+	}
+	completedParts := []*s3.CompletedPart{}
+	var partNumber int64 = 1
+	for offset := int64(0); offset < size; offset += this.maxCopyObjectSize {
+		end := offset + this.maxCopyObjectSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		var partOutput *s3.UploadPartCopyOutput
+		partOutput, err = this.s3.UploadPartCopyWithContext(ctx, &s3.UploadPartCopyInput{
+			Bucket:          aws.String(this.bucket),
+			Key:             aws.String(key),
+			UploadId:        createOutput.UploadId,
+			PartNumber:      aws.Int64(partNumber),
+			CopySource:      copySource,
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", offset, end)),
+		})
+		if err != nil {
+			break
+		}
+		completedParts = append(completedParts, &s3.CompletedPart{
+			ETag:       partOutput.CopyPartResult.ETag,
+			PartNumber: aws.Int64(partNumber),
+		})
+		partNumber++
+	}
+	if err == nil {
+		_, err = this.s3.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:   aws.String(this.bucket),
+			Key:      aws.String(key),
+			UploadId: createOutput.UploadId,
+			MultipartUpload: &s3.CompletedMultipartUpload{
+				Parts: completedParts,
+			},
+		})
+	}
+	if err != nil {
+		_, abortErr := this.s3.AbortMultipartUploadWithContext(context.Background(), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(this.bucket),
+			Key:      aws.String(key),
+			UploadId: createOutput.UploadId,
+		})
+		if abortErr != nil {
+			this.logger.Errorf("Failed to abort copy of %s in bucket %s, err: %v", key, this.bucket, abortErr)
+		}
+		return errors.Wrapf(err, "Failed to change storage class of %s in bucket %s to %s", key, this.bucket,
+			storageClass)
+	}
+	return nil
+}
+
+/*
+ * isRestored parses the x-amz-restore header returned by HeadObject
+ */
+func isRestored(restoreHeader *string) bool {
+	return strings.Contains(aws.StringValue(restoreHeader), `ongoing-request="false"`)
+}
+
+/*
+ * restoredSegments returns the number of segments that have a readable restored copy
+ */
+func (this *ProtectedEntityTypeManager) restoredSegments(ctx context.Context, segments []s3Segment) (int, error) {
+	restored := 0
+	for _, segment := range segments {
+		headOutput, err := this.s3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(this.bucket),
+			Key:    aws.String(segment.key),
+		})
+		if err != nil {
+			return restored, errors.Wrapf(err, "Failed to retrieve %s from bucket %s", segment.key, this.bucket)
+		}
+		if isRestored(headOutput.Restore) {
+			restored++
+		}
+	}
+	return restored, nil
+}
+
+/*
+ * checkReadable returns a RehydrationRequiredError if the data of pe is archived and has not been restored
+ */
+func (this *ProtectedEntityTypeManager) checkReadable(ctx context.Context, pe ProtectedEntity,
+	segments []s3Segment) error {
+	tier := storageTier(pe.peinfo)
+	if !requiresRehydration(tier) {
+		return nil
+	}
+	restored, err := this.restoredSegments(ctx, segments)
+	if err != nil {
+		return err
+	}
+	if restored < len(segments) {
+		return astrolabe.RehydrationRequiredError{
+			ID:   pe.GetID(),
+			Tier: tier,
+		}
+	}
+	return nil
+}
+
+/*
+ * Rehydrate requests a restore of the archived data segments of the snapshot id and returns a task that completes
+ * when all of the segments can be read.  The restore continues in S3 if the task is cancelled.
+ */
+func (this *ProtectedEntityTypeManager) Rehydrate(ctx context.Context, id astrolabe.ProtectedEntityID) (astrolabe.Task, error) {
+	peIntf, err := this.GetProtectedEntity(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	pe := peIntf.(ProtectedEntity)
+	segments, err := pe.getS3Segments(ctx, this.bucket, this.dataName(id)+"/")
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to list data segments of %s", id.String())
+	}
+	taskCtx, cancel := context.WithCancel(context.Background())
+	task := &rehydrationTask{
+		task:   astrolabe.NewGenericTask(),
+		cancel: cancel,
+	}
+	tier := storageTier(pe.peinfo)
+	if !requiresRehydration(tier) || len(segments) == 0 {
+		cancel()
+		task.finish(astrolabe.Success, fmt.Sprintf("data of %s is in the %s tier and does not need rehydration",
+			id.String(), tier))
+		return task, nil
+	}
+	task.update(0, fmt.Sprintf("restoring %d segments of %s from %s", len(segments), id.String(), tier))
+	go this.rehydrate(taskCtx, task, id, segments)
+	return task, nil
+}
+
+func (this *ProtectedEntityTypeManager) rehydrate(ctx context.Context, task *rehydrationTask,
+	id astrolabe.ProtectedEntityID, segments []s3Segment) {
+	defer task.cancel()
+	options := this.rehydrationOptions
+	for _, segment := range segments {
+		_, err := this.s3.RestoreObjectWithContext(ctx, &s3.RestoreObjectInput{
+			Bucket: aws.String(this.bucket),
+			Key:    aws.String(segment.key),
+			RestoreRequest: &s3.RestoreRequest{
+				Days: aws.Int64(options.Days),
+				GlacierJobParameters: &s3.GlacierJobParameters{
+					Tier: aws.String(options.RetrievalTier),
+				},
+			},
+		})
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "RestoreAlreadyInProgress" {
+			err = nil
+		}
+		if err != nil {
+			this.finishRehydration(ctx, task, id, errors.Wrapf(err, "Failed to restore %s", segment.key))
+			return
+		}
+	}
+	this.logger.Infof("Requested restore of %d segments of %s", len(segments), id.String())
+	for {
+		restored, err := this.restoredSegments(ctx, segments)
+		if err != nil {
+			this.finishRehydration(ctx, task, id, err)
+			return
+		}
+		if restored == len(segments) {
+			this.finishRehydration(ctx, task, id, nil)
+			return
+		}
+		task.update(float64(restored)*100/float64(len(segments)),
+			fmt.Sprintf("restored %d of %d segments of %s", restored, len(segments), id.String()))
+		select {
+		case <-ctx.Done():
+			this.finishRehydration(ctx, task, id, ctx.Err())
+			return
+		case <-time.After(options.PollInterval):
+		}
+	}
+}
+
+func (this *ProtectedEntityTypeManager) finishRehydration(ctx context.Context, task *rehydrationTask,
+	id astrolabe.ProtectedEntityID, err error) {
+	switch {
+	case err == nil:
+		this.logger.Infof("Rehydrated snapshot %s", id.String())
+		task.finish(astrolabe.Success, fmt.Sprintf("rehydrated %s, data can be read for %d days", id.String(),
+			this.rehydrationOptions.Days))
+	case ctx.Err() != nil:
+		this.logger.Infof("Rehydration of snapshot %s was cancelled", id.String())
+		task.finish(astrolabe.Cancelled, fmt.Sprintf("rehydration of %s was cancelled", id.String()))
+	default:
+		this.logger.Errorf("Rehydration of snapshot %s failed, err: %v", id.String(), err)
+		task.finish(astrolabe.Failed, err.Error())
+	}
+}
+
+/*
+ * rehydrationTask reports the progress of a Rehydrate.  The task is updated by the rehydration goroutine so all access
+ * goes through the mutex.
+ */
+type rehydrationTask struct {
+	mutex  sync.RWMutex
+	task   astrolabe.GenericTask
+	cancel context.CancelFunc
+}
+
+func (this *rehydrationTask) update(progress float64, details string) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.task.Progress = progress
+	this.task.Details = details
+}
+
+func (this *rehydrationTask) finish(status astrolabe.TaskStatus, details string) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.task.TaskStatus = status
+	this.task.Details = details
+	if status == astrolabe.Success {
+		this.task.Progress = 100
+	}
+	this.task.Completed = true
+	this.task.FinishedTime = time.Now()
+}
+
+func (this *rehydrationTask) GetID() astrolabe.TaskID {
+	return this.task.ID
+}
+
+func (this *rehydrationTask) GetStatus() astrolabe.TaskStatus {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	return this.task.GetStatus()
+}
+
+func (this *rehydrationTask) GetDetails() string {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	return this.task.GetDetails()
+}
+
+func (this *rehydrationTask) GetFinishedTime() time.Time {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	return this.task.GetFinishedTime()
+}
+
+func (this *rehydrationTask) GetStartedTime() time.Time {
+	return this.task.GetStartedTime()
+}
+
+func (this *rehydrationTask) GetProgress() float64 {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	return this.task.GetProgress()
+}
+
+func (this *rehydrationTask) GetResult() interface{} {
+	return nil
+}
+
+func (this *rehydrationTask) GetModelTaskInfo() models.TaskInfo {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	return this.task.GetModelTaskInfo()
+}
+
+func (this *rehydrationTask) Cancel() error {
+	this.cancel()
+	return nil
+}
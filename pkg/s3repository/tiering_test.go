@@ -0,0 +1,201 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3repository
+
+import (
+	"bytes"
+	"context"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+func TestTieringAndRehydration(t *testing.T) {
+	fake := newFakeS3Server()
+	defer fake.Close()
+	petm := fake.newPETM(t, "ivd", "tier-bucket")
+	ctx := context.Background()
+	// Force the multipart copy used for segments too large for CopyObject
+	petm.maxCopyObjectSize = 3000
+	err := petm.SetRehydrationOptions(RehydrationOptions{
+		Days:          1,
+		RetrievalTier: s3.TierBulk,
+		PollInterval:  time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	repoPE := copyRetentionTestPE(t, petm, "snap1", nil)
+	tier, err := petm.GetStorageTier(ctx, repoPE.GetID())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, s3.StorageClassStandard, tier)
+
+	// Nothing is old enough yet
+	err = petm.SetTieringPolicies([]TieringPolicy{
+		{Age: time.Hour, StorageClass: s3.StorageClassGlacier},
+		{Age: time.Minute, StorageClass: s3.StorageClassStandardIa},
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	tiered, err := petm.ApplyTieringPolicies(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 0, tiered)
+
+	err = petm.SetTieringPolicies([]TieringPolicy{
+		{Age: 0, StorageClass: s3.StorageClassGlacier},
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	tiered, err = petm.ApplyTieringPolicies(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1, tiered)
+	// Already in the policy's class
+	tiered, err = petm.ApplyTieringPolicies(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 0, tiered)
+
+	dataClasses := fake.storageClasses("tier-bucket", petm.dataPrefix)
+	assert.Equal(t, 1, len(dataClasses))
+	for key, storageClass := range dataClasses {
+		assert.Equal(t, s3.StorageClassGlacier, storageClass, key)
+	}
+	for _, prefix := range []string{petm.peinfoPrefix, petm.mdPrefix} {
+		for key, storageClass := range fake.storageClasses("tier-bucket", prefix) {
+			assert.Equal(t, s3.StorageClassStandard, storageClass, key)
+		}
+	}
+	tier, err = petm.GetStorageTier(ctx, repoPE.GetID())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, s3.StorageClassGlacier, tier)
+
+	tieredPE, err := petm.GetProtectedEntity(ctx, repoPE.GetID())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, err = tieredPE.GetDataReader(ctx)
+	assert.Assert(t, astrolabe.IsRehydrationRequiredError(err), "expected rehydration required error, got %v", err)
+	// Metadata is never tiered
+	mdReader, err := tieredPE.GetMetadataReader(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	md, err := ioutil.ReadAll(mdReader)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "metadata", string(md))
+
+	task, err := petm.Rehydrate(ctx, repoPE.GetID())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for task.GetFinishedTime().IsZero() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	taskInfo := task.GetModelTaskInfo()
+	assert.Equal(t, astrolabe.Success.String(), *taskInfo.Status, taskInfo.Details)
+	assert.Equal(t, float64(100), task.GetProgress())
+
+	dataReader, err := tieredPE.GetDataReader(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	data, err := ioutil.ReadAll(dataReader)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, bytes.Equal(bytes.Repeat([]byte("astrolabe"), 1000), data))
+}
+
+func TestRehydrateStandardTier(t *testing.T) {
+	fake := newFakeS3Server()
+	defer fake.Close()
+	petm := fake.newPETM(t, "ivd", "tier-bucket")
+	ctx := context.Background()
+
+	repoPE := copyRetentionTestPE(t, petm, "snap1", nil)
+	task, err := petm.Rehydrate(ctx, repoPE.GetID())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, !task.GetFinishedTime().IsZero(), "rehydration of standard data should complete immediately")
+	assert.Equal(t, astrolabe.Success.String(), *task.GetModelTaskInfo().Status)
+
+	err = petm.SetTieringPolicies([]TieringPolicy{
+		{Age: time.Hour, StorageClass: "COLD"},
+	})
+	assert.Assert(t, err != nil, "unknown storage class should fail")
+}
+
+func TestTieringFromConfig(t *testing.T) {
+	fake := newFakeS3Server()
+	defer fake.Close()
+	ctx := context.Background()
+	// Created before the background work starts, creating a session races with requests in flight
+	sess := fake.session(t)
+	petm, err := NewS3RepositoryProtectedEntityTypeManagerFromConfig("ivd", sess, "tier-bucket", "repo",
+		map[string]interface{}{
+			TieringPoliciesParam: []interface{}{
+				map[string]interface{}{"age": "0s", "storageClass": s3.StorageClassStandardIa},
+				map[string]interface{}{"age": "720h", "storageClass": s3.StorageClassGlacier},
+			},
+			TierIntervalParam: "10ms",
+		}, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer petm.Close()
+
+	// The tierer moves the snapshot without being called
+	repoPE := copyRetentionTestPE(t, petm, "snap1", nil)
+	tier := s3.StorageClassStandard
+	deadline := time.Now().Add(10 * time.Second)
+	for tier == s3.StorageClassStandard && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		tier, err = petm.GetStorageTier(ctx, repoPE.GetID())
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+	}
+	assert.Equal(t, s3.StorageClassStandardIa, tier)
+
+	_, err = NewS3RepositoryProtectedEntityTypeManagerFromConfig("ivd", sess, "tier-bucket", "repo",
+		map[string]interface{}{
+			TieringPoliciesParam: []interface{}{
+				map[string]interface{}{"age": "720h", "storageClass": "COLD"},
+			},
+		}, nil)
+	assert.Assert(t, err != nil, "unknown storage class should be rejected")
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+ * Usage is computed from the bucket listings only, no objects are read.  Each listing page is folded into the totals
+ * as it arrives so the full listing is never held in memory.
+ *
+ * Stored bytes are the sizes of all of the objects for a snapshot (peinfo, md and data segments).  Logical bytes are
+ * the lengths of the md and data streams, taken from the end offset of the last segment of each stream.  The
+ * repository does not currently dedup or compress so the reduction ratio reflects the peinfo and segment overhead.
+ */
+
+type snapshotUsageAccumulator struct {
+	usage          astrolabe.UsageStats
+	mdEnd, dataEnd int64
+}
+
+type usageAccumulator struct {
+	snapshots map[string]*snapshotUsageAccumulator
+}
+
+func newUsageAccumulator() usageAccumulator {
+	return usageAccumulator{
+		snapshots: map[string]*snapshotUsageAccumulator{},
+	}
+}
+
+func (this usageAccumulator) snapshot(idStr string) *snapshotUsageAccumulator {
+	snapshotUsage, ok := this.snapshots[idStr]
+	if !ok {
+		snapshotUsage = &snapshotUsageAccumulator{}
+		this.snapshots[idStr] = snapshotUsage
+	}
+	return snapshotUsage
+}
+
+func (this *ProtectedEntityTypeManager) addObjectUsage(accumulator usageAccumulator, key string, size int64) {
+	if strings.HasPrefix(key, this.peinfoPrefix) {
+		snapshotUsage := accumulator.snapshot(strings.TrimPrefix(key, this.peinfoPrefix))
+		snapshotUsage.usage.StoredBytes += size
+		return
+	}
+	var componentPrefix, componentSuffix string
+	if strings.HasPrefix(key, this.mdPrefix) {
+		componentPrefix, componentSuffix = this.mdPrefix, MD_SUFFIX
+	} else if strings.HasPrefix(key, this.dataPrefix) {
+		componentPrefix, componentSuffix = this.dataPrefix, DATA_SUFFIX
+	} else {
+		this.logger.Debugf("Skipping unrecognized key %s in usage", key)
+		return
+	}
+	// Streams are stored as <name>/<segment>, older repositories may have a single object with no segments
+	baseName, startOffset := key, int64(0)
+	if !strings.HasSuffix(key, componentSuffix) {
+		baseName, _, startOffset = parseSegmentName(key)
+	}
+	idStr := strings.TrimPrefix(baseName, componentPrefix)
+	if !strings.HasSuffix(idStr, componentSuffix) {
+		this.logger.Debugf("Skipping key %s with unexpected suffix in usage", key)
+		return
+	}
+	snapshotUsage := accumulator.snapshot(strings.TrimSuffix(idStr, componentSuffix))
+	snapshotUsage.usage.StoredBytes += size
+	snapshotUsage.usage.SegmentCount++
+	end := startOffset + size
+	if componentSuffix == MD_SUFFIX {
+		if end > snapshotUsage.mdEnd {
+			snapshotUsage.mdEnd = end
+		}
+	} else {
+		if end > snapshotUsage.dataEnd {
+			snapshotUsage.dataEnd = end
+		}
+	}
+}
+
+func (this usageAccumulator) serviceUsage(typeName string) astrolabe.ServiceUsage {
+	serviceUsage := astrolabe.ServiceUsage{
+		Service:           typeName,
+		ProtectedEntities: []astrolabe.ProtectedEntityUsage{},
+	}
+	peUsages := map[string]*astrolabe.ProtectedEntityUsage{}
+	for idStr, snapshotUsage := range this.snapshots {
+		snapshotID, err := astrolabe.NewProtectedEntityIDFromString(idStr)
+		if err != nil {
+			continue
+		}
+		usage := snapshotUsage.usage
+		usage.LogicalBytes = snapshotUsage.mdEnd + snapshotUsage.dataEnd
+		baseID := astrolabe.NewProtectedEntityID(snapshotID.GetPeType(), snapshotID.GetID())
+		peUsage, ok := peUsages[baseID.String()]
+		if !ok {
+			peUsage = &astrolabe.ProtectedEntityUsage{
+				ID:        baseID,
+				Snapshots: []astrolabe.SnapshotUsage{},
+			}
+			peUsages[baseID.String()] = peUsage
+		}
+		peUsage.Usage.Add(usage)
+		peUsage.Snapshots = append(peUsage.Snapshots, astrolabe.SnapshotUsage{
+			ID:    snapshotID,
+			Usage: usage,
+		})
+		serviceUsage.Usage.Add(usage)
+	}
+	for _, peUsage := range peUsages {
+		sort.Slice(peUsage.Snapshots, func(i, j int) bool {
+			return peUsage.Snapshots[i].ID.String() < peUsage.Snapshots[j].ID.String()
+		})
+		serviceUsage.ProtectedEntities = append(serviceUsage.ProtectedEntities, *peUsage)
+	}
+	sort.Slice(serviceUsage.ProtectedEntities, func(i, j int) bool {
+		return serviceUsage.ProtectedEntities[i].ID.String() < serviceUsage.ProtectedEntities[j].ID.String()
+	})
+	return serviceUsage
+}
+
+/*
+ * GetUsage reports the logical and stored bytes for the protected entities in this repository, totalled per
+ * snapshot, per protected entity and for the type.  If idPrefix is set only IDs starting with the prefix are listed.
+ */
+func (this *ProtectedEntityTypeManager) GetUsage(ctx context.Context, idPrefix string) (astrolabe.ServiceUsage, error) {
+	accumulator := newUsageAccumulator()
+	for _, componentPrefix := range []string{this.peinfoPrefix, this.mdPrefix, this.dataPrefix} {
+		listPrefix := componentPrefix + idPrefix
+		err := this.s3.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+			Bucket: aws.String(this.bucket),
+			Prefix: aws.String(listPrefix),
+		}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, object := range output.Contents {
+				this.addObjectUsage(accumulator, aws.StringValue(object.Key), aws.Int64Value(object.Size))
+			}
+			return true
+		})
+		if err != nil {
+			return astrolabe.ServiceUsage{}, errors.Wrapf(err, "Failed to list bucket %s, prefix %s", this.bucket, listPrefix)
+		}
+	}
+	return accumulator.serviceUsage(this.typeName), nil
+}
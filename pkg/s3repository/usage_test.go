@@ -0,0 +1,119 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3repository
+
+import (
+	"bytes"
+	"context"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"testing"
+
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+func TestGetUsage(t *testing.T) {
+	fake := newFakeS3Server()
+	defer fake.Close()
+	petm := fake.newPETM(t, "ivd", "usage-bucket")
+
+	fake.putObject("usage-bucket", "repo/ivd/peinfo/ivd:disk1:snap1", make([]byte, 100))
+	fake.putObject("usage-bucket", "repo/ivd/md/ivd:disk1:snap1.md/000000-0000000000000000", make([]byte, 50))
+	fake.putObject("usage-bucket", "repo/ivd/data/ivd:disk1:snap1.data/000000-0000000000000000", make([]byte, 1000))
+	fake.putObject("usage-bucket", "repo/ivd/data/ivd:disk1:snap1.data/000001-0000000000001000", make([]byte, 500))
+	fake.putObject("usage-bucket", "repo/ivd/peinfo/ivd:disk1:snap2", make([]byte, 100))
+	fake.putObject("usage-bucket", "repo/ivd/data/ivd:disk1:snap2.data/000000-0000000000000000", make([]byte, 200))
+	fake.putObject("usage-bucket", "repo/ivd/peinfo/ivd:disk2:snap1", make([]byte, 100))
+	fake.putObject("usage-bucket", "repo/ivd/data/ivd:disk2:snap1.data/000000-0000000000000000", make([]byte, 300))
+	// Other types in the same bucket are not counted
+	fake.putObject("usage-bucket", "repo/fs/peinfo/fs:dir1:snap1", make([]byte, 100))
+
+	usage, err := petm.GetUsage(context.Background(), "")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "ivd", usage.Service)
+	assert.Equal(t, int64(50+1000+500+200+300), usage.Usage.LogicalBytes)
+	assert.Equal(t, int64(300+50+1000+500+200+300), usage.Usage.StoredBytes)
+	assert.Equal(t, int64(5), usage.Usage.SegmentCount)
+	assert.Equal(t, 2, len(usage.ProtectedEntities))
+
+	disk1 := usage.ProtectedEntities[0]
+	assert.Equal(t, "ivd:disk1", disk1.ID.String())
+	assert.Equal(t, int64(1750), disk1.Usage.LogicalBytes)
+	assert.Equal(t, int64(1950), disk1.Usage.StoredBytes)
+	assert.Equal(t, 2, len(disk1.Snapshots))
+	assert.Equal(t, "ivd:disk1:snap1", disk1.Snapshots[0].ID.String())
+	assert.Equal(t, int64(1550), disk1.Snapshots[0].Usage.LogicalBytes)
+	assert.Equal(t, int64(1650), disk1.Snapshots[0].Usage.StoredBytes)
+	assert.Equal(t, int64(3), disk1.Snapshots[0].Usage.SegmentCount)
+
+	disk2Usage, err := petm.GetUsage(context.Background(), "ivd:disk2")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1, len(disk2Usage.ProtectedEntities))
+	assert.Equal(t, int64(300), disk2Usage.Usage.LogicalBytes)
+	assert.Equal(t, int64(400), disk2Usage.Usage.StoredBytes)
+	assert.Equal(t, float64(300)/float64(400), disk2Usage.Usage.ReductionRatio())
+}
+
+func TestCopyAndGetUsage(t *testing.T) {
+	fake := newFakeS3Server()
+	defer fake.Close()
+	petm := fake.newPETM(t, "ivd", "usage-bucket")
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("astrolabe"), 1000)
+	md := []byte("metadata")
+	peID := astrolabe.NewProtectedEntityIDWithSnapshotID("ivd", "disk1", astrolabe.NewProtectedEntitySnapshotID("snap1"))
+	sourcePE := newMemoryProtectedEntity(peID, data, md)
+	params := map[string]map[string]interface{}{
+		astrolabe.LabelsParamKey: {"app": "nginx"},
+	}
+	repoPE, err := petm.Copy(ctx, sourcePE, params, astrolabe.AllocateNewObject)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	retrievedPE, err := petm.GetProtectedEntity(ctx, repoPE.GetID())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	retrievedInfo, err := retrievedPE.GetInfo(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "nginx", retrievedInfo.GetLabels()["app"])
+	dataReader, err := retrievedPE.GetDataReader(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	readData, err := ioutil.ReadAll(dataReader)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, bytes.Equal(data, readData), "data read from repository does not match")
+
+	usage, err := petm.GetUsage(ctx, "")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, int64(len(data)+len(md)), usage.Usage.LogicalBytes)
+	assert.Equal(t, int64(2), usage.Usage.SegmentCount)
+	assert.Assert(t, usage.Usage.StoredBytes > usage.Usage.LogicalBytes, "stored bytes should include peinfo")
+}
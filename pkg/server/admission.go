@@ -0,0 +1,591 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+const admissionConfigFile = "admission.json"
+
+/*
+AdmissionPriority orders the operations waiting for a service, operations with a higher priority are admitted first
+*/
+type AdmissionPriority int
+
+const (
+	// Snapshots and snapshot deletes
+	BackupPriority AdmissionPriority = 1
+	// Copies into a service
+	RestorePriority AdmissionPriority = 2
+)
+
+func (this AdmissionPriority) String() string {
+	switch this {
+	case BackupPriority:
+		return "backup"
+	case RestorePriority:
+		return "restore"
+	}
+	return "unknown"
+}
+
+/*
+ServiceLimits limits the operations that run at the same time on a service.  0 is unlimited.
+*/
+type ServiceLimits struct {
+	// Limits the snapshot and copy operations on the service and, separately, the data streams read from it
+	MaxConcurrent int `json:"maxConcurrent,omitempty"`
+	// Limits the snapshot operations on each datastore of services that implement astrolabe.DatastoreLocator
+	MaxPerDatastore int `json:"maxPerDatastore,omitempty"`
+}
+
+type AdmissionConfig struct {
+	// The limits of each service by service name.  A named instance without an entry, e.g. ivd@vc-east, uses the
+	// entry of its type, ivd.
+	Services map[string]ServiceLimits `json:"services,omitempty"`
+	// The limits of services without an entry
+	Default ServiceLimits `json:"default,omitempty"`
+}
+
+func (this AdmissionConfig) limits(service string) ServiceLimits {
+	if limits, ok := this.Services[service]; ok {
+		return limits
+	}
+	peType, _ := astrolabe.SplitServiceName(service)
+	if limits, ok := this.Services[peType]; ok {
+		return limits
+	}
+	return this.Default
+}
+
+/*
+AdmissionController limits the snapshot and copy operations that run at the same time on each service and each
+datastore.  Operations that are over a limit wait in a queue.  The waiting operation with the highest priority whose
+limits allow it runs first, so restores run before backups.  Within a priority, the callers take turns so that a caller
+that starts many operations does not hold up the others: the operations of the caller with the fewest running
+operations and then of the caller whose last operation was admitted longest ago run first, and then in the order they
+arrived.  An operation that waits for a busy service does not hold up operations on other
+services.
+*/
+type AdmissionController struct {
+	config AdmissionConfig
+	mutex  sync.Mutex
+	// Running operations by resource, service/<service> or datastore/<service>/<datastore>
+	running map[string]int
+	// Running operations by caller
+	callerRunning map[string]int
+	// The admission number of the last operation admitted for each caller
+	callerLastAdmitted map[string]uint64
+	admissions         uint64
+	waiting            []*admissionRequest
+	nextSeq            uint64
+	logger             logrus.FieldLogger
+}
+
+type admissionResource struct {
+	key   string
+	limit int
+}
+
+type admissionRequest struct {
+	resources []admissionResource
+	priority  AdmissionPriority
+	caller    string
+	seq       uint64
+	// Closed when the request is admitted
+	admitted       chan struct{}
+	reportPosition func(position int)
+}
+
+/*
+NewAdmissionControllerFromConfigDir reads admission.json from confDirPath.  If there is no admission.json, nil is
+returned and operations are not limited.
+*/
+func NewAdmissionControllerFromConfigDir(confDirPath string, logger logrus.FieldLogger) (*AdmissionController, error) {
+	configPath := filepath.Join(confDirPath, admissionConfigFile)
+	configBytes, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "could not read %s", configPath)
+	}
+	config := AdmissionConfig{}
+	err = json.Unmarshal(configBytes, &config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse %s", configPath)
+	}
+	return NewAdmissionController(config, logger)
+}
+
+func NewAdmissionController(config AdmissionConfig, logger logrus.FieldLogger) (*AdmissionController, error) {
+	checkLimits := func(name string, limits ServiceLimits) error {
+		if limits.MaxConcurrent < 0 || limits.MaxPerDatastore < 0 {
+			return errors.Errorf("limits of %s cannot be negative", name)
+		}
+		return nil
+	}
+	if err := checkLimits("default", config.Default); err != nil {
+		return nil, err
+	}
+	for service, limits := range config.Services {
+		if err := checkLimits(service, limits); err != nil {
+			return nil, err
+		}
+	}
+	return &AdmissionController{
+		config:             config,
+		running:            map[string]int{},
+		callerRunning:      map[string]int{},
+		callerLastAdmitted: map[string]uint64{},
+		logger:             logger,
+	}, nil
+}
+
+/*
+Admit waits until an operation on service, and on datastore if it is not empty, can run.  The returned release function
+must be called when the operation finishes.  If ctx is cancelled while the operation waits, the operation leaves the
+queue and the error is returned.  The caller is the identity in ctx.  While the operation waits, its position in the
+queue is reported to the reporter in ctx, see withQueuePositionReporter.
+*/
+func (this *AdmissionController) Admit(ctx context.Context, service string, datastore string,
+	priority AdmissionPriority) (release func(), err error) {
+	limits := this.config.limits(service)
+	var resources []admissionResource
+	if limits.MaxConcurrent > 0 {
+		resources = append(resources, admissionResource{
+			key:   "service/" + service,
+			limit: limits.MaxConcurrent,
+		})
+	}
+	if datastore != "" && limits.MaxPerDatastore > 0 {
+		resources = append(resources, admissionResource{
+			key:   "datastore/" + service + "/" + datastore,
+			limit: limits.MaxPerDatastore,
+		})
+	}
+	return this.wait(ctx, service, resources, priority)
+}
+
+/*
+AdmitRead waits until a data stream of service can be read, see Admit.  Reads count against the maxConcurrent of the
+service separately from its operations.  A copy reads its source while it holds its own admission, and a read only
+waits for other reads, so a copy that reads from the service it copies into does not wait for itself.
+*/
+func (this *AdmissionController) AdmitRead(ctx context.Context, service string) (release func(), err error) {
+	limits := this.config.limits(service)
+	var resources []admissionResource
+	if limits.MaxConcurrent > 0 {
+		resources = append(resources, admissionResource{
+			key:   "read/" + service,
+			limit: limits.MaxConcurrent,
+		})
+	}
+	return this.wait(ctx, service, resources, BackupPriority)
+}
+
+/*
+wait queues an operation on service that needs resources and waits until it is admitted
+*/
+func (this *AdmissionController) wait(ctx context.Context, service string, resources []admissionResource,
+	priority AdmissionPriority) (release func(), err error) {
+	if len(resources) == 0 {
+		return func() {}, nil
+	}
+	request := &admissionRequest{
+		resources:      resources,
+		priority:       priority,
+		caller:         admissionCaller(ctx),
+		admitted:       make(chan struct{}),
+		reportPosition: queuePositionReporter(ctx),
+	}
+	this.mutex.Lock()
+	request.seq = this.nextSeq
+	this.nextSeq++
+	this.waiting = append(this.waiting, request)
+	this.schedule()
+	this.mutex.Unlock()
+
+	var releaseOnce sync.Once
+	release = func() {
+		releaseOnce.Do(func() {
+			this.release(request)
+		})
+	}
+	select {
+	case <-request.admitted:
+		return release, nil
+	case <-ctx.Done():
+	}
+	this.mutex.Lock()
+	select {
+	case <-request.admitted:
+		// Admitted while ctx was cancelled
+		this.mutex.Unlock()
+		release()
+	default:
+		this.remove(request)
+		this.schedule()
+		this.mutex.Unlock()
+	}
+	return nil, errors.Wrapf(ctx.Err(), "cancelled while waiting for %s", service)
+}
+
+/*
+QueueLength returns the number of operations waiting
+*/
+func (this *AdmissionController) QueueLength() int {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return len(this.waiting)
+}
+
+func (this *AdmissionController) release(request *admissionRequest) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	for _, resource := range request.resources {
+		this.running[resource.key]--
+		if this.running[resource.key] == 0 {
+			delete(this.running, resource.key)
+		}
+	}
+	this.callerRunning[request.caller]--
+	if this.callerRunning[request.caller] == 0 {
+		delete(this.callerRunning, request.caller)
+	}
+	this.schedule()
+}
+
+// Must be called with the mutex held
+func (this *AdmissionController) remove(request *admissionRequest) {
+	for i, waiting := range this.waiting {
+		if waiting == request {
+			this.waiting = append(this.waiting[:i], this.waiting[i+1:]...)
+			return
+		}
+	}
+}
+
+/*
+schedule admits the waiting requests that fit within the limits, in queue order, and then reports the positions of the
+requests that are still waiting.  Must be called with the mutex held.
+*/
+func (this *AdmissionController) schedule() {
+	for {
+		// The order changes as requests are admitted because it depends on the running operations of each caller
+		this.sortWaiting()
+		admitted := false
+		for _, request := range this.waiting {
+			if this.fits(request) {
+				this.admit(request)
+				admitted = true
+				break
+			}
+		}
+		if !admitted {
+			break
+		}
+	}
+	for i, request := range this.waiting {
+		// The position counts the requests ahead that wait for one of the same resources
+		position := 1
+		for _, ahead := range this.waiting[:i] {
+			if sharesResource(ahead, request) {
+				position++
+			}
+		}
+		if request.reportPosition != nil {
+			request.reportPosition(position)
+		}
+	}
+}
+
+// Must be called with the mutex held
+func (this *AdmissionController) sortWaiting() {
+	sort.SliceStable(this.waiting, func(i, j int) bool {
+		first, second := this.waiting[i], this.waiting[j]
+		if first.priority != second.priority {
+			return first.priority > second.priority
+		}
+		firstRunning, secondRunning := this.callerRunning[first.caller], this.callerRunning[second.caller]
+		if firstRunning != secondRunning {
+			return firstRunning < secondRunning
+		}
+		firstLast, secondLast := this.callerLastAdmitted[first.caller], this.callerLastAdmitted[second.caller]
+		if firstLast != secondLast {
+			return firstLast < secondLast
+		}
+		return first.seq < second.seq
+	})
+}
+
+// Must be called with the mutex held
+func (this *AdmissionController) fits(request *admissionRequest) bool {
+	for _, resource := range request.resources {
+		if this.running[resource.key] >= resource.limit {
+			return false
+		}
+	}
+	return true
+}
+
+// Must be called with the mutex held
+func (this *AdmissionController) admit(request *admissionRequest) {
+	for _, resource := range request.resources {
+		this.running[resource.key]++
+	}
+	this.callerRunning[request.caller]++
+	this.admissions++
+	this.callerLastAdmitted[request.caller] = this.admissions
+	this.remove(request)
+	if request.reportPosition != nil {
+		request.reportPosition(0)
+	}
+	this.logger.Debugf("Admitted %s operation of %q on %v", request.priority.String(), request.caller,
+		request.resources)
+	close(request.admitted)
+}
+
+func sharesResource(first *admissionRequest, second *admissionRequest) bool {
+	for _, firstResource := range first.resources {
+		for _, secondResource := range second.resources {
+			if firstResource.key == secondResource.key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+admissionCaller returns the name of the identity in ctx, the empty string for unauthenticated requests
+*/
+func admissionCaller(ctx context.Context) string {
+	identity := IdentityFromContext(ctx)
+	if identity == nil {
+		return ""
+	}
+	return identity.Name
+}
+
+type queuePositionContextKey struct{}
+
+/*
+withQueuePositionReporter returns a context that passes the queue position of an operation waiting for admission to
+report, 0 once the operation is admitted.  report is called with the admission lock held and must not block.
+*/
+func withQueuePositionReporter(ctx context.Context, report func(position int)) context.Context {
+	return context.WithValue(ctx, queuePositionContextKey{}, report)
+}
+
+func queuePositionReporter(ctx context.Context) func(position int) {
+	report, _ := ctx.Value(queuePositionContextKey{}).(func(position int))
+	return report
+}
+
+/*
+admissionProtectedEntityTypeManager decorates a type manager so that copies wait for admission.  The protected
+entities it returns are decorated so that snapshots and snapshot deletes wait for admission.
+*/
+type admissionProtectedEntityTypeManager struct {
+	astrolabe.ProtectedEntityTypeManager
+	admission *AdmissionController
+}
+
+func newAdmissionProtectedEntityTypeManager(petm astrolabe.ProtectedEntityTypeManager,
+	admission *AdmissionController) astrolabe.ProtectedEntityTypeManager {
+	return &admissionProtectedEntityTypeManager{
+		ProtectedEntityTypeManager: petm,
+		admission:                  admission,
+	}
+}
+
+func (this *admissionProtectedEntityTypeManager) Unwrap() astrolabe.ProtectedEntityTypeManager {
+	return this.ProtectedEntityTypeManager
+}
+
+func (this *admissionProtectedEntityTypeManager) GetProtectedEntity(ctx context.Context,
+	id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
+	pe, err := this.ProtectedEntityTypeManager.GetProtectedEntity(ctx, id)
+	return this.wrap(pe), err
+}
+
+func (this *admissionProtectedEntityTypeManager) Copy(ctx context.Context, pe astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	release, err := this.admission.Admit(ctx, this.GetTypeName(), "", RestorePriority)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	newPE, err := this.ProtectedEntityTypeManager.Copy(ctx, pe, params, options)
+	return this.wrap(newPE), err
+}
+
+func (this *admissionProtectedEntityTypeManager) CopyFromInfo(ctx context.Context, info astrolabe.ProtectedEntityInfo,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	release, err := this.admission.Admit(ctx, this.GetTypeName(), "", RestorePriority)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	newPE, err := this.ProtectedEntityTypeManager.CopyFromInfo(ctx, info, params, options)
+	return this.wrap(newPE), err
+}
+
+func (this *admissionProtectedEntityTypeManager) wrap(pe astrolabe.ProtectedEntity) astrolabe.ProtectedEntity {
+	if pe == nil {
+		return nil
+	}
+	return &admissionProtectedEntity{
+		ProtectedEntity: pe,
+		petm:            this,
+	}
+}
+
+/*
+datastore returns the datastore of id if the service limits its datastores, the empty string if it does not or the
+datastore cannot be found
+*/
+func (this *admissionProtectedEntityTypeManager) datastore(ctx context.Context, id astrolabe.ProtectedEntityID) string {
+	if this.admission.config.limits(this.GetTypeName()).MaxPerDatastore == 0 {
+		return ""
+	}
+	locator, ok := astrolabe.UnwrapProtectedEntityTypeManager(this).(astrolabe.DatastoreLocator)
+	if !ok {
+		return ""
+	}
+	datastore, err := locator.GetDatastore(ctx, id)
+	if err != nil {
+		// The service limit still applies
+		this.admission.logger.WithError(err).Warnf("Could not find the datastore of %s", id.String())
+		return ""
+	}
+	return datastore
+}
+
+/*
+admissionProtectedEntity waits for admission before the snapshot operations of a protected entity returned by a type
+manager.  Its data readers wait for admission on their first read and hold it until they are closed, so opening a
+reader only to find the size of the stream is not limited.
+*/
+type admissionProtectedEntity struct {
+	astrolabe.ProtectedEntity
+	petm *admissionProtectedEntityTypeManager
+}
+
+func (this *admissionProtectedEntity) admit(ctx context.Context) (func(), error) {
+	return this.petm.admission.Admit(ctx, this.petm.GetTypeName(), this.petm.datastore(ctx, this.GetID()),
+		BackupPriority)
+}
+
+func (this *admissionProtectedEntity) Snapshot(ctx context.Context,
+	params map[string]map[string]interface{}) (astrolabe.ProtectedEntitySnapshotID, error) {
+	release, err := this.admit(ctx)
+	if err != nil {
+		return astrolabe.ProtectedEntitySnapshotID{}, err
+	}
+	defer release()
+	return this.ProtectedEntity.Snapshot(ctx, params)
+}
+
+func (this *admissionProtectedEntity) DeleteSnapshot(ctx context.Context,
+	snapshotToDelete astrolabe.ProtectedEntitySnapshotID, params map[string]map[string]interface{}) (bool, error) {
+	release, err := this.admit(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+	return this.ProtectedEntity.DeleteSnapshot(ctx, snapshotToDelete, params)
+}
+
+func (this *admissionProtectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	reader, err := this.ProtectedEntity.GetDataReader(ctx)
+	if reader == nil || err != nil {
+		return reader, err
+	}
+	admittedReader := &admittedReadCloser{
+		ReadCloser: reader,
+		admit: func() (func(), error) {
+			return this.petm.admission.AdmitRead(ctx, this.petm.GetTypeName())
+		},
+	}
+	// Keep the reader seekable and sized so that ranges and sizes can still be served
+	if seeker, ok := reader.(io.Seeker); ok {
+		return &admittedReadSeekCloser{
+			admittedReadCloser: admittedReader,
+			Seeker:             seeker,
+		}, nil
+	}
+	if sized, ok := reader.(astrolabe.SizedReader); ok {
+		return astrolabe.NewSizedReadCloser(admittedReader, sized.Size()), nil
+	}
+	return admittedReader, nil
+}
+
+/*
+admittedReadCloser waits for admission on its first read and releases it when it is closed
+*/
+type admittedReadCloser struct {
+	io.ReadCloser
+	admit    func() (func(), error)
+	mutex    sync.Mutex
+	admitted bool
+	release  func()
+}
+
+func (this *admittedReadCloser) Read(p []byte) (int, error) {
+	this.mutex.Lock()
+	if !this.admitted {
+		release, err := this.admit()
+		if err != nil {
+			this.mutex.Unlock()
+			return 0, err
+		}
+		this.admitted = true
+		this.release = release
+	}
+	this.mutex.Unlock()
+	return this.ReadCloser.Read(p)
+}
+
+func (this *admittedReadCloser) Close() error {
+	err := this.ReadCloser.Close()
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.release != nil {
+		this.release()
+		this.release = nil
+	}
+	return err
+}
+
+type admittedReadSeekCloser struct {
+	*admittedReadCloser
+	io.Seeker
+}
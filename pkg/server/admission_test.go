@@ -0,0 +1,293 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+	"github.com/vmware-tanzu/astrolabe/gen/restapi/operations"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+func callerContext(name string) context.Context {
+	return context.WithValue(context.Background(), authContextKey{}, authResult{
+		identity: &Identity{Name: name},
+	})
+}
+
+/*
+Waits until the admission controller has length operations waiting
+*/
+func waitForQueueLength(t *testing.T, admission *AdmissionController, length int) {
+	deadline := time.Now().Add(10 * time.Second)
+	for admission.QueueLength() != length {
+		if time.Now().After(deadline) {
+			t.Fatalf("Queue length is %d, expected %d", admission.QueueLength(), length)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAdmissionOrder(t *testing.T) {
+	admission, err := NewAdmissionController(AdmissionConfig{
+		Services: map[string]ServiceLimits{
+			"ivd": {MaxConcurrent: 1},
+		},
+	}, logrus.New())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	release, err := admission.Admit(callerContext("alice"), "ivd", "", BackupPriority)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	// Each operation reports its name once admitted and runs until it is released
+	type admitted struct {
+		name    string
+		release func()
+	}
+	admittedCh := make(chan admitted)
+	queue := func(name string, caller string, priority AdmissionPriority) {
+		go func() {
+			release, err := admission.Admit(callerContext(caller), "ivd", "", priority)
+			if err != nil {
+				panic(err)
+			}
+			admittedCh <- admitted{name: name, release: release}
+		}()
+	}
+	queue("alice-backup-1", "alice", BackupPriority)
+	waitForQueueLength(t, admission, 1)
+	queue("alice-backup-2", "alice", BackupPriority)
+	waitForQueueLength(t, admission, 2)
+	queue("bob-backup", "bob", BackupPriority)
+	waitForQueueLength(t, admission, 3)
+	queue("carol-restore", "carol", RestorePriority)
+	waitForQueueLength(t, admission, 4)
+
+	// The restore goes first, then bob because alice had the last turn
+	release()
+	order := []string{}
+	for len(order) < 4 {
+		next := <-admittedCh
+		order = append(order, next.name)
+		next.release()
+	}
+	assert.DeepEqual(t, []string{"carol-restore", "bob-backup", "alice-backup-1", "alice-backup-2"}, order)
+	assert.Equal(t, 0, admission.QueueLength())
+}
+
+func TestAdmissionLimits(t *testing.T) {
+	admission, err := NewAdmissionController(AdmissionConfig{
+		Services: map[string]ServiceLimits{
+			"ivd": {MaxConcurrent: 2, MaxPerDatastore: 1},
+		},
+	}, logrus.New())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	ctx := context.Background()
+	// Named instances use the limits of their type and services without limits are not queued
+	release1, err := admission.Admit(ctx, "ivd@vc-east", "ds-1", BackupPriority)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer release1()
+	for i := 0; i < 3; i++ {
+		release, err := admission.Admit(ctx, "fs", "", RestorePriority)
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		defer release()
+	}
+
+	// ds-1 is busy, the operation waits until it is cancelled
+	cancelCtx, cancel := context.WithCancel(ctx)
+	waitErr := make(chan error)
+	go func() {
+		_, err := admission.Admit(cancelCtx, "ivd@vc-east", "ds-1", BackupPriority)
+		waitErr <- err
+	}()
+	waitForQueueLength(t, admission, 1)
+
+	// ds-2 is free and the service has room for a second operation
+	release2, err := admission.Admit(ctx, "ivd@vc-east", "ds-2", BackupPriority)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer release2()
+
+	cancel()
+	err = <-waitErr
+	assert.Assert(t, err != nil, "Admit did not fail when cancelled")
+	assert.Equal(t, 0, admission.QueueLength())
+
+	_, err = NewAdmissionController(AdmissionConfig{
+		Default: ServiceLimits{MaxConcurrent: -1},
+	}, logrus.New())
+	assert.Assert(t, err != nil, "Negative limits were accepted")
+}
+
+/*
+blockingProtectedEntityTypeManager signals started when a copy starts and holds it until proceed is closed
+*/
+type blockingProtectedEntityTypeManager struct {
+	*memoryProtectedEntityTypeManager
+	started chan struct{}
+	proceed chan struct{}
+}
+
+func (this *blockingProtectedEntityTypeManager) CopyFromInfo(ctx context.Context, info astrolabe.ProtectedEntityInfo,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	this.started <- struct{}{}
+	<-this.proceed
+	return this.memoryProtectedEntityTypeManager.CopyFromInfo(ctx, info, params, options)
+}
+
+func TestAdmissionQueuePosition(t *testing.T) {
+	petm := &blockingProtectedEntityTypeManager{
+		memoryProtectedEntityTypeManager: newMemoryProtectedEntityTypeManager("mem"),
+		started:                          make(chan struct{}, 3),
+		proceed:                          make(chan struct{}),
+	}
+	petm.addEntity("a", nil)
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	admission, err := NewAdmissionController(AdmissionConfig{
+		Default: ServiceLimits{MaxConcurrent: 1},
+	}, logrus.New())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	pem.SetAdmissionController(admission)
+	handler := NewOpenAPIAstrolabeHandler(pem, NewTaskManager())
+
+	pe, err := petm.GetProtectedEntity(context.Background(), astrolabe.NewProtectedEntityID("mem", "a"))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	info, err := pe.GetInfo(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	mpeInfo := info.GetModelProtectedEntityInfo()
+	taskIDs := []models.TaskID{}
+	for i := 0; i < 3; i++ {
+		resp := handler.CopyProtectedEntity(operations.CopyProtectedEntityParams{
+			Service: "mem",
+			Mode:    "create_new",
+			Body: &models.CopyParameters{
+				ProtectedEntityInfo: &mpeInfo,
+			},
+		})
+		taskIDs = append(taskIDs, resp.(*operations.CopyProtectedEntityAccepted).Payload.TaskID)
+		// The first copy runs and the others queue in order
+		if i == 0 {
+			<-petm.started
+		} else {
+			waitForQueueLength(t, admission, i)
+		}
+	}
+	positions := []int64{}
+	for _, taskID := range taskIDs {
+		resp := handler.GetTaskInfo(operations.GetTaskInfoParams{TaskID: string(taskID)})
+		positions = append(positions, resp.(*operations.GetTaskInfoOK).Payload.QueuePosition)
+	}
+	assert.DeepEqual(t, []int64{0, 1, 2}, positions)
+
+	// Snapshots are limited too and wait behind the copies
+	snapshotCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	wrappedPE, err := pem.GetProtectedEntity(snapshotCtx, astrolabe.NewProtectedEntityID("mem", "a"))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, err = wrappedPE.Snapshot(snapshotCtx, nil)
+	assert.Assert(t, err != nil, "Snapshot was not queued")
+
+	close(petm.proceed)
+	for _, taskID := range taskIDs {
+		taskInfo := waitForTask(t, handler, taskID)
+		assert.Equal(t, astrolabe.Success.String(), *taskInfo.Status, taskInfo.Details)
+		assert.Equal(t, int64(0), taskInfo.QueuePosition)
+	}
+	assert.Equal(t, 0, admission.QueueLength())
+}
+
+func TestAdmissionReads(t *testing.T) {
+	ctx := context.Background()
+	petm := newMemoryProtectedEntityTypeManager("mem")
+	peid := petm.addEntity("a", nil)
+	petm.setData("a", testData(100), nil)
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	admission, err := NewAdmissionController(AdmissionConfig{
+		Default: ServiceLimits{MaxConcurrent: 1},
+	}, logrus.New())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	pem.SetAdmissionController(admission)
+	pe, err := pem.GetProtectedEntity(ctx, peid)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	reader1, err := pe.GetDataReader(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, err = reader1.Read(make([]byte, 10))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	// Opening a reader and finding its size does not wait, reading does
+	reader2, err := pe.GetDataReader(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer reader2.Close()
+	size, err := astrolabe.StreamSize(reader2)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, int64(100), size)
+	readData := make(chan []byte)
+	go func() {
+		data, _ := ioutil.ReadAll(reader2)
+		readData <- data
+	}()
+	waitForQueueLength(t, admission, 1)
+
+	// Reads are limited separately from snapshots
+	_, err = pe.Snapshot(ctx, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	err = reader1.Close()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, testData(100), <-readData)
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package server
+
+import (
+	"context"
+	"github.com/go-openapi/swag"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"github.com/vmware-tanzu/astrolabe/pkg/metrics"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+asyncTask runs an operation in the background.  The result of the operation becomes the task result, an error
+fails the task and cancelling the task cancels the context passed to the operation.
+*/
+type asyncTask struct {
+	mutex  sync.RWMutex
+	task   astrolabe.GenericTask
+	cancel context.CancelFunc
+	// Closed when the task finishes
+	done chan struct{}
+	// The admission queue position of the operation while it waits, 0 when it is not waiting
+	queuePosition int
+	// The Protected Entity locks the operation holds or waits for
+	locks map[astrolabe.ProtectedEntityID]*models.TaskLock
+}
+
+/*
+startAsyncTask starts run in the background.  The context passed to run carries the identity of requestCtx, so that
+the operation is attributed to the caller, but is not cancelled when the request finishes.  Progress that run reports
+with astrolabe.ReportProgress becomes the progress of the task.
+*/
+func startAsyncTask(requestCtx context.Context, details string, run func(ctx context.Context) (interface{}, error)) *asyncTask {
+	ctx := context.Background()
+	if result, ok := requestCtx.Value(authContextKey{}).(authResult); ok {
+		ctx = context.WithValue(ctx, authContextKey{}, result)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	task := &asyncTask{
+		task:   astrolabe.NewGenericTask(),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	task.task.Details = details
+	ctx = withQueuePositionReporter(ctx, task.setQueuePosition)
+	ctx = withLockStateReporter(ctx, task.setLockState)
+	ctx = astrolabe.WithProgressReporter(ctx, task.setProgress)
+	metrics.TaskStarted()
+	go func() {
+		defer cancel()
+		result, err := run(ctx)
+		task.finish(ctx, result, err)
+	}()
+	return task
+}
+
+func (this *asyncTask) setQueuePosition(position int) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.queuePosition = position
+}
+
+func (this *asyncTask) setProgress(progress float64) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if !this.task.Completed {
+		this.task.Progress = progress
+	}
+}
+
+func (this *asyncTask) setLockState(id astrolabe.ProtectedEntityID, mode LockMode, state string) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if state == "" {
+		delete(this.locks, id)
+		return
+	}
+	if this.locks == nil {
+		this.locks = map[astrolabe.ProtectedEntityID]*models.TaskLock{}
+	}
+	this.locks[id] = &models.TaskLock{
+		ProtectedEntityID: id.GetModelProtectedEntityID(),
+		Mode:              swag.String(mode.String()),
+		State:             swag.String(state),
+	}
+}
+
+func (this *asyncTask) finish(ctx context.Context, result interface{}, err error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	switch {
+	case err == nil:
+		this.task.TaskStatus = astrolabe.Success
+		this.task.Progress = 100
+		this.task.Result = result
+	case ctx.Err() != nil:
+		this.task.TaskStatus = astrolabe.Cancelled
+		this.task.Details = err.Error()
+	default:
+		this.task.TaskStatus = astrolabe.Failed
+		this.task.Details = err.Error()
+	}
+	this.task.Completed = true
+	this.queuePosition = 0
+	this.locks = nil
+	this.task.FinishedTime = time.Now()
+	metrics.TaskFinished(this.task.TaskStatus.String())
+	close(this.done)
+}
+
+/*
+Done returns a channel that is closed when the task finishes
+*/
+func (this *asyncTask) Done() <-chan struct{} {
+	return this.done
+}
+
+func (this *asyncTask) GetID() astrolabe.TaskID {
+	return this.task.ID
+}
+
+func (this *asyncTask) GetStatus() astrolabe.TaskStatus {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	return this.task.GetStatus()
+}
+
+func (this *asyncTask) GetDetails() string {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	return this.task.GetDetails()
+}
+
+func (this *asyncTask) GetFinishedTime() time.Time {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	return this.task.GetFinishedTime()
+}
+
+func (this *asyncTask) GetStartedTime() time.Time {
+	return this.task.GetStartedTime()
+}
+
+func (this *asyncTask) GetProgress() float64 {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	return this.task.GetProgress()
+}
+
+func (this *asyncTask) GetResult() interface{} {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	return this.task.GetResult()
+}
+
+func (this *asyncTask) GetModelTaskInfo() models.TaskInfo {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	taskInfo := this.task.GetModelTaskInfo()
+	taskInfo.QueuePosition = int64(this.queuePosition)
+	for _, lock := range this.locks {
+		lockCopy := *lock
+		taskInfo.Locks = append(taskInfo.Locks, &lockCopy)
+	}
+	sort.Slice(taskInfo.Locks, func(i, j int) bool {
+		return taskInfo.Locks[i].ProtectedEntityID < taskInfo.Locks[j].ProtectedEntityID
+	})
+	return taskInfo
+}
+
+func (this *asyncTask) Cancel() error {
+	this.cancel()
+	return nil
+}
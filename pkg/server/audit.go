@@ -0,0 +1,737 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+The audit log records an event for every mutating call, with the caller, the protected entities, the parameters and
+the outcome.  Events are appended as JSON lines to numbered files in the audit directory, a new file is started when
+the current one would grow past the maximum size.  Each event carries the hash of the previous event and its own
+hash, so that removing or changing an event breaks the chain.
+*/
+const (
+	auditFilePrefix = "audit-"
+	auditFileSuffix = ".jsonl"
+	// DefaultAuditMaxFileSize is the size at which a new audit file is started
+	DefaultAuditMaxFileSize = 100 * 1024 * 1024
+	// How often the end of a task started by an audited call is checked for
+	auditTaskPollInterval = time.Second
+	// Audited request and response bodies are only read up to this size
+	auditMaxBodySize = 1024 * 1024
+	auditMaxLineSize = 16 * 1024 * 1024
+	auditRedacted    = "REDACTED"
+)
+
+// Outcomes of audited calls, the end of a task is recorded with the status of the task
+const (
+	AuditSuccess  = "success"
+	AuditFailure  = "failure"
+	AuditDenied   = "denied"
+	AuditAccepted = "accepted"
+)
+
+// Operation of the events recorded for the S3 copy bucket and for the end of tasks
+const (
+	auditS3CopyOperation = "s3Copy"
+	auditTaskOperation   = "task"
+)
+
+/*
+auditedOperations maps the OpenAPI operation IDs of mutating calls to the action recorded.  A copy in update mode is
+recorded as an overwrite.
+*/
+var auditedOperations = map[string]string{
+	"createSnapshot":        "snapshot",
+	"copyProtectedEntity":   "copy",
+	"deleteProtectedEntity": "delete",
+	"updateRetention":       "retention",
+	"undeleteSnapshot":      "undelete",
+	"rehydrateSnapshot":     "rehydrate",
+}
+
+// Parameters whose names contain one of these are redacted
+var auditSecretNames = []string{"password", "secret", "token", "key", "credential", "signature"}
+
+type AuditEvent struct {
+	Sequence           int64       `json:"sequence"`
+	Time               time.Time   `json:"time"`
+	Identity           string      `json:"identity,omitempty"`
+	AuthMethod         string      `json:"authMethod,omitempty"`
+	Action             string      `json:"action"`
+	Operation          string      `json:"operation"`
+	Service            string      `json:"service,omitempty"`
+	ProtectedEntityIDs []string    `json:"protectedEntityIDs,omitempty"`
+	Params             interface{} `json:"params,omitempty"`
+	Outcome            string      `json:"outcome"`
+	Status             int         `json:"status,omitempty"`
+	Error              string      `json:"error,omitempty"`
+	TaskID             string      `json:"taskID,omitempty"`
+	DurationSeconds    float64     `json:"durationSeconds"`
+	PreviousHash       string      `json:"previousHash"`
+	Hash               string      `json:"hash"`
+}
+
+func (this AuditEvent) GetModelAuditEvent() *models.AuditEvent {
+	mpeids := make([]models.ProtectedEntityID, len(this.ProtectedEntityIDs))
+	for curID, peid := range this.ProtectedEntityIDs {
+		mpeids[curID] = models.ProtectedEntityID(peid)
+	}
+	eventTime := strfmt.DateTime(this.Time)
+	return &models.AuditEvent{
+		Sequence:           &this.Sequence,
+		Time:               &eventTime,
+		Identity:           this.Identity,
+		AuthMethod:         this.AuthMethod,
+		Action:             &this.Action,
+		Operation:          &this.Operation,
+		Service:            this.Service,
+		ProtectedEntityIDs: mpeids,
+		Params:             this.Params,
+		Outcome:            &this.Outcome,
+		Status:             int32(this.Status),
+		Error:              this.Error,
+		TaskID:             models.TaskID(this.TaskID),
+		DurationSeconds:    this.DurationSeconds,
+		PreviousHash:       this.PreviousHash,
+		Hash:               &this.Hash,
+	}
+}
+
+/*
+computeHash returns the SHA-256 of the previous hash and the event without its hash
+*/
+func (this AuditEvent) computeHash() (string, error) {
+	this.Hash = ""
+	eventJSON, err := json.Marshal(this)
+	if err != nil {
+		return "", errors.Wrap(err, "could not marshal audit event")
+	}
+	sum := sha256.Sum256(append([]byte(this.PreviousHash+"\n"), eventJSON...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+/*
+matchesProtectedEntity returns true if one of the IDs of the event is peid or, if peid does not have a snapshot ID, a
+snapshot of peid
+*/
+func (this AuditEvent) matchesProtectedEntity(peid astrolabe.ProtectedEntityID) bool {
+	for _, idStr := range this.ProtectedEntityIDs {
+		eventID, err := astrolabe.NewProtectedEntityIDFromString(idStr)
+		if err != nil {
+			continue
+		}
+		if eventID.String() == peid.String() {
+			return true
+		}
+		if !peid.HasSnapshot() &&
+			astrolabe.NewProtectedEntityID(eventID.GetPeType(), eventID.GetID()).String() == peid.String() {
+			return true
+		}
+	}
+	return false
+}
+
+type AuditConfig struct {
+	Dir string
+	// A new file is started when the current file would grow past MaxFileSize bytes
+	MaxFileSize int64
+	// The oldest files are removed when there are more than MaxFiles, 0 keeps all files
+	MaxFiles int
+}
+
+/*
+AuditFilter selects events for Query.  Zero values do not filter.
+*/
+type AuditFilter struct {
+	ProtectedEntityID *astrolabe.ProtectedEntityID
+	// Events at or after From and before To are returned
+	From, To time.Time
+}
+
+type AuditLog struct {
+	mutex    sync.Mutex
+	config   AuditConfig
+	file     *os.File
+	fileNum  int
+	fileSize int64
+	sequence int64
+	lastHash string
+	tm       *TaskManager
+	logger   logrus.FieldLogger
+	now      func() time.Time
+}
+
+/*
+NewAuditLog opens the audit log in config.Dir, creating the directory if necessary, and continues the hash chain from
+the last event written
+*/
+func NewAuditLog(config AuditConfig, logger logrus.FieldLogger) (*AuditLog, error) {
+	if config.Dir == "" {
+		return nil, errors.New("audit directory is not set")
+	}
+	if config.MaxFileSize <= 0 {
+		config.MaxFileSize = DefaultAuditMaxFileSize
+	}
+	if err := os.MkdirAll(config.Dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "could not create audit directory %s", config.Dir)
+	}
+	returnLog := &AuditLog{
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+	fileNums, err := returnLog.listFiles()
+	if err != nil {
+		return nil, err
+	}
+	returnLog.fileNum = 1
+	if len(fileNums) > 0 {
+		returnLog.fileNum = fileNums[len(fileNums)-1]
+	}
+	// The newest file may be empty if the server stopped right after rotating
+	for curFile := len(fileNums) - 1; curFile >= 0 && returnLog.sequence == 0; curFile-- {
+		err = returnLog.readFile(fileNums[curFile], func(event AuditEvent) bool {
+			returnLog.sequence = event.Sequence
+			returnLog.lastHash = event.Hash
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = returnLog.openFile(); err != nil {
+		return nil, err
+	}
+	logger.Infof("Audit log in %s, next event %d", config.Dir, returnLog.sequence+1)
+	return returnLog, nil
+}
+
+/*
+SetTaskManager lets the audit log record the end of the tasks started by audited calls
+*/
+func (this *AuditLog) SetTaskManager(tm *TaskManager) {
+	this.tm = tm
+}
+
+func (this *AuditLog) Close() error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.file == nil {
+		return nil
+	}
+	err := this.file.Close()
+	this.file = nil
+	return err
+}
+
+func (this *AuditLog) filePath(fileNum int) string {
+	return filepath.Join(this.config.Dir, fmt.Sprintf("%s%06d%s", auditFilePrefix, fileNum, auditFileSuffix))
+}
+
+/*
+listFiles returns the numbers of the audit files, oldest first
+*/
+func (this *AuditLog) listFiles() ([]int, error) {
+	entries, err := ioutil.ReadDir(this.config.Dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not list audit directory %s", this.config.Dir)
+	}
+	fileNums := []int{}
+	for _, entry := range entries {
+		var fileNum int
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, auditFilePrefix) || !strings.HasSuffix(name, auditFileSuffix) {
+			continue
+		}
+		if _, err := fmt.Sscanf(strings.TrimSuffix(strings.TrimPrefix(name, auditFilePrefix), auditFileSuffix), "%d",
+			&fileNum); err == nil {
+			fileNums = append(fileNums, fileNum)
+		}
+	}
+	sort.Ints(fileNums)
+	return fileNums, nil
+}
+
+/*
+readFile calls handle for each event in the file until it returns false
+*/
+func (this *AuditLog) readFile(fileNum int, handle func(event AuditEvent) bool) error {
+	file, err := os.Open(this.filePath(fileNum))
+	if err != nil {
+		return errors.Wrapf(err, "could not open audit file %s", this.filePath(fileNum))
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), auditMaxLineSize)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		var event AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return errors.Wrapf(err, "audit file %s is corrupt at line %d", this.filePath(fileNum), lineNum)
+		}
+		if !handle(event) {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrapf(err, "could not read audit file %s", this.filePath(fileNum))
+	}
+	return nil
+}
+
+// Must be called with the mutex held
+func (this *AuditLog) openFile() error {
+	file, err := os.OpenFile(this.filePath(this.fileNum), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "could not open audit file %s", this.filePath(this.fileNum))
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return errors.Wrapf(err, "could not stat audit file %s", this.filePath(this.fileNum))
+	}
+	this.file = file
+	this.fileSize = info.Size()
+	return nil
+}
+
+// Must be called with the mutex held
+func (this *AuditLog) rotate() error {
+	if err := this.file.Close(); err != nil {
+		return errors.Wrapf(err, "could not close audit file %s", this.filePath(this.fileNum))
+	}
+	this.file = nil
+	this.fileNum++
+	if err := this.openFile(); err != nil {
+		return err
+	}
+	if this.config.MaxFiles <= 0 {
+		return nil
+	}
+	fileNums, err := this.listFiles()
+	if err != nil {
+		return err
+	}
+	for curFile := 0; curFile < len(fileNums)-this.config.MaxFiles; curFile++ {
+		if err := os.Remove(this.filePath(fileNums[curFile])); err != nil {
+			this.logger.WithError(err).Errorf("Could not remove audit file %s", this.filePath(fileNums[curFile]))
+		}
+	}
+	return nil
+}
+
+/*
+Record appends event to the log.  The sequence number and hashes are filled in, as is the time if it is not set.
+*/
+func (this *AuditLog) Record(event *AuditEvent) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.file == nil {
+		return errors.New("audit log is closed")
+	}
+	event.Sequence = this.sequence + 1
+	if event.Time.IsZero() {
+		event.Time = this.now()
+	}
+	event.Time = event.Time.UTC()
+	event.PreviousHash = this.lastHash
+	hash, err := event.computeHash()
+	if err != nil {
+		return err
+	}
+	event.Hash = hash
+	line, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal audit event")
+	}
+	line = append(line, '\n')
+	if this.fileSize > 0 && this.fileSize+int64(len(line)) > this.config.MaxFileSize {
+		if err := this.rotate(); err != nil {
+			return err
+		}
+	}
+	bytesWritten, err := this.file.Write(line)
+	this.fileSize += int64(bytesWritten)
+	if err == nil {
+		err = this.file.Sync()
+	}
+	if err != nil {
+		return errors.Wrapf(err, "could not write audit file %s", this.filePath(this.fileNum))
+	}
+	this.sequence = event.Sequence
+	this.lastHash = event.Hash
+	return nil
+}
+
+/*
+Query returns the events that match filter, oldest first
+*/
+func (this *AuditLog) Query(filter AuditFilter) ([]AuditEvent, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	fileNums, err := this.listFiles()
+	if err != nil {
+		return nil, err
+	}
+	events := []AuditEvent{}
+	for _, fileNum := range fileNums {
+		err = this.readFile(fileNum, func(event AuditEvent) bool {
+			if !filter.From.IsZero() && event.Time.Before(filter.From) {
+				return true
+			}
+			if !filter.To.IsZero() && !event.Time.Before(filter.To) {
+				return true
+			}
+			if filter.ProtectedEntityID != nil && !event.matchesProtectedEntity(*filter.ProtectedEntityID) {
+				return true
+			}
+			events = append(events, event)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+/*
+Verify checks the sequence numbers and the hash chain of the events in the log.  When old files have been removed,
+the chain is checked from the oldest event kept.
+*/
+func (this *AuditLog) Verify() error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	fileNums, err := this.listFiles()
+	if err != nil {
+		return err
+	}
+	var previous *AuditEvent
+	var verifyErr error
+	for _, fileNum := range fileNums {
+		err = this.readFile(fileNum, func(event AuditEvent) bool {
+			if previous != nil && (event.Sequence != previous.Sequence+1 || event.PreviousHash != previous.Hash) {
+				verifyErr = errors.Errorf("audit event %d does not follow event %d", event.Sequence, previous.Sequence)
+				return false
+			}
+			hash, err := event.computeHash()
+			if err != nil {
+				verifyErr = err
+				return false
+			}
+			if hash != event.Hash {
+				verifyErr = errors.Errorf("audit event %d has been modified", event.Sequence)
+				return false
+			}
+			previous = &event
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if verifyErr != nil {
+			return verifyErr
+		}
+	}
+	return nil
+}
+
+/*
+recordCall fills in the caller from ctx and the duration since start, records event and, if the call started a task,
+records the end of the task when it finishes.  Failures to record are logged, the call has already happened.
+*/
+func (this *AuditLog) recordCall(ctx context.Context, event *AuditEvent, start time.Time) {
+	if identity := IdentityFromContext(ctx); identity != nil {
+		event.Identity = identity.Name
+		event.AuthMethod = identity.Method
+	}
+	event.DurationSeconds = this.now().Sub(start).Seconds()
+	if err := this.Record(event); err != nil {
+		this.logger.WithError(err).Errorf("Could not record audit event for %s", event.Operation)
+		return
+	}
+	if event.Outcome == AuditAccepted && event.TaskID != "" && this.tm != nil {
+		go this.recordTask(*event, start)
+	}
+}
+
+/*
+recordTask waits for the task started by call to finish and records its status and the protected entities it created
+*/
+func (this *AuditLog) recordTask(call AuditEvent, start time.Time) {
+	var task astrolabe.Task
+	for {
+		var ok bool
+		task, ok = this.tm.RetrieveTask(astrolabe.NewTaskIDFromString(call.TaskID))
+		if !ok {
+			this.logger.Errorf("Task %s started by audit event %d was not found", call.TaskID, call.Sequence)
+			return
+		}
+		if !task.GetFinishedTime().IsZero() {
+			break
+		}
+		time.Sleep(auditTaskPollInterval)
+	}
+	taskInfo := task.GetModelTaskInfo()
+	event := &AuditEvent{
+		Identity:           call.Identity,
+		AuthMethod:         call.AuthMethod,
+		Action:             call.Action,
+		Operation:          auditTaskOperation,
+		Service:            call.Service,
+		ProtectedEntityIDs: append([]string{}, call.ProtectedEntityIDs...),
+		Outcome:            *taskInfo.Status,
+		TaskID:             call.TaskID,
+		DurationSeconds:    task.GetFinishedTime().Sub(start).Seconds(),
+	}
+	if *taskInfo.Status != astrolabe.Success.String() {
+		event.Error = task.GetDetails()
+	}
+	switch result := task.GetResult().(type) {
+	case models.ProtectedEntityID:
+		event.ProtectedEntityIDs = append(event.ProtectedEntityIDs, string(result))
+	case map[string]string:
+		newIDs := []string{}
+		for _, newID := range result {
+			newIDs = append(newIDs, newID)
+		}
+		sort.Strings(newIDs)
+		event.ProtectedEntityIDs = append(event.ProtectedEntityIDs, newIDs...)
+	}
+	if err := this.Record(event); err != nil {
+		this.logger.WithError(err).Errorf("Could not record audit event for task %s", call.TaskID)
+	}
+}
+
+/*
+auditResponseWriter keeps the status and the start of the body of the response to an audited call
+*/
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (this *auditResponseWriter) WriteHeader(status int) {
+	this.status = status
+	this.ResponseWriter.WriteHeader(status)
+}
+
+func (this *auditResponseWriter) Write(p []byte) (int, error) {
+	if this.status == 0 {
+		this.status = http.StatusOK
+	}
+	if remaining := auditMaxBodySize - this.body.Len(); remaining > 0 {
+		if remaining > len(p) {
+			remaining = len(p)
+		}
+		this.body.Write(p[:remaining])
+	}
+	return this.ResponseWriter.Write(p)
+}
+
+func auditOutcome(status int) string {
+	switch {
+	case status == http.StatusAccepted:
+		return AuditAccepted
+	case status < 300:
+		return AuditSuccess
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return AuditDenied
+	default:
+		return AuditFailure
+	}
+}
+
+/*
+OpenAPIMiddleware records the mutating OpenAPI calls.  It is used as the builder passed to api.Serve, outside of the
+authorization so that denied calls are recorded too.
+*/
+func (this *AuditLog) OpenAPIMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := middleware.MatchedRouteFrom(r)
+		if route == nil || route.Operation == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		action, ok := auditedOperations[route.Operation.ID]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := this.now()
+		event := &AuditEvent{
+			Action:    action,
+			Operation: route.Operation.ID,
+			Service:   route.Params.Get("service"),
+		}
+		if peid := route.Params.Get("protectedEntityID"); peid != "" {
+			event.ProtectedEntityIDs = []string{peid}
+		}
+		var body interface{}
+		if r.Body != nil {
+			// The body is read for the parameters and put back for the handler
+			bodyBytes, err := ioutil.ReadAll(r.Body)
+			r.Body.Close()
+			r.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+			if err == nil && len(bodyBytes) <= auditMaxBodySize {
+				json.Unmarshal(bodyBytes, &body)
+			}
+		}
+		switch route.Operation.ID {
+		case "copyProtectedEntity":
+			mode := r.URL.Query().Get("mode")
+			if copyModes[mode] == astrolabe.UpdateExistingObject {
+				event.Action = "overwrite"
+			}
+			params := map[string]interface{}{
+				"mode": mode,
+			}
+			if bodyMap, ok := body.(map[string]interface{}); ok {
+				// Only the ID of the protected entity info is kept, the data transports may carry credentials
+				if info, ok := bodyMap["protectedEntityInfo"].(map[string]interface{}); ok {
+					if id, ok := info["id"].(string); ok {
+						event.ProtectedEntityIDs = append(event.ProtectedEntityIDs, id)
+					}
+				}
+				if copyParams, ok := bodyMap["copyParams"]; ok {
+					params["copyParams"] = redactAuditParams(copyParams)
+				}
+			}
+			event.Params = params
+		default:
+			if body != nil {
+				event.Params = redactAuditParams(body)
+			}
+		}
+
+		recorder := &auditResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		event.Status = recorder.status
+		event.Outcome = auditOutcome(recorder.status)
+		switch {
+		case recorder.status >= 400:
+			var apiErr models.Error
+			if json.Unmarshal(recorder.body.Bytes(), &apiErr) == nil && apiErr.Message != nil {
+				event.Error = *apiErr.Message
+			}
+		case recorder.status == http.StatusAccepted:
+			var inProgress models.CreateInProgressResponse
+			if json.Unmarshal(recorder.body.Bytes(), &inProgress) == nil {
+				event.TaskID = string(inProgress.TaskID)
+			}
+		}
+		this.recordCall(r.Context(), event, start)
+	})
+}
+
+/*
+recordS3Copy records an upload to the S3 copy bucket.  sourceIDs are the IDs in the zip, task is the task copying
+them if one was started.
+*/
+func (this *AuditLog) recordS3Copy(ctx context.Context, key string, sourceIDs []string, task astrolabe.Task,
+	err error, start time.Time) {
+	event := &AuditEvent{
+		Action:             "copy",
+		Operation:          auditS3CopyOperation,
+		ProtectedEntityIDs: sourceIDs,
+		Params: map[string]interface{}{
+			"bucket": S3CopyBucket,
+			"key":    key,
+		},
+	}
+	if err != nil {
+		event.Status = http.StatusInternalServerError
+		if s3Err, ok := err.(s3Error); ok {
+			event.Status = s3Err.status
+		}
+		event.Outcome = auditOutcome(event.Status)
+		event.Error = err.Error()
+	} else {
+		event.Status = http.StatusOK
+		event.Outcome = AuditAccepted
+		event.TaskID = task.GetID().String()
+	}
+	this.recordCall(ctx, event, start)
+}
+
+/*
+redactAuditParams returns a copy of params with the values of parameters that may be secrets replaced.  Parameters
+are redacted both as object fields and as the key/value items of an OperationParamList.
+*/
+func redactAuditParams(params interface{}) interface{} {
+	switch value := params.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(value))
+		itemKey, isItem := value["key"].(string)
+		for name, fieldValue := range value {
+			switch {
+			case isAuditSecret(name) && !(isItem && name == "key"):
+				redacted[name] = auditRedacted
+			case isItem && name == "value" && isAuditSecret(itemKey):
+				redacted[name] = auditRedacted
+			default:
+				redacted[name] = redactAuditParams(fieldValue)
+			}
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(value))
+		for curItem, item := range value {
+			redacted[curItem] = redactAuditParams(item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+func isAuditSecret(name string) bool {
+	lowerName := strings.ToLower(name)
+	for _, secretName := range auditSecretNames {
+		if strings.Contains(lowerName, secretName) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,298 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/loads"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+	"github.com/vmware-tanzu/astrolabe/gen/restapi"
+	"github.com/vmware-tanzu/astrolabe/gen/restapi/operations"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+func newTestAuditLog(t *testing.T, dir string, maxFileSize int64, maxFiles int) *AuditLog {
+	audit, err := NewAuditLog(AuditConfig{
+		Dir:         dir,
+		MaxFileSize: maxFileSize,
+		MaxFiles:    maxFiles,
+	}, logrus.New())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return audit
+}
+
+func TestAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "astrolabe-audit")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	// Each event is a few hundred bytes, so every couple of events start a new file
+	audit := newTestAuditLog(t, dir, 600, 0)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	peIDs := []string{"mem:a", "mem:a:snap-1", "mem:b", "mem:a:snap-2"}
+	for eventNum, peID := range peIDs {
+		err = audit.Record(&AuditEvent{
+			Time:               start.Add(time.Duration(eventNum) * time.Hour),
+			Action:             "snapshot",
+			Operation:          "createSnapshot",
+			ProtectedEntityIDs: []string{peID},
+			Outcome:            AuditSuccess,
+		})
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+	}
+	audit.Close()
+	fileNums, err := audit.listFiles()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, len(fileNums) > 1, "expected the log to rotate")
+
+	// Reopening continues the sequence and the hash chain
+	audit = newTestAuditLog(t, dir, 600, 0)
+	defer audit.Close()
+	err = audit.Record(&AuditEvent{
+		Time:               start.Add(4 * time.Hour),
+		Action:             "delete",
+		Operation:          "deleteProtectedEntity",
+		ProtectedEntityIDs: []string{"mem:a:snap-1"},
+		Outcome:            AuditSuccess,
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	events, err := audit.Query(AuditFilter{})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 5, len(events))
+	for eventNum, event := range events {
+		assert.Equal(t, int64(eventNum+1), event.Sequence)
+		if eventNum > 0 {
+			assert.Equal(t, events[eventNum-1].Hash, event.PreviousHash)
+		}
+	}
+	if err = audit.Verify(); err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	peID := astrolabe.NewProtectedEntityID("mem", "a")
+	events, err = audit.Query(AuditFilter{ProtectedEntityID: &peID})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 4, len(events))
+	snapshotID := peID.IDWithSnapshot(astrolabe.NewProtectedEntitySnapshotID("snap-1"))
+	events, err = audit.Query(AuditFilter{ProtectedEntityID: &snapshotID})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, "delete", events[1].Action)
+	events, err = audit.Query(AuditFilter{From: start.Add(time.Hour), To: start.Add(3 * time.Hour)})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, int64(2), events[0].Sequence)
+
+	// Changing an event breaks the chain
+	firstFile := audit.filePath(fileNums[0])
+	contents, err := ioutil.ReadFile(firstFile)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	err = ioutil.WriteFile(firstFile, bytes.Replace(contents, []byte("mem:a"), []byte("mem:c"), 1), 0600)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.ErrorContains(t, audit.Verify(), "audit event 1 has been modified")
+
+	// Only the newest files are kept
+	audit.config.MaxFiles = 2
+	if err = audit.rotate(); err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	fileNums, err = audit.listFiles()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 2, len(fileNums))
+}
+
+func TestAuditRedaction(t *testing.T) {
+	var params interface{}
+	err := json.Unmarshal([]byte(`[{"key": "ivd", "value": [{"key": "password", "value": "hunter2"},
+		{"key": "host", "value": "vc.example.com"}]}, {"key": "s3", "value": {"secretAccessKey": "abc", "region": "r"}}]`),
+		&params)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	redactedJSON, err := json.Marshal(redactAuditParams(params))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	redacted := string(redactedJSON)
+	assert.Assert(t, !strings.Contains(redacted, "hunter2"), redacted)
+	assert.Assert(t, !strings.Contains(redacted, "abc"), redacted)
+	assert.Assert(t, strings.Contains(redacted, `"key":"password","value":"REDACTED"`), redacted)
+	assert.Assert(t, strings.Contains(redacted, "vc.example.com"), redacted)
+	assert.Assert(t, strings.Contains(redacted, `"region":"r"`), redacted)
+}
+
+func TestAuditOpenAPI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "astrolabe-audit")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer os.RemoveAll(dir)
+	handler, petm := newTestHandler()
+	peid := petm.addEntity("a", nil)
+	audit := newTestAuditLog(t, filepath.Join(dir, "audit"), 0, 0)
+	defer audit.Close()
+	audit.SetTaskManager(handler.tm)
+	handler.SetAuditLog(audit)
+	auth := newTestAuthManager(t, AuthConfig{
+		BearerTokens: []BearerToken{
+			{Token: "admin-token", Identity: "admin"},
+			{Token: "viewer-token", Identity: "viewer", Groups: []string{"viewers"}},
+			{Token: "snapper-token", Identity: "snapper"},
+		},
+	}, "")
+	swaggerSpec, err := loads.Analyzed(restapi.SwaggerJSON, "")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	api := operations.NewAstrolabeAPI(swaggerSpec)
+	handler.AttachHandlers(api)
+	testServer := httptest.NewServer(auth.Authenticate(api.Serve(func(next http.Handler) http.Handler {
+		return audit.OpenAPIMiddleware(auth.OpenAPIMiddleware(next))
+	})))
+	defer testServer.Close()
+
+	doRequest := func(method string, path string, token string, body interface{}) *http.Response {
+		var bodyBytes []byte
+		if body != nil {
+			bodyBytes, err = json.Marshal(body)
+			if err != nil {
+				t.Fatal("Got error " + err.Error())
+			}
+		}
+		httpRequest, err := http.NewRequest(method, testServer.URL+path, bytes.NewReader(bodyBytes))
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		httpRequest.Header.Set("Authorization", "Bearer "+token)
+		httpRequest.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(httpRequest)
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		return resp
+	}
+
+	resp := doRequest(http.MethodPost, "/v1/astrolabe/mem/"+peid.String()+"/snapshots", "viewer-token", nil)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = doRequest(http.MethodPost, "/v1/astrolabe/mem/"+peid.String()+"/snapshots", "snapper-token", nil)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	// Reads are not audited
+	resp = doRequest(http.MethodGet, "/v1/astrolabe/mem/"+peid.String(), "viewer-token", nil)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	pe, err := petm.GetProtectedEntity(context.Background(), peid)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	info, err := pe.GetInfo(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	mpeInfo := info.GetModelProtectedEntityInfo()
+	resp = doRequest(http.MethodPost, "/v1/astrolabe/mem?mode=update", "admin-token", &models.CopyParameters{
+		ProtectedEntityInfo: &mpeInfo,
+		CopyParams: models.OperationParamList{
+			{Key: "mem", Value: models.OperationPEParamList{{Key: "password", Value: "hunter2"}}},
+		},
+	})
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	// The end of the copy task is recorded when it finishes
+	var events []AuditEvent
+	deadline := time.Now().Add(10 * time.Second)
+	for len(events) < 4 && time.Now().Before(deadline) {
+		events, err = audit.Query(AuditFilter{})
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.Equal(t, 4, len(events))
+	assert.Equal(t, "viewer", events[0].Identity)
+	assert.Equal(t, AuditDenied, events[0].Outcome)
+	assert.Equal(t, http.StatusForbidden, events[0].Status)
+	assert.Equal(t, "snapper", events[1].Identity)
+	assert.Equal(t, "snapshot", events[1].Action)
+	assert.Equal(t, AuditSuccess, events[1].Outcome)
+	assert.DeepEqual(t, []string{peid.String()}, events[1].ProtectedEntityIDs)
+	assert.Equal(t, "admin", events[2].Identity)
+	assert.Equal(t, "overwrite", events[2].Action)
+	assert.Equal(t, AuditAccepted, events[2].Outcome)
+	assert.Assert(t, events[2].TaskID != "", "accepted copy should have a task ID")
+	paramsJSON, err := json.Marshal(events[2].Params)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, !strings.Contains(string(paramsJSON), "hunter2"), string(paramsJSON))
+	assert.Equal(t, auditTaskOperation, events[3].Operation)
+	assert.Equal(t, events[2].TaskID, events[3].TaskID)
+	assert.Equal(t, "admin", events[3].Identity)
+
+	resp = doRequest(http.MethodGet, "/v1/astrolabe/audit?protectedEntityID="+peid.String(), "viewer-token", nil)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = doRequest(http.MethodGet, "/v1/astrolabe/audit?protectedEntityID="+peid.String(), "admin-token", nil)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	eventList := models.AuditEventList{}
+	if err = json.NewDecoder(resp.Body).Decode(&eventList); err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 4, len(eventList.List))
+	assert.Equal(t, events[3].Hash, *eventList.List[3].Hash)
+}
@@ -0,0 +1,538 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+/*
+AuthConfig is read from auth.json in the configuration directory.  Clients authenticate with a static bearer token,
+a client certificate signed by the CA in ClientCAFile, an OIDC JWT signed by a key in the JWKS file or an AWS SigV4
+signature made with one of the AccessKeys.  Rules grant
+identities operations on services, anything not granted is denied.  Relative file names are relative to the
+configuration directory.
+*/
+type AuthConfig struct {
+	BearerTokens []BearerToken `json:"bearerTokens,omitempty"`
+	ClientCAFile string        `json:"clientCAFile,omitempty"`
+	OIDC         *OIDCConfig   `json:"oidc,omitempty"`
+	AccessKeys   []AccessKey   `json:"accessKeys,omitempty"`
+	Rules        []AuthRule    `json:"rules"`
+}
+
+type BearerToken struct {
+	Token    string   `json:"token"`
+	Identity string   `json:"identity"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+/*
+AccessKey is an S3 access key, requests signed with SigV4 using the secret key are authenticated as Identity
+*/
+type AccessKey struct {
+	AccessKey string   `json:"accessKey"`
+	SecretKey string   `json:"secretKey"`
+	Identity  string   `json:"identity"`
+	Groups    []string `json:"groups,omitempty"`
+}
+
+/*
+OIDCConfig configures JWT validation.  The identity is taken from IdentityClaim (default sub) and the groups from
+GroupsClaim if it is set.
+*/
+type OIDCConfig struct {
+	JWKSFile      string `json:"jwksFile"`
+	Issuer        string `json:"issuer,omitempty"`
+	Audience      string `json:"audience,omitempty"`
+	IdentityClaim string `json:"identityClaim,omitempty"`
+	GroupsClaim   string `json:"groupsClaim,omitempty"`
+}
+
+/*
+AuthRule grants Operations, or the operations of Role, on Services to Identities.  Identities are identity names,
+group:<group name> or * for any authenticated identity.  Services are service types, e.g. ivd, or * for all services.
+*/
+type AuthRule struct {
+	Identities []string `json:"identities"`
+	Services   []string `json:"services"`
+	Operations []string `json:"operations,omitempty"`
+	Role       string   `json:"role,omitempty"`
+}
+
+/*
+Operations that are authorized per service.  Listing services and working with tasks only requires authentication.
+*/
+const (
+	ReadOperation      = "read"
+	SnapshotOperation  = "snapshot"
+	DeleteOperation    = "delete"
+	CopyOperation      = "copy"
+	RetentionOperation = "retention"
+	UndeleteOperation  = "undelete"
+	RehydrateOperation = "rehydrate"
+	AuditOperation     = "audit"
+	AllOperations      = "*"
+	AllServices        = "*"
+)
+
+var authRoles = map[string][]string{
+	"read-only":     {ReadOperation},
+	"snapshot-only": {SnapshotOperation},
+	"backup":        {ReadOperation, SnapshotOperation, DeleteOperation},
+	"restore":       {ReadOperation, CopyOperation},
+	"auditor":       {AuditOperation},
+	"admin":         {AllOperations},
+}
+
+/*
+openAPIOperations maps the OpenAPI operation IDs to the operation that is authorized.  An empty operation only
+requires authentication.  Operations that are not listed are denied.
+*/
+var openAPIOperations = map[string]string{
+	"listServices":           "",
+	"listTasks":              "",
+	"getTaskInfo":            "",
+	"watchTaskEvents":        "",
+	"listTaskNexus":          "",
+	"createTaskNexus":        "",
+	"waitOnTaskNexus":        "",
+	"getUsage":               ReadOperation,
+	"listProtectedEntities":  ReadOperation,
+	"getProtectedEntityInfo": ReadOperation,
+	"listSnapshots":          ReadOperation,
+	"getRetention":           ReadOperation,
+	"listDeletedSnapshots":   ReadOperation,
+	"copyProtectedEntity":    CopyOperation,
+	"createSnapshot":         SnapshotOperation,
+	"deleteProtectedEntity":  DeleteOperation,
+	"updateRetention":        RetentionOperation,
+	"undeleteSnapshot":       UndeleteOperation,
+	"rehydrateSnapshot":      RehydrateOperation,
+	"listAuditEvents":        AuditOperation,
+}
+
+/*
+Identity is an authenticated client
+*/
+type Identity struct {
+	Name   string
+	Groups []string
+	// Method is how the identity was authenticated, token, certificate, jwt or sigv4
+	Method string
+}
+
+type authContextKey struct{}
+
+type authResult struct {
+	identity *Identity
+	err      error
+	// sigV4 is set for requests authenticated with a SigV4 signature so that the payload can be verified
+	sigV4 *sigV4Request
+}
+
+/*
+IdentityFromContext returns the identity authenticated for the request, nil if there is none
+*/
+func IdentityFromContext(ctx context.Context) *Identity {
+	result, ok := ctx.Value(authContextKey{}).(authResult)
+	if !ok {
+		return nil
+	}
+	return result.identity
+}
+
+/*
+AuthManager authenticates requests and authorizes operations against the rules in the AuthConfig
+*/
+type AuthManager struct {
+	tokens      []BearerToken
+	clientCAs   *x509.CertPool
+	jwtVerifier *jwtVerifier
+	oidc        OIDCConfig
+	accessKeys  map[string]AccessKey
+	rules       []AuthRule
+	logger      logrus.FieldLogger
+	now         func() time.Time
+}
+
+const authConfigFile = "auth.json"
+
+/*
+NewAuthManagerFromConfigDir reads auth.json from confDirPath.  If there is no auth.json, nil is returned and requests
+are not authenticated.
+*/
+func NewAuthManagerFromConfigDir(confDirPath string, logger logrus.FieldLogger) (*AuthManager, error) {
+	authConfigPath := filepath.Join(confDirPath, authConfigFile)
+	configBytes, err := ioutil.ReadFile(authConfigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "could not read %s", authConfigPath)
+	}
+	config := AuthConfig{}
+	err = json.Unmarshal(configBytes, &config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse %s", authConfigPath)
+	}
+	return NewAuthManager(config, confDirPath, logger)
+}
+
+func NewAuthManager(config AuthConfig, confDirPath string, logger logrus.FieldLogger) (*AuthManager, error) {
+	returnManager := &AuthManager{
+		tokens:     config.BearerTokens,
+		accessKeys: map[string]AccessKey{},
+		logger:     logger,
+		now:        time.Now,
+	}
+	for _, token := range config.BearerTokens {
+		if token.Token == "" || token.Identity == "" {
+			return nil, errors.New("bearer tokens must have a token and an identity")
+		}
+	}
+	for _, accessKey := range config.AccessKeys {
+		if accessKey.AccessKey == "" || accessKey.SecretKey == "" || accessKey.Identity == "" {
+			return nil, errors.New("access keys must have an access key, a secret key and an identity")
+		}
+		if _, ok := returnManager.accessKeys[accessKey.AccessKey]; ok {
+			return nil, errors.Errorf("access key %s is defined more than once", accessKey.AccessKey)
+		}
+		returnManager.accessKeys[accessKey.AccessKey] = accessKey
+	}
+	if config.ClientCAFile != "" {
+		caPEM, err := ioutil.ReadFile(configPath(confDirPath, config.ClientCAFile))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read client CA file %s", config.ClientCAFile)
+		}
+		returnManager.clientCAs = x509.NewCertPool()
+		if !returnManager.clientCAs.AppendCertsFromPEM(caPEM) {
+			return nil, errors.Errorf("no certificates found in client CA file %s", config.ClientCAFile)
+		}
+	}
+	if config.OIDC != nil {
+		var err error
+		returnManager.oidc = *config.OIDC
+		if returnManager.oidc.IdentityClaim == "" {
+			returnManager.oidc.IdentityClaim = "sub"
+		}
+		returnManager.jwtVerifier, err = newJWTVerifier(configPath(confDirPath, config.OIDC.JWKSFile),
+			config.OIDC.Issuer, config.OIDC.Audience)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, rule := range config.Rules {
+		if rule.Role != "" {
+			if _, ok := authRoles[rule.Role]; !ok {
+				return nil, errors.Errorf("unknown role %q", rule.Role)
+			}
+		}
+	}
+	returnManager.rules = config.Rules
+	return returnManager, nil
+}
+
+func configPath(confDirPath string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(confDirPath, path)
+}
+
+/*
+ConfigureTLS asks TLS clients for a certificate signed by the client CA.  Clients without a certificate can still
+connect and authenticate with a token.
+*/
+func (this *AuthManager) ConfigureTLS(tlsConfig *tls.Config) {
+	if this.clientCAs != nil {
+		tlsConfig.ClientCAs = this.clientCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+}
+
+/*
+Authenticate authenticates each request and records the result in the request context for the authorization checks
+of the OpenAPI and S3 handlers.  Failed authentication is reported by those checks.
+*/
+func (this *AuthManager) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result := this.authenticate(r)
+		if result.err != nil {
+			this.logger.WithError(result.err).Infof("Authentication failed for %s %s from %s", r.Method, r.URL.Path,
+				r.RemoteAddr)
+		}
+		ctx := context.WithValue(r.Context(), authContextKey{}, result)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+var errNotAuthenticated = errors.New("no credentials were provided")
+
+func (this *AuthManager) authenticate(r *http.Request) authResult {
+	var result authResult
+	authorization := r.Header.Get("Authorization")
+	switch {
+	case strings.HasPrefix(authorization, "Bearer "):
+		result.identity, result.err = this.authenticateBearer(strings.TrimSpace(strings.TrimPrefix(authorization,
+			"Bearer ")))
+	case strings.HasPrefix(authorization, "AWS"), r.URL.Query().Get("X-Amz-Algorithm") != "":
+		result.identity, result.sigV4, result.err = this.authenticateSigV4(r)
+	case r.TLS != nil && len(r.TLS.PeerCertificates) > 0 && this.clientCAs != nil:
+		result.identity, result.err = this.authenticateCertificate(r.TLS.PeerCertificates)
+	default:
+		result.err = errNotAuthenticated
+	}
+	if result.err != nil {
+		return authResult{err: result.err}
+	}
+	return result
+}
+
+func (this *AuthManager) authenticateBearer(token string) (*Identity, error) {
+	for _, checkToken := range this.tokens {
+		if subtle.ConstantTimeCompare([]byte(checkToken.Token), []byte(token)) == 1 {
+			return &Identity{
+				Name:   checkToken.Identity,
+				Groups: checkToken.Groups,
+				Method: "token",
+			}, nil
+		}
+	}
+	if this.jwtVerifier == nil || strings.Count(token, ".") != 2 {
+		return nil, errors.New("unknown bearer token")
+	}
+	claims, err := this.jwtVerifier.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	name, ok := claims[this.oidc.IdentityClaim].(string)
+	if !ok || name == "" {
+		return nil, errors.Errorf("JWT does not have a %s claim", this.oidc.IdentityClaim)
+	}
+	identity := &Identity{
+		Name:   name,
+		Method: "jwt",
+	}
+	if this.oidc.GroupsClaim != "" {
+		groups, _ := claims[this.oidc.GroupsClaim].([]interface{})
+		for _, group := range groups {
+			if groupStr, ok := group.(string); ok {
+				identity.Groups = append(identity.Groups, groupStr)
+			}
+		}
+	}
+	return identity, nil
+}
+
+/*
+authenticateCertificate verifies the client certificate chain against the client CAs.  The identity is the common
+name of the certificate and the groups are its organizations.
+*/
+func (this *AuthManager) authenticateCertificate(certs []*x509.Certificate) (*Identity, error) {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         this.clientCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid client certificate")
+	}
+	if certs[0].Subject.CommonName == "" {
+		return nil, errors.New("client certificate does not have a common name")
+	}
+	return &Identity{
+		Name:   certs[0].Subject.CommonName,
+		Groups: certs[0].Subject.Organization,
+		Method: "certificate",
+	}, nil
+}
+
+/*
+AuthError is returned when a request is not authenticated or not authorized
+*/
+type AuthError struct {
+	Authenticated bool
+	Message       string
+	// Err is why authentication failed
+	Err error
+}
+
+func (this AuthError) Error() string {
+	return this.Message
+}
+
+/*
+authorize checks that the request is authenticated and, if operation is not empty, that the identity is allowed
+operation on service.  An empty service requires that operation is allowed on all services.
+*/
+func (this *AuthManager) authorize(ctx context.Context, service string, operation string) (*Identity, error) {
+	result, ok := ctx.Value(authContextKey{}).(authResult)
+	if !ok || result.identity == nil {
+		message := "not authenticated"
+		if ok && result.err != nil {
+			message = "not authenticated: " + result.err.Error()
+		}
+		return nil, AuthError{Message: message, Err: result.err}
+	}
+	if operation == "" || this.Allowed(result.identity, service, operation) {
+		return result.identity, nil
+	}
+	if service == "" {
+		service = AllServices
+	}
+	this.logger.Infof("Denied %s on service %s to %s", operation, service, result.identity.Name)
+	return result.identity, AuthError{
+		Authenticated: true,
+		Message:       result.identity.Name + " is not allowed " + operation + " on service " + service,
+	}
+}
+
+/*
+Allowed returns true if a rule grants identity operation on service
+*/
+func (this *AuthManager) Allowed(identity *Identity, service string, operation string) bool {
+	for _, rule := range this.rules {
+		if ruleMatchesIdentity(rule, identity) && ruleMatchesService(rule, service) &&
+			ruleMatchesOperation(rule, operation) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+AllowedOnAnyService returns true if a rule grants identity operation on at least one service
+*/
+func (this *AuthManager) AllowedOnAnyService(identity *Identity, operation string) bool {
+	for _, rule := range this.rules {
+		if ruleMatchesIdentity(rule, identity) && len(rule.Services) > 0 && ruleMatchesOperation(rule, operation) {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleMatchesIdentity(rule AuthRule, identity *Identity) bool {
+	for _, ruleIdentity := range rule.Identities {
+		if ruleIdentity == "*" || ruleIdentity == identity.Name {
+			return true
+		}
+		if strings.HasPrefix(ruleIdentity, "group:") {
+			for _, group := range identity.Groups {
+				if ruleIdentity == "group:"+group {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func ruleMatchesService(rule AuthRule, service string) bool {
+	for _, ruleService := range rule.Services {
+		if ruleService == AllServices || (service != "" && ruleService == service) {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleMatchesOperation(rule AuthRule, operation string) bool {
+	operations := append(append([]string{}, rule.Operations...), authRoles[rule.Role]...)
+	for _, ruleOperation := range operations {
+		if ruleOperation == AllOperations || ruleOperation == operation {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+OpenAPIMiddleware authorizes OpenAPI requests after they have been routed.  It is passed to AstrolabeAPI.Serve.
+The service is taken from the service path parameter, or the service query parameter for getUsage.
+*/
+func (this *AuthManager) OpenAPIMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := middleware.MatchedRouteFrom(r)
+		if route == nil || route.Operation == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		operation, ok := openAPIOperations[route.Operation.ID]
+		if !ok {
+			writeAuthError(w, AuthError{Authenticated: true, Message: "operation " + route.Operation.ID + " is not allowed"})
+			return
+		}
+		service := route.Params.Get("service")
+		if service == "" {
+			service = r.URL.Query().Get("service")
+		}
+		_, err := this.authorize(r.Context(), service, operation)
+		if err != nil {
+			writeAuthError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+/*
+RequireAuthentication only passes authenticated requests to next.  It is used for endpoints that are not part of a
+service, e.g. the metrics.
+*/
+func (this *AuthManager) RequireAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := this.authorize(r.Context(), "", "")
+		if err != nil {
+			writeAuthError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeAuthError(w http.ResponseWriter, err error) {
+	status := http.StatusUnauthorized
+	if authErr, ok := err.(AuthError); ok && authErr.Authenticated {
+		status = http.StatusForbidden
+	} else {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+	payload, _ := json.Marshal(errorPayload(status, err.Error()))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(payload)
+}
@@ -0,0 +1,487 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/loads"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/gen/restapi"
+	"github.com/vmware-tanzu/astrolabe/gen/restapi/operations"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+var testAuthRules = []AuthRule{
+	{Identities: []string{"admin"}, Services: []string{AllServices}, Role: "admin"},
+	{Identities: []string{"group:viewers"}, Services: []string{"mem"}, Role: "read-only"},
+	{Identities: []string{"snapper"}, Services: []string{"mem"}, Role: "snapshot-only"},
+	{Identities: []string{"restorer"}, Services: []string{"mem"}, Operations: []string{ReadOperation, CopyOperation}},
+}
+
+func newTestAuthManager(t *testing.T, config AuthConfig, confDir string) *AuthManager {
+	config.Rules = testAuthRules
+	auth, err := NewAuthManager(config, confDir, logrus.New())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return auth
+}
+
+func TestAuthRules(t *testing.T) {
+	auth := newTestAuthManager(t, AuthConfig{}, "")
+	admin := &Identity{Name: "admin"}
+	viewer := &Identity{Name: "someone", Groups: []string{"viewers"}}
+	snapper := &Identity{Name: "snapper"}
+	stranger := &Identity{Name: "stranger"}
+
+	assert.Assert(t, auth.Allowed(admin, "ivd", DeleteOperation))
+	assert.Assert(t, auth.Allowed(admin, "", DeleteOperation))
+	assert.Assert(t, auth.Allowed(viewer, "mem", ReadOperation))
+	assert.Assert(t, !auth.Allowed(viewer, "mem", SnapshotOperation))
+	assert.Assert(t, !auth.Allowed(viewer, "ivd", ReadOperation))
+	// An empty service requires the operation to be allowed on all services
+	assert.Assert(t, !auth.Allowed(viewer, "", ReadOperation))
+	assert.Assert(t, auth.Allowed(snapper, "mem", SnapshotOperation))
+	assert.Assert(t, !auth.Allowed(snapper, "mem", ReadOperation))
+	assert.Assert(t, !auth.Allowed(stranger, "mem", ReadOperation))
+	assert.Assert(t, auth.AllowedOnAnyService(&Identity{Name: "restorer"}, CopyOperation))
+	assert.Assert(t, !auth.AllowedOnAnyService(viewer, CopyOperation))
+
+	_, err := NewAuthManager(AuthConfig{Rules: []AuthRule{{Identities: []string{"*"}, Role: "superuser"}}}, "",
+		logrus.New())
+	assert.Assert(t, err != nil, "expected an error for an unknown role")
+
+	// Every OpenAPI operation must be mapped, otherwise it would always be denied
+	swaggerSpec, err := loads.Analyzed(restapi.SwaggerJSON, "")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	for _, operationID := range swaggerSpec.Analyzer.OperationIDs() {
+		_, ok := openAPIOperations[operationID]
+		assert.Assert(t, ok, "operation %s is not mapped", operationID)
+	}
+}
+
+func authRequest(t *testing.T, auth *AuthManager, token string) (*Identity, error) {
+	request, err := http.NewRequest(http.MethodGet, "http://localhost/v1/astrolabe", nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	result := auth.authenticate(request)
+	return result.identity, result.err
+}
+
+func encodeJWTPart(t *testing.T, part interface{}) string {
+	partBytes, err := json.Marshal(part)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(partBytes)
+}
+
+func signJWT(t *testing.T, alg string, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	signed := encodeJWTPart(t, map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." +
+		encodeJWTPart(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	var signature []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestAuthBearerTokens(t *testing.T) {
+	confDir, err := ioutil.TempDir("", "astrolabe-auth")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer os.RemoveAll(confDir)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	encodeInt := func(i *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(i.Bytes())
+	}
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "n": encodeInt(rsaKey.N),
+				"e": encodeInt(big.NewInt(int64(rsaKey.E)))},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encodeInt(ecKey.X), "y": encodeInt(ecKey.Y)},
+			{"kty": "RSA", "kid": "enc", "use": "enc", "n": "", "e": ""},
+		},
+	}
+	jwksBytes, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	err = ioutil.WriteFile(filepath.Join(confDir, "jwks.json"), jwksBytes, 0600)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	auth := newTestAuthManager(t, AuthConfig{
+		BearerTokens: []BearerToken{{Token: "admin-token", Identity: "admin"}},
+		OIDC: &OIDCConfig{
+			JWKSFile:    "jwks.json",
+			Issuer:      "https://issuer.example.com",
+			Audience:    "astrolabe",
+			GroupsClaim: "groups",
+		},
+	}, confDir)
+
+	identity, err := authRequest(t, auth, "admin-token")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "admin", identity.Name)
+	assert.Equal(t, "token", identity.Method)
+	_, err = authRequest(t, auth, "wrong-token")
+	assert.Assert(t, err != nil, "expected an error for an unknown token")
+	_, err = authRequest(t, auth, "")
+	assert.Equal(t, errNotAuthenticated, err)
+
+	now := time.Now().Unix()
+	claims := map[string]interface{}{
+		"iss":    "https://issuer.example.com",
+		"aud":    []string{"other", "astrolabe"},
+		"sub":    "someone",
+		"groups": []string{"viewers"},
+		"exp":    now + 300,
+		"nbf":    now - 10,
+	}
+	identity, err = authRequest(t, auth, signJWT(t, "RS256", "rsa", rsaKey, claims))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "someone", identity.Name)
+	assert.Equal(t, "jwt", identity.Method)
+	assert.DeepEqual(t, []string{"viewers"}, identity.Groups)
+	identity, err = authRequest(t, auth, signJWT(t, "ES256", "ec", ecKey, claims))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "someone", identity.Name)
+
+	// The key must match the algorithm and the kid
+	_, err = authRequest(t, auth, signJWT(t, "RS256", "ec", rsaKey, claims))
+	assert.Assert(t, err != nil, "expected an error for the wrong key")
+	_, err = authRequest(t, auth, signJWT(t, "ES256", "rsa", ecKey, claims))
+	assert.Assert(t, err != nil, "expected an error for the wrong algorithm")
+	_, err = authRequest(t, auth, signJWT(t, "RS256", "enc", rsaKey, claims))
+	assert.Assert(t, err != nil, "expected an error for an encryption key")
+	tampered := signJWT(t, "RS256", "rsa", rsaKey, claims)
+	parts := strings.Split(tampered, ".")
+	claims["sub"] = "admin"
+	parts[1] = encodeJWTPart(t, claims)
+	_, err = authRequest(t, auth, strings.Join(parts, "."))
+	assert.Assert(t, err != nil, "expected an error for modified claims")
+	claims["sub"] = "someone"
+
+	invalidClaims := []map[string]interface{}{
+		{"exp": now - 3600},
+		{"exp": nil},
+		{"nbf": now + 3600},
+		{"iss": "https://other.example.com"},
+		{"aud": "other"},
+	}
+	for _, invalid := range invalidClaims {
+		badClaims := map[string]interface{}{}
+		for name, value := range claims {
+			badClaims[name] = value
+		}
+		for name, value := range invalid {
+			if value == nil {
+				delete(badClaims, name)
+			} else {
+				badClaims[name] = value
+			}
+		}
+		_, err = authRequest(t, auth, signJWT(t, "RS256", "rsa", rsaKey, badClaims))
+		assert.Assert(t, err != nil, "expected an error for %v", invalid)
+	}
+}
+
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *x509.Certificate,
+	parentKey crypto.Signer) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	if parent == nil {
+		parent = template
+		parentKey = key
+	}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return cert, key
+}
+
+func TestAuthClientCertificate(t *testing.T) {
+	confDir, err := ioutil.TempDir("", "astrolabe-auth")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer os.RemoveAll(confDir)
+
+	caCert, caKey := newTestCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "astrolabe test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+	err = ioutil.WriteFile(filepath.Join(confDir, "ca.pem"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}), 0600)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	clientCert, clientKey := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "backup-client", Organization: []string{"viewers"}},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, caKey)
+	otherCACert, otherCAKey := newTestCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(3),
+		Subject:               pkix.Name{CommonName: "other CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+	otherCert, otherKey := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(4),
+		Subject:      pkix.Name{CommonName: "admin"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, otherCACert, otherCAKey)
+
+	auth := newTestAuthManager(t, AuthConfig{ClientCAFile: "ca.pem"}, confDir)
+	identity, err := auth.authenticateCertificate([]*x509.Certificate{clientCert})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "backup-client", identity.Name)
+	assert.DeepEqual(t, []string{"viewers"}, identity.Groups)
+	_, err = auth.authenticateCertificate([]*x509.Certificate{otherCert})
+	assert.Assert(t, err != nil, "expected an error for a certificate from another CA")
+
+	// Clients without certificates are accepted by the TLS handshake and rejected by authorization
+	testServer := httptest.NewUnstartedServer(auth.Authenticate(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, err := auth.authorize(r.Context(), "mem", ReadOperation)
+			if err != nil {
+				writeAuthError(w, err)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})))
+	testServer.TLS = &tls.Config{}
+	auth.ConfigureTLS(testServer.TLS)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, testServer.TLS.ClientAuth)
+	testServer.StartTLS()
+	defer testServer.Close()
+
+	expectedStatus := map[*x509.Certificate]int{
+		clientCert: http.StatusOK,
+		otherCert:  http.StatusUnauthorized,
+		nil:        http.StatusUnauthorized,
+	}
+	keys := map[*x509.Certificate]crypto.PrivateKey{clientCert: clientKey, otherCert: otherKey}
+	for cert, status := range expectedStatus {
+		// Clone the transport so that neither the certificate nor the connection is reused
+		transport := testServer.Client().Transport.(*http.Transport).Clone()
+		client := &http.Client{Transport: transport}
+		if cert != nil {
+			transport.TLSClientConfig.Certificates = []tls.Certificate{{
+				Certificate: [][]byte{cert.Raw},
+				PrivateKey:  keys[cert],
+			}}
+		}
+		resp, err := client.Get(testServer.URL)
+		if err != nil {
+			// The server refuses the handshake for a certificate it cannot verify
+			assert.Assert(t, cert == otherCert, "Got error "+err.Error())
+			continue
+		}
+		resp.Body.Close()
+		assert.Equal(t, status, resp.StatusCode)
+	}
+}
+
+func TestAuthOpenAPI(t *testing.T) {
+	handler, petm := newTestHandler()
+	petm.addEntity("a", nil)
+	auth := newTestAuthManager(t, AuthConfig{
+		BearerTokens: []BearerToken{
+			{Token: "admin-token", Identity: "admin"},
+			{Token: "viewer-token", Identity: "viewer", Groups: []string{"viewers"}},
+			{Token: "snapper-token", Identity: "snapper"},
+		},
+	}, "")
+	swaggerSpec, err := loads.Analyzed(restapi.SwaggerJSON, "")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	api := operations.NewAstrolabeAPI(swaggerSpec)
+	handler.AttachHandlers(api)
+	testServer := httptest.NewServer(auth.Authenticate(api.Serve(auth.OpenAPIMiddleware)))
+	defer testServer.Close()
+
+	peID := astrolabe.NewProtectedEntityID("mem", "a").String()
+	requests := []struct {
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{http.MethodGet, "/v1/astrolabe", "", http.StatusUnauthorized},
+		{http.MethodGet, "/v1/astrolabe", "bad-token", http.StatusUnauthorized},
+		{http.MethodGet, "/v1/astrolabe", "snapper-token", http.StatusOK},
+		{http.MethodGet, "/v1/astrolabe/mem", "viewer-token", http.StatusOK},
+		{http.MethodGet, "/v1/astrolabe/mem", "snapper-token", http.StatusForbidden},
+		{http.MethodGet, "/v1/astrolabe/mem/" + peID, "viewer-token", http.StatusOK},
+		{http.MethodPost, "/v1/astrolabe/mem/" + peID + "/snapshots", "viewer-token", http.StatusForbidden},
+		{http.MethodPost, "/v1/astrolabe/mem/" + peID + "/snapshots", "snapper-token", http.StatusOK},
+		{http.MethodDelete, "/v1/astrolabe/mem/" + peID, "snapper-token", http.StatusForbidden},
+		// getUsage without a service covers all of the services
+		{http.MethodGet, "/v1/astrolabe/usage", "viewer-token", http.StatusForbidden},
+		{http.MethodGet, "/v1/astrolabe/usage", "admin-token", http.StatusOK},
+	}
+	for _, request := range requests {
+		httpRequest, err := http.NewRequest(request.method, testServer.URL+request.path, nil)
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		if request.token != "" {
+			httpRequest.Header.Set("Authorization", "Bearer "+request.token)
+		}
+		resp, err := http.DefaultClient.Do(httpRequest)
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		resp.Body.Close()
+		assert.Equal(t, request.status, resp.StatusCode, "%s %s with %q", request.method, request.path,
+			request.token)
+		if request.status == http.StatusUnauthorized {
+			assert.Equal(t, "Bearer", resp.Header.Get("WWW-Authenticate"))
+		}
+	}
+}
+
+func TestAuthS3(t *testing.T) {
+	petm := newMemoryProtectedEntityTypeManager("mem")
+	petm.addEntity("a", nil)
+	petm.setData("a", testData(10), nil)
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	auth := newTestAuthManager(t, AuthConfig{
+		BearerTokens: []BearerToken{
+			{Token: "viewer-token", Identity: "viewer", Groups: []string{"viewers"}},
+			{Token: "snapper-token", Identity: "snapper"},
+		},
+	}, "")
+	s3Service := NewServiceS3(pem, NewTaskManager(), "s3", logrus.New())
+	s3Service.SetAuthManager(auth)
+	testServer := httptest.NewServer(auth.Authenticate(s3Service.Handler(http.NotFoundHandler())))
+	defer testServer.Close()
+
+	peID := astrolabe.NewProtectedEntityID("mem", "a").String()
+	requests := []struct {
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{http.MethodGet, "/s3/", "", http.StatusForbidden},
+		{http.MethodGet, "/s3/", "snapper-token", http.StatusOK},
+		{http.MethodGet, "/s3/mem?list-type=2", "snapper-token", http.StatusForbidden},
+		{http.MethodGet, "/s3/mem?list-type=2", "viewer-token", http.StatusOK},
+		{http.MethodGet, "/s3/mem/" + peID, "viewer-token", http.StatusOK},
+		{http.MethodHead, "/s3/mem/" + peID, "snapper-token", http.StatusForbidden},
+		{http.MethodPut, "/s3/copy/a.zip", "viewer-token", http.StatusForbidden},
+	}
+	for _, request := range requests {
+		httpRequest, err := http.NewRequest(request.method, testServer.URL+request.path, nil)
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		if request.token != "" {
+			httpRequest.Header.Set("Authorization", "Bearer "+request.token)
+		}
+		resp, err := http.DefaultClient.Do(httpRequest)
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, request.status, resp.StatusCode, "%s %s with %q", request.method, request.path,
+			request.token)
+		if request.status == http.StatusForbidden && request.method != http.MethodHead {
+			assert.Assert(t, strings.Contains(string(body), "AccessDenied"), string(body))
+		}
+	}
+}
@@ -26,74 +26,236 @@ import (
 	"github.com/vmware-tanzu/astrolabe/pkg/fs"
 	"github.com/vmware-tanzu/astrolabe/pkg/ivd"
 	"github.com/vmware-tanzu/astrolabe/pkg/kubernetes"
+	"github.com/vmware-tanzu/astrolabe/pkg/metrics"
 	"github.com/vmware-tanzu/astrolabe/pkg/pvc"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 type DirectProtectedEntityManager struct {
+	mutex       sync.RWMutex
 	typeManager map[string]astrolabe.ProtectedEntityTypeManager
-	s3Config    astrolabe.S3Config
-	logger      logrus.FieldLogger
+	// The services created from configuration files, by the name of the file.  Type managers that are not listed
+	// were passed in or registered directly and are not changed by a reload.
+	configured map[string]configuredService
+	// The services whose configuration file could not be read or whose type manager could not be created, by the
+	// name of the file.  They are reported as failed, or degraded if an earlier configuration is still running.
+	failed   map[string]error
+	s3Config astrolabe.S3Config
+	// Snapshot and copy events are published to events if it is set
+	events *EventBus
+	// Snapshots and copies wait for admission if it is set
+	admission *AdmissionController
+	// Conflicting snapshots, snapshot deletes and copies of a Protected Entity are serialized by locks if it is set
+	locks *LockManager
+	// Components on other servers are resolved through federation if it is set
+	federation *Federation
+	// Type managers replaced or removed by a reload are closed once the tasks that were running at the reload have
+	// finished if it is set, or right away if it is not
+	tasks  *TaskManager
+	logger logrus.FieldLogger
+}
+
+type configuredService struct {
+	typeName string
+	params   map[string]interface{}
 }
 
 func NewDirectProtectedEntityManager(petms []astrolabe.ProtectedEntityTypeManager, s3Config astrolabe.S3Config, logger logrus.FieldLogger) (returnPEM *DirectProtectedEntityManager) {
 	returnPEM = &DirectProtectedEntityManager{
 		typeManager: make(map[string]astrolabe.ProtectedEntityTypeManager),
+		configured:  make(map[string]configuredService),
+		failed:      make(map[string]error),
 		logger:      logger,
 	}
 	for _, curPETM := range petms {
-		returnPEM.typeManager[curPETM.GetTypeName()] = curPETM
-		switch curPETM.(type) {
-		case *pvc.PVCProtectedEntityTypeManager:
-			curPETM.(*pvc.PVCProtectedEntityTypeManager).SetProtectedEntityManager(returnPEM)
-		}
+		returnPEM.typeManager[curPETM.GetTypeName()] = returnPEM.wrapTypeManager(curPETM)
 	}
 	returnPEM.s3Config = s3Config
 	return
 }
 
+/*
+wrapTypeManager connects type managers that need the protected entity manager to it and decorates them
+*/
+func (this *DirectProtectedEntityManager) wrapTypeManager(petm astrolabe.ProtectedEntityTypeManager) astrolabe.ProtectedEntityTypeManager {
+	switch petm.(type) {
+	case *pvc.PVCProtectedEntityTypeManager:
+		petm.(*pvc.PVCProtectedEntityTypeManager).SetProtectedEntityManager(this)
+	}
+	return this.decorate(petm)
+}
+
+/*
+decorate adds metrics and, if they are set, admission control, locks and events to a type manager.  Admission is
+outside of the metrics so that the time spent waiting is not counted as operation latency.  Locks are taken before
+admission so that an operation waiting for a lock does not hold up the admission queue.
+*/
+func (this *DirectProtectedEntityManager) decorate(petm astrolabe.ProtectedEntityTypeManager) astrolabe.ProtectedEntityTypeManager {
+	decorated := metrics.NewMetricsProtectedEntityTypeManager(petm)
+	if this.admission != nil {
+		decorated = newAdmissionProtectedEntityTypeManager(decorated, this.admission)
+	}
+	if this.locks != nil {
+		decorated = newLockingProtectedEntityTypeManager(decorated, this.locks)
+	}
+	if this.events != nil {
+		decorated = newEventsProtectedEntityTypeManager(decorated, this.events)
+	}
+	return decorated
+}
+
+/*
+SetEventBus publishes the snapshot and copy events of all type managers, including those added later, to events
+*/
+func (this *DirectProtectedEntityManager) SetEventBus(events *EventBus) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.events = events
+	for typeName, petm := range this.typeManager {
+		this.typeManager[typeName] = this.decorate(astrolabe.UnwrapProtectedEntityTypeManager(petm))
+	}
+}
+
+/*
+SetAdmissionController limits the snapshots and copies of all type managers, including those added later, with
+admission
+*/
+func (this *DirectProtectedEntityManager) SetAdmissionController(admission *AdmissionController) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.admission = admission
+	for typeName, petm := range this.typeManager {
+		this.typeManager[typeName] = this.decorate(astrolabe.UnwrapProtectedEntityTypeManager(petm))
+	}
+}
+
+/*
+SetLockManager serializes the conflicting snapshots, snapshot deletes and copies of all type managers, including those
+added later, with locks
+*/
+func (this *DirectProtectedEntityManager) SetLockManager(locks *LockManager) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.locks = locks
+	for typeName, petm := range this.typeManager {
+		this.typeManager[typeName] = this.decorate(astrolabe.UnwrapProtectedEntityTypeManager(petm))
+	}
+}
+
+/*
+SetTaskManager delays closing the type managers replaced or removed by a reload until the tasks that were
+running at the reload have finished
+*/
+func (this *DirectProtectedEntityManager) SetTaskManager(tasks *TaskManager) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.tasks = tasks
+}
+
 func NewDirectProtectedEntityManagerFromConfigDir(confDirPath string) *DirectProtectedEntityManager {
-	configInfo, err := readConfigFiles(confDirPath)
+	configInfo, invalidFiles, err := readConfigDir(confDirPath)
 	if err != nil {
 		log.Fatalf("Could not read config files from dir %s, err: %v", confDirPath, err)
 	}
-	return NewDirectProtectedEntityManagerFromParamMap(configInfo, nil)
+	for serviceName, err := range invalidFiles {
+		log.Printf("Could not read config for service %s, err: %v", serviceName, err)
+	}
+	return newDirectProtectedEntityManagerFromConfig(configInfo, invalidFiles, nil)
 }
 
+/*
+NewDirectProtectedEntityManagerFromParamMap starts the services in configInfo.  Services that cannot be started are
+logged and reported as failed by the health checks.
+*/
 func NewDirectProtectedEntityManagerFromParamMap(configInfo ConfigInfo, logger logrus.FieldLogger) *DirectProtectedEntityManager {
+	return newDirectProtectedEntityManagerFromConfig(configInfo, map[string]error{}, logger)
+}
+
+func newDirectProtectedEntityManagerFromConfig(configInfo ConfigInfo, invalidFiles map[string]error,
+	logger logrus.FieldLogger) *DirectProtectedEntityManager {
 	petms := make([]astrolabe.ProtectedEntityTypeManager, 0) // No guarantee all configs will be valid, so don't preallocate
-	var err error
+	configured := make(map[string]configuredService)
+	failed := make(map[string]error)
+	for serviceName, err := range invalidFiles {
+		failed[serviceName] = err
+	}
 	if logger == nil {
 		logger = logrus.New()
 	}
 	for serviceName, params := range configInfo.PEConfigs {
-		var curService astrolabe.ProtectedEntityTypeManager
-		switch serviceName {
-		case "ivd":
-			curService, err = ivd.NewIVDProtectedEntityTypeManagerFromConfig(params, configInfo.S3Config, logger)
-		case "k8sns":
-			curService, err = kubernetes.NewKubernetesNamespaceProtectedEntityTypeManagerFromConfig(params, configInfo.S3Config,
-				logger)
-		case "fs":
-			curService, err = fs.NewFSProtectedEntityTypeManagerFromConfig(params, configInfo.S3Config, logger)
-		case "pvc":
-			curService, err = pvc.NewPVCProtectedEntityTypeManagerFromConfig(params, configInfo.S3Config, logger)
-		default:
-			logger.Warnf("Unknown service type, %v", serviceName)
-		}
+		curService, err := newTypeManagerFromConfig(serviceName, params, configInfo.S3Config, logger)
 		if err != nil {
-			logger.Infof("Could not start service %s err=%v", serviceName, err)
+			logger.WithError(err).Errorf("Could not start service %s", serviceName)
+			failed[serviceName] = err
 			continue
 		}
-		if curService != nil {
-			petms = append(petms, curService)
+		petms = append(petms, curService)
+		configured[serviceName] = configuredService{
+			typeName: curService.GetTypeName(),
+			params:   params,
+		}
+	}
+	returnPEM := NewDirectProtectedEntityManager(petms, configInfo.S3Config, logger)
+	returnPEM.configured = configured
+	returnPEM.failed = failed
+	return returnPEM
+}
+
+// The instance name is part of Protected Entity IDs and of the S3 bucket names, so ':' and '/' are not allowed
+var instanceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+/*
+newTypeManagerFromConfig creates the type manager for a service from its configuration file.  A service named
+<type>@<instance> is a named instance of type, only type managers that implement
+astrolabe.NamedInstanceProtectedEntityTypeManager can be named.  A type manager that panics on bad parameters is
+reported as an error.
+*/
+func newTypeManagerFromConfig(serviceName string, params map[string]interface{}, s3Config astrolabe.S3Config,
+	logger logrus.FieldLogger) (curService astrolabe.ProtectedEntityTypeManager, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			curService = nil
+			err = errors.Errorf("invalid configuration: %v", r)
+		}
+	}()
+	peType, instance := astrolabe.SplitServiceName(serviceName)
+	if serviceName != peType && !instanceNamePattern.MatchString(instance) {
+		return nil, errors.Errorf("invalid instance name %q, instance names start with a letter or digit and "+
+			"contain letters, digits, '.', '_' and '-'", instance)
+	}
+	switch peType {
+	case "ivd":
+		curService, err = ivd.NewIVDProtectedEntityTypeManagerFromConfig(params, s3Config, logger)
+	case "k8sns":
+		curService, err = kubernetes.NewKubernetesNamespaceProtectedEntityTypeManagerFromConfig(params, s3Config, logger)
+	case "fs":
+		curService, err = fs.NewFSProtectedEntityTypeManagerFromConfig(params, s3Config, logger)
+	case "pvc":
+		curService, err = pvc.NewPVCProtectedEntityTypeManagerFromConfig(params, s3Config, logger)
+	default:
+		return nil, errors.Errorf("unknown service type %s", peType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if instance != "" {
+		namedService, ok := curService.(astrolabe.NamedInstanceProtectedEntityTypeManager)
+		if !ok {
+			return nil, errors.Errorf("service type %s does not support named instances", peType)
 		}
+		namedService.SetInstanceName(instance)
 	}
-	return NewDirectProtectedEntityManager(petms, configInfo.S3Config, logger)
+	return curService, nil
 }
 
 type ConfigInfo struct {
@@ -108,37 +270,43 @@ func NewConfigInfo(peConfigs map[string]map[string]interface{}, s3Config astrola
 	}
 }
 
-func readConfigFiles(confDirPath string) (ConfigInfo, error) {
+/*
+readConfigDir reads pes/*.pe.json and s3config.json from confDirPath.  Protected entity configuration files that
+cannot be read are returned in invalidFiles by service name and left out of the ConfigInfo.  An error is returned if
+the directories or s3config.json cannot be read.
+*/
+func readConfigDir(confDirPath string) (configInfo ConfigInfo, invalidFiles map[string]error, err error) {
 	configMap := make(map[string]map[string]interface{})
+	invalidFiles = make(map[string]error)
 
 	confDir, err := os.Stat(confDirPath)
 	if err != nil {
-		log.Panicln("Could not stat configuration directory " + confDirPath)
+		return ConfigInfo{}, nil, errors.Wrapf(err, "could not stat configuration directory %s", confDirPath)
 	}
 	if !confDir.Mode().IsDir() {
-		log.Panicln(confDirPath + " is not a directory")
-
+		return ConfigInfo{}, nil, errors.Errorf("%s is not a directory", confDirPath)
 	}
 
-	peDirPath := confDirPath + "/pes"
+	peDirPath := filepath.Join(confDirPath, "pes")
 	peDir, err := os.Stat(peDirPath)
 	if err != nil {
-		log.Panicf("Could not stat protected entity configuration directory %s, err = %v\n", peDirPath, err)
+		return ConfigInfo{}, nil, errors.Wrapf(err, "could not stat protected entity configuration directory %s",
+			peDirPath)
 	}
 	if !peDir.Mode().IsDir() {
-		log.Panicf("%s is not a directory", peDirPath)
-
+		return ConfigInfo{}, nil, errors.Errorf("%s is not a directory", peDirPath)
 	}
 	files, err := ioutil.ReadDir(peDirPath)
 	if err != nil {
-		log.Panicf("Could not list protected entity configuration directory %s, err = %v\n", peDirPath, err)
+		return ConfigInfo{}, nil, errors.Wrapf(err, "could not list protected entity configuration directory %s",
+			peDirPath)
 	}
 	for _, curFile := range files {
 		if !strings.HasPrefix(curFile.Name(), ".") && strings.HasSuffix(curFile.Name(), fileSuffix) {
 			peTypeName := strings.TrimSuffix(curFile.Name(), fileSuffix)
 			peConf, err := readConfigFile(filepath.Join(peDirPath, curFile.Name()))
 			if err != nil {
-				log.Panicln("Could not process conf file " + curFile.Name() + " continuing, err = " + err.Error())
+				invalidFiles[peTypeName] = err
 			} else {
 				configMap[peTypeName] = peConf
 			}
@@ -149,10 +317,10 @@ func readConfigFiles(confDirPath string) (ConfigInfo, error) {
 
 	s3Config, err := readS3ConfigFile(s3ConfFilePath)
 	if err != nil {
-		log.Panicf("Could not read S3 configuration directory %s, err = %v\n", s3ConfFilePath, err)
+		return ConfigInfo{}, nil, err
 	}
 
-	return NewConfigInfo(configMap, *s3Config), nil
+	return NewConfigInfo(configMap, *s3Config), invalidFiles, nil
 }
 
 func readConfigFile(confFile string) (map[string]interface{}, error) {
@@ -192,7 +360,9 @@ func readS3ConfigFile(s3ConfFile string) (*astrolabe.S3Config, error) {
 }
 
 func (this *DirectProtectedEntityManager) GetProtectedEntity(ctx context.Context, id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
+	this.mutex.RLock()
 	typeManager, ok := this.typeManager[id.GetPeType()]
+	this.mutex.RUnlock()
 	if !ok {
 		errMsg := fmt.Sprintf("PeType, %v, is not available", id.GetPeType())
 		this.logger.Error(errMsg)
@@ -202,10 +372,14 @@ func (this *DirectProtectedEntityManager) GetProtectedEntity(ctx context.Context
 }
 
 func (this *DirectProtectedEntityManager) GetProtectedEntityTypeManager(peType string) astrolabe.ProtectedEntityTypeManager {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
 	return this.typeManager[peType]
 }
 
 func (this *DirectProtectedEntityManager) ListEntityTypeManagers() []astrolabe.ProtectedEntityTypeManager {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
 	returnArr := []astrolabe.ProtectedEntityTypeManager{}
 	for _, curPETM := range this.typeManager {
 		returnArr = append(returnArr, curPETM)
@@ -213,9 +387,184 @@ func (this *DirectProtectedEntityManager) ListEntityTypeManagers() []astrolabe.P
 	return returnArr
 }
 
+/*
+GetS3Config returns the S3 configuration the S3 transports of the protected entities point at
+*/
+func (this *DirectProtectedEntityManager) GetS3Config() astrolabe.S3Config {
+	return this.s3Config
+}
+
 func (this *DirectProtectedEntityManager) RegisterExternalProtectedEntityTypeManagers(petms []astrolabe.ProtectedEntityTypeManager) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
 	for _, curPETM := range petms {
 		this.logger.Infof("Registered External ProtectedEntityTypeManager: %v", curPETM.GetTypeName())
-		this.typeManager[curPETM.GetTypeName()] = curPETM
+		this.typeManager[curPETM.GetTypeName()] = this.decorate(curPETM)
 	}
-}
\ No newline at end of file
+}
+
+/*
+Close closes the type managers that hold sessions, those that implement io.Closer, for example the vCenter sessions
+of ivd.  Tasks must have finished, the type managers cannot be used afterwards.
+*/
+func (this *DirectProtectedEntityManager) Close() error {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	var closeErrors []string
+	for typeName, petm := range this.typeManager {
+		closer, ok := astrolabe.UnwrapProtectedEntityTypeManager(petm).(io.Closer)
+		if !ok {
+			continue
+		}
+		if err := closer.Close(); err != nil {
+			closeErrors = append(closeErrors, fmt.Sprintf("%s: %v", typeName, err))
+		}
+	}
+	if len(closeErrors) > 0 {
+		sort.Strings(closeErrors)
+		return errors.Errorf("could not close services: %s", strings.Join(closeErrors, ", "))
+	}
+	return nil
+}
+
+/*
+ReloadResult lists the services changed by a reload, by the name of their configuration file
+*/
+type ReloadResult struct {
+	Added     []string
+	Replaced  []string
+	Removed   []string
+	Unchanged []string
+	// Services whose configuration could not be read or whose type manager could not be created.  A type manager
+	// that was already running for the service is kept.
+	Failed map[string]error
+}
+
+/*
+ReloadFromConfigDir re-reads the configuration directory and applies the protected entity configuration with Reload.
+Nothing is changed if the directory or s3config.json cannot be read.
+*/
+func (this *DirectProtectedEntityManager) ReloadFromConfigDir(confDirPath string) (ReloadResult, error) {
+	configInfo, invalidFiles, err := readConfigDir(confDirPath)
+	if err != nil {
+		return ReloadResult{}, err
+	}
+	return this.reload(configInfo, invalidFiles), nil
+}
+
+/*
+Reload adds, replaces and removes the services created from configuration files to match configInfo.  Services whose
+configuration has not changed keep their type manager, and tasks already running on a replaced or removed type
+manager carry on with it.  Replaced and removed type managers that implement io.Closer are closed once those tasks
+have finished, see SetTaskManager.  The S3 configuration cannot be changed without a restart, the type managers are created
+with the S3 configuration the manager started with.
+*/
+func (this *DirectProtectedEntityManager) Reload(configInfo ConfigInfo) ReloadResult {
+	return this.reload(configInfo, map[string]error{})
+}
+
+func (this *DirectProtectedEntityManager) reload(configInfo ConfigInfo, invalidFiles map[string]error) ReloadResult {
+	result := ReloadResult{
+		Failed: map[string]error{},
+	}
+	this.mutex.RLock()
+	s3Config := this.s3Config
+	configured := make(map[string]configuredService, len(this.configured))
+	for serviceName, service := range this.configured {
+		configured[serviceName] = service
+	}
+	this.mutex.RUnlock()
+	if !reflect.DeepEqual(configInfo.S3Config, s3Config) {
+		this.logger.Warn("s3config.json has changed, restart the server to apply it")
+	}
+
+	// Type managers are created without the lock held, creating one may connect to a remote service
+	newServices := map[string]astrolabe.ProtectedEntityTypeManager{}
+	for serviceName, params := range configInfo.PEConfigs {
+		current, exists := configured[serviceName]
+		if exists && reflect.DeepEqual(current.params, params) {
+			result.Unchanged = append(result.Unchanged, serviceName)
+			continue
+		}
+		curService, err := newTypeManagerFromConfig(serviceName, params, s3Config, this.logger)
+		if err != nil {
+			result.Failed[serviceName] = err
+			continue
+		}
+		newServices[serviceName] = curService
+	}
+	for serviceName, err := range invalidFiles {
+		result.Failed[serviceName] = err
+	}
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	retired := []astrolabe.ProtectedEntityTypeManager{}
+	for serviceName, service := range this.configured {
+		_, inConfig := configInfo.PEConfigs[serviceName]
+		_, failed := result.Failed[serviceName]
+		if !inConfig && !failed {
+			retired = append(retired, this.typeManager[service.typeName])
+			delete(this.typeManager, service.typeName)
+			delete(this.configured, serviceName)
+			result.Removed = append(result.Removed, serviceName)
+		}
+	}
+	for serviceName, curService := range newServices {
+		if current, exists := this.configured[serviceName]; exists {
+			retired = append(retired, this.typeManager[current.typeName])
+			delete(this.typeManager, current.typeName)
+			result.Replaced = append(result.Replaced, serviceName)
+		} else {
+			result.Added = append(result.Added, serviceName)
+		}
+		this.typeManager[curService.GetTypeName()] = this.wrapTypeManager(curService)
+		this.configured[serviceName] = configuredService{
+			typeName: curService.GetTypeName(),
+			params:   configInfo.PEConfigs[serviceName],
+		}
+	}
+	// A service that failed before and has not been fixed fails again, the others have been fixed or removed
+	this.failed = make(map[string]error, len(result.Failed))
+	for serviceName, err := range result.Failed {
+		this.failed[serviceName] = err
+	}
+	if len(retired) > 0 {
+		running := []astrolabe.Task{}
+		if this.tasks != nil {
+			running = this.tasks.runningTasks()
+		}
+		go this.closeRetired(retired, running)
+	}
+	for _, names := range [][]string{result.Added, result.Replaced, result.Removed, result.Unchanged} {
+		sort.Strings(names)
+	}
+	this.logger.Infof("Reloaded configuration, added %v, replaced %v, removed %v, %d unchanged, %d failed",
+		result.Added, result.Replaced, result.Removed, len(result.Unchanged), len(result.Failed))
+	for serviceName, err := range result.Failed {
+		this.logger.WithError(err).Errorf("Could not load service %s", serviceName)
+	}
+	return result
+}
+
+/*
+closeRetired waits for the tasks in running to finish and then closes the type managers in retired that implement
+io.Closer
+*/
+func (this *DirectProtectedEntityManager) closeRetired(retired []astrolabe.ProtectedEntityTypeManager,
+	running []astrolabe.Task) {
+	for _, task := range running {
+		for task.GetFinishedTime().IsZero() {
+			time.Sleep(nexusPollInterval)
+		}
+	}
+	for _, petm := range retired {
+		closer, ok := astrolabe.UnwrapProtectedEntityTypeManager(petm).(io.Closer)
+		if !ok {
+			continue
+		}
+		if err := closer.Close(); err != nil {
+			this.logger.WithError(err).Errorf("Could not close replaced service %s", petm.GetTypeName())
+		}
+	}
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"gotest.tools/assert"
+)
+
+func writeTestConfigFile(t *testing.T, path string, contents string) {
+	err := ioutil.WriteFile(path, []byte(contents), 0600)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+}
+
+func TestReloadFromConfigDir(t *testing.T) {
+	confDir, err := ioutil.TempDir("", "astrolabe-conf")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer os.RemoveAll(confDir)
+	peDir := filepath.Join(confDir, "pes")
+	err = os.Mkdir(peDir, 0700)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	writeTestConfigFile(t, filepath.Join(confDir, "s3config.json"), `{"host": "127.0.0.1", "port": 1323}`)
+	fsConfigPath := filepath.Join(peDir, "fs"+fileSuffix)
+	writeTestConfigFile(t, fsConfigPath, `{"root": "`+confDir+`"}`)
+
+	configInfo, invalidFiles, err := readConfigDir(confDir)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 0, len(invalidFiles))
+	pem := NewDirectProtectedEntityManagerFromParamMap(configInfo, logrus.New())
+	pem.RegisterExternalProtectedEntityTypeManagers([]astrolabe.ProtectedEntityTypeManager{
+		newMemoryProtectedEntityTypeManager("mem")})
+	fsPETM := pem.GetProtectedEntityTypeManager("fs")
+	assert.Assert(t, fsPETM != nil)
+
+	// An unchanged configuration keeps the running type manager
+	result, err := pem.ReloadFromConfigDir(confDir)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{"fs"}, result.Unchanged)
+	assert.Equal(t, fsPETM, pem.GetProtectedEntityTypeManager("fs"))
+
+	writeTestConfigFile(t, fsConfigPath, `{"root": "`+peDir+`"}`)
+	result, err = pem.ReloadFromConfigDir(confDir)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{"fs"}, result.Replaced)
+	assert.Assert(t, fsPETM != pem.GetProtectedEntityTypeManager("fs"))
+	fsPETM = pem.GetProtectedEntityTypeManager("fs")
+
+	// Invalid files and type managers that cannot be created are reported and the running type manager is kept
+	writeTestConfigFile(t, fsConfigPath, `{"root": `)
+	result, err = pem.ReloadFromConfigDir(confDir)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, result.Failed["fs"] != nil)
+	assert.Equal(t, fsPETM, pem.GetProtectedEntityTypeManager("fs"))
+	writeTestConfigFile(t, fsConfigPath, `{"root": 1}`)
+	result, err = pem.ReloadFromConfigDir(confDir)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.ErrorContains(t, result.Failed["fs"], "invalid configuration")
+	assert.Equal(t, fsPETM, pem.GetProtectedEntityTypeManager("fs"))
+	writeTestConfigFile(t, filepath.Join(peDir, "unknown"+fileSuffix), `{}`)
+	result, err = pem.ReloadFromConfigDir(confDir)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.ErrorContains(t, result.Failed["unknown"], "unknown service type")
+
+	// Removing the file removes the service, type managers that were registered directly are kept
+	err = os.Remove(fsConfigPath)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	result, err = pem.ReloadFromConfigDir(confDir)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{"fs"}, result.Removed)
+	assert.Assert(t, pem.GetProtectedEntityTypeManager("fs") == nil)
+	assert.Assert(t, pem.GetProtectedEntityTypeManager("mem") != nil)
+
+	writeTestConfigFile(t, fsConfigPath, `{"root": "`+confDir+`"}`)
+	result, err = pem.ReloadFromConfigDir(confDir)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{"fs"}, result.Added)
+	assert.Assert(t, pem.GetProtectedEntityTypeManager("fs") != nil)
+
+	// A configuration directory that cannot be read changes nothing
+	_, err = pem.ReloadFromConfigDir(filepath.Join(confDir, "missing"))
+	assert.ErrorContains(t, err, "could not stat configuration directory")
+	assert.Assert(t, pem.GetProtectedEntityTypeManager("fs") != nil)
+}
+
+func TestNamedInstances(t *testing.T) {
+	ctx := context.Background()
+	confDir, err := ioutil.TempDir("", "astrolabe-conf")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer os.RemoveAll(confDir)
+	for _, dir := range []string{"pes", "east", "west"} {
+		err = os.Mkdir(filepath.Join(confDir, dir), 0700)
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+	}
+	err = os.Mkdir(filepath.Join(confDir, "west", "west-entity"), 0700)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	writeTestConfigFile(t, filepath.Join(confDir, "s3config.json"), `{"host": "127.0.0.1", "port": 1323}`)
+	peDir := filepath.Join(confDir, "pes")
+	writeTestConfigFile(t, filepath.Join(peDir, "fs@east"+fileSuffix), `{"root": "`+filepath.Join(confDir, "east")+`"}`)
+	writeTestConfigFile(t, filepath.Join(peDir, "fs@west"+fileSuffix), `{"root": "`+filepath.Join(confDir, "west")+`"}`)
+	writeTestConfigFile(t, filepath.Join(peDir, "fs@bad:name"+fileSuffix), `{"root": "`+confDir+`"}`)
+
+	configInfo, _, err := readConfigDir(confDir)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	pem := NewDirectProtectedEntityManagerFromParamMap(configInfo, logrus.New())
+	typeNames := []string{}
+	for _, curPETM := range pem.ListEntityTypeManagers() {
+		typeNames = append(typeNames, curPETM.GetTypeName())
+	}
+	sort.Strings(typeNames)
+	assert.DeepEqual(t, []string{"fs@east", "fs@west"}, typeNames)
+
+	westIDs, err := pem.GetProtectedEntityTypeManager("fs@west").GetProtectedEntities(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{"fs@west:west-entity"}, []string{westIDs[0].String()})
+	eastIDs, err := pem.GetProtectedEntityTypeManager("fs@east").GetProtectedEntities(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 0, len(eastIDs))
+	westPE, err := pem.GetProtectedEntity(ctx, westIDs[0])
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, westIDs[0], westPE.GetID())
+
+	_, err = newTypeManagerFromConfig("fs@bad:name", map[string]interface{}{"root": confDir}, astrolabe.S3Config{},
+		logrus.New())
+	assert.ErrorContains(t, err, "invalid instance name")
+}
+
+/*
+closingProtectedEntityTypeManager closes closed when it is closed
+*/
+type closingProtectedEntityTypeManager struct {
+	*memoryProtectedEntityTypeManager
+	closed chan struct{}
+}
+
+func (this *closingProtectedEntityTypeManager) Close() error {
+	close(this.closed)
+	return nil
+}
+
+func TestReloadClosesRemovedServices(t *testing.T) {
+	petm := &closingProtectedEntityTypeManager{
+		memoryProtectedEntityTypeManager: newMemoryProtectedEntityTypeManager("mem"),
+		closed:                           make(chan struct{}),
+	}
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	pem.configured["mem"] = configuredService{typeName: "mem", params: map[string]interface{}{}}
+	tm := NewTaskManager()
+	pem.SetTaskManager(tm)
+	release := make(chan struct{})
+	tm.AddTask(startAsyncTask(context.Background(), "running", func(ctx context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	}))
+
+	result := pem.Reload(ConfigInfo{})
+	assert.DeepEqual(t, []string{"mem"}, result.Removed)
+	// The task that was running at the reload may still be using the type manager
+	select {
+	case <-petm.closed:
+		t.Fatal("Type manager was closed while a task was running")
+	case <-time.After(2 * nexusPollInterval):
+	}
+	close(release)
+	select {
+	case <-petm.closed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Type manager was not closed after the task finished")
+	}
+}
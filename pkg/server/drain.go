@@ -0,0 +1,114 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+// The Retry-After header of the requests rejected while draining
+const drainRetryAfterSeconds = "30"
+
+/*
+Drainer coordinates a graceful shutdown.  Once draining starts, the server is reported as not ready, new mutating
+requests are rejected with 503 Service Unavailable (Unavailable for gRPC) and the running tasks are given time to
+finish.  Reads, including the task APIs, are still served so that clients can follow their tasks.  A nil Drainer never
+drains.
+*/
+type Drainer struct {
+	draining int32
+	tm       *TaskManager
+	logger   logrus.FieldLogger
+}
+
+func NewDrainer(tm *TaskManager, logger logrus.FieldLogger) *Drainer {
+	return &Drainer{
+		tm:     tm,
+		logger: logger,
+	}
+}
+
+func (this *Drainer) IsDraining() bool {
+	if this == nil {
+		return false
+	}
+	return atomic.LoadInt32(&this.draining) != 0
+}
+
+/*
+Drain starts draining and waits up to timeout for the running tasks to finish.  The tasks still running after timeout
+are cancelled, which lets them clean up, for example abort partial uploads, and are given up to cancelWait to finish.
+*/
+func (this *Drainer) Drain(timeout time.Duration, cancelWait time.Duration) error {
+	atomic.StoreInt32(&this.draining, 1)
+	this.logger.Infof("Draining, waiting up to %v for the running tasks to finish", timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cancelled, err := this.tm.Drain(ctx, cancelWait)
+	for _, taskID := range cancelled {
+		this.logger.Warnf("Cancelled task %s while draining", taskID.String())
+	}
+	if err != nil {
+		return err
+	}
+	this.logger.Infof("Drained")
+	return nil
+}
+
+/*
+isMutatingOperation is false for the authorization operations that only read, including "" for calls that only need
+an authenticated identity
+*/
+func isMutatingOperation(operation string) bool {
+	switch operation {
+	case "", ReadOperation, AuditOperation:
+		return false
+	}
+	return true
+}
+
+func (this *Drainer) writeDraining(w http.ResponseWriter) {
+	payload, _ := json.Marshal(errorPayload(http.StatusServiceUnavailable, "the server is shutting down"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", drainRetryAfterSeconds)
+	w.WriteHeader(http.StatusServiceUnavailable)
+	w.Write(payload)
+}
+
+/*
+OpenAPIMiddleware rejects the mutating OpenAPI calls while draining.  It is used in the builder passed to api.Serve.
+*/
+func (this *Drainer) OpenAPIMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if this.IsDraining() {
+			if route := middleware.MatchedRouteFrom(r); route != nil && route.Operation != nil {
+				if _, mutating := auditedOperations[route.Operation.ID]; mutating {
+					this.writeDraining(w)
+					return
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"github.com/vmware-tanzu/astrolabe/pkg/grpcapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDrain(t *testing.T) {
+	ctx := context.Background()
+	petm := newMemoryProtectedEntityTypeManager("mem")
+	peid := petm.addEntity("a", nil)
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	tm := NewTaskManager()
+	drainer := NewDrainer(tm, logrus.New())
+	grpcHandler := NewGRPCAstrolabeHandler(pem, tm)
+	grpcHandler.SetDrainer(drainer)
+	conn, stop := startTestGRPCServer(t, grpcHandler)
+	defer stop()
+	grpcClient := grpcapi.NewAstrolabeClient(conn)
+	s3Service := NewServiceS3(pem, tm, "s3", logrus.New())
+	s3Service.SetDrainer(drainer)
+	healthHandler := NewHealthHandler(pem, drainer).Handler(http.NotFoundHandler())
+
+	recorder := httptest.NewRecorder()
+	healthHandler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// One task finishes on its own, the other runs until it is cancelled and then cleans up
+	finishes := startAsyncTask(ctx, "finishes", func(ctx context.Context) (interface{}, error) {
+		time.Sleep(50 * time.Millisecond)
+		return "done", nil
+	})
+	tm.AddTask(finishes)
+	var cleanedUp int32
+	cancelled := startAsyncTask(ctx, "cancelled", func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		atomic.StoreInt32(&cleanedUp, 1)
+		return nil, ctx.Err()
+	})
+	tm.AddTask(cancelled)
+
+	err := drainer.Drain(500*time.Millisecond, 10*time.Second)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, astrolabe.Success, finishes.GetStatus())
+	assert.Equal(t, astrolabe.Cancelled, cancelled.GetStatus())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&cleanedUp))
+
+	recorder = httptest.NewRecorder()
+	healthHandler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	// Reads are served, mutating calls are not
+	_, err = grpcClient.ListServices(ctx, &grpcapi.Empty{})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, err = grpcClient.CreateSnapshot(ctx, &grpcapi.CreateSnapshotRequest{
+		Service: "mem",
+		Id:      peid.String(),
+	})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	recorder = httptest.NewRecorder()
+	s3Service.Handler(http.NotFoundHandler()).ServeHTTP(recorder, httptest.NewRequest(http.MethodPut,
+		"/s3/"+S3CopyBucket+"/a.zip", strings.NewReader("zip")))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, drainRetryAfterSeconds, recorder.Header().Get("Retry-After"))
+}
+
+func TestDrainTaskIgnoresCancel(t *testing.T) {
+	tm := NewTaskManager()
+	release := make(chan struct{})
+	defer close(release)
+	tm.AddTask(startAsyncTask(context.Background(), "stuck", func(ctx context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	cancelled, err := tm.Drain(ctx, 100*time.Millisecond)
+	assert.Equal(t, 1, len(cancelled))
+	assert.Assert(t, err != nil, "Drain did not report the task that did not finish")
+}
+
+// uncancellableTask fails to cancel and finishes when released
+type uncancellableTask struct {
+	*asyncTask
+}
+
+func (this uncancellableTask) Cancel() error {
+	return errors.New("cannot cancel")
+}
+
+func TestDrainCancelError(t *testing.T) {
+	tm := NewTaskManager()
+	release := make(chan struct{})
+	tm.AddTask(uncancellableTask{startAsyncTask(context.Background(), "uncancellable",
+		func(ctx context.Context) (interface{}, error) {
+			<-release
+			return nil, nil
+		})})
+	cancellable := startAsyncTask(context.Background(), "cancellable", func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		close(release)
+		return nil, ctx.Err()
+	})
+	tm.AddTask(cancellable)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	cancelled, err := tm.Drain(ctx, 10*time.Second)
+	assert.Equal(t, 1, len(cancelled))
+	assert.Equal(t, cancellable.GetID().String(), cancelled[0].String())
+	assert.Equal(t, astrolabe.Cancelled, cancellable.GetStatus())
+	assert.Assert(t, err != nil, "Drain did not report the task that could not be cancelled")
+	assert.Assert(t, strings.Contains(err.Error(), "cannot cancel"), err.Error())
+}
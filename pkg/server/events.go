@@ -0,0 +1,324 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+The event bus carries lifecycle events to subscribers such as the webhook notifier.  Task events are published by the
+TaskManager, snapshot and copy events by a decorator on the type managers of the DirectProtectedEntityManager, so that
+they are published whichever API started the operation.
+*/
+const (
+	// Task events are task.<status>, task.running when the task is added and then task.success, task.failed or
+	// task.cancelled when it finishes
+	EventTaskPrefix      = "task."
+	EventSnapshotCreated = "snapshot.created"
+	EventSnapshotDeleted = "snapshot.deleted"
+	// Published when a copy succeeds or fails
+	EventCopyFinished = "copy.finished"
+)
+
+// How often a task that cannot signal that it is done is checked
+const taskEventPollInterval = time.Second
+
+type Event struct {
+	ID   string    `json:"id"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	// The service of the snapshot or copy
+	Service string `json:"service,omitempty"`
+	// The snapshot, the new Protected Entity of a copy or the Protected Entity created by a task
+	ProtectedEntityID string `json:"protectedEntityID,omitempty"`
+	// The source of a copy
+	SourceID string `json:"sourceID,omitempty"`
+	TaskID   string `json:"taskID,omitempty"`
+	// The task status, or success or failed for a copy
+	Status  string `json:"status,omitempty"`
+	Details string `json:"details,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// The bytes read from the source of a copy.  They are only counted for copies from a Protected Entity, a copy from
+	// a Protected Entity info reads the data transports itself.
+	DataBytes     *int64 `json:"dataBytes,omitempty"`
+	MetadataBytes *int64 `json:"metadataBytes,omitempty"`
+}
+
+/*
+EventBus passes the published events to its subscribers.  A nil EventBus drops the events.
+*/
+type EventBus struct {
+	mutex       sync.RWMutex
+	subscribers []func(event Event)
+	logger      logrus.FieldLogger
+}
+
+func NewEventBus(logger logrus.FieldLogger) *EventBus {
+	return &EventBus{
+		logger: logger,
+	}
+}
+
+/*
+Subscribe calls handler for every event published after it.  Handlers are called on the publishing goroutine, in
+the order they subscribed, and must not block.
+*/
+func (this *EventBus) Subscribe(handler func(event Event)) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.subscribers = append(this.subscribers, handler)
+}
+
+/*
+Publish fills in the ID and, if it is not set, the time of event and passes it to the subscribers
+*/
+func (this *EventBus) Publish(event Event) {
+	if this == nil {
+		return
+	}
+	event.ID = uuid.New().String()
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	this.logger.Debugf("Publishing event %s %s", event.Type, event.ID)
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	for _, handler := range this.subscribers {
+		handler(event)
+	}
+}
+
+/*
+SetEventBus publishes the task events to events.  It must be called before tasks are added.
+*/
+func (this *TaskManager) SetEventBus(events *EventBus) {
+	this.events = events
+}
+
+/*
+doneTask is implemented by tasks that can signal that they are done, other tasks are polled
+*/
+type doneTask interface {
+	Done() <-chan struct{}
+}
+
+/*
+publishTaskEvents publishes task.running for a task that was just added and the finished status once it finishes
+*/
+func (this *TaskManager) publishTaskEvents(task astrolabe.Task) {
+	this.events.Publish(Event{
+		Type:    EventTaskPrefix + astrolabe.Running.String(),
+		TaskID:  task.GetID().String(),
+		Status:  astrolabe.Running.String(),
+		Details: task.GetDetails(),
+	})
+	go func() {
+		if doneTask, ok := task.(doneTask); ok {
+			<-doneTask.Done()
+		} else {
+			for task.GetFinishedTime().IsZero() {
+				time.Sleep(taskEventPollInterval)
+			}
+		}
+		taskInfo := task.GetModelTaskInfo()
+		event := Event{
+			Type:    EventTaskPrefix + *taskInfo.Status,
+			TaskID:  task.GetID().String(),
+			Status:  *taskInfo.Status,
+			Details: taskInfo.Details,
+		}
+		if peid, ok := taskInfo.Result.(models.ProtectedEntityID); ok {
+			event.ProtectedEntityID = string(peid)
+		}
+		this.events.Publish(event)
+	}()
+}
+
+/*
+eventsProtectedEntityTypeManager decorates a type manager to publish the copy events.  The protected entities it
+returns are decorated to publish the snapshot events.
+*/
+type eventsProtectedEntityTypeManager struct {
+	astrolabe.ProtectedEntityTypeManager
+	events *EventBus
+}
+
+func newEventsProtectedEntityTypeManager(petm astrolabe.ProtectedEntityTypeManager,
+	events *EventBus) astrolabe.ProtectedEntityTypeManager {
+	return &eventsProtectedEntityTypeManager{
+		ProtectedEntityTypeManager: petm,
+		events:                     events,
+	}
+}
+
+func (this *eventsProtectedEntityTypeManager) Unwrap() astrolabe.ProtectedEntityTypeManager {
+	return this.ProtectedEntityTypeManager
+}
+
+func (this *eventsProtectedEntityTypeManager) GetProtectedEntity(ctx context.Context,
+	id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
+	pe, err := this.ProtectedEntityTypeManager.GetProtectedEntity(ctx, id)
+	return this.wrap(pe), err
+}
+
+func (this *eventsProtectedEntityTypeManager) Copy(ctx context.Context, pe astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	source := &countingProtectedEntity{
+		ProtectedEntity: pe,
+	}
+	newPE, err := this.ProtectedEntityTypeManager.Copy(ctx, source, params, options)
+	event := this.copyEvent(pe.GetID(), newPE, err)
+	dataBytes := atomic.LoadInt64(&source.dataBytes)
+	metadataBytes := atomic.LoadInt64(&source.metadataBytes)
+	event.DataBytes = &dataBytes
+	event.MetadataBytes = &metadataBytes
+	this.events.Publish(event)
+	return this.wrap(newPE), err
+}
+
+func (this *eventsProtectedEntityTypeManager) CopyFromInfo(ctx context.Context, info astrolabe.ProtectedEntityInfo,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	newPE, err := this.ProtectedEntityTypeManager.CopyFromInfo(ctx, info, params, options)
+	this.events.Publish(this.copyEvent(info.GetID(), newPE, err))
+	return this.wrap(newPE), err
+}
+
+func (this *eventsProtectedEntityTypeManager) copyEvent(sourceID astrolabe.ProtectedEntityID,
+	newPE astrolabe.ProtectedEntity, err error) Event {
+	event := Event{
+		Type:     EventCopyFinished,
+		Service:  this.GetTypeName(),
+		SourceID: sourceID.String(),
+		Status:   astrolabe.Success.String(),
+	}
+	if err != nil {
+		event.Status = astrolabe.Failed.String()
+		event.Error = err.Error()
+	} else if newPE != nil {
+		event.ProtectedEntityID = newPE.GetID().String()
+	}
+	return event
+}
+
+func (this *eventsProtectedEntityTypeManager) wrap(pe astrolabe.ProtectedEntity) astrolabe.ProtectedEntity {
+	if pe == nil {
+		return nil
+	}
+	return &eventsProtectedEntity{
+		ProtectedEntity: pe,
+		typeName:        this.GetTypeName(),
+		events:          this.events,
+	}
+}
+
+/*
+eventsProtectedEntity publishes the snapshot events of a protected entity returned by a type manager
+*/
+type eventsProtectedEntity struct {
+	astrolabe.ProtectedEntity
+	typeName string
+	events   *EventBus
+}
+
+func (this *eventsProtectedEntity) Snapshot(ctx context.Context,
+	params map[string]map[string]interface{}) (astrolabe.ProtectedEntitySnapshotID, error) {
+	snapshotID, err := this.ProtectedEntity.Snapshot(ctx, params)
+	if err == nil {
+		this.events.Publish(Event{
+			Type:              EventSnapshotCreated,
+			Service:           this.typeName,
+			ProtectedEntityID: this.GetID().IDWithSnapshot(snapshotID).String(),
+		})
+	}
+	return snapshotID, err
+}
+
+func (this *eventsProtectedEntity) DeleteSnapshot(ctx context.Context, snapshotToDelete astrolabe.ProtectedEntitySnapshotID,
+	params map[string]map[string]interface{}) (bool, error) {
+	deleted, err := this.ProtectedEntity.DeleteSnapshot(ctx, snapshotToDelete, params)
+	if err == nil && deleted {
+		this.events.Publish(Event{
+			Type:              EventSnapshotDeleted,
+			Service:           this.typeName,
+			ProtectedEntityID: this.GetID().IDWithSnapshot(snapshotToDelete).String(),
+		})
+	}
+	return deleted, err
+}
+
+/*
+countingProtectedEntity counts the bytes a type manager reads from the source of a copy
+*/
+type countingProtectedEntity struct {
+	astrolabe.ProtectedEntity
+	dataBytes     int64
+	metadataBytes int64
+}
+
+func (this *countingProtectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	return countReader(&this.dataBytes)(this.ProtectedEntity.GetDataReader(ctx))
+}
+
+func (this *countingProtectedEntity) GetMetadataReader(ctx context.Context) (io.ReadCloser, error) {
+	return countReader(&this.metadataBytes)(this.ProtectedEntity.GetMetadataReader(ctx))
+}
+
+func countReader(count *int64) func(reader io.ReadCloser, err error) (io.ReadCloser, error) {
+	return func(reader io.ReadCloser, err error) (io.ReadCloser, error) {
+		if reader == nil || err != nil {
+			return reader, err
+		}
+		countingReader := &countingReadCloser{
+			ReadCloser: reader,
+			count:      count,
+		}
+		// Keep the reader seekable so that copies can still skip over holes
+		if seeker, ok := reader.(io.Seeker); ok {
+			return &countingReadSeekCloser{
+				countingReadCloser: countingReader,
+				Seeker:             seeker,
+			}, nil
+		}
+		return countingReader, nil
+	}
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	count *int64
+}
+
+func (this *countingReadCloser) Read(p []byte) (int, error) {
+	bytesRead, err := this.ReadCloser.Read(p)
+	atomic.AddInt64(this.count, int64(bytesRead))
+	return bytesRead, err
+}
+
+type countingReadSeekCloser struct {
+	*countingReadCloser
+	io.Seeker
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+eventCollector records the events published on an EventBus
+*/
+type eventCollector struct {
+	mutex  sync.Mutex
+	events []Event
+}
+
+func newTestEventBus() (*EventBus, *eventCollector) {
+	bus := NewEventBus(logrus.New())
+	collector := &eventCollector{}
+	bus.Subscribe(func(event Event) {
+		collector.mutex.Lock()
+		defer collector.mutex.Unlock()
+		collector.events = append(collector.events, event)
+	})
+	return bus, collector
+}
+
+/*
+Waits for count events and returns them
+*/
+func (this *eventCollector) waitForEvents(t *testing.T, count int) []Event {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		this.mutex.Lock()
+		if len(this.events) >= count {
+			events := make([]Event, len(this.events))
+			copy(events, this.events)
+			this.mutex.Unlock()
+			return events
+		}
+		this.mutex.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Did not get %d events", count)
+	return nil
+}
+
+func TestSnapshotAndCopyEvents(t *testing.T) {
+	ctx := context.Background()
+	petm := newMemoryProtectedEntityTypeManager("mem")
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	bus, collector := newTestEventBus()
+	pem.SetEventBus(bus)
+
+	peid := petm.addEntity("a", nil)
+	petm.setData("a", []byte("0123456789"), []byte("md"))
+	pe, err := pem.GetProtectedEntity(ctx, peid)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	snapshotID, err := pe.Snapshot(ctx, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	petm.failSnapshot["a"] = true
+	_, err = pe.Snapshot(ctx, nil)
+	assert.Assert(t, err != nil, "snapshot should fail")
+	deleted, err := pe.DeleteSnapshot(ctx, snapshotID, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, deleted)
+	// Deleting again deletes nothing and publishes nothing
+	_, err = pe.DeleteSnapshot(ctx, snapshotID, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	wrappedPETM := pem.GetProtectedEntityTypeManager("mem")
+	newPE, err := wrappedPETM.Copy(ctx, pe, nil, astrolabe.AllocateNewObject)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	newInfo, err := newPE.GetInfo(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, err = wrappedPETM.CopyFromInfo(ctx, newInfo, nil, astrolabe.AllocateObjectWithID)
+	assert.Assert(t, err != nil, "copy over an existing entity should fail")
+
+	events := collector.waitForEvents(t, 4)
+	assert.Equal(t, 4, len(events))
+	assert.Equal(t, EventSnapshotCreated, events[0].Type)
+	assert.Equal(t, "mem", events[0].Service)
+	assert.Equal(t, peid.IDWithSnapshot(snapshotID).String(), events[0].ProtectedEntityID)
+	assert.Assert(t, events[0].ID != "" && !events[0].Time.IsZero())
+	assert.Equal(t, EventSnapshotDeleted, events[1].Type)
+	assert.Equal(t, peid.IDWithSnapshot(snapshotID).String(), events[1].ProtectedEntityID)
+
+	assert.Equal(t, EventCopyFinished, events[2].Type)
+	assert.Equal(t, astrolabe.Success.String(), events[2].Status)
+	assert.Equal(t, peid.String(), events[2].SourceID)
+	assert.Equal(t, newPE.GetID().String(), events[2].ProtectedEntityID)
+	assert.Equal(t, int64(10), *events[2].DataBytes)
+	assert.Equal(t, int64(2), *events[2].MetadataBytes)
+
+	assert.Equal(t, EventCopyFinished, events[3].Type)
+	assert.Equal(t, astrolabe.Failed.String(), events[3].Status)
+	assert.Equal(t, newPE.GetID().String(), events[3].SourceID)
+	assert.Assert(t, events[3].Error != "")
+	assert.Assert(t, events[3].DataBytes == nil)
+}
+
+func TestTaskEvents(t *testing.T) {
+	tm := NewTaskManager()
+	bus, collector := newTestEventBus()
+	tm.SetEventBus(bus)
+
+	release := make(chan struct{})
+	succeeded := startAsyncTask(context.Background(), "succeeds", func(ctx context.Context) (interface{}, error) {
+		<-release
+		return "done", nil
+	})
+	tm.AddTask(succeeded)
+	failed := startAsyncTask(context.Background(), "fails", func(ctx context.Context) (interface{}, error) {
+		<-release
+		return nil, errors.New("task failed")
+	})
+	tm.AddTask(failed)
+	events := collector.waitForEvents(t, 2)
+	assert.Equal(t, EventTaskPrefix+"running", events[0].Type)
+	assert.Equal(t, succeeded.GetID().String(), events[0].TaskID)
+	assert.Equal(t, "succeeds", events[0].Details)
+	assert.Equal(t, EventTaskPrefix+"running", events[1].Type)
+
+	close(release)
+	events = collector.waitForEvents(t, 4)
+	finished := map[string]Event{}
+	for _, event := range events[2:] {
+		finished[event.TaskID] = event
+	}
+	assert.Equal(t, EventTaskPrefix+"success", finished[succeeded.GetID().String()].Type)
+	assert.Equal(t, "success", finished[succeeded.GetID().String()].Status)
+	assert.Equal(t, EventTaskPrefix+"failed", finished[failed.GetID().String()].Type)
+}
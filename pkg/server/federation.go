@@ -0,0 +1,204 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	restClient "github.com/vmware-tanzu/astrolabe/gen/client"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	astrolabeClient "github.com/vmware-tanzu/astrolabe/pkg/client"
+)
+
+const federationConfigFile = "servers.json"
+
+/*
+FederationConfig names this server and the other astrolabe servers that serve components of its Protected Entities,
+for example the vCenter server that serves the IVDs of the PVCs of a cluster.  The names are used as the server of
+component specs and must be the same in the servers.json of every server.
+*/
+type FederationConfig struct {
+	// The name of this server, components on this server are reported without a server
+	Name    string                        `json:"name"`
+	Servers map[string]RemoteServerConfig `json:"servers"`
+}
+
+type RemoteServerConfig struct {
+	// host[:port] of the API listener of the server
+	Host string `json:"host"`
+	// Use http instead of https
+	Insecure bool `json:"insecure,omitempty"`
+	// Sent as a bearer token if the server authenticates requests
+	BearerToken string `json:"bearerToken,omitempty"`
+}
+
+/*
+Federation resolves components on other astrolabe servers through the REST API.  The client for a server is created
+when it is first used, a server that cannot be reached is retried on the next use.
+*/
+type Federation struct {
+	name     string
+	servers  map[string]RemoteServerConfig
+	logger   logrus.FieldLogger
+	mutex    sync.Mutex
+	managers map[string]*astrolabeClient.ClientProtectedEntityManager
+	// Components of remote Protected Entities that are on other servers are resolved through resolver
+	resolver astrolabe.ComponentResolver
+}
+
+/*
+NewFederationFromConfigDir reads servers.json from confDirPath.  If there is no servers.json, nil is returned and
+components can only be resolved locally.
+*/
+func NewFederationFromConfigDir(confDirPath string, logger logrus.FieldLogger) (*Federation, error) {
+	configPath := filepath.Join(confDirPath, federationConfigFile)
+	configBytes, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "could not read %s", configPath)
+	}
+	config := FederationConfig{}
+	err = json.Unmarshal(configBytes, &config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse %s", configPath)
+	}
+	return NewFederation(config, logger)
+}
+
+func NewFederation(config FederationConfig, logger logrus.FieldLogger) (*Federation, error) {
+	for serverName, serverConfig := range config.Servers {
+		if serverName == "" || serverName == config.Name {
+			return nil, errors.Errorf("invalid server name %q", serverName)
+		}
+		if serverConfig.Host == "" {
+			return nil, errors.Errorf("server %s has no host", serverName)
+		}
+	}
+	return &Federation{
+		name:     config.Name,
+		servers:  config.Servers,
+		logger:   logger,
+		managers: map[string]*astrolabeClient.ClientProtectedEntityManager{},
+	}, nil
+}
+
+/*
+IsLocal returns true if server names this server
+*/
+func (this *Federation) IsLocal(server string) bool {
+	return server == "" || server == this.name
+}
+
+/*
+GetProtectedEntityManager returns the client for server
+*/
+func (this *Federation) GetProtectedEntityManager(server string) (astrolabe.ProtectedEntityManager, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if pem, ok := this.managers[server]; ok {
+		return pem, nil
+	}
+	serverConfig, ok := this.servers[server]
+	if !ok {
+		return nil, errors.Errorf("server %s is not configured in %s", server, federationConfigFile)
+	}
+	schemes := []string{"https"}
+	if serverConfig.Insecure {
+		schemes = []string{"http"}
+	}
+	transport := httptransport.New(serverConfig.Host, restClient.DefaultBasePath, schemes)
+	if serverConfig.BearerToken != "" {
+		transport.DefaultAuthentication = httptransport.BearerToken(serverConfig.BearerToken)
+	}
+	pem, err := astrolabeClient.NewClientProtectedEntityManager(restClient.New(transport, strfmt.Default))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not connect to server %s at %s", server, serverConfig.Host)
+	}
+	pem.SetComponentResolver(this.resolver)
+	this.managers[server] = pem
+	this.logger.Infof("Connected to server %s at %s", server, serverConfig.Host)
+	return pem, nil
+}
+
+/*
+SetFederation makes the components on the servers in federation resolvable.  Without a federation only local
+components can be resolved.
+*/
+func (this *DirectProtectedEntityManager) SetFederation(federation *Federation) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if federation != nil {
+		federation.resolver = this
+	}
+	this.federation = federation
+}
+
+/*
+GetComponent returns the Protected Entity for spec, from this server or from the server named by spec.Server
+*/
+func (this *DirectProtectedEntityManager) GetComponent(ctx context.Context, spec astrolabe.ComponentSpec) (astrolabe.ProtectedEntity, error) {
+	pem, err := this.getServer(spec.Server)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not resolve %s", spec.ID.String())
+	}
+	return pem.GetProtectedEntity(ctx, spec.ID)
+}
+
+/*
+GetServerTypeManager returns the type manager for peType on server, or on this server if server is empty
+*/
+func (this *DirectProtectedEntityManager) GetServerTypeManager(server string, peType string) (astrolabe.ProtectedEntityTypeManager, error) {
+	pem, err := this.getServer(server)
+	if err != nil {
+		return nil, err
+	}
+	petm := pem.GetProtectedEntityTypeManager(peType)
+	if petm == nil {
+		if server == "" {
+			return nil, errors.Errorf("service %s not found", peType)
+		}
+		return nil, errors.Errorf("service %s not found on server %s", peType, server)
+	}
+	return petm, nil
+}
+
+func (this *DirectProtectedEntityManager) getServer(server string) (astrolabe.ProtectedEntityManager, error) {
+	this.mutex.RLock()
+	federation := this.federation
+	this.mutex.RUnlock()
+	if federation == nil {
+		if server == "" {
+			return this, nil
+		}
+		return nil, errors.Errorf("server %s is not known, there is no %s", server, federationConfigFile)
+	}
+	if federation.IsLocal(server) {
+		return this, nil
+	}
+	return federation.GetProtectedEntityManager(server)
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/loads"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/gen/restapi"
+	"github.com/vmware-tanzu/astrolabe/gen/restapi/operations"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+func startTestRESTServer(t *testing.T, pem *DirectProtectedEntityManager) *httptest.Server {
+	swaggerSpec, err := loads.Analyzed(restapi.SwaggerJSON, "")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	api := operations.NewAstrolabeAPI(swaggerSpec)
+	NewOpenAPIAstrolabeHandler(pem, NewTaskManager()).AttachHandlers(api)
+	return httptest.NewServer(api.Serve(nil))
+}
+
+func TestFederatedComponents(t *testing.T) {
+	ctx := context.Background()
+	// The vCenter server serves the disk, the cluster server serves the PVC that claims it
+	ivdPETM := newMemoryProtectedEntityTypeManager("ivd")
+	diskID := ivdPETM.addEntity("disk-1", nil)
+	vcPEM := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{ivdPETM}, astrolabe.S3Config{},
+		logrus.New())
+	vcServer := startTestRESTServer(t, vcPEM)
+	defer vcServer.Close()
+
+	pvcPETM := newMemoryProtectedEntityTypeManager("pvc")
+	pvcID := pvcPETM.addEntity("data", nil)
+	diskSpec := astrolabe.ComponentSpec{ID: diskID, Server: "vc-east"}
+	pvcPETM.setComponents("data", []astrolabe.ComponentSpec{diskSpec})
+	clusterPEM := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{pvcPETM},
+		astrolabe.S3Config{}, logrus.New())
+	pvcPETM.pem = clusterPEM
+	pvcPE, err := clusterPEM.GetProtectedEntity(ctx, pvcID)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	// Without servers.json the disk cannot be found
+	_, err = pvcPE.GetComponents(ctx)
+	assert.Assert(t, err != nil, "resolved a component on an unknown server")
+
+	federation, err := NewFederation(FederationConfig{
+		Name: "cluster-a",
+		Servers: map[string]RemoteServerConfig{
+			"vc-east": {
+				Host:     strings.TrimPrefix(vcServer.URL, "http://"),
+				Insecure: true,
+			},
+		},
+	}, logrus.New())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	clusterPEM.SetFederation(federation)
+	components, err := pvcPE.GetComponents(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1, len(components))
+	assert.Equal(t, diskID.String(), components[0].GetID().String())
+	diskInfo, err := components[0].GetInfo(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "disk-1", diskInfo.GetName())
+	// This server's own name is local
+	localPE, err := clusterPEM.GetComponent(ctx, astrolabe.ComponentSpec{ID: pvcID, Server: "cluster-a"})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, pvcID.String(), localPE.GetID().String())
+
+	// The zip of the PVC includes the disk and keeps its server
+	zipBuf := bytes.Buffer{}
+	err = astrolabe.ZipProtectedEntity(ctx, pvcPE, &zipBuf)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	zipPE, err := astrolabe.NewZipFileProtectedEntity(bytes.NewReader(zipBuf.Bytes()), int64(zipBuf.Len()))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	zipIDs := []string{}
+	for _, curPE := range zipPE.GetAllProtectedEntities() {
+		zipIDs = append(zipIDs, curPE.GetID().String())
+	}
+	assert.DeepEqual(t, []string{diskID.String(), pvcID.String()}, zipIDs)
+	zipInfo, err := zipPE.GetInfo(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, diskSpec, zipInfo.GetComponentSpecs()[0])
+
+	// Copying the zip restores the disk on its own server
+	tm := NewTaskManager()
+	s3Service := NewServiceS3(clusterPEM, tm, "s3", logrus.New())
+	recorder := httptest.NewRecorder()
+	s3Service.Handler(http.NotFoundHandler()).ServeHTTP(recorder, httptest.NewRequest(http.MethodPut,
+		"/s3/"+S3CopyBucket+"/data.zip", bytes.NewReader(zipBuf.Bytes())))
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	newIDs := waitForCopyTask(t, tm, recorder.Header())
+	assert.DeepEqual(t, map[string]string{
+		diskID.String(): "ivd:copy-1",
+		pvcID.String():  "pvc:copy-1",
+	}, newIDs)
+	_, err = ivdPETM.GetProtectedEntity(ctx, astrolabe.NewProtectedEntityID("ivd", "copy-1"))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+}
@@ -0,0 +1,592 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"github.com/vmware-tanzu/astrolabe/pkg/grpcapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// How often WatchTask checks the task for changes
+const taskWatchInterval = 100 * time.Millisecond
+
+/*
+GRPCAstrolabeHandler serves the gRPC control-plane API in pkg/grpcapi.  The calls behave like their OpenAPI
+counterparts, they are authorized against the same rules and the mutating calls are recorded in the audit log under
+the OpenAPI operation IDs.
+*/
+type GRPCAstrolabeHandler struct {
+	pem   astrolabe.ProtectedEntityManager
+	tm    *TaskManager
+	auth  *AuthManager
+	audit *AuditLog
+	// Mutating calls are rejected while drainer drains
+	drainer *Drainer
+	// For the lookups shared with the OpenAPI handlers
+	openAPI OpenAPIAstrolabeHandler
+}
+
+func NewGRPCAstrolabeHandler(pem astrolabe.ProtectedEntityManager, tm *TaskManager) *GRPCAstrolabeHandler {
+	return &GRPCAstrolabeHandler{
+		pem:     pem,
+		tm:      tm,
+		openAPI: NewOpenAPIAstrolabeHandler(pem, tm),
+	}
+}
+
+/*
+SetAuthManager authenticates and authorizes the calls with auth.  Calls are not authenticated if it is not set.
+*/
+func (this *GRPCAstrolabeHandler) SetAuthManager(auth *AuthManager) {
+	this.auth = auth
+}
+
+/*
+SetAuditLog records the mutating calls in audit
+*/
+func (this *GRPCAstrolabeHandler) SetAuditLog(audit *AuditLog) {
+	this.audit = audit
+}
+
+/*
+SetDrainer rejects the mutating calls while drainer drains
+*/
+func (this *GRPCAstrolabeHandler) SetDrainer(drainer *Drainer) {
+	this.drainer = drainer
+}
+
+func (this *GRPCAstrolabeHandler) Register(server *grpc.Server) {
+	grpcapi.RegisterAstrolabeServer(server, this)
+}
+
+/*
+authenticateGRPC authenticates a gRPC call with the bearer token in its authorization metadata or its client
+certificate and records the result in the returned context, like Authenticate does for HTTP requests
+*/
+func (this *AuthManager) authenticateGRPC(ctx context.Context) context.Context {
+	var result authResult
+	authorization := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+	var tlsState *tls.ConnectionState
+	if callPeer, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := callPeer.AuthInfo.(credentials.TLSInfo); ok {
+			tlsState = &tlsInfo.State
+		}
+	}
+	switch {
+	case strings.HasPrefix(authorization, "Bearer "):
+		result.identity, result.err = this.authenticateBearer(strings.TrimSpace(strings.TrimPrefix(authorization,
+			"Bearer ")))
+	case tlsState != nil && len(tlsState.PeerCertificates) > 0 && this.clientCAs != nil:
+		result.identity, result.err = this.authenticateCertificate(tlsState.PeerCertificates)
+	default:
+		result.err = errNotAuthenticated
+	}
+	if result.err != nil {
+		method, _ := grpc.Method(ctx)
+		this.logger.WithError(result.err).Infof("Authentication failed for gRPC call %s", method)
+		result = authResult{err: result.err}
+	}
+	return context.WithValue(ctx, authContextKey{}, result)
+}
+
+/*
+authorize authenticates the call and checks that it is allowed operation on service, see AuthManager.authorize.  The
+returned context carries the identity for the audit log.  Mutating calls are unavailable while draining.
+*/
+func (this *GRPCAstrolabeHandler) authorize(ctx context.Context, service string, operation string) (context.Context,
+	error) {
+	if this.auth != nil {
+		ctx = this.auth.authenticateGRPC(ctx)
+		if _, err := this.auth.authorize(ctx, service, operation); err != nil {
+			if authErr, ok := err.(AuthError); ok && authErr.Authenticated {
+				return ctx, status.Error(codes.PermissionDenied, err.Error())
+			}
+			return ctx, status.Error(codes.Unauthenticated, err.Error())
+		}
+	}
+	if isMutatingOperation(operation) && this.drainer.IsDraining() {
+		return ctx, status.Error(codes.Unavailable, "the server is shutting down")
+	}
+	return ctx, nil
+}
+
+/*
+recordCall records a mutating call in the audit log, if there is one.  The outcome is taken from err, a call that
+started a task is accepted.
+*/
+func (this *GRPCAstrolabeHandler) recordCall(ctx context.Context, event *AuditEvent, start time.Time, err error) {
+	if this.audit == nil {
+		return
+	}
+	switch code := status.Code(err); {
+	case err == nil && event.TaskID != "":
+		event.Outcome = AuditAccepted
+	case err == nil:
+		event.Outcome = AuditSuccess
+	case code == codes.Unauthenticated || code == codes.PermissionDenied:
+		event.Outcome = AuditDenied
+	default:
+		event.Outcome = AuditFailure
+	}
+	if err != nil {
+		event.Error = status.Convert(err).Message()
+	}
+	this.audit.recordCall(ctx, event, start)
+}
+
+/*
+idempotent runs call unless a call with the same idempotency key, taken from the idempotency-key metadata, succeeded
+within the idempotency window, in which case its response is returned.  Calls without a key are always run.
+*/
+func (this *GRPCAstrolabeHandler) idempotent(ctx context.Context, operation string, request interface{},
+	call func() (interface{}, error)) (interface{}, error) {
+	key := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(astrolabe.IdempotencyKeyMetadata); len(values) > 0 {
+			key = values[0]
+		}
+	}
+	if key == "" {
+		return call()
+	}
+	if err := checkIdempotencyKey(key); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not marshal the request: %v", err)
+	}
+	var callErr error
+	result, _, err := this.tm.runIdempotent(ctx, "grpc\x00"+key, idempotencyFingerprint(operation, string(requestJSON)),
+		func() (interface{}, bool) {
+			var result interface{}
+			result, callErr = call()
+			return result, callErr == nil
+		})
+	switch {
+	case err == errIdempotencyKeyReused:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		return nil, status.FromContextError(err).Err()
+	case callErr != nil:
+		return nil, callErr
+	}
+	return result, nil
+}
+
+/*
+auditParams returns the parameters of a call, as JSON, for the audit log with the secrets redacted
+*/
+func auditParams(paramsJSON []byte) interface{} {
+	var params interface{}
+	if len(paramsJSON) == 0 || json.Unmarshal(paramsJSON, &params) != nil {
+		return nil
+	}
+	return redactAuditParams(params)
+}
+
+/*
+grpcError returns the error payload of the OpenAPI handlers as a gRPC error
+*/
+func grpcError(apiErr *models.Error) error {
+	code := codes.Internal
+	switch *apiErr.Code {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.FailedPrecondition
+	}
+	return status.Error(code, *apiErr.Message)
+}
+
+func (this *GRPCAstrolabeHandler) ListServices(ctx context.Context, request *grpcapi.Empty) (*grpcapi.ServiceList,
+	error) {
+	if _, err := this.authorize(ctx, "", ""); err != nil {
+		return nil, err
+	}
+	etms := this.pem.ListEntityTypeManagers()
+	serviceList := &grpcapi.ServiceList{
+		Services: make([]string, len(etms)),
+	}
+	for curETMNum, curETM := range etms {
+		serviceList.Services[curETMNum] = curETM.GetTypeName()
+	}
+	return serviceList, nil
+}
+
+func (this *GRPCAstrolabeHandler) ListProtectedEntities(ctx context.Context,
+	request *grpcapi.ListProtectedEntitiesRequest) (*grpcapi.ProtectedEntityList, error) {
+	ctx, err := this.authorize(ctx, request.Service, ReadOperation)
+	if err != nil {
+		return nil, err
+	}
+	petm := this.pem.GetProtectedEntityTypeManager(request.Service)
+	if petm == nil {
+		return nil, status.Errorf(codes.NotFound, "service %s not found", request.Service)
+	}
+	options, err := grpcListOptions(request.LabelSelector, request.MaxResults, request.Options)
+	if err != nil {
+		return nil, err
+	}
+	if request.IdsAfter != "" {
+		if options.ContinuationToken != "" || options.OrderBy != "" || options.Descending {
+			return nil, status.Error(codes.InvalidArgument,
+				"ids_after cannot be combined with continuation_token, order_by or descending")
+		}
+		options.ContinuationToken = astrolabe.ContinuationTokenAfter(request.IdsAfter)
+	}
+	page, err := astrolabe.GetProtectedEntitiesPage(ctx, petm, options)
+	if err != nil {
+		return nil, grpcListError(err)
+	}
+	return grpcapi.PageToProto(page), nil
+}
+
+func grpcListOptions(labelSelector string, maxResults int32, protoOptions *grpcapi.ListOptions) (astrolabe.ListOptions,
+	error) {
+	selector, err := astrolabe.ParseLabelSelector(labelSelector)
+	if err != nil {
+		return astrolabe.ListOptions{}, status.Errorf(codes.InvalidArgument, "invalid label selector: %v", err)
+	}
+	options := grpcapi.ListOptionsFromProto(maxResults, protoOptions)
+	options.LabelSelector = selector
+	if err := options.Validate(); err != nil {
+		return astrolabe.ListOptions{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return options, nil
+}
+
+func grpcListError(err error) error {
+	if astrolabe.IsInvalidListOptionsError(err) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func (this *GRPCAstrolabeHandler) GetProtectedEntityInfo(ctx context.Context,
+	request *grpcapi.ProtectedEntityRequest) (*grpcapi.ProtectedEntityInfo, error) {
+	ctx, err := this.authorize(ctx, request.Service, ReadOperation)
+	if err != nil {
+		return nil, err
+	}
+	petm, peid, apiErr := this.openAPI.lookupService(request.Service, request.Id, false)
+	if apiErr != nil {
+		return nil, grpcError(apiErr)
+	}
+	pe, apiErr := getProtectedEntity(ctx, petm, peid)
+	if apiErr != nil {
+		return nil, grpcError(apiErr)
+	}
+	peInfo, err := pe.GetInfo(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return grpcapi.InfoToProto(peInfo), nil
+}
+
+func (this *GRPCAstrolabeHandler) CreateSnapshot(ctx context.Context,
+	request *grpcapi.CreateSnapshotRequest) (response *grpcapi.SnapshotID, err error) {
+	start := time.Now()
+	event := &AuditEvent{
+		Action:             "snapshot",
+		Operation:          "createSnapshot",
+		Service:            request.Service,
+		ProtectedEntityIDs: []string{request.Id},
+		Params:             auditParams(request.ParamsJson),
+	}
+	ctx, err = this.authorize(ctx, request.Service, SnapshotOperation)
+	defer func() { this.recordCall(ctx, event, start, err) }()
+	if err != nil {
+		return nil, err
+	}
+	result, err := this.idempotent(ctx, event.Operation, request, func() (interface{}, error) {
+		return this.createSnapshot(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*grpcapi.SnapshotID), nil
+}
+
+func (this *GRPCAstrolabeHandler) createSnapshot(ctx context.Context,
+	request *grpcapi.CreateSnapshotRequest) (*grpcapi.SnapshotID, error) {
+	petm, peid, apiErr := this.openAPI.lookupService(request.Service, request.Id, false)
+	if apiErr != nil {
+		return nil, grpcError(apiErr)
+	}
+	if peid.HasSnapshot() {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot snapshot snapshot %s", peid.String())
+	}
+	params, err := grpcapi.ParamsFromJSON(request.ParamsJson)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	pe, apiErr := getProtectedEntity(ctx, petm, peid)
+	if apiErr != nil {
+		return nil, grpcError(apiErr)
+	}
+	snapshotID, err := pe.Snapshot(ctx, params)
+	if err != nil {
+		if IsLockConflictError(err) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		if astrolabe.IsLabelsNotSupportedError(err) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &grpcapi.SnapshotID{
+		Id: snapshotID.String(),
+	}, nil
+}
+
+func (this *GRPCAstrolabeHandler) ListSnapshots(ctx context.Context,
+	request *grpcapi.ListSnapshotsRequest) (*grpcapi.ProtectedEntityList, error) {
+	ctx, err := this.authorize(ctx, request.Service, ReadOperation)
+	if err != nil {
+		return nil, err
+	}
+	petm, peid, apiErr := this.openAPI.lookupService(request.Service, request.Id, false)
+	if apiErr != nil {
+		return nil, grpcError(apiErr)
+	}
+	options, err := grpcListOptions(request.LabelSelector, request.MaxResults, request.Options)
+	if err != nil {
+		return nil, err
+	}
+	pe, apiErr := getProtectedEntity(ctx, petm, peid)
+	if apiErr != nil {
+		return nil, grpcError(apiErr)
+	}
+	page, err := astrolabe.ListSnapshotsPage(ctx, petm, pe, options)
+	if err != nil {
+		return nil, grpcListError(err)
+	}
+	return grpcapi.PageToProto(page), nil
+}
+
+func (this *GRPCAstrolabeHandler) CopyProtectedEntity(ctx context.Context,
+	request *grpcapi.CopyProtectedEntityRequest) (response *grpcapi.TaskID, err error) {
+	start := time.Now()
+	event := &AuditEvent{
+		Action:    "copy",
+		Operation: "copyProtectedEntity",
+		Service:   request.Service,
+		Params: map[string]interface{}{
+			"mode":       request.Mode,
+			"copyParams": auditParams(request.ParamsJson),
+		},
+	}
+	if copyModes[request.Mode] == astrolabe.UpdateExistingObject {
+		event.Action = "overwrite"
+	}
+	// Only the ID of the protected entity info is kept, the data transports may carry credentials
+	if request.Info != nil {
+		event.ProtectedEntityIDs = []string{request.Info.Id}
+	}
+	ctx, err = this.authorize(ctx, request.Service, CopyOperation)
+	defer func() { this.recordCall(ctx, event, start, err) }()
+	if err != nil {
+		return nil, err
+	}
+	result, err := this.idempotent(ctx, event.Operation, request, func() (interface{}, error) {
+		return this.copyProtectedEntity(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	response = result.(*grpcapi.TaskID)
+	event.TaskID = response.Id
+	return response, nil
+}
+
+func (this *GRPCAstrolabeHandler) copyProtectedEntity(ctx context.Context,
+	request *grpcapi.CopyProtectedEntityRequest) (*grpcapi.TaskID, error) {
+	petm := this.pem.GetProtectedEntityTypeManager(request.Service)
+	if petm == nil {
+		return nil, status.Errorf(codes.NotFound, "service %s not found", request.Service)
+	}
+	options, ok := copyModes[request.Mode]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown copy mode %s", request.Mode)
+	}
+	if request.Info == nil {
+		return nil, status.Error(codes.InvalidArgument, "info is required")
+	}
+	pei, err := grpcapi.InfoFromProto(request.Info)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid info: %v", err)
+	}
+	copyParams, err := grpcapi.ParamsFromJSON(request.ParamsJson)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	task := startAsyncTask(ctx, fmt.Sprintf("copying %s to %s", pei.GetID().String(), request.Service),
+		func(ctx context.Context) (interface{}, error) {
+			newPE, err := petm.CopyFromInfo(ctx, pei, copyParams, options)
+			if err != nil {
+				return nil, err
+			}
+			return newPE.GetID().GetModelProtectedEntityID(), nil
+		})
+	this.tm.AddTask(task)
+	return &grpcapi.TaskID{
+		Id: task.GetID().String(),
+	}, nil
+}
+
+func (this *GRPCAstrolabeHandler) DeleteProtectedEntity(ctx context.Context,
+	request *grpcapi.ProtectedEntityRequest) (response *grpcapi.ProtectedEntityID, err error) {
+	start := time.Now()
+	event := &AuditEvent{
+		Action:             "delete",
+		Operation:          "deleteProtectedEntity",
+		Service:            request.Service,
+		ProtectedEntityIDs: []string{request.Id},
+	}
+	ctx, err = this.authorize(ctx, request.Service, DeleteOperation)
+	defer func() { this.recordCall(ctx, event, start, err) }()
+	if err != nil {
+		return nil, err
+	}
+	result, err := this.idempotent(ctx, event.Operation, request, func() (interface{}, error) {
+		return this.deleteProtectedEntity(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*grpcapi.ProtectedEntityID), nil
+}
+
+func (this *GRPCAstrolabeHandler) deleteProtectedEntity(ctx context.Context,
+	request *grpcapi.ProtectedEntityRequest) (*grpcapi.ProtectedEntityID, error) {
+	// Only snapshots can be deleted through the API for now
+	petm, peid, apiErr := this.openAPI.lookupService(request.Service, request.Id, true)
+	if apiErr != nil {
+		return nil, grpcError(apiErr)
+	}
+	pe, apiErr := getProtectedEntity(ctx, petm, peid)
+	if apiErr != nil {
+		return nil, grpcError(apiErr)
+	}
+	_, err := pe.DeleteSnapshot(ctx, peid.GetSnapshotID(), make(map[string]map[string]interface{}))
+	if err != nil {
+		if astrolabe.IsRetentionError(err) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		if IsLockConflictError(err) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &grpcapi.ProtectedEntityID{
+		Id: peid.String(),
+	}, nil
+}
+
+func (this *GRPCAstrolabeHandler) ListTasks(ctx context.Context, request *grpcapi.Empty) (*grpcapi.TaskIDList, error) {
+	if _, err := this.authorize(ctx, "", ""); err != nil {
+		return nil, err
+	}
+	taskIDs := this.tm.ListTasks()
+	taskIDList := &grpcapi.TaskIDList{
+		Ids: make([]string, len(taskIDs)),
+	}
+	for taskNum, taskID := range taskIDs {
+		taskIDList.Ids[taskNum] = taskID.String()
+	}
+	return taskIDList, nil
+}
+
+func (this *GRPCAstrolabeHandler) retrieveTask(ctx context.Context, request *grpcapi.TaskID) (astrolabe.Task, error) {
+	if _, err := this.authorize(ctx, "", ""); err != nil {
+		return nil, err
+	}
+	task, ok := this.tm.RetrieveTask(astrolabe.NewTaskIDFromString(request.Id))
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %s not found", request.Id)
+	}
+	return task, nil
+}
+
+func (this *GRPCAstrolabeHandler) GetTaskInfo(ctx context.Context, request *grpcapi.TaskID) (*grpcapi.TaskInfo,
+	error) {
+	task, err := this.retrieveTask(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	taskInfo, err := grpcapi.TaskInfoToProto(task)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return taskInfo, nil
+}
+
+/*
+WatchTask polls the task and sends its info whenever the progress or the status changes, the stream ends once the
+task has finished
+*/
+func (this *GRPCAstrolabeHandler) WatchTask(request *grpcapi.TaskID, stream grpcapi.TaskInfoSender) error {
+	ctx := stream.Context()
+	task, err := this.retrieveTask(ctx, request)
+	if err != nil {
+		return err
+	}
+	var lastSent *grpcapi.TaskInfo
+	for {
+		taskInfo, err := grpcapi.TaskInfoToProto(task)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if lastSent == nil || taskInfo.Progress != lastSent.Progress || taskInfo.Status != lastSent.Status ||
+			taskInfo.Completed != lastSent.Completed {
+			if err := stream.Send(taskInfo); err != nil {
+				return err
+			}
+			lastSent = taskInfo
+		}
+		if taskInfo.Completed {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return status.Error(codes.Canceled, ctx.Err().Error())
+		case <-time.After(taskWatchInterval):
+		}
+	}
+}
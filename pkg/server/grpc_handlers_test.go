@@ -0,0 +1,258 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"github.com/vmware-tanzu/astrolabe/pkg/client"
+	"github.com/vmware-tanzu/astrolabe/pkg/grpcapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+/*
+Serves handler on a local port and returns a connection to it, stop shuts down the server and closes the connection
+*/
+func startTestGRPCServer(t *testing.T, handler *GRPCAstrolabeHandler) (conn *grpc.ClientConn, stop func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	grpcServer := grpc.NewServer()
+	handler.Register(grpcServer)
+	go grpcServer.Serve(listener)
+	conn, err = grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		grpcServer.Stop()
+		t.Fatal("Got error " + err.Error())
+	}
+	return conn, func() {
+		conn.Close()
+		grpcServer.Stop()
+	}
+}
+
+func TestGRPCHandlers(t *testing.T) {
+	ctx := context.Background()
+	petm := newMemoryProtectedEntityTypeManager("mem")
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	peidA := petm.addEntity("a", map[string]string{"env": "prod"})
+	petm.addEntity("b", map[string]string{"env": "dev"})
+	conn, stop := startTestGRPCServer(t, NewGRPCAstrolabeHandler(pem, NewTaskManager()))
+	defer stop()
+
+	grpcPEM, err := client.NewGRPCProtectedEntityManager(conn)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1, len(grpcPEM.ListEntityTypeManagers()))
+	grpcPETM := grpcPEM.GetProtectedEntityTypeManager("mem").(client.GRPCProtectedEntityTypeManager)
+	peids, err := grpcPETM.GetProtectedEntities(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 2, len(peids))
+	selector, err := labels.Parse("env=prod")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	peids, err = grpcPETM.GetProtectedEntitiesWithLabelSelector(ctx, selector)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{peidA.String()}, []string{peids[0].String()})
+
+	// Paging and errors through the raw client
+	grpcClient := grpcapi.NewAstrolabeClient(conn)
+	peList, err := grpcClient.ListProtectedEntities(ctx, &grpcapi.ListProtectedEntitiesRequest{
+		Service:    "mem",
+		MaxResults: 1,
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{"mem:a"}, peList.Ids)
+	assert.Assert(t, peList.Truncated)
+	// The client continues with the token
+	page, err := astrolabe.GetProtectedEntitiesPage(ctx, grpcPETM, astrolabe.ListOptions{
+		MaxResults:        1,
+		ContinuationToken: peList.ContinuationToken,
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{"mem:b"}, []string{page.IDs[0].String()})
+	assert.Equal(t, "", page.ContinuationToken)
+	_, err = grpcClient.ListProtectedEntities(ctx, &grpcapi.ListProtectedEntitiesRequest{
+		Service: "mem",
+		Options: &grpcapi.ListOptions{ContinuationToken: "garbage"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = grpcClient.ListProtectedEntities(ctx, &grpcapi.ListProtectedEntitiesRequest{Service: "ivd"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = grpcClient.GetProtectedEntityInfo(ctx, &grpcapi.ProtectedEntityRequest{Service: "mem", Id: "not an id"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = grpcClient.GetProtectedEntityInfo(ctx, &grpcapi.ProtectedEntityRequest{Service: "mem", Id: "mem:c"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Snapshots
+	pe, err := grpcPEM.GetProtectedEntity(ctx, peidA)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	info, err := pe.GetInfo(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "prod", info.GetLabels()["env"])
+	snapshotID, err := pe.Snapshot(ctx, map[string]map[string]interface{}{"mem": {"note": "first"}})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	snapshotIDs, err := pe.ListSnapshots(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{snapshotID.String()}, []string{snapshotIDs[0].String()})
+	petm.retained[peidA.IDWithSnapshot(snapshotID).String()] = true
+	_, err = grpcClient.DeleteProtectedEntity(ctx, &grpcapi.ProtectedEntityRequest{
+		Service: "mem",
+		Id:      peidA.IDWithSnapshot(snapshotID).String(),
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	delete(petm.retained, peidA.IDWithSnapshot(snapshotID).String())
+	_, err = pe.DeleteSnapshot(ctx, snapshotID, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	snapshotIDs, err = pe.ListSnapshots(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 0, len(snapshotIDs))
+
+	// Copies wait for the copy task through WatchTask
+	newPE, err := grpcPETM.Copy(ctx, pe, nil, astrolabe.AllocateNewObject)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "mem:copy-1", newPE.GetID().String())
+	_, err = grpcPETM.CopyFromInfo(ctx, info, nil, astrolabe.AllocateObjectWithID)
+	assert.ErrorContains(t, err, "already exists")
+	taskIDs, err := grpcPEM.ListTasks(ctx)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 2, len(taskIDs))
+	var progressCalls int
+	taskInfo, err := grpcPEM.WatchTask(ctx, taskIDs[0], func(taskInfo *grpcapi.TaskInfo) {
+		progressCalls++
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, taskInfo.Completed)
+	assert.Equal(t, 1, progressCalls)
+	_, err = grpcPEM.GetTaskInfo(ctx, astrolabe.NewTaskIDFromString("unknown"))
+	assert.Equal(t, codes.NotFound, status.Code(errors.Cause(err)))
+}
+
+func TestGRPCAuthAndAudit(t *testing.T) {
+	ctx := context.Background()
+	auditDir, err := ioutil.TempDir("", "astrolabe-audit")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer os.RemoveAll(auditDir)
+
+	petm := newMemoryProtectedEntityTypeManager("mem")
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	peid := petm.addEntity("a", nil)
+	handler := NewGRPCAstrolabeHandler(pem, NewTaskManager())
+	handler.SetAuthManager(newTestAuthManager(t, AuthConfig{
+		BearerTokens: []BearerToken{
+			{Token: "viewer-token", Identity: "someone", Groups: []string{"viewers"}},
+			{Token: "snapper-token", Identity: "snapper"},
+		},
+	}, ""))
+	audit := newTestAuditLog(t, auditDir, 0, 0)
+	defer audit.Close()
+	handler.SetAuditLog(audit)
+	conn, stop := startTestGRPCServer(t, handler)
+	defer stop()
+	grpcClient := grpcapi.NewAstrolabeClient(conn)
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+
+	_, err = grpcClient.ListServices(ctx, &grpcapi.Empty{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = grpcClient.ListServices(withToken("unknown"), &grpcapi.Empty{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	serviceList, err := grpcClient.ListServices(withToken("viewer-token"), &grpcapi.Empty{})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{"mem"}, serviceList.Services)
+	_, err = grpcClient.GetProtectedEntityInfo(withToken("viewer-token"), &grpcapi.ProtectedEntityRequest{
+		Service: "mem",
+		Id:      peid.String(),
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	snapshotRequest := &grpcapi.CreateSnapshotRequest{
+		Service:    "mem",
+		Id:         peid.String(),
+		ParamsJson: []byte(`{"mem":{"password":"hunter2"}}`),
+	}
+	_, err = grpcClient.CreateSnapshot(withToken("viewer-token"), snapshotRequest)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	snapshotID, err := grpcClient.CreateSnapshot(withToken("snapper-token"), snapshotRequest)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	events, err := audit.Query(AuditFilter{})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, AuditDenied, events[0].Outcome)
+	assert.Equal(t, "someone", events[0].Identity)
+	assert.Equal(t, "createSnapshot", events[1].Operation)
+	assert.Equal(t, AuditSuccess, events[1].Outcome)
+	assert.Equal(t, "snapper", events[1].Identity)
+	assert.DeepEqual(t, map[string]interface{}{"mem": map[string]interface{}{"password": auditRedacted}},
+		events[1].Params)
+	assert.Assert(t, snapshotID.Id != "")
+}
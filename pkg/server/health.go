@@ -0,0 +1,273 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+// The health probe paths served by HealthHandler
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// Health statuses of the services and of the server
+const (
+	HealthOK = "ok"
+	// A service whose health check fails, or whose new configuration could not be loaded and that is still running
+	// with the previous one.  The server is degraded if any service is not ok.
+	HealthDegraded = "degraded"
+	// A service that could not be started
+	HealthFailed = "failed"
+	// The server is unavailable if it has services and none of them is ok
+	HealthUnavailable = "unavailable"
+	HealthDraining    = "draining"
+)
+
+// How long a service health check may take
+const defaultHealthCheckTimeout = 5 * time.Second
+
+// How long the results of the health checks are reused, probes are frequent and the checks reach remote services
+const defaultHealthCacheTime = 10 * time.Second
+
+type ServiceHealth struct {
+	Service string `json:"service"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+type HealthReport struct {
+	Status      string          `json:"status"`
+	CheckedTime time.Time       `json:"checkedTime"`
+	Services    []ServiceHealth `json:"services"`
+}
+
+/*
+CheckHealth reports the health of each service.  Services whose type manager implements astrolabe.HealthChecker are
+checked concurrently, each within timeout.  Services whose configuration could not be loaded are reported as failed,
+or degraded if they are still running with an earlier configuration.  The result is sorted by service name.
+*/
+func (this *DirectProtectedEntityManager) CheckHealth(ctx context.Context, timeout time.Duration) []ServiceHealth {
+	this.mutex.RLock()
+	typeManagers := make(map[string]astrolabe.ProtectedEntityTypeManager, len(this.typeManager))
+	for typeName, petm := range this.typeManager {
+		typeManagers[typeName] = petm
+	}
+	configErrors := map[string]error{}
+	for serviceName, err := range this.failed {
+		if running, ok := this.configured[serviceName]; ok {
+			// The service keeps running with its previous configuration
+			configErrors[running.typeName] = err
+			continue
+		}
+		configErrors[serviceName] = err
+	}
+	this.mutex.RUnlock()
+
+	results := make(chan ServiceHealth, len(typeManagers))
+	for typeName, petm := range typeManagers {
+		go func(typeName string, petm astrolabe.ProtectedEntityTypeManager) {
+			results <- checkServiceHealth(ctx, typeName, petm, timeout)
+		}(typeName, petm)
+	}
+	health := make([]ServiceHealth, 0, len(typeManagers)+len(configErrors))
+	for range typeManagers {
+		serviceHealth := <-results
+		if err, ok := configErrors[serviceHealth.Service]; ok && serviceHealth.Status == HealthOK {
+			serviceHealth.Status = HealthDegraded
+			serviceHealth.Error = "could not load the new configuration: " + err.Error()
+		}
+		health = append(health, serviceHealth)
+	}
+	for serviceName, err := range configErrors {
+		if _, running := typeManagers[serviceName]; running {
+			continue
+		}
+		health = append(health, ServiceHealth{
+			Service: serviceName,
+			Status:  HealthFailed,
+			Error:   err.Error(),
+		})
+	}
+	sort.Slice(health, func(i, j int) bool {
+		return health[i].Service < health[j].Service
+	})
+	return health
+}
+
+func checkServiceHealth(ctx context.Context, typeName string, petm astrolabe.ProtectedEntityTypeManager,
+	timeout time.Duration) ServiceHealth {
+	serviceHealth := ServiceHealth{
+		Service: typeName,
+		Status:  HealthOK,
+	}
+	checker, ok := astrolabe.UnwrapProtectedEntityTypeManager(petm).(astrolabe.HealthChecker)
+	if !ok {
+		return serviceHealth
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// A check that ignores its context is abandoned at the deadline
+	checkErr := make(chan error, 1)
+	go func() {
+		checkErr <- checker.CheckHealth(checkCtx)
+	}()
+	var err error
+	select {
+	case err = <-checkErr:
+	case <-checkCtx.Done():
+		err = errors.Errorf("health check did not finish within %v", timeout)
+	}
+	if err != nil {
+		serviceHealth.Status = HealthDegraded
+		serviceHealth.Error = err.Error()
+	}
+	return serviceHealth
+}
+
+/*
+HealthHandler serves the Kubernetes probes.  /healthz is the liveness probe, it reports 200 as long as the server
+responds, failing services do not make a restart useful.  /readyz is the readiness probe, it reports 503 while the
+server drains or if none of its services is ok.  Adding verbose to the query returns the health of each service as a
+HealthReport in JSON.  If an AuthManager is set, the verbose view requires an authenticated identity.
+*/
+type HealthHandler struct {
+	pem       *DirectProtectedEntityManager
+	drainer   *Drainer
+	auth      *AuthManager
+	timeout   time.Duration
+	cacheTime time.Duration
+
+	mutex      sync.Mutex
+	lastReport *HealthReport
+}
+
+func NewHealthHandler(pem *DirectProtectedEntityManager, drainer *Drainer) *HealthHandler {
+	return &HealthHandler{
+		pem:       pem,
+		drainer:   drainer,
+		timeout:   defaultHealthCheckTimeout,
+		cacheTime: defaultHealthCacheTime,
+	}
+}
+
+/*
+SetAuthManager makes the verbose view require an identity authenticated by auth.Authenticate
+*/
+func (this *HealthHandler) SetAuthManager(auth *AuthManager) {
+	this.auth = auth
+}
+
+/*
+Report returns the health of the server and its services.  The service checks are reused for the cache time.
+*/
+func (this *HealthHandler) Report(ctx context.Context) HealthReport {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.lastReport == nil || time.Since(this.lastReport.CheckedTime) > this.cacheTime {
+		services := this.pem.CheckHealth(ctx, this.timeout)
+		this.lastReport = &HealthReport{
+			Status:      serverHealth(services),
+			CheckedTime: time.Now(),
+			Services:    services,
+		}
+	}
+	report := *this.lastReport
+	if this.drainer.IsDraining() {
+		report.Status = HealthDraining
+	}
+	return report
+}
+
+func serverHealth(services []ServiceHealth) string {
+	okCount := 0
+	for _, serviceHealth := range services {
+		if serviceHealth.Status == HealthOK {
+			okCount++
+		}
+	}
+	switch {
+	case okCount == len(services):
+		return HealthOK
+	case okCount == 0:
+		return HealthUnavailable
+	}
+	return HealthDegraded
+}
+
+/*
+Handler returns an http.Handler that serves the probes and passes everything else to next
+*/
+func (this *HealthHandler) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case LivenessPath:
+			this.serveProbe(w, r, false)
+		case ReadinessPath:
+			this.serveProbe(w, r, true)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func (this *HealthHandler) serveProbe(w http.ResponseWriter, r *http.Request, readiness bool) {
+	_, verbose := r.URL.Query()["verbose"]
+	if verbose && this.auth != nil {
+		if _, err := this.auth.authorize(r.Context(), "", ""); err != nil {
+			writeAuthError(w, err)
+			return
+		}
+	}
+	status := http.StatusOK
+	var report HealthReport
+	if readiness {
+		// Readiness depends on the services, a draining server is not ready whatever their state
+		report = this.Report(r.Context())
+		if report.Status == HealthUnavailable || report.Status == HealthDraining {
+			status = http.StatusServiceUnavailable
+		}
+	} else if verbose {
+		report = this.Report(r.Context())
+	}
+	if !verbose {
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(HealthOK + "\n"))
+		} else {
+			w.Write([]byte(report.Status + "\n"))
+		}
+		return
+	}
+	payload, err := json.Marshal(report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(payload)
+}
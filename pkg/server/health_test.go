@@ -0,0 +1,111 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+func TestHealth(t *testing.T) {
+	fsRoot, err := ioutil.TempDir("", "astrolabe-health")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer os.RemoveAll(fsRoot)
+	configInfo := ConfigInfo{
+		PEConfigs: map[string]map[string]interface{}{
+			"fs":         {"root": fsRoot},
+			"nosuchtype": {},
+		},
+	}
+	pem := NewDirectProtectedEntityManagerFromParamMap(configInfo, logrus.New())
+	healthHandler := NewHealthHandler(pem, NewDrainer(NewTaskManager(), logrus.New()))
+	healthHandler.cacheTime = 0
+	handler := healthHandler.Handler(http.NotFoundHandler())
+
+	// The service that could not be started is reported instead of being dropped
+	report := getHealthReport(t, handler, ReadinessPath+"?verbose", http.StatusOK)
+	assert.Equal(t, HealthDegraded, report.Status)
+	assert.DeepEqual(t, []string{"fs", "nosuchtype"}, []string{report.Services[0].Service, report.Services[1].Service})
+	assert.Equal(t, HealthOK, report.Services[0].Status)
+	assert.Equal(t, HealthFailed, report.Services[1].Status)
+	assert.Assert(t, report.Services[1].Error != "")
+
+	// Without a usable service the server is not ready but still alive
+	err = os.RemoveAll(fsRoot)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	report = getHealthReport(t, handler, ReadinessPath+"?verbose", http.StatusServiceUnavailable)
+	assert.Equal(t, HealthUnavailable, report.Status)
+	assert.Equal(t, HealthDegraded, report.Services[0].Status)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, LivenessPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	stuck := make(chan struct{})
+	defer close(stuck)
+	petm := &healthCheckProtectedEntityTypeManager{
+		memoryProtectedEntityTypeManager: newMemoryProtectedEntityTypeManager("mem"),
+		check: func(ctx context.Context) error {
+			// Ignores the deadline
+			<-stuck
+			return nil
+		},
+	}
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm,
+		newMemoryProtectedEntityTypeManager("other")}, astrolabe.S3Config{}, logrus.New())
+	health := pem.CheckHealth(context.Background(), 50*time.Millisecond)
+	assert.Equal(t, 2, len(health))
+	assert.Equal(t, "mem", health[0].Service)
+	assert.Equal(t, HealthDegraded, health[0].Status)
+	assert.Equal(t, HealthOK, health[1].Status)
+}
+
+type healthCheckProtectedEntityTypeManager struct {
+	*memoryProtectedEntityTypeManager
+	check func(ctx context.Context) error
+}
+
+func (this *healthCheckProtectedEntityTypeManager) CheckHealth(ctx context.Context) error {
+	return this.check(ctx)
+}
+
+func getHealthReport(t *testing.T, handler http.Handler, path string, expectedCode int) HealthReport {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	assert.Equal(t, expectedCode, recorder.Code)
+	var report HealthReport
+	err := json.Unmarshal(recorder.Body.Bytes(), &report)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return report
+}
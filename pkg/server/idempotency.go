@@ -0,0 +1,283 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+The task manager remembers the result of the mutating calls made with an idempotency key (see
+astrolabe.WithIdempotencyKey) for the idempotency window.  A call with a key that is remembered returns the result of
+the first call, the snapshot ID or the task that was started, instead of running again.  A call that arrives while the
+first call with its key is still running waits for it.  Only successful calls are remembered, a failed call can be
+retried with the same key.  Keys are scoped to the caller's identity and cannot be reused for a different request.
+*/
+const (
+	// DefaultIdempotencyWindow is how long the results of calls with an idempotency key are kept by default
+	DefaultIdempotencyWindow = taskRetention
+	// IdempotentReplayedHeader is set on REST responses that were returned for an earlier call with the same key
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	// REST responses larger than this are not remembered
+	idempotentMaxResponseSize = 1024 * 1024
+)
+
+var errIdempotencyKeyReused = errors.New("the idempotency key was already used for a different request")
+
+type idempotentRecord struct {
+	fingerprint string
+	// Closed when the first call finishes
+	done chan struct{}
+	// Set before done is closed.  Calls that failed are removed instead.
+	result       interface{}
+	finishedTime time.Time
+}
+
+/*
+SetIdempotencyWindow sets how long the results of calls with an idempotency key are kept.  Idempotency keys are
+ignored if window is 0.  Tasks are only kept for an hour after they finish, a longer window can return the ID of a task
+that is gone.
+*/
+func (this *TaskManager) SetIdempotencyWindow(window time.Duration) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.idempotencyWindow = window
+}
+
+// Must be called with the mutex held
+func (this *TaskManager) idempotentRecordExpired(record *idempotentRecord) bool {
+	return !record.finishedTime.IsZero() && time.Now().Sub(record.finishedTime) > this.idempotencyWindow
+}
+
+/*
+runIdempotent runs call unless a call with the same caller and key was remembered, in which case the result of that
+call is returned and replayed is true.  call returns its result and whether it succeeded.  An error is returned if key
+was used for a request with a different fingerprint or if ctx is done while waiting for the first call.
+*/
+func (this *TaskManager) runIdempotent(ctx context.Context, key string, fingerprint string,
+	call func() (interface{}, bool)) (result interface{}, replayed bool, err error) {
+	scopedKey := admissionCaller(ctx) + "\x00" + key
+	for {
+		this.mutex.Lock()
+		if this.idempotencyWindow <= 0 {
+			this.mutex.Unlock()
+			result, _ = call()
+			return result, false, nil
+		}
+		record, ok := this.idempotency[scopedKey]
+		if !ok || this.idempotentRecordExpired(record) {
+			record = &idempotentRecord{
+				fingerprint: fingerprint,
+				done:        make(chan struct{}),
+			}
+			this.idempotency[scopedKey] = record
+			this.mutex.Unlock()
+			return this.runRecorded(scopedKey, record, call), false, nil
+		}
+		this.mutex.Unlock()
+		if record.fingerprint != fingerprint {
+			return nil, false, errIdempotencyKeyReused
+		}
+		select {
+		case <-record.done:
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+		if !record.finishedTime.IsZero() {
+			return record.result, true, nil
+		}
+		// The first call failed and was forgotten, run this one
+	}
+}
+
+func (this *TaskManager) runRecorded(scopedKey string, record *idempotentRecord,
+	call func() (interface{}, bool)) (result interface{}) {
+	succeeded := false
+	// The record is finished even if call panics, so that the calls waiting for it do not hang
+	defer func() {
+		this.mutex.Lock()
+		if succeeded {
+			record.result = result
+			record.finishedTime = time.Now()
+		} else {
+			delete(this.idempotency, scopedKey)
+		}
+		this.mutex.Unlock()
+		close(record.done)
+	}()
+	result, succeeded = call()
+	return result
+}
+
+func checkIdempotencyKey(key string) error {
+	if len(key) > maxIdempotencyKeyLength {
+		return errors.Errorf("the idempotency key is longer than %d characters", maxIdempotencyKeyLength)
+	}
+	return nil
+}
+
+/*
+idempotencyFingerprint returns a hash of the parts of a request that must be the same for a call to be replayed
+*/
+func idempotencyFingerprint(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(hash, "%d:%s", len(part), part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+/*
+idempotentResponse is a successful REST response remembered for its idempotency key
+*/
+type idempotentResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func (this *idempotentResponse) replay(w http.ResponseWriter) {
+	for name, values := range this.header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(this.status)
+	w.Write(this.body)
+}
+
+/*
+idempotentResponseWriter keeps the response to a call with an idempotency key
+*/
+type idempotentResponseWriter struct {
+	http.ResponseWriter
+	status   int
+	header   http.Header
+	body     bytes.Buffer
+	tooLarge bool
+}
+
+func (this *idempotentResponseWriter) WriteHeader(status int) {
+	if this.status == 0 {
+		this.status = status
+		this.header = this.ResponseWriter.Header().Clone()
+	}
+	this.ResponseWriter.WriteHeader(status)
+}
+
+func (this *idempotentResponseWriter) Write(p []byte) (int, error) {
+	if this.status == 0 {
+		this.WriteHeader(http.StatusOK)
+	}
+	if this.body.Len()+len(p) > idempotentMaxResponseSize {
+		this.tooLarge = true
+	} else {
+		this.body.Write(p)
+	}
+	return this.ResponseWriter.Write(p)
+}
+
+/*
+response returns the response to remember, nil if the call failed or the response is too large
+*/
+func (this *idempotentResponseWriter) response() *idempotentResponse {
+	if this.status == 0 {
+		this.WriteHeader(http.StatusOK)
+	}
+	if this.status < 200 || this.status >= 300 || this.tooLarge {
+		return nil
+	}
+	return &idempotentResponse{
+		status: this.status,
+		header: this.header,
+		body:   this.body.Bytes(),
+	}
+}
+
+func writeIdempotencyError(w http.ResponseWriter, code int, message string) {
+	payload, _ := json.Marshal(errorPayload(code, message))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(payload)
+}
+
+/*
+IdempotencyMiddleware replays the response to a mutating OpenAPI call made with the key in its Idempotency-Key header
+if a call with the same key succeeded within the idempotency window.  Replayed responses carry the
+Idempotent-Replayed header.  Reusing a key for a different request is rejected with 422 Unprocessable Entity.  It is
+used in the builder passed to api.Serve, inside the authorization so that denied calls are not remembered.
+*/
+func (this *TaskManager) IdempotencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(astrolabe.IdempotencyKeyHeader)
+		route := middleware.MatchedRouteFrom(r)
+		if key == "" || route == nil || route.Operation == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, mutating := auditedOperations[route.Operation.ID]; !mutating {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err := checkIdempotencyKey(key); err != nil {
+			writeIdempotencyError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		var body []byte
+		if r.Body != nil {
+			// The body is part of the fingerprint and put back for the handler
+			var err error
+			body, err = ioutil.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				writeIdempotencyError(w, http.StatusBadRequest, "could not read the request: "+err.Error())
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		fingerprint := idempotencyFingerprint(route.Operation.ID, r.Method, r.URL.Path, r.URL.Query().Encode(),
+			string(body))
+		result, replayed, err := this.runIdempotent(r.Context(), "rest\x00"+key, fingerprint,
+			func() (interface{}, bool) {
+				recorder := &idempotentResponseWriter{ResponseWriter: w}
+				next.ServeHTTP(recorder, r)
+				response := recorder.response()
+				return response, response != nil
+			})
+		switch {
+		case err == errIdempotencyKeyReused:
+			writeIdempotencyError(w, http.StatusUnprocessableEntity, err.Error())
+		case err != nil:
+			// The caller went away while waiting for the first call
+			writeIdempotencyError(w, http.StatusServiceUnavailable, err.Error())
+		case replayed:
+			result.(*idempotentResponse).replay(w)
+		}
+	})
+}
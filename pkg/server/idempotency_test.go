@@ -0,0 +1,206 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/loads"
+	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+	"github.com/sirupsen/logrus"
+	restClient "github.com/vmware-tanzu/astrolabe/gen/client"
+	"github.com/vmware-tanzu/astrolabe/gen/restapi"
+	"github.com/vmware-tanzu/astrolabe/gen/restapi/operations"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	astrolabeClient "github.com/vmware-tanzu/astrolabe/pkg/client"
+	"github.com/vmware-tanzu/astrolabe/pkg/grpcapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	ctx := context.Background()
+	petm := newMemoryProtectedEntityTypeManager("mem")
+	peid := petm.addEntity("a", nil)
+	otherID := petm.addEntity("b", nil)
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	tm := NewTaskManager()
+	swaggerSpec, err := loads.Analyzed(restapi.SwaggerJSON, "")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	api := operations.NewAstrolabeAPI(swaggerSpec)
+	NewOpenAPIAstrolabeHandler(pem, tm).AttachHandlers(api)
+	restServer := httptest.NewServer(api.Serve(tm.IdempotencyMiddleware))
+	defer restServer.Close()
+	transport := httptransport.New(strings.TrimPrefix(restServer.URL, "http://"), restClient.DefaultBasePath,
+		[]string{"http"})
+	clientPEM, err := astrolabeClient.NewClientProtectedEntityManager(restClient.New(transport, strfmt.Default))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	clientPE, err := clientPEM.GetProtectedEntity(ctx, peid)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	// A retry with the same key returns the first snapshot
+	firstCtx := astrolabe.WithIdempotencyKey(ctx, "first")
+	snapshotID, err := clientPE.Snapshot(firstCtx, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	retriedID, err := clientPE.Snapshot(firstCtx, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, snapshotID, retriedID)
+	assert.Equal(t, 1, len(petm.entities["a"].snapshots))
+	// Calls without a key or with a new key run
+	if _, err = clientPE.Snapshot(ctx, nil); err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	secondID, err := clientPE.Snapshot(astrolabe.WithIdempotencyKey(ctx, "second"), nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "snap-3", secondID.String())
+
+	// A key cannot be reused for a different request
+	request, _ := http.NewRequest(http.MethodPost,
+		restServer.URL+restClient.DefaultBasePath+"/astrolabe/mem/"+otherID.String()+"/snapshots", nil)
+	request.Header.Set(astrolabe.IdempotencyKeyHeader, "first")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	response.Body.Close()
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+	request.Header.Set(astrolabe.IdempotencyKeyHeader, "other")
+	response, err = http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "", response.Header.Get(IdempotentReplayedHeader))
+	response, err = http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	response.Body.Close()
+	assert.Equal(t, "true", response.Header.Get(IdempotentReplayedHeader))
+
+	// Failed calls are not remembered
+	failedCtx := astrolabe.WithIdempotencyKey(ctx, "failed")
+	petm.failSnapshot["a"] = true
+	_, err = clientPE.Snapshot(failedCtx, nil)
+	assert.Assert(t, err != nil, "snapshot did not fail")
+	petm.failSnapshot["a"] = false
+	retriedID, err = clientPE.Snapshot(failedCtx, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "snap-4", retriedID.String())
+
+	// gRPC calls take the key from their metadata
+	conn, stop := startTestGRPCServer(t, NewGRPCAstrolabeHandler(pem, tm))
+	defer stop()
+	grpcClient := grpcapi.NewAstrolabeClient(conn)
+	grpcCtx := metadata.AppendToOutgoingContext(ctx, astrolabe.IdempotencyKeyMetadata, "first")
+	grpcRequest := &grpcapi.CreateSnapshotRequest{
+		Service: "mem",
+		Id:      peid.String(),
+	}
+	grpcSnapshotID, err := grpcClient.CreateSnapshot(grpcCtx, grpcRequest)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "snap-5", grpcSnapshotID.Id)
+	grpcRetriedID, err := grpcClient.CreateSnapshot(grpcCtx, grpcRequest)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, grpcSnapshotID.Id, grpcRetriedID.Id)
+	_, err = grpcClient.CreateSnapshot(grpcCtx, &grpcapi.CreateSnapshotRequest{
+		Service: "mem",
+		Id:      otherID.String(),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Keys are forgotten after the window
+	tm.SetIdempotencyWindow(time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	retriedID, err = clientPE.Snapshot(firstCtx, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "snap-6", retriedID.String())
+}
+
+func TestIdempotentConcurrentCalls(t *testing.T) {
+	tm := NewTaskManager()
+	ctx := context.Background()
+	release := make(chan struct{})
+	var calls int32
+	var callsMutex sync.Mutex
+	call := func() (interface{}, bool) {
+		callsMutex.Lock()
+		calls++
+		callsMutex.Unlock()
+		<-release
+		return "result", true
+	}
+	results := make(chan interface{}, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			result, _, err := tm.runIdempotent(ctx, "key", "fingerprint", call)
+			if err != nil {
+				results <- err
+				return
+			}
+			results <- result
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "result", <-results)
+	}
+	assert.Equal(t, int32(1), calls)
+
+	// Callers do not share keys
+	aliceCtx := context.WithValue(ctx, authContextKey{}, authResult{identity: &Identity{Name: "alice"}})
+	result, replayed, err := tm.runIdempotent(aliceCtx, "key", "other", func() (interface{}, bool) {
+		return "alice", true
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "alice", result)
+	assert.Assert(t, !replayed)
+}
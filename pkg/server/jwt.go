@@ -0,0 +1,278 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+/*
+jwtVerifier validates OIDC ID tokens (JWTs) against the keys in a local JWKS file.  RS256, RS384, RS512, ES256,
+ES384 and ES512 signatures are supported.  Tokens must not be expired and must match the configured issuer and
+audience.
+*/
+type jwtVerifier struct {
+	keys     map[string]crypto.PublicKey
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+const jwtClockSkew = time.Minute
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func newJWTVerifier(jwksFile string, issuer string, audience string) (*jwtVerifier, error) {
+	jwksBytes, err := ioutil.ReadFile(jwksFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read JWKS file %s", jwksFile)
+	}
+	jwks := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	err = json.Unmarshal(jwksBytes, &jwks)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse JWKS file %s", jwksFile)
+	}
+	verifier := &jwtVerifier{
+		keys:     map[string]crypto.PublicKey{},
+		issuer:   issuer,
+		audience: audience,
+		now:      time.Now,
+	}
+	for _, key := range jwks.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		publicKey, err := key.publicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key %q in JWKS file %s", key.Kid, jwksFile)
+		}
+		verifier.keys[key.Kid] = publicKey
+	}
+	if len(verifier.keys) == 0 {
+		return nil, errors.Errorf("JWKS file %s has no signing keys", jwksFile)
+	}
+	return verifier, nil
+}
+
+func decodeBigInt(encoded string) (*big.Int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(decoded), nil
+}
+
+func (this jwk) publicKey() (crypto.PublicKey, error) {
+	switch this.Kty {
+	case "RSA":
+		n, err := decodeBigInt(this.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid modulus")
+		}
+		e, err := decodeBigInt(this.E)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch this.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve %q", this.Crv)
+		}
+		x, err := decodeBigInt(this.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid x")
+		}
+		y, err := decodeBigInt(this.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid y")
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, errors.Errorf("unsupported key type %q", this.Kty)
+}
+
+/*
+verify checks the signature and the registered claims of token and returns its claims
+*/
+func (this *jwtVerifier) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token is not a JWT")
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid JWT header")
+	}
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+	err = json.Unmarshal(headerBytes, &header)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid JWT header")
+	}
+	key, ok := this.keys[header.Kid]
+	if !ok {
+		return nil, errors.Errorf("unknown key ID %q", header.Kid)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid JWT signature")
+	}
+	err = verifyJWTSignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature)
+	if err != nil {
+		return nil, err
+	}
+
+	claimsBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid JWT claims")
+	}
+	claims := map[string]interface{}{}
+	decoder := json.NewDecoder(strings.NewReader(string(claimsBytes)))
+	decoder.UseNumber()
+	err = decoder.Decode(&claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid JWT claims")
+	}
+	now := this.now()
+	exp, ok := numericClaim(claims, "exp")
+	if !ok {
+		return nil, errors.New("JWT does not have an expiration")
+	}
+	if now.After(exp.Add(jwtClockSkew)) {
+		return nil, errors.New("JWT has expired")
+	}
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(jwtClockSkew).Before(nbf) {
+		return nil, errors.New("JWT is not valid yet")
+	}
+	if this.issuer != "" && claims["iss"] != this.issuer {
+		return nil, errors.Errorf("JWT issuer %v is not %s", claims["iss"], this.issuer)
+	}
+	if this.audience != "" && !hasAudience(claims["aud"], this.audience) {
+		return nil, errors.Errorf("JWT audience %v does not include %s", claims["aud"], this.audience)
+	}
+	return claims, nil
+}
+
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed []byte, signature []byte) error {
+	if len(alg) != 5 {
+		return errors.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return errors.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	hasher := hash.New()
+	hasher.Write(signed)
+	digest := hasher.Sum(nil)
+	switch {
+	case strings.HasPrefix(alg, "RS"):
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.Errorf("key does not match JWT algorithm %s", alg)
+		}
+		err := rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+		if err != nil {
+			return errors.Wrap(err, "invalid JWT signature")
+		}
+		return nil
+	case strings.HasPrefix(alg, "ES"):
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.Errorf("key does not match JWT algorithm %s", alg)
+		}
+		// The signature is r and s, each padded to the size of the curve
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid JWT signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return errors.New("invalid JWT signature")
+		}
+		return nil
+	}
+	return errors.Errorf("unsupported JWT algorithm %q", alg)
+}
+
+func numericClaim(claims map[string]interface{}, name string) (time.Time, bool) {
+	number, ok := claims[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
+
+func hasAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, curAud := range aud {
+			if curAud == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,474 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+// DefaultLockWait is how long an operation waits for a conflicting operation on the same Protected Entity by default
+const DefaultLockWait = 5 * time.Minute
+
+/*
+LockMode is the mode of a Protected Entity lock.  Any number of operations can hold a read lock at the same time, a
+write lock is held by one operation and excludes the readers.
+*/
+type LockMode int
+
+const (
+	// Copies read their source with a read lock
+	ReadLock LockMode = 1
+	// Snapshots, snapshot deletes and overwrites hold a write lock
+	WriteLock LockMode = 2
+)
+
+func (this LockMode) String() string {
+	switch this {
+	case ReadLock:
+		return "read"
+	case WriteLock:
+		return "write"
+	}
+	return "unknown"
+}
+
+// States of the locks reported in the task info
+const (
+	LockWaiting = "waiting"
+	LockHeld    = "held"
+)
+
+/*
+LockConflictError is returned when an operation could not get a lock because other operations held it
+*/
+type LockConflictError struct {
+	ID   astrolabe.ProtectedEntityID
+	Mode LockMode
+	// The operations holding the lock
+	HeldBy []string
+}
+
+func (this LockConflictError) Error() string {
+	return fmt.Sprintf("could not get a %s lock on %s, it is held by %s", this.Mode.String(), this.ID.String(),
+		strings.Join(this.HeldBy, ", "))
+}
+
+func IsLockConflictError(err error) bool {
+	_, ok := errors.Cause(err).(LockConflictError)
+	return ok
+}
+
+/*
+LockManager serializes conflicting operations on a Protected Entity.  Locks are taken on the Protected Entity without
+its snapshot ID, so deleting a snapshot waits for a snapshot of the same Protected Entity, while snapshots of different
+Protected Entities run in parallel.  Operations that conflict with a held lock wait in arrival order, a read lock is not
+granted ahead of a waiting write lock.  An operation that waits longer than maxWait, or at all if maxWait is 0, fails
+with a LockConflictError.
+*/
+type LockManager struct {
+	mutex   sync.Mutex
+	locks   map[astrolabe.ProtectedEntityID]*peLock
+	maxWait time.Duration
+	logger  logrus.FieldLogger
+}
+
+type peLock struct {
+	readers int
+	writer  bool
+	holders []*lockRequest
+	waiting []*lockRequest
+}
+
+type lockRequest struct {
+	mode      LockMode
+	operation string
+	// Closed when the lock is granted
+	granted     chan struct{}
+	reportState func(id astrolabe.ProtectedEntityID, mode LockMode, state string)
+}
+
+func NewLockManager(maxWait time.Duration, logger logrus.FieldLogger) *LockManager {
+	return &LockManager{
+		locks:   map[astrolabe.ProtectedEntityID]*peLock{},
+		maxWait: maxWait,
+		logger:  logger,
+	}
+}
+
+/*
+lockID returns the ID locks are taken on for id, the ID without its snapshot ID
+*/
+func lockID(id astrolabe.ProtectedEntityID) astrolabe.ProtectedEntityID {
+	return astrolabe.NewProtectedEntityID(id.GetPeType(), id.GetID())
+}
+
+/*
+LockRequest is a lock wanted by an operation
+*/
+type LockRequest struct {
+	ID   astrolabe.ProtectedEntityID
+	Mode LockMode
+}
+
+/*
+Lock takes the locks in requests for operation, in a fixed order so that operations that need more than one lock do
+not deadlock.  A Protected Entity that is requested more than once is locked once in the strongest mode.  The returned
+context carries the locks, operations called with it do not wait for the locks their caller holds.  release must be
+called when the operation finishes.  While the operation waits and holds the locks, their state is reported to the
+task in ctx.
+*/
+func (this *LockManager) Lock(ctx context.Context, operation string, requests ...LockRequest) (context.Context,
+	func(), error) {
+	held := heldLocks(ctx)
+	wanted := map[astrolabe.ProtectedEntityID]LockMode{}
+	for _, request := range requests {
+		id := lockID(request.ID)
+		if request.Mode > wanted[id] {
+			wanted[id] = request.Mode
+		}
+	}
+	ids := []astrolabe.ProtectedEntityID{}
+	for id, mode := range wanted {
+		heldMode := held[id]
+		if heldMode >= mode {
+			continue
+		}
+		if heldMode != 0 {
+			return ctx, nil, errors.Errorf("%s cannot upgrade its %s lock on %s", operation, heldMode.String(),
+				id.String())
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+	releases := []func(){}
+	release := func() {
+		for curRelease := len(releases) - 1; curRelease >= 0; curRelease-- {
+			releases[curRelease]()
+		}
+	}
+	newHeld := make(map[astrolabe.ProtectedEntityID]LockMode, len(held)+len(ids))
+	for id, mode := range held {
+		newHeld[id] = mode
+	}
+	for _, id := range ids {
+		lockRelease, err := this.lock(ctx, id, wanted[id], operation)
+		if err != nil {
+			release()
+			return ctx, nil, err
+		}
+		releases = append(releases, lockRelease)
+		newHeld[id] = wanted[id]
+	}
+	var releaseOnce sync.Once
+	return context.WithValue(ctx, heldLocksContextKey{}, newHeld), func() {
+		releaseOnce.Do(release)
+	}, nil
+}
+
+func (this *LockManager) lock(ctx context.Context, id astrolabe.ProtectedEntityID, mode LockMode,
+	operation string) (func(), error) {
+	request := &lockRequest{
+		mode:        mode,
+		operation:   operation,
+		granted:     make(chan struct{}),
+		reportState: lockStateReporter(ctx),
+	}
+	release := func() {
+		this.release(id, request)
+	}
+	this.mutex.Lock()
+	curLock, ok := this.locks[id]
+	if !ok {
+		curLock = &peLock{}
+		this.locks[id] = curLock
+	}
+	if len(curLock.waiting) == 0 && curLock.compatible(mode) {
+		curLock.grant(id, request)
+		this.mutex.Unlock()
+		return release, nil
+	}
+	if this.maxWait <= 0 {
+		conflict := curLock.conflict(id, mode)
+		this.mutex.Unlock()
+		return nil, conflict
+	}
+	curLock.waiting = append(curLock.waiting, request)
+	request.report(id, LockWaiting)
+	this.mutex.Unlock()
+	this.logger.Debugf("%s waiting for a %s lock on %s", operation, mode.String(), id.String())
+
+	timer := time.NewTimer(this.maxWait)
+	defer timer.Stop()
+	var waitErr error
+	select {
+	case <-request.granted:
+		return release, nil
+	case <-timer.C:
+	case <-ctx.Done():
+		waitErr = errors.Wrapf(ctx.Err(), "cancelled while waiting for a %s lock on %s", mode.String(), id.String())
+	}
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	select {
+	case <-request.granted:
+		// Granted while the wait ended
+		return release, nil
+	default:
+	}
+	curLock.remove(request)
+	request.report(id, "")
+	if waitErr == nil {
+		waitErr = curLock.conflict(id, mode)
+	}
+	// The lock may be free for the operations that waited behind this one
+	this.grantWaiting(id, curLock)
+	return nil, waitErr
+}
+
+func (this *LockManager) release(id astrolabe.ProtectedEntityID, request *lockRequest) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	curLock := this.locks[id]
+	for curHolder, holder := range curLock.holders {
+		if holder == request {
+			curLock.holders = append(curLock.holders[:curHolder], curLock.holders[curHolder+1:]...)
+			break
+		}
+	}
+	if request.mode == WriteLock {
+		curLock.writer = false
+	} else {
+		curLock.readers--
+	}
+	request.report(id, "")
+	this.grantWaiting(id, curLock)
+}
+
+/*
+grantWaiting grants the waiting requests in order until one conflicts.  Must be called with the mutex held.
+*/
+func (this *LockManager) grantWaiting(id astrolabe.ProtectedEntityID, curLock *peLock) {
+	for len(curLock.waiting) > 0 && curLock.compatible(curLock.waiting[0].mode) {
+		request := curLock.waiting[0]
+		curLock.waiting = curLock.waiting[1:]
+		curLock.grant(id, request)
+	}
+	if len(curLock.holders) == 0 && len(curLock.waiting) == 0 {
+		delete(this.locks, id)
+	}
+}
+
+func (this *peLock) compatible(mode LockMode) bool {
+	if mode == WriteLock {
+		return !this.writer && this.readers == 0
+	}
+	return !this.writer
+}
+
+func (this *peLock) grant(id astrolabe.ProtectedEntityID, request *lockRequest) {
+	if request.mode == WriteLock {
+		this.writer = true
+	} else {
+		this.readers++
+	}
+	this.holders = append(this.holders, request)
+	request.report(id, LockHeld)
+	close(request.granted)
+}
+
+func (this *peLock) remove(request *lockRequest) {
+	for curWaiting, waiting := range this.waiting {
+		if waiting == request {
+			this.waiting = append(this.waiting[:curWaiting], this.waiting[curWaiting+1:]...)
+			return
+		}
+	}
+}
+
+func (this *peLock) conflict(id astrolabe.ProtectedEntityID, mode LockMode) LockConflictError {
+	heldBy := []string{}
+	for _, holder := range this.holders {
+		heldBy = append(heldBy, holder.operation+" ("+holder.mode.String()+")")
+	}
+	if len(heldBy) == 0 {
+		// Only operations that are waiting ahead
+		for _, waiting := range this.waiting {
+			heldBy = append(heldBy, waiting.operation+" ("+waiting.mode.String()+", waiting)")
+		}
+	}
+	return LockConflictError{
+		ID:     id,
+		Mode:   mode,
+		HeldBy: heldBy,
+	}
+}
+
+func (this *lockRequest) report(id astrolabe.ProtectedEntityID, state string) {
+	if this.reportState != nil {
+		this.reportState(id, this.mode, state)
+	}
+}
+
+type heldLocksContextKey struct{}
+
+func heldLocks(ctx context.Context) map[astrolabe.ProtectedEntityID]LockMode {
+	held, _ := ctx.Value(heldLocksContextKey{}).(map[astrolabe.ProtectedEntityID]LockMode)
+	return held
+}
+
+type lockStateContextKey struct{}
+
+/*
+withLockStateReporter returns a context that passes the state of the locks of an operation to report, "" once a lock
+is released or no longer waited for.  report is called with the lock manager's lock held and must not block.
+*/
+func withLockStateReporter(ctx context.Context, report func(id astrolabe.ProtectedEntityID, mode LockMode,
+	state string)) context.Context {
+	return context.WithValue(ctx, lockStateContextKey{}, report)
+}
+
+func lockStateReporter(ctx context.Context) func(id astrolabe.ProtectedEntityID, mode LockMode, state string) {
+	report, _ := ctx.Value(lockStateContextKey{}).(func(id astrolabe.ProtectedEntityID, mode LockMode, state string))
+	return report
+}
+
+/*
+lockingProtectedEntityTypeManager decorates a type manager so that copies hold a read lock on their source and, when
+they overwrite or create a Protected Entity with a given ID, a write lock on it.  The protected entities it returns are
+decorated so that snapshots, snapshot deletes and overwrites hold a write lock.
+*/
+type lockingProtectedEntityTypeManager struct {
+	astrolabe.ProtectedEntityTypeManager
+	locks *LockManager
+}
+
+func newLockingProtectedEntityTypeManager(petm astrolabe.ProtectedEntityTypeManager,
+	locks *LockManager) astrolabe.ProtectedEntityTypeManager {
+	return &lockingProtectedEntityTypeManager{
+		ProtectedEntityTypeManager: petm,
+		locks:                      locks,
+	}
+}
+
+func (this *lockingProtectedEntityTypeManager) Unwrap() astrolabe.ProtectedEntityTypeManager {
+	return this.ProtectedEntityTypeManager
+}
+
+func (this *lockingProtectedEntityTypeManager) GetProtectedEntity(ctx context.Context,
+	id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
+	pe, err := this.ProtectedEntityTypeManager.GetProtectedEntity(ctx, id)
+	return this.wrap(pe), err
+}
+
+/*
+copyLocks returns the locks of a copy of the Protected Entity sourceID into this type manager
+*/
+func (this *lockingProtectedEntityTypeManager) copyLocks(sourceID astrolabe.ProtectedEntityID,
+	options astrolabe.CopyCreateOptions) []LockRequest {
+	requests := []LockRequest{{ID: sourceID, Mode: ReadLock}}
+	if options != astrolabe.AllocateNewObject {
+		requests = append(requests, LockRequest{
+			ID:   astrolabe.NewProtectedEntityID(this.GetTypeName(), sourceID.GetID()),
+			Mode: WriteLock,
+		})
+	}
+	return requests
+}
+
+func (this *lockingProtectedEntityTypeManager) Copy(ctx context.Context, pe astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	ctx, release, err := this.locks.Lock(ctx, "copy to "+this.GetTypeName(), this.copyLocks(pe.GetID(), options)...)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	newPE, err := this.ProtectedEntityTypeManager.Copy(ctx, pe, params, options)
+	return this.wrap(newPE), err
+}
+
+func (this *lockingProtectedEntityTypeManager) CopyFromInfo(ctx context.Context, info astrolabe.ProtectedEntityInfo,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	ctx, release, err := this.locks.Lock(ctx, "copy to "+this.GetTypeName(), this.copyLocks(info.GetID(), options)...)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	newPE, err := this.ProtectedEntityTypeManager.CopyFromInfo(ctx, info, params, options)
+	return this.wrap(newPE), err
+}
+
+func (this *lockingProtectedEntityTypeManager) wrap(pe astrolabe.ProtectedEntity) astrolabe.ProtectedEntity {
+	if pe == nil {
+		return nil
+	}
+	return &lockingProtectedEntity{
+		ProtectedEntity: pe,
+		locks:           this.locks,
+	}
+}
+
+/*
+lockingProtectedEntity holds a write lock on a protected entity returned by a type manager during its snapshot
+and overwrite operations.  An overwrite also holds a read lock on its source.
+*/
+type lockingProtectedEntity struct {
+	astrolabe.ProtectedEntity
+	locks *LockManager
+}
+
+func (this *lockingProtectedEntity) Snapshot(ctx context.Context,
+	params map[string]map[string]interface{}) (astrolabe.ProtectedEntitySnapshotID, error) {
+	ctx, release, err := this.locks.Lock(ctx, "snapshot", LockRequest{ID: this.GetID(), Mode: WriteLock})
+	if err != nil {
+		return astrolabe.ProtectedEntitySnapshotID{}, err
+	}
+	defer release()
+	return this.ProtectedEntity.Snapshot(ctx, params)
+}
+
+func (this *lockingProtectedEntity) DeleteSnapshot(ctx context.Context,
+	snapshotToDelete astrolabe.ProtectedEntitySnapshotID, params map[string]map[string]interface{}) (bool, error) {
+	ctx, release, err := this.locks.Lock(ctx, "delete snapshot "+snapshotToDelete.String(),
+		LockRequest{ID: this.GetID(), Mode: WriteLock})
+	if err != nil {
+		return false, err
+	}
+	defer release()
+	return this.ProtectedEntity.DeleteSnapshot(ctx, snapshotToDelete, params)
+}
+
+func (this *lockingProtectedEntity) Overwrite(ctx context.Context, sourcePE astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, overwriteComponents bool) error {
+	ctx, release, err := this.locks.Lock(ctx, "overwrite from "+sourcePE.GetID().String(),
+		LockRequest{ID: sourcePE.GetID(), Mode: ReadLock}, LockRequest{ID: this.GetID(), Mode: WriteLock})
+	if err != nil {
+		return err
+	}
+	defer release()
+	return this.ProtectedEntity.Overwrite(ctx, sourcePE, params, overwriteComponents)
+}
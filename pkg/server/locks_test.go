@@ -0,0 +1,253 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+	"github.com/vmware-tanzu/astrolabe/gen/restapi/operations"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+lockWaitContext returns a context that signals waiting when an operation starts waiting for a lock
+*/
+func lockWaitContext(waiting chan string) context.Context {
+	return withLockStateReporter(context.Background(), func(id astrolabe.ProtectedEntityID, mode LockMode,
+		state string) {
+		if state == LockWaiting {
+			waiting <- mode.String()
+		}
+	})
+}
+
+func TestLockModes(t *testing.T) {
+	locks := NewLockManager(10*time.Second, logrus.New())
+	ctx := context.Background()
+	diskA := astrolabe.NewProtectedEntityID("ivd", "a")
+	snapshotA := diskA.IDWithSnapshot(astrolabe.NewProtectedEntitySnapshotID("snap-1"))
+	// Readers share the lock, snapshot IDs are ignored
+	_, releaseRead1, err := locks.Lock(ctx, "copy 1", LockRequest{ID: diskA, Mode: ReadLock})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, releaseRead2, err := locks.Lock(ctx, "copy 2", LockRequest{ID: snapshotA, Mode: ReadLock})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	// A writer waits for the readers and a reader that arrives after it waits for the writer
+	waiting := make(chan string, 2)
+	granted := make(chan string, 2)
+	go func() {
+		_, release, err := locks.Lock(lockWaitContext(waiting), "delete", LockRequest{ID: snapshotA, Mode: WriteLock})
+		if err != nil {
+			panic(err)
+		}
+		granted <- "write"
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	assert.Equal(t, "write", <-waiting)
+	go func() {
+		_, release, err := locks.Lock(lockWaitContext(waiting), "copy 3", LockRequest{ID: diskA, Mode: ReadLock})
+		if err != nil {
+			panic(err)
+		}
+		granted <- "read"
+		release()
+	}()
+	assert.Equal(t, "read", <-waiting)
+
+	// Other Protected Entities are not held up
+	_, releaseB, err := locks.Lock(ctx, "snapshot", LockRequest{ID: astrolabe.NewProtectedEntityID("ivd", "b"),
+		Mode: WriteLock})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	releaseB()
+
+	releaseRead1()
+	releaseRead2()
+	assert.Equal(t, "write", <-granted)
+	assert.Equal(t, "read", <-granted)
+
+	// An operation does not wait for the locks its caller holds
+	heldCtx, release, err := locks.Lock(ctx, "copy", LockRequest{ID: diskA, Mode: ReadLock},
+		LockRequest{ID: snapshotA, Mode: WriteLock})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, nestedRelease, err := locks.Lock(heldCtx, "snapshot", LockRequest{ID: diskA, Mode: WriteLock})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	nestedRelease()
+
+	// The wait is limited by ctx
+	cancelCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, _, err = locks.Lock(cancelCtx, "snapshot", LockRequest{ID: diskA, Mode: WriteLock})
+	assert.Assert(t, err != nil, "Lock did not fail when cancelled")
+	release()
+	_, release, err = locks.Lock(ctx, "snapshot", LockRequest{ID: diskA, Mode: WriteLock})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	release()
+	assert.Equal(t, 0, len(locks.locks))
+}
+
+func TestLockedOperations(t *testing.T) {
+	petm := &blockingProtectedEntityTypeManager{
+		memoryProtectedEntityTypeManager: newMemoryProtectedEntityTypeManager("mem"),
+		started:                          make(chan struct{}, 1),
+		proceed:                          make(chan struct{}),
+	}
+	peid := petm.addEntity("a", nil)
+	otherID := petm.addEntity("b", nil)
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	// Conflicting operations fail at once
+	pem.SetLockManager(NewLockManager(0, logrus.New()))
+	handler := NewOpenAPIAstrolabeHandler(pem, NewTaskManager())
+
+	pe, err := petm.GetProtectedEntity(context.Background(), peid)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	info, err := pe.GetInfo(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	mpeInfo := info.GetModelProtectedEntityInfo()
+	resp := handler.CopyProtectedEntity(operations.CopyProtectedEntityParams{
+		Service: "mem",
+		Mode:    "update",
+		Body: &models.CopyParameters{
+			ProtectedEntityInfo: &mpeInfo,
+		},
+	})
+	taskID := resp.(*operations.CopyProtectedEntityAccepted).Payload.TaskID
+	<-petm.started
+
+	// The overwrite holds a write lock on a, which is shown in the task info
+	taskInfoOK := handler.GetTaskInfo(operations.GetTaskInfoParams{TaskID: string(taskID)}).(*operations.GetTaskInfoOK)
+	assert.DeepEqual(t, []*models.TaskLock{{
+		ProtectedEntityID: peid.GetModelProtectedEntityID(),
+		Mode:              swag.String("write"),
+		State:             swag.String(LockHeld),
+	}}, taskInfoOK.Payload.Locks)
+	snapshotResp := handler.CreateSnapshot(operations.CreateSnapshotParams{
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+	})
+	_, ok := snapshotResp.(*operations.CreateSnapshotConflict)
+	assert.Assert(t, ok, "Got %T from CreateSnapshot", snapshotResp)
+	snapshotResp = handler.CreateSnapshot(operations.CreateSnapshotParams{
+		Service:           "mem",
+		ProtectedEntityID: otherID.String(),
+	})
+	_, ok = snapshotResp.(*operations.CreateSnapshotOK)
+	assert.Assert(t, ok, "Got %T from CreateSnapshot", snapshotResp)
+
+	close(petm.proceed)
+	taskInfo := waitForTask(t, handler, taskID)
+	assert.Equal(t, astrolabe.Success.String(), *taskInfo.Status, taskInfo.Details)
+	assert.Equal(t, 0, len(taskInfo.Locks))
+	snapshotResp = handler.CreateSnapshot(operations.CreateSnapshotParams{
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+	})
+	_, ok = snapshotResp.(*operations.CreateSnapshotOK)
+	assert.Assert(t, ok, "Got %T from CreateSnapshot", snapshotResp)
+}
+
+/*
+blockingOverwriteProtectedEntity signals started when an overwrite starts and finishes it when proceed is closed
+*/
+type blockingOverwriteProtectedEntity struct {
+	astrolabe.ProtectedEntity
+	started chan struct{}
+	proceed chan struct{}
+}
+
+func (this *blockingOverwriteProtectedEntity) Overwrite(ctx context.Context, sourcePE astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, overwriteComponents bool) error {
+	this.started <- struct{}{}
+	<-this.proceed
+	return nil
+}
+
+func TestLockedOverwrite(t *testing.T) {
+	ctx := context.Background()
+	petm := newMemoryProtectedEntityTypeManager("mem")
+	targetID := petm.addEntity("a", nil)
+	sourceID := petm.addEntity("b", nil)
+	// Conflicting operations fail at once
+	locks := NewLockManager(0, logrus.New())
+	lockingPETM := newLockingProtectedEntityTypeManager(petm, locks)
+	targetPE, err := petm.GetProtectedEntity(ctx, targetID)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	blockingPE := &blockingOverwriteProtectedEntity{
+		ProtectedEntity: targetPE,
+		started:         make(chan struct{}, 1),
+		proceed:         make(chan struct{}),
+	}
+	target := &lockingProtectedEntity{
+		ProtectedEntity: blockingPE,
+		locks:           locks,
+	}
+	source, err := lockingPETM.GetProtectedEntity(ctx, sourceID)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	overwriteErr := make(chan error, 1)
+	go func() {
+		overwriteErr <- target.Overwrite(ctx, source, nil, false)
+	}()
+	<-blockingPE.started
+
+	// The overwrite holds a write lock on the target and a read lock on the source
+	_, err = target.Snapshot(ctx, nil)
+	assert.Assert(t, IsLockConflictError(err), "Got %v from Snapshot", err)
+	_, err = source.Snapshot(ctx, nil)
+	assert.Assert(t, IsLockConflictError(err), "Got %v from Snapshot", err)
+	_, releaseRead, err := locks.Lock(ctx, "copy", LockRequest{ID: sourceID, Mode: ReadLock})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	releaseRead()
+
+	close(blockingPE.proceed)
+	if err := <-overwriteErr; err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, err = target.Snapshot(ctx, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+}
@@ -0,0 +1,347 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+memoryProtectedEntityTypeManager keeps protected entities and their snapshots in memory for the handler tests.
+Snapshots listed in retained cannot be deleted and snapshots of entities listed in failSnapshot fail.  Entities with
+data or metadata have S3 transports pointing at s3Config.  Components set with setComponents are resolved through
+pem.
+*/
+type memoryProtectedEntityTypeManager struct {
+	typeName     string
+	s3Config     astrolabe.S3Config
+	pem          astrolabe.ProtectedEntityManager
+	mutex        sync.Mutex
+	entities     map[string]*memoryEntity
+	retained     map[string]bool
+	failSnapshot map[string]bool
+	nextID       int
+}
+
+type memoryEntity struct {
+	info      astrolabe.ProtectedEntityInfo
+	snapshots []astrolabe.ProtectedEntitySnapshotID
+	data      []byte
+	metadata  []byte
+}
+
+func newMemoryProtectedEntityTypeManager(typeName string) *memoryProtectedEntityTypeManager {
+	return &memoryProtectedEntityTypeManager{
+		typeName:     typeName,
+		entities:     map[string]*memoryEntity{},
+		retained:     map[string]bool{},
+		failSnapshot: map[string]bool{},
+	}
+}
+
+func (this *memoryProtectedEntityTypeManager) addEntity(id string, labels map[string]string) astrolabe.ProtectedEntityID {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	peid := astrolabe.NewProtectedEntityID(this.typeName, id)
+	this.entities[id] = &memoryEntity{
+		info: astrolabe.NewProtectedEntityInfoWithLabels(peid, id, []astrolabe.DataTransport{},
+			[]astrolabe.DataTransport{}, []astrolabe.DataTransport{}, []astrolabe.ProtectedEntityID{}, labels, nil),
+		snapshots: []astrolabe.ProtectedEntitySnapshotID{},
+	}
+	return peid
+}
+
+func (this *memoryProtectedEntityTypeManager) setData(id string, data []byte, metadata []byte) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.entities[id].data = data
+	this.entities[id].metadata = metadata
+}
+
+func (this *memoryProtectedEntityTypeManager) setComponents(id string, componentSpecs []astrolabe.ComponentSpec) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	info := this.entities[id].info
+	this.entities[id].info = astrolabe.NewProtectedEntityInfoWithComponentSpecs(info.GetID(), info.GetName(),
+		info.GetDataTransports(), info.GetMetadataTransports(), info.GetCombinedTransports(), componentSpecs,
+		info.GetLabels(), info.GetAnnotations())
+}
+
+func (this *memoryProtectedEntityTypeManager) GetTypeName() string {
+	return this.typeName
+}
+
+func (this *memoryProtectedEntityTypeManager) GetProtectedEntity(ctx context.Context,
+	id astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	entity, ok := this.entities[id.GetID()]
+	if !ok || id.GetPeType() != this.typeName {
+		return nil, errors.Errorf("%s not found", id.String())
+	}
+	if id.HasSnapshot() && !entity.hasSnapshot(id.GetSnapshotID()) {
+		return nil, errors.Errorf("snapshot %s not found", id.String())
+	}
+	return memoryProtectedEntity{
+		petm: this,
+		id:   id,
+	}, nil
+}
+
+func (this *memoryEntity) hasSnapshot(snapshotID astrolabe.ProtectedEntitySnapshotID) bool {
+	for _, curSnapshotID := range this.snapshots {
+		if curSnapshotID.String() == snapshotID.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *memoryProtectedEntityTypeManager) GetProtectedEntities(ctx context.Context) ([]astrolabe.ProtectedEntityID, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	peids := []astrolabe.ProtectedEntityID{}
+	for _, entity := range this.entities {
+		peids = append(peids, entity.info.GetID())
+	}
+	return peids, nil
+}
+
+func (this *memoryProtectedEntityTypeManager) Copy(ctx context.Context, pe astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	info, err := pe.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dataReader, err := pe.GetDataReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if dataReader != nil {
+		defer dataReader.Close()
+		// Reports the share of the data that has been read as the progress of the copy
+		data, err = ioutil.ReadAll(astrolabe.NewProgressReader(ctx, dataReader))
+		if err != nil {
+			return nil, err
+		}
+	}
+	metadata, err := readStream(pe.GetMetadataReader(ctx))
+	if err != nil {
+		return nil, err
+	}
+	newPE, err := this.CopyFromInfo(ctx, info, params, options)
+	if err != nil {
+		return nil, err
+	}
+	this.setData(newPE.GetID().GetID(), data, metadata)
+	return newPE, nil
+}
+
+func readStream(reader io.ReadCloser, err error) ([]byte, error) {
+	if err != nil || reader == nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+func (this *memoryProtectedEntityTypeManager) CopyFromInfo(ctx context.Context, info astrolabe.ProtectedEntityInfo,
+	params map[string]map[string]interface{}, options astrolabe.CopyCreateOptions) (astrolabe.ProtectedEntity, error) {
+	this.mutex.Lock()
+	id := info.GetID().GetID()
+	switch options {
+	case astrolabe.AllocateNewObject:
+		this.nextID++
+		id = fmt.Sprintf("copy-%d", this.nextID)
+	case astrolabe.AllocateObjectWithID:
+		if _, ok := this.entities[id]; ok {
+			this.mutex.Unlock()
+			return nil, errors.Errorf("%s already exists", id)
+		}
+	}
+	this.mutex.Unlock()
+	peid := this.addEntity(id, info.GetLabels())
+	if len(info.GetComponentSpecs()) > 0 {
+		this.setComponents(id, info.GetComponentSpecs())
+	}
+	return this.GetProtectedEntity(ctx, peid)
+}
+
+type memoryProtectedEntity struct {
+	petm *memoryProtectedEntityTypeManager
+	id   astrolabe.ProtectedEntityID
+}
+
+func (this memoryProtectedEntity) GetInfo(ctx context.Context) (astrolabe.ProtectedEntityInfo, error) {
+	this.petm.mutex.Lock()
+	defer this.petm.mutex.Unlock()
+	entity := this.petm.entities[this.id.GetID()]
+	data := []astrolabe.DataTransport{}
+	if entity.data != nil {
+		transport, err := astrolabe.NewS3DataTransportForPEID(this.id, this.petm.s3Config)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, transport)
+	}
+	md := []astrolabe.DataTransport{}
+	if entity.metadata != nil {
+		transport, err := astrolabe.NewS3MDTransportForPEID(this.id, this.petm.s3Config)
+		if err != nil {
+			return nil, err
+		}
+		md = append(md, transport)
+	}
+	return astrolabe.NewProtectedEntityInfoWithComponentSpecs(this.id, entity.info.GetName(), data, md,
+		entity.info.GetCombinedTransports(), entity.info.GetComponentSpecs(), entity.info.GetLabels(),
+		entity.info.GetAnnotations()), nil
+}
+
+func (this memoryProtectedEntity) GetCombinedInfo(ctx context.Context) ([]astrolabe.ProtectedEntityInfo, error) {
+	info, err := this.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []astrolabe.ProtectedEntityInfo{info}, nil
+}
+
+func (this memoryProtectedEntity) Snapshot(ctx context.Context,
+	params map[string]map[string]interface{}) (astrolabe.ProtectedEntitySnapshotID, error) {
+	this.petm.mutex.Lock()
+	defer this.petm.mutex.Unlock()
+	if this.petm.failSnapshot[this.id.GetID()] {
+		return astrolabe.ProtectedEntitySnapshotID{}, errors.Errorf("snapshot of %s failed", this.id.String())
+	}
+	entity := this.petm.entities[this.id.GetID()]
+	snapshotID := astrolabe.NewProtectedEntitySnapshotID(fmt.Sprintf("snap-%d", len(entity.snapshots)+1))
+	entity.snapshots = append(entity.snapshots, snapshotID)
+	return snapshotID, nil
+}
+
+func (this memoryProtectedEntity) ListSnapshots(ctx context.Context) ([]astrolabe.ProtectedEntitySnapshotID, error) {
+	this.petm.mutex.Lock()
+	defer this.petm.mutex.Unlock()
+	entity := this.petm.entities[this.id.GetID()]
+	snapshots := make([]astrolabe.ProtectedEntitySnapshotID, len(entity.snapshots))
+	copy(snapshots, entity.snapshots)
+	return snapshots, nil
+}
+
+func (this memoryProtectedEntity) DeleteSnapshot(ctx context.Context, snapshotToDelete astrolabe.ProtectedEntitySnapshotID,
+	params map[string]map[string]interface{}) (bool, error) {
+	this.petm.mutex.Lock()
+	defer this.petm.mutex.Unlock()
+	snapshotPEID := this.id.IDWithSnapshot(snapshotToDelete)
+	if this.petm.retained[snapshotPEID.String()] {
+		return false, astrolabe.RetentionError{
+			ID: snapshotPEID,
+			Retention: astrolabe.Retention{
+				LegalHold: true,
+			},
+		}
+	}
+	entity := this.petm.entities[this.id.GetID()]
+	for snapshotNum, curSnapshotID := range entity.snapshots {
+		if curSnapshotID.String() == snapshotToDelete.String() {
+			entity.snapshots = append(entity.snapshots[:snapshotNum], entity.snapshots[snapshotNum+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (this memoryProtectedEntity) GetInfoForSnapshot(ctx context.Context,
+	snapshotID astrolabe.ProtectedEntitySnapshotID) (*astrolabe.ProtectedEntityInfo, error) {
+	info, err := memoryProtectedEntity{
+		petm: this.petm,
+		id:   this.id.IDWithSnapshot(snapshotID),
+	}.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (this memoryProtectedEntity) GetComponents(ctx context.Context) ([]astrolabe.ProtectedEntity, error) {
+	this.petm.mutex.Lock()
+	componentSpecs := this.petm.entities[this.id.GetID()].info.GetComponentSpecs()
+	this.petm.mutex.Unlock()
+	components := []astrolabe.ProtectedEntity{}
+	for _, curComponentSpec := range componentSpecs {
+		component, err := astrolabe.ResolveComponent(ctx, this.petm.pem, curComponentSpec)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, component)
+	}
+	return components, nil
+}
+
+func (this memoryProtectedEntity) GetID() astrolabe.ProtectedEntityID {
+	return this.id
+}
+
+/*
+memoryDataReader is seekable while the metadata reader is only sized, so that both ways of sizing a stream get
+exercised
+*/
+type memoryDataReader struct {
+	*bytes.Reader
+}
+
+func (this memoryDataReader) Close() error {
+	return nil
+}
+
+func (this memoryProtectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	this.petm.mutex.Lock()
+	defer this.petm.mutex.Unlock()
+	data := this.petm.entities[this.id.GetID()].data
+	if data == nil {
+		return nil, nil
+	}
+	return memoryDataReader{bytes.NewReader(data)}, nil
+}
+
+func (this memoryProtectedEntity) GetMetadataReader(ctx context.Context) (io.ReadCloser, error) {
+	this.petm.mutex.Lock()
+	defer this.petm.mutex.Unlock()
+	metadata := this.petm.entities[this.id.GetID()].metadata
+	if metadata == nil {
+		return nil, nil
+	}
+	return astrolabe.NewSizedReadCloser(ioutil.NopCloser(bytes.NewReader(metadata)), int64(len(metadata))), nil
+}
+
+func (this memoryProtectedEntity) Overwrite(ctx context.Context, sourcePE astrolabe.ProtectedEntity,
+	params map[string]map[string]interface{}, overwriteComponents bool) error {
+	return errors.New("overwrite is not supported")
+}
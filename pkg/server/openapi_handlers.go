@@ -17,16 +17,23 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
 	"github.com/vmware-tanzu/astrolabe/gen/models"
 	"github.com/vmware-tanzu/astrolabe/gen/restapi/operations"
 	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"net/http"
+	"sort"
 	"time"
 )
 
 type OpenAPIAstrolabeHandler struct {
-	pem astrolabe.ProtectedEntityManager
-	tm  *TaskManager
+	pem   astrolabe.ProtectedEntityManager
+	tm    *TaskManager
+	audit *AuditLog
 }
 
 func NewOpenAPIAstrolabeHandler(pem astrolabe.ProtectedEntityManager, tm *TaskManager) OpenAPIAstrolabeHandler {
@@ -35,6 +42,14 @@ func NewOpenAPIAstrolabeHandler(pem astrolabe.ProtectedEntityManager, tm *TaskMa
 		tm:  tm,
 	}
 }
+
+/*
+SetAuditLog serves the events in audit from listAuditEvents.  It must be called before AttachHandlers.
+*/
+func (this *OpenAPIAstrolabeHandler) SetAuditLog(audit *AuditLog) {
+	this.audit = audit
+}
+
 func (this OpenAPIAstrolabeHandler) AttachHandlers(api *operations.AstrolabeAPI) {
 	api.ListServicesHandler = operations.ListServicesHandlerFunc(this.ListServices)
 	api.ListProtectedEntitiesHandler = operations.ListProtectedEntitiesHandlerFunc(this.ListProtectedEntities)
@@ -42,6 +57,155 @@ func (this OpenAPIAstrolabeHandler) AttachHandlers(api *operations.AstrolabeAPI)
 	api.CreateSnapshotHandler = operations.CreateSnapshotHandlerFunc(this.CreateSnapshot)
 	api.ListSnapshotsHandler = operations.ListSnapshotsHandlerFunc(this.ListSnapshots)
 	api.CopyProtectedEntityHandler = operations.CopyProtectedEntityHandlerFunc(this.CopyProtectedEntity)
+	api.GetUsageHandler = operations.GetUsageHandlerFunc(this.GetUsage)
+	api.GetRetentionHandler = operations.GetRetentionHandlerFunc(this.GetRetention)
+	api.UpdateRetentionHandler = operations.UpdateRetentionHandlerFunc(this.UpdateRetention)
+	api.DeleteProtectedEntityHandler = operations.DeleteProtectedEntityHandlerFunc(this.DeleteProtectedEntity)
+	api.ListDeletedSnapshotsHandler = operations.ListDeletedSnapshotsHandlerFunc(this.ListDeletedSnapshots)
+	api.UndeleteSnapshotHandler = operations.UndeleteSnapshotHandlerFunc(this.UndeleteSnapshot)
+	api.RehydrateSnapshotHandler = operations.RehydrateSnapshotHandlerFunc(this.RehydrateSnapshot)
+	api.ListTasksHandler = operations.ListTasksHandlerFunc(this.ListTasks)
+	api.GetTaskInfoHandler = operations.GetTaskInfoHandlerFunc(this.GetTaskInfo)
+	api.WatchTaskEventsHandler = operations.WatchTaskEventsHandlerFunc(this.WatchTaskEvents)
+	api.ListTaskNexusHandler = operations.ListTaskNexusHandlerFunc(this.ListTaskNexus)
+	api.CreateTaskNexusHandler = operations.CreateTaskNexusHandlerFunc(this.CreateTaskNexus)
+	api.WaitOnTaskNexusHandler = operations.WaitOnTaskNexusHandlerFunc(this.WaitOnTaskNexus)
+	api.ListAuditEventsHandler = operations.ListAuditEventsHandlerFunc(this.ListAuditEvents)
+}
+
+/*
+Error payloads returned with 4xx and 5xx responses.  The code is the HTTP status of the response.
+*/
+func errorPayload(code int, message string) *models.Error {
+	code32 := int32(code)
+	return &models.Error{
+		Code:    &code32,
+		Message: &message,
+	}
+}
+
+func badRequestError(format string, args ...interface{}) *models.Error {
+	return errorPayload(http.StatusBadRequest, fmt.Sprintf(format, args...))
+}
+
+func notFoundError(format string, args ...interface{}) *models.Error {
+	return errorPayload(http.StatusNotFound, fmt.Sprintf(format, args...))
+}
+
+func conflictError(err error) *models.Error {
+	return errorPayload(http.StatusConflict, err.Error())
+}
+
+func internalServerError(err error) *models.Error {
+	return errorPayload(http.StatusInternalServerError, err.Error())
+}
+
+func isBadRequest(apiErr *models.Error) bool {
+	return *apiErr.Code == http.StatusBadRequest
+}
+
+/*
+Returns the type manager for service and the parsed protected entity ID.  The error payload has a 404 code if the
+service does not exist and a 400 code if the ID cannot be parsed, or does not have a snapshot ID and requireSnapshot
+is set.
+*/
+func (this OpenAPIAstrolabeHandler) lookupService(service string, peidStr string,
+	requireSnapshot bool) (astrolabe.ProtectedEntityTypeManager, astrolabe.ProtectedEntityID, *models.Error) {
+	petm := this.pem.GetProtectedEntityTypeManager(service)
+	if petm == nil {
+		return nil, astrolabe.ProtectedEntityID{}, notFoundError("service %s not found", service)
+	}
+	peid, err := astrolabe.NewProtectedEntityIDFromString(peidStr)
+	if err != nil {
+		return nil, astrolabe.ProtectedEntityID{}, badRequestError("invalid protected entity ID %s: %v", peidStr, err)
+	}
+	if requireSnapshot && !peid.HasSnapshot() {
+		return nil, astrolabe.ProtectedEntityID{}, badRequestError("%s does not have a snapshot ID", peidStr)
+	}
+	return petm, peid, nil
+}
+
+/*
+requestContext returns the context of request, which carries the identity of the caller, or the background context if
+there is no request
+*/
+func requestContext(request *http.Request) context.Context {
+	if request == nil {
+		return context.Background()
+	}
+	return request.Context()
+}
+
+func getProtectedEntity(ctx context.Context, petm astrolabe.ProtectedEntityTypeManager,
+	peid astrolabe.ProtectedEntityID) (astrolabe.ProtectedEntity, *models.Error) {
+	pe, err := petm.GetProtectedEntity(ctx, peid)
+	if err != nil {
+		return nil, notFoundError("protected entity %s not found: %v", peid.String(), err)
+	}
+	return pe, nil
+}
+
+/*
+Converts the params passed in the REST API to the map of param groups used by the astrolabe APIs
+*/
+func getParamsFromModel(mparams models.OperationParamList) map[string]map[string]interface{} {
+	params := make(map[string]map[string]interface{})
+	for _, curPEParams := range mparams {
+		if curPEParams != nil && curPEParams.Value != nil {
+			curPEParamsMap := make(map[string]interface{})
+			for _, curParam := range curPEParams.Value {
+				if curParam != nil {
+					curPEParamsMap[curParam.Key] = curParam.Value
+				}
+			}
+			params[curPEParams.Key] = curPEParamsMap
+		}
+	}
+	return params
+}
+
+/*
+Converts the list parameters of the REST API to ListOptions
+*/
+func getListOptions(labelSelector *string, maxResults *int32, continuationToken *string, prefix *string,
+	name *string, orderBy *string, order *string) (astrolabe.ListOptions, *models.Error) {
+	selector, err := astrolabe.ParseLabelSelector(swag.StringValue(labelSelector))
+	if err != nil {
+		return astrolabe.ListOptions{}, badRequestError("invalid label selector: %v", err)
+	}
+	options := astrolabe.ListOptions{
+		ContinuationToken: swag.StringValue(continuationToken),
+		Prefix:            swag.StringValue(prefix),
+		Name:              swag.StringValue(name),
+		LabelSelector:     selector,
+		OrderBy:           astrolabe.ListOrderBy(swag.StringValue(orderBy)),
+		Descending:        swag.StringValue(order) == "desc",
+	}
+	if maxResults != nil {
+		if *maxResults < 1 {
+			return astrolabe.ListOptions{}, badRequestError("maxResults must be at least 1")
+		}
+		options.MaxResults = int(*maxResults)
+	}
+	if err := options.Validate(); err != nil {
+		return astrolabe.ListOptions{}, badRequestError("%v", err)
+	}
+	return options, nil
+}
+
+/*
+Converts a page of IDs to the ProtectedEntityList returned by the REST API
+*/
+func getModelProtectedEntityList(page astrolabe.ProtectedEntityIDPage) *models.ProtectedEntityList {
+	mpeids := make([]models.ProtectedEntityID, len(page.IDs))
+	for peidNum, peid := range page.IDs {
+		mpeids[peidNum] = peid.GetModelProtectedEntityID()
+	}
+	return &models.ProtectedEntityList{
+		List:              mpeids,
+		Truncated:         page.ContinuationToken != "",
+		ContinuationToken: page.ContinuationToken,
+	}
 }
 
 func (this OpenAPIAstrolabeHandler) ListServices(params operations.ListServicesParams) middleware.Responder {
@@ -58,118 +222,489 @@ func (this OpenAPIAstrolabeHandler) ListServices(params operations.ListServicesP
 func (this OpenAPIAstrolabeHandler) ListProtectedEntities(params operations.ListProtectedEntitiesParams) middleware.Responder {
 	petm := this.pem.GetProtectedEntityTypeManager(params.Service)
 	if petm == nil {
-		return operations.NewListProtectedEntitiesNotFound()
+		return operations.NewListProtectedEntitiesNotFound().WithPayload(notFoundError("service %s not found",
+			params.Service))
+	}
+	options, apiErr := getListOptions(params.LabelSelector, params.MaxResults, params.ContinuationToken,
+		params.Prefix, params.Name, params.OrderBy, params.Order)
+	if apiErr != nil {
+		return operations.NewListProtectedEntitiesBadRequest().WithPayload(apiErr)
 	}
-	peids, err := petm.GetProtectedEntities(context.Background())
+	if params.IdsAfter != nil {
+		if params.ContinuationToken != nil || params.OrderBy != nil || params.Order != nil {
+			return operations.NewListProtectedEntitiesBadRequest().WithPayload(badRequestError(
+				"idsAfter cannot be combined with continuationToken, orderBy or order"))
+		}
+		options.ContinuationToken = astrolabe.ContinuationTokenAfter(*params.IdsAfter)
+	}
+	page, err := astrolabe.GetProtectedEntitiesPage(requestContext(params.HTTPRequest), petm, options)
 	if err != nil {
+		if astrolabe.IsInvalidListOptionsError(err) {
+			return operations.NewListProtectedEntitiesBadRequest().WithPayload(badRequestError("%v", err))
+		}
+		return operations.NewListProtectedEntitiesInternalServerError().WithPayload(internalServerError(err))
+	}
+	return operations.NewListProtectedEntitiesOK().WithPayload(getModelProtectedEntityList(page))
+}
 
+func (this OpenAPIAstrolabeHandler) GetProtectedEntityInfo(params operations.GetProtectedEntityInfoParams) middleware.Responder {
+	ctx := requestContext(params.HTTPRequest)
+	petm, peid, apiErr := this.lookupService(params.Service, params.ProtectedEntityID, false)
+	if apiErr != nil {
+		if isBadRequest(apiErr) {
+			return operations.NewGetProtectedEntityInfoBadRequest().WithPayload(apiErr)
+		}
+		return operations.NewGetProtectedEntityInfoNotFound().WithPayload(apiErr)
 	}
-	mpeids := make([]models.ProtectedEntityID, len(peids))
-	for peidNum, peid := range peids {
-		mpeids[peidNum] = models.ProtectedEntityID(peid.String())
+	pe, apiErr := getProtectedEntity(ctx, petm, peid)
+	if apiErr != nil {
+		return operations.NewGetProtectedEntityInfoNotFound().WithPayload(apiErr)
 	}
-	peList := models.ProtectedEntityList{
-		List:      mpeids,
-		Truncated: false,
+	peInfo, err := pe.GetInfo(ctx)
+	if err != nil {
+		return operations.NewGetProtectedEntityInfoInternalServerError().WithPayload(internalServerError(err))
 	}
-	return operations.NewListProtectedEntitiesOK().WithPayload(&peList)
+	peInfoResponse := peInfo.GetModelProtectedEntityInfo()
+	return operations.NewGetProtectedEntityInfoOK().WithPayload(&peInfoResponse)
 }
 
-func (this OpenAPIAstrolabeHandler) GetProtectedEntityInfo(params operations.GetProtectedEntityInfoParams) middleware.Responder {
+/*
+Snapshots use the context of the request so that a snapshot waiting for admission is abandoned if the client goes away
+*/
+func (this OpenAPIAstrolabeHandler) CreateSnapshot(params operations.CreateSnapshotParams) middleware.Responder {
+	ctx := requestContext(params.HTTPRequest)
+	petm, peid, apiErr := this.lookupService(params.Service, params.ProtectedEntityID, false)
+	if apiErr != nil {
+		if isBadRequest(apiErr) {
+			return operations.NewCreateSnapshotBadRequest().WithPayload(apiErr)
+		}
+		return operations.NewCreateSnapshotNotFound().WithPayload(apiErr)
+	}
+	if peid.HasSnapshot() {
+		return operations.NewCreateSnapshotBadRequest().WithPayload(badRequestError("cannot snapshot snapshot %s",
+			peid.String()))
+	}
+	pe, apiErr := getProtectedEntity(ctx, petm, peid)
+	if apiErr != nil {
+		return operations.NewCreateSnapshotNotFound().WithPayload(apiErr)
+	}
+	snapshotID, err := pe.Snapshot(ctx, getParamsFromModel(params.Params))
+	if err != nil {
+		if IsLockConflictError(err) {
+			return operations.NewCreateSnapshotConflict().WithPayload(conflictError(err))
+		}
+		if astrolabe.IsLabelsNotSupportedError(err) {
+			return operations.NewCreateSnapshotBadRequest().WithPayload(badRequestError("%v", err))
+		}
+		return operations.NewCreateSnapshotInternalServerError().WithPayload(internalServerError(err))
+	}
+	return operations.NewCreateSnapshotOK().WithPayload(snapshotID.GetModelProtectedEntitySnapshotID())
+}
+
+func (this OpenAPIAstrolabeHandler) ListSnapshots(params operations.ListSnapshotsParams) middleware.Responder {
+	ctx := requestContext(params.HTTPRequest)
+	petm, peid, apiErr := this.lookupService(params.Service, params.ProtectedEntityID, false)
+	if apiErr != nil {
+		if isBadRequest(apiErr) {
+			return operations.NewListSnapshotsBadRequest().WithPayload(apiErr)
+		}
+		return operations.NewListSnapshotsNotFound().WithPayload(apiErr)
+	}
+	options, apiErr := getListOptions(params.LabelSelector, params.MaxResults, params.ContinuationToken,
+		params.Prefix, params.Name, params.OrderBy, params.Order)
+	if apiErr != nil {
+		return operations.NewListSnapshotsBadRequest().WithPayload(apiErr)
+	}
+	pe, apiErr := getProtectedEntity(ctx, petm, peid)
+	if apiErr != nil {
+		return operations.NewListSnapshotsNotFound().WithPayload(apiErr)
+	}
+	page, err := astrolabe.ListSnapshotsPage(ctx, petm, pe, options)
+	if err != nil {
+		if astrolabe.IsInvalidListOptionsError(err) {
+			return operations.NewListSnapshotsBadRequest().WithPayload(badRequestError("%v", err))
+		}
+		return operations.NewListSnapshotsInternalServerError().WithPayload(internalServerError(err))
+	}
+	return operations.NewListSnapshotsOK().WithPayload(getModelProtectedEntityList(page))
+}
 
+/*
+Maps the copy modes of the REST API to CopyCreateOptions
+*/
+var copyModes = map[string]astrolabe.CopyCreateOptions{
+	"create":     astrolabe.AllocateObjectWithID,
+	"create_new": astrolabe.AllocateNewObject,
+	"update":     astrolabe.UpdateExistingObject,
+}
+
+/*
+Copies run in the background, the response carries the ID of the task that reports the outcome.  The task result is
+the ID of the new protected entity.
+*/
+func (this OpenAPIAstrolabeHandler) CopyProtectedEntity(params operations.CopyProtectedEntityParams) middleware.Responder {
 	petm := this.pem.GetProtectedEntityTypeManager(params.Service)
 	if petm == nil {
+		return operations.NewCopyProtectedEntityNotFound().WithPayload(notFoundError("service %s not found",
+			params.Service))
+	}
+	options, ok := copyModes[params.Mode]
+	if !ok {
+		return operations.NewCopyProtectedEntityBadRequest().WithPayload(badRequestError("unknown copy mode %s",
+			params.Mode))
+	}
+	if params.Body == nil || params.Body.ProtectedEntityInfo == nil {
+		return operations.NewCopyProtectedEntityBadRequest().WithPayload(badRequestError("protectedEntityInfo is required"))
+	}
+	pei, err := astrolabe.NewProtectedEntityInfoFromModel(params.Body.ProtectedEntityInfo)
+	if err != nil {
+		return operations.NewCopyProtectedEntityBadRequest().WithPayload(badRequestError("invalid protectedEntityInfo: %v",
+			err))
+	}
+	copyParams := getParamsFromModel(params.Body.CopyParams)
+	task := startAsyncTask(requestContext(params.HTTPRequest),
+		fmt.Sprintf("copying %s to %s", pei.GetID().String(), params.Service),
+		func(ctx context.Context) (interface{}, error) {
+			newPE, err := petm.CopyFromInfo(ctx, pei, copyParams, options)
+			if err != nil {
+				return nil, err
+			}
+			return newPE.GetID().GetModelProtectedEntityID(), nil
+		})
+	this.tm.AddTask(task)
+	return operations.NewCopyProtectedEntityAccepted().WithPayload(&models.CreateInProgressResponse{
+		TaskID: task.GetID().GetModelTaskID(),
+	})
+}
 
+func (this OpenAPIAstrolabeHandler) GetUsage(params operations.GetUsageParams) middleware.Responder {
+	service := swag.StringValue(params.Service)
+	if service != "" {
+		petm := this.pem.GetProtectedEntityTypeManager(service)
+		if petm == nil {
+			return operations.NewGetUsageNotFound().WithPayload(notFoundError("service %s not found", service))
+		}
+		if _, ok := astrolabe.UnwrapProtectedEntityTypeManager(petm).(astrolabe.UsageReporter); !ok {
+			return operations.NewGetUsageNotFound().WithPayload(notFoundError("service %s does not report usage",
+				service))
+		}
 	}
-	peid, err := astrolabe.NewProtectedEntityIDFromString(params.ProtectedEntityID)
+	usage, err := astrolabe.GetRepositoryUsage(requestContext(params.HTTPRequest), this.pem, service,
+		swag.StringValue(params.IDPrefix))
 	if err != nil {
+		return operations.NewGetUsageInternalServerError().WithPayload(internalServerError(err))
+	}
+	return operations.NewGetUsageOK().WithPayload(usage)
+}
 
+/*
+Returns the retention manager for the service and the snapshot ID.  The error payload has a 404 code if the service
+does not exist, does not support retention or the snapshot does not exist and a 400 code if the ID is not a snapshot
+ID.
+*/
+func (this OpenAPIAstrolabeHandler) getRetentionManager(ctx context.Context, service string,
+	peidStr string) (astrolabe.RetentionManager, astrolabe.ProtectedEntityID, *models.Error) {
+	petm, peid, apiErr := this.lookupService(service, peidStr, true)
+	if apiErr != nil {
+		return nil, peid, apiErr
+	}
+	rm, ok := astrolabe.UnwrapProtectedEntityTypeManager(petm).(astrolabe.RetentionManager)
+	if !ok {
+		return nil, peid, notFoundError("service %s does not support retention", service)
+	}
+	if _, apiErr := getProtectedEntity(ctx, petm, peid); apiErr != nil {
+		return nil, peid, apiErr
+	}
+	return rm, peid, nil
+}
+
+func (this OpenAPIAstrolabeHandler) GetRetention(params operations.GetRetentionParams) middleware.Responder {
+	ctx := requestContext(params.HTTPRequest)
+	rm, peid, apiErr := this.getRetentionManager(ctx, params.Service, params.ProtectedEntityID)
+	if apiErr != nil {
+		if isBadRequest(apiErr) {
+			return operations.NewGetRetentionBadRequest().WithPayload(apiErr)
+		}
+		return operations.NewGetRetentionNotFound().WithPayload(apiErr)
 	}
-	pe, err := petm.GetProtectedEntity(context.Background(), peid)
+	retention, err := rm.GetRetention(ctx, peid)
 	if err != nil {
+		return operations.NewGetRetentionInternalServerError().WithPayload(internalServerError(err))
+	}
+	return operations.NewGetRetentionOK().WithPayload(retention.GetModelRetention())
+}
 
+func (this OpenAPIAstrolabeHandler) UpdateRetention(params operations.UpdateRetentionParams) middleware.Responder {
+	ctx := requestContext(params.HTTPRequest)
+	rm, peid, apiErr := this.getRetentionManager(ctx, params.Service, params.ProtectedEntityID)
+	if apiErr == nil && params.Update == nil {
+		apiErr = badRequestError("retention update is required")
 	}
-	peInfo, err := pe.GetInfo(context.Background())
-	peInfoResponse := peInfo.GetModelProtectedEntityInfo()
-	return operations.NewGetProtectedEntityInfoOK().WithPayload(&peInfoResponse)
+	if apiErr != nil {
+		if isBadRequest(apiErr) {
+			return operations.NewUpdateRetentionBadRequest().WithPayload(apiErr)
+		}
+		return operations.NewUpdateRetentionNotFound().WithPayload(apiErr)
+	}
+	if !time.Time(params.Update.RetainUntil).IsZero() {
+		retainUntil := time.Time(params.Update.RetainUntil)
+		current, err := rm.GetRetention(ctx, peid)
+		if err != nil {
+			return operations.NewUpdateRetentionInternalServerError().WithPayload(internalServerError(err))
+		}
+		if retainUntil.Before(current.RetainUntil) {
+			return operations.NewUpdateRetentionBadRequest().WithPayload(badRequestError(
+				"cannot shorten retention from %s to %s", current.RetainUntil.UTC().Format(time.RFC3339),
+				retainUntil.UTC().Format(time.RFC3339)))
+		}
+		if !retainUntil.After(time.Now()) {
+			return operations.NewUpdateRetentionBadRequest().WithPayload(badRequestError(
+				"retain until date %s is in the past", retainUntil.UTC().Format(time.RFC3339)))
+		}
+		if err := rm.ExtendRetention(ctx, peid, retainUntil); err != nil {
+			return operations.NewUpdateRetentionInternalServerError().WithPayload(internalServerError(err))
+		}
+	}
+	if params.Update.LegalHold != nil {
+		if err := rm.SetLegalHold(ctx, peid, *params.Update.LegalHold); err != nil {
+			return operations.NewUpdateRetentionInternalServerError().WithPayload(internalServerError(err))
+		}
+	}
+	retention, err := rm.GetRetention(ctx, peid)
+	if err != nil {
+		return operations.NewUpdateRetentionInternalServerError().WithPayload(internalServerError(err))
+	}
+	return operations.NewUpdateRetentionOK().WithPayload(retention.GetModelRetention())
 }
 
-func (this OpenAPIAstrolabeHandler) CreateSnapshot(params operations.CreateSnapshotParams) middleware.Responder {
-	petm := this.pem.GetProtectedEntityTypeManager(params.Service)
+func (this OpenAPIAstrolabeHandler) DeleteProtectedEntity(params operations.DeleteProtectedEntityParams) middleware.Responder {
+	ctx := requestContext(params.HTTPRequest)
+	// Only snapshots can be deleted through the API for now
+	petm, peid, apiErr := this.lookupService(params.Service, params.ProtectedEntityID, true)
+	if apiErr != nil {
+		if isBadRequest(apiErr) {
+			return operations.NewDeleteProtectedEntityBadRequest().WithPayload(apiErr)
+		}
+		return operations.NewDeleteProtectedEntityNotFound().WithPayload(apiErr)
+	}
+	pe, apiErr := getProtectedEntity(ctx, petm, peid)
+	if apiErr != nil {
+		return operations.NewDeleteProtectedEntityNotFound().WithPayload(apiErr)
+	}
+	_, err := pe.DeleteSnapshot(ctx, peid.GetSnapshotID(), make(map[string]map[string]interface{}))
+	if err != nil {
+		if astrolabe.IsRetentionError(err) || IsLockConflictError(err) {
+			return operations.NewDeleteProtectedEntityConflict().WithPayload(conflictError(err))
+		}
+		return operations.NewDeleteProtectedEntityInternalServerError().WithPayload(internalServerError(err))
+	}
+	return operations.NewDeleteProtectedEntityOK().WithPayload(peid.GetModelProtectedEntityID())
+}
+
+func (this OpenAPIAstrolabeHandler) getSoftDeleteManager(service string) (astrolabe.SoftDeleteManager, *models.Error) {
+	petm := this.pem.GetProtectedEntityTypeManager(service)
 	if petm == nil {
+		return nil, notFoundError("service %s not found", service)
+	}
+	sdm, ok := astrolabe.UnwrapProtectedEntityTypeManager(petm).(astrolabe.SoftDeleteManager)
+	if !ok {
+		return nil, notFoundError("service %s does not support soft delete", service)
+	}
+	return sdm, nil
+}
 
+func (this OpenAPIAstrolabeHandler) ListDeletedSnapshots(params operations.ListDeletedSnapshotsParams) middleware.Responder {
+	sdm, apiErr := this.getSoftDeleteManager(params.Service)
+	if apiErr != nil {
+		return operations.NewListDeletedSnapshotsNotFound().WithPayload(apiErr)
 	}
 	peid, err := astrolabe.NewProtectedEntityIDFromString(params.ProtectedEntityID)
 	if err != nil {
-
+		return operations.NewListDeletedSnapshotsBadRequest().WithPayload(badRequestError(
+			"invalid protected entity ID %s: %v", params.ProtectedEntityID, err))
 	}
+	deletedSnapshots, err := sdm.ListDeletedSnapshots(requestContext(params.HTTPRequest), peid)
+	if err != nil {
+		return operations.NewListDeletedSnapshotsInternalServerError().WithPayload(internalServerError(err))
+	}
+	mdeletedSnapshots := make([]*models.DeletedSnapshot, len(deletedSnapshots))
+	for deletedNum, deletedSnapshot := range deletedSnapshots {
+		mdeletedSnapshots[deletedNum] = deletedSnapshot.GetModelDeletedSnapshot()
+	}
+	return operations.NewListDeletedSnapshotsOK().WithPayload(&models.DeletedSnapshotList{
+		List: mdeletedSnapshots,
+	})
+}
 
-	snapshotParams := make(map[string]map[string]interface{})
+func (this OpenAPIAstrolabeHandler) UndeleteSnapshot(params operations.UndeleteSnapshotParams) middleware.Responder {
+	ctx := requestContext(params.HTTPRequest)
+	sdm, apiErr := this.getSoftDeleteManager(params.Service)
+	if apiErr != nil {
+		return operations.NewUndeleteSnapshotNotFound().WithPayload(apiErr)
+	}
+	peid, err := astrolabe.NewProtectedEntityIDFromString(params.ProtectedEntityID)
+	if err != nil || !peid.HasSnapshot() {
+		return operations.NewUndeleteSnapshotBadRequest().WithPayload(badRequestError(
+			"%s is not a protected entity snapshot ID", params.ProtectedEntityID))
+	}
+	deletedSnapshots, err := sdm.ListDeletedSnapshots(ctx, peid)
+	if err != nil {
+		return operations.NewUndeleteSnapshotInternalServerError().WithPayload(internalServerError(err))
+	}
+	if len(deletedSnapshots) == 0 {
+		return operations.NewUndeleteSnapshotNotFound().WithPayload(notFoundError("snapshot %s is not deleted",
+			peid.String()))
+	}
+	err = sdm.UndeleteSnapshot(ctx, peid)
+	if err != nil {
+		return operations.NewUndeleteSnapshotInternalServerError().WithPayload(internalServerError(err))
+	}
+	return operations.NewUndeleteSnapshotOK().WithPayload(peid.GetModelProtectedEntityID())
+}
 
-	if params.Params != nil {
-		for _, curPEParams := range params.Params {
-			if curPEParams.Value != nil {
-				curPEParamsMap := make(map[string]interface{})
-				for _, curParam := range curPEParams.Value {
-					curPEParamsMap[curParam.Key] = curParam.Value
-				}
-				snapshotParams[curPEParams.Key] = curPEParamsMap
-			}
+func (this OpenAPIAstrolabeHandler) RehydrateSnapshot(params operations.RehydrateSnapshotParams) middleware.Responder {
+	ctx := requestContext(params.HTTPRequest)
+	petm, peid, apiErr := this.lookupService(params.Service, params.ProtectedEntityID, true)
+	if apiErr != nil {
+		if isBadRequest(apiErr) {
+			return operations.NewRehydrateSnapshotBadRequest().WithPayload(apiErr)
 		}
+		return operations.NewRehydrateSnapshotNotFound().WithPayload(apiErr)
+	}
+	rehydrator, ok := astrolabe.UnwrapProtectedEntityTypeManager(petm).(astrolabe.Rehydrator)
+	if !ok {
+		return operations.NewRehydrateSnapshotNotFound().WithPayload(notFoundError(
+			"service %s does not support tiering", params.Service))
+	}
+	if _, apiErr := getProtectedEntity(ctx, petm, peid); apiErr != nil {
+		return operations.NewRehydrateSnapshotNotFound().WithPayload(apiErr)
 	}
-	pe, err := petm.GetProtectedEntity(context.Background(), peid)
+	task, err := rehydrator.Rehydrate(ctx, peid)
 	if err != nil {
+		return operations.NewRehydrateSnapshotInternalServerError().WithPayload(internalServerError(err))
+	}
+	this.tm.AddTask(task)
+	return operations.NewRehydrateSnapshotAccepted().WithPayload(&models.CreateInProgressResponse{
+		TaskID: task.GetID().GetModelTaskID(),
+	})
+}
 
+func (this OpenAPIAstrolabeHandler) ListTasks(params operations.ListTasksParams) middleware.Responder {
+	taskIDs := this.tm.ListTasks()
+	taskIDList := make(models.TaskIDList, len(taskIDs))
+	for taskNum, taskID := range taskIDs {
+		taskIDList[taskNum] = taskID.GetModelTaskID()
 	}
-	snapshotID, err := pe.Snapshot(context.Background(), snapshotParams)
-	if err != nil {
+	return operations.NewListTasksOK().WithPayload(taskIDList)
+}
 
+func (this OpenAPIAstrolabeHandler) GetTaskInfo(params operations.GetTaskInfoParams) middleware.Responder {
+	task, ok := this.tm.RetrieveTask(astrolabe.NewTaskIDFromString(params.TaskID))
+	if !ok {
+		return operations.NewGetTaskInfoNotFound().WithPayload(notFoundError("task %s not found", params.TaskID))
 	}
+	taskInfo := task.GetModelTaskInfo()
+	return operations.NewGetTaskInfoOK().WithPayload(&taskInfo)
+}
 
-	return operations.NewCreateSnapshotOK().WithPayload(snapshotID.GetModelProtectedEntitySnapshotID())
+/*
+WatchTaskEvents streams the task events as Server-Sent Events.  The response is written directly as the operation
+only produces text/event-stream, the 404 payload is written as JSON.
+*/
+func (this OpenAPIAstrolabeHandler) WatchTaskEvents(params operations.WatchTaskEventsParams) middleware.Responder {
+	taskID := swag.StringValue(params.TaskID)
+	if taskID != "" {
+		if _, ok := this.tm.RetrieveTask(astrolabe.NewTaskIDFromString(taskID)); !ok {
+			payload, _ := json.Marshal(notFoundError("task %s not found", taskID))
+			return middleware.ResponderFunc(func(w http.ResponseWriter, _ runtime.Producer) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write(payload)
+			})
+		}
+	}
+	ctx := requestContext(params.HTTPRequest)
+	return middleware.ResponderFunc(func(w http.ResponseWriter, _ runtime.Producer) {
+		this.tm.StreamTaskEvents(ctx, w, taskID, swag.StringValue(params.LastEventID))
+	})
 }
 
-func (this OpenAPIAstrolabeHandler) ListSnapshots(params operations.ListSnapshotsParams) middleware.Responder {
-	return nil
+func (this OpenAPIAstrolabeHandler) ListTaskNexus(params operations.ListTaskNexusParams) middleware.Responder {
+	nexusList := models.TaskNexusList{}
+	for nexusID, taskIDs := range this.tm.ListNexus() {
+		associatedTasks := make([]models.TaskID, len(taskIDs))
+		for taskNum, taskID := range taskIDs {
+			associatedTasks[taskNum] = taskID.GetModelTaskID()
+		}
+		nexusList = append(nexusList, &models.TaskNexusInfo{
+			ID:              models.TaskNexusID(nexusID),
+			AssociatedTasks: associatedTasks,
+		})
+	}
+	sort.Slice(nexusList, func(i, j int) bool {
+		return nexusList[i].ID < nexusList[j].ID
+	})
+	return operations.NewListTaskNexusOK().WithPayload(nexusList)
 }
 
-func (this OpenAPIAstrolabeHandler) CopyProtectedEntity(params operations.CopyProtectedEntityParams) middleware.Responder {
-	petm := this.pem.GetProtectedEntityTypeManager(params.Service)
-	if petm == nil {
+func (this OpenAPIAstrolabeHandler) CreateTaskNexus(params operations.CreateTaskNexusParams) middleware.Responder {
+	return operations.NewCreateTaskNexusOK().WithPayload(models.TaskNexusID(this.tm.CreateNexus()))
+}
 
+func (this OpenAPIAstrolabeHandler) WaitOnTaskNexus(params operations.WaitOnTaskNexusParams) middleware.Responder {
+	if params.WaitTime < 0 || params.LastFinishedNS < 0 {
+		return operations.NewWaitOnTaskNexusBadRequest().WithPayload(badRequestError(
+			"waitTime and lastFinishedNS cannot be negative"))
 	}
-	pei, err := astrolabe.NewProtectedEntityInfoFromModel(params.Body.ProtectedEntityInfo)
-	if err != nil {
-
+	ctx := requestContext(params.HTTPRequest)
+	finished, ok := this.tm.WaitOnNexus(ctx, params.TaskNexusID, time.Unix(0, params.LastFinishedNS),
+		time.Duration(params.WaitTime)*time.Millisecond)
+	if !ok {
+		return operations.NewWaitOnTaskNexusNotFound().WithPayload(notFoundError("task nexus %s not found",
+			params.TaskNexusID))
+	}
+	finishedInfo := make([]*models.TaskInfo, len(finished))
+	for taskNum, task := range finished {
+		taskInfo := task.GetModelTaskInfo()
+		finishedInfo[taskNum] = &taskInfo
 	}
-	copyParams := make(map[string]map[string]interface{})
+	return operations.NewWaitOnTaskNexusOK().WithPayload(&models.TaskNexusResponse{
+		ID:       models.TaskNexusID(params.TaskNexusID),
+		Finished: finishedInfo,
+	})
+}
 
-	if params.Body.CopyParams != nil {
-		for _, curPEParams := range params.Body.CopyParams {
-			if curPEParams.Value != nil {
-				curPEParamsMap := make(map[string]interface{})
-				for _, curParam := range curPEParams.Value {
-					curPEParamsMap[curParam.Key] = curParam.Value
-				}
-				copyParams[curPEParams.Key] = curPEParamsMap
-			}
+func (this OpenAPIAstrolabeHandler) ListAuditEvents(params operations.ListAuditEventsParams) middleware.Responder {
+	if this.audit == nil {
+		return operations.NewListAuditEventsNotFound().WithPayload(notFoundError("the audit log is not enabled"))
+	}
+	filter := AuditFilter{}
+	if params.ProtectedEntityID != nil {
+		peid, err := astrolabe.NewProtectedEntityIDFromString(*params.ProtectedEntityID)
+		if err != nil {
+			return operations.NewListAuditEventsBadRequest().WithPayload(badRequestError(
+				"invalid protected entity ID %s: %v", *params.ProtectedEntityID, err))
 		}
+		filter.ProtectedEntityID = &peid
+	}
+	if params.From != nil {
+		filter.From = time.Time(*params.From)
+	}
+	if params.To != nil {
+		filter.To = time.Time(*params.To)
 	}
-	startedTime := time.Now()
-	newPE, err := petm.CopyFromInfo(context.Background(), pei, copyParams, astrolabe.AllocateNewObject)
-	var taskStatus astrolabe.TaskStatus
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return operations.NewListAuditEventsBadRequest().WithPayload(badRequestError("from must be before to"))
+	}
+	events, err := this.audit.Query(filter)
 	if err != nil {
-		taskStatus = astrolabe.Failed
-	} else {
-		taskStatus = astrolabe.Success
+		return operations.NewListAuditEventsInternalServerError().WithPayload(internalServerError(err))
+	}
+	mevents := make([]*models.AuditEvent, len(events))
+	for eventNum, event := range events {
+		mevents[eventNum] = event.GetModelAuditEvent()
 	}
-	// Fake a task for now
-	task := astrolabe.NewGenericTask()
-	task.Completed = true
-	task.StartedTime = startedTime
-	task.FinishedTime = time.Now()
-	task.Progress = 100
-	task.TaskStatus = taskStatus
-	task.Result = newPE.GetID().GetModelProtectedEntityID()
-	return operations.NewCopyProtectedEntityAccepted()
+	return operations.NewListAuditEventsOK().WithPayload(&models.AuditEventList{
+		List: mevents,
+	})
 }
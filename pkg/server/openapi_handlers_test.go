@@ -0,0 +1,481 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package server
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+	"github.com/vmware-tanzu/astrolabe/gen/restapi/operations"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+func newTestHandler() (OpenAPIAstrolabeHandler, *memoryProtectedEntityTypeManager) {
+	petm := newMemoryProtectedEntityTypeManager("mem")
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	return NewOpenAPIAstrolabeHandler(pem, NewTaskManager()), petm
+}
+
+func assertError(t *testing.T, code int, payload *models.Error) {
+	assert.Assert(t, payload != nil, "missing error payload")
+	assert.Equal(t, int32(code), *payload.Code)
+	assert.Assert(t, *payload.Message != "", "missing error message")
+}
+
+/*
+Polls getTaskInfo until the task finishes
+*/
+func waitForTask(t *testing.T, handler OpenAPIAstrolabeHandler, taskID models.TaskID) *models.TaskInfo {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		resp := handler.GetTaskInfo(operations.GetTaskInfoParams{TaskID: string(taskID)})
+		taskInfoOK, ok := resp.(*operations.GetTaskInfoOK)
+		if !ok {
+			t.Fatalf("Got %T from GetTaskInfo", resp)
+		}
+		if *taskInfoOK.Payload.Completed {
+			return taskInfoOK.Payload
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Task " + string(taskID) + " did not finish")
+	return nil
+}
+
+func TestListProtectedEntitiesHandler(t *testing.T) {
+	handler, petm := newTestHandler()
+	petm.addEntity("c", map[string]string{"app": "db"})
+	petm.addEntity("a", map[string]string{"app": "web"})
+	petm.addEntity("b", map[string]string{"app": "web"})
+
+	resp := handler.ListServices(operations.ListServicesParams{})
+	assert.DeepEqual(t, []string{"mem"}, resp.(*operations.ListServicesOK).Payload.Services)
+
+	resp = handler.ListProtectedEntities(operations.ListProtectedEntitiesParams{Service: "missing"})
+	assertError(t, http.StatusNotFound, resp.(*operations.ListProtectedEntitiesNotFound).Payload)
+	resp = handler.ListProtectedEntities(operations.ListProtectedEntitiesParams{
+		Service:       "mem",
+		LabelSelector: swag.String("app in web"),
+	})
+	assertError(t, http.StatusBadRequest, resp.(*operations.ListProtectedEntitiesBadRequest).Payload)
+
+	resp = handler.ListProtectedEntities(operations.ListProtectedEntitiesParams{
+		Service:    "mem",
+		MaxResults: swag.Int32(2),
+	})
+	peList := resp.(*operations.ListProtectedEntitiesOK).Payload
+	assert.DeepEqual(t, []models.ProtectedEntityID{"mem:a", "mem:b"}, peList.List)
+	assert.Assert(t, peList.Truncated)
+
+	resp = handler.ListProtectedEntities(operations.ListProtectedEntitiesParams{
+		Service:  "mem",
+		IdsAfter: swag.String("mem:b"),
+	})
+	peList = resp.(*operations.ListProtectedEntitiesOK).Payload
+	assert.DeepEqual(t, []models.ProtectedEntityID{"mem:c"}, peList.List)
+	assert.Assert(t, !peList.Truncated)
+
+	resp = handler.ListProtectedEntities(operations.ListProtectedEntitiesParams{
+		Service:       "mem",
+		LabelSelector: swag.String("app=web"),
+	})
+	peList = resp.(*operations.ListProtectedEntitiesOK).Payload
+	assert.DeepEqual(t, []models.ProtectedEntityID{"mem:a", "mem:b"}, peList.List)
+
+	// Page through in descending order
+	resp = handler.ListProtectedEntities(operations.ListProtectedEntitiesParams{
+		Service:    "mem",
+		MaxResults: swag.Int32(2),
+		Order:      swag.String("desc"),
+	})
+	peList = resp.(*operations.ListProtectedEntitiesOK).Payload
+	assert.DeepEqual(t, []models.ProtectedEntityID{"mem:c", "mem:b"}, peList.List)
+	assert.Assert(t, peList.Truncated && peList.ContinuationToken != "")
+	resp = handler.ListProtectedEntities(operations.ListProtectedEntitiesParams{
+		Service:           "mem",
+		MaxResults:        swag.Int32(2),
+		Order:             swag.String("desc"),
+		ContinuationToken: swag.String(peList.ContinuationToken),
+	})
+	nextList := resp.(*operations.ListProtectedEntitiesOK).Payload
+	assert.DeepEqual(t, []models.ProtectedEntityID{"mem:a"}, nextList.List)
+	assert.Assert(t, !nextList.Truncated && nextList.ContinuationToken == "")
+
+	// The token is for descending order
+	resp = handler.ListProtectedEntities(operations.ListProtectedEntitiesParams{
+		Service:           "mem",
+		ContinuationToken: swag.String(peList.ContinuationToken),
+	})
+	assertError(t, http.StatusBadRequest, resp.(*operations.ListProtectedEntitiesBadRequest).Payload)
+	resp = handler.ListProtectedEntities(operations.ListProtectedEntitiesParams{
+		Service:           "mem",
+		IdsAfter:          swag.String("mem:a"),
+		ContinuationToken: swag.String(peList.ContinuationToken),
+	})
+	assertError(t, http.StatusBadRequest, resp.(*operations.ListProtectedEntitiesBadRequest).Payload)
+
+	resp = handler.ListProtectedEntities(operations.ListProtectedEntitiesParams{
+		Service: "mem",
+		OrderBy: swag.String("name"),
+		Prefix:  swag.String("b"),
+	})
+	assert.DeepEqual(t, []models.ProtectedEntityID{"mem:b"}, resp.(*operations.ListProtectedEntitiesOK).Payload.List)
+	resp = handler.ListProtectedEntities(operations.ListProtectedEntitiesParams{
+		Service: "mem",
+		Name:    swag.String("c"),
+	})
+	assert.DeepEqual(t, []models.ProtectedEntityID{"mem:c"}, resp.(*operations.ListProtectedEntitiesOK).Payload.List)
+}
+
+func TestListSnapshotsPaging(t *testing.T) {
+	handler, petm := newTestHandler()
+	peid := petm.addEntity("a", nil)
+	ctx := context.Background()
+	pe, err := petm.GetProtectedEntity(ctx, peid)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	for snapshotNum := 0; snapshotNum < 3; snapshotNum++ {
+		if _, err := pe.Snapshot(ctx, nil); err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+	}
+
+	params := operations.ListSnapshotsParams{
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+		MaxResults:        swag.Int32(2),
+	}
+	resp := handler.ListSnapshots(params)
+	peList := resp.(*operations.ListSnapshotsOK).Payload
+	assert.DeepEqual(t, []models.ProtectedEntityID{"mem:a:snap-1", "mem:a:snap-2"}, peList.List)
+	assert.Assert(t, peList.Truncated)
+	params.ContinuationToken = swag.String(peList.ContinuationToken)
+	resp = handler.ListSnapshots(params)
+	peList = resp.(*operations.ListSnapshotsOK).Payload
+	assert.DeepEqual(t, []models.ProtectedEntityID{"mem:a:snap-3"}, peList.List)
+	assert.Assert(t, !peList.Truncated)
+
+	resp = handler.ListSnapshots(operations.ListSnapshotsParams{
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+		Prefix:            swag.String("snap-2"),
+	})
+	assert.DeepEqual(t, []models.ProtectedEntityID{"mem:a:snap-2"}, resp.(*operations.ListSnapshotsOK).Payload.List)
+	resp = handler.ListSnapshots(operations.ListSnapshotsParams{
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+		ContinuationToken: swag.String("garbage"),
+	})
+	assertError(t, http.StatusBadRequest, resp.(*operations.ListSnapshotsBadRequest).Payload)
+}
+
+func TestGetProtectedEntityInfoHandler(t *testing.T) {
+	handler, petm := newTestHandler()
+	peid := petm.addEntity("a", nil)
+
+	resp := handler.GetProtectedEntityInfo(operations.GetProtectedEntityInfoParams{
+		Service:           "missing",
+		ProtectedEntityID: peid.String(),
+	})
+	assertError(t, http.StatusNotFound, resp.(*operations.GetProtectedEntityInfoNotFound).Payload)
+	resp = handler.GetProtectedEntityInfo(operations.GetProtectedEntityInfoParams{
+		Service:           "mem",
+		ProtectedEntityID: "not-an-id",
+	})
+	assertError(t, http.StatusBadRequest, resp.(*operations.GetProtectedEntityInfoBadRequest).Payload)
+	resp = handler.GetProtectedEntityInfo(operations.GetProtectedEntityInfoParams{
+		Service:           "mem",
+		ProtectedEntityID: "mem:b",
+	})
+	assertError(t, http.StatusNotFound, resp.(*operations.GetProtectedEntityInfoNotFound).Payload)
+
+	resp = handler.GetProtectedEntityInfo(operations.GetProtectedEntityInfoParams{
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+	})
+	peInfo := resp.(*operations.GetProtectedEntityInfoOK).Payload
+	assert.Equal(t, peid.GetModelProtectedEntityID(), peInfo.ID)
+}
+
+func TestHandlersUseRequestContext(t *testing.T) {
+	handler, petm := newTestHandler()
+	peid := petm.addEntity("a", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	request, err := http.NewRequest(http.MethodGet, "/", nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	request = request.WithContext(ctx)
+
+	// Calls made for a client that has gone away are not completed
+	resp := handler.ListProtectedEntities(operations.ListProtectedEntitiesParams{
+		HTTPRequest: request,
+		Service:     "mem",
+	})
+	_, ok := resp.(*operations.ListProtectedEntitiesInternalServerError)
+	assert.Assert(t, ok, "got %T from ListProtectedEntities", resp)
+	resp = handler.GetProtectedEntityInfo(operations.GetProtectedEntityInfoParams{
+		HTTPRequest:       request,
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+	})
+	_, ok = resp.(*operations.GetProtectedEntityInfoOK)
+	assert.Assert(t, !ok, "GetProtectedEntityInfo ignored the cancelled request")
+	resp = handler.ListSnapshots(operations.ListSnapshotsParams{
+		HTTPRequest:       request,
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+	})
+	_, ok = resp.(*operations.ListSnapshotsOK)
+	assert.Assert(t, !ok, "ListSnapshots ignored the cancelled request")
+}
+
+func TestSnapshotHandlers(t *testing.T) {
+	handler, petm := newTestHandler()
+	peid := petm.addEntity("a", nil)
+
+	resp := handler.CreateSnapshot(operations.CreateSnapshotParams{
+		Service:           "mem",
+		ProtectedEntityID: "mem:b",
+	})
+	assertError(t, http.StatusNotFound, resp.(*operations.CreateSnapshotNotFound).Payload)
+
+	resp = handler.CreateSnapshot(operations.CreateSnapshotParams{
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+	})
+	snapshotID := astrolabe.NewProtectedEntitySnapshotIDFromModel(resp.(*operations.CreateSnapshotOK).Payload)
+	snapshotPEID := peid.IDWithSnapshot(snapshotID)
+
+	resp = handler.CreateSnapshot(operations.CreateSnapshotParams{
+		Service:           "mem",
+		ProtectedEntityID: snapshotPEID.String(),
+	})
+	assertError(t, http.StatusBadRequest, resp.(*operations.CreateSnapshotBadRequest).Payload)
+
+	petm.failSnapshot["a"] = true
+	resp = handler.CreateSnapshot(operations.CreateSnapshotParams{
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+	})
+	assertError(t, http.StatusInternalServerError, resp.(*operations.CreateSnapshotInternalServerError).Payload)
+
+	resp = handler.ListSnapshots(operations.ListSnapshotsParams{
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+	})
+	assert.DeepEqual(t, []models.ProtectedEntityID{snapshotPEID.GetModelProtectedEntityID()},
+		resp.(*operations.ListSnapshotsOK).Payload.List)
+
+	// Only snapshots can be deleted
+	resp = handler.DeleteProtectedEntity(operations.DeleteProtectedEntityParams{
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+	})
+	assertError(t, http.StatusBadRequest, resp.(*operations.DeleteProtectedEntityBadRequest).Payload)
+
+	petm.retained[snapshotPEID.String()] = true
+	resp = handler.DeleteProtectedEntity(operations.DeleteProtectedEntityParams{
+		Service:           "mem",
+		ProtectedEntityID: snapshotPEID.String(),
+	})
+	assertError(t, http.StatusConflict, resp.(*operations.DeleteProtectedEntityConflict).Payload)
+
+	delete(petm.retained, snapshotPEID.String())
+	resp = handler.DeleteProtectedEntity(operations.DeleteProtectedEntityParams{
+		Service:           "mem",
+		ProtectedEntityID: snapshotPEID.String(),
+	})
+	assert.Equal(t, snapshotPEID.GetModelProtectedEntityID(), resp.(*operations.DeleteProtectedEntityOK).Payload)
+	resp = handler.DeleteProtectedEntity(operations.DeleteProtectedEntityParams{
+		Service:           "mem",
+		ProtectedEntityID: snapshotPEID.String(),
+	})
+	assertError(t, http.StatusNotFound, resp.(*operations.DeleteProtectedEntityNotFound).Payload)
+}
+
+func TestCopyProtectedEntityHandler(t *testing.T) {
+	handler, petm := newTestHandler()
+	peid := petm.addEntity("a", nil)
+	pe, err := petm.GetProtectedEntity(context.Background(), peid)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	info, err := pe.GetInfo(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	mpeInfo := info.GetModelProtectedEntityInfo()
+
+	resp := handler.CopyProtectedEntity(operations.CopyProtectedEntityParams{
+		Service: "mem",
+		Mode:    "clone",
+		Body: &models.CopyParameters{
+			ProtectedEntityInfo: &mpeInfo,
+		},
+	})
+	assertError(t, http.StatusBadRequest, resp.(*operations.CopyProtectedEntityBadRequest).Payload)
+	resp = handler.CopyProtectedEntity(operations.CopyProtectedEntityParams{
+		Service: "mem",
+		Mode:    "create_new",
+		Body:    &models.CopyParameters{},
+	})
+	assertError(t, http.StatusBadRequest, resp.(*operations.CopyProtectedEntityBadRequest).Payload)
+	resp = handler.CopyProtectedEntity(operations.CopyProtectedEntityParams{
+		Service: "missing",
+		Mode:    "create_new",
+		Body: &models.CopyParameters{
+			ProtectedEntityInfo: &mpeInfo,
+		},
+	})
+	assertError(t, http.StatusNotFound, resp.(*operations.CopyProtectedEntityNotFound).Payload)
+
+	resp = handler.CopyProtectedEntity(operations.CopyProtectedEntityParams{
+		Service: "mem",
+		Mode:    "create_new",
+		Body: &models.CopyParameters{
+			ProtectedEntityInfo: &mpeInfo,
+		},
+	})
+	taskInfo := waitForTask(t, handler, resp.(*operations.CopyProtectedEntityAccepted).Payload.TaskID)
+	assert.Equal(t, astrolabe.Success.String(), *taskInfo.Status, taskInfo.Details)
+	assert.Equal(t, models.ProtectedEntityID("mem:copy-1"), taskInfo.Result)
+
+	// The ID is already in use, the failure is reported by the task
+	resp = handler.CopyProtectedEntity(operations.CopyProtectedEntityParams{
+		Service: "mem",
+		Mode:    "create",
+		Body: &models.CopyParameters{
+			ProtectedEntityInfo: &mpeInfo,
+		},
+	})
+	taskInfo = waitForTask(t, handler, resp.(*operations.CopyProtectedEntityAccepted).Payload.TaskID)
+	assert.Equal(t, astrolabe.Failed.String(), *taskInfo.Status)
+	assert.Assert(t, taskInfo.Details != "", "failed task should have details")
+}
+
+func TestTaskHandlers(t *testing.T) {
+	handler, petm := newTestHandler()
+	peid := petm.addEntity("a", nil)
+	info, err := memoryProtectedEntity{petm: petm, id: peid}.GetInfo(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	mpeInfo := info.GetModelProtectedEntityInfo()
+
+	resp := handler.GetTaskInfo(operations.GetTaskInfoParams{TaskID: "missing"})
+	assertError(t, http.StatusNotFound, resp.(*operations.GetTaskInfoNotFound).Payload)
+	resp = handler.WaitOnTaskNexus(operations.WaitOnTaskNexusParams{TaskNexusID: "missing"})
+	assertError(t, http.StatusNotFound, resp.(*operations.WaitOnTaskNexusNotFound).Payload)
+
+	resp = handler.CreateTaskNexus(operations.CreateTaskNexusParams{})
+	nexusID := resp.(*operations.CreateTaskNexusOK).Payload
+
+	resp = handler.WaitOnTaskNexus(operations.WaitOnTaskNexusParams{
+		TaskNexusID: string(nexusID),
+		WaitTime:    -1,
+	})
+	assertError(t, http.StatusBadRequest, resp.(*operations.WaitOnTaskNexusBadRequest).Payload)
+	// Nothing has finished, the wait times out
+	resp = handler.WaitOnTaskNexus(operations.WaitOnTaskNexusParams{
+		TaskNexusID: string(nexusID),
+		WaitTime:    10,
+	})
+	assert.Equal(t, 0, len(resp.(*operations.WaitOnTaskNexusOK).Payload.Finished))
+
+	resp = handler.CopyProtectedEntity(operations.CopyProtectedEntityParams{
+		Service: "mem",
+		Mode:    "create_new",
+		Body: &models.CopyParameters{
+			ProtectedEntityInfo: &mpeInfo,
+		},
+	})
+	taskID := resp.(*operations.CopyProtectedEntityAccepted).Payload.TaskID
+
+	resp = handler.ListTasks(operations.ListTasksParams{})
+	assert.DeepEqual(t, models.TaskIDList{taskID}, resp.(*operations.ListTasksOK).Payload)
+
+	resp = handler.WaitOnTaskNexus(operations.WaitOnTaskNexusParams{
+		TaskNexusID: string(nexusID),
+		WaitTime:    10000,
+	})
+	finished := resp.(*operations.WaitOnTaskNexusOK).Payload.Finished
+	assert.Equal(t, 1, len(finished))
+	assert.Equal(t, taskID, finished[0].ID)
+
+	// Tasks that finished before lastFinishedNS are not returned again
+	resp = handler.WaitOnTaskNexus(operations.WaitOnTaskNexusParams{
+		TaskNexusID:    string(nexusID),
+		WaitTime:       10,
+		LastFinishedNS: finished[0].FinishedTimeNS,
+	})
+	assert.Equal(t, 0, len(resp.(*operations.WaitOnTaskNexusOK).Payload.Finished))
+
+	resp = handler.ListTaskNexus(operations.ListTaskNexusParams{})
+	nexusList := resp.(*operations.ListTaskNexusOK).Payload
+	assert.Equal(t, 1, len(nexusList))
+	assert.Equal(t, nexusID, nexusList[0].ID)
+	assert.DeepEqual(t, []models.TaskID{taskID}, nexusList[0].AssociatedTasks)
+}
+
+func TestUnsupportedServiceHandlers(t *testing.T) {
+	handler, petm := newTestHandler()
+	peid := petm.addEntity("a", nil)
+	snapshotPEID := peid.IDWithSnapshot(astrolabe.NewProtectedEntitySnapshotID("snap-1"))
+
+	resp := handler.GetUsage(operations.GetUsageParams{Service: swag.String("mem")})
+	assertError(t, http.StatusNotFound, resp.(*operations.GetUsageNotFound).Payload)
+	resp = handler.GetRetention(operations.GetRetentionParams{
+		Service:           "mem",
+		ProtectedEntityID: snapshotPEID.String(),
+	})
+	assertError(t, http.StatusNotFound, resp.(*operations.GetRetentionNotFound).Payload)
+	resp = handler.GetRetention(operations.GetRetentionParams{
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+	})
+	assertError(t, http.StatusBadRequest, resp.(*operations.GetRetentionBadRequest).Payload)
+	resp = handler.UpdateRetention(operations.UpdateRetentionParams{
+		Service:           "mem",
+		ProtectedEntityID: snapshotPEID.String(),
+		Update:            &models.RetentionUpdate{},
+	})
+	assertError(t, http.StatusNotFound, resp.(*operations.UpdateRetentionNotFound).Payload)
+	resp = handler.ListDeletedSnapshots(operations.ListDeletedSnapshotsParams{
+		Service:           "mem",
+		ProtectedEntityID: peid.String(),
+	})
+	assertError(t, http.StatusNotFound, resp.(*operations.ListDeletedSnapshotsNotFound).Payload)
+	resp = handler.UndeleteSnapshot(operations.UndeleteSnapshotParams{
+		Service:           "mem",
+		ProtectedEntityID: snapshotPEID.String(),
+	})
+	assertError(t, http.StatusNotFound, resp.(*operations.UndeleteSnapshotNotFound).Payload)
+	resp = handler.RehydrateSnapshot(operations.RehydrateSnapshotParams{
+		Service:           "mem",
+		ProtectedEntityID: snapshotPEID.String(),
+	})
+	assertError(t, http.StatusNotFound, resp.(*operations.RehydrateSnapshotNotFound).Payload)
+}
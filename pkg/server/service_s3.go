@@ -18,69 +18,685 @@ package server
 
 import (
 	"context"
-	"github.com/labstack/echo"
-	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
 )
 
+/*
+ServiceS3 serves the S3 data path for the protected entities of a ProtectedEntityManager.  Each service is a bucket
+and each protected entity has a data object keyed by its ID, a metadata object with .md appended and a combined zip
+object with .zip appended.  The buckets are served path style under prefix, which is where the S3 transports for
+the protected entities point (see astrolabe.NewS3TransportForPEID).  Zips uploaded to the copy bucket are
+reconstituted by tasks added to tm.
+*/
 type ServiceS3 struct {
-	petm astrolabe.ProtectedEntityTypeManager
+	pem          astrolabe.ProtectedEntityManager
+	tm           *TaskManager
+	prefix       string
+	logger       logrus.FieldLogger
+	auth         *AuthManager
+	audit        *AuditLog
+	drainer      *Drainer
+	uploadsMutex sync.Mutex
+	uploads      map[string]*s3Upload
 }
 
-func NewServiceS3(petm astrolabe.ProtectedEntityTypeManager) *ServiceS3 {
+const (
+	s3XMLNamespace    = "http://s3.amazonaws.com/doc/2006-03-01/"
+	s3DefaultMaxKeys  = 1000
+	s3ContentType     = "application/octet-stream"
+	s3ZipContentType  = "application/zip"
+	s3ListTypeV2      = "2"
+	s3BucketOwnerName = "astrolabe"
+)
+
+func NewServiceS3(pem astrolabe.ProtectedEntityManager, tm *TaskManager, prefix string, logger logrus.FieldLogger) *ServiceS3 {
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix = "/" + prefix
+	}
 	return &ServiceS3{
-		petm: petm,
+		pem:     pem,
+		tm:      tm,
+		prefix:  prefix,
+		logger:  logger,
+		uploads: map[string]*s3Upload{},
 	}
 }
 
-func (this *ServiceS3) listObjects(echoContext echo.Context) error {
-	/*
-	 * No, this is not a correct implementation of S3 list bucket.
-	 * TODO - write a proper implementation
-	 */
-	pes, err := this.petm.GetProtectedEntities(context.Background())
+/*
+Handler returns an http.Handler that serves the S3 requests under the prefix and passes everything else to next.  This
+allows the S3 data path to share a listener with the REST API.
+*/
+func (this *ServiceS3) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if this.prefix != "" && r.URL.Path != this.prefix && !strings.HasPrefix(r.URL.Path, this.prefix+"/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		this.ServeHTTP(w, r)
+	})
+}
+
+/*
+SetAuthManager makes the S3 data path authorize requests with auth.  Requests must have been authenticated by
+auth.Authenticate.
+*/
+func (this *ServiceS3) SetAuthManager(auth *AuthManager) {
+	this.auth = auth
+}
+
+/*
+SetAuditLog records uploads to the copy bucket in audit
+*/
+func (this *ServiceS3) SetAuditLog(audit *AuditLog) {
+	this.audit = audit
+}
+
+/*
+SetDrainer rejects uploads to the copy bucket while drainer drains
+*/
+func (this *ServiceS3) SetDrainer(drainer *Drainer) {
+	this.drainer = drainer
+}
+
+func (this *ServiceS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, this.prefix), "/")
+	bucket := path
+	key := ""
+	if slash := strings.Index(path, "/"); slash >= 0 {
+		bucket = path[:slash]
+		key = path[slash+1:]
+	}
+	if err := this.authorizeBucket(r, bucket); err != nil {
+		this.writeError(w, r, err)
+		return
+	}
+	if bucket == S3CopyBucket {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead && this.drainer.IsDraining() {
+			w.Header().Set("Retry-After", drainRetryAfterSeconds)
+			this.writeError(w, r, s3Error{http.StatusServiceUnavailable, "ServiceUnavailable",
+				"The server is shutting down."})
+			return
+		}
+		err := this.handleCopyBucket(w, r, key)
+		if err != nil {
+			this.writeError(w, r, err)
+		}
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		this.writeError(w, r, s3Error{http.StatusMethodNotAllowed, "MethodNotAllowed",
+			"The specified method is not allowed against this resource."})
+		return
+	}
+	var err error
+	switch {
+	case bucket == "":
+		err = this.listBuckets(w, r)
+	case key == "":
+		err = this.listObjects(w, r, bucket)
+	default:
+		err = this.getObject(w, r, bucket, key)
+	}
 	if err != nil {
-		return err
+		this.writeError(w, r, err)
 	}
-	var pesList []string
-	for _, curPes := range pes {
-		pesList = append(pesList, curPes.GetID())
+}
+
+/*
+s3Error is an error reported to the client in the S3 error XML format
+*/
+type s3Error struct {
+	status  int
+	code    string
+	message string
+}
+
+func (this s3Error) Error() string {
+	return this.code + ": " + this.message
+}
+
+type s3ErrorResponse struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+func noSuchBucketError(bucket string) s3Error {
+	return s3Error{http.StatusNotFound, "NoSuchBucket", fmt.Sprintf("The bucket %s does not exist", bucket)}
+}
+
+func noSuchKeyError(key string) s3Error {
+	return s3Error{http.StatusNotFound, "NoSuchKey", fmt.Sprintf("The key %s does not exist", key)}
+}
+
+func invalidArgumentError(format string, args ...interface{}) s3Error {
+	return s3Error{http.StatusBadRequest, "InvalidArgument", fmt.Sprintf(format, args...)}
+}
+
+/*
+accessDeniedError converts an authorization failure into an S3 error.  SigV4 failures keep their S3 error code, e.g.
+SignatureDoesNotMatch.
+*/
+func accessDeniedError(err error) s3Error {
+	if authErr, ok := err.(AuthError); ok && authErr.Err != nil {
+		if s3Err, ok := errors.Cause(authErr.Err).(s3Error); ok {
+			return s3Err
+		}
+	}
+	return s3Error{http.StatusForbidden, "AccessDenied", err.Error()}
+}
+
+/*
+authorizeBucket checks that listing buckets is authenticated, that the copy bucket is only used by identities that
+can copy to some service and that the other buckets are only read by identities that can read that service.  The
+services of the protected entities uploaded to the copy bucket are checked by startCopyTask.
+*/
+func (this *ServiceS3) authorizeBucket(r *http.Request, bucket string) error {
+	if this.auth == nil {
+		return nil
+	}
+	switch bucket {
+	case "":
+		_, err := this.auth.authorize(r.Context(), "", "")
+		if err != nil {
+			return accessDeniedError(err)
+		}
+	case S3CopyBucket:
+		identity, err := this.auth.authorize(r.Context(), "", "")
+		if err != nil {
+			return accessDeniedError(err)
+		}
+		if !this.auth.AllowedOnAnyService(identity, CopyOperation) {
+			return accessDeniedError(errors.Errorf("%s is not allowed %s on any service", identity.Name,
+				CopyOperation))
+		}
+	default:
+		_, err := this.auth.authorize(r.Context(), bucket, ReadOperation)
+		if err != nil {
+			return accessDeniedError(err)
+		}
 	}
-	echoContext.JSON(http.StatusOK, pesList)
 	return nil
 }
 
-func (this *ServiceS3) handleObjectRequest(echoContext echo.Context) error {
-	objectKey := echoContext.Param("objectKey")
-	var objectStream io.Reader
-	var idStr, source, contentType string
-	if strings.HasSuffix(objectKey, ".md") {
-		idStr = strings.TrimSuffix(objectKey, ".md")
-		source = "md"
-		contentType = "application/octet-stream"
-	} else {
-		idStr = objectKey
-		source = "data"
-		contentType = "application/octet-stream"
+func (this *ServiceS3) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr, ok := errors.Cause(err).(s3Error)
+	if !ok {
+		if astrolabe.IsRehydrationRequiredError(err) {
+			apiErr = s3Error{http.StatusForbidden, "InvalidObjectState", err.Error()}
+		} else {
+			this.logger.WithError(err).Errorf("S3 request %s %s failed", r.Method, r.URL.Path)
+			apiErr = s3Error{http.StatusInternalServerError, "InternalError", err.Error()}
+		}
+	}
+	if r.Method == http.MethodHead {
+		// HEAD responses have no body, the status is all the client gets
+		w.WriteHeader(apiErr.status)
+		return
+	}
+	this.writeXML(w, apiErr.status, s3ErrorResponse{
+		Code:     apiErr.code,
+		Message:  apiErr.message,
+		Resource: r.URL.Path,
+	})
+}
+
+func (this *ServiceS3) writeXML(w http.ResponseWriter, status int, response interface{}) {
+	buf, err := xml.Marshal(response)
+	if err != nil {
+		this.logger.WithError(err).Error("Could not marshal S3 response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Length", strconv.Itoa(len(xml.Header)+len(buf)))
+	w.WriteHeader(status)
+	_, err = io.WriteString(w, xml.Header)
+	if err == nil {
+		_, err = w.Write(buf)
+	}
+	if err != nil {
+		this.logger.WithError(err).Error("Could not write S3 response")
+	}
+}
+
+type s3Owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+type s3Bucket struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+type s3ListAllMyBucketsResult struct {
+	XMLName xml.Name   `xml:"ListAllMyBucketsResult"`
+	XMLNS   string     `xml:"xmlns,attr"`
+	Owner   s3Owner    `xml:"Owner"`
+	Buckets []s3Bucket `xml:"Buckets>Bucket"`
+}
+
+func (this *ServiceS3) listBuckets(w http.ResponseWriter, r *http.Request) error {
+	buckets := []s3Bucket{}
+	for _, curPETM := range this.pem.ListEntityTypeManagers() {
+		buckets = append(buckets, s3Bucket{
+			Name:         curPETM.GetTypeName(),
+			CreationDate: time.Unix(0, 0).UTC().Format(time.RFC3339),
+		})
+	}
+	buckets = append(buckets, s3Bucket{
+		Name:         S3CopyBucket,
+		CreationDate: time.Unix(0, 0).UTC().Format(time.RFC3339),
+	})
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Name < buckets[j].Name
+	})
+	this.writeXML(w, http.StatusOK, s3ListAllMyBucketsResult{
+		XMLNS: s3XMLNamespace,
+		Owner: s3Owner{
+			ID:          s3BucketOwnerName,
+			DisplayName: s3BucketOwnerName,
+		},
+		Buckets: buckets,
+	})
+	return nil
+}
+
+type s3Object struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type s3CommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type s3ListBucketV2Result struct {
+	XMLName               xml.Name         `xml:"ListBucketResult"`
+	XMLNS                 string           `xml:"xmlns,attr"`
+	Name                  string           `xml:"Name"`
+	Prefix                string           `xml:"Prefix"`
+	StartAfter            string           `xml:"StartAfter,omitempty"`
+	ContinuationToken     string           `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string           `xml:"NextContinuationToken,omitempty"`
+	KeyCount              int              `xml:"KeyCount"`
+	MaxKeys               int              `xml:"MaxKeys"`
+	Delimiter             string           `xml:"Delimiter,omitempty"`
+	IsTruncated           bool             `xml:"IsTruncated"`
+	Contents              []s3Object       `xml:"Contents"`
+	CommonPrefixes        []s3CommonPrefix `xml:"CommonPrefixes"`
+}
+
+/*
+listObjects implements ListObjectsV2.  The keys are generated from the protected entities of the service.  The data
+and metadata keys are only listed for entities whose info has data or metadata transports.  Objects are listed with
+the size HEAD reports, objects whose size is not known without reading them, such as the .zip objects, are listed
+with size 0.  The info is retrieved for the entities on the returned page only, so a truncated page may be followed
+by an empty one if the remaining entities have no streams.  A page that ends on a common prefix continues after all
+of the keys under it.
+*/
+func (this *ServiceS3) listObjects(w http.ResponseWriter, r *http.Request, bucket string) error {
+	petm := this.pem.GetProtectedEntityTypeManager(bucket)
+	if petm == nil {
+		return noSuchBucketError(bucket)
+	}
+	query := r.URL.Query()
+	if query.Get("list-type") != s3ListTypeV2 {
+		return s3Error{http.StatusNotImplemented, "NotImplemented", "Only ListObjectsV2 (list-type=2) is supported"}
+	}
+	prefix := query.Get("prefix")
+	delimiter := query.Get("delimiter")
+	maxKeys := s3DefaultMaxKeys
+	if maxKeysStr := query.Get("max-keys"); maxKeysStr != "" {
+		var err error
+		maxKeys, err = strconv.Atoi(maxKeysStr)
+		if err != nil || maxKeys < 0 {
+			return invalidArgumentError("max-keys %q is not a non-negative integer", maxKeysStr)
+		}
+		if maxKeys > s3DefaultMaxKeys {
+			maxKeys = s3DefaultMaxKeys
+		}
+	}
+	after := query.Get("start-after")
+	continuationToken := query.Get("continuation-token")
+	if continuationToken != "" {
+		tokenBytes, err := base64.URLEncoding.DecodeString(continuationToken)
+		if err != nil {
+			return invalidArgumentError("The continuation token provided is incorrect")
+		}
+		after = string(tokenBytes)
 	}
 
-	_, pe, err := getProtectedEntityForIDStr(this.petm, idStr, echoContext)
+	peids, err := petm.GetProtectedEntities(r.Context())
 	if err != nil {
+		return errors.Wrapf(err, "could not list protected entities for %s", bucket)
+	}
+	type candidateKey struct {
+		key  string
+		peid astrolabe.ProtectedEntityID
+		ext  string
+	}
+	candidates := []candidateKey{}
+	for _, curPEID := range peids {
+		for _, ext := range []string{astrolabe.DataExt, astrolabe.MDExt, astrolabe.CombinedExt} {
+			key := curPEID.String() + ext
+			if strings.HasPrefix(key, prefix) && key > after {
+				candidates = append(candidates, candidateKey{key, curPEID, ext})
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].key < candidates[j].key
+	})
 
+	result := s3ListBucketV2Result{
+		XMLNS:             s3XMLNamespace,
+		Name:              bucket,
+		Prefix:            prefix,
+		StartAfter:        query.Get("start-after"),
+		ContinuationToken: continuationToken,
+		MaxKeys:           maxKeys,
+		Delimiter:         delimiter,
+	}
+	pes := map[string]astrolabe.ProtectedEntity{}
+	infos := map[string]astrolabe.ProtectedEntityInfo{}
+	lastKey := ""
+	for _, candidate := range candidates {
+		if delimiter != "" {
+			if index := strings.Index(candidate.key[len(prefix):], delimiter); index >= 0 {
+				commonPrefix := candidate.key[:len(prefix)+index+len(delimiter)]
+				if len(result.CommonPrefixes) > 0 && result.CommonPrefixes[len(result.CommonPrefixes)-1].Prefix == commonPrefix {
+					continue
+				}
+				if result.KeyCount == maxKeys {
+					result.IsTruncated = true
+					break
+				}
+				result.CommonPrefixes = append(result.CommonPrefixes, s3CommonPrefix{Prefix: commonPrefix})
+				result.KeyCount++
+				// Keys are valid UTF-8, which never has a 0xff byte, so this sorts after every key under the prefix
+				lastKey = commonPrefix + "\xff"
+				continue
+			}
+		}
+		// The info is only retrieved for the entities on the page being returned
+		if result.KeyCount == maxKeys {
+			result.IsTruncated = true
+			break
+		}
+		pe, ok := pes[candidate.peid.String()]
+		if !ok {
+			pe, err = petm.GetProtectedEntity(r.Context(), candidate.peid)
+			if err != nil {
+				// The entity went away while we were listing
+				this.logger.WithError(err).Infof("Skipping %s in S3 listing", candidate.peid.String())
+				continue
+			}
+			info, err := pe.GetInfo(r.Context())
+			if err != nil {
+				return errors.Wrapf(err, "could not get info for %s", candidate.peid.String())
+			}
+			pes[candidate.peid.String()] = pe
+			infos[candidate.peid.String()] = info
+		}
+		info := infos[candidate.peid.String()]
+		if (candidate.ext == astrolabe.DataExt && len(info.GetDataTransports()) == 0) ||
+			(candidate.ext == astrolabe.MDExt && len(info.GetMetadataTransports()) == 0) {
+			continue
+		}
+		size, err := streamSize(r.Context(), pe, candidate.ext)
+		if err != nil {
+			this.logger.WithError(err).Infof("Listing %s in S3 listing with size 0", candidate.key)
+		}
+		if size < 0 {
+			size = 0
+		}
+		result.Contents = append(result.Contents, s3Object{
+			Key:          candidate.key,
+			LastModified: time.Unix(0, 0).UTC().Format(time.RFC3339),
+			Size:         size,
+			StorageClass: "STANDARD",
+		})
+		result.KeyCount++
+		lastKey = candidate.key
 	}
+	if result.IsTruncated {
+		result.NextContinuationToken = base64.URLEncoding.EncodeToString([]byte(lastKey))
+	}
+	this.writeXML(w, http.StatusOK, result)
+	return nil
+}
 
-	switch source {
-	case "md":
-		objectStream, err = pe.GetMetadataReader(nil)
-	case "data":
-		objectStream, err = pe.GetDataReader(nil)
+/*
+openObject parses the key and returns a reader for the stream it names and the length of the stream, or -1 if it is
+not known without reading the stream (see astrolabe.StreamSize).  The combined .zip stream is assembled on the fly
+and is not sized.
+*/
+func (this *ServiceS3) openObject(ctx context.Context, bucket string, key string) (io.ReadCloser, string, int64,
+	error) {
+	petm := this.pem.GetProtectedEntityTypeManager(bucket)
+	if petm == nil {
+		return nil, "", 0, noSuchBucketError(bucket)
+	}
+	idStr := key
+	ext := astrolabe.DataExt
+	for _, checkExt := range []string{astrolabe.MDExt, astrolabe.CombinedExt} {
+		if strings.HasSuffix(key, checkExt) {
+			idStr = strings.TrimSuffix(key, checkExt)
+			ext = checkExt
+		}
+	}
+	peid, err := astrolabe.NewProtectedEntityIDFromString(idStr)
+	if err != nil || peid.GetPeType() != bucket {
+		return nil, "", 0, noSuchKeyError(key)
+	}
+	pe, err := petm.GetProtectedEntity(ctx, peid)
+	if err != nil {
+		return nil, "", 0, noSuchKeyError(key)
 	}
+	var reader io.ReadCloser
+	contentType := s3ContentType
+	switch ext {
+	case astrolabe.MDExt:
+		reader, err = pe.GetMetadataReader(ctx)
+	case astrolabe.CombinedExt:
+		reader = zipReader(ctx, pe)
+		contentType = s3ZipContentType
+	default:
+		reader, err = pe.GetDataReader(ctx)
+	}
+	if err != nil {
+		return nil, "", 0, errors.Wrapf(err, "could not open %s", key)
+	}
+	if reader == nil {
+		// The entity does not have this stream
+		return nil, "", 0, noSuchKeyError(key)
+	}
+	size, err := astrolabe.StreamSize(reader)
 	if err != nil {
+		reader.Close()
+		return nil, "", 0, errors.Wrapf(err, "could not size %s", key)
+	}
+	return reader, contentType, size, nil
+}
 
+/*
+streamSize returns the length of the data or metadata stream of pe named by ext, or -1 if it is not known without
+reading the stream.  The .zip stream is not opened, it is never sized.
+*/
+func streamSize(ctx context.Context, pe astrolabe.ProtectedEntity, ext string) (int64, error) {
+	var reader io.ReadCloser
+	var err error
+	switch ext {
+	case astrolabe.MDExt:
+		reader, err = pe.GetMetadataReader(ctx)
+	case astrolabe.DataExt:
+		reader, err = pe.GetDataReader(ctx)
+	default:
+		return -1, nil
+	}
+	if err != nil || reader == nil {
+		return -1, err
 	}
+	defer reader.Close()
+	return astrolabe.StreamSize(reader)
+}
+
+func zipReader(ctx context.Context, pe astrolabe.ProtectedEntity) io.ReadCloser {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(astrolabe.ZipProtectedEntity(ctx, pe, writer))
+	}()
+	return reader
+}
+
+/*
+getObject implements GetObject and HeadObject.  A single byte range is supported, requests for multiple ranges get
+the whole object as permitted by RFC 7233.  Streams whose size is not known, such as the .zip stream, are not read
+through to size them, HEAD is answered without a Content-Length and ranges are ignored so the whole object is
+returned.
+*/
+func (this *ServiceS3) getObject(w http.ResponseWriter, r *http.Request, bucket string, key string) error {
+	reader, contentType, size, err := this.openObject(r.Context(), bucket, key)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	w.Header().Set("Content-Type", contentType)
+	if size < 0 {
+		w.Header().Set("Accept-Ranges", "none")
+	} else {
+		w.Header().Set("Accept-Ranges", "bytes")
+	}
+	if r.Method == http.MethodHead {
+		if size >= 0 {
+			w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		}
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+	if size >= 0 {
+		rangeHeader := r.Header.Get("Range")
+		start, length, ok, err := parseRange(rangeHeader, size)
+		if err != nil {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			return err
+		}
+		if ok {
+			return this.writeObject(w, reader, bucket, key, start, length, size)
+		}
+	}
+	return this.writeObject(w, reader, bucket, key, 0, -1, size)
+}
 
-	echoContext.Stream(http.StatusOK, contentType, objectStream)
+/*
+writeObject streams length bytes of reader starting at start.  A negative length streams the whole object, with a
+Content-Length if its size is known.
+*/
+func (this *ServiceS3) writeObject(w http.ResponseWriter, reader io.Reader, bucket string, key string, start int64,
+	length int64, size int64) error {
+	status := http.StatusOK
+	var body io.Reader = reader
+	if length >= 0 {
+		if start > 0 {
+			err := skip(reader, start)
+			if err != nil {
+				return errors.Wrapf(err, "could not skip to %d in %s", start, key)
+			}
+		}
+		body = io.LimitReader(reader, length)
+		status = http.StatusPartialContent
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, size))
+		w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+	} else if size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+	w.WriteHeader(status)
+	_, err := io.Copy(w, body)
+	if err != nil {
+		// The status has been sent already, all we can do is drop the connection
+		this.logger.WithError(err).Errorf("Failed streaming %s/%s", bucket, key)
+	}
 	return nil
 }
+
+func skip(reader io.Reader, offset int64) error {
+	if seeker, ok := reader.(io.Seeker); ok {
+		_, err := seeker.Seek(offset, io.SeekStart)
+		return err
+	}
+	_, err := io.CopyN(ioutil.Discard, reader, offset)
+	return err
+}
+
+/*
+parseRange parses a Range header for an object of size bytes.  ok is false if the whole object should be returned.
+*/
+func parseRange(rangeHeader string, size int64) (start int64, length int64, ok bool, err error) {
+	if rangeHeader == "" || !strings.HasPrefix(rangeHeader, "bytes=") {
+		return 0, 0, false, nil
+	}
+	spec := strings.TrimPrefix(rangeHeader, "bytes=")
+	if strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+	invalidRange := s3Error{http.StatusRequestedRangeNotSatisfiable, "InvalidRange",
+		"The requested range " + rangeHeader + " is not satisfiable"}
+	dash := strings.Index(spec, "-")
+	if dash < 0 {
+		return 0, 0, false, nil
+	}
+	startStr := strings.TrimSpace(spec[:dash])
+	endStr := strings.TrimSpace(spec[dash+1:])
+	end := size - 1
+	if startStr == "" {
+		// Suffix range, the last n bytes
+		suffixLength, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil {
+			return 0, 0, false, nil
+		}
+		if suffixLength == 0 {
+			return 0, 0, false, invalidRange
+		}
+		if suffixLength > size {
+			suffixLength = size
+		}
+		return size - suffixLength, suffixLength, true, nil
+	}
+	start, err = strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return 0, 0, false, nil
+	}
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, nil
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	if start >= size {
+		return 0, 0, false, invalidRange
+	}
+	return start, end - start + 1, true, nil
+}
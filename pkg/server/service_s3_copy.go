@@ -0,0 +1,589 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+The copy bucket accepts combined zips (see astrolabe.ZipProtectedEntity).  Each zip uploaded, either with a single PUT
+or a multipart upload, starts a task that reconstitutes the protected entities in the zip.  The ID of the task is
+returned in the S3TaskIDHeader of the response.
+*/
+const (
+	S3CopyBucket   = "copy"
+	S3TaskIDHeader = "X-Astrolabe-Task-Id"
+)
+
+/*
+s3Upload is an in-progress multipart upload.  Parts are spooled to files in dir until the upload is completed.
+*/
+type s3Upload struct {
+	key   string
+	dir   string
+	parts map[int]string
+}
+
+func (this *ServiceS3) handleCopyBucket(w http.ResponseWriter, r *http.Request, key string) error {
+	query := r.URL.Query()
+	_, hasUploads := query["uploads"]
+	uploadID := query.Get("uploadId")
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		// Nothing is ever stored in the copy bucket
+		this.writeXML(w, http.StatusOK, s3ListBucketV2Result{
+			XMLNS:   s3XMLNamespace,
+			Name:    S3CopyBucket,
+			Prefix:  query.Get("prefix"),
+			MaxKeys: s3DefaultMaxKeys,
+		})
+		return nil
+	case key == "":
+		return s3Error{http.StatusMethodNotAllowed, "MethodNotAllowed", "The copy bucket cannot be modified"}
+	case r.Method == http.MethodPost && hasUploads:
+		return this.createMultipartUpload(w, key)
+	case r.Method == http.MethodPut && uploadID != "":
+		return this.uploadPart(w, r, uploadID, query.Get("partNumber"))
+	case r.Method == http.MethodPost && uploadID != "":
+		return this.completeMultipartUpload(w, r, key, uploadID)
+	case r.Method == http.MethodDelete && uploadID != "":
+		return this.abortMultipartUpload(w, uploadID)
+	case r.Method == http.MethodPut:
+		return this.putObject(w, r, key)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return noSuchKeyError(key)
+	}
+	return s3Error{http.StatusMethodNotAllowed, "MethodNotAllowed",
+		"The specified method is not allowed against this resource."}
+}
+
+/*
+requestBody returns the body of an upload.  Bodies signed with streaming SigV4 are in the aws-chunked encoding, which
+is decoded.  If the request was authenticated with SigV4, the payload hash or the chunk signatures are verified as
+the body is read.
+*/
+func requestBody(r *http.Request) io.Reader {
+	result, _ := r.Context().Value(authContextKey{}).(authResult)
+	if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") ||
+		strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") {
+		chunkedReader := &awsChunkedReader{reader: bufio.NewReader(r.Body)}
+		if result.sigV4 != nil && result.sigV4.payloadHash == sigV4StreamingPayload {
+			chunkedReader.sigV4 = result.sigV4
+			chunkedReader.previousSignature = result.sigV4.signature
+			chunkedReader.chunkHash = sha256.New()
+		}
+		return chunkedReader
+	}
+	if result.sigV4 != nil && result.sigV4.payloadHash != sigV4UnsignedPayload {
+		return &payloadHashReader{
+			reader:   r.Body,
+			hash:     sha256.New(),
+			expected: result.sigV4.payloadHash,
+		}
+	}
+	return r.Body
+}
+
+/*
+awsChunkedReader decodes the aws-chunked encoding.  Each chunk is a hex length, optionally followed by
+;chunk-signature=<signature>, then the data.  A zero length chunk ends the body, any trailers after it are ignored.
+If sigV4 is set, each chunk's signature is checked when the end of the chunk is reached.
+*/
+type awsChunkedReader struct {
+	reader    *bufio.Reader
+	remaining int64
+	done      bool
+
+	sigV4             *sigV4Request
+	previousSignature string
+	chunkSignature    string
+	chunkHash         hash.Hash
+}
+
+func (this *awsChunkedReader) verifyChunk() error {
+	if this.sigV4 == nil {
+		return nil
+	}
+	expected := this.sigV4.chunkSignature(this.previousSignature, this.chunkHash.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(this.chunkSignature)) {
+		return s3Error{http.StatusForbidden, "SignatureDoesNotMatch", "The chunk signature does not match"}
+	}
+	this.previousSignature = expected
+	this.chunkHash.Reset()
+	return nil
+}
+
+func (this *awsChunkedReader) Read(p []byte) (int, error) {
+	if this.done {
+		return 0, io.EOF
+	}
+	if this.remaining == 0 {
+		line, err := this.reader.ReadString('\n')
+		if err != nil {
+			return 0, errors.Wrap(err, "could not read aws-chunked chunk header")
+		}
+		sizeStr := strings.TrimSpace(line)
+		this.chunkSignature = ""
+		if semicolon := strings.Index(sizeStr, ";"); semicolon >= 0 {
+			this.chunkSignature = strings.TrimPrefix(sizeStr[semicolon+1:], "chunk-signature=")
+			sizeStr = sizeStr[:semicolon]
+		}
+		this.remaining, err = strconv.ParseInt(sizeStr, 16, 64)
+		if err != nil {
+			return 0, errors.Errorf("invalid aws-chunked chunk size %q", sizeStr)
+		}
+		if this.remaining == 0 {
+			// The final chunk is signed as well
+			this.done = true
+			if err := this.verifyChunk(); err != nil {
+				return 0, err
+			}
+			return 0, io.EOF
+		}
+	}
+	if int64(len(p)) > this.remaining {
+		p = p[:this.remaining]
+	}
+	bytesRead, err := this.reader.Read(p)
+	this.remaining -= int64(bytesRead)
+	if this.chunkHash != nil {
+		this.chunkHash.Write(p[:bytesRead])
+	}
+	if this.remaining == 0 {
+		// Each chunk's data is followed by CRLF
+		_, crlfErr := this.reader.Discard(2)
+		if crlfErr != nil && err == nil {
+			err = crlfErr
+		}
+		if err == nil {
+			err = this.verifyChunk()
+		}
+	}
+	if err == io.EOF && !this.done {
+		err = io.ErrUnexpectedEOF
+	}
+	return bytesRead, err
+}
+
+/*
+bodyError returns the S3 error from verifying the signature of the body, or an InvalidArgument error if reading the
+body failed for another reason
+*/
+func bodyError(err error, format string, args ...interface{}) error {
+	if s3Err, ok := errors.Cause(err).(s3Error); ok {
+		return s3Err
+	}
+	return invalidArgumentError(format, args...)
+}
+
+/*
+spool copies reader to file and returns the quoted MD5 ETag of the contents
+*/
+func spool(reader io.Reader, file *os.File) (string, error) {
+	hash := md5.New()
+	_, err := io.Copy(io.MultiWriter(file, hash), reader)
+	if err != nil {
+		return "", err
+	}
+	return "\"" + hex.EncodeToString(hash.Sum(nil)) + "\"", nil
+}
+
+func (this *ServiceS3) putObject(w http.ResponseWriter, r *http.Request, key string) error {
+	if r.Header.Get("X-Amz-Copy-Source") != "" {
+		return s3Error{http.StatusNotImplemented, "NotImplemented", "CopyObject is not supported"}
+	}
+	zipFile, err := ioutil.TempFile("", "astrolabe-copy")
+	if err != nil {
+		return errors.Wrap(err, "could not create spool file")
+	}
+	etag, err := spool(requestBody(r), zipFile)
+	if err != nil {
+		removeFile(zipFile)
+		return bodyError(err, "Could not read the body for %s: %v", key, err)
+	}
+	task, err := this.startCopyTask(r.Context(), key, zipFile)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set(S3TaskIDHeader, task.GetID().String())
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func removeFile(file *os.File) {
+	file.Close()
+	os.Remove(file.Name())
+}
+
+/*
+startCopyTask parses the zip in zipFile and starts a task that copies the protected entities in it with
+AllocateNewObject.  Components are copied before the entities that contain them, to the servers named in their
+component specs, and the entities that contain them are copied with component specs naming the copies.  The result of
+the task maps the IDs in the zip to the IDs of the new protected entities.  zipFile is removed when the task finishes.  ctx is the
+request context, the authenticated identity must be allowed to copy to the services of all of the protected entities.
+The upload is recorded in the audit log if there is one.
+*/
+func (this *ServiceS3) startCopyTask(ctx context.Context, key string, zipFile *os.File) (*asyncTask, error) {
+	start := time.Now()
+	task, sourceIDs, err := this.startZipCopy(ctx, key, zipFile)
+	if this.audit != nil {
+		this.audit.recordS3Copy(ctx, key, sourceIDs, task, err, start)
+	}
+	return task, err
+}
+
+func (this *ServiceS3) startZipCopy(ctx context.Context, key string, zipFile *os.File) (*asyncTask, []string, error) {
+	size, err := zipFile.Seek(0, io.SeekEnd)
+	if err != nil {
+		removeFile(zipFile)
+		return nil, nil, errors.Wrap(err, "could not size spool file")
+	}
+	zipPE, err := astrolabe.NewZipFileProtectedEntity(zipFile, size)
+	if err != nil {
+		removeFile(zipFile)
+		return nil, nil, invalidArgumentError("%s is not a valid protected entity zip: %v", key, err)
+	}
+	sourcePEs := zipPE.GetAllProtectedEntities()
+	sourceIDs := make([]string, len(sourcePEs))
+	for curPE, sourcePE := range sourcePEs {
+		sourceIDs[curPE] = sourcePE.GetID().String()
+	}
+	// Components are copied to the servers that their parents place them on
+	componentServers := map[string]string{}
+	for _, sourcePE := range sourcePEs {
+		info, err := sourcePE.GetInfo(ctx)
+		if err != nil {
+			zipPE.Close()
+			removeFile(zipFile)
+			return nil, sourceIDs, invalidArgumentError("%s contains %s, could not read its info: %v", key,
+				sourcePE.GetID().String(), err)
+		}
+		for _, curComponentSpec := range info.GetComponentSpecs() {
+			componentServers[curComponentSpec.ID.String()] = curComponentSpec.Server
+		}
+	}
+	targetPETMs := make([]astrolabe.ProtectedEntityTypeManager, len(sourcePEs))
+	for curPE, sourcePE := range sourcePEs {
+		targetPETMs[curPE], err = this.copyTypeManager(componentServers[sourcePE.GetID().String()],
+			sourcePE.GetID().GetPeType())
+		if err != nil {
+			zipPE.Close()
+			removeFile(zipFile)
+			return nil, sourceIDs, invalidArgumentError("%s contains %s, %v", key, sourcePE.GetID().String(), err)
+		}
+		if this.auth != nil {
+			_, err = this.auth.authorize(ctx, sourcePE.GetID().GetPeType(), CopyOperation)
+			if err != nil {
+				zipPE.Close()
+				removeFile(zipFile)
+				return nil, sourceIDs, accessDeniedError(err)
+			}
+		}
+	}
+	task := startAsyncTask(ctx, fmt.Sprintf("copying %s from %s", zipPE.GetID().String(), key),
+		func(ctx context.Context) (interface{}, error) {
+			defer removeFile(zipFile)
+			defer zipPE.Close()
+			newIDs := map[string]string{}
+			newPEs := map[string]astrolabe.ProtectedEntity{}
+			for curPE, sourcePE := range sourcePEs {
+				copySource, err := remapComponents(ctx, sourcePE, newPEs)
+				if err != nil {
+					return nil, errors.Wrapf(err, "could not copy %s", sourcePE.GetID().String())
+				}
+				// Each Protected Entity in the zip is an equal share of the progress of the task
+				copyCtx := astrolabe.WithProgressReporter(ctx, func(progress float64) {
+					astrolabe.ReportProgress(ctx, (float64(curPE)*100+progress)/float64(len(sourcePEs)))
+				})
+				newPE, err := targetPETMs[curPE].Copy(copyCtx, copySource, make(map[string]map[string]interface{}),
+					astrolabe.AllocateNewObject)
+				if err != nil {
+					return nil, errors.Wrapf(err, "could not copy %s", sourcePE.GetID().String())
+				}
+				newIDs[sourcePE.GetID().String()] = newPE.GetID().String()
+				newPEs[sourcePE.GetID().String()] = newPE
+				astrolabe.ReportProgress(ctx, float64(curPE+1)*100/float64(len(sourcePEs)))
+			}
+			return newIDs, nil
+		})
+	this.tm.AddTask(task)
+	this.logger.Infof("Started task %s copying %s from %s", task.GetID().String(), zipPE.GetID().String(), key)
+	return task, sourceIDs, nil
+}
+
+/*
+remappedProtectedEntity is a Protected Entity from an uploaded zip whose components have been copied already.  Its
+info and components name the copies, so that the copy of the Protected Entity refers to them and type managers that
+copy the components themselves do not copy them again.
+*/
+type remappedProtectedEntity struct {
+	astrolabe.ProtectedEntity
+	info       astrolabe.ProtectedEntityInfo
+	components []astrolabe.ProtectedEntity
+}
+
+func (this remappedProtectedEntity) GetInfo(ctx context.Context) (astrolabe.ProtectedEntityInfo, error) {
+	return this.info, nil
+}
+
+func (this remappedProtectedEntity) GetComponents(ctx context.Context) ([]astrolabe.ProtectedEntity, error) {
+	return this.components, nil
+}
+
+/*
+remapComponents returns sourcePE with its component specs rewritten to the copies in newPEs, which are keyed by the
+IDs in the zip.  The specs keep their servers, the components were copied to them.
+*/
+func remapComponents(ctx context.Context, sourcePE astrolabe.ProtectedEntity,
+	newPEs map[string]astrolabe.ProtectedEntity) (astrolabe.ProtectedEntity, error) {
+	info, err := sourcePE.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(info.GetComponentSpecs()) == 0 {
+		return sourcePE, nil
+	}
+	componentSpecs := make([]astrolabe.ComponentSpec, len(info.GetComponentSpecs()))
+	components := make([]astrolabe.ProtectedEntity, len(info.GetComponentSpecs()))
+	for curComponent, componentSpec := range info.GetComponentSpecs() {
+		newPE, ok := newPEs[componentSpec.ID.String()]
+		if !ok {
+			return nil, errors.Errorf("component %s is not in the zip", componentSpec.ID.String())
+		}
+		componentSpecs[curComponent] = astrolabe.ComponentSpec{
+			ID:     newPE.GetID(),
+			Server: componentSpec.Server,
+		}
+		components[curComponent] = newPE
+	}
+	return remappedProtectedEntity{
+		ProtectedEntity: sourcePE,
+		info: astrolabe.NewProtectedEntityInfoWithComponentSpecs(info.GetID(), info.GetName(),
+			info.GetDataTransports(), info.GetMetadataTransports(), info.GetCombinedTransports(), componentSpecs,
+			info.GetLabels(), info.GetAnnotations()),
+		components: components,
+	}, nil
+}
+
+/*
+copyTypeManager returns the type manager that a Protected Entity is copied to, on server if the server can reach
+other servers and server is not empty
+*/
+func (this *ServiceS3) copyTypeManager(server string, peType string) (astrolabe.ProtectedEntityTypeManager, error) {
+	if resolver, ok := this.pem.(astrolabe.ComponentResolver); ok {
+		return resolver.GetServerTypeManager(server, peType)
+	}
+	petm := this.pem.GetProtectedEntityTypeManager(peType)
+	if petm == nil {
+		return nil, errors.Errorf("service %s not found", peType)
+	}
+	return petm, nil
+}
+
+type s3InitiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+func (this *ServiceS3) createMultipartUpload(w http.ResponseWriter, key string) error {
+	dir, err := ioutil.TempDir("", "astrolabe-upload")
+	if err != nil {
+		return errors.Wrap(err, "could not create upload dir")
+	}
+	uploadID := uuid.New().String()
+	this.uploadsMutex.Lock()
+	this.uploads[uploadID] = &s3Upload{
+		key:   key,
+		dir:   dir,
+		parts: map[int]string{},
+	}
+	this.uploadsMutex.Unlock()
+	this.writeXML(w, http.StatusOK, s3InitiateMultipartUploadResult{
+		XMLNS:    s3XMLNamespace,
+		Bucket:   S3CopyBucket,
+		Key:      key,
+		UploadID: uploadID,
+	})
+	return nil
+}
+
+func noSuchUploadError(uploadID string) s3Error {
+	return s3Error{http.StatusNotFound, "NoSuchUpload", fmt.Sprintf("The upload %s does not exist", uploadID)}
+}
+
+func (this *ServiceS3) getUpload(uploadID string) (*s3Upload, error) {
+	this.uploadsMutex.Lock()
+	defer this.uploadsMutex.Unlock()
+	upload, ok := this.uploads[uploadID]
+	if !ok {
+		return nil, noSuchUploadError(uploadID)
+	}
+	return upload, nil
+}
+
+func (this *ServiceS3) uploadPart(w http.ResponseWriter, r *http.Request, uploadID string, partNumberStr string) error {
+	partNumber, err := strconv.Atoi(partNumberStr)
+	if err != nil || partNumber < 1 || partNumber > 10000 {
+		return invalidArgumentError("Part number %q must be an integer between 1 and 10000", partNumberStr)
+	}
+	upload, err := this.getUpload(uploadID)
+	if err != nil {
+		return err
+	}
+	partFile, err := os.Create(filepath.Join(upload.dir, fmt.Sprintf("part-%05d", partNumber)))
+	if err != nil {
+		return errors.Wrapf(err, "could not create part %d for upload %s", partNumber, uploadID)
+	}
+	defer partFile.Close()
+	etag, err := spool(requestBody(r), partFile)
+	if err != nil {
+		return bodyError(err, "Could not read part %d: %v", partNumber, err)
+	}
+	this.uploadsMutex.Lock()
+	upload.parts[partNumber] = etag
+	this.uploadsMutex.Unlock()
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+type s3CompleteMultipartUpload struct {
+	Parts []struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	} `xml:"Part"`
+}
+
+type s3CompleteMultipartUploadResult struct {
+	XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	Bucket  string   `xml:"Bucket"`
+	Key     string   `xml:"Key"`
+	ETag    string   `xml:"ETag"`
+}
+
+/*
+completeMultipartUpload assembles the listed parts into the zip and starts the copy task
+*/
+func (this *ServiceS3) completeMultipartUpload(w http.ResponseWriter, r *http.Request, key string, uploadID string) error {
+	request := s3CompleteMultipartUpload{}
+	// Read the whole body so that its signature is verified
+	body, err := ioutil.ReadAll(requestBody(r))
+	if err != nil {
+		return bodyError(err, "Could not read the parts of %s: %v", key, err)
+	}
+	err = xml.Unmarshal(body, &request)
+	if err != nil || len(request.Parts) == 0 {
+		return s3Error{http.StatusBadRequest, "MalformedXML", "The CompleteMultipartUpload XML is not well-formed"}
+	}
+	this.uploadsMutex.Lock()
+	upload, ok := this.uploads[uploadID]
+	ok = ok && upload.key == key
+	if ok {
+		// Remove the upload so that it cannot be completed twice
+		delete(this.uploads, uploadID)
+	}
+	this.uploadsMutex.Unlock()
+	if !ok {
+		return noSuchUploadError(uploadID)
+	}
+	defer os.RemoveAll(upload.dir)
+
+	zipFile, err := ioutil.TempFile("", "astrolabe-copy")
+	if err != nil {
+		return errors.Wrap(err, "could not create spool file")
+	}
+	etagHash := md5.New()
+	lastPartNumber := 0
+	for _, part := range request.Parts {
+		etag, ok := upload.parts[part.PartNumber]
+		if !ok || part.PartNumber <= lastPartNumber || (part.ETag != "" && part.ETag != etag) {
+			removeFile(zipFile)
+			return s3Error{http.StatusBadRequest, "InvalidPart",
+				fmt.Sprintf("Part %d was not uploaded or is out of order", part.PartNumber)}
+		}
+		lastPartNumber = part.PartNumber
+		etagBytes, _ := hex.DecodeString(strings.Trim(etag, "\""))
+		etagHash.Write(etagBytes)
+		err = appendFile(zipFile, filepath.Join(upload.dir, fmt.Sprintf("part-%05d", part.PartNumber)))
+		if err != nil {
+			removeFile(zipFile)
+			return errors.Wrapf(err, "could not assemble part %d of upload %s", part.PartNumber, uploadID)
+		}
+	}
+	task, err := this.startCopyTask(r.Context(), key, zipFile)
+	if err != nil {
+		return err
+	}
+	etag := fmt.Sprintf("\"%s-%d\"", hex.EncodeToString(etagHash.Sum(nil)), len(request.Parts))
+	w.Header().Set(S3TaskIDHeader, task.GetID().String())
+	this.writeXML(w, http.StatusOK, s3CompleteMultipartUploadResult{
+		XMLNS:  s3XMLNamespace,
+		Bucket: S3CopyBucket,
+		Key:    key,
+		ETag:   etag,
+	})
+	return nil
+}
+
+func appendFile(dest *os.File, sourcePath string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	_, err = io.Copy(dest, source)
+	return err
+}
+
+func (this *ServiceS3) abortMultipartUpload(w http.ResponseWriter, uploadID string) error {
+	this.uploadsMutex.Lock()
+	upload, ok := this.uploads[uploadID]
+	delete(this.uploads, uploadID)
+	this.uploadsMutex.Unlock()
+	if !ok {
+		return noSuchUploadError(uploadID)
+	}
+	os.RemoveAll(upload.dir)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,598 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+Starts the S3 data path for a memory type manager whose transports point at it and returns an S3 client for it
+*/
+func newTestS3Service(t *testing.T) (*httptest.Server, *memoryProtectedEntityTypeManager, *s3.S3) {
+	petm := newMemoryProtectedEntityTypeManager("mem")
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	s3Service := NewServiceS3(pem, NewTaskManager(), "s3", logrus.New())
+	s3Server := httptest.NewServer(s3Service.Handler(http.NotFoundHandler()))
+
+	serverURL, err := url.Parse(s3Server.URL)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	port, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	petm.s3Config = astrolabe.S3Config{
+		Host:      net.ParseIP(serverURL.Hostname()),
+		Port:      port,
+		Prefix:    "s3",
+		UseHttp:   true,
+		Region:    "us-east-1",
+		AccessKey: "access",
+		Secret:    "secret",
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(s3Server.URL + "/s3"),
+		Region:           aws.String("us-east-1"),
+		Credentials:      credentials.NewStaticCredentials("access", "secret", ""),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return s3Server, petm, s3.New(sess)
+}
+
+func testData(size int) []byte {
+	data := make([]byte, size)
+	for curByte := range data {
+		data[curByte] = byte(curByte % 251)
+	}
+	return data
+}
+
+func assertS3ErrorCode(t *testing.T, code string, err error) {
+	assert.Assert(t, err != nil, "expected %s", code)
+	awsErr, ok := err.(awserr.Error)
+	assert.Assert(t, ok, "not an AWS error: %v", err)
+	assert.Equal(t, code, awsErr.Code())
+}
+
+func TestS3ListObjects(t *testing.T) {
+	s3Server, petm, s3Client := newTestS3Service(t)
+	defer s3Server.Close()
+	petm.addEntity("a", nil)
+	petm.setData("a", testData(100), []byte("a metadata"))
+	petm.addEntity("b", nil)
+	petm.setData("b", nil, []byte("b metadata"))
+	petm.addEntity("c", nil)
+
+	bucketsOutput, err := s3Client.ListBuckets(&s3.ListBucketsInput{})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 2, len(bucketsOutput.Buckets))
+	assert.Equal(t, S3CopyBucket, *bucketsOutput.Buckets[0].Name)
+	assert.Equal(t, "mem", *bucketsOutput.Buckets[1].Name)
+
+	expectedKeys := []string{"mem:a", "mem:a.md", "mem:a.zip", "mem:b.md", "mem:b.zip", "mem:c.zip"}
+	expectedSizes := []int64{100, int64(len("a metadata")), 0, int64(len("b metadata")), 0, 0}
+	keys := []string{}
+	sizes := []int64{}
+	pages := 0
+	err = s3Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket:  aws.String("mem"),
+		MaxKeys: aws.Int64(4),
+	}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+		pages++
+		assert.Equal(t, int64(len(output.Contents)), *output.KeyCount)
+		for _, curObject := range output.Contents {
+			keys = append(keys, *curObject.Key)
+			sizes = append(sizes, *curObject.Size)
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 2, pages)
+	assert.DeepEqual(t, expectedKeys, keys)
+	assert.DeepEqual(t, expectedSizes, sizes)
+
+	listOutput, err := s3Client.ListObjectsV2(&s3.ListObjectsV2Input{
+		Bucket:     aws.String("mem"),
+		Prefix:     aws.String("mem:a"),
+		StartAfter: aws.String("mem:a"),
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 2, len(listOutput.Contents))
+	assert.Equal(t, "mem:a.md", *listOutput.Contents[0].Key)
+	assert.Assert(t, !*listOutput.IsTruncated)
+
+	listOutput, err = s3Client.ListObjectsV2(&s3.ListObjectsV2Input{
+		Bucket:    aws.String("mem"),
+		Delimiter: aws.String("."),
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1, len(listOutput.Contents))
+	assert.Equal(t, "mem:a", *listOutput.Contents[0].Key)
+	commonPrefixes := []string{}
+	for _, curPrefix := range listOutput.CommonPrefixes {
+		commonPrefixes = append(commonPrefixes, *curPrefix.Prefix)
+	}
+	assert.DeepEqual(t, []string{"mem:a.", "mem:b.", "mem:c."}, commonPrefixes)
+
+	// Paging one key at a time must continue past each common prefix rather than returning it again
+	keys = []string{}
+	commonPrefixes = []string{}
+	pages = 0
+	err = s3Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket:    aws.String("mem"),
+		Delimiter: aws.String("."),
+		MaxKeys:   aws.Int64(1),
+	}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+		pages++
+		assert.Assert(t, pages <= 5, "listing did not terminate")
+		for _, curObject := range output.Contents {
+			keys = append(keys, *curObject.Key)
+		}
+		for _, curPrefix := range output.CommonPrefixes {
+			commonPrefixes = append(commonPrefixes, *curPrefix.Prefix)
+		}
+		return pages <= 5
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, []string{"mem:a"}, keys)
+	assert.DeepEqual(t, []string{"mem:a.", "mem:b.", "mem:c."}, commonPrefixes)
+
+	_, err = s3Client.ListObjectsV2(&s3.ListObjectsV2Input{
+		Bucket: aws.String("missing"),
+	})
+	assertS3ErrorCode(t, "NoSuchBucket", err)
+}
+
+func TestS3GetObject(t *testing.T) {
+	s3Server, petm, s3Client := newTestS3Service(t)
+	defer s3Server.Close()
+	data := testData(5000)
+	metadata := testData(300)
+	petm.addEntity("a", nil)
+	petm.setData("a", data, metadata)
+	petm.addEntity("b", nil)
+
+	headOutput, err := s3Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String("mem"),
+		Key:    aws.String("mem:a"),
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, int64(len(data)), *headOutput.ContentLength)
+	// The metadata reader is not seekable but knows its size
+	headOutput, err = s3Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String("mem"),
+		Key:    aws.String("mem:a.md"),
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, int64(len(metadata)), *headOutput.ContentLength)
+
+	for _, testCase := range []struct {
+		key      string
+		rangeStr string
+		expected []byte
+	}{
+		{"mem:a", "bytes=100-199", data[100:200]},
+		{"mem:a", "bytes=4990-", data[4990:]},
+		{"mem:a", "bytes=-10", data[4990:]},
+		{"mem:a", "bytes=4900-6000", data[4900:]},
+		{"mem:a.md", "bytes=10-19", metadata[10:20]},
+		{"mem:a.md", "", metadata},
+	} {
+		getInput := &s3.GetObjectInput{
+			Bucket: aws.String("mem"),
+			Key:    aws.String(testCase.key),
+		}
+		if testCase.rangeStr != "" {
+			getInput.Range = aws.String(testCase.rangeStr)
+		}
+		getOutput, err := s3Client.GetObject(getInput)
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		readData, err := ioutil.ReadAll(getOutput.Body)
+		getOutput.Body.Close()
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		assert.DeepEqual(t, testCase.expected, readData)
+	}
+
+	_, err = s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String("mem"),
+		Key:    aws.String("mem:a"),
+		Range:  aws.String("bytes=5000-"),
+	})
+	assertS3ErrorCode(t, "InvalidRange", err)
+	_, err = s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String("mem"),
+		Key:    aws.String("mem:b"),
+	})
+	assertS3ErrorCode(t, "NoSuchKey", err)
+	_, err = s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String("mem"),
+		Key:    aws.String("mem:z.md"),
+	})
+	assertS3ErrorCode(t, "NoSuchKey", err)
+
+	// The downloader fetches the object with ranged GETs
+	downloader := s3manager.NewDownloaderWithClient(s3Client, func(d *s3manager.Downloader) {
+		d.PartSize = 1024
+	})
+	buf := aws.NewWriteAtBuffer([]byte{})
+	downloaded, err := downloader.Download(buf, &s3.GetObjectInput{
+		Bucket: aws.String("mem"),
+		Key:    aws.String("mem:a"),
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, int64(len(data)), downloaded)
+	assert.DeepEqual(t, data, buf.Bytes())
+}
+
+func TestS3Transports(t *testing.T) {
+	s3Server, petm, _ := newTestS3Service(t)
+	defer s3Server.Close()
+	data := testData(1000)
+	peid := petm.addEntity("a", nil)
+	petm.setData("a", data, []byte("metadata"))
+
+	pe, err := petm.GetProtectedEntity(context.Background(), peid)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	info, err := pe.GetInfo(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	transportURL, ok := info.GetDataTransports()[0].GetParam(astrolabe.S3URLParam)
+	assert.Assert(t, ok)
+	resp, err := http.Get(transportURL)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	readData, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.DeepEqual(t, data, readData)
+
+	resp, err = http.Get(s3Server.URL + "/s3/mem/mem:a.zip")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	zipData, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+	zipReader, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	entries := map[string][]byte{}
+	for _, curFile := range zipReader.File {
+		fileReader, err := curFile.Open()
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		entries[curFile.Name], err = ioutil.ReadAll(fileReader)
+		fileReader.Close()
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+	}
+	names := []string{}
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.DeepEqual(t, []string{"mem:a.data", "mem:a.md", "mem:a.peinfo"}, names)
+	assert.DeepEqual(t, data, entries["mem:a.data"])
+
+	// The zip is not sized, so HEAD has no length and ranges get the whole zip rather than reading it through twice
+	resp, err = http.Head(s3Server.URL + "/s3/mem/mem:a.zip")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(-1), resp.ContentLength)
+	assert.Equal(t, "none", resp.Header.Get("Accept-Ranges"))
+	rangeReq, err := http.NewRequest(http.MethodGet, s3Server.URL+"/s3/mem/mem:a.zip", nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	rangeReq.Header.Set("Range", "bytes=0-99")
+	resp, err = http.DefaultClient.Do(rangeReq)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	rangeData, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, len(zipData), len(rangeData))
+
+	// Paths outside of the prefix are not part of the S3 data path
+	resp, err = http.Get(s3Server.URL + "/astrolabe")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+/*
+Waits for the copy task in the response headers and returns the IDs of the new protected entities
+*/
+func waitForCopyTask(t *testing.T, tm *TaskManager, header http.Header) map[string]string {
+	taskID := header.Get(S3TaskIDHeader)
+	assert.Assert(t, taskID != "", "missing task ID header")
+	task, ok := tm.RetrieveTask(astrolabe.NewTaskIDFromString(taskID))
+	assert.Assert(t, ok, "task %s not found", taskID)
+	deadline := time.Now().Add(10 * time.Second)
+	for !*task.GetModelTaskInfo().Completed && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, astrolabe.Success.String(), *task.GetModelTaskInfo().Status, task.GetDetails())
+	return task.GetResult().(map[string]string)
+}
+
+func TestS3CopyBucket(t *testing.T) {
+	petm := newMemoryProtectedEntityTypeManager("mem")
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	tm := NewTaskManager()
+	s3Server := httptest.NewServer(NewServiceS3(pem, tm, "s3", logrus.New()).Handler(http.NotFoundHandler()))
+	defer s3Server.Close()
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(s3Server.URL + "/s3"),
+		Region:           aws.String("us-east-1"),
+		Credentials:      credentials.NewStaticCredentials("access", "secret", ""),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	s3Client := s3.New(sess)
+
+	data := make([]byte, 64*1024)
+	rand.New(rand.NewSource(1)).Read(data)
+	peid := petm.addEntity("a", map[string]string{"app": "db"})
+	petm.setData("a", data, []byte("metadata"))
+	pe, err := petm.GetProtectedEntity(context.Background(), peid)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	zipBuf := bytes.Buffer{}
+	err = astrolabe.ZipProtectedEntity(context.Background(), pe, &zipBuf)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	zipBytes := zipBuf.Bytes()
+
+	// Single PUT
+	putReq, _ := s3Client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket: aws.String(S3CopyBucket),
+		Key:    aws.String("a.zip"),
+		Body:   bytes.NewReader(zipBytes),
+	})
+	err = putReq.Send()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	newIDs := waitForCopyTask(t, tm, putReq.HTTPResponse.Header)
+	assert.DeepEqual(t, map[string]string{"mem:a": "mem:copy-1"}, newIDs)
+	copyPE, err := petm.GetProtectedEntity(context.Background(), astrolabe.NewProtectedEntityID("mem", "copy-1"))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	copyData, err := readStream(copyPE.GetDataReader(context.Background()))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, data, copyData)
+	copyInfo, err := copyPE.GetInfo(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, map[string]string{"app": "db"}, copyInfo.GetLabels())
+
+	// Multipart upload
+	createOutput, err := s3Client.CreateMultipartUpload(&s3.CreateMultipartUploadInput{
+		Bucket: aws.String(S3CopyBucket),
+		Key:    aws.String("b.zip"),
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	completedParts := []*s3.CompletedPart{}
+	partSize := len(zipBytes)/3 + 1
+	for partNum := 0; partNum*partSize < len(zipBytes); partNum++ {
+		end := (partNum + 1) * partSize
+		if end > len(zipBytes) {
+			end = len(zipBytes)
+		}
+		partOutput, err := s3Client.UploadPart(&s3.UploadPartInput{
+			Bucket:     aws.String(S3CopyBucket),
+			Key:        aws.String("b.zip"),
+			UploadId:   createOutput.UploadId,
+			PartNumber: aws.Int64(int64(partNum + 1)),
+			Body:       bytes.NewReader(zipBytes[partNum*partSize : end]),
+		})
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		completedParts = append(completedParts, &s3.CompletedPart{
+			ETag:       partOutput.ETag,
+			PartNumber: aws.Int64(int64(partNum + 1)),
+		})
+	}
+	completeReq, _ := s3Client.CompleteMultipartUploadRequest(&s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(S3CopyBucket),
+		Key:             aws.String("b.zip"),
+		UploadId:        createOutput.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: completedParts},
+	})
+	err = completeReq.Send()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	newIDs = waitForCopyTask(t, tm, completeReq.HTTPResponse.Header)
+	assert.DeepEqual(t, map[string]string{"mem:a": "mem:copy-2"}, newIDs)
+
+	// The upload cannot be completed twice
+	_, err = s3Client.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(S3CopyBucket),
+		Key:             aws.String("b.zip"),
+		UploadId:        createOutput.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: completedParts},
+	})
+	assertS3ErrorCode(t, "NoSuchUpload", err)
+
+	// Streaming SigV4 uploads are sent in the aws-chunked encoding
+	chunkedBody := bytes.Buffer{}
+	for offset := 0; offset < len(zipBytes); offset += 8192 {
+		end := offset + 8192
+		if end > len(zipBytes) {
+			end = len(zipBytes)
+		}
+		fmt.Fprintf(&chunkedBody, "%x;chunk-signature=%064d\r\n", end-offset, 0)
+		chunkedBody.Write(zipBytes[offset:end])
+		chunkedBody.WriteString("\r\n")
+	}
+	fmt.Fprintf(&chunkedBody, "0;chunk-signature=%064d\r\n\r\n", 0)
+	chunkedReq, err := http.NewRequest(http.MethodPut, s3Server.URL+"/s3/copy/c.zip", &chunkedBody)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	chunkedReq.Header.Set("X-Amz-Content-Sha256", "STREAMING-AWS4-HMAC-SHA256-PAYLOAD")
+	chunkedReq.Header.Set("Content-Encoding", "aws-chunked")
+	resp, err := http.DefaultClient.Do(chunkedReq)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	newIDs = waitForCopyTask(t, tm, resp.Header)
+	assert.DeepEqual(t, map[string]string{"mem:a": "mem:copy-3"}, newIDs)
+
+	// The copy of a parent names the copies of its components
+	petm.pem = pem
+	childID := petm.addEntity("child", nil)
+	petm.setData("child", []byte("child data"), []byte("child metadata"))
+	petm.addEntity("parent", nil)
+	petm.setData("parent", []byte("parent data"), []byte("parent metadata"))
+	petm.setComponents("parent", []astrolabe.ComponentSpec{{ID: childID}})
+	parentPE, err := petm.GetProtectedEntity(context.Background(), astrolabe.NewProtectedEntityID("mem", "parent"))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	parentZip := bytes.Buffer{}
+	err = astrolabe.ZipProtectedEntity(context.Background(), parentPE, &parentZip)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	putReq, _ = s3Client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket: aws.String(S3CopyBucket),
+		Key:    aws.String("parent.zip"),
+		Body:   bytes.NewReader(parentZip.Bytes()),
+	})
+	err = putReq.Send()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	newIDs = waitForCopyTask(t, tm, putReq.HTTPResponse.Header)
+	assert.Equal(t, 2, len(newIDs))
+	assert.Assert(t, newIDs["mem:child"] != "mem:child")
+	parentCopyID, err := astrolabe.NewProtectedEntityIDFromString(newIDs["mem:parent"])
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	parentCopy, err := petm.GetProtectedEntity(context.Background(), parentCopyID)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	parentCopyInfo, err := parentCopy.GetInfo(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 1, len(parentCopyInfo.GetComponentSpecs()))
+	assert.Equal(t, newIDs["mem:child"], parentCopyInfo.GetComponentSpecs()[0].ID.String())
+
+	_, err = s3Client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(S3CopyBucket),
+		Key:    aws.String("bad.zip"),
+		Body:   bytes.NewReader([]byte("not a zip")),
+	})
+	assertS3ErrorCode(t, "InvalidArgument", err)
+	_, err = s3Client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String("mem"),
+		Key:    aws.String("mem:z"),
+		Body:   bytes.NewReader(zipBytes),
+	})
+	assertS3ErrorCode(t, "MethodNotAllowed", err)
+}
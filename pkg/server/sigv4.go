@@ -0,0 +1,304 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+AWS Signature Version 4 verification for the S3 data path.  Both the Authorization header and presigned query
+parameters are verified.  The canonical request is built the way S3 clients build it, i.e. the path is not encoded a
+second time.
+*/
+const (
+	sigV4Algorithm        = "AWS4-HMAC-SHA256"
+	sigV4PayloadAlgorithm = "AWS4-HMAC-SHA256-PAYLOAD"
+	sigV4TimeFormat       = "20060102T150405Z"
+	sigV4Terminator       = "aws4_request"
+	sigV4Service          = "s3"
+	sigV4UnsignedPayload  = "UNSIGNED-PAYLOAD"
+	sigV4StreamingPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	// sigV4MaxSkew is how far the request time may be from the server's time
+	sigV4MaxSkew = 15 * time.Minute
+)
+
+var sigV4EmptyHash = hex.EncodeToString(sha256Sum(nil))
+
+/*
+sigV4Request is kept for a request authenticated with SigV4 so that its payload can be verified as it is read
+*/
+type sigV4Request struct {
+	signingKey  []byte
+	amzDate     string
+	scope       string
+	signature   string
+	payloadHash string
+}
+
+func sigV4Denied(code string, format string, args ...interface{}) s3Error {
+	return s3Error{http.StatusForbidden, code, fmt.Sprintf(format, args...)}
+}
+
+func sigV4Malformed(format string, args ...interface{}) s3Error {
+	return s3Error{http.StatusBadRequest, "AuthorizationHeaderMalformed", fmt.Sprintf(format, args...)}
+}
+
+func sha256Sum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+/*
+authenticateSigV4 verifies the SigV4 signature of r, either from the Authorization header or from the presigned query
+parameters, and returns the identity of the access key
+*/
+func (this *AuthManager) authenticateSigV4(r *http.Request) (*Identity, *sigV4Request, error) {
+	query := r.URL.Query()
+	presigned := r.Header.Get("Authorization") == ""
+	var credential, signedHeadersStr, signature, amzDate, payloadHash string
+	if presigned {
+		if query.Get("X-Amz-Algorithm") != sigV4Algorithm {
+			return nil, nil, sigV4Malformed("Unsupported algorithm %q", query.Get("X-Amz-Algorithm"))
+		}
+		credential = query.Get("X-Amz-Credential")
+		signedHeadersStr = query.Get("X-Amz-SignedHeaders")
+		signature = query.Get("X-Amz-Signature")
+		amzDate = query.Get("X-Amz-Date")
+		payloadHash = sigV4UnsignedPayload
+	} else {
+		authorization := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authorization, sigV4Algorithm+" ") {
+			return nil, nil, sigV4Malformed("Only %s signatures are supported", sigV4Algorithm)
+		}
+		for _, field := range strings.Split(strings.TrimPrefix(authorization, sigV4Algorithm+" "), ",") {
+			field = strings.TrimSpace(field)
+			switch {
+			case strings.HasPrefix(field, "Credential="):
+				credential = strings.TrimPrefix(field, "Credential=")
+			case strings.HasPrefix(field, "SignedHeaders="):
+				signedHeadersStr = strings.TrimPrefix(field, "SignedHeaders=")
+			case strings.HasPrefix(field, "Signature="):
+				signature = strings.TrimPrefix(field, "Signature=")
+			}
+		}
+		amzDate = r.Header.Get("X-Amz-Date")
+		payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+		if payloadHash == "" {
+			return nil, nil, s3Error{http.StatusBadRequest, "InvalidRequest",
+				"Missing required header for this request: x-amz-content-sha256"}
+		}
+	}
+	if credential == "" || signedHeadersStr == "" || signature == "" || amzDate == "" {
+		return nil, nil, sigV4Malformed("The signature is missing the credential, signed headers, signature or date")
+	}
+
+	// The credential is <access key>/<date>/<region>/<service>/aws4_request
+	credentialParts := strings.Split(credential, "/")
+	if len(credentialParts) != 5 || credentialParts[3] != sigV4Service || credentialParts[4] != sigV4Terminator {
+		return nil, nil, sigV4Malformed("Invalid credential %q", credential)
+	}
+	accessKey, ok := this.accessKeys[credentialParts[0]]
+	if !ok {
+		return nil, nil, sigV4Denied("InvalidAccessKeyId", "The access key %s does not exist", credentialParts[0])
+	}
+	requestTime, err := time.Parse(sigV4TimeFormat, amzDate)
+	if err != nil || credentialParts[1] != amzDate[:8] {
+		return nil, nil, sigV4Malformed("Invalid date %q for credential %q", amzDate, credential)
+	}
+	now := this.now()
+	if presigned {
+		expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+		if err != nil || expires <= 0 {
+			return nil, nil, sigV4Malformed("Invalid X-Amz-Expires %q", query.Get("X-Amz-Expires"))
+		}
+		if now.After(requestTime.Add(time.Duration(expires) * time.Second)) {
+			return nil, nil, sigV4Denied("AccessDenied", "Request has expired")
+		}
+		if requestTime.After(now.Add(sigV4MaxSkew)) {
+			return nil, nil, sigV4Denied("AccessDenied", "Request is not valid yet")
+		}
+	} else if requestTime.Before(now.Add(-sigV4MaxSkew)) || requestTime.After(now.Add(sigV4MaxSkew)) {
+		return nil, nil, sigV4Denied("RequestTimeTooSkewed",
+			"The difference between the request time and the server's time is too large")
+	}
+
+	signedHeaders := strings.Split(signedHeadersStr, ";")
+	if !sort.StringsAreSorted(signedHeaders) || !containsString(signedHeaders, "host") {
+		return nil, nil, sigV4Malformed("Invalid signed headers %q", signedHeadersStr)
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		sigV4CanonicalURI(r),
+		sigV4CanonicalQuery(query),
+		sigV4CanonicalHeaders(r, signedHeaders),
+		signedHeadersStr,
+		payloadHash,
+	}, "\n")
+	scope := strings.Join(credentialParts[1:], "/")
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(sha256Sum([]byte(canonicalRequest))),
+	}, "\n")
+	signingKey := []byte("AWS4" + accessKey.SecretKey)
+	for _, scopePart := range credentialParts[1:] {
+		signingKey = hmacSHA256(signingKey, scopePart)
+	}
+	expected := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return nil, nil, sigV4Denied("SignatureDoesNotMatch",
+			"The request signature we calculated does not match the signature you provided")
+	}
+	return &Identity{
+		Name:   accessKey.Identity,
+		Groups: accessKey.Groups,
+		Method: "sigv4",
+	}, &sigV4Request{
+		signingKey:  signingKey,
+		amzDate:     amzDate,
+		scope:       scope,
+		signature:   expected,
+		payloadHash: payloadHash,
+	}, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, curValue := range values {
+		if curValue == value {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+sigV4CanonicalURI returns the path as the client sent it.  S3 clients sign the escaped path without escaping it again.
+*/
+func sigV4CanonicalURI(r *http.Request) string {
+	uri := r.URL.EscapedPath()
+	if r.RequestURI != "" && strings.HasPrefix(r.RequestURI, "/") {
+		uri = r.RequestURI
+		if question := strings.Index(uri, "?"); question >= 0 {
+			uri = uri[:question]
+		}
+	}
+	if uri == "" {
+		uri = "/"
+	}
+	return uri
+}
+
+/*
+sigV4CanonicalQuery sorts the query parameters by name and value and encodes them.  The signature of a presigned
+request is not part of what was signed.
+*/
+func sigV4CanonicalQuery(query url.Values) string {
+	params := []string{}
+	for name, values := range query {
+		if name == "X-Amz-Signature" {
+			continue
+		}
+		for _, value := range values {
+			params = append(params, sigV4Escape(name)+"="+sigV4Escape(value))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+func sigV4Escape(value string) string {
+	// QueryEscape leaves the same characters unescaped as SigV4 except for space
+	return strings.Replace(url.QueryEscape(value), "+", "%20", -1)
+}
+
+func sigV4CanonicalHeaders(r *http.Request, signedHeaders []string) string {
+	canonicalHeaders := strings.Builder{}
+	for _, name := range signedHeaders {
+		var values []string
+		switch name {
+		case "host":
+			values = []string{r.Host}
+		case "content-length":
+			values = r.Header.Values(name)
+			if len(values) == 0 {
+				values = []string{strconv.FormatInt(r.ContentLength, 10)}
+			}
+		default:
+			values = r.Header.Values(name)
+		}
+		trimmed := make([]string, len(values))
+		for curValue, value := range values {
+			trimmed[curValue] = strings.Join(strings.Fields(value), " ")
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.Join(trimmed, ",") + "\n")
+	}
+	return canonicalHeaders.String()
+}
+
+/*
+chunkSignature returns the signature of a chunk of a streaming upload, which chains from the signature of the
+previous chunk.  The first chunk chains from the request signature.
+*/
+func (this *sigV4Request) chunkSignature(previousSignature string, chunkHash []byte) string {
+	stringToSign := strings.Join([]string{
+		sigV4PayloadAlgorithm,
+		this.amzDate,
+		this.scope,
+		previousSignature,
+		sigV4EmptyHash,
+		hex.EncodeToString(chunkHash),
+	}, "\n")
+	return hex.EncodeToString(hmacSHA256(this.signingKey, stringToSign))
+}
+
+/*
+payloadHashReader checks that the SHA256 of the body matches the signed payload hash when the body has been read
+*/
+type payloadHashReader struct {
+	reader   io.Reader
+	hash     hash.Hash
+	expected string
+}
+
+func (this *payloadHashReader) Read(p []byte) (int, error) {
+	bytesRead, err := this.reader.Read(p)
+	this.hash.Write(p[:bytesRead])
+	if err == io.EOF && hex.EncodeToString(this.hash.Sum(nil)) != this.expected {
+		return bytesRead, s3Error{http.StatusBadRequest, "XAmzContentSHA256Mismatch",
+			"The provided x-amz-content-sha256 header does not match what was computed"}
+	}
+	return bytesRead, err
+}
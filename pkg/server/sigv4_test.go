@@ -0,0 +1,339 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+)
+
+/*
+Starts the S3 data path with SigV4 authentication.  admin-key is allowed everything and viewer-key can read mem.
+The S3 transports of petm are presigned with admin-key.
+*/
+func newTestSigV4Service(t *testing.T) (*httptest.Server, *AuthManager, *memoryProtectedEntityTypeManager,
+	*TaskManager) {
+	petm := newMemoryProtectedEntityTypeManager("mem")
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{petm}, astrolabe.S3Config{},
+		logrus.New())
+	auth := newTestAuthManager(t, AuthConfig{
+		AccessKeys: []AccessKey{
+			{AccessKey: "admin-key", SecretKey: "admin-secret", Identity: "admin"},
+			{AccessKey: "viewer-key", SecretKey: "viewer-secret", Identity: "viewer", Groups: []string{"viewers"}},
+		},
+	}, "")
+	tm := NewTaskManager()
+	s3Service := NewServiceS3(pem, tm, "s3", logrus.New())
+	s3Service.SetAuthManager(auth)
+	s3Server := httptest.NewServer(auth.Authenticate(s3Service.Handler(http.NotFoundHandler())))
+
+	serverURL, err := url.Parse(s3Server.URL)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	port, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	petm.s3Config = astrolabe.S3Config{
+		Host:      net.ParseIP(serverURL.Hostname()),
+		Port:      port,
+		Prefix:    "s3",
+		UseHttp:   true,
+		Region:    "us-east-1",
+		AccessKey: "admin-key",
+		Secret:    "admin-secret",
+	}
+	return s3Server, auth, petm, tm
+}
+
+func newTestS3Client(t *testing.T, s3Server *httptest.Server, accessKey string, secretKey string) *s3.S3 {
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(s3Server.URL + "/s3"),
+		Region:           aws.String("us-east-1"),
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretKey, ""),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return s3.New(sess)
+}
+
+func getURL(t *testing.T, url string) (int, []byte) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return resp.StatusCode, body
+}
+
+func TestSigV4Header(t *testing.T) {
+	s3Server, auth, petm, tm := newTestSigV4Service(t)
+	defer s3Server.Close()
+	data := testData(1000)
+	peid := petm.addEntity("a", nil)
+	petm.setData("a", data, []byte("metadata"))
+
+	adminClient := newTestS3Client(t, s3Server, "admin-key", "admin-secret")
+	viewerClient := newTestS3Client(t, s3Server, "viewer-key", "viewer-secret")
+	listOutput, err := viewerClient.ListObjectsV2(&s3.ListObjectsV2Input{Bucket: aws.String("mem")})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, 3, len(listOutput.Contents))
+	getOutput, err := viewerClient.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String("mem"),
+		Key:    aws.String(peid.String()),
+		Range:  aws.String("bytes=10-19"),
+	})
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	readData, err := ioutil.ReadAll(getOutput.Body)
+	getOutput.Body.Close()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, data[10:20], readData)
+
+	_, err = newTestS3Client(t, s3Server, "viewer-key", "wrong-secret").ListBuckets(&s3.ListBucketsInput{})
+	assertS3ErrorCode(t, "SignatureDoesNotMatch", err)
+	_, err = newTestS3Client(t, s3Server, "unknown-key", "viewer-secret").ListBuckets(&s3.ListBucketsInput{})
+	assertS3ErrorCode(t, "InvalidAccessKeyId", err)
+	auth.now = func() time.Time { return time.Now().Add(time.Hour) }
+	_, err = viewerClient.ListBuckets(&s3.ListBucketsInput{})
+	assertS3ErrorCode(t, "RequestTimeTooSkewed", err)
+	auth.now = time.Now
+
+	pe, err := petm.GetProtectedEntity(context.Background(), peid)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	zipBuf := bytes.Buffer{}
+	err = astrolabe.ZipProtectedEntity(context.Background(), pe, &zipBuf)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	zipBytes := zipBuf.Bytes()
+	_, err = viewerClient.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(S3CopyBucket),
+		Key:    aws.String("a.zip"),
+		Body:   bytes.NewReader(zipBytes),
+	})
+	assertS3ErrorCode(t, "AccessDenied", err)
+	putReq, _ := adminClient.PutObjectRequest(&s3.PutObjectInput{
+		Bucket: aws.String(S3CopyBucket),
+		Key:    aws.String("a.zip"),
+		Body:   bytes.NewReader(zipBytes),
+	})
+	err = putReq.Send()
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.DeepEqual(t, map[string]string{"mem:a": "mem:copy-1"}, waitForCopyTask(t, tm, putReq.HTTPResponse.Header))
+
+	// The signature covers the hash of the payload, a different body is rejected once it has been read
+	signer := v4.NewSigner(credentials.NewStaticCredentials("admin-key", "admin-secret", ""))
+	tamperedReq, err := http.NewRequest(http.MethodPut, s3Server.URL+"/s3/copy/b.zip", nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	_, err = signer.Sign(tamperedReq, bytes.NewReader(zipBytes), "s3", "us-east-1", time.Now())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	tampered := append([]byte{}, zipBytes...)
+	tampered[100] ^= 0xff
+	tamperedReq.Body = ioutil.NopCloser(bytes.NewReader(tampered))
+	tamperedReq.ContentLength = int64(len(tampered))
+	resp, err := http.DefaultClient.Do(tamperedReq)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Assert(t, strings.Contains(string(body), "XAmzContentSHA256Mismatch"), string(body))
+}
+
+/*
+Signs zipBytes as a streaming SigV4 upload in 8K chunks.  If badChunk is not -1 that chunk's signature is wrong.
+*/
+func streamingUpload(t *testing.T, s3Server *httptest.Server, key string, zipBytes []byte, badChunk int) *http.Response {
+	req, err := http.NewRequest(http.MethodPut, s3Server.URL+"/s3/copy/"+key, nil)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	req.Header.Set("X-Amz-Content-Sha256", sigV4StreamingPayload)
+	req.Header.Set("Content-Encoding", "aws-chunked")
+	req.Header.Set("X-Amz-Decoded-Content-Length", strconv.Itoa(len(zipBytes)))
+	signTime := time.Now().UTC()
+	signer := v4.NewSigner(credentials.NewStaticCredentials("admin-key", "admin-secret", ""))
+	_, err = signer.Sign(req, nil, "s3", "us-east-1", signTime)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	authorization := req.Header.Get("Authorization")
+	previousSignature := authorization[strings.Index(authorization, "Signature=")+len("Signature="):]
+
+	signingKey := []byte("AWS4admin-secret")
+	for _, scopePart := range []string{signTime.Format("20060102"), "us-east-1", "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, scopePart)
+	}
+	chunkedBody := bytes.Buffer{}
+	// The final chunk is empty
+	for offset, chunk := 0, 0; ; chunk++ {
+		end := offset + 8192
+		if end > len(zipBytes) {
+			end = len(zipBytes)
+		}
+		stringToSign := strings.Join([]string{
+			"AWS4-HMAC-SHA256-PAYLOAD",
+			signTime.Format(sigV4TimeFormat),
+			signTime.Format("20060102") + "/us-east-1/s3/aws4_request",
+			previousSignature,
+			hex.EncodeToString(sha256Sum(nil)),
+			hex.EncodeToString(sha256Sum(zipBytes[offset:end])),
+		}, "\n")
+		previousSignature = hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+		signature := previousSignature
+		if chunk == badChunk {
+			signature = strings.Repeat("0", 64)
+		}
+		fmt.Fprintf(&chunkedBody, "%x;chunk-signature=%s\r\n", end-offset, signature)
+		chunkedBody.Write(zipBytes[offset:end])
+		chunkedBody.WriteString("\r\n")
+		if end == offset {
+			break
+		}
+		offset = end
+	}
+	req.Body = ioutil.NopCloser(&chunkedBody)
+	req.ContentLength = int64(chunkedBody.Len())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return resp
+}
+
+func TestSigV4Streaming(t *testing.T) {
+	s3Server, _, petm, tm := newTestSigV4Service(t)
+	defer s3Server.Close()
+	peid := petm.addEntity("a", nil)
+	data := make([]byte, 20000)
+	rand.New(rand.NewSource(1)).Read(data)
+	petm.setData("a", data, nil)
+	pe, err := petm.GetProtectedEntity(context.Background(), peid)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	zipBuf := bytes.Buffer{}
+	err = astrolabe.ZipProtectedEntity(context.Background(), pe, &zipBuf)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	resp := streamingUpload(t, s3Server, "a.zip", zipBuf.Bytes(), -1)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.DeepEqual(t, map[string]string{"mem:a": "mem:copy-1"}, waitForCopyTask(t, tm, resp.Header))
+
+	resp = streamingUpload(t, s3Server, "b.zip", zipBuf.Bytes(), 1)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Assert(t, strings.Contains(string(body), "SignatureDoesNotMatch"), string(body))
+}
+
+func TestSigV4Presigned(t *testing.T) {
+	s3Server, auth, petm, _ := newTestSigV4Service(t)
+	defer s3Server.Close()
+	data := testData(1000)
+	peid := petm.addEntity("a", nil)
+	petm.setData("a", data, []byte("metadata"))
+
+	viewerClient := newTestS3Client(t, s3Server, "viewer-key", "viewer-secret")
+	getReq, _ := viewerClient.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String("mem"),
+		Key:    aws.String(peid.String()),
+	})
+	presignedURL, err := getReq.Presign(time.Minute)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	status, body := getURL(t, presignedURL)
+	assert.Equal(t, http.StatusOK, status)
+	assert.DeepEqual(t, data, body)
+
+	// Changing the key invalidates the signature
+	assert.Assert(t, strings.Contains(presignedURL, "/mem/mem%3Aa?"), presignedURL)
+	status, body = getURL(t, strings.Replace(presignedURL, "/mem/mem%3Aa?", "/mem/mem%3Aa.md?", 1))
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Assert(t, strings.Contains(string(body), "SignatureDoesNotMatch"), string(body))
+	status, body = getURL(t, strings.Replace(presignedURL, "X-Amz-Expires=60", "X-Amz-Expires=600", 1))
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Assert(t, strings.Contains(string(body), "SignatureDoesNotMatch"), string(body))
+
+	auth.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	status, body = getURL(t, presignedURL)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Assert(t, strings.Contains(string(body), "expired"), string(body))
+	auth.now = time.Now
+
+	// The S3 transports are presigned with the access key in the S3 config
+	pe, err := petm.GetProtectedEntity(context.Background(), peid)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	info, err := pe.GetInfo(context.Background())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	transportURL, ok := info.GetDataTransports()[0].GetParam(astrolabe.S3URLParam)
+	assert.Assert(t, ok)
+	status, body = getURL(t, transportURL)
+	assert.Equal(t, http.StatusOK, status)
+	assert.DeepEqual(t, data, body)
+}
@@ -0,0 +1,321 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/vmware-tanzu/astrolabe/gen/models"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How many task events are kept so that clients can resume a stream with Last-Event-ID
+const taskEventBufferSize = 1024
+
+// How often a comment is written to an idle task event stream so that proxies do not close it
+const taskEventKeepAlive = 15 * time.Second
+
+// Types of the task events
+const (
+	// The task was started or its status changed
+	TaskEventStatus = "status"
+	// The progress, details, queue position or locks of the task changed
+	TaskEventProgress = "progress"
+)
+
+/*
+TaskEvent is a change of a task seen by the task manager.  Info is the task info after the change.
+*/
+type TaskEvent struct {
+	ID   string
+	Type string
+	Info models.TaskInfo
+}
+
+/*
+taskEventLog records the changes of the tasks.  Events are numbered in order, their IDs are prefixed with an epoch
+that is unique to the task manager so that IDs issued by another server, or before a restart, are not mistaken for
+ours.  Only the last taskEventBufferSize events are kept.
+*/
+type taskEventLog struct {
+	start    sync.Once
+	mutex    sync.Mutex
+	epoch    string
+	nextSeq  uint64
+	firstSeq uint64
+	events   []TaskEvent
+	// Task info as of the last recorded event
+	current map[astrolabe.TaskID]models.TaskInfo
+	// Closed and replaced when events are recorded
+	added chan struct{}
+}
+
+func newTaskEventLog() *taskEventLog {
+	return &taskEventLog{
+		epoch:    uuid.New().String(),
+		nextSeq:  1,
+		firstSeq: 1,
+		current:  map[astrolabe.TaskID]models.TaskInfo{},
+		added:    make(chan struct{}),
+	}
+}
+
+func (this *taskEventLog) eventID(seq uint64) string {
+	return this.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+/*
+parseEventID returns the sequence number of an event ID issued by this log
+*/
+func (this *taskEventLog) parseEventID(id string) (uint64, bool) {
+	separator := strings.LastIndex(id, "-")
+	if separator < 0 || id[:separator] != this.epoch {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(id[separator+1:], 10, 64)
+	if err != nil || seq >= this.nextSeq {
+		return 0, false
+	}
+	return seq, true
+}
+
+func (this *taskEventLog) record(eventType string, info models.TaskInfo) {
+	this.events = append(this.events, TaskEvent{
+		ID:   this.eventID(this.nextSeq),
+		Type: eventType,
+		Info: info,
+	})
+	this.nextSeq++
+	if len(this.events) > taskEventBufferSize {
+		this.events = this.events[len(this.events)-taskEventBufferSize:]
+		this.firstSeq = this.nextSeq - taskEventBufferSize
+	}
+}
+
+/*
+taskEventType returns the type of the event for a task that changed from last to info, or "" if it did not change
+*/
+func taskEventType(last models.TaskInfo, known bool, info models.TaskInfo) string {
+	if !known || *last.Status != *info.Status || *last.Completed != *info.Completed {
+		return TaskEventStatus
+	}
+	if *last.Progress != *info.Progress || last.Details != info.Details || last.QueuePosition != info.QueuePosition ||
+		!reflect.DeepEqual(last.Locks, info.Locks) {
+		return TaskEventProgress
+	}
+	return ""
+}
+
+/*
+startTaskEvents starts recording the task events the first time it is called.  The tasks that exist at that point
+are taken as the initial state, without events.
+*/
+func (this *TaskManager) startTaskEvents() {
+	this.taskEvents.start.Do(func() {
+		this.pollTaskEvents(false)
+		go func() {
+			for this.keepRunning {
+				time.Sleep(taskWatchInterval)
+				this.pollTaskEvents(true)
+			}
+		}()
+	})
+}
+
+/*
+pollTaskEvents compares the tasks with their last recorded state and records an event for each task that changed
+*/
+func (this *TaskManager) pollTaskEvents(record bool) {
+	this.mutex.RLock()
+	tasks := make(map[astrolabe.TaskID]astrolabe.Task, len(this.tasks))
+	for id, task := range this.tasks {
+		tasks[id] = task
+	}
+	this.mutex.RUnlock()
+
+	log := this.taskEvents
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	changed := []models.TaskInfo{}
+	for id, task := range tasks {
+		last, known := log.current[id]
+		// Finished tasks do not change anymore
+		if known && *last.Completed {
+			continue
+		}
+		info := task.GetModelTaskInfo()
+		if taskEventType(last, known, info) != "" {
+			changed = append(changed, info)
+		}
+	}
+	for id := range log.current {
+		if _, ok := tasks[id]; !ok {
+			delete(log.current, id)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	sortTaskInfos(changed)
+	for _, info := range changed {
+		id := astrolabe.NewTaskIDFromString(string(info.ID))
+		last, known := log.current[id]
+		if record {
+			log.record(taskEventType(last, known, info), info)
+		}
+		log.current[id] = info
+	}
+	if record {
+		close(log.added)
+		log.added = make(chan struct{})
+	}
+}
+
+func sortTaskInfos(infos []models.TaskInfo) {
+	sort.Slice(infos, func(i, j int) bool {
+		if *infos[i].StartedTimeNS != *infos[j].StartedTimeNS {
+			return *infos[i].StartedTimeNS < *infos[j].StartedTimeNS
+		}
+		return infos[i].ID < infos[j].ID
+	})
+}
+
+/*
+TaskEventsSince returns the events of taskID, or of all tasks if it is empty, recorded after the event lastEventID.
+If lastEventID is empty, was not issued by this task manager or is too old to resume from, the current state of the
+tasks is returned instead, as status events.  cursor is the ID to pass as lastEventID to get the next events, added is
+closed when more events are recorded and done is true if taskID is set and has finished, or is no longer known.
+*/
+func (this *TaskManager) TaskEventsSince(taskID string, lastEventID string) (events []TaskEvent, cursor string,
+	added <-chan struct{}, done bool) {
+	this.startTaskEvents()
+	var task astrolabe.Task
+	taskKnown := false
+	if taskID != "" {
+		task, taskKnown = this.RetrieveTask(astrolabe.NewTaskIDFromString(taskID))
+	}
+	log := this.taskEvents
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	// A task added since the last poll is recorded now, rather than taken for gone
+	if taskKnown {
+		if _, ok := log.current[task.GetID()]; !ok {
+			info := task.GetModelTaskInfo()
+			log.record(TaskEventStatus, info)
+			log.current[task.GetID()] = info
+			close(log.added)
+			log.added = make(chan struct{})
+		}
+	}
+	events = []TaskEvent{}
+	cursor = log.eventID(log.nextSeq - 1)
+	if seq, ok := log.parseEventID(lastEventID); ok && seq+1 >= log.firstSeq {
+		for _, event := range log.events[seq+1-log.firstSeq:] {
+			if taskID == "" || string(event.Info.ID) == taskID {
+				events = append(events, event)
+			}
+		}
+	} else {
+		infos := []models.TaskInfo{}
+		for id, info := range log.current {
+			if taskID == "" || id.String() == taskID {
+				infos = append(infos, info)
+			}
+		}
+		sortTaskInfos(infos)
+		for _, info := range infos {
+			events = append(events, TaskEvent{
+				ID:   cursor,
+				Type: TaskEventStatus,
+				Info: info,
+			})
+		}
+	}
+	if taskID != "" {
+		info, ok := log.current[astrolabe.NewTaskIDFromString(taskID)]
+		done = !ok || *info.Completed
+	}
+	return events, cursor, log.added, done
+}
+
+/*
+StreamTaskEvents writes the events of taskID, or of all tasks if it is empty, recorded after lastEventID to w as
+Server-Sent Events until ctx is done or, if taskID is set, the task has finished.  See TaskEventsSince for
+lastEventID.
+*/
+func (this *TaskManager) StreamTaskEvents(ctx context.Context, w http.ResponseWriter, taskID string,
+	lastEventID string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	// Lets the client know the stream is open before there are any events
+	if _, err := io.WriteString(w, ": task events\n\n"); err != nil {
+		return
+	}
+	flush()
+	keepAlive := time.NewTicker(taskEventKeepAlive)
+	defer keepAlive.Stop()
+	cursor := lastEventID
+	for {
+		events, nextCursor, added, done := this.TaskEventsSince(taskID, cursor)
+		cursor = nextCursor
+		for _, event := range events {
+			if err := writeTaskEvent(w, event); err != nil {
+				return
+			}
+		}
+		flush()
+		if done {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-added:
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flush()
+		}
+	}
+}
+
+func writeTaskEvent(w io.Writer, event TaskEvent) error {
+	data, err := json.Marshal(event.Info)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,267 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/loads"
+	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/sirupsen/logrus"
+	restClient "github.com/vmware-tanzu/astrolabe/gen/client"
+	"github.com/vmware-tanzu/astrolabe/gen/restapi"
+	"github.com/vmware-tanzu/astrolabe/gen/restapi/operations"
+	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	astrolabeClient "github.com/vmware-tanzu/astrolabe/pkg/client"
+)
+
+/*
+nextTaskEvent waits for an event sent to events by a WatchTaskEvents handler
+*/
+func nextTaskEvent(t *testing.T, events chan astrolabeClient.TaskEvent) astrolabeClient.TaskEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(10 * time.Second):
+		t.Fatal("No task event received")
+	}
+	return astrolabeClient.TaskEvent{}
+}
+
+func TestTaskEventStream(t *testing.T) {
+	ctx := context.Background()
+	pem := NewDirectProtectedEntityManager([]astrolabe.ProtectedEntityTypeManager{newMemoryProtectedEntityTypeManager("mem")},
+		astrolabe.S3Config{}, logrus.New())
+	tm := NewTaskManager()
+	swaggerSpec, err := loads.Analyzed(restapi.SwaggerJSON, "")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	api := operations.NewAstrolabeAPI(swaggerSpec)
+	NewOpenAPIAstrolabeHandler(pem, tm).AttachHandlers(api)
+	restServer := httptest.NewServer(api.Serve(nil))
+	defer restServer.Close()
+	transport := httptransport.New(strings.TrimPrefix(restServer.URL, "http://"), restClient.DefaultBasePath,
+		[]string{"http"})
+	clientPEM, err := astrolabeClient.NewClientProtectedEntityManager(restClient.New(transport, strfmt.Default))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	release := make(chan struct{})
+	task := startAsyncTask(ctx, "copy", func(ctx context.Context) (interface{}, error) {
+		<-release
+		return "copied", nil
+	})
+	tm.AddTask(task)
+	taskID := task.GetID().String()
+
+	// Watching one task starts with its current state and ends once it has finished
+	events := make(chan astrolabeClient.TaskEvent, 10)
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- clientPEM.WatchTaskEvents(ctx, taskID, func(event astrolabeClient.TaskEvent) bool {
+			events <- event
+			return true
+		})
+	}()
+	started := nextTaskEvent(t, events)
+	assert.Equal(t, TaskEventStatus, started.Type)
+	assert.Equal(t, taskID, string(started.Info.ID))
+	assert.Equal(t, astrolabe.Running.String(), swag.StringValue(started.Info.Status))
+
+	task.mutex.Lock()
+	task.task.Progress = 50
+	task.mutex.Unlock()
+	progress := nextTaskEvent(t, events)
+	assert.Equal(t, TaskEventProgress, progress.Type)
+	assert.Equal(t, float64(50), swag.Float64Value(progress.Info.Progress))
+
+	close(release)
+	finished := nextTaskEvent(t, events)
+	assert.Equal(t, TaskEventStatus, finished.Type)
+	assert.Equal(t, astrolabe.Success.String(), swag.StringValue(finished.Info.Status))
+	assert.Equal(t, true, swag.BoolValue(finished.Info.Completed))
+	select {
+	case err = <-watchErr:
+		if err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("WatchTaskEvents did not return")
+	}
+
+	// A stream resumed from an event gets the events that followed it
+	resumed, _, _, done := tm.TaskEventsSince(taskID, started.ID)
+	assert.Equal(t, 2, len(resumed))
+	assert.Equal(t, progress.ID, resumed[0].ID)
+	assert.Equal(t, finished.ID, resumed[1].ID)
+	assert.Equal(t, true, done)
+	// IDs that were not issued by this task manager get the current state
+	current, cursor, _, _ := tm.TaskEventsSince(taskID, "other-1")
+	assert.Equal(t, 1, len(current))
+	assert.Equal(t, cursor, current[0].ID)
+	assert.Equal(t, true, swag.BoolValue(current[0].Info.Completed))
+
+	// Watching all tasks gets the tasks added later
+	next := startAsyncTask(ctx, "snapshot", func(ctx context.Context) (interface{}, error) {
+		return nil, nil
+	})
+	nextID := next.GetID().String()
+	go func() {
+		watchErr <- clientPEM.WatchTaskEvents(ctx, "", func(event astrolabeClient.TaskEvent) bool {
+			events <- event
+			return string(event.Info.ID) != nextID
+		})
+	}()
+	assert.Equal(t, taskID, string(nextTaskEvent(t, events).Info.ID))
+	tm.AddTask(next)
+	added := nextTaskEvent(t, events)
+	assert.Equal(t, nextID, string(added.Info.ID))
+	assert.Equal(t, TaskEventStatus, added.Type)
+	if err = <-watchErr; err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+
+	// Unknown tasks are not found
+	err = clientPEM.WatchTaskEvents(ctx, "unknown", func(event astrolabeClient.TaskEvent) bool {
+		return true
+	})
+	assert.Assert(t, err != nil, "watching an unknown task did not fail")
+}
+
+func TestTaskEventsSinceAddTask(t *testing.T) {
+	tm := NewTaskManager()
+	// Starts recording before the task is added
+	tm.TaskEventsSince("", "")
+
+	release := make(chan struct{})
+	task := startAsyncTask(context.Background(), "copy", func(ctx context.Context) (interface{}, error) {
+		<-release
+		return "copied", nil
+	})
+	tm.AddTask(task)
+	taskID := task.GetID().String()
+
+	// Subscribing before the task has been polled gets its current state and waits for it to finish
+	events, cursor, added, done := tm.TaskEventsSince(taskID, "")
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, TaskEventStatus, events[0].Type)
+	assert.Equal(t, astrolabe.Running.String(), swag.StringValue(events[0].Info.Status))
+	assert.Equal(t, false, done)
+
+	close(release)
+	finished := []TaskEvent{}
+	for !done {
+		select {
+		case <-added:
+		case <-time.After(10 * time.Second):
+			t.Fatal("Task did not finish")
+		}
+		events, cursor, added, done = tm.TaskEventsSince(taskID, cursor)
+		finished = append(finished, events...)
+	}
+	assert.Equal(t, 1, len(finished))
+	assert.Equal(t, astrolabe.Success.String(), swag.StringValue(finished[0].Info.Status))
+}
+
+/*
+streamedProtectedEntity is a Protected Entity whose data is read from dataReader
+*/
+type streamedProtectedEntity struct {
+	astrolabe.ProtectedEntity
+	dataReader io.ReadCloser
+}
+
+func (this streamedProtectedEntity) GetDataReader(ctx context.Context) (io.ReadCloser, error) {
+	return this.dataReader, nil
+}
+
+func TestTaskEventsCopyProgress(t *testing.T) {
+	ctx := context.Background()
+	petm := newMemoryProtectedEntityTypeManager("mem")
+	peid := petm.addEntity("a", nil)
+	petm.setData("a", nil, []byte("metadata"))
+	source, err := petm.GetProtectedEntity(ctx, peid)
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	data := make([]byte, 1000)
+	pipeReader, pipeWriter := io.Pipe()
+	resume := make(chan struct{})
+	go func() {
+		pipeWriter.Write(data[:250])
+		<-resume
+		pipeWriter.Write(data[250:])
+		pipeWriter.Close()
+	}()
+	tm := NewTaskManager()
+	task := startAsyncTask(ctx, "copy", func(ctx context.Context) (interface{}, error) {
+		sourcePE := streamedProtectedEntity{
+			ProtectedEntity: source,
+			dataReader:      astrolabe.NewSizedReadCloser(pipeReader, int64(len(data))),
+		}
+		newPE, err := petm.Copy(ctx, sourcePE, make(map[string]map[string]interface{}), astrolabe.AllocateNewObject)
+		if err != nil {
+			return nil, err
+		}
+		return newPE.GetID().String(), nil
+	})
+	tm.AddTask(task)
+	taskID := task.GetID().String()
+
+	// The copy reports the share of the data it has read while it runs
+	events, cursor, added, done := tm.TaskEventsSince(taskID, "")
+	var progress *TaskEvent
+	for progress == nil {
+		for curEvent := range events {
+			if progress == nil && swag.Float64Value(events[curEvent].Info.Progress) > 0 {
+				progress = &events[curEvent]
+			}
+		}
+		if progress != nil {
+			break
+		}
+		assert.Equal(t, false, done)
+		select {
+		case <-added:
+		case <-time.After(10 * time.Second):
+			t.Fatal("No progress reported")
+		}
+		events, cursor, added, done = tm.TaskEventsSince(taskID, cursor)
+	}
+	assert.Equal(t, TaskEventProgress, progress.Type)
+	assert.Equal(t, float64(25), swag.Float64Value(progress.Info.Progress))
+	assert.Equal(t, astrolabe.Running.String(), swag.StringValue(progress.Info.Status))
+
+	close(resume)
+	select {
+	case <-task.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("Copy did not finish")
+	}
+	assert.Equal(t, astrolabe.Success, task.GetStatus(), task.GetDetails())
+	assert.Equal(t, float64(100), task.GetProgress())
+}
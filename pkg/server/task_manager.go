@@ -16,30 +16,64 @@
 package server
 
 import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/vmware-tanzu/astrolabe/pkg/astrolabe"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
+// How long finished tasks and unused task nexus are kept
+const taskRetention = 3600 * time.Second
+
+// How often WaitOnNexus checks for finished tasks
+const nexusPollInterval = 100 * time.Millisecond
+
+/*
+A task nexus collects the tasks started after it was created so that a client can wait for any of them to finish
+*/
+type taskNexus struct {
+	id       string
+	tasks    []astrolabe.TaskID
+	lastUsed time.Time
+}
+
 type TaskManager struct {
 	tasks map[astrolabe.TaskID]astrolabe.Task
+	nexus map[string]*taskNexus
 	mutex sync.RWMutex
+	// Task events are published to events if it is set
+	events *EventBus
+	// Results of the calls made with an idempotency key, by caller and key
+	idempotency       map[string]*idempotentRecord
+	idempotencyWindow time.Duration
+	// Changes of the tasks, for the task event streams
+	taskEvents *taskEventLog
 
 	// For the clean up routine
 	keepRunning bool
 }
 
 func NewTaskManager() *TaskManager {
-	newTM := TaskManager{
-		keepRunning: true,
+	newTM := &TaskManager{
+		tasks:             map[astrolabe.TaskID]astrolabe.Task{},
+		nexus:             map[string]*taskNexus{},
+		idempotency:       map[string]*idempotentRecord{},
+		idempotencyWindow: DefaultIdempotencyWindow,
+		taskEvents:        newTaskEventLog(),
+		keepRunning:       true,
 	}
 	go newTM.cleanUpLoop()
-	return &newTM
+	return newTM
 }
 
 func (this *TaskManager) ListTasks() []astrolabe.TaskID {
 	this.mutex.RLock()
-	defer this.mutex.Unlock()
+	defer this.mutex.RUnlock()
 	retTasks := make([]astrolabe.TaskID, len(this.tasks))
 	curTaskNum := 0
 	for curTask := range this.tasks {
@@ -51,13 +85,19 @@ func (this *TaskManager) ListTasks() []astrolabe.TaskID {
 
 func (this *TaskManager) AddTask(addTask astrolabe.Task) {
 	this.mutex.Lock()
-	defer this.mutex.Unlock()
 	this.tasks[addTask.GetID()] = addTask
+	for _, curNexus := range this.nexus {
+		curNexus.tasks = append(curNexus.tasks, addTask.GetID())
+	}
+	this.mutex.Unlock()
+	if this.events != nil {
+		this.publishTaskEvents(addTask)
+	}
 }
 
 func (this *TaskManager) RetrieveTask(taskID astrolabe.TaskID) (retTask astrolabe.Task, ok bool) {
 	this.mutex.RLock()
-	defer this.mutex.Unlock()
+	defer this.mutex.RUnlock()
 	retTask, ok = this.tasks[taskID]
 	return
 }
@@ -73,8 +113,161 @@ func (this *TaskManager) cleanUp() {
 	this.mutex.Lock()
 	defer this.mutex.Unlock()
 	for id, task := range this.tasks {
-		if time.Now().Sub(task.GetFinishedTime()) > 3600*time.Second {
+		// Running tasks do not have a finished time yet
+		finishedTime := task.GetFinishedTime()
+		if !finishedTime.IsZero() && time.Now().Sub(finishedTime) > taskRetention {
 			delete(this.tasks, id)
 		}
 	}
+	for id, curNexus := range this.nexus {
+		if time.Now().Sub(curNexus.lastUsed) > taskRetention {
+			delete(this.nexus, id)
+		}
+	}
+	for key, record := range this.idempotency {
+		if this.idempotentRecordExpired(record) {
+			delete(this.idempotency, key)
+		}
+	}
+}
+
+/*
+Creates a new task nexus and returns its ID.  Tasks added after this call are associated with the nexus.
+*/
+func (this *TaskManager) CreateNexus() string {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	newUUID, err := uuid.NewUUID()
+	if err != nil {
+		panic("Cannot create UUID")
+	}
+	newNexus := &taskNexus{
+		id:       newUUID.String(),
+		tasks:    []astrolabe.TaskID{},
+		lastUsed: time.Now(),
+	}
+	this.nexus[newNexus.id] = newNexus
+	return newNexus.id
+}
+
+/*
+Returns the IDs of the task nexus and the tasks associated with each.  Tasks that have been cleaned up are not
+returned.
+*/
+func (this *TaskManager) ListNexus() map[string][]astrolabe.TaskID {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	retNexus := make(map[string][]astrolabe.TaskID, len(this.nexus))
+	for id, curNexus := range this.nexus {
+		retNexus[id] = this.nexusTasks(curNexus)
+	}
+	return retNexus
+}
+
+// Must be called with the mutex held
+func (this *TaskManager) nexusTasks(curNexus *taskNexus) []astrolabe.TaskID {
+	taskIDs := []astrolabe.TaskID{}
+	for _, taskID := range curNexus.tasks {
+		if _, ok := this.tasks[taskID]; ok {
+			taskIDs = append(taskIDs, taskID)
+		}
+	}
+	return taskIDs
+}
+
+/*
+Waits until a task associated with the nexus finishes after lastFinished or waitTime passes and returns the tasks
+that finished after lastFinished, ordered by finished time.  ok is false if the nexus does not exist.
+*/
+func (this *TaskManager) WaitOnNexus(ctx context.Context, nexusID string, lastFinished time.Time,
+	waitTime time.Duration) (finished []astrolabe.Task, ok bool) {
+	deadline := time.Now().Add(waitTime)
+	for {
+		finished, ok = this.finishedNexusTasks(nexusID, lastFinished)
+		if !ok || len(finished) > 0 || !time.Now().Before(deadline) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(nexusPollInterval):
+		}
+	}
+}
+
+func (this *TaskManager) finishedNexusTasks(nexusID string, lastFinished time.Time) ([]astrolabe.Task, bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	curNexus, ok := this.nexus[nexusID]
+	if !ok {
+		return nil, false
+	}
+	curNexus.lastUsed = time.Now()
+	finished := []astrolabe.Task{}
+	for _, taskID := range this.nexusTasks(curNexus) {
+		task := this.tasks[taskID]
+		finishedTime := task.GetFinishedTime()
+		if !finishedTime.IsZero() && finishedTime.After(lastFinished) {
+			finished = append(finished, task)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].GetFinishedTime().Before(finished[j].GetFinishedTime())
+	})
+	return finished, true
+}
+
+/*
+Drain waits for the running tasks to finish.  When ctx is done, the tasks that are still running are cancelled, so that
+they can clean up, and Drain waits up to cancelWait for them to finish.  It returns the IDs of the tasks that were
+cancelled and an error if some of them could not be cancelled or did not finish.  A task that cannot be cancelled does
+not stop the others from being cancelled.
+*/
+func (this *TaskManager) Drain(ctx context.Context, cancelWait time.Duration) ([]astrolabe.TaskID, error) {
+waitLoop:
+	for len(this.runningTasks()) > 0 {
+		select {
+		case <-ctx.Done():
+			break waitLoop
+		case <-time.After(nexusPollInterval):
+		}
+	}
+	running := this.runningTasks()
+	if len(running) == 0 {
+		return nil, nil
+	}
+	cancelled := []astrolabe.TaskID{}
+	var drainErrors []string
+	for _, task := range running {
+		if err := task.Cancel(); err != nil {
+			drainErrors = append(drainErrors, fmt.Sprintf("could not cancel task %s: %v", task.GetID().String(), err))
+			continue
+		}
+		cancelled = append(cancelled, task.GetID())
+	}
+	deadline := time.Now().Add(cancelWait)
+	for len(this.runningTasks()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(nexusPollInterval)
+	}
+	running = this.runningTasks()
+	if len(running) > 0 {
+		drainErrors = append(drainErrors, fmt.Sprintf("%d tasks did not finish within %v of being cancelled",
+			len(running), cancelWait))
+	}
+	if len(drainErrors) > 0 {
+		return cancelled, errors.New(strings.Join(drainErrors, ", "))
+	}
+	return cancelled, nil
+}
+
+func (this *TaskManager) runningTasks() []astrolabe.Task {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+	running := []astrolabe.Task{}
+	for _, task := range this.tasks {
+		if task.GetFinishedTime().IsZero() {
+			running = append(running, task)
+		}
+	}
+	return running
 }
@@ -0,0 +1,348 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const webhooksConfigFile = "webhooks.json"
+
+// Defaults for the settings that are not in webhooks.json
+const (
+	defaultWebhookMaxAttempts    = 5
+	defaultWebhookInitialBackoff = time.Second
+	defaultWebhookMaxBackoff     = time.Minute
+	defaultWebhookTimeout        = 10 * time.Second
+	defaultWebhookQueueSize      = 1000
+	defaultWebhookDeadLetterFile = "webhooks-dead-letter.jsonl"
+)
+
+// Headers of the webhook requests
+const (
+	WebhookSignatureHeader = "X-Astrolabe-Signature"
+	WebhookEventHeader     = "X-Astrolabe-Event"
+	WebhookDeliveryHeader  = "X-Astrolabe-Delivery"
+)
+
+type WebhookConfig struct {
+	URL string `json:"url"`
+	// The body of each request is signed with HMAC-SHA256 using secret, if it is set
+	Secret string `json:"secret,omitempty"`
+	// The event types delivered to the webhook, a type ending in * matches by prefix, e.g. task.*.  All events are
+	// delivered if events is empty.
+	Events []string `json:"events,omitempty"`
+}
+
+type WebhooksConfig struct {
+	Webhooks []WebhookConfig `json:"webhooks"`
+	// A delivery is attempted up to maxAttempts times.  The wait between attempts starts at initialBackoffSeconds and
+	// doubles up to maxBackoffSeconds.
+	MaxAttempts           int     `json:"maxAttempts,omitempty"`
+	InitialBackoffSeconds float64 `json:"initialBackoffSeconds,omitempty"`
+	MaxBackoffSeconds     float64 `json:"maxBackoffSeconds,omitempty"`
+	TimeoutSeconds        float64 `json:"timeoutSeconds,omitempty"`
+	// Events waiting for delivery to each webhook, events are dead lettered when the queue is full
+	QueueSize int `json:"queueSize,omitempty"`
+	// Events that could not be delivered are appended to deadLetterFile, relative to the configuration directory
+	DeadLetterFile string `json:"deadLetterFile,omitempty"`
+}
+
+/*
+deadLetter is a line of the dead-letter file
+*/
+type deadLetter struct {
+	Time     time.Time `json:"time"`
+	URL      string    `json:"url"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	Event    Event     `json:"event"`
+}
+
+/*
+WebhookNotifier delivers events to the webhooks in a WebhooksConfig.  Each webhook has a queue and delivers its
+events in order.  Failed deliveries are retried with exponential backoff on network errors, 408, 429 and 5xx
+responses.  Events that are not delivered are appended to the dead-letter file.
+*/
+type WebhookNotifier struct {
+	webhooks       []*webhook
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	deadLetterPath string
+	deadLetterLock sync.Mutex
+	client         *http.Client
+	logger         logrus.FieldLogger
+
+	closeLock sync.Mutex
+	closed    bool
+	stop      chan struct{}
+	workers   sync.WaitGroup
+}
+
+type webhook struct {
+	config WebhookConfig
+	queue  chan Event
+}
+
+/*
+NewWebhookNotifierFromConfigDir reads webhooks.json from confDirPath.  If there is no webhooks.json, nil is returned
+and no events are delivered.
+*/
+func NewWebhookNotifierFromConfigDir(confDirPath string, logger logrus.FieldLogger) (*WebhookNotifier, error) {
+	configPath := filepath.Join(confDirPath, webhooksConfigFile)
+	configBytes, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "could not read %s", configPath)
+	}
+	config := WebhooksConfig{}
+	err = json.Unmarshal(configBytes, &config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse %s", configPath)
+	}
+	return NewWebhookNotifier(config, confDirPath, logger)
+}
+
+func NewWebhookNotifier(config WebhooksConfig, confDirPath string, logger logrus.FieldLogger) (*WebhookNotifier, error) {
+	returnNotifier := &WebhookNotifier{
+		maxAttempts:    config.MaxAttempts,
+		initialBackoff: time.Duration(config.InitialBackoffSeconds * float64(time.Second)),
+		maxBackoff:     time.Duration(config.MaxBackoffSeconds * float64(time.Second)),
+		client: &http.Client{
+			Timeout: time.Duration(config.TimeoutSeconds * float64(time.Second)),
+		},
+		logger: logger,
+		stop:   make(chan struct{}),
+	}
+	if returnNotifier.maxAttempts <= 0 {
+		returnNotifier.maxAttempts = defaultWebhookMaxAttempts
+	}
+	if returnNotifier.initialBackoff <= 0 {
+		returnNotifier.initialBackoff = defaultWebhookInitialBackoff
+	}
+	if returnNotifier.maxBackoff <= 0 {
+		returnNotifier.maxBackoff = defaultWebhookMaxBackoff
+	}
+	if returnNotifier.client.Timeout <= 0 {
+		returnNotifier.client.Timeout = defaultWebhookTimeout
+	}
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultWebhookQueueSize
+	}
+	deadLetterFile := config.DeadLetterFile
+	if deadLetterFile == "" {
+		deadLetterFile = defaultWebhookDeadLetterFile
+	}
+	returnNotifier.deadLetterPath = configPath(confDirPath, deadLetterFile)
+	for _, webhookConfig := range config.Webhooks {
+		if !strings.HasPrefix(webhookConfig.URL, "http://") && !strings.HasPrefix(webhookConfig.URL, "https://") {
+			return nil, errors.Errorf("webhook URL %q must be an http or https URL", webhookConfig.URL)
+		}
+		returnNotifier.webhooks = append(returnNotifier.webhooks, &webhook{
+			config: webhookConfig,
+			queue:  make(chan Event, queueSize),
+		})
+	}
+	for _, curWebhook := range returnNotifier.webhooks {
+		returnNotifier.workers.Add(1)
+		go returnNotifier.deliverLoop(curWebhook)
+	}
+	return returnNotifier, nil
+}
+
+/*
+Notify queues event for the webhooks that want it.  It does not block, it is meant to be subscribed to an EventBus.
+*/
+func (this *WebhookNotifier) Notify(event Event) {
+	this.closeLock.Lock()
+	defer this.closeLock.Unlock()
+	for _, curWebhook := range this.webhooks {
+		if !curWebhook.wants(event.Type) {
+			continue
+		}
+		if this.closed {
+			this.writeDeadLetter(curWebhook, event, 0, errors.New("the notifier is closed"))
+			continue
+		}
+		select {
+		case curWebhook.queue <- event:
+		default:
+			this.writeDeadLetter(curWebhook, event, 0, errors.New("the webhook queue is full"))
+		}
+	}
+}
+
+/*
+Close stops retrying, makes a last attempt to deliver the queued events and waits for the deliveries to finish
+*/
+func (this *WebhookNotifier) Close() error {
+	this.closeLock.Lock()
+	if this.closed {
+		this.closeLock.Unlock()
+		return nil
+	}
+	this.closed = true
+	close(this.stop)
+	for _, curWebhook := range this.webhooks {
+		close(curWebhook.queue)
+	}
+	this.closeLock.Unlock()
+	this.workers.Wait()
+	return nil
+}
+
+func (this *webhook) wants(eventType string) bool {
+	if len(this.config.Events) == 0 {
+		return true
+	}
+	for _, wanted := range this.config.Events {
+		if wanted == eventType || (strings.HasSuffix(wanted, "*") && strings.HasPrefix(eventType,
+			strings.TrimSuffix(wanted, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *WebhookNotifier) deliverLoop(curWebhook *webhook) {
+	defer this.workers.Done()
+	for event := range curWebhook.queue {
+		this.deliver(curWebhook, event)
+	}
+}
+
+/*
+deliver posts event to the webhook until it is accepted, the attempts run out or the notifier is closed
+*/
+func (this *WebhookNotifier) deliver(curWebhook *webhook, event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		this.writeDeadLetter(curWebhook, event, 0, errors.Wrap(err, "could not marshal event"))
+		return
+	}
+	backoff := this.initialBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := this.post(curWebhook, event, body)
+		if err == nil {
+			return
+		}
+		this.logger.WithError(err).Infof("Delivery %d of event %s to %s failed", attempt, event.ID,
+			curWebhook.config.URL)
+		if !retry || attempt == this.maxAttempts {
+			this.writeDeadLetter(curWebhook, event, attempt, err)
+			return
+		}
+		select {
+		case <-this.stop:
+			this.writeDeadLetter(curWebhook, event, attempt, err)
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > this.maxBackoff {
+			backoff = this.maxBackoff
+		}
+	}
+}
+
+/*
+post makes one delivery attempt.  retry is set if the attempt failed in a way that may succeed later.
+*/
+func (this *WebhookNotifier) post(curWebhook *webhook, event Event, body []byte) (retry bool, err error) {
+	request, err := http.NewRequest(http.MethodPost, curWebhook.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "could not create request")
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookEventHeader, event.Type)
+	request.Header.Set(WebhookDeliveryHeader, event.ID)
+	if curWebhook.config.Secret != "" {
+		request.Header.Set(WebhookSignatureHeader, SignWebhookBody(curWebhook.config.Secret, body))
+	}
+	response, err := this.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(ioutil.Discard, response.Body)
+	response.Body.Close()
+	switch {
+	case response.StatusCode >= 200 && response.StatusCode < 300:
+		return false, nil
+	case response.StatusCode == http.StatusRequestTimeout || response.StatusCode == http.StatusTooManyRequests ||
+		response.StatusCode >= 500:
+		return true, errors.Errorf("webhook returned %s", response.Status)
+	default:
+		return false, errors.Errorf("webhook returned %s", response.Status)
+	}
+}
+
+/*
+SignWebhookBody returns the signature header of a webhook request, sha256= and the hex HMAC-SHA256 of body with
+secret.  Receivers compute the same and compare it with hmac.Equal.
+*/
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (this *WebhookNotifier) writeDeadLetter(curWebhook *webhook, event Event, attempts int, deliveryErr error) {
+	line, err := json.Marshal(deadLetter{
+		Time:     time.Now(),
+		URL:      curWebhook.config.URL,
+		Attempts: attempts,
+		Error:    deliveryErr.Error(),
+		Event:    event,
+	})
+	if err == nil {
+		line = append(line, '\n')
+		this.deadLetterLock.Lock()
+		defer this.deadLetterLock.Unlock()
+		var deadLetterFile *os.File
+		deadLetterFile, err = os.OpenFile(this.deadLetterPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err == nil {
+			_, err = deadLetterFile.Write(line)
+			if closeErr := deadLetterFile.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	if err != nil {
+		this.logger.WithError(err).Errorf("Could not dead letter event %s for %s", event.ID, curWebhook.config.URL)
+		return
+	}
+	this.logger.Warnf("Dead lettered event %s for %s: %v", event.ID, curWebhook.config.URL, deliveryErr)
+}
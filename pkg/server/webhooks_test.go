@@ -0,0 +1,212 @@
+/*
+ * Copyright 2019 the Astrolabe contributors
+ * SPDX-License-Identifier: Apache-2.0
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+/*
+webhookReceiver records the requests it accepts and answers the first requests with the queued status codes
+*/
+type webhookReceiver struct {
+	mutex    sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (this *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	status := http.StatusOK
+	if len(this.statuses) > 0 {
+		status = this.statuses[0]
+		this.statuses = this.statuses[1:]
+	}
+	this.requests = append(this.requests, r)
+	this.bodies = append(this.bodies, body)
+	w.WriteHeader(status)
+}
+
+func (this *webhookReceiver) received() int {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return len(this.requests)
+}
+
+/*
+Waits for count requests, Close gives up on retries so the tests wait before closing the notifier
+*/
+func (this *webhookReceiver) waitForRequests(t *testing.T, count int) {
+	deadline := time.Now().Add(10 * time.Second)
+	for this.received() < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("Got %d webhook requests, expected %d", this.received(), count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func newTestWebhookNotifier(t *testing.T, dir string, webhooks ...WebhookConfig) *WebhookNotifier {
+	notifier, err := NewWebhookNotifier(WebhooksConfig{
+		Webhooks:              webhooks,
+		MaxAttempts:           3,
+		InitialBackoffSeconds: 0.01,
+		MaxBackoffSeconds:     0.02,
+	}, dir, logrus.New())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	return notifier
+}
+
+func readDeadLetters(t *testing.T, dir string) []deadLetter {
+	deadLetterBytes, err := ioutil.ReadFile(filepath.Join(dir, defaultWebhookDeadLetterFile))
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	deadLetters := []deadLetter{}
+	for _, line := range strings.Split(strings.TrimSpace(string(deadLetterBytes)), "\n") {
+		curDeadLetter := deadLetter{}
+		if err := json.Unmarshal([]byte(line), &curDeadLetter); err != nil {
+			t.Fatal("Got error " + err.Error())
+		}
+		deadLetters = append(deadLetters, curDeadLetter)
+	}
+	return deadLetters
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	receiver := &webhookReceiver{
+		statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+	}
+	receiverServer := httptest.NewServer(receiver)
+	defer receiverServer.Close()
+	dir, err := ioutil.TempDir("", "astrolabe-webhooks")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer os.RemoveAll(dir)
+	notifier := newTestWebhookNotifier(t, dir, WebhookConfig{
+		URL:    receiverServer.URL,
+		Secret: "webhook-secret",
+		Events: []string{"snapshot.*", EventCopyFinished},
+	})
+	bus, _ := newTestEventBus()
+	bus.Subscribe(notifier.Notify)
+
+	bus.Publish(Event{Type: EventTaskPrefix + "running", TaskID: "filtered"})
+	bus.Publish(Event{Type: EventSnapshotCreated, ProtectedEntityID: "mem:a:snap-1"})
+	bus.Publish(Event{Type: EventCopyFinished, SourceID: "mem:a"})
+	receiver.waitForRequests(t, 4)
+	notifier.Close()
+
+	// The snapshot event is accepted on the third attempt, the task event is not sent
+	assert.Equal(t, 4, receiver.received())
+	for requestNum, request := range receiver.requests {
+		assert.Equal(t, SignWebhookBody("webhook-secret", receiver.bodies[requestNum]),
+			request.Header.Get(WebhookSignatureHeader))
+	}
+	assert.Equal(t, receiver.requests[0].Header.Get(WebhookDeliveryHeader),
+		receiver.requests[2].Header.Get(WebhookDeliveryHeader))
+	event := Event{}
+	if err = json.Unmarshal(receiver.bodies[2], &event); err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Equal(t, EventSnapshotCreated, event.Type)
+	assert.Equal(t, "mem:a:snap-1", event.ProtectedEntityID)
+	assert.Equal(t, EventCopyFinished, receiver.requests[3].Header.Get(WebhookEventHeader))
+}
+
+func TestWebhookDeadLetter(t *testing.T) {
+	receiver := &webhookReceiver{
+		statuses: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable,
+			http.StatusBadRequest},
+	}
+	receiverServer := httptest.NewServer(receiver)
+	defer receiverServer.Close()
+	dir, err := ioutil.TempDir("", "astrolabe-webhooks")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer os.RemoveAll(dir)
+	notifier := newTestWebhookNotifier(t, dir, WebhookConfig{
+		URL: receiverServer.URL,
+	})
+	notifier.Notify(Event{ID: "retried", Type: EventSnapshotDeleted})
+	notifier.Notify(Event{ID: "rejected", Type: EventSnapshotDeleted})
+	receiver.waitForRequests(t, 4)
+	notifier.Close()
+
+	// The first event runs out of attempts, the second is not retried after a 400
+	assert.Equal(t, 4, receiver.received())
+	deadLetters := readDeadLetters(t, dir)
+	assert.Equal(t, 2, len(deadLetters))
+	assert.Equal(t, "retried", deadLetters[0].Event.ID)
+	assert.Equal(t, 3, deadLetters[0].Attempts)
+	assert.Equal(t, receiverServer.URL, deadLetters[0].URL)
+	assert.Assert(t, strings.Contains(deadLetters[0].Error, "503"), deadLetters[0].Error)
+	assert.Equal(t, "rejected", deadLetters[1].Event.ID)
+	assert.Equal(t, 1, deadLetters[1].Attempts)
+
+	// Events published after close go straight to the dead-letter file
+	notifier.Notify(Event{ID: "closed", Type: EventSnapshotDeleted})
+	deadLetters = readDeadLetters(t, dir)
+	assert.Equal(t, "closed", deadLetters[2].Event.ID)
+	assert.Equal(t, 0, deadLetters[2].Attempts)
+}
+
+func TestWebhookNotifierFromConfigDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "astrolabe-webhooks")
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer os.RemoveAll(dir)
+	notifier, err := NewWebhookNotifierFromConfigDir(dir, logrus.New())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	assert.Assert(t, notifier == nil)
+
+	writeTestConfigFile(t, filepath.Join(dir, webhooksConfigFile), `{"webhooks": [{"url": "ftp://example.com"}]}`)
+	_, err = NewWebhookNotifierFromConfigDir(dir, logrus.New())
+	assert.ErrorContains(t, err, "must be an http or https URL")
+
+	writeTestConfigFile(t, filepath.Join(dir, webhooksConfigFile),
+		`{"webhooks": [{"url": "https://example.com/hook"}], "deadLetterFile": "dead.jsonl"}`)
+	notifier, err = NewWebhookNotifierFromConfigDir(dir, logrus.New())
+	if err != nil {
+		t.Fatal("Got error " + err.Error())
+	}
+	defer notifier.Close()
+	assert.Equal(t, filepath.Join(dir, "dead.jsonl"), notifier.deadLetterPath)
+	assert.Equal(t, defaultWebhookMaxAttempts, notifier.maxAttempts)
+}